@@ -0,0 +1,141 @@
+package auth
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/TFMV/trino-cli/config"
+	krbclient "github.com/jcmturner/gokrb5/v8/client"
+	krbconfig "github.com/jcmturner/gokrb5/v8/config"
+	"github.com/jcmturner/gokrb5/v8/credentials"
+	"github.com/jcmturner/gokrb5/v8/keytab"
+	"github.com/jcmturner/gokrb5/v8/spnego"
+	"github.com/trinodb/trino-go-client/trino"
+)
+
+// RegisterKerberos registers with the Trino driver the HTTP client that
+// authenticates with a profile's Kerberos settings, under the name its DSN
+// refers to. Nothing is read until the first request, so a missing ticket
+// is reported by the query that needs it.
+func RegisterKerberos(settings config.Kerberos) error {
+	client := &http.Client{Transport: &kerberosTransport{settings: settings, base: http.DefaultTransport}}
+	return trino.RegisterCustomClient(settings.ClientKey(), client)
+}
+
+// kerberosTransport adds a SPNEGO Authorization header to each request. It
+// logs in on first use, and again after a failure, so that a ticket renewed
+// with kinit is picked up without restarting.
+type kerberosTransport struct {
+	settings config.Kerberos
+	base     http.RoundTripper
+
+	mu     sync.Mutex
+	client *krbclient.Client
+}
+
+func (t *kerberosTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	client, err := t.login()
+	if err != nil {
+		return nil, err
+	}
+	service := t.settings.ServiceName
+	if service == "" {
+		service = "trino"
+	}
+	req = req.Clone(req.Context())
+	if err := spnego.SetSPNEGOHeader(client, req, service+"/"+req.URL.Hostname()); err != nil {
+		t.forget(client)
+		return nil, fmt.Errorf("kerberos: %w", err)
+	}
+	return t.base.RoundTrip(req)
+}
+
+// login returns the logged-in Kerberos client, creating it if need be
+func (t *kerberosTransport) login() (*krbclient.Client, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.client != nil {
+		return t.client, nil
+	}
+	client, err := newKerberosClient(t.settings)
+	if err != nil {
+		return nil, err
+	}
+	t.client = client
+	return client, nil
+}
+
+// forget drops a client whose credentials failed, so the next request
+// logs in afresh
+func (t *kerberosTransport) forget(client *krbclient.Client) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.client == client {
+		t.client = nil
+		client.Destroy()
+	}
+}
+
+// newKerberosClient logs in with the keytab, or with the ticket cache when
+// there is none
+func newKerberosClient(settings config.Kerberos) (*krbclient.Client, error) {
+	conf, err := krbconfig.Load(krb5ConfigPath(settings))
+	if err != nil {
+		return nil, fmt.Errorf("kerberos: failed to load %s: %w", krb5ConfigPath(settings), err)
+	}
+
+	if settings.Keytab != "" {
+		if settings.Principal == "" {
+			return nil, fmt.Errorf("kerberos: a principal is needed with a keytab")
+		}
+		kt, err := keytab.Load(settings.Keytab)
+		if err != nil {
+			return nil, fmt.Errorf("kerberos: failed to load keytab %s: %w", settings.Keytab, err)
+		}
+		user, realm, _ := strings.Cut(settings.Principal, "@")
+		if realm == "" {
+			realm = conf.LibDefaults.DefaultRealm
+		}
+		client := krbclient.NewWithKeytab(user, realm, kt, conf, krbclient.DisablePAFXFAST(true))
+		if err := client.Login(); err != nil {
+			return nil, fmt.Errorf("kerberos: login as %s failed: %w", settings.Principal, err)
+		}
+		return client, nil
+	}
+
+	path := ticketCachePath(settings)
+	ccache, err := credentials.LoadCCache(path)
+	if err != nil {
+		return nil, fmt.Errorf("kerberos: no ticket cache at %s (run kinit, or set a keytab): %w", path, err)
+	}
+	client, err := krbclient.NewFromCCache(ccache, conf, krbclient.DisablePAFXFAST(true))
+	if err != nil {
+		return nil, fmt.Errorf("kerberos: failed to use ticket cache %s: %w", path, err)
+	}
+	return client, nil
+}
+
+// krb5ConfigPath returns the krb5.conf to read
+func krb5ConfigPath(settings config.Kerberos) string {
+	if settings.Config != "" {
+		return settings.Config
+	}
+	if env := os.Getenv("KRB5_CONFIG"); env != "" {
+		return env
+	}
+	return "/etc/krb5.conf"
+}
+
+// ticketCachePath returns the credential cache to read, as kinit writes it
+func ticketCachePath(settings config.Kerberos) string {
+	if settings.TicketCache != "" {
+		return settings.TicketCache
+	}
+	if env := os.Getenv("KRB5CCNAME"); env != "" {
+		return strings.TrimPrefix(env, "FILE:")
+	}
+	return fmt.Sprintf("/tmp/krb5cc_%d", os.Getuid())
+}
@@ -0,0 +1,55 @@
+package auth
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/TFMV/trino-cli/config"
+)
+
+func TestKerberosPaths(t *testing.T) {
+	t.Setenv("KRB5CCNAME", "FILE:/tmp/krb5cc_alice")
+	t.Setenv("KRB5_CONFIG", "/opt/krb5.conf")
+	if got := ticketCachePath(config.Kerberos{}); got != "/tmp/krb5cc_alice" {
+		t.Errorf("ticket cache = %s, want it from $KRB5CCNAME", got)
+	}
+	if got := krb5ConfigPath(config.Kerberos{}); got != "/opt/krb5.conf" {
+		t.Errorf("krb5.conf = %s, want it from $KRB5_CONFIG", got)
+	}
+	settings := config.Kerberos{TicketCache: "/var/cc", Config: "/etc/alt.conf"}
+	if ticketCachePath(settings) != "/var/cc" || krb5ConfigPath(settings) != "/etc/alt.conf" {
+		t.Error("expected configured paths to take precedence over the environment")
+	}
+}
+
+func TestKerberosTransportErrors(t *testing.T) {
+	dir := t.TempDir()
+	conf := filepath.Join(dir, "krb5.conf")
+	if err := os.WriteFile(conf, []byte("[libdefaults]\n  default_realm = EXAMPLE.COM\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		settings config.Kerberos
+		want     string
+	}{
+		{"no ticket", config.Kerberos{Config: conf, TicketCache: filepath.Join(dir, "missing")}, "run kinit"},
+		{"keytab without principal", config.Kerberos{Config: conf, Keytab: filepath.Join(dir, "app.keytab")}, "principal is needed"},
+		{"missing keytab", config.Kerberos{Config: conf, Keytab: filepath.Join(dir, "app.keytab"), Principal: "app@EXAMPLE.COM"}, "failed to load keytab"},
+		{"missing krb5.conf", config.Kerberos{Config: filepath.Join(dir, "none.conf")}, "failed to load"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport := &kerberosTransport{settings: tt.settings, base: http.DefaultTransport}
+			req, _ := http.NewRequest(http.MethodGet, "https://trino.example.com/v1/statement", nil)
+			_, err := transport.RoundTrip(req)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("RoundTrip error = %v, want it to mention %q", err, tt.want)
+			}
+		})
+	}
+}
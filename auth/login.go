@@ -0,0 +1,187 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// pollDelay is how long Login waits before asking the token server again
+// after it fails to answer
+const pollDelay = time.Second
+
+// challenge is where a Trino server sends clients to log in with OAuth2:
+// redirectURL is opened in a browser, and tokenURL polled for the token
+// issued once the user has logged in
+type challenge struct {
+	redirectURL string
+	tokenURL    string
+}
+
+// Login runs Trino's OAuth2 login flow against the server at serverURL,
+// e.g. https://trino.example.com:443. It asks the server where to log in,
+// passes that page to open, and waits until the user has finished there for
+// the token. Cancel ctx to give up waiting.
+func Login(ctx context.Context, client *http.Client, serverURL, user string, open func(url string) error) (Token, error) {
+	c, err := askChallenge(ctx, client, serverURL, user)
+	if err != nil {
+		return Token{}, err
+	}
+	if c.redirectURL != "" {
+		if err := open(c.redirectURL); err != nil {
+			return Token{}, err
+		}
+	}
+	accessToken, err := pollToken(ctx, client, c.tokenURL)
+	if err != nil {
+		return Token{}, err
+	}
+	return NewToken(accessToken), nil
+}
+
+// askChallenge sends the server an unauthenticated statement and returns
+// the OAuth2 challenge of its 401 response
+func askChallenge(ctx context.Context, client *http.Client, serverURL, user string) (challenge, error) {
+	url := strings.TrimRight(serverURL, "/") + "/v1/statement"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, strings.NewReader("SELECT 1"))
+	if err != nil {
+		return challenge{}, err
+	}
+	req.Header.Set("X-Trino-User", user)
+	resp, err := client.Do(req)
+	if err != nil {
+		return challenge{}, fmt.Errorf("failed to reach %s: %w", serverURL, err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode != http.StatusUnauthorized {
+		return challenge{}, fmt.Errorf("%s did not ask for a login (status %s); it may not use OAuth2", serverURL, resp.Status)
+	}
+	for _, header := range resp.Header.Values("WWW-Authenticate") {
+		params, ok := bearerParams(header)
+		if !ok || params["x_token_server"] == "" {
+			continue
+		}
+		return challenge{redirectURL: params["x_redirect_server"], tokenURL: params["x_token_server"]}, nil
+	}
+	return challenge{}, fmt.Errorf("%s does not offer OAuth2 login", serverURL)
+}
+
+// bearerParams parses the parameters of a Bearer WWW-Authenticate challenge,
+// e.g. Bearer x_redirect_server="https://...", x_token_server="https://..."
+func bearerParams(header string) (map[string]string, bool) {
+	scheme, rest, _ := strings.Cut(strings.TrimSpace(header), " ")
+	if !strings.EqualFold(scheme, "Bearer") {
+		return nil, false
+	}
+	params := make(map[string]string)
+	for rest = strings.TrimSpace(rest); rest != ""; rest = strings.TrimLeft(rest, ", ") {
+		key, value, ok := strings.Cut(rest, "=")
+		if !ok {
+			break
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		if strings.HasPrefix(value, `"`) {
+			end := strings.Index(value[1:], `"`)
+			if end < 0 {
+				params[key] = value[1:]
+				break
+			}
+			params[key], rest = value[1:end+1], value[end+2:]
+		} else {
+			params[key], rest, _ = strings.Cut(value, ",")
+		}
+	}
+	return params, true
+}
+
+// tokenResponse is the token server's answer: the token once the user has
+// logged in, where to ask next while they have not, or why it failed
+type tokenResponse struct {
+	Token   string `json:"token"`
+	NextURI string `json:"nextUri"`
+	Error   string `json:"error"`
+}
+
+// pollToken asks the token server for the token until it is issued. The
+// server holds each request open for a while before answering with the
+// next URL to ask.
+func pollToken(ctx context.Context, client *http.Client, url string) (string, error) {
+	for {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return "", err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			if ctx.Err() != nil {
+				return "", ctx.Err()
+			}
+			if err := sleep(ctx, pollDelay); err != nil {
+				return "", err
+			}
+			continue
+		}
+
+		var answer tokenResponse
+		switch {
+		case resp.StatusCode == http.StatusOK:
+			err = json.NewDecoder(resp.Body).Decode(&answer)
+		case resp.StatusCode >= 500:
+			// Busy or restarting; ask again
+		default:
+			err = fmt.Errorf("token server answered %s", resp.Status)
+		}
+		resp.Body.Close()
+		if err != nil {
+			return "", err
+		}
+
+		switch {
+		case answer.Token != "":
+			return answer.Token, nil
+		case answer.Error != "":
+			return "", errors.New("login failed: " + answer.Error)
+		case answer.NextURI != "":
+			url = answer.NextURI
+		default:
+			if err := sleep(ctx, pollDelay); err != nil {
+				return "", err
+			}
+		}
+	}
+}
+
+// sleep waits for d, or until ctx is done
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// OpenBrowser opens url in the desktop's web browser
+func OpenBrowser(url string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", url)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		cmd = exec.Command("xdg-open", url)
+	}
+	return cmd.Start()
+}
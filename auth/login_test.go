@@ -0,0 +1,118 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLogin(t *testing.T) {
+	var server *httptest.Server
+	polls := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/statement", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Trino-User") != "ana" {
+			t.Errorf("statement sent as %q, want ana", r.Header.Get("X-Trino-User"))
+		}
+		w.Header().Add("WWW-Authenticate", `Basic realm="Trino"`)
+		w.Header().Add("WWW-Authenticate", fmt.Sprintf(`Bearer x_redirect_server="%s/oauth2/token/initiate/abc", x_token_server="%s/oauth2/token/abc"`, server.URL, server.URL))
+		w.WriteHeader(http.StatusUnauthorized)
+	})
+	mux.HandleFunc("/oauth2/token/abc", func(w http.ResponseWriter, r *http.Request) {
+		polls++
+		switch polls {
+		case 1:
+			fmt.Fprintf(w, `{"nextUri": "%s/oauth2/token/abc"}`, server.URL)
+		case 2:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			fmt.Fprint(w, `{"token": "issued"}`)
+		}
+	})
+	server = httptest.NewServer(mux)
+	defer server.Close()
+
+	var opened string
+	token, err := Login(context.Background(), server.Client(), server.URL, "ana", func(url string) error {
+		opened = url
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+	if token.AccessToken != "issued" {
+		t.Errorf("token = %q, want issued", token.AccessToken)
+	}
+	if opened != server.URL+"/oauth2/token/initiate/abc" {
+		t.Errorf("opened %q, want the redirect server", opened)
+	}
+	if polls != 3 {
+		t.Errorf("polled the token server %d times, want 3", polls)
+	}
+}
+
+func TestLoginErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		want    string
+	}{
+		{"no login required", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `{"id": "q1"}`)
+		}, "did not ask for a login"},
+		{"password only", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("WWW-Authenticate", `Basic realm="Trino"`)
+			w.WriteHeader(http.StatusUnauthorized)
+		}, "does not offer OAuth2"},
+		{"login refused", func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/token" {
+				fmt.Fprint(w, `{"error": "access denied"}`)
+				return
+			}
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer x_token_server="http://%s/token"`, r.Host))
+			w.WriteHeader(http.StatusUnauthorized)
+		}, "access denied"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(tt.handler)
+			defer server.Close()
+			_, err := Login(context.Background(), server.Client(), server.URL, "ana", func(string) error { return nil })
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Login error = %v, want it to mention %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoginCancelled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			fmt.Fprint(w, `{}`)
+			return
+		}
+		w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer x_token_server="http://%s/token"`, r.Host))
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := Login(ctx, server.Client(), server.URL, "ana", func(string) error { return nil }); err != context.DeadlineExceeded {
+		t.Errorf("Login error = %v, want the deadline", err)
+	}
+}
+
+func TestBearerParams(t *testing.T) {
+	params, ok := bearerParams(`Bearer x_redirect_server="https://a/b?c=d,e", x_token_server=https://t/u`)
+	if !ok || params["x_redirect_server"] != "https://a/b?c=d,e" || params["x_token_server"] != "https://t/u" {
+		t.Errorf("bearerParams = %v, %v", params, ok)
+	}
+	if _, ok := bearerParams(`Basic realm="Trino"`); ok {
+		t.Error("expected a Basic challenge to be skipped")
+	}
+}
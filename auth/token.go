@@ -0,0 +1,113 @@
+package auth
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Token is an OAuth2 access token cached for a profile by trino-cli login
+type Token struct {
+	AccessToken string    `json:"access_token"`
+	Expiry      time.Time `json:"expiry,omitempty"` // Zero when the token does not say
+	Created     time.Time `json:"created"`
+}
+
+// NewToken wraps an access token, reading its expiry from the exp claim when
+// it is a JWT, as Trino's tokens are
+func NewToken(accessToken string) Token {
+	t := Token{AccessToken: accessToken, Created: time.Now()}
+	parts := strings.Split(accessToken, ".")
+	if len(parts) != 3 {
+		return t
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return t
+	}
+	var claims struct {
+		Exp int64 `json:"exp"`
+	}
+	if json.Unmarshal(payload, &claims) == nil && claims.Exp > 0 {
+		t.Expiry = time.Unix(claims.Exp, 0)
+	}
+	return t
+}
+
+// Expired reports whether the token has passed its expiry
+func (t Token) Expired() bool {
+	return !t.Expiry.IsZero() && time.Now().After(t.Expiry)
+}
+
+// tokenPath returns the file caching a profile's token under
+// ~/.trino-cli/tokens
+func tokenPath(profile string) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	name := strings.Map(func(r rune) rune {
+		if r == '-' || r == '_' || r == '.' || r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' {
+			return r
+		}
+		return '_'
+	}, profile)
+	return filepath.Join(home, ".trino-cli", "tokens", name+".json"), nil
+}
+
+// SaveToken caches a profile's token in a file only the user can read
+func SaveToken(profile string, t Token) error {
+	path, err := tokenPath(profile)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create token directory: %w", err)
+	}
+	data, err := json.MarshalIndent(t, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so a failed write keeps the old token
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".token-*")
+	if err != nil {
+		return fmt.Errorf("failed to save token: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to save token: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to save token: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to save token: %w", err)
+	}
+	return nil
+}
+
+// LoadToken returns a profile's cached token. ok is false when it has none.
+func LoadToken(profile string) (t Token, ok bool, err error) {
+	path, err := tokenPath(profile)
+	if err != nil {
+		return Token{}, false, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return Token{}, false, nil
+	}
+	if err != nil {
+		return Token{}, false, fmt.Errorf("failed to read token: %w", err)
+	}
+	if err := json.Unmarshal(data, &t); err != nil {
+		return Token{}, false, fmt.Errorf("failed to parse token %s: %w", path, err)
+	}
+	return t, t.AccessToken != "", nil
+}
@@ -0,0 +1,59 @@
+package auth
+
+import (
+	"encoding/base64"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestNewToken(t *testing.T) {
+	payload := base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"ana","exp":1700000000}`))
+	token := NewToken("header." + payload + ".signature")
+	if !token.Expiry.Equal(time.Unix(1700000000, 0)) {
+		t.Errorf("expiry = %v, want it read from the exp claim", token.Expiry)
+	}
+	if !token.Expired() {
+		t.Error("expected a token past its expiry to be expired")
+	}
+
+	opaque := NewToken("not-a-jwt")
+	if !opaque.Expiry.IsZero() || opaque.Expired() {
+		t.Errorf("opaque token expiry = %v, want none", opaque.Expiry)
+	}
+}
+
+func TestSaveLoadToken(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	if _, ok, err := LoadToken("prod"); ok || err != nil {
+		t.Fatalf("LoadToken before login = %v, %v", ok, err)
+	}
+	saved := Token{AccessToken: "secret", Expiry: time.Now().Add(time.Hour).Truncate(time.Second), Created: time.Now().Truncate(time.Second)}
+	if err := SaveToken("prod", saved); err != nil {
+		t.Fatalf("SaveToken failed: %v", err)
+	}
+
+	loaded, ok, err := LoadToken("prod")
+	if err != nil || !ok {
+		t.Fatalf("LoadToken = %v, %v", ok, err)
+	}
+	if loaded.AccessToken != "secret" || !loaded.Expiry.Equal(saved.Expiry) {
+		t.Errorf("loaded %+v, want %+v", loaded, saved)
+	}
+
+	path, err := tokenPath("prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("token file mode = %v, want 0600", info.Mode().Perm())
+	}
+	if _, ok, _ := LoadToken("dev"); ok {
+		t.Error("expected other profiles to have no token")
+	}
+}
@@ -1,11 +1,14 @@
 package autocomplete
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"sort"
 	"strings"
 	"sync"
 
+	"github.com/TFMV/trino-cli/sqllex"
 	"go.uber.org/zap"
 )
 
@@ -21,6 +24,10 @@ var CommonSQLKeywords = []string{
 	"CURRENT_TIME", "CURRENT_TIMESTAMP", "INTERVAL",
 }
 
+// maxCandidates bounds how many names are collected from the cache per
+// kind of suggestion, so completion stays fast on large catalogs
+const maxCandidates = 1000
+
 // SQLCompletion types represent different categories of SQL suggestions
 type SQLCompletionType int
 
@@ -30,6 +37,9 @@ const (
 	TableName
 	ColumnName
 	Function
+	CatalogName
+	SessionProperty
+	SessionValue
 )
 
 // Suggestion represents a single autocompletion suggestion
@@ -37,9 +47,11 @@ type Suggestion struct {
 	Text       string
 	Type       SQLCompletionType
 	Score      float64 // Higher is better
+	Catalog    string  // Only for catalog/schema/table/column suggestions
 	Schema     string  // Only for table/column suggestions
 	Table      string  // Only for column suggestions
 	DetailText string  // Additional context/details
+	Terminal   bool    // A catalog or schema that ends the name rather than qualifying one
 }
 
 // AutocompleteService provides SQL autocompletion functionality
@@ -51,9 +63,19 @@ type AutocompleteService struct {
 	logger         *zap.Logger
 	mu             sync.RWMutex
 	maxSuggestions int
+	sessionCatalog string          // Catalog selected by the profile or the last USE
+	sessionSchema  string          // Schema selected by the profile or the last USE
+	refreshCtx     context.Context // Bounds on-demand refreshes, set by Start
+	historyTables  map[string]int  // Queries each table appears in, by lower-cased name
+	historyColumns map[string]int  // Queries each other name appears in, by lower-cased name
+
+	sessionMu sync.Mutex    // Guards session, which is filled in under ac.mu's read lock
+	session   *sessionIndex // Names of the session catalog and schema, read on demand
 }
 
-// NewAutocompleteService creates a new autocomplete service
+// NewAutocompleteService creates a new autocomplete service that caches
+// metadata under cacheDir. db is a Trino connection to introspect, or nil
+// to complete only from the cache and schemas added with AddSchema.
 func NewAutocompleteService(db *sql.DB, cacheDir string, logger *zap.Logger) (*AutocompleteService, error) {
 	if logger == nil {
 		var err error
@@ -89,23 +111,40 @@ func NewAutocompleteService(db *sql.DB, cacheDir string, logger *zap.Logger) (*A
 	}, nil
 }
 
-// Start initializes the service and begins background refresh
-func (ac *AutocompleteService) Start() error {
+// Start initializes the service and begins background refresh, which runs
+// until ctx is cancelled or Stop is called
+func (ac *AutocompleteService) Start(ctx context.Context) error {
 	// Load initial metadata from cache
 	if err := ac.cache.LoadCache(); err != nil {
 		ac.logger.Warn("Failed to initialize from cache", zap.Error(err))
 		// Non-fatal, we'll refresh from Trino
 	}
+	ac.restoreUsage()
+
+	// Without a connection, suggestions come from the cache and the
+	// schemas added with AddSchema
+	if ac.db == nil {
+		return nil
+	}
+
+	// Load the server's functions, fetching them only for a new version
+	if err := ac.introspector.RefreshFunctions(ctx); err != nil {
+		ac.logger.Warn("Failed to load functions", zap.Error(err))
+		// Non-fatal, the built-in function list still works
+	}
 
 	// Do an initial refresh from Trino
-	if err := ac.introspector.RefreshAll(); err != nil {
+	if err := ac.introspector.RefreshAll(ctx); err != nil {
 		ac.logger.Error("Initial schema refresh failed", zap.Error(err))
 		// Return this error as we need metadata for autocomplete to work
 		return fmt.Errorf("initial schema refresh failed: %w", err)
 	}
 
 	// Start background refresh
-	ac.introspector.StartBackgroundRefresh()
+	ac.introspector.StartBackgroundRefresh(ctx)
+	ac.mu.Lock()
+	ac.refreshCtx = ctx
+	ac.mu.Unlock()
 	return nil
 }
 
@@ -114,6 +153,19 @@ func (ac *AutocompleteService) Stop() {
 	ac.introspector.StopBackgroundRefresh()
 }
 
+// Close stops the service and closes its cache
+func (ac *AutocompleteService) Close() error {
+	ac.Stop()
+	return ac.cache.Close()
+}
+
+// AddSchema stores a schema's tables and columns in the cache, replacing
+// what was cached for it, so programs that know their schema, or have no
+// Trino connection, can complete from it
+func (ac *AutocompleteService) AddSchema(metadata SchemaMetadata) error {
+	return ac.cache.StoreSchema(metadata)
+}
+
 // SetMaxSuggestions sets the maximum number of suggestions to return
 func (ac *AutocompleteService) SetMaxSuggestions(max int) {
 	ac.mu.Lock()
@@ -121,8 +173,10 @@ func (ac *AutocompleteService) SetMaxSuggestions(max int) {
 	ac.maxSuggestions = max
 }
 
-// GetCompletions returns suggestions for the given SQL input and cursor position
-func (ac *AutocompleteService) GetCompletions(sql string, cursorPos int) ([]Suggestion, error) {
+// GetCompletions returns suggestions for the word at cursorPos, a byte
+// offset in sql, best first. It stops early, returning ctx's error, once
+// ctx is cancelled, e.g. because more was typed.
+func (ac *AutocompleteService) GetCompletions(ctx context.Context, sql string, cursorPos int) ([]Suggestion, error) {
 	ac.mu.RLock()
 	defer ac.mu.RUnlock()
 
@@ -135,47 +189,48 @@ func (ac *AutocompleteService) GetCompletions(sql string, cursorPos int) ([]Sugg
 	}
 
 	// Get the word at cursor
-	word, wordStart := getWordAtCursor(sql, cursorPos)
+	word, wordStart := WordAtCursor(sql, cursorPos)
 	ac.logger.Debug("Getting completions",
 		zap.String("word", word),
 		zap.Int("wordStart", wordStart),
 		zap.Int("cursorPos", cursorPos))
 
 	// Get context to determine what type of completions to show
-	ctx := analyzeContext(sql, cursorPos)
-
-	// Use the new contextual suggestions function to get more relevant suggestions
-	contextualSuggestions := GetContextualSuggestions(sql, cursorPos, ac.cache)
+	sc := analyzeContext(sql, cursorPos)
+	if sc.literal {
+		// Nothing to complete inside a string or comment
+		return nil, nil
+	}
+	ac.refreshCompleted(sc)
 
-	// Convert string suggestions to Suggestion objects
+	// Suggestions for the clause and qualifier at the cursor
 	var suggestions []Suggestion
-	if len(contextualSuggestions) > 0 {
-		for _, text := range contextualSuggestions {
-			// Determine suggestion type based on context
-			suggestionType := Keyword
-			switch ctx.completionType {
-			case SchemaName:
-				suggestionType = SchemaName
-			case TableName:
-				suggestionType = TableName
-			case ColumnName:
-				suggestionType = ColumnName
-			case Function:
-				suggestionType = Function
-			}
-
-			score := calculateScore(word, text)
-			suggestions = append(suggestions, Suggestion{
-				Text:  text,
-				Type:  suggestionType,
-				Score: score,
-			})
+	if strings.TrimSpace(sql[:cursorPos]) != "" {
+		suggestions = contextualSuggestions(sc, word, ac.cache)
+		for i := range suggestions {
+			suggestions[i].Score = calculateScore(word, suggestions[i].Text)
 		}
-	} else {
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if len(suggestions) == 0 {
 		// Fall back to the original method if contextual suggestions are empty
-		suggestions = ac.getSuggestionsByContext(word, ctx)
+		suggestions = ac.getSuggestionsByContext(word, sc)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
 	}
 
+	// Rank objects from the active catalog/schema first
+	ac.applySessionPriority(suggestions)
+
+	// Then what the user has picked before
+	ac.applyUsage(suggestions)
+
+	// And the tables and columns the user's queries work with
+	ac.applyHistory(suggestions)
+
 	// Sort by score and limit results
 	sortSuggestionsByScore(suggestions)
 	if len(suggestions) > ac.maxSuggestions {
@@ -185,6 +240,42 @@ func (ac *AutocompleteService) GetCompletions(sql string, cursorPos int) ([]Sugg
 	return suggestions, nil
 }
 
+// refreshCompleted has the schemas that the names being completed are in
+// refreshed on demand, so objects created since the last refresh appear.
+// Callers must hold ac.mu.
+func (ac *AutocompleteService) refreshCompleted(sc sqlContext) {
+	if ac.refreshCtx == nil {
+		return // Not connected
+	}
+	refresh := func(catalog, schema string) {
+		if catalog == "" {
+			catalog = ac.sessionCatalog
+		}
+		if catalog != "" && schema != "" {
+			ac.introspector.RefreshOnDemand(ac.refreshCtx, catalog, schema)
+		}
+	}
+
+	switch sc.completionType {
+	case TableName:
+		refresh(sc.catalog, sc.schema)
+	case ColumnName:
+		if sc.schema != "" {
+			refresh(sc.catalog, sc.schema)
+		}
+		for _, ref := range sc.tables {
+			if len(ref.names) == 0 {
+				continue
+			}
+			catalog, schema, _ := splitQualifier(ref.names)
+			if schema == "" {
+				schema = ac.sessionSchema
+			}
+			refresh(catalog, schema)
+		}
+	}
+}
+
 // Get suggestions based on SQL context
 func (ac *AutocompleteService) getSuggestionsByContext(prefix string, ctx sqlContext) []Suggestion {
 	var suggestions []Suggestion
@@ -203,7 +294,7 @@ func (ac *AutocompleteService) getSuggestionsByContext(prefix string, ctx sqlCon
 		var tableSuggestions []Suggestion
 		if ctx.schema != "" {
 			// If we know the schema, only get tables from that schema
-			tableSuggestions = ac.getTableSuggestions(prefix, ctx.schema)
+			tableSuggestions = ac.getTableSuggestions(prefix, ctx.catalog, ctx.schema)
 		} else {
 			// Otherwise get all tables
 			tableSuggestions = ac.getAllTableSuggestions(prefix)
@@ -212,9 +303,12 @@ func (ac *AutocompleteService) getSuggestionsByContext(prefix string, ctx sqlCon
 
 	case ColumnName:
 		var columnSuggestions []Suggestion
-		if ctx.table != "" {
+		if ctx.table != "" && ctx.schema == "" {
+			// The table could be in any schema
+			columnSuggestions = ac.getTableColumnSuggestions(prefix, ctx.table)
+		} else if ctx.table != "" {
 			// If we know the table, only get columns from that table
-			columnSuggestions = ac.getColumnSuggestions(prefix, ctx.schema, ctx.table)
+			columnSuggestions = ac.getColumnSuggestions(prefix, ctx.catalog, ctx.schema, ctx.table)
 		} else if ctx.schema != "" {
 			// If we only know the schema, get all columns from that schema
 			columnSuggestions = ac.getAllColumnSuggestionsForSchema(prefix, ctx.schema)
@@ -236,6 +330,9 @@ func (ac *AutocompleteService) getSuggestionsByContext(prefix string, ctx sqlCon
 // getKeywordSuggestions returns SQL keyword suggestions
 func (ac *AutocompleteService) getKeywordSuggestions(prefix string) []Suggestion {
 	words := ac.keywordTrie.GetSuggestions(strings.ToUpper(prefix), 10) // Get top 10 keyword matches
+	if len(prefix) >= minTypoLength {
+		words = appendMissing(words, ac.keywordTrie.GetFuzzyMatches(prefix, 1, 10))
+	}
 	suggestions := make([]Suggestion, 0, len(words))
 
 	for _, word := range words {
@@ -252,7 +349,7 @@ func (ac *AutocompleteService) getKeywordSuggestions(prefix string) []Suggestion
 
 // getSchemaSuggestions returns schema name suggestions
 func (ac *AutocompleteService) getSchemaSuggestions(prefix string) []Suggestion {
-	schemas, err := ac.cache.GetSchemas()
+	schemas, err := ac.cache.GetSchemas("")
 	if err != nil {
 		ac.logger.Error("Failed to get schemas from cache", zap.Error(err))
 		return nil
@@ -273,9 +370,10 @@ func (ac *AutocompleteService) getSchemaSuggestions(prefix string) []Suggestion
 	return suggestions
 }
 
-// getTableSuggestions returns table suggestions for a specific schema
-func (ac *AutocompleteService) getTableSuggestions(prefix, schema string) []Suggestion {
-	tables, err := ac.cache.GetTables(schema)
+// getTableSuggestions returns table suggestions for a specific schema, in
+// any catalog when catalog is empty
+func (ac *AutocompleteService) getTableSuggestions(prefix, catalog, schema string) []Suggestion {
+	tables, err := ac.cache.GetTables(catalog, schema)
 	if err != nil {
 		ac.logger.Error("Failed to get tables from cache",
 			zap.String("schema", schema),
@@ -291,8 +389,9 @@ func (ac *AutocompleteService) getTableSuggestions(prefix, schema string) []Sugg
 				Text:       table,
 				Type:       TableName,
 				Score:      score,
+				Catalog:    catalog,
 				Schema:     schema,
-				DetailText: fmt.Sprintf("%s.%s", schema, table),
+				DetailText: qualifiedName(catalog, schema, table),
 			})
 		}
 	}
@@ -302,25 +401,31 @@ func (ac *AutocompleteService) getTableSuggestions(prefix, schema string) []Sugg
 
 // getAllTableSuggestions returns table suggestions across all schemas
 func (ac *AutocompleteService) getAllTableSuggestions(prefix string) []Suggestion {
-	var suggestions []Suggestion
-
-	schemas, err := ac.cache.GetSchemas()
+	tables, err := ac.cache.FindTables(prefix, maxCandidates)
 	if err != nil {
-		ac.logger.Error("Failed to get schemas from cache", zap.Error(err))
+		ac.logger.Error("Failed to get tables from cache", zap.Error(err))
 		return nil
 	}
 
-	for _, schema := range schemas {
-		schemaSuggestions := ac.getTableSuggestions(prefix, schema)
-		suggestions = append(suggestions, schemaSuggestions...)
+	suggestions := make([]Suggestion, 0, len(tables))
+	for _, table := range tables {
+		suggestions = append(suggestions, Suggestion{
+			Text:       table.Name,
+			Type:       TableName,
+			Score:      calculateScore(prefix, table.Name),
+			Catalog:    table.Catalog,
+			Schema:     table.Schema,
+			DetailText: qualifiedName(table.Catalog, table.Schema, table.Name),
+		})
 	}
 
 	return suggestions
 }
 
-// getColumnSuggestions returns column suggestions for a specific table
-func (ac *AutocompleteService) getColumnSuggestions(prefix, schema, table string) []Suggestion {
-	columns, err := ac.cache.GetColumns(schema, table)
+// getColumnSuggestions returns column suggestions for a specific table, in
+// any catalog when catalog is empty
+func (ac *AutocompleteService) getColumnSuggestions(prefix, catalog, schema, table string) []Suggestion {
+	columns, err := ac.cache.GetColumns(catalog, schema, table)
 	if err != nil {
 		ac.logger.Error("Failed to get columns from cache",
 			zap.String("schema", schema),
@@ -333,315 +438,446 @@ func (ac *AutocompleteService) getColumnSuggestions(prefix, schema, table string
 	for _, col := range columns {
 		if strings.HasPrefix(strings.ToLower(col.Name), strings.ToLower(prefix)) {
 			score := calculateScore(prefix, col.Name)
-			suggestions = append(suggestions, Suggestion{
-				Text:       col.Name,
-				Type:       ColumnName,
-				Score:      score,
-				Schema:     schema,
-				Table:      table,
-				DetailText: fmt.Sprintf("%s.%s.%s (%s)", schema, table, col.Name, col.DataType),
-			})
+			suggestions = append(suggestions, columnSuggestion(col))
+			suggestions[len(suggestions)-1].Score = score
 		}
 	}
 
 	return suggestions
 }
 
-// getAllColumnSuggestionsForSchema returns column suggestions across all tables in a schema
-func (ac *AutocompleteService) getAllColumnSuggestionsForSchema(prefix, schema string) []Suggestion {
+// getTableColumnSuggestions returns column suggestions for tables with the
+// given name in any schema
+func (ac *AutocompleteService) getTableColumnSuggestions(prefix, table string) []Suggestion {
 	var suggestions []Suggestion
 
-	tables, err := ac.cache.GetTables(schema)
+	schemas, err := ac.cache.GetSchemas("")
 	if err != nil {
-		ac.logger.Error("Failed to get tables from cache",
-			zap.String("schema", schema),
-			zap.Error(err))
+		ac.logger.Error("Failed to get schemas from cache", zap.Error(err))
 		return nil
 	}
 
-	for _, table := range tables {
-		tableSuggestions := ac.getColumnSuggestions(prefix, schema, table)
-		suggestions = append(suggestions, tableSuggestions...)
+	for _, schema := range schemas {
+		suggestions = append(suggestions, ac.getColumnSuggestions(prefix, "", schema, table)...)
 	}
 
 	return suggestions
 }
 
-// getAllColumnSuggestions returns column suggestions across all schemas and tables
-func (ac *AutocompleteService) getAllColumnSuggestions(prefix string) []Suggestion {
-	var suggestions []Suggestion
-
-	schemas, err := ac.cache.GetSchemas()
+// getAllColumnSuggestionsForSchema returns column suggestions across all tables in a schema
+func (ac *AutocompleteService) getAllColumnSuggestionsForSchema(prefix, schema string) []Suggestion {
+	columns, err := ac.cache.FindColumns(schema, prefix, maxCandidates)
 	if err != nil {
-		ac.logger.Error("Failed to get schemas from cache", zap.Error(err))
+		ac.logger.Error("Failed to get columns from cache",
+			zap.String("schema", schema),
+			zap.Error(err))
 		return nil
 	}
 
-	for _, schema := range schemas {
-		schemaSuggestions := ac.getAllColumnSuggestionsForSchema(prefix, schema)
-		suggestions = append(suggestions, schemaSuggestions...)
+	suggestions := make([]Suggestion, 0, len(columns))
+	for _, col := range columns {
+		suggestion := columnSuggestion(col)
+		suggestion.Score = calculateScore(prefix, col.Name)
+		suggestions = append(suggestions, suggestion)
 	}
 
 	return suggestions
 }
 
+// getAllColumnSuggestions returns column suggestions across all schemas and tables
+func (ac *AutocompleteService) getAllColumnSuggestions(prefix string) []Suggestion {
+	return ac.getAllColumnSuggestionsForSchema(prefix, "")
+}
+
 // getFunctionSuggestions returns SQL function suggestions
 func (ac *AutocompleteService) getFunctionSuggestions(prefix string) []Suggestion {
-	// Common SQL functions
-	functions := []string{
-		"COUNT", "SUM", "AVG", "MIN", "MAX", "STDDEV", "VARIANCE",
-		"LOWER", "UPPER", "CONCAT", "SUBSTRING", "TRIM", "LENGTH",
-		"CAST", "ROUND", "FLOOR", "CEILING", "ABS", "MOD",
-		"CURRENT_DATE", "CURRENT_TIME", "CURRENT_TIMESTAMP", "EXTRACT",
+	suggestions := cachedFunctionSuggestions(ac.cache, prefix)
+	for i := range suggestions {
+		suggestions[i].Score = calculateScore(prefix, suggestions[i].Text)
 	}
+	return suggestions
+}
 
-	suggestions := make([]Suggestion, 0)
-	for _, fn := range functions {
-		if strings.HasPrefix(strings.ToUpper(fn), strings.ToUpper(prefix)) {
-			score := calculateScore(prefix, fn)
-			suggestions = append(suggestions, Suggestion{
-				Text:  fn,
-				Type:  Function,
-				Score: score,
-			})
+// cachedFunctionSuggestions suggests the server's functions, or the
+// built-in list before they are loaded
+func cachedFunctionSuggestions(cache *SchemaCache, prefix string) []Suggestion {
+	if cache != nil {
+		if functions := cache.GetFunctions(); len(functions) > 0 {
+			return functionSuggestions(functions, prefix)
 		}
 	}
 
+	var suggestions []Suggestion
+	for _, fn := range builtinFunctions {
+		if strings.HasPrefix(strings.ToUpper(fn), strings.ToUpper(prefix)) {
+			suggestions = append(suggestions, Suggestion{Text: fn, Type: Function})
+		}
+	}
 	return suggestions
 }
 
 // sqlContext represents the SQL context at a given position
 type sqlContext struct {
 	completionType SQLCompletionType
-	schema         string // Set if we know the schema
-	table          string // Set if we know the table
+	catalog        string      // Set if we know the catalog
+	schema         string      // Set if we know the schema
+	table          string      // Set if we know the table
+	clause         sqlClause   // Clause the cursor is in
+	previous       string      // Upper-cased token before the name at the cursor
+	literal        bool        // The cursor is inside a string literal or comment
+	tables         []*tableRef // Tables in scope at the cursor, innermost first
+	property       string      // Session property whose value is completed
 }
 
-// analyzeContext determines what type of completion to show based on SQL context
+// analyzeContext determines what type of completion to show by parsing the
+// statement up to the cursor
 func analyzeContext(sql string, cursorPos int) sqlContext {
-	// This is a simplified implementation
-	// A full implementation would use a SQL parser
+	ctx := sqlContext{completionType: Keyword}
 
-	// Default to keyword completion
-	ctx := sqlContext{
-		completionType: Keyword,
+	tokens := sqllex.Tokenize(sql)
+	n := 0 // Tokens before the cursor
+	for n < len(tokens) && tokens[n].Start < cursorPos {
+		n++
 	}
-
-	// Check for schema completion (e.g., "SELECT * FROM sch")
-	fromMatch := strings.LastIndex(sql[:cursorPos], "FROM ")
-	if fromMatch != -1 {
-		afterFrom := sql[fromMatch+5 : cursorPos]
-		if !strings.Contains(afterFrom, " ") {
-			ctx.completionType = SchemaName
+	rest := n // Tokens after the name at the cursor
+	if n > 0 {
+		last := tokens[n-1]
+		if (last.Kind == sqllex.String || last.Kind == sqllex.Comment || last.Kind == sqllex.Placeholder) && last.Contains(cursorPos) {
+			ctx.literal = true
 			return ctx
 		}
+		if last.IsName() && last.End >= cursorPos {
+			n-- // The word being typed
+		}
 	}
 
-	// Check for table completion (e.g., "SELECT * FROM schema.")
-	dotMatch := strings.LastIndex(sql[:cursorPos], ".")
-	if dotMatch != -1 && dotMatch < cursorPos-1 {
-		beforeDot := sql[:dotMatch]
-		lastSpaceBeforeDot := strings.LastIndex(beforeDot, " ")
-		if lastSpaceBeforeDot != -1 {
-			potentialSchema := strings.TrimSpace(beforeDot[lastSpaceBeforeDot:])
-			ctx.schema = potentialSchema
-			ctx.completionType = TableName
-			return ctx
-		}
+	// Walk back over a qualifier such as "schema." or "alias."
+	var qualifier []string
+	for n >= 2 && tokens[n-1].Is(".") && tokens[n-2].IsName() {
+		qualifier = append([]string{identifierName(tokens[n-2])}, qualifier...)
+		n -= 2
 	}
 
-	// Check for column completion after SELECT
-	selectMatch := strings.LastIndex(sql[:cursorPos], "SELECT ")
-	if selectMatch != -1 {
-		afterSelect := sql[selectMatch+7 : cursorPos]
-		if !strings.Contains(afterSelect, "FROM") && !strings.Contains(afterSelect, " WHERE ") {
-			ctx.completionType = ColumnName
-			return ctx
+	p := newParser()
+	for _, tok := range tokens[:n] {
+		p.step(tok)
+	}
+	ctx.clause = p.scope.clause
+	ctx.previous = strings.ToUpper(p.last.Text)
+	operand := p.scope.operand
+
+	// The rest of the statement names tables the cursor can refer to too,
+	// as in "SELECT o.| FROM orders o"
+	at := p.scope
+	for _, tok := range tokens[rest:] {
+		if tok.Is(";") {
+			break
 		}
+		p.step(tok)
 	}
+	ctx.tables = at.visibleTables()
 
-	// Check for column completion after WHERE
-	whereMatch := strings.LastIndex(sql[:cursorPos], "WHERE ")
-	if whereMatch != -1 {
-		ctx.completionType = ColumnName
+	if statementContext(&ctx, tokens[:n], qualifier) {
 		return ctx
 	}
 
+	switch {
+	case len(qualifier) > 0 && ctx.clause.tableClause():
+		// "schema." or "catalog.", then "catalog.schema."
+		ctx.completionType = TableName
+		ctx.catalog, ctx.schema, _ = splitQualifier(append(qualifier, ""))
+	case len(qualifier) > 0:
+		ctx.completionType = ColumnName
+		ctx.catalog, ctx.schema, ctx.table = splitQualifier(qualifier)
+		if len(qualifier) > 1 {
+			break
+		}
+		if ref := resolveTable(ctx.tables, ctx.table); ref != nil && ref.table() != "" {
+			// An alias, or a table qualified with its schema in the FROM clause
+			ctx.catalog, ctx.schema, ctx.table = splitQualifier(ref.names)
+		}
+	case !operand:
+		// A name or value just ended, so a keyword or operator follows
+	case ctx.clause.tableClause():
+		ctx.completionType = TableName
+	case ctx.clause.columnClause():
+		ctx.completionType = ColumnName
+	}
 	return ctx
 }
 
+// splitQualifier takes the catalog, schema and table from the last three
+// parts of a qualified table name, leaving any missing parts empty
+func splitQualifier(names []string) (catalog, schema, table string) {
+	part := func(i int) string {
+		if i < 0 {
+			return ""
+		}
+		return names[i]
+	}
+	n := len(names)
+	return part(n - 3), part(n - 2), part(n - 1)
+}
+
+// qualifiedName joins the non-empty parts of a name with dots
+func qualifiedName(parts ...string) string {
+	var nonEmpty []string
+	for _, part := range parts {
+		if part != "" {
+			nonEmpty = append(nonEmpty, part)
+		}
+	}
+	return strings.Join(nonEmpty, ".")
+}
+
+// identifierName returns the name a word or quoted identifier token refers
+// to. Trino folds unquoted identifiers to lower case.
+func identifierName(tok sqllex.Token) string {
+	if tok.Kind == sqllex.Quoted {
+		if tok.Open {
+			return strings.ReplaceAll(tok.Text[1:], `""`, `"`)
+		}
+		return unquoteIdentifier(tok.Text)
+	}
+	return strings.ToLower(tok.Text)
+}
+
 // GetContextualSuggestions returns suggestions based on the SQL query context
-// It analyzes the query and cursor position to provide more relevant suggestions
+// It parses the query up to the cursor to tell which clause the cursor is in
 func GetContextualSuggestions(query string, cursorPos int, cache *SchemaCache) []string {
-	// Only look at query before cursor
-	queryBeforeCursor := query
-	if cursorPos < len(query) {
-		queryBeforeCursor = query[:cursorPos]
+	if cursorPos > len(query) {
+		cursorPos = len(query)
 	}
-
-	tokens := strings.Fields(queryBeforeCursor)
-	if len(tokens) == 0 {
+	if strings.TrimSpace(query[:cursorPos]) == "" {
 		return nil
 	}
 
-	lastToken := tokens[len(tokens)-1]
-	lastTokenUpper := strings.ToUpper(lastToken)
+	ctx := analyzeContext(query, cursorPos)
+	if ctx.literal {
+		return nil
+	}
 
 	// Get the word at cursor for prefix matching
-	word, _ := getWordAtCursor(query, cursorPos)
+	word, _ := WordAtCursor(query, cursorPos)
 
+	suggestions := contextualSuggestions(ctx, word, cache)
+	texts := make([]string, len(suggestions))
+	for i, s := range suggestions {
+		texts[i] = s.Text
+	}
+	return texts
+}
+
+// contextualSuggestions returns the unscored suggestions that fit ctx and
+// start with word. Each level of a dotted name completes the next one:
+// catalogs and schemas lead to tables, and tables to their columns.
+func contextualSuggestions(ctx sqlContext, word string, cache *SchemaCache) []Suggestion {
 	// Default limit for suggestions
 	limit := 50
 
-	switch lastTokenUpper {
-	case "SELECT":
-		// After SELECT, suggest columns and functions
-		columns, err := cache.GetAllColumns()
-		if err != nil {
-			return nil
+	var candidates []Suggestion
+	add := func(texts []string, typ SQLCompletionType) {
+		for _, text := range texts {
+			candidates = append(candidates, Suggestion{Text: text, Type: typ})
 		}
-
-		// Add SQL functions that are commonly used in SELECT
-		selectFunctions := []string{
-			"COUNT", "SUM", "AVG", "MIN", "MAX", "DISTINCT", "CAST", "COALESCE",
-			"NULLIF", "EXTRACT", "CURRENT_DATE", "CURRENT_TIME", "CURRENT_TIMESTAMP",
+	}
+	addCandidates := func(names []candidate, typ SQLCompletionType) {
+		for _, name := range names {
+			candidates = append(candidates, Suggestion{Text: name.Name, Type: typ, Catalog: name.Catalog, Schema: name.Schema})
 		}
+	}
 
-		// Combine columns and functions
-		suggestions := append(columns, selectFunctions...)
+	switch {
+	case ctx.previous == "ORDER" || ctx.previous == "GROUP":
+		// The next token should be "BY"
+		return []Suggestion{{Text: "BY", Type: Keyword}}
 
-		// Filter by prefix if there is one
-		if word != "" {
-			var filtered []string
-			for _, s := range suggestions {
-				if strings.HasPrefix(strings.ToLower(s), strings.ToLower(word)) {
-					filtered = append(filtered, s)
-				}
-			}
-			return filtered
-		}
+	case ctx.completionType == SessionProperty:
+		candidates = sessionPropertySuggestions(cache, ctx.catalog)
 
-		return suggestions
+	case ctx.completionType == SessionValue:
+		// Values are suggested whatever has been typed of them
+		return sessionValueSuggestions(cache, ctx.property)
 
-	case "FROM":
-		// After FROM, suggest tables and schemas
-		tables, err := cache.GetAllTables()
+	case ctx.completionType == CatalogName:
+		// The catalog of SHOW SCHEMAS
+		catalogs, err := cache.GetCatalogs()
 		if err != nil {
 			return nil
 		}
-
-		// Also include schema-qualified tables
-		schemaQualifiedTables, err := cache.GetAllSchemaQualifiedTables()
-		if err == nil {
-			tables = append(tables, schemaQualifiedTables...)
+		for _, catalog := range catalogs {
+			candidates = append(candidates, Suggestion{Text: catalog, Type: CatalogName, Terminal: true})
 		}
 
-		// Get schemas too
-		schemas, err := cache.GetSchemas()
-		if err == nil {
-			tables = append(tables, schemas...)
+	case ctx.completionType == SchemaName:
+		// The schema of SHOW TABLES, or a catalog to qualify it with
+		schemas, err := cache.GetSchemas(ctx.catalog)
+		if err != nil {
+			return nil
 		}
-
-		// Filter by prefix if there is one
-		if word != "" {
-			var filtered []string
-			for _, s := range tables {
-				if strings.HasPrefix(strings.ToLower(s), strings.ToLower(word)) {
-					filtered = append(filtered, s)
-				}
+		for _, schema := range schemas {
+			candidates = append(candidates, Suggestion{
+				Text:       schema,
+				Type:       SchemaName,
+				Catalog:    ctx.catalog,
+				DetailText: qualifiedName(ctx.catalog, schema),
+				Terminal:   true,
+			})
+		}
+		if ctx.catalog == "" {
+			if catalogs, err := cache.GetCatalogs(); err == nil {
+				add(catalogs, CatalogName)
 			}
-			return filtered
 		}
 
-		return tables
-
-	case "JOIN":
-		// After JOIN, suggest tables
-		tables, err := cache.GetAllTables()
+	case ctx.completionType == TableName && ctx.schema != "":
+		// After "catalog.schema." or "schema.", suggest that schema's tables
+		tables, err := cache.GetTables(ctx.catalog, ctx.schema)
 		if err != nil {
 			return nil
 		}
-
-		// Also include schema-qualified tables
-		schemaQualifiedTables, err := cache.GetAllSchemaQualifiedTables()
-		if err == nil {
-			tables = append(tables, schemaQualifiedTables...)
+		for _, table := range tables {
+			candidates = append(candidates, Suggestion{
+				Text:       table,
+				Type:       TableName,
+				Catalog:    ctx.catalog,
+				Schema:     ctx.schema,
+				DetailText: qualifiedName(ctx.catalog, ctx.schema, table),
+			})
 		}
 
-		// Filter by prefix if there is one
-		if word != "" {
-			var filtered []string
-			for _, s := range tables {
-				if strings.HasPrefix(strings.ToLower(s), strings.ToLower(word)) {
-					filtered = append(filtered, s)
-				}
+		// A single qualifier may name a catalog instead, so add its schemas
+		if ctx.catalog == "" {
+			schemas, _ := cache.GetSchemas(ctx.schema)
+			for _, schema := range schemas {
+				candidates = append(candidates, Suggestion{
+					Text:       schema,
+					Type:       SchemaName,
+					Catalog:    ctx.schema,
+					DetailText: qualifiedName(ctx.schema, schema),
+				})
 			}
-			return filtered
 		}
 
-		return tables
-
-	case "WHERE", "AND", "OR", "ON":
-		// After WHERE/AND/OR/ON, suggest columns
-		columns, err := cache.GetAllColumns()
+	case ctx.completionType == TableName:
+		// Suggest tables, schema-qualified tables and, after FROM, schemas
+		// and catalogs to qualify a table with
+		tables, err := cache.allTables(word, maxCandidates)
 		if err != nil {
 			return nil
 		}
-
-		// Filter by prefix if there is one
-		if word != "" {
-			var filtered []string
-			for _, s := range columns {
-				if strings.HasPrefix(strings.ToLower(s), strings.ToLower(word)) {
-					filtered = append(filtered, s)
-				}
+		addCandidates(tables, TableName)
+		if schemaQualifiedTables, err := cache.allSchemaQualifiedTables(word, maxCandidates); err == nil {
+			addCandidates(schemaQualifiedTables, TableName)
+		}
+		if ctx.clause == clauseFrom {
+			if schemas, err := cache.GetSchemas(""); err == nil {
+				add(schemas, SchemaName)
+			}
+			if catalogs, err := cache.GetCatalogs(); err == nil {
+				add(catalogs, CatalogName)
 			}
-			return filtered
 		}
 
-		return columns
+	case ctx.completionType == ColumnName && ctx.table != "":
+		// After "table.", suggest that table's columns
+		for _, col := range tableColumns(cache, ctx.catalog, ctx.schema, ctx.table) {
+			candidates = append(candidates, columnSuggestion(col))
+		}
 
-	case "ORDER", "GROUP":
-		// The next token should be "BY"
-		return []string{"BY"}
-
-	case "BY":
-		// After ORDER BY or GROUP BY, suggest columns
-		// Check if the token before "BY" is "ORDER" or "GROUP"
-		if len(tokens) >= 2 {
-			prevToken := strings.ToUpper(tokens[len(tokens)-2])
-			if prevToken == "ORDER" || prevToken == "GROUP" {
-				columns, err := cache.GetAllColumns()
-				if err != nil {
-					return nil
-				}
-
-				// Filter by prefix if there is one
-				if word != "" {
-					var filtered []string
-					for _, s := range columns {
-						if strings.HasPrefix(strings.ToLower(s), strings.ToLower(word)) {
-							filtered = append(filtered, s)
-						}
-					}
-					return filtered
-				}
-
-				return columns
+	case ctx.completionType == ColumnName:
+		// Columns of the tables the statement refers to, or of every table
+		// when none of them is known
+		if scoped := referencedColumns(cache, ctx.tables); len(scoped) > 0 {
+			candidates = append(candidates, scoped...)
+		} else {
+			columns, err := cache.allColumns(word, maxCandidates)
+			if err != nil {
+				return nil
 			}
+			addCandidates(columns, ColumnName)
 		}
 
-		// Default to general suggestions
-		return cache.GetSuggestions(word, limit)
+		if ctx.clause == clauseSelect {
+			// Add the functions that can be called in SELECT
+			candidates = append(candidates, cachedFunctionSuggestions(cache, word)...)
+		}
 
 	default:
-		// For other contexts, provide general suggestions
-		return cache.GetSuggestions(word, limit)
+		// For other contexts, provide general suggestions, tolerating a
+		// typo once the word is long enough to tell
+		words := cache.GetSuggestions(word, limit)
+		if len(word) >= minTypoLength {
+			words = appendMissing(words, cache.GetFuzzyMatches(word, 1, limit))
+		}
+		add(words, Keyword)
+		return candidates
+	}
+
+	var filtered []Suggestion
+	for _, s := range candidates {
+		if matchesWord(word, s.Text) {
+			filtered = append(filtered, s)
+		}
+	}
+	return filtered
+}
+
+// columnSuggestion describes a cached column as a suggestion
+func columnSuggestion(col ColumnMetadata) Suggestion {
+	return Suggestion{
+		Text:       col.Name,
+		Type:       ColumnName,
+		Catalog:    col.Catalog,
+		Schema:     col.Schema,
+		Table:      col.Table,
+		DetailText: fmt.Sprintf("%s (%s)", qualifiedName(col.Catalog, col.Schema, col.Table, col.Name), col.DataType),
+	}
+}
+
+// referencedColumns suggests the cached columns of tables, once per name,
+// so the innermost table's column wins. Subqueries and tables missing from
+// the cache contribute nothing.
+func referencedColumns(cache *SchemaCache, tables []*tableRef) []Suggestion {
+	var suggestions []Suggestion
+	seen := make(map[string]bool)
+	for _, ref := range tables {
+		if ref.table() == "" {
+			continue
+		}
+		catalog, schema, table := splitQualifier(ref.names)
+		for _, col := range tableColumns(cache, catalog, schema, table) {
+			if name := strings.ToLower(col.Name); !seen[name] {
+				seen[name] = true
+				suggestions = append(suggestions, columnSuggestion(col))
+			}
+		}
+	}
+	return suggestions
+}
+
+// tableColumns returns the cached columns of table, looking in every schema
+// when schema is empty and in every catalog when catalog is empty
+func tableColumns(cache *SchemaCache, catalog, schema, table string) []ColumnMetadata {
+	if schema != "" {
+		columns, _ := cache.GetColumns(catalog, schema, table)
+		return columns
+	}
+	schemas, err := cache.GetSchemas(catalog)
+	if err != nil {
+		return nil
+	}
+	var columns []ColumnMetadata
+	for _, s := range schemas {
+		found, _ := cache.GetColumns(catalog, s, table)
+		columns = append(columns, found...)
 	}
+	return columns
 }
 
-// getWordAtCursor returns the word at the cursor position
-func getWordAtCursor(sql string, cursorPos int) (string, int) {
+// WordAtCursor returns the word at the cursor position and the offset it
+// starts at, which is what a suggestion replaces
+func WordAtCursor(sql string, cursorPos int) (string, int) {
 	if cursorPos <= 0 || cursorPos > len(sql) {
 		return "", 0
 	}
@@ -666,6 +902,43 @@ func getWordAtCursor(sql string, cursorPos int) (string, int) {
 	return sql[start:end], start
 }
 
+// ApplySuggestion replaces the word at cursorPos in sql with a suggestion,
+// followed by what usually comes next: a dot after a catalog or schema that
+// qualifies a name, " = " after a session property, a comma between columns
+// of a SELECT list and a space after keywords and tables in FROM. It returns
+// the new text and the cursor position after the insertion.
+func ApplySuggestion(sql string, cursorPos int, suggestion Suggestion) (string, int) {
+	cursorPos = max(0, min(cursorPos, len(sql)))
+	word, wordStart := WordAtCursor(sql, cursorPos)
+	before := strings.ToUpper(sql[:wordStart])
+	after := ""
+	if wordStart+len(word) < len(sql) {
+		after = sql[wordStart+len(word):]
+	}
+
+	inserted := suggestion.Text
+	switch suggestion.Type {
+	case CatalogName, SchemaName:
+		if !suggestion.Terminal {
+			inserted += "."
+		}
+	case SessionProperty:
+		inserted += " = "
+	case TableName:
+		if strings.Contains(before, "FROM") {
+			inserted += " "
+		}
+	case ColumnName:
+		if strings.Contains(before, "SELECT") && !strings.Contains(strings.ToUpper(after), "FROM") {
+			inserted += ", "
+		}
+	case Keyword:
+		inserted += " "
+	}
+
+	return sql[:wordStart] + inserted + after, wordStart + len(inserted)
+}
+
 // isWordChar returns whether a character is part of a word
 func isWordChar(c byte) bool {
 	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') || c == '_'
@@ -683,6 +956,12 @@ func calculateScore(prefix, suggestion string) float64 {
 		return 1.0 - (1.0-prefixRatio)*0.1 // Higher score for more complete matches
 	}
 
+	// Camel-hump match, e.g. "cid" for customer_id
+	if humpMatch(prefix, suggestion) {
+		prefixRatio := float64(len(prefix)) / float64(len(suggestion))
+		return 0.85 - (1.0-prefixRatio)*0.1
+	}
+
 	// Case insensitive matching
 	lcPrefix := strings.ToLower(prefix)
 	lcSuggestion := strings.ToLower(suggestion)
@@ -697,7 +976,11 @@ func calculateScore(prefix, suggestion string) float64 {
 		return 0.6
 	}
 
-	// Levenshtein distance could be added here
+	// A typo in an otherwise matching prefix
+	if typoMatch(prefix, suggestion) {
+		return 0.5
+	}
+
 	// Lower score for weak matches
 	return 0.1
 }
@@ -720,33 +1003,37 @@ func fuzzyMatch(prefix, suggestion string) bool {
 	return i == len(prefix)
 }
 
-// sortSuggestionsByScore sorts suggestions by score in descending order
+// sortSuggestionsByScore sorts suggestions by score in descending order,
+// keeping the order of equal scores
 func sortSuggestionsByScore(suggestions []Suggestion) {
-	// Simple bubble sort for simplicity
-	// In production, use sort.Slice with a more efficient sort algorithm
-	n := len(suggestions)
-	for i := 0; i < n-1; i++ {
-		for j := 0; j < n-i-1; j++ {
-			if suggestions[j].Score < suggestions[j+1].Score {
-				suggestions[j], suggestions[j+1] = suggestions[j+1], suggestions[j]
-			}
-		}
-	}
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		return suggestions[i].Score > suggestions[j].Score
+	})
 }
 
-// BoostSuggestion increases the score of a suggestion when it's used
+// BoostSuggestion increases the score of a suggestion when it's used, and
+// records the use so the ranking carries over to later sessions
 func (ac *AutocompleteService) BoostSuggestion(suggestion Suggestion) {
 	ac.mu.Lock()
 	defer ac.mu.Unlock()
 
 	// Boost score in the appropriate trie based on suggestion type
+	amount := 0
 	switch suggestion.Type {
 	case Keyword:
-		ac.keywordTrie.BoostWord(suggestion.Text, 5) // Boost keywords
-	case SchemaName, TableName, ColumnName, Function:
+		amount = 5
+		ac.keywordTrie.BoostWord(suggestion.Text, amount) // Boost keywords
+	case CatalogName, SchemaName, TableName, ColumnName, Function:
 		// For schema objects, we'll boost them in the cache's trie
+		amount = 10
 		if ac.cache != nil {
-			ac.cache.BoostWord(suggestion.Text, 10) // Higher boost for schema objects
+			ac.cache.BoostWord(suggestion.Text, amount) // Higher boost for schema objects
+		}
+	}
+
+	if kind := usageKind(suggestion.Type); kind != "" && ac.cache != nil {
+		if err := ac.cache.RecordUsage(kind, suggestion.Text, float64(amount)); err != nil {
+			ac.logger.Warn("Failed to store suggestion usage", zap.Error(err))
 		}
 	}
 
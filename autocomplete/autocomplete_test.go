@@ -0,0 +1,68 @@
+package autocomplete
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestGetCompletionsStopsWhenCancelled(t *testing.T) {
+	ac, err := NewAutocompleteService(nil, t.TempDir(), zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ac.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if suggestions, err := ac.GetCompletions(ctx, "SEL", 3); !errors.Is(err, context.Canceled) || suggestions != nil {
+		t.Errorf("GetCompletions after cancel = %+v, %v", suggestions, err)
+	}
+}
+
+func TestApplySuggestion(t *testing.T) {
+	for _, tc := range []struct {
+		name, sql  string
+		cursor     int
+		suggestion Suggestion
+		want       string // With | at the returned cursor
+	}{
+		{"keyword", "SEL", 3, Suggestion{Text: "SELECT", Type: Keyword}, "SELECT |"},
+		{"column in a SELECT list", "SELECT ord", 10, Suggestion{Text: "order_id", Type: ColumnName}, "SELECT order_id, |"},
+		{"column before FROM", "SELECT ord FROM orders", 10, Suggestion{Text: "order_id", Type: ColumnName}, "SELECT order_id| FROM orders"},
+		{"table in FROM", "SELECT * FROM ord", 17, Suggestion{Text: "orders", Type: TableName}, "SELECT * FROM orders |"},
+		{"qualifying schema", "SELECT * FROM sa", 16, Suggestion{Text: "sales", Type: SchemaName}, "SELECT * FROM sales.|"},
+		{"terminal schema", "USE hive.sa", 11, Suggestion{Text: "sales", Type: SchemaName, Terminal: true}, "USE hive.sales|"},
+		{"session property", "SET SESSION query_m", 19, Suggestion{Text: "query_max_run_time", Type: SessionProperty}, "SET SESSION query_max_run_time = |"},
+		{"whole word under the cursor", "SELECT * FROM ord WHERE x", 15, Suggestion{Text: "orders", Type: TableName}, "SELECT * FROM orders | WHERE x"},
+	} {
+		sql, cursor := ApplySuggestion(tc.sql, tc.cursor, tc.suggestion)
+		if got := sql[:cursor] + "|" + sql[cursor:]; got != tc.want {
+			t.Errorf("%s: ApplySuggestion = %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestCompletionsFromAddedSchemaWithoutConnection(t *testing.T) {
+	ac, err := NewAutocompleteService(nil, t.TempDir(), zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ac.Close()
+	ctx := context.Background()
+	if err := ac.Start(ctx); err != nil {
+		t.Fatalf("Start without a connection: %v", err)
+	}
+	if err := ac.AddSchema(SchemaMetadata{Catalog: "hive", Name: "sales", Tables: []TableMetadata{
+		{Name: "orders", Columns: []ColumnMetadata{{Name: "order_id", DataType: "bigint"}}},
+	}}); err != nil {
+		t.Fatal(err)
+	}
+
+	query := "SELECT * FROM sales.ord"
+	suggestions, err := ac.GetCompletions(ctx, query, len(query))
+	if err != nil || len(suggestions) == 0 || suggestions[0].Text != "orders" || suggestions[0].Type != TableName {
+		t.Fatalf("GetCompletions(%q) = %+v, %v", query, suggestions, err)
+	}
+}
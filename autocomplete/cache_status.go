@@ -0,0 +1,125 @@
+package autocomplete
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// CacheStatus describes what a profile's autocomplete cache holds
+type CacheStatus struct {
+	Dir      string    `json:"dir"`
+	Exists   bool      `json:"exists"`
+	Size     int64     `json:"size_bytes"` // Bytes on disk
+	Catalogs int       `json:"catalogs"`
+	Schemas  int       `json:"schemas"`
+	Tables   int       `json:"tables"`
+	Columns  int       `json:"columns"`
+	Oldest   time.Time `json:"oldest,omitzero"` // When the least recently refreshed schema was read
+	Newest   time.Time `json:"newest,omitzero"` // When the most recently refreshed schema was read
+}
+
+// CacheRootDir returns the directory holding every profile's autocomplete
+// cache
+func CacheRootDir() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".trino-cli", "autocomplete_cache"), nil
+}
+
+// ProfileCacheStatus reports the size and age of a profile's autocomplete
+// cache without creating or changing it
+func ProfileCacheStatus(p Profile) (CacheStatus, error) {
+	dir, err := ProfileCacheDir(p)
+	if err != nil {
+		return CacheStatus{}, err
+	}
+	status := CacheStatus{Dir: dir}
+
+	dbPath := filepath.Join(dir, "schema_cache.db")
+	if _, err := os.Stat(dbPath); os.IsNotExist(err) {
+		return status, nil
+	} else if err != nil {
+		return status, err
+	}
+	status.Exists = true
+
+	filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		if info, err := d.Info(); err == nil {
+			status.Size += info.Size()
+		}
+		return nil
+	})
+
+	db, err := sql.Open("sqlite3", "file:"+dbPath+"?mode=ro")
+	if err != nil {
+		return status, err
+	}
+	defer db.Close()
+
+	for _, count := range []struct {
+		query string
+		into  *int
+	}{
+		{"SELECT COUNT(DISTINCT catalog_name) FROM schemas", &status.Catalogs},
+		{"SELECT COUNT(*) FROM schemas", &status.Schemas},
+		{"SELECT COUNT(*) FROM tables", &status.Tables},
+		{"SELECT COUNT(*) FROM columns", &status.Columns},
+	} {
+		if err := db.QueryRow(count.query).Scan(count.into); err != nil {
+			return status, fmt.Errorf("failed to read cache: %w", err)
+		}
+	}
+
+	// The aggregates of a TIMESTAMP column come back untyped, so the
+	// oldest and newest are picked here
+	rows, err := db.Query("SELECT last_update FROM schemas")
+	if err != nil {
+		return status, fmt.Errorf("failed to read cache: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var lastUpdate sql.NullTime
+		if err := rows.Scan(&lastUpdate); err != nil {
+			return status, err
+		}
+		if !lastUpdate.Valid {
+			continue
+		}
+		if status.Oldest.IsZero() || lastUpdate.Time.Before(status.Oldest) {
+			status.Oldest = lastUpdate.Time
+		}
+		if lastUpdate.Time.After(status.Newest) {
+			status.Newest = lastUpdate.Time
+		}
+	}
+	return status, rows.Err()
+}
+
+// ClearProfileCache deletes a profile's autocomplete cache, returning how
+// many bytes it took. The next session or refresh rebuilds it.
+func ClearProfileCache(p Profile) (int64, error) {
+	status, err := ProfileCacheStatus(p)
+	if err != nil && !status.Exists {
+		return 0, err
+	}
+	if err := os.RemoveAll(status.Dir); err != nil {
+		return 0, err
+	}
+	return status.Size, nil
+}
+
+// ForceRefreshSchema re-reads every schema of a profile into its cache,
+// however recently it was refreshed
+func ForceRefreshSchema(ctx context.Context, p Profile) error {
+	return fetchAndCacheSchema(ctx, p, true)
+}
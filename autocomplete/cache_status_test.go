@@ -0,0 +1,85 @@
+package autocomplete
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func TestProfileCacheStatusAndClear(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	prod := Profile{Name: "prod", Server: "etl@trino.example.com:443"}
+
+	status, err := ProfileCacheStatus(prod)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Exists {
+		t.Fatal("status reports a cache that was never built")
+	}
+	if _, err := os.Stat(status.Dir); !os.IsNotExist(err) {
+		t.Fatal("status created the cache directory")
+	}
+
+	dir, err := ProfileCacheDir(prod)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache, err := NewSchemaCache(dir, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, schema := range []SchemaMetadata{
+		{Catalog: "hive", Name: "sales", Tables: []TableMetadata{
+			{Name: "orders", Columns: []ColumnMetadata{{Name: "id"}, {Name: "total"}}},
+			{Name: "customers", Columns: []ColumnMetadata{{Name: "id"}}},
+		}},
+		{Catalog: "iceberg", Name: "events", Tables: []TableMetadata{
+			{Name: "clicks", Columns: []ColumnMetadata{{Name: "at"}}},
+		}},
+	} {
+		if err := cache.StoreSchema(schema); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Closing exports the cache, which must not deadlock on the cache lock
+	closed := make(chan error, 1)
+	go func() { closed <- cache.Close() }()
+	select {
+	case err := <-closed:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Close did not return")
+	}
+
+	status, err = ProfileCacheStatus(prod)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !status.Exists || status.Size == 0 {
+		t.Fatalf("status = %+v, want an existing cache with a size", status)
+	}
+	if status.Catalogs != 2 || status.Schemas != 2 || status.Tables != 3 || status.Columns != 4 {
+		t.Errorf("counts = %d catalogs, %d schemas, %d tables, %d columns; want 2, 2, 3, 4",
+			status.Catalogs, status.Schemas, status.Tables, status.Columns)
+	}
+	if status.Newest.IsZero() || status.Oldest.After(status.Newest) || time.Since(status.Newest) > time.Minute {
+		t.Errorf("refresh times = %v to %v", status.Oldest, status.Newest)
+	}
+
+	freed, err := ClearProfileCache(prod)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if freed != status.Size {
+		t.Errorf("cleared %d bytes, want %d", freed, status.Size)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Error("the cache directory was not removed")
+	}
+}
@@ -0,0 +1,31 @@
+// Package autocomplete is a SQL completion engine for Trino. Given the text
+// of a statement and a cursor position, it suggests keywords, functions,
+// catalogs, schemas, tables, columns and session properties that fit the
+// clause at the cursor, ranked by how well they match the word being typed,
+// the active catalog and schema, and what the user has picked and queried
+// before.
+//
+// Metadata is kept in a SQLite cache in a directory of the caller's
+// choosing. With a Trino connection, Start introspects the server into it
+// and keeps it fresh in the background; without one, callers inject the
+// schemas they know with AddSchema:
+//
+//	ac, err := autocomplete.NewAutocompleteService(nil, cacheDir, logger)
+//	if err != nil {
+//		return err
+//	}
+//	defer ac.Close()
+//	ac.AddSchema(autocomplete.SchemaMetadata{Catalog: "hive", Name: "sales", Tables: tables})
+//	ac.Start(ctx)
+//
+//	suggestions, err := ac.GetCompletions(ctx, sql, cursor)
+//	sql, cursor = autocomplete.ApplySuggestion(sql, cursor, suggestions[0])
+//
+// StartSchemaCacheUpdater and the other functions managing a cache per
+// server take a Profile, which names the server, its connection string and
+// the catalogs and schemas to introspect; the engine reads no configuration
+// of its own.
+//
+// The engine has no user interface of its own; package tui shows its
+// suggestions in a tview application.
+package autocomplete
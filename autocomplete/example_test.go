@@ -0,0 +1,58 @@
+package autocomplete_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/TFMV/trino-cli/autocomplete"
+	"go.uber.org/zap"
+)
+
+// Completing from a schema the program knows, without a Trino connection
+func Example() {
+	cacheDir, err := os.MkdirTemp("", "completions")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(cacheDir)
+
+	ac, err := autocomplete.NewAutocompleteService(nil, cacheDir, zap.NewNop())
+	if err != nil {
+		panic(err)
+	}
+	defer ac.Close()
+
+	if err := ac.AddSchema(autocomplete.SchemaMetadata{
+		Catalog: "hive",
+		Name:    "sales",
+		Tables: []autocomplete.TableMetadata{{
+			Name: "orders",
+			Columns: []autocomplete.ColumnMetadata{
+				{Name: "order_id", DataType: "bigint"},
+				{Name: "total", DataType: "decimal(10,2)"},
+			},
+		}},
+	}); err != nil {
+		panic(err)
+	}
+
+	ctx := context.Background()
+	if err := ac.Start(ctx); err != nil {
+		panic(err)
+	}
+
+	sql := "SELECT o.tot FROM sales.orders o"
+	cursor := len("SELECT o.tot")
+	suggestions, err := ac.GetCompletions(ctx, sql, cursor)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(suggestions[0].Text, suggestions[0].DetailText)
+
+	sql, cursor = autocomplete.ApplySuggestion(sql, cursor, suggestions[0])
+	fmt.Println(sql[:cursor] + "|" + sql[cursor:])
+	// Output:
+	// total hive.sales.orders.total (decimal(10,2))
+	// SELECT o.total| FROM sales.orders o
+}
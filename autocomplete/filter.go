@@ -0,0 +1,118 @@
+package autocomplete
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// introspectionFilter limits introspection to some catalogs and schemas.
+// Patterns are globs matched, ignoring case, against a catalog name, or
+// against catalog.schema when they contain a dot.
+type introspectionFilter struct {
+	include []string
+	exclude []string
+}
+
+// newIntrospectionFilter checks the patterns and builds a filter from them
+func newIntrospectionFilter(include, exclude []string) (introspectionFilter, error) {
+	var f introspectionFilter
+	for _, list := range []struct {
+		patterns []string
+		into     *[]string
+	}{{include, &f.include}, {exclude, &f.exclude}} {
+		for _, pattern := range list.patterns {
+			pattern = strings.ToLower(strings.TrimSpace(pattern))
+			if pattern == "" {
+				continue
+			}
+			if _, err := path.Match(pattern, ""); err != nil {
+				return introspectionFilter{}, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+			}
+			*list.into = append(*list.into, pattern)
+		}
+	}
+	return f, nil
+}
+
+// catalogPattern returns the part of a pattern matched against catalogs
+func catalogPattern(pattern string) string {
+	catalog, _, _ := strings.Cut(pattern, ".")
+	return catalog
+}
+
+// includesCatalog reports whether any of a catalog's schemas may be
+// introspected
+func (f introspectionFilter) includesCatalog(catalog string) bool {
+	catalog = strings.ToLower(catalog)
+	for _, pattern := range f.exclude {
+		if !strings.Contains(pattern, ".") && globMatch(pattern, catalog) {
+			return false
+		}
+	}
+	if len(f.include) == 0 {
+		return true
+	}
+	for _, pattern := range f.include {
+		if globMatch(catalogPattern(pattern), catalog) {
+			return true
+		}
+	}
+	return false
+}
+
+// includesSchema reports whether a schema may be introspected
+func (f introspectionFilter) includesSchema(catalog, schema string) bool {
+	if !f.includesCatalog(catalog) {
+		return false
+	}
+	name := strings.ToLower(catalog + "." + schema)
+	for _, pattern := range f.exclude {
+		if strings.Contains(pattern, ".") && globMatch(pattern, name) {
+			return false
+		}
+	}
+	if len(f.include) == 0 {
+		return true
+	}
+	for _, pattern := range f.include {
+		if !strings.Contains(pattern, ".") && globMatch(pattern, strings.ToLower(catalog)) ||
+			strings.Contains(pattern, ".") && globMatch(pattern, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// globMatch matches name against a pattern already checked to be valid
+func globMatch(pattern, name string) bool {
+	ok, _ := path.Match(pattern, name)
+	return ok
+}
+
+// SetFilter limits introspection to the catalogs and schemas matching an
+// include pattern, if there are any, and not matching an exclude pattern.
+// Cached schemas that are filtered out are dropped by the next refresh.
+func (si *SchemaIntrospector) SetFilter(include, exclude []string) error {
+	f, err := newIntrospectionFilter(include, exclude)
+	if err != nil {
+		return err
+	}
+	si.accessMu.Lock()
+	defer si.accessMu.Unlock()
+	si.filter = f
+	return nil
+}
+
+// currentFilter returns the filter set by SetFilter
+func (si *SchemaIntrospector) currentFilter() introspectionFilter {
+	si.accessMu.Lock()
+	defer si.accessMu.Unlock()
+	return si.filter
+}
+
+// SetFilter limits the introspection of the service's connection as
+// SchemaIntrospector.SetFilter does
+func (ac *AutocompleteService) SetFilter(include, exclude []string) error {
+	return ac.introspector.SetFilter(include, exclude)
+}
@@ -0,0 +1,97 @@
+package autocomplete
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"go.uber.org/zap"
+)
+
+func TestIntrospectionFilter(t *testing.T) {
+	f, err := newIntrospectionFilter(
+		[]string{"hive.sales*", "Iceberg"},
+		[]string{"system", "*.legacy_*", "hive.sales_archive"},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		catalog, schema string
+		want            bool
+	}{
+		{"hive", "sales", true},
+		{"hive", "sales_eu", true},
+		{"hive", "sales_archive", false},
+		{"hive", "marketing", false},
+		{"iceberg", "logs", true},
+		{"ICEBERG", "legacy_logs", false},
+		{"system", "runtime", false},
+		{"postgres", "public", false},
+	} {
+		if got := f.includesSchema(tc.catalog, tc.schema); got != tc.want {
+			t.Errorf("includesSchema(%s, %s) = %v, want %v", tc.catalog, tc.schema, got, tc.want)
+		}
+	}
+	for catalog, want := range map[string]bool{"hive": true, "iceberg": true, "system": false, "postgres": false} {
+		if got := f.includesCatalog(catalog); got != want {
+			t.Errorf("includesCatalog(%s) = %v, want %v", catalog, got, want)
+		}
+	}
+
+	var all introspectionFilter
+	if !all.includesSchema("any", "schema") {
+		t.Error("an empty filter should include everything")
+	}
+	if _, err := newIntrospectionFilter([]string{"hive.[sales"}, nil); err == nil {
+		t.Error("an invalid pattern should be rejected")
+	}
+}
+
+func TestRefreshAllDropsFilteredSchemas(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	cache, err := NewSchemaCache(t.TempDir(), zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cache.db.Close()
+	for _, metadata := range []SchemaMetadata{
+		{Catalog: "hive", Name: "sales", Tables: []TableMetadata{{Name: "orders"}}},
+		{Catalog: "hive", Name: "legacy", Tables: []TableMetadata{{Name: "old"}}},
+		{Catalog: "system", Name: "runtime", Tables: []TableMetadata{{Name: "queries"}}},
+	} {
+		if err := cache.StoreSchema(metadata); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	si := NewSchemaIntrospector(db, cache, zap.NewNop())
+	if err := si.SetFilter(nil, []string{"system", "hive.legacy"}); err != nil {
+		t.Fatal(err)
+	}
+	// The system catalog is not even listed
+	mock.ExpectQuery("system.metadata.catalogs").WillReturnRows(sqlmock.NewRows([]string{"catalog_name"}).AddRow("hive").AddRow("system"))
+	mock.ExpectQuery("SHOW SESSION").WillReturnRows(sqlmock.NewRows([]string{"Name"}))
+	mock.ExpectQuery("information_schema.schemata").WillReturnRows(sqlmock.NewRows([]string{"schema_name"}).AddRow("sales").AddRow("legacy"))
+	mock.ExpectQuery("SELECT table_schema, table_name FROM").WillReturnRows(sqlmock.NewRows([]string{"table_schema", "table_name"}).
+		AddRow("sales", "orders").AddRow("legacy", "old"))
+
+	if err := si.RefreshAll(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+	if catalogs, err := cache.GetCatalogs(); err != nil || !slices.Equal(catalogs, []string{"hive"}) {
+		t.Errorf("cached catalogs = %q, %v", catalogs, err)
+	}
+	if schemas, err := cache.GetSchemas("hive"); err != nil || !slices.Equal(schemas, []string{"sales"}) {
+		t.Errorf("cached hive schemas = %q, %v", schemas, err)
+	}
+}
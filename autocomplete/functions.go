@@ -0,0 +1,235 @@
+package autocomplete
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// builtinFunctions are suggested until the cluster's functions are loaded
+var builtinFunctions = []string{
+	"COUNT", "SUM", "AVG", "MIN", "MAX", "STDDEV", "VARIANCE",
+	"LOWER", "UPPER", "CONCAT", "SUBSTRING", "TRIM", "LENGTH",
+	"CAST", "ROUND", "FLOOR", "CEILING", "ABS", "MOD",
+	"CURRENT_DATE", "CURRENT_TIME", "CURRENT_TIMESTAMP", "EXTRACT",
+}
+
+// FunctionMetadata describes one signature of a function from SHOW FUNCTIONS
+type FunctionMetadata struct {
+	Name          string `json:"name"`
+	ArgumentTypes string `json:"argument_types"`
+	ReturnType    string `json:"return_type"`
+	FunctionType  string `json:"function_type"` // scalar, aggregate, window or table
+	Description   string `json:"description"`
+}
+
+// Signature renders the function as name(argument types) → return type
+func (f FunctionMetadata) Signature() string {
+	signature := fmt.Sprintf("%s(%s)", f.Name, f.ArgumentTypes)
+	if f.ReturnType != "" {
+		signature += " → " + f.ReturnType
+	}
+	return signature
+}
+
+// functionSuggestions groups overloads by name into one suggestion each,
+// detailed with the first signature and description
+func functionSuggestions(functions []FunctionMetadata, prefix string) []Suggestion {
+	overloads := make(map[string][]FunctionMetadata)
+	var names []string
+	for _, fn := range functions {
+		if !strings.HasPrefix(strings.ToLower(fn.Name), strings.ToLower(prefix)) {
+			continue
+		}
+		if _, ok := overloads[fn.Name]; !ok {
+			names = append(names, fn.Name)
+		}
+		overloads[fn.Name] = append(overloads[fn.Name], fn)
+	}
+	sort.Strings(names)
+
+	suggestions := make([]Suggestion, 0, len(names))
+	for _, name := range names {
+		fns := overloads[name]
+		detail := fns[0].Signature()
+		if len(fns) > 1 {
+			detail += fmt.Sprintf(" (+%d more)", len(fns)-1)
+		}
+		if fns[0].Description != "" {
+			detail += " — " + fns[0].Description
+		}
+		suggestions = append(suggestions, Suggestion{
+			Text:       name,
+			Type:       Function,
+			DetailText: detail,
+		})
+	}
+	return suggestions
+}
+
+// GetFunctions returns the functions loaded for the connected server, or
+// nil before any were loaded
+func (sc *SchemaCache) GetFunctions() []FunctionMetadata {
+	sc.lock.RLock()
+	defer sc.lock.RUnlock()
+	return sc.functions
+}
+
+// LoadFunctions makes the functions cached for a server version current,
+// reporting whether any were cached. An empty version loads the functions
+// stored most recently, for use before the server has been reached.
+func (sc *SchemaCache) LoadFunctions(version string) (bool, error) {
+	sc.lock.Lock()
+	defer sc.lock.Unlock()
+
+	if version == "" {
+		err := sc.db.QueryRow("SELECT server_version FROM functions ORDER BY rowid DESC LIMIT 1").Scan(&version)
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+	}
+
+	rows, err := sc.db.Query(
+		`SELECT name, argument_types, return_type, function_type, description
+		FROM functions WHERE server_version = ? ORDER BY rowid`,
+		version,
+	)
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+
+	var functions []FunctionMetadata
+	for rows.Next() {
+		var fn FunctionMetadata
+		if err := rows.Scan(&fn.Name, &fn.ArgumentTypes, &fn.ReturnType, &fn.FunctionType, &fn.Description); err != nil {
+			return false, err
+		}
+		functions = append(functions, fn)
+	}
+	if err := rows.Err(); err != nil {
+		return false, err
+	}
+	if len(functions) == 0 {
+		return false, nil
+	}
+
+	sc.functions = functions
+	return true, nil
+}
+
+// StoreFunctions caches the functions of a server version, replacing any
+// cached before for it, and makes them current
+func (sc *SchemaCache) StoreFunctions(version string, functions []FunctionMetadata) error {
+	sc.lock.Lock()
+	defer sc.lock.Unlock()
+
+	tx, err := sc.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec("DELETE FROM functions WHERE server_version = ?", version); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	stmt, err := tx.Prepare(
+		`INSERT OR REPLACE INTO functions
+		(server_version, name, argument_types, return_type, function_type, description)
+		VALUES (?, ?, ?, ?, ?, ?)`,
+	)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	defer stmt.Close()
+
+	for _, fn := range functions {
+		if _, err := stmt.Exec(version, fn.Name, fn.ArgumentTypes, fn.ReturnType, fn.FunctionType, fn.Description); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	sc.functions = functions
+	sc.logger.Info("Stored functions in cache",
+		zap.String("version", version),
+		zap.Int("functions", len(functions)))
+	return nil
+}
+
+// GetServerVersion retrieves the Trino version of the connected cluster
+func (si *SchemaIntrospector) GetServerVersion(ctx context.Context) (string, error) {
+	versions, err := si.queryNames(ctx, "SELECT version()")
+	if err != nil {
+		return "", err
+	}
+	if len(versions) == 0 {
+		return "", fmt.Errorf("server returned no version")
+	}
+	return versions[0], nil
+}
+
+// GetFunctions retrieves the functions the cluster provides
+func (si *SchemaIntrospector) GetFunctions(ctx context.Context) ([]FunctionMetadata, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	records, err := si.queryRecords(ctx, "SHOW FUNCTIONS")
+	if err != nil {
+		return nil, err
+	}
+
+	if len(records) > 0 {
+		if _, ok := records[0]["function"]; !ok {
+			return nil, fmt.Errorf("SHOW FUNCTIONS returned no function column")
+		}
+	}
+
+	functions := make([]FunctionMetadata, 0, len(records))
+	for _, record := range records {
+		functions = append(functions, FunctionMetadata{
+			Name:          record["function"],
+			ArgumentTypes: record["argument types"],
+			ReturnType:    record["return type"],
+			FunctionType:  record["function type"],
+			Description:   record["description"],
+		})
+	}
+	return functions, nil
+}
+
+// RefreshFunctions loads the cluster's functions, from the local cache when
+// they were already fetched for its version and with SHOW FUNCTIONS otherwise
+func (si *SchemaIntrospector) RefreshFunctions(ctx context.Context) error {
+	version, err := si.GetServerVersion(ctx)
+	if err != nil {
+		return err
+	}
+
+	if ok, err := si.cache.LoadFunctions(version); err != nil {
+		si.logger.Warn("Failed to load cached functions", zap.Error(err))
+	} else if ok {
+		si.logger.Debug("Using cached functions", zap.String("version", version))
+		return nil
+	}
+
+	functions, err := si.GetFunctions(ctx)
+	if err != nil {
+		return err
+	}
+	return si.cache.StoreFunctions(version, functions)
+}
@@ -0,0 +1,69 @@
+package autocomplete
+
+import (
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestFunctionSuggestions(t *testing.T) {
+	functions := []FunctionMetadata{
+		{Name: "round", ArgumentTypes: "double", ReturnType: "double", Description: "Round to nearest integer"},
+		{Name: "round", ArgumentTypes: "double, bigint", ReturnType: "double", Description: "Round to given number of decimal places"},
+		{Name: "regexp_like", ArgumentTypes: "varchar, JoniRegExp", ReturnType: "boolean"},
+		{Name: "count", ArgumentTypes: "", ReturnType: "bigint"},
+	}
+
+	got := functionSuggestions(functions, "R")
+	if len(got) != 2 {
+		t.Fatalf("got %d suggestions, want 2: %+v", len(got), got)
+	}
+	if got[0].Text != "regexp_like" || got[0].Type != Function || got[0].DetailText != "regexp_like(varchar, JoniRegExp) → boolean" {
+		t.Errorf("first suggestion = %+v", got[0])
+	}
+	if want := "round(double) → double (+1 more) — Round to nearest integer"; got[1].DetailText != want {
+		t.Errorf("overloaded detail = %q, want %q", got[1].DetailText, want)
+	}
+}
+
+func TestFunctionsCachedPerServerVersion(t *testing.T) {
+	cache, err := NewSchemaCache(t.TempDir(), zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cache.db.Close()
+
+	if ok, err := cache.LoadFunctions(""); ok || err != nil {
+		t.Fatalf("empty cache loaded functions: %v, %v", ok, err)
+	}
+	if got := cachedFunctionSuggestions(cache, "cou"); len(got) != 1 || got[0].Text != "COUNT" {
+		t.Errorf("built-in functions should be used before loading, got %+v", got)
+	}
+
+	old := []FunctionMetadata{{Name: "approx_set", ArgumentTypes: "bigint", ReturnType: "HyperLogLog", FunctionType: "aggregate"}}
+	current := []FunctionMetadata{{Name: "count", ReturnType: "bigint", FunctionType: "aggregate"}}
+	if err := cache.StoreFunctions("434", old); err != nil {
+		t.Fatal(err)
+	}
+	if err := cache.StoreFunctions("435", current); err != nil {
+		t.Fatal(err)
+	}
+
+	if ok, err := cache.LoadFunctions("434"); !ok || err != nil {
+		t.Fatalf("LoadFunctions(434) = %v, %v", ok, err)
+	}
+	if got := cache.GetFunctions(); len(got) != 1 || got[0] != old[0] {
+		t.Errorf("functions for 434 = %+v", got)
+	}
+	if ok, _ := cache.LoadFunctions("436"); ok {
+		t.Error("a new server version should need fetching")
+	}
+
+	// Before reaching the server, the last version stored is assumed
+	if ok, err := cache.LoadFunctions(""); !ok || err != nil {
+		t.Fatalf("LoadFunctions() = %v, %v", ok, err)
+	}
+	if got := cachedFunctionSuggestions(cache, "cou"); len(got) != 1 || got[0].Text != "count" || got[0].DetailText != "count() → bigint" {
+		t.Errorf("cached suggestions = %+v", got)
+	}
+}
@@ -0,0 +1,135 @@
+package autocomplete
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// minMatchScore is the calculateScore a name needs to be suggested for a
+// typed word: prefixes, camel humps, subsequences and one-letter typos
+const minMatchScore = 0.5
+
+// minTypoLength is the shortest word checked for typos, below which nearly
+// every name would be one edit away
+const minTypoLength = 4
+
+// matchesWord reports whether name is worth suggesting for the typed word.
+// Matches must start with the word's first letter.
+func matchesWord(word, name string) bool {
+	if word == "" {
+		return true
+	}
+	first := []rune(strings.ToLower(word))[0]
+	if nameRunes := []rune(strings.ToLower(name)); len(nameRunes) == 0 || nameRunes[0] != first {
+		return false
+	}
+	return calculateScore(word, name) >= minMatchScore
+}
+
+// nameParts splits a name into the words it is made of, at underscores,
+// dots, dashes and lower-to-upper case changes: customer_id and customerId
+// both have the parts customer and id
+func nameParts(name string) []string {
+	var parts []string
+	var part []rune
+	var prev rune
+	flush := func() {
+		if len(part) > 0 {
+			parts = append(parts, strings.ToLower(string(part)))
+			part = part[:0]
+		}
+	}
+	for _, r := range name {
+		switch {
+		case r == '_' || r == '.' || r == '-':
+			flush()
+		case unicode.IsUpper(r) && unicode.IsLower(prev):
+			flush()
+			part = append(part, r)
+		default:
+			part = append(part, r)
+		}
+		prev = r
+	}
+	flush()
+	return parts
+}
+
+// humpMatch reports whether word is made of prefixes of name's parts, in
+// order and starting with the first, so cid matches customer_id and ordit
+// order_items. Parts may be skipped after the first.
+func humpMatch(word, name string) bool {
+	parts := nameParts(name)
+	word = strings.ToLower(word)
+	if len(parts) < 2 || word == "" {
+		return false
+	}
+
+	var match func(word string, i int, anchored bool) bool
+	match = func(word string, i int, anchored bool) bool {
+		if word == "" {
+			return true
+		}
+		for ; i < len(parts); i++ {
+			part := parts[i]
+			for n := min(len(word), len(part)); n > 0; n-- {
+				if word[:n] == part[:n] && match(word[n:], i+1, false) {
+					return true
+				}
+			}
+			if anchored {
+				return false
+			}
+		}
+		return false
+	}
+	return match(word, 0, true)
+}
+
+// typoMatch reports whether name starts with word give or take one typed
+// letter too many, too few or wrong
+func typoMatch(word, name string) bool {
+	w := []rune(strings.ToLower(word))
+	n := []rune(strings.ToLower(name))
+	if len(w) < minTypoLength {
+		return false
+	}
+	for length := len(w) - 1; length <= len(w)+1; length++ {
+		if length <= len(n) && editDistance(w, n[:length]) <= 1 {
+			return true
+		}
+	}
+	return false
+}
+
+// editDistance returns the Levenshtein distance between a and b
+func editDistance(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+// appendMissing appends the words of more that are not in words yet
+func appendMissing(words, more []string) []string {
+	for _, word := range more {
+		if !slices.Contains(words, word) {
+			words = append(words, word)
+		}
+	}
+	return words
+}
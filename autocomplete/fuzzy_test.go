@@ -0,0 +1,83 @@
+package autocomplete
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestFuzzyMatching(t *testing.T) {
+	for _, tc := range []struct {
+		word, name string
+		hump, typo bool
+	}{
+		{"cid", "customer_id", true, false},
+		{"ordit", "order_items", true, false},
+		{"oi", "orderItems", true, false},
+		{"cusid", "customer_order_id", true, false},
+		{"id", "customer_id", false, false}, // Humps start at the first part
+		{"custmer", "customer_id", false, true},
+		{"cutsomer", "customer_id", false, false}, // Two edits
+		{"ord", "orders", false, false},           // Too short for typos
+	} {
+		if got := humpMatch(tc.word, tc.name); got != tc.hump {
+			t.Errorf("humpMatch(%q, %q) = %v, want %v", tc.word, tc.name, got, tc.hump)
+		}
+		if got := typoMatch(tc.word, tc.name); got != tc.typo {
+			t.Errorf("typoMatch(%q, %q) = %v, want %v", tc.word, tc.name, got, tc.typo)
+		}
+	}
+
+	// Prefix matches outrank camel humps, which outrank looser matches
+	prefix, hump, typo := calculateScore("cust", "customer_id"), calculateScore("cid", "customer_id"), calculateScore("custmer", "customer_id")
+	if !(prefix > hump && hump > 0.7 && typo >= minMatchScore && typo < hump) {
+		t.Errorf("scores: prefix %.2f, hump %.2f, typo %.2f", prefix, hump, typo)
+	}
+	if matchesWord("id", "customer_id") {
+		t.Error("matches must start with the first letter typed")
+	}
+}
+
+func TestFuzzyCompletions(t *testing.T) {
+	ac, err := NewAutocompleteService(nil, t.TempDir(), zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ac.cache.db.Close()
+	if err := ac.cache.StoreSchema(SchemaMetadata{Catalog: "hive", Name: "sales", Tables: []TableMetadata{
+		{Name: "customers", Columns: []ColumnMetadata{{Name: "customer_id"}, {Name: "created_at"}}},
+		{Name: "order_items", Columns: []ColumnMetadata{{Name: "order_id"}}},
+		{Name: "orders"},
+	}}); err != nil {
+		t.Fatal(err)
+	}
+
+	texts := func(query string) []string {
+		suggestions, err := ac.GetCompletions(context.Background(), query, len(query))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var texts []string
+		for _, s := range suggestions {
+			texts = append(texts, strings.ToLower(s.Text))
+		}
+		return texts
+	}
+
+	if got := texts("SELECT cid"); len(got) == 0 || got[0] != "customer_id" {
+		t.Errorf("SELECT cid = %q, want customer_id first", got)
+	}
+	if got := texts("SELECT * FROM ordit"); len(got) == 0 || got[0] != "order_items" {
+		t.Errorf("FROM ordit = %q, want order_items first", got)
+	}
+	// A prefix match still ranks above a hump match
+	if got := texts("SELECT * FROM ord"); len(got) < 2 || got[0] != "orders" && got[0] != "order_items" {
+		t.Errorf("FROM ord = %q", got)
+	}
+	if got := texts("selct"); !slices.Contains(got, "select") {
+		t.Errorf("selct = %q, want the select keyword despite the typo", got)
+	}
+}
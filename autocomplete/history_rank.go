@@ -0,0 +1,114 @@
+package autocomplete
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/TFMV/trino-cli/sqllex"
+	"go.uber.org/zap"
+)
+
+// Tables and columns found in the query history lift a suggestion's score
+// by up to maxHistoryBoost, reaching half of it once a name appears in
+// historySaturation queries, so the objects a user works with rank above
+// the rest of the warehouse without outranking a better match
+const (
+	maxHistoryBoost   = 0.1
+	historySaturation = 10.0
+)
+
+// queryObjects returns the lower-cased names of the tables a query reads,
+// and of the other names it uses, which include its columns. Each name is
+// listed once.
+func queryObjects(query string) (tables, names []string) {
+	p := newParser()
+	refs := make(map[*tableRef]bool)
+	var words []string
+	for _, tok := range sqllex.Tokenize(query) {
+		p.step(tok)
+		if ref := p.scope.ref; ref != nil {
+			refs[ref] = true
+		}
+		if tok.IsName() && !(tok.Kind == sqllex.Word && reservedWords[strings.ToUpper(tok.Text)]) {
+			words = append(words, strings.ToLower(identifierName(tok)))
+		}
+	}
+
+	// Table names, their qualifiers and aliases are not columns
+	seen := make(map[string]bool)
+	for ref := range refs {
+		for _, name := range ref.names {
+			seen[strings.ToLower(name)] = true
+		}
+		if ref.alias != "" {
+			seen[strings.ToLower(ref.alias)] = true
+		}
+		if table := strings.ToLower(ref.table()); table != "" && !slices.Contains(tables, table) {
+			tables = append(tables, table)
+		}
+	}
+	for _, word := range words {
+		if !seen[word] {
+			seen[word] = true
+			names = append(names, word)
+		}
+	}
+	return tables, names
+}
+
+// LearnQueries counts the tables and columns that queries use, typically
+// the user's query history, so that they rank higher among suggestions
+func (ac *AutocompleteService) LearnQueries(queries []string) {
+	tableCounts := make(map[string]int)
+	nameCounts := make(map[string]int)
+	for _, query := range queries {
+		tables, names := queryObjects(query)
+		for _, table := range tables {
+			tableCounts[table]++
+		}
+		for _, name := range names {
+			nameCounts[name]++
+		}
+	}
+
+	ac.mu.Lock()
+	defer ac.mu.Unlock()
+	if ac.historyTables == nil {
+		ac.historyTables = make(map[string]int)
+		ac.historyColumns = make(map[string]int)
+	}
+	for table, n := range tableCounts {
+		ac.historyTables[table] += n
+	}
+	for name, n := range nameCounts {
+		ac.historyColumns[name] += n
+	}
+	ac.logger.Debug("Learned object use from queries",
+		zap.Int("queries", len(queries)),
+		zap.Int("tables", len(ac.historyTables)),
+		zap.Int("columns", len(ac.historyColumns)))
+}
+
+// applyHistory raises the scores of tables and columns that appear in the
+// learned queries. Callers must hold ac.mu.
+func (ac *AutocompleteService) applyHistory(suggestions []Suggestion) {
+	for i := range suggestions {
+		s := &suggestions[i]
+		var counts map[string]int
+		switch s.Type {
+		case TableName:
+			counts = ac.historyTables
+		case ColumnName:
+			counts = ac.historyColumns
+		default:
+			continue
+		}
+		name := s.Text
+		if dot := strings.LastIndex(name, "."); dot >= 0 {
+			name = name[dot+1:]
+		}
+		if n := float64(counts[strings.ToLower(strings.Trim(name, `"`))]); n > 0 {
+			s.Score += maxHistoryBoost * n / (n + historySaturation)
+		}
+	}
+}
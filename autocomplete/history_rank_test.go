@@ -0,0 +1,72 @@
+package autocomplete
+
+import (
+	"context"
+	"reflect"
+	"slices"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestQueryObjects(t *testing.T) {
+	tables, names := queryObjects(`SELECT o.id, c."Name", count(*)
+		FROM hive.sales.orders o JOIN customers AS c ON o.customer_id = c.id
+		WHERE o.total > 10 AND o.id IN (SELECT order_id FROM refunds)`)
+	slices.Sort(tables)
+	if want := []string{"customers", "orders", "refunds"}; !reflect.DeepEqual(tables, want) {
+		t.Errorf("tables = %q, want %q", tables, want)
+	}
+	for _, name := range []string{"id", "name", "customer_id", "total", "order_id"} {
+		if !slices.Contains(names, name) {
+			t.Errorf("names %q lack %q", names, name)
+		}
+	}
+	for _, name := range []string{"o", "c", "hive", "sales", "orders", "select", "and"} {
+		if slices.Contains(names, name) {
+			t.Errorf("names %q should not hold %q", names, name)
+		}
+	}
+	if n := len(names); n != len(slices.Compact(slices.Sorted(slices.Values(names)))) {
+		t.Errorf("names %q repeat", names)
+	}
+}
+
+func TestHistoryRanksUsedObjectsFirst(t *testing.T) {
+	ac, err := NewAutocompleteService(nil, t.TempDir(), zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ac.cache.db.Close()
+	if err := ac.cache.StoreSchema(SchemaMetadata{Catalog: "hive", Name: "sales", Tables: []TableMetadata{
+		{Name: "orders", Columns: []ColumnMetadata{{Name: "order_date"}, {Name: "order_key"}}},
+		{Name: "orgs"},
+	}}); err != nil {
+		t.Fatal(err)
+	}
+
+	first := func(query string) string {
+		suggestions, err := ac.GetCompletions(context.Background(), query, len(query))
+		if err != nil || len(suggestions) == 0 {
+			t.Fatalf("GetCompletions(%q) = %+v, %v", query, suggestions, err)
+		}
+		return suggestions[0].Text
+	}
+	if got := first("SELECT * FROM sales.or"); got != "orgs" {
+		t.Fatalf("the closer match should rank first without history, got %q", got)
+	}
+	if got := first("SELECT * FROM orders WHERE order_"); got != "order_key" {
+		t.Fatalf("the shorter column should rank first without history, got %q", got)
+	}
+
+	ac.LearnQueries([]string{
+		"SELECT order_date, count(*) FROM sales.orders GROUP BY order_date",
+		"SELECT * FROM orders WHERE order_date > DATE '2024-01-01'",
+	})
+	if got := first("SELECT * FROM sales.or"); got != "orders" {
+		t.Errorf("a table from the history should rank first, got %q", got)
+	}
+	if got := first("SELECT * FROM orders WHERE order_"); got != "order_date" {
+		t.Errorf("a column from the history should rank first, got %q", got)
+	}
+}
@@ -0,0 +1,78 @@
+package autocomplete
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// Name depths for CompleteName
+const (
+	CatalogDepth = 1 // catalog
+	SchemaDepth  = 2 // catalog.schema
+	TableDepth   = 3 // catalog.schema.table
+)
+
+// CompleteName completes a dotted object name, such as a shell argument,
+// from the cache. depth is the number of parts of a full name; names with
+// fewer parts end with a dot, for the next part to follow.
+func (sc *SchemaCache) CompleteName(prefix string, depth int) ([]string, error) {
+	parts := strings.Split(prefix, ".")
+	if len(parts) > depth {
+		return nil, nil
+	}
+
+	var names []string
+	var err error
+	switch len(parts) {
+	case 1:
+		names, err = sc.GetCatalogs()
+	case 2:
+		names, err = sc.GetSchemas(parts[0])
+	default:
+		names, err = sc.GetTables(parts[0], parts[1])
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	qualifier := strings.Join(parts[:len(parts)-1], ".")
+	if qualifier != "" {
+		qualifier += "."
+	}
+	last := strings.ToLower(parts[len(parts)-1])
+	var completions []string
+	for _, name := range names {
+		if !strings.HasPrefix(strings.ToLower(name), last) {
+			continue
+		}
+		completion := qualifier + name
+		if len(parts) < depth {
+			completion += "."
+		}
+		completions = append(completions, completion)
+	}
+	return completions, nil
+}
+
+// CompleteCachedName completes a dotted object name from a profile's
+// autocomplete cache as CompleteName does, finding nothing when the
+// profile has no cache yet rather than creating one
+func CompleteCachedName(p Profile, prefix string, depth int) ([]string, error) {
+	dir, err := ProfileCacheDir(p)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(filepath.Join(dir, "schema_cache.db")); err != nil {
+		return nil, nil
+	}
+	cache, err := NewSchemaCache(dir, zap.NewNop())
+	if err != nil {
+		return nil, err
+	}
+	// Close would rewrite the JSON export, which reading leaves as it was
+	defer cache.db.Close()
+	return cache.CompleteName(prefix, depth)
+}
@@ -0,0 +1,63 @@
+package autocomplete
+
+import (
+	"slices"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestCompleteName(t *testing.T) {
+	cache, err := NewSchemaCache(t.TempDir(), zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cache.db.Close()
+
+	for _, metadata := range []SchemaMetadata{
+		{Catalog: "hive", Name: "sales", Tables: []TableMetadata{{Name: "orders"}, {Name: "order_items"}, {Name: "users"}}},
+		{Catalog: "hive", Name: "staging"},
+		{Catalog: "iceberg", Name: "sales", Tables: []TableMetadata{{Name: "returns"}}},
+	} {
+		if err := cache.StoreSchema(metadata); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		prefix string
+		depth  int
+		want   []string
+	}{
+		{"", TableDepth, []string{"hive.", "iceberg."}},
+		{"h", CatalogDepth, []string{"hive"}},
+		{"hive.s", TableDepth, []string{"hive.sales.", "hive.staging."}},
+		{"hive.st", SchemaDepth, []string{"hive.staging"}},
+		{"hive.sales.ORD", TableDepth, []string{"hive.sales.order_items", "hive.sales.orders"}},
+		{"iceberg.sales.", TableDepth, []string{"iceberg.sales.returns"}},
+		{"hive.sales.", SchemaDepth, nil},
+		{"hive.missing.", TableDepth, nil},
+	}
+	for _, tt := range tests {
+		got, err := cache.CompleteName(tt.prefix, tt.depth)
+		if err != nil {
+			t.Fatal(err)
+		}
+		slices.Sort(got)
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("CompleteName(%q, %d) = %q, want %q", tt.prefix, tt.depth, got, tt.want)
+		}
+	}
+}
+
+func TestCompleteCachedNameWithoutCache(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	dev := Profile{Name: "dev", Server: "me@localhost:8080"}
+	names, err := CompleteCachedName(dev, "", TableDepth)
+	if err != nil || len(names) != 0 {
+		t.Errorf("CompleteCachedName without a cache = %q, %v; want nothing", names, err)
+	}
+	if status, _ := ProfileCacheStatus(dev); status.Exists {
+		t.Error("expected completion to leave the missing cache uncreated")
+	}
+}
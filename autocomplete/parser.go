@@ -0,0 +1,276 @@
+package autocomplete
+
+import (
+	"strings"
+
+	"github.com/TFMV/trino-cli/sqllex"
+)
+
+// sqlClause is the part of a query a position falls in
+type sqlClause int
+
+const (
+	clauseStatement sqlClause = iota // Start of a statement or set operation
+	clauseSelect                     // SELECT list
+	clauseFrom                       // FROM list, or the table of INTO, UPDATE and TABLE
+	clauseJoin                       // Table after JOIN
+	clauseOn                         // Join condition after ON or USING
+	clauseWhere
+	clauseGroupBy
+	clauseHaving
+	clauseOrderBy
+	clauseOther // A clause without names to complete, such as LIMIT
+)
+
+// tableClause reports whether names in c refer to tables
+func (c sqlClause) tableClause() bool {
+	return c == clauseFrom || c == clauseJoin
+}
+
+// columnClause reports whether names in c refer to columns
+func (c sqlClause) columnClause() bool {
+	switch c {
+	case clauseSelect, clauseOn, clauseWhere, clauseGroupBy, clauseHaving, clauseOrderBy:
+		return true
+	}
+	return false
+}
+
+// reservedWords are Trino's reserved keywords, which can't be unquoted
+// identifiers and so never name a table, column or alias
+var reservedWords = toSet(
+	"ALTER", "AND", "AS", "BETWEEN", "BY", "CASE", "CAST", "CONSTRAINT", "CREATE",
+	"CROSS", "CUBE", "CURRENT_CATALOG", "CURRENT_DATE", "CURRENT_PATH", "CURRENT_ROLE",
+	"CURRENT_SCHEMA", "CURRENT_TIME", "CURRENT_TIMESTAMP", "CURRENT_USER", "DEALLOCATE",
+	"DELETE", "DESCRIBE", "DISTINCT", "DROP", "ELSE", "END", "ESCAPE", "EXCEPT", "EXECUTE",
+	"EXISTS", "EXTRACT", "FALSE", "FOR", "FROM", "FULL", "GROUP", "GROUPING", "HAVING",
+	"IN", "INNER", "INSERT", "INTERSECT", "INTO", "IS", "JOIN", "LEFT", "LIKE",
+	"LISTAGG", "LOCALTIME", "LOCALTIMESTAMP", "NATURAL", "NORMALIZE", "NOT", "NULL",
+	"ON", "OR", "ORDER", "OUTER", "PREPARE", "RECURSIVE", "RIGHT", "ROLLUP", "SELECT",
+	"SKIP", "TABLE", "THEN", "TRIM", "TRUE", "UESCAPE", "UNION", "UNNEST", "USING",
+	"VALUES", "WHEN", "WHERE", "WITH",
+)
+
+// IsReserved reports whether word is one of Trino's reserved keywords, which
+// must be quoted to name a catalog, schema, table or column
+func IsReserved(word string) bool {
+	return reservedWords[strings.ToUpper(word)]
+}
+
+// valueWords are reserved words that complete an operand on their own
+var valueWords = toSet(
+	"CURRENT_CATALOG", "CURRENT_DATE", "CURRENT_PATH", "CURRENT_ROLE", "CURRENT_SCHEMA",
+	"CURRENT_TIME", "CURRENT_TIMESTAMP", "CURRENT_USER", "END", "FALSE", "LOCALTIME",
+	"LOCALTIMESTAMP", "NULL", "TRUE",
+)
+
+// joinWords qualify a JOIN that still has to follow
+var joinWords = toSet("CROSS", "FULL", "INNER", "LEFT", "NATURAL", "OUTER", "RIGHT")
+
+// relationWords are non-reserved keywords that can stand where a table name
+// or alias would otherwise be
+var relationWords = toSet("FETCH", "LATERAL", "LIMIT", "MATCH_RECOGNIZE", "OFFSET", "TABLESAMPLE", "WINDOW")
+
+func toSet(words ...string) map[string]bool {
+	set := make(map[string]bool, len(words))
+	for _, w := range words {
+		set[w] = true
+	}
+	return set
+}
+
+// tableRef is a table named in a FROM or JOIN clause
+type tableRef struct {
+	names []string // Parts of the qualified name, empty for a subquery
+	alias string
+}
+
+// table returns the unqualified table name
+func (r *tableRef) table() string {
+	if len(r.names) == 0 {
+		return ""
+	}
+	return r.names[len(r.names)-1]
+}
+
+// schema returns the schema the table was qualified with, if any
+func (r *tableRef) schema() string {
+	if len(r.names) < 2 {
+		return ""
+	}
+	return r.names[len(r.names)-2]
+}
+
+// resolveTable finds the table that name, an alias or an unaliased table
+// name, refers to. Tables are searched in order, innermost scope first.
+func resolveTable(tables []*tableRef, name string) *tableRef {
+	for _, ref := range tables {
+		if ref.alias == name || ref.alias == "" && ref.table() == name {
+			return ref
+		}
+	}
+	return nil
+}
+
+// scope is a query, or a parenthesised expression within one, being parsed
+type scope struct {
+	parent  *scope
+	clause  sqlClause
+	query   bool      // Clause keywords apply here, rather than inside e.g. EXTRACT(... FROM ...)
+	fresh   bool      // Nothing has been parsed in the scope yet
+	operand bool      // The next token starts an operand rather than following one
+	by      sqlClause // Clause that GROUP or ORDER starts once BY follows
+	tables  []*tableRef
+	ref     *tableRef // Table reference still taking name parts or an alias
+}
+
+// visibleTables lists the tables s and its enclosing scopes refer to,
+// innermost first
+func (s *scope) visibleTables() []*tableRef {
+	var tables []*tableRef
+	for ; s != nil; s = s.parent {
+		tables = append(tables, s.tables...)
+	}
+	return tables
+}
+
+// parser tracks the clause structure of a statement one token at a time
+type parser struct {
+	scope *scope
+	last  sqllex.Token // Last token parsed, other than comments
+}
+
+func newParser() *parser {
+	return &parser{scope: &scope{clause: clauseStatement, query: true, fresh: true, operand: true}}
+}
+
+// step advances the parser past tok
+func (p *parser) step(tok sqllex.Token) {
+	if tok.Kind == sqllex.Comment {
+		return
+	}
+	s := p.scope
+	fresh := s.fresh
+	s.fresh = false
+	qualified := p.last.Is(".")
+	p.last = tok
+
+	if tok.IsName() && s.query && s.clause.tableClause() && s.tableName(tok, qualified) {
+		return
+	}
+
+	switch tok.Kind {
+	case sqllex.Number, sqllex.String, sqllex.Quoted, sqllex.Placeholder:
+		s.operand = false
+		s.ref = nil
+		return
+	case sqllex.Punct:
+		if tok.Text != "." {
+			s.ref = nil
+		}
+		switch tok.Text {
+		case "(":
+			p.scope = &scope{parent: s, clause: s.clause, fresh: true, operand: true}
+		case ")":
+			if s.parent != nil {
+				p.scope = s.parent
+			}
+			p.scope.operand = false
+			if parent := p.scope; parent.query && parent.clause.tableClause() {
+				// A subquery or table function, which an alias may follow
+				parent.ref = &tableRef{}
+				parent.tables = append(parent.tables, parent.ref)
+			}
+		case ";":
+			*p = *newParser()
+		case ".":
+			// The name continues
+		case "*":
+			// A wildcard where an operand was expected, otherwise a product
+			s.operand = !s.operand
+		default:
+			s.operand = true
+		}
+		return
+	}
+
+	word := strings.ToUpper(tok.Text)
+	if qualified {
+		s.operand = false
+		return
+	}
+	if fresh && (word == "SELECT" || word == "WITH" || word == "VALUES") {
+		s.query = true
+	}
+	if !s.query {
+		s.operand = !valueWords[word] && reservedWords[word]
+		return
+	}
+
+	expecting := s.operand
+	s.operand = true
+	ref := s.ref
+	s.ref = nil
+	switch word {
+	case "SELECT":
+		s.clause = clauseSelect
+	case "FROM", "INTO", "TABLE", "UPDATE":
+		s.clause = clauseFrom
+	case "JOIN":
+		s.clause = clauseJoin
+	case "ON", "USING":
+		if s.clause == clauseJoin {
+			s.clause = clauseOn
+		}
+	case "WHERE":
+		s.clause = clauseWhere
+	case "HAVING":
+		s.clause = clauseHaving
+	case "GROUP":
+		s.by = clauseGroupBy
+	case "ORDER":
+		s.by = clauseOrderBy
+	case "BY":
+		if s.by != clauseStatement {
+			s.clause, s.by = s.by, clauseStatement
+		}
+	case "UNION", "INTERSECT", "EXCEPT", "WITH":
+		s.clause = clauseStatement
+	case "LIMIT", "OFFSET", "FETCH", "WINDOW":
+		// Non-reserved, so a column name where an operand is expected
+		if expecting && s.clause.columnClause() {
+			s.operand = false
+		} else {
+			s.clause = clauseOther
+		}
+	case "AS":
+		// An alias follows, which is new rather than something to complete
+		s.operand = false
+		s.ref = ref
+	default:
+		s.operand = !valueWords[word] && !joinWords[word] && reservedWords[word]
+	}
+}
+
+// tableName takes tok as part of a table reference's name or as its alias
+// when it is one, and reports whether it was
+func (s *scope) tableName(tok sqllex.Token, qualified bool) bool {
+	word := strings.ToUpper(tok.Text)
+	keyword := tok.Kind == sqllex.Word && (reservedWords[word] || relationWords[word])
+	switch {
+	case qualified:
+		if s.ref == nil || s.ref.alias != "" {
+			return false
+		}
+		s.ref.names = append(s.ref.names, identifierName(tok))
+	case s.operand && !keyword:
+		s.ref = &tableRef{names: []string{identifierName(tok)}}
+		s.tables = append(s.tables, s.ref)
+	case s.ref != nil && s.ref.alias == "" && !keyword:
+		s.ref.alias = identifierName(tok)
+	default:
+		return false
+	}
+	s.operand = false
+	return true
+}
@@ -0,0 +1,244 @@
+package autocomplete
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+// cursor marks the cursor position in the test queries
+const cursor = "|"
+
+func TestAnalyzeContext(t *testing.T) {
+	tests := []struct {
+		query          string
+		clause         sqlClause
+		completionType SQLCompletionType
+		schema, table  string
+	}{
+		{"SELECT |", clauseSelect, ColumnName, "", ""},
+		{"SELECT na|", clauseSelect, ColumnName, "", ""},
+		{"SELECT a, |", clauseSelect, ColumnName, "", ""},
+		{"SELECT a |", clauseSelect, Keyword, "", ""},
+		{"SELECT * |", clauseSelect, Keyword, "", ""},
+		{"SELECT a * |", clauseSelect, ColumnName, "", ""},
+		{"SELECT * FROM |", clauseFrom, TableName, "", ""},
+		{"SELECT * FROM sales.ord|", clauseFrom, TableName, "sales", ""},
+		{`SELECT * FROM "Sales".|`, clauseFrom, TableName, "Sales", ""},
+		{"SELECT * FROM orders |", clauseFrom, Keyword, "", ""},
+		{"SELECT * FROM orders o, |", clauseFrom, TableName, "", ""},
+		{"SELECT * FROM orders o LEFT |", clauseFrom, Keyword, "", ""},
+		{"SELECT * FROM orders o JOIN |", clauseJoin, TableName, "", ""},
+		{"SELECT * FROM orders o JOIN customers c ON |", clauseOn, ColumnName, "", ""},
+		{"SELECT * FROM orders o JOIN customers c USING (|", clauseOn, ColumnName, "", ""},
+		{"SELECT * FROM t WHERE |", clauseWhere, ColumnName, "", ""},
+		{"SELECT * FROM t WHERE a = 1 AND |", clauseWhere, ColumnName, "", ""},
+		{"SELECT * FROM t WHERE o.|", clauseWhere, ColumnName, "", "o"},
+		{"SELECT * FROM t WHERE sales.orders.|", clauseWhere, ColumnName, "sales", "orders"},
+		{"SELECT a FROM t GROUP BY |", clauseGroupBy, ColumnName, "", ""},
+		{"SELECT a FROM t GROUP BY a HAVING |", clauseHaving, ColumnName, "", ""},
+		{"SELECT a FROM t ORDER BY |", clauseOrderBy, ColumnName, "", ""},
+		{"SELECT a FROM t LIMIT |", clauseOther, Keyword, "", ""},
+		{"SELECT limit FROM t ORDER BY |", clauseOrderBy, ColumnName, "", ""},
+
+		// Keywords in strings and comments don't count
+		{"SELECT 'FROM x' , |", clauseSelect, ColumnName, "", ""},
+		{"SELECT a -- FROM\n, |", clauseSelect, ColumnName, "", ""},
+		{"SELECT /* WHERE */ |", clauseSelect, ColumnName, "", ""},
+
+		// Subqueries have their own clauses, and the outer one resumes after them
+		{"SELECT * FROM (SELECT |", clauseSelect, ColumnName, "", ""},
+		{"SELECT * FROM (SELECT a FROM |", clauseFrom, TableName, "", ""},
+		{"SELECT * FROM (SELECT a FROM t) x WHERE |", clauseWhere, ColumnName, "", ""},
+		{"SELECT * FROM t WHERE a IN (SELECT b FROM u) AND |", clauseWhere, ColumnName, "", ""},
+		{"SELECT * FROM t WHERE a IN (SELECT b FROM |", clauseFrom, TableName, "", ""},
+		{"WITH x AS (SELECT a FROM t) SELECT * FROM |", clauseFrom, TableName, "", ""},
+		{"SELECT a FROM t UNION SELECT |", clauseSelect, ColumnName, "", ""},
+
+		// FROM inside a function call is not a clause
+		{"SELECT extract(year FROM |", clauseSelect, ColumnName, "", ""},
+		{"SELECT extract(year FROM d), |", clauseSelect, ColumnName, "", ""},
+
+		// Only the statement at the cursor matters
+		{"SELECT * FROM t; SELECT |", clauseSelect, ColumnName, "", ""},
+		{"SELECT * FROM t WHERE a = 1; |", clauseStatement, Keyword, "", ""},
+	}
+	for _, tt := range tests {
+		pos := strings.Index(tt.query, cursor)
+		sql := strings.Replace(tt.query, cursor, "", 1)
+		ctx := analyzeContext(sql, pos)
+		if ctx.literal || ctx.clause != tt.clause || ctx.completionType != tt.completionType ||
+			ctx.schema != tt.schema || ctx.table != tt.table {
+			t.Errorf("analyzeContext(%q) = %+v, want clause %d, type %d, schema %q, table %q",
+				tt.query, ctx, tt.clause, tt.completionType, tt.schema, tt.table)
+		}
+	}
+}
+
+func TestAnalyzeContextInLiteral(t *testing.T) {
+	for _, query := range []string{
+		"SELECT * FROM t WHERE name = 'FROM |",
+		"SELECT * FROM t WHERE name = 'a|b'",
+		"SELECT a -- pick |",
+		"SELECT a /* FROM | */ FROM t",
+		"SELECT * FROM ${ta|",
+	} {
+		pos := strings.Index(query, cursor)
+		if ctx := analyzeContext(strings.Replace(query, cursor, "", 1), pos); !ctx.literal {
+			t.Errorf("analyzeContext(%q) should be inside a literal, got %+v", query, ctx)
+		}
+	}
+
+	for _, query := range []string{
+		"SELECT 'a' |",
+		"SELECT a /* x */ |",
+		"SELECT a -- x\n|",
+		"SELECT * FROM ${table} |",
+	} {
+		pos := strings.Index(query, cursor)
+		if ctx := analyzeContext(strings.Replace(query, cursor, "", 1), pos); ctx.literal {
+			t.Errorf("analyzeContext(%q) should be outside a literal", query)
+		}
+	}
+}
+
+func TestAnalyzeContextResolvesAliases(t *testing.T) {
+	tests := []struct {
+		query         string
+		schema, table string
+	}{
+		{"SELECT o.| FROM orders o JOIN customers c ON o.customer_id = c.id", "", "orders"},
+		{"SELECT c.| FROM orders o JOIN customers c ON o.customer_id = c.id", "", "customers"},
+		{"SELECT * FROM sales.orders AS o WHERE o.|", "sales", "orders"},
+		{`SELECT * FROM "Sales"."Orders" "O" WHERE "O".|`, "Sales", "Orders"},
+		{"SELECT * FROM sales.orders WHERE orders.|", "sales", "orders"},
+		{"SELECT * FROM orders o LEFT JOIN customers ON customers.|", "", "customers"},
+
+		// Subqueries see their own tables first, then the enclosing ones
+		{"SELECT * FROM orders o WHERE EXISTS (SELECT 1 FROM items o WHERE o.|)", "", "items"},
+		{"SELECT * FROM orders o WHERE EXISTS (SELECT 1 FROM items i WHERE i.id = o.|)", "", "orders"},
+		{"SELECT x.| FROM (SELECT * FROM orders o) x", "", "x"},
+
+		// Unknown qualifiers are taken as table names
+		{"SELECT t.| FROM orders o", "", "t"},
+	}
+	for _, tt := range tests {
+		pos := strings.Index(tt.query, cursor)
+		ctx := analyzeContext(strings.Replace(tt.query, cursor, "", 1), pos)
+		if ctx.completionType != ColumnName || ctx.schema != tt.schema || ctx.table != tt.table {
+			t.Errorf("analyzeContext(%q) = %+v, want columns of %q.%q", tt.query, ctx, tt.schema, tt.table)
+		}
+	}
+}
+
+func TestContextualSuggestionsForAlias(t *testing.T) {
+	cache, err := NewSchemaCache(t.TempDir(), zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cache.db.Close()
+	err = cache.StoreSchema(SchemaMetadata{Name: "sales", Tables: []TableMetadata{
+		{Name: "orders", Columns: []ColumnMetadata{{Name: "id"}, {Name: "customer_id"}, {Name: "total"}}},
+		{Name: "customers", Columns: []ColumnMetadata{{Name: "id"}, {Name: "name"}}},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	query := "SELECT o. FROM orders o JOIN customers c ON o.customer_id = c.id"
+	got := GetContextualSuggestions(query, len("SELECT o."), cache)
+	slices.Sort(got)
+	if want := []string{"customer_id", "id", "total"}; !slices.Equal(got, want) {
+		t.Errorf("suggestions for o. = %q, want %q", got, want)
+	}
+
+	query = "SELECT c.n FROM orders o JOIN customers c ON o.customer_id = c.id"
+	if got := GetContextualSuggestions(query, len("SELECT c.n"), cache); !slices.Equal(got, []string{"name"}) {
+		t.Errorf("suggestions for c.n = %q", got)
+	}
+}
+
+func TestContextualSuggestionsByCatalog(t *testing.T) {
+	cache, err := NewSchemaCache(t.TempDir(), zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cache.db.Close()
+	for _, metadata := range []SchemaMetadata{
+		{Catalog: "hive", Name: "sales", Tables: []TableMetadata{
+			{Name: "orders", Columns: []ColumnMetadata{{Name: "id"}, {Name: "legacy_code"}}},
+		}},
+		{Catalog: "iceberg", Name: "sales", Tables: []TableMetadata{
+			{Name: "orders", Columns: []ColumnMetadata{{Name: "id"}, {Name: "total"}}},
+		}},
+		{Catalog: "iceberg", Name: "logs", Tables: []TableMetadata{
+			{Name: "events", Columns: []ColumnMetadata{{Name: "ts"}}},
+		}},
+	} {
+		if err := cache.StoreSchema(metadata); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"SELECT * FROM iceberg.|", []string{"logs", "sales"}},
+		{"SELECT * FROM iceberg.logs.|", []string{"events"}},
+		{"SELECT * FROM hive.sales.o|", []string{"orders"}},
+		{"SELECT * FROM sales.|", []string{"orders"}},
+		{"SELECT * FROM ic|", []string{"iceberg"}},
+		{"SELECT o.| FROM iceberg.sales.orders o", []string{"id", "total"}},
+		{"SELECT hive.sales.orders.| FROM hive.sales.orders", []string{"id", "legacy_code"}},
+	}
+	for _, tt := range tests {
+		pos := strings.Index(tt.query, cursor)
+		got := GetContextualSuggestions(strings.Replace(tt.query, cursor, "", 1), pos, cache)
+		slices.Sort(got)
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("suggestions for %q = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestContextualSuggestionsScopedToReferencedTables(t *testing.T) {
+	cache, err := NewSchemaCache(t.TempDir(), zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cache.db.Close()
+	err = cache.StoreSchema(SchemaMetadata{Catalog: "hive", Name: "sales", Tables: []TableMetadata{
+		{Name: "orders", Columns: []ColumnMetadata{{Name: "id"}, {Name: "customer_id"}, {Name: "total"}}},
+		{Name: "customers", Columns: []ColumnMetadata{{Name: "id"}, {Name: "name"}}},
+		{Name: "products", Columns: []ColumnMetadata{{Name: "sku"}, {Name: "title"}}},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"SELECT * FROM orders WHERE |", []string{"customer_id", "id", "total"}},
+		{"SELECT * FROM orders o JOIN customers c ON o.customer_id = c.id WHERE |", []string{"customer_id", "id", "name", "total"}},
+		{"SELECT count(*) FROM sales.customers GROUP BY |", []string{"id", "name"}},
+		{"SELECT * FROM products ORDER BY t|", []string{"title"}},
+		{"SELECT * FROM products WHERE sku IN (SELECT id FROM orders WHERE t|)", []string{"title", "total"}},
+
+		// Nothing known about the table, so every column is a candidate
+		{"WITH x AS (SELECT 1) SELECT * FROM x WHERE s|", []string{"sku"}},
+		{"SELECT * FROM (SELECT * FROM products) p WHERE n|", []string{"name"}},
+	}
+	for _, tt := range tests {
+		pos := strings.Index(tt.query, cursor)
+		got := GetContextualSuggestions(strings.Replace(tt.query, cursor, "", 1), pos, cache)
+		slices.Sort(got)
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("suggestions for %q = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
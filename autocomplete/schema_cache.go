@@ -5,7 +5,9 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	_ "github.com/mattn/go-sqlite3"
@@ -14,6 +16,7 @@ import (
 
 // SchemaMetadata represents a complete schema's metadata
 type SchemaMetadata struct {
+	Catalog    string          `json:"catalog"`
 	Name       string          `json:"name"`
 	Tables     []TableMetadata `json:"tables"`
 	LastUpdate time.Time       `json:"last_update"`
@@ -22,6 +25,7 @@ type SchemaMetadata struct {
 // TableMetadata represents a table's metadata
 type TableMetadata struct {
 	Name    string           `json:"name"`
+	Catalog string           `json:"catalog"`
 	Schema  string           `json:"schema"`
 	Columns []ColumnMetadata `json:"columns"`
 }
@@ -32,6 +36,8 @@ type ColumnMetadata struct {
 	DataType string `json:"data_type"`
 	Table    string `json:"table"`
 	Schema   string `json:"schema"`
+	Catalog  string `json:"catalog"`
+	Comment  string `json:"comment,omitempty"`
 }
 
 // SchemaCache manages caching of Trino schema metadata
@@ -42,6 +48,11 @@ type SchemaCache struct {
 	lock        sync.RWMutex
 	logger      *zap.Logger
 	lastRefresh time.Time
+	functions   []FunctionMetadata // Functions of the connected server version
+
+	sessionProperties []SessionPropertyMetadata
+	usage             map[usageKey]usageEntry // Learned scores of picked suggestions
+	version           atomic.Uint64           // Bumped by every change to the cached names
 }
 
 // NewSchemaCache creates a new schema cache
@@ -79,6 +90,7 @@ func NewSchemaCache(cacheDir string, logger *zap.Logger) (*SchemaCache, error) {
 		trie:      NewTrie(),
 		cacheFile: filepath.Join(cacheDir, "schema_cache.json"),
 		logger:    logger,
+		usage:     make(map[usageKey]usageEntry),
 	}
 
 	// Load existing trie data from cache
@@ -91,26 +103,69 @@ func NewSchemaCache(cacheDir string, logger *zap.Logger) (*SchemaCache, error) {
 
 // initCacheDB initializes the SQLite database schema
 func initCacheDB(db *sql.DB) error {
+	// Caches from before catalogs were tracked are dropped and rebuilt by
+	// the next refresh
+	if _, err := db.Exec("SELECT catalog_name FROM schemas LIMIT 0"); err != nil {
+		if _, err := db.Exec(`
+			DROP TABLE IF EXISTS columns;
+			DROP TABLE IF EXISTS tables;
+			DROP TABLE IF EXISTS schemas;
+		`); err != nil {
+			return err
+		}
+	}
+
 	_, err := db.Exec(`
 		CREATE TABLE IF NOT EXISTS schemas (
-			name TEXT PRIMARY KEY,
-			last_update TIMESTAMP
+			catalog_name TEXT,
+			name TEXT,
+			last_update TIMESTAMP,
+			PRIMARY KEY (catalog_name, name)
 		);
 		
 		CREATE TABLE IF NOT EXISTS tables (
+			catalog_name TEXT,
 			name TEXT,
 			schema_name TEXT,
-			PRIMARY KEY (name, schema_name),
-			FOREIGN KEY (schema_name) REFERENCES schemas(name) ON DELETE CASCADE
+			PRIMARY KEY (catalog_name, name, schema_name),
+			FOREIGN KEY (catalog_name, schema_name) REFERENCES schemas(catalog_name, name) ON DELETE CASCADE
 		);
 		
 		CREATE TABLE IF NOT EXISTS columns (
+			catalog_name TEXT,
 			name TEXT,
 			data_type TEXT,
 			table_name TEXT,
 			schema_name TEXT,
-			PRIMARY KEY (name, table_name, schema_name),
-			FOREIGN KEY (table_name, schema_name) REFERENCES tables(name, schema_name) ON DELETE CASCADE
+			comment TEXT,
+			PRIMARY KEY (catalog_name, name, table_name, schema_name),
+			FOREIGN KEY (catalog_name, table_name, schema_name) REFERENCES tables(catalog_name, name, schema_name) ON DELETE CASCADE
+		);
+		
+		CREATE TABLE IF NOT EXISTS functions (
+			server_version TEXT,
+			name TEXT,
+			argument_types TEXT,
+			return_type TEXT,
+			function_type TEXT,
+			description TEXT,
+			PRIMARY KEY (server_version, name, argument_types, function_type)
+		);
+		
+		CREATE TABLE IF NOT EXISTS session_properties (
+			name TEXT PRIMARY KEY,
+			value TEXT,
+			default_value TEXT,
+			type TEXT,
+			description TEXT
+		);
+		
+		CREATE TABLE IF NOT EXISTS suggestion_usage (
+			kind TEXT,
+			word TEXT,
+			score REAL,
+			last_used INTEGER,
+			PRIMARY KEY (kind, word)
 		);
 		
 		CREATE TABLE IF NOT EXISTS sql_keywords (
@@ -118,7 +173,17 @@ func initCacheDB(db *sql.DB) error {
 			score INTEGER
 		);
 	`)
-	return err
+	if err != nil {
+		return err
+	}
+
+	// Caches from before comments were kept gain the column; their comments
+	// fill in as schemas are refreshed
+	if _, err := db.Exec("SELECT comment FROM columns LIMIT 0"); err != nil {
+		_, err = db.Exec("ALTER TABLE columns ADD COLUMN comment TEXT")
+		return err
+	}
+	return nil
 }
 
 // loadTrieFromCache loads the trie from the cache database
@@ -146,19 +211,21 @@ func (sc *SchemaCache) loadTrieFromCache() error {
 		return err
 	}
 
-	// Load schema names
-	schemaRows, err := sc.db.Query("SELECT name FROM schemas")
+	// Load catalog and schema names
+	schemaRows, err := sc.db.Query("SELECT catalog_name, name FROM schemas")
 	if err != nil {
 		return err
 	}
 	defer schemaRows.Close()
 
 	for schemaRows.Next() {
-		var schemaName string
-		if err := schemaRows.Scan(&schemaName); err != nil {
+		var catalogName, schemaName string
+		if err := schemaRows.Scan(&catalogName, &schemaName); err != nil {
 			return err
 		}
+		sc.trie.Insert(catalogName, 500)
 		sc.trie.Insert(schemaName, 500) // Medium priority for schema names
+		sc.trie.Insert(catalogName+"."+schemaName, 450)
 	}
 
 	if err := schemaRows.Err(); err != nil {
@@ -166,19 +233,20 @@ func (sc *SchemaCache) loadTrieFromCache() error {
 	}
 
 	// Load table names
-	tableRows, err := sc.db.Query("SELECT schema_name, name FROM tables")
+	tableRows, err := sc.db.Query("SELECT catalog_name, schema_name, name FROM tables")
 	if err != nil {
 		return err
 	}
 	defer tableRows.Close()
 
 	for tableRows.Next() {
-		var schemaName, tableName string
-		if err := tableRows.Scan(&schemaName, &tableName); err != nil {
+		var catalogName, schemaName, tableName string
+		if err := tableRows.Scan(&catalogName, &schemaName, &tableName); err != nil {
 			return err
 		}
 		sc.trie.Insert(tableName, 400)                // Lower priority for table names
 		sc.trie.Insert(schemaName+"."+tableName, 450) // Higher for fully qualified names
+		sc.trie.Insert(catalogName+"."+schemaName+"."+tableName, 450)
 	}
 
 	if err := tableRows.Err(); err != nil {
@@ -186,15 +254,15 @@ func (sc *SchemaCache) loadTrieFromCache() error {
 	}
 
 	// Load column names
-	columnRows, err := sc.db.Query("SELECT schema_name, table_name, name FROM columns")
+	columnRows, err := sc.db.Query("SELECT name FROM columns")
 	if err != nil {
 		return err
 	}
 	defer columnRows.Close()
 
 	for columnRows.Next() {
-		var schemaName, tableName, columnName string
-		if err := columnRows.Scan(&schemaName, &tableName, &columnName); err != nil {
+		var columnName string
+		if err := columnRows.Scan(&columnName); err != nil {
 			return err
 		}
 		sc.trie.Insert(columnName, 300) // Lower priority for column names
@@ -210,38 +278,58 @@ func (sc *SchemaCache) loadTrieFromCache() error {
 
 // LoadCache initializes the trie with data from the cache database
 func (sc *SchemaCache) LoadCache() error {
-	return sc.loadTrieFromCache()
+	if err := sc.loadTrieFromCache(); err != nil {
+		return err
+	}
+
+	if err := sc.loadSessionProperties(); err != nil {
+		return err
+	}
+	if err := sc.loadUsage(); err != nil {
+		return err
+	}
+
+	// Until the server tells its version, assume it is the last one seen
+	_, err := sc.LoadFunctions("")
+	return err
 }
 
 // StoreSchema stores a schema's metadata in the cache
 func (sc *SchemaCache) StoreSchema(metadata SchemaMetadata) error {
 	sc.lock.Lock()
 	defer sc.lock.Unlock()
+	defer sc.version.Add(1)
 
 	tx, err := sc.db.Begin()
 	if err != nil {
 		return err
 	}
 
-	// Upsert schema
+	// Replace the schema, dropping tables and columns it no longer has
+	if err := deleteSchemaObjects(tx, metadata.Catalog, metadata.Name); err != nil {
+		tx.Rollback()
+		return err
+	}
 	_, err = tx.Exec(
-		"INSERT OR REPLACE INTO schemas (name, last_update) VALUES (?, ?)",
-		metadata.Name, time.Now(),
+		"INSERT OR REPLACE INTO schemas (catalog_name, name, last_update) VALUES (?, ?, ?)",
+		metadata.Catalog, metadata.Name, time.Now(),
 	)
 	if err != nil {
 		tx.Rollback()
 		return err
 	}
 
-	// Add schema name to trie
+	// Add catalog and schema names to trie
+	sc.trie.Insert(metadata.Catalog, 100)
 	sc.trie.Insert(metadata.Name, 100)
+	sc.trie.Insert(metadata.Catalog+"."+metadata.Name, 95)
 
 	// Process tables and columns
 	for _, table := range metadata.Tables {
 		// Upsert table
 		_, err = tx.Exec(
-			"INSERT OR REPLACE INTO tables (name, schema_name) VALUES (?, ?)",
-			table.Name, metadata.Name,
+			"INSERT OR REPLACE INTO tables (catalog_name, name, schema_name) VALUES (?, ?, ?)",
+			metadata.Catalog, table.Name, metadata.Name,
 		)
 		if err != nil {
 			tx.Rollback()
@@ -251,13 +339,14 @@ func (sc *SchemaCache) StoreSchema(metadata SchemaMetadata) error {
 		// Add table names to trie
 		sc.trie.Insert(table.Name, 90)
 		sc.trie.Insert(metadata.Name+"."+table.Name, 95)
+		sc.trie.Insert(metadata.Catalog+"."+metadata.Name+"."+table.Name, 95)
 
 		// Process columns
 		for _, col := range table.Columns {
 			// Upsert column
 			_, err = tx.Exec(
-				"INSERT OR REPLACE INTO columns (name, data_type, table_name, schema_name) VALUES (?, ?, ?, ?)",
-				col.Name, col.DataType, table.Name, metadata.Name,
+				"INSERT OR REPLACE INTO columns (catalog_name, name, data_type, table_name, schema_name, comment) VALUES (?, ?, ?, ?, ?, ?)",
+				metadata.Catalog, col.Name, col.DataType, table.Name, metadata.Name, col.Comment,
 			)
 			if err != nil {
 				tx.Rollback()
@@ -275,80 +364,311 @@ func (sc *SchemaCache) StoreSchema(metadata SchemaMetadata) error {
 	}
 
 	sc.lastRefresh = time.Now()
-	sc.logger.Info("Stored schema in cache", zap.String("schema", metadata.Name))
+	sc.logger.Info("Stored schema in cache",
+		zap.String("catalog", metadata.Catalog),
+		zap.String("schema", metadata.Name))
 	return nil
 }
 
-// GetSuggestions returns autocomplete suggestions for a given prefix
-func (sc *SchemaCache) GetSuggestions(prefix string, limit int) []string {
+// deleteSchemaObjects deletes the cached tables and columns of a schema
+func deleteSchemaObjects(tx *sql.Tx, catalogName, schemaName string) error {
+	if _, err := tx.Exec("DELETE FROM columns WHERE catalog_name = ? AND schema_name = ?", catalogName, schemaName); err != nil {
+		return err
+	}
+	_, err := tx.Exec("DELETE FROM tables WHERE catalog_name = ? AND schema_name = ?", catalogName, schemaName)
+	return err
+}
+
+// DeleteSchema removes a schema that no longer exists from the cache
+func (sc *SchemaCache) DeleteSchema(catalogName, schemaName string) error {
+	sc.lock.Lock()
+	defer sc.lock.Unlock()
+	defer sc.version.Add(1)
+
+	tx, err := sc.db.Begin()
+	if err != nil {
+		return err
+	}
+	if err := deleteSchemaObjects(tx, catalogName, schemaName); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if _, err := tx.Exec("DELETE FROM schemas WHERE catalog_name = ? AND name = ?", catalogName, schemaName); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	sc.logger.Info("Removed schema from cache",
+		zap.String("catalog", catalogName),
+		zap.String("schema", schemaName))
+	return nil
+}
+
+// SyncTables makes the cached tables of a schema match tables, dropping the
+// columns of tables that are gone. Columns of new tables are left for the
+// next refresh, so the schema keeps its last_update. Schemas the cache does
+// not hold, e.g. ones the introspection filter leaves out, are not added.
+func (sc *SchemaCache) SyncTables(catalogName, schemaName string, tables []string) error {
+	sc.lock.Lock()
+	defer sc.lock.Unlock()
+	defer sc.version.Add(1)
+
+	tx, err := sc.db.Begin()
+	if err != nil {
+		return err
+	}
+	var exists int
+	err = tx.QueryRow("SELECT COUNT(*) FROM schemas WHERE catalog_name = ? AND name = ?", catalogName, schemaName).Scan(&exists)
+	if err != nil || exists == 0 {
+		tx.Rollback()
+		return err
+	}
+
+	current := make(map[string]bool, len(tables))
+	for _, table := range tables {
+		current[table] = true
+		if _, err := tx.Exec(
+			"INSERT OR IGNORE INTO tables (catalog_name, name, schema_name) VALUES (?, ?, ?)",
+			catalogName, table, schemaName,
+		); err != nil {
+			tx.Rollback()
+			return err
+		}
+		sc.trie.Insert(table, 90)
+		sc.trie.Insert(schemaName+"."+table, 95)
+		sc.trie.Insert(catalogName+"."+schemaName+"."+table, 95)
+	}
+
+	rows, err := tx.Query("SELECT name FROM tables WHERE catalog_name = ? AND schema_name = ?", catalogName, schemaName)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	var gone []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			tx.Rollback()
+			return err
+		}
+		if !current[name] {
+			gone = append(gone, name)
+		}
+	}
+	rows.Close()
+	for _, table := range gone {
+		if _, err := tx.Exec("DELETE FROM columns WHERE catalog_name = ? AND schema_name = ? AND table_name = ?",
+			catalogName, schemaName, table); err != nil {
+			tx.Rollback()
+			return err
+		}
+		if _, err := tx.Exec("DELETE FROM tables WHERE catalog_name = ? AND schema_name = ? AND name = ?",
+			catalogName, schemaName, table); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// StoreColumns replaces the cached columns of one table. Like SyncTables, it
+// only changes schemas the cache already holds.
+func (sc *SchemaCache) StoreColumns(catalogName, schemaName, tableName string, columns []ColumnMetadata) error {
+	sc.lock.Lock()
+	defer sc.lock.Unlock()
+	defer sc.version.Add(1)
+
+	tx, err := sc.db.Begin()
+	if err != nil {
+		return err
+	}
+	var exists int
+	err = tx.QueryRow("SELECT COUNT(*) FROM schemas WHERE catalog_name = ? AND name = ?", catalogName, schemaName).Scan(&exists)
+	if err != nil || exists == 0 {
+		tx.Rollback()
+		return err
+	}
+
+	if _, err := tx.Exec(
+		"INSERT OR IGNORE INTO tables (catalog_name, name, schema_name) VALUES (?, ?, ?)",
+		catalogName, tableName, schemaName,
+	); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if _, err := tx.Exec("DELETE FROM columns WHERE catalog_name = ? AND schema_name = ? AND table_name = ?",
+		catalogName, schemaName, tableName); err != nil {
+		tx.Rollback()
+		return err
+	}
+	for _, col := range columns {
+		if _, err := tx.Exec(
+			"INSERT OR REPLACE INTO columns (catalog_name, name, data_type, table_name, schema_name, comment) VALUES (?, ?, ?, ?, ?, ?)",
+			catalogName, col.Name, col.DataType, tableName, schemaName, col.Comment,
+		); err != nil {
+			tx.Rollback()
+			return err
+		}
+		sc.trie.Insert(col.Name, 80)
+		sc.trie.Insert(tableName+"."+col.Name, 85)
+	}
+	return tx.Commit()
+}
+
+// GetSchemaUpdates returns when each cached schema of a catalog was last
+// refreshed, by schema name
+func (sc *SchemaCache) GetSchemaUpdates(catalogName string) (map[string]time.Time, error) {
 	sc.lock.RLock()
 	defer sc.lock.RUnlock()
 
-	return sc.trie.GetSuggestions(prefix, limit)
+	rows, err := sc.db.Query("SELECT name, last_update FROM schemas WHERE catalog_name = ?", catalogName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	updates := make(map[string]time.Time)
+	for rows.Next() {
+		var name string
+		var lastUpdate sql.NullTime
+		if err := rows.Scan(&name, &lastUpdate); err != nil {
+			return nil, err
+		}
+		updates[name] = lastUpdate.Time
+	}
+
+	return updates, rows.Err()
 }
 
-// GetFuzzyMatches gets fuzzy-matched suggestions for a prefix
-func (sc *SchemaCache) GetFuzzyMatches(prefix string, maxDistance int, limit int) []string {
+// GetSchemaUpdate returns when a schema was last refreshed, reporting
+// whether it is cached at all
+func (sc *SchemaCache) GetSchemaUpdate(catalogName, schemaName string) (time.Time, bool, error) {
 	sc.lock.RLock()
 	defer sc.lock.RUnlock()
 
-	return sc.trie.GetFuzzyMatches(prefix, maxDistance, limit)
+	var lastUpdate sql.NullTime
+	err := sc.db.QueryRow(
+		"SELECT last_update FROM schemas WHERE catalog_name = ? AND name = ?",
+		catalogName, schemaName,
+	).Scan(&lastUpdate)
+	if err == sql.ErrNoRows {
+		return time.Time{}, false, nil
+	}
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	return lastUpdate.Time, true, nil
 }
 
-// GetSchemas returns all schema names from the cache
-func (sc *SchemaCache) GetSchemas() ([]string, error) {
+// GetCatalogTables returns the cached table names of every schema in a
+// catalog, by schema name
+func (sc *SchemaCache) GetCatalogTables(catalogName string) (map[string][]string, error) {
 	sc.lock.RLock()
 	defer sc.lock.RUnlock()
 
-	rows, err := sc.db.Query("SELECT name FROM schemas")
+	rows, err := sc.db.Query("SELECT schema_name, name FROM tables WHERE catalog_name = ?", catalogName)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var schemas []string
+	tables := make(map[string][]string)
 	for rows.Next() {
-		var name string
-		if err := rows.Scan(&name); err != nil {
+		var schemaName, tableName string
+		if err := rows.Scan(&schemaName, &tableName); err != nil {
 			return nil, err
 		}
-		schemas = append(schemas, name)
+		tables[schemaName] = append(tables[schemaName], tableName)
 	}
 
-	return schemas, nil
+	return tables, rows.Err()
+}
+
+// GetSuggestions returns autocomplete suggestions for a given prefix
+func (sc *SchemaCache) GetSuggestions(prefix string, limit int) []string {
+	sc.lock.RLock()
+	defer sc.lock.RUnlock()
+
+	return sc.trie.GetSuggestions(prefix, limit)
+}
+
+// GetFuzzyMatches gets fuzzy-matched suggestions for a prefix
+func (sc *SchemaCache) GetFuzzyMatches(prefix string, maxDistance int, limit int) []string {
+	sc.lock.RLock()
+	defer sc.lock.RUnlock()
+
+	return sc.trie.GetFuzzyMatches(prefix, maxDistance, limit)
+}
+
+// GetCatalogs returns all catalog names from the cache
+func (sc *SchemaCache) GetCatalogs() ([]string, error) {
+	sc.lock.RLock()
+	defer sc.lock.RUnlock()
+
+	return sc.queryNames("SELECT DISTINCT catalog_name FROM schemas ORDER BY catalog_name")
 }
 
-// GetTables returns all table names for a schema from the cache
-func (sc *SchemaCache) GetTables(schemaName string) ([]string, error) {
+// GetSchemas returns the schema names in a catalog from the cache, or the
+// distinct schema names across all catalogs when catalogName is empty
+func (sc *SchemaCache) GetSchemas(catalogName string) ([]string, error) {
 	sc.lock.RLock()
 	defer sc.lock.RUnlock()
 
-	rows, err := sc.db.Query("SELECT name FROM tables WHERE schema_name = ?", schemaName)
+	if catalogName == "" {
+		return sc.queryNames("SELECT DISTINCT name FROM schemas")
+	}
+	return sc.queryNames("SELECT name FROM schemas WHERE catalog_name = ?", catalogName)
+}
+
+// GetTables returns all table names for a schema from the cache. An empty
+// catalogName matches the schema in any catalog.
+func (sc *SchemaCache) GetTables(catalogName, schemaName string) ([]string, error) {
+	sc.lock.RLock()
+	defer sc.lock.RUnlock()
+
+	return sc.queryNames(
+		"SELECT DISTINCT name FROM tables WHERE schema_name = ? AND (? = '' OR catalog_name = ?)",
+		schemaName, catalogName, catalogName,
+	)
+}
+
+// queryNames runs a query selecting a single text column. Callers must
+// hold sc.lock.
+func (sc *SchemaCache) queryNames(query string, args ...any) ([]string, error) {
+	rows, err := sc.db.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var tables []string
+	var names []string
 	for rows.Next() {
 		var name string
 		if err := rows.Scan(&name); err != nil {
 			return nil, err
 		}
-		tables = append(tables, name)
+		names = append(names, name)
 	}
 
-	return tables, nil
+	return names, rows.Err()
 }
 
-// GetColumns returns all column names for a table from the cache
-func (sc *SchemaCache) GetColumns(schemaName, tableName string) ([]ColumnMetadata, error) {
+// GetColumns returns all column names for a table from the cache, in the
+// order they were stored. An empty catalogName matches the table in any
+// catalog.
+func (sc *SchemaCache) GetColumns(catalogName, schemaName, tableName string) ([]ColumnMetadata, error) {
 	sc.lock.RLock()
 	defer sc.lock.RUnlock()
 
 	rows, err := sc.db.Query(
-		"SELECT name, data_type FROM columns WHERE schema_name = ? AND table_name = ?",
-		schemaName, tableName,
+		`SELECT catalog_name, name, data_type, COALESCE(comment, '') FROM columns
+		WHERE schema_name = ? AND table_name = ? AND (? = '' OR catalog_name = ?)
+		ORDER BY rowid`,
+		schemaName, tableName, catalogName, catalogName,
 	)
 	if err != nil {
 		return nil, err
@@ -358,7 +678,7 @@ func (sc *SchemaCache) GetColumns(schemaName, tableName string) ([]ColumnMetadat
 	var columns []ColumnMetadata
 	for rows.Next() {
 		var col ColumnMetadata
-		if err := rows.Scan(&col.Name, &col.DataType); err != nil {
+		if err := rows.Scan(&col.Catalog, &col.Name, &col.DataType, &col.Comment); err != nil {
 			return nil, err
 		}
 		col.Table = tableName
@@ -369,92 +689,236 @@ func (sc *SchemaCache) GetColumns(schemaName, tableName string) ([]ColumnMetadat
 	return columns, nil
 }
 
-// GetAllColumns returns all column names from the cache
-func (sc *SchemaCache) GetAllColumns() ([]string, error) {
+// likePrefix returns a LIKE pattern matching names that start with prefix,
+// for use with ESCAPE '\'
+func likePrefix(prefix string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(prefix) + "%"
+}
+
+// GetAllColumns returns up to limit distinct column names from the cache
+// that could match word, ignoring case: those starting with its first
+// letter, the ones starting with all of it first
+func (sc *SchemaCache) GetAllColumns(word string, limit int) ([]string, error) {
+	return candidateNames(sc.allColumns(word, limit))
+}
+
+// GetAllTables returns up to limit distinct table names from the cache
+// that could match word, as GetAllColumns does
+func (sc *SchemaCache) GetAllTables(word string, limit int) ([]string, error) {
+	return candidateNames(sc.allTables(word, limit))
+}
+
+// GetAllSchemaQualifiedTables returns up to limit schema-qualified table
+// names (schema.table) from the cache that could match word, as
+// GetAllColumns does
+func (sc *SchemaCache) GetAllSchemaQualifiedTables(word string, limit int) ([]string, error) {
+	return candidateNames(sc.allSchemaQualifiedTables(word, limit))
+}
+
+// candidate is a name from the cache with the catalog and schema it is in,
+// left empty when it is in more than one
+type candidate struct {
+	Name    string
+	Catalog string
+	Schema  string
+}
+
+func (sc *SchemaCache) allColumns(word string, limit int) ([]candidate, error) {
 	sc.lock.RLock()
 	defer sc.lock.RUnlock()
 
-	rows, err := sc.db.Query("SELECT DISTINCT name FROM columns")
+	return sc.queryCandidates("name", "columns", word, limit)
+}
+
+func (sc *SchemaCache) allTables(word string, limit int) ([]candidate, error) {
+	sc.lock.RLock()
+	defer sc.lock.RUnlock()
+
+	return sc.queryCandidates("name", "tables", word, limit)
+}
+
+func (sc *SchemaCache) allSchemaQualifiedTables(word string, limit int) ([]candidate, error) {
+	sc.lock.RLock()
+	defer sc.lock.RUnlock()
+
+	return sc.queryCandidates("schema_name || '.' || name", "tables", word, limit)
+}
+
+// candidateNames returns the names of candidates
+func candidateNames(candidates []candidate, err error) ([]string, error) {
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(candidates))
+	for i, c := range candidates {
+		names[i] = c.Name
+	}
+	return names, nil
+}
+
+// queryCandidates selects up to limit distinct values of expr from table
+// that start with the first letter of word, ranking those starting with
+// all of it first. Callers must hold sc.lock.
+func (sc *SchemaCache) queryCandidates(expr, table, word string, limit int) ([]candidate, error) {
+	first := ""
+	if r := []rune(word); len(r) > 0 {
+		first = string(r[0])
+	}
+	rows, err := sc.db.Query(
+		`SELECT `+expr+` AS candidate,
+			CASE WHEN COUNT(DISTINCT catalog_name) = 1 THEN MIN(catalog_name) ELSE '' END,
+			CASE WHEN COUNT(DISTINCT catalog_name || '.' || schema_name) = 1 THEN MIN(schema_name) ELSE '' END
+		FROM `+table+`
+		WHERE candidate LIKE ? ESCAPE '\'
+		GROUP BY candidate
+		ORDER BY candidate LIKE ? ESCAPE '\' DESC
+		LIMIT ?`,
+		likePrefix(first), likePrefix(word), limit,
+	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var columns []string
+	var candidates []candidate
 	for rows.Next() {
-		var name string
-		if err := rows.Scan(&name); err != nil {
+		var c candidate
+		if err := rows.Scan(&c.Name, &c.Catalog, &c.Schema); err != nil {
 			return nil, err
 		}
-		columns = append(columns, name)
+		candidates = append(candidates, c)
 	}
+	return candidates, rows.Err()
+}
 
-	return columns, nil
+// namesVersion returns a number that changes whenever the cached schemas,
+// tables or columns do
+func (sc *SchemaCache) namesVersion() uint64 {
+	return sc.version.Load()
 }
 
-// GetAllTables returns all table names from the cache
-func (sc *SchemaCache) GetAllTables() ([]string, error) {
+// catalogNames returns the lower-cased names of the schemas, tables and
+// columns cached for catalogName. Tables and columns map to whether one of
+// them is in schemaName.
+func (sc *SchemaCache) catalogNames(catalogName, schemaName string) (map[string]bool, map[string]bool, map[string]bool, error) {
 	sc.lock.RLock()
 	defer sc.lock.RUnlock()
 
-	rows, err := sc.db.Query("SELECT DISTINCT name FROM tables")
+	schemas, err := sc.queryNameSet(
+		"SELECT lower(name), 0 FROM schemas WHERE catalog_name = ? GROUP BY lower(name)",
+		catalogName)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	tables, err := sc.queryNameSet(
+		"SELECT lower(name), MAX(lower(schema_name) = lower(?)) FROM tables WHERE catalog_name = ? GROUP BY lower(name)",
+		schemaName, catalogName)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	columns, err := sc.queryNameSet(
+		"SELECT lower(name), MAX(lower(schema_name) = lower(?)) FROM columns WHERE catalog_name = ? GROUP BY lower(name)",
+		schemaName, catalogName)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return schemas, tables, columns, nil
+}
+
+// queryNameSet runs a query selecting a name and a flag. Callers must hold
+// sc.lock.
+func (sc *SchemaCache) queryNameSet(query string, args ...any) (map[string]bool, error) {
+	rows, err := sc.db.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var tables []string
+	names := make(map[string]bool)
 	for rows.Next() {
 		var name string
-		if err := rows.Scan(&name); err != nil {
+		var flag bool
+		if err := rows.Scan(&name, &flag); err != nil {
 			return nil, err
 		}
-		tables = append(tables, name)
+		names[name] = flag
+	}
+	return names, rows.Err()
+}
+
+// FindTables returns up to limit cached tables, in any schema, whose names
+// start with prefix
+func (sc *SchemaCache) FindTables(prefix string, limit int) ([]TableMetadata, error) {
+	sc.lock.RLock()
+	defer sc.lock.RUnlock()
+
+	rows, err := sc.db.Query(
+		`SELECT catalog_name, schema_name, name FROM tables
+		WHERE name LIKE ? ESCAPE '\' LIMIT ?`,
+		likePrefix(prefix), limit,
+	)
+	if err != nil {
+		return nil, err
 	}
+	defer rows.Close()
 
-	return tables, nil
+	var tables []TableMetadata
+	for rows.Next() {
+		var table TableMetadata
+		if err := rows.Scan(&table.Catalog, &table.Schema, &table.Name); err != nil {
+			return nil, err
+		}
+		tables = append(tables, table)
+	}
+
+	return tables, rows.Err()
 }
 
-// GetAllSchemaQualifiedTables returns all schema-qualified table names (schema.table) from the cache
-func (sc *SchemaCache) GetAllSchemaQualifiedTables() ([]string, error) {
+// FindColumns returns up to limit cached columns whose names start with
+// prefix, in the given schema or in any schema when it is empty
+func (sc *SchemaCache) FindColumns(schemaName, prefix string, limit int) ([]ColumnMetadata, error) {
 	sc.lock.RLock()
 	defer sc.lock.RUnlock()
 
-	rows, err := sc.db.Query("SELECT schema_name, name FROM tables")
+	rows, err := sc.db.Query(
+		`SELECT catalog_name, schema_name, table_name, name, data_type FROM columns
+		WHERE (? = '' OR schema_name = ?) AND name LIKE ? ESCAPE '\' LIMIT ?`,
+		schemaName, schemaName, likePrefix(prefix), limit,
+	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var tables []string
+	var columns []ColumnMetadata
 	for rows.Next() {
-		var schemaName, tableName string
-		if err := rows.Scan(&schemaName, &tableName); err != nil {
+		var col ColumnMetadata
+		if err := rows.Scan(&col.Catalog, &col.Schema, &col.Table, &col.Name, &col.DataType); err != nil {
 			return nil, err
 		}
-		tables = append(tables, schemaName+"."+tableName)
+		columns = append(columns, col)
 	}
 
-	return tables, nil
+	return columns, rows.Err()
 }
 
 // Close closes the schema cache and database connection
 func (sc *SchemaCache) Close() error {
-	sc.lock.Lock()
-	defer sc.lock.Unlock()
-
-	// Export cache to JSON before closing
+	// Export cache to JSON before closing. The export reads through the
+	// locking getters, so it runs before the lock is taken.
 	if err := sc.exportToJSON(); err != nil {
 		sc.logger.Warn("Failed to export cache to JSON", zap.Error(err))
 	}
 
+	sc.lock.Lock()
+	defer sc.lock.Unlock()
 	return sc.db.Close()
 }
 
 // exportToJSON exports the cache to a JSON file for persistence
 func (sc *SchemaCache) exportToJSON() error {
 	// Get all schemas
-	rows, err := sc.db.Query("SELECT name, last_update FROM schemas")
+	rows, err := sc.db.Query("SELECT catalog_name, name, last_update FROM schemas")
 	if err != nil {
 		return err
 	}
@@ -464,13 +928,13 @@ func (sc *SchemaCache) exportToJSON() error {
 	for rows.Next() {
 		var schema SchemaMetadata
 		var lastUpdate time.Time
-		if err := rows.Scan(&schema.Name, &lastUpdate); err != nil {
+		if err := rows.Scan(&schema.Catalog, &schema.Name, &lastUpdate); err != nil {
 			return err
 		}
 		schema.LastUpdate = lastUpdate
 
 		// Get tables for this schema
-		tables, err := sc.GetTables(schema.Name)
+		tables, err := sc.GetTables(schema.Catalog, schema.Name)
 		if err != nil {
 			return err
 		}
@@ -478,11 +942,12 @@ func (sc *SchemaCache) exportToJSON() error {
 		// Get columns for each table
 		for _, tableName := range tables {
 			table := TableMetadata{
-				Name:   tableName,
-				Schema: schema.Name,
+				Name:    tableName,
+				Catalog: schema.Catalog,
+				Schema:  schema.Name,
 			}
 
-			columns, err := sc.GetColumns(schema.Name, tableName)
+			columns, err := sc.GetColumns(schema.Catalog, schema.Name, tableName)
 			if err != nil {
 				return err
 			}
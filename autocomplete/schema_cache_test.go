@@ -0,0 +1,184 @@
+package autocomplete
+
+import (
+	"database/sql"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestSchemaCacheRebuildsCacheWithoutCatalogs(t *testing.T) {
+	dir := t.TempDir()
+	db, err := sql.Open("sqlite3", filepath.Join(dir, "schema_cache.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = db.Exec(`
+		CREATE TABLE schemas (name TEXT PRIMARY KEY, last_update TIMESTAMP);
+		CREATE TABLE tables (name TEXT, schema_name TEXT, PRIMARY KEY (name, schema_name));
+		INSERT INTO schemas VALUES ('sales', NULL);
+		INSERT INTO tables VALUES ('orders', 'sales');
+	`)
+	db.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cache, err := NewSchemaCache(dir, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cache.db.Close()
+
+	if schemas, err := cache.GetSchemas(""); err != nil || len(schemas) != 0 {
+		t.Errorf("old cache should be dropped, got %q, %v", schemas, err)
+	}
+	err = cache.StoreSchema(SchemaMetadata{Catalog: "hive", Name: "sales", Tables: []TableMetadata{{Name: "orders"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if catalogs, _ := cache.GetCatalogs(); !slices.Equal(catalogs, []string{"hive"}) {
+		t.Errorf("catalogs = %q", catalogs)
+	}
+	if tables, _ := cache.GetTables("hive", "sales"); !slices.Equal(tables, []string{"orders"}) {
+		t.Errorf("tables = %q", tables)
+	}
+	if tables, _ := cache.GetTables("iceberg", "sales"); len(tables) != 0 {
+		t.Errorf("tables in another catalog = %q", tables)
+	}
+}
+
+func TestSchemaCacheLookupsAreBoundedByPrefix(t *testing.T) {
+	cache, err := NewSchemaCache(t.TempDir(), zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cache.db.Close()
+
+	var tables []TableMetadata
+	for _, name := range []string{"orders", "order_items", "orgs", "orderXitems", "users"} {
+		tables = append(tables, TableMetadata{Name: name, Columns: []ColumnMetadata{{Name: name + "_id", DataType: "bigint"}}})
+	}
+	if err := cache.StoreSchema(SchemaMetadata{Catalog: "hive", Name: "sales", Tables: tables}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Every name with the first letter is a candidate for fuzzy matching
+	names, err := cache.GetAllTables("ORD", 10)
+	slices.Sort(names)
+	if err != nil || !slices.Equal(names, []string{"orderXitems", "order_items", "orders", "orgs"}) {
+		t.Errorf("GetAllTables(ORD) = %q, %v", names, err)
+	}
+	// Within the limit, names with the whole prefix come first, and _
+	// matches itself rather than any character
+	if names, err := cache.GetAllTables("order_", 1); err != nil || !slices.Equal(names, []string{"order_items"}) {
+		t.Errorf("GetAllTables(order_) limited to 1 = %q, %v", names, err)
+	}
+	if names, err := cache.GetAllTables("org", 1); err != nil || !slices.Equal(names, []string{"orgs"}) {
+		t.Errorf("GetAllTables(org) limited to 1 = %q, %v", names, err)
+	}
+	if names, err := cache.GetAllSchemaQualifiedTables("sales.us", 10); err != nil || len(names) != 5 {
+		t.Errorf("GetAllSchemaQualifiedTables = %q, %v", names, err)
+	}
+	if names, err := cache.GetAllColumns("u", 10); err != nil || !slices.Equal(names, []string{"users_id"}) {
+		t.Errorf("GetAllColumns(u) = %q, %v", names, err)
+	}
+
+	found, err := cache.FindTables("org", 10)
+	if err != nil || len(found) != 1 || found[0].Catalog != "hive" || found[0].Schema != "sales" || found[0].Name != "orgs" {
+		t.Errorf("FindTables(org) = %+v, %v", found, err)
+	}
+	columns, err := cache.FindColumns("sales", "users", 10)
+	if err != nil || len(columns) != 1 || columns[0].Table != "users" || columns[0].DataType != "bigint" {
+		t.Errorf("FindColumns(sales, users) = %+v, %v", columns, err)
+	}
+	if columns, err := cache.FindColumns("other", "users", 10); err != nil || len(columns) != 0 {
+		t.Errorf("FindColumns in another schema = %+v, %v", columns, err)
+	}
+}
+
+func TestSchemaCacheSyncsTablesAndColumns(t *testing.T) {
+	cache, err := NewSchemaCache(t.TempDir(), zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cache.db.Close()
+
+	// Schemas autocomplete has not introspected are left alone
+	if err := cache.SyncTables("hive", "sales", []string{"orders"}); err != nil {
+		t.Fatal(err)
+	}
+	if schemas, _ := cache.GetSchemas("hive"); len(schemas) != 0 {
+		t.Errorf("SyncTables added schemas %q", schemas)
+	}
+
+	err = cache.StoreSchema(SchemaMetadata{Catalog: "hive", Name: "sales", Tables: []TableMetadata{
+		{Name: "orders", Columns: []ColumnMetadata{{Name: "id", DataType: "bigint"}}},
+		{Name: "refunds", Columns: []ColumnMetadata{{Name: "id", DataType: "bigint"}}},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cache.SyncTables("hive", "sales", []string{"orders", "users"}); err != nil {
+		t.Fatal(err)
+	}
+	tables, _ := cache.GetTables("hive", "sales")
+	slices.Sort(tables)
+	if !slices.Equal(tables, []string{"orders", "users"}) {
+		t.Errorf("tables = %q", tables)
+	}
+	if columns, _ := cache.GetColumns("hive", "sales", "refunds"); len(columns) != 0 {
+		t.Errorf("a dropped table kept its columns %+v", columns)
+	}
+	if columns, _ := cache.GetColumns("hive", "sales", "orders"); len(columns) != 1 {
+		t.Errorf("syncing tables lost the columns of one that remains: %+v", columns)
+	}
+
+	err = cache.StoreColumns("hive", "sales", "users", []ColumnMetadata{
+		{Name: "name", DataType: "varchar", Comment: "Display name"},
+		{Name: "id", DataType: "bigint"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	columns, err := cache.GetColumns("hive", "sales", "users")
+	if err != nil || len(columns) != 2 || columns[0].Comment != "Display name" || columns[1].DataType != "bigint" {
+		t.Errorf("GetColumns(users) = %+v, %v", columns, err)
+	}
+	if names, _ := cache.GetAllColumns("nam", 10); !slices.Equal(names, []string{"name"}) {
+		t.Errorf("stored columns are not completed: %q", names)
+	}
+}
+
+func TestSchemaCacheAddsCommentsToOldCaches(t *testing.T) {
+	dir := t.TempDir()
+	cache, err := NewSchemaCache(dir, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = cache.StoreSchema(SchemaMetadata{Catalog: "hive", Name: "sales", Tables: []TableMetadata{
+		{Name: "orders", Columns: []ColumnMetadata{{Name: "id", DataType: "bigint"}}},
+	}})
+	if err == nil {
+		_, err = cache.db.Exec("ALTER TABLE columns DROP COLUMN comment")
+	}
+	cache.db.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cache, err = NewSchemaCache(dir, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cache.db.Close()
+	if columns, err := cache.GetColumns("hive", "sales", "orders"); err != nil || len(columns) != 1 || columns[0].Comment != "" {
+		t.Errorf("columns kept from before comments = %+v, %v", columns, err)
+	}
+	err = cache.StoreColumns("hive", "sales", "orders", []ColumnMetadata{{Name: "id", DataType: "bigint", Comment: "Order number"}})
+	if columns, _ := cache.GetColumns("hive", "sales", "orders"); err != nil || len(columns) != 1 || columns[0].Comment != "Order number" {
+		t.Errorf("columns = %+v, %v", columns, err)
+	}
+}
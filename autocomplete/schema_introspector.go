@@ -3,6 +3,9 @@ package autocomplete
 import (
 	"context"
 	"database/sql"
+	"fmt"
+	"slices"
+	"strings"
 	"sync"
 	"time"
 
@@ -17,8 +20,25 @@ type SchemaIntrospector struct {
 	refreshInterval   time.Duration
 	lastRefresh       time.Time
 	stopRefresh       chan struct{}
+	refreshCtx        context.Context // Bounds the background refresh goroutine
 	backgroundRefresh bool
 	mu                sync.Mutex
+
+	// Schemas are re-read once older than their TTL, which is shorter for
+	// schemas completed from recently
+	schemaTTL       time.Duration
+	activeSchemaTTL time.Duration
+
+	accessMu sync.Mutex
+	filter   introspectionFilter     // Catalogs and schemas to introspect
+	accessed map[schemaKey]time.Time // When each schema was last completed from
+	demanded map[schemaKey]time.Time // When each schema was last refreshed on demand
+}
+
+// schemaKey identifies a schema in a catalog
+type schemaKey struct {
+	catalog string
+	schema  string
 }
 
 // NewSchemaIntrospector creates a new schema introspector
@@ -38,6 +58,10 @@ func NewSchemaIntrospector(db *sql.DB, cache *SchemaCache, logger *zap.Logger) *
 		logger:          logger,
 		refreshInterval: 30 * time.Minute, // Default refresh every 30 minutes
 		stopRefresh:     make(chan struct{}),
+		schemaTTL:       24 * time.Hour,
+		activeSchemaTTL: 10 * time.Minute,
+		accessed:        make(map[schemaKey]time.Time),
+		demanded:        make(map[schemaKey]time.Time),
 	}
 }
 
@@ -56,19 +80,30 @@ func (si *SchemaIntrospector) SetRefreshInterval(interval time.Duration) {
 				zap.Duration("interval", interval))
 
 			// Stop the current refresh goroutine
-			si.stopRefresh <- struct{}{}
+			close(si.stopRefresh)
 
 			// Create a new channel for the new goroutine
 			si.stopRefresh = make(chan struct{})
 
 			// Start a new refresh goroutine
-			go si.runBackgroundRefresh()
+			go si.runBackgroundRefresh(si.refreshCtx, interval, si.stopRefresh)
 		}
 	}
 }
 
-// StartBackgroundRefresh begins a background goroutine that refreshes schema metadata
-func (si *SchemaIntrospector) StartBackgroundRefresh() {
+// SetSchemaTTLs sets how old a cached schema may get before a refresh
+// re-reads it, for schemas in general and for those completed from within
+// the last TTL
+func (si *SchemaIntrospector) SetSchemaTTLs(ttl, activeTTL time.Duration) {
+	si.accessMu.Lock()
+	defer si.accessMu.Unlock()
+	si.schemaTTL = ttl
+	si.activeSchemaTTL = activeTTL
+}
+
+// StartBackgroundRefresh begins a background goroutine that refreshes schema metadata.
+// The goroutine exits when ctx is cancelled or StopBackgroundRefresh is called.
+func (si *SchemaIntrospector) StartBackgroundRefresh(ctx context.Context) {
 	si.mu.Lock()
 	defer si.mu.Unlock()
 
@@ -78,24 +113,34 @@ func (si *SchemaIntrospector) StartBackgroundRefresh() {
 	}
 
 	si.backgroundRefresh = true
+	si.refreshCtx = ctx
+	si.stopRefresh = make(chan struct{})
 	si.logger.Info("Starting background schema refresh",
 		zap.Duration("interval", si.refreshInterval))
 
-	go si.runBackgroundRefresh()
+	go si.runBackgroundRefresh(ctx, si.refreshInterval, si.stopRefresh)
 }
 
 // runBackgroundRefresh is the goroutine that periodically refreshes schema metadata
-func (si *SchemaIntrospector) runBackgroundRefresh() {
-	ticker := time.NewTicker(si.refreshInterval)
+func (si *SchemaIntrospector) runBackgroundRefresh(ctx context.Context, interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
 	for {
 		select {
 		case <-ticker.C:
-			if err := si.RefreshAll(); err != nil {
+			if err := si.RefreshAll(ctx); err != nil {
 				si.logger.Error("Background refresh failed", zap.Error(err))
 			}
-		case <-si.stopRefresh:
+		case <-ctx.Done():
+			si.mu.Lock()
+			if si.stopRefresh == stop {
+				si.backgroundRefresh = false
+			}
+			si.mu.Unlock()
+			si.logger.Info("Background refresh cancelled")
+			return
+		case <-stop:
 			si.logger.Info("Background refresh stopped")
 			return
 		}
@@ -112,145 +157,359 @@ func (si *SchemaIntrospector) StopBackgroundRefresh() {
 	}
 
 	si.backgroundRefresh = false
-	si.stopRefresh <- struct{}{}
+	close(si.stopRefresh)
 }
 
-// RefreshAll refreshes all schema metadata
-func (si *SchemaIntrospector) RefreshAll() error {
+// RefreshAll brings the cached metadata of every catalog up to date. Only
+// schemas that are new, whose tables changed or that outlived their TTL
+// are re-read; schemas that no longer exist are dropped.
+func (si *SchemaIntrospector) RefreshAll(ctx context.Context) error {
 	si.mu.Lock()
 	defer si.mu.Unlock()
 
-	si.logger.Info("Starting full schema refresh")
+	si.logger.Info("Starting schema refresh")
 
-	// Get all schemas
-	schemas, err := si.GetSchemas()
+	// Get all catalogs
+	catalogs, err := si.GetCatalogs(ctx)
 	if err != nil {
 		return err
 	}
 
-	for _, schemaName := range schemas {
-		// Skip internal schemas
-		if schemaName == "information_schema" || schemaName == "system" {
+	// Catalogs bring their own session properties
+	if err := si.RefreshSessionProperties(ctx); err != nil {
+		si.logger.Warn("Failed to refresh session properties", zap.Error(err))
+	}
+
+	filter := si.currentFilter()
+	var refreshed, unchanged, removed int
+	for _, catalogName := range catalogs {
+		if !filter.includesCatalog(catalogName) {
+			n, err := si.dropCatalog(catalogName)
+			if err != nil {
+				return err
+			}
+			removed += n
 			continue
 		}
 
-		si.logger.Debug("Refreshing schema", zap.String("schema", schemaName))
-
-		// Build SchemaMetadata object
-		metadata := SchemaMetadata{
-			Name:       schemaName,
-			LastUpdate: time.Now(),
+		// Get all schemas
+		schemas, err := si.GetSchemas(ctx, catalogName)
+		if err != nil {
+			// One unreachable connector shouldn't hide the other catalogs
+			si.logger.Error("Failed to get schemas",
+				zap.String("catalog", catalogName),
+				zap.Error(err))
+			continue
 		}
 
-		// Get tables for this schema
-		tables, err := si.GetTables(schemaName)
+		// One listing of the catalog's tables tells which schemas changed
+		listing, err := si.GetTableListing(ctx, catalogName)
 		if err != nil {
-			si.logger.Error("Failed to get tables",
-				zap.String("schema", schemaName),
+			si.logger.Error("Failed to list tables",
+				zap.String("catalog", catalogName),
 				zap.Error(err))
 			continue
 		}
+		updated, err := si.cache.GetSchemaUpdates(catalogName)
+		if err != nil {
+			return err
+		}
+		cachedTables, err := si.cache.GetCatalogTables(catalogName)
+		if err != nil {
+			return err
+		}
 
-		// For each table, get columns
-		for _, tableName := range tables {
-			tableMetadata := TableMetadata{
-				Name:   tableName,
-				Schema: schemaName,
+		current := make(map[string]bool, len(schemas))
+		for _, schemaName := range schemas {
+			// Stop early rather than failing every remaining lookup
+			if err := ctx.Err(); err != nil {
+				return err
 			}
 
-			columns, err := si.GetColumns(schemaName, tableName)
-			if err != nil {
-				si.logger.Error("Failed to get columns",
+			// Skip internal and filtered out schemas
+			if schemaName == "information_schema" || schemaName == "system" ||
+				!filter.includesSchema(catalogName, schemaName) {
+				continue
+			}
+			current[schemaName] = true
+
+			lastUpdate, cached := updated[schemaName]
+			reason := si.staleReason(schemaKey{catalogName, schemaName}, cached, lastUpdate,
+				cachedTables[schemaName], listing[schemaName])
+			if reason == "" {
+				unchanged++
+				continue
+			}
+
+			si.logger.Debug("Schema needs refresh",
+				zap.String("catalog", catalogName),
+				zap.String("schema", schemaName),
+				zap.String("reason", reason))
+			if err := si.refreshSchema(ctx, catalogName, schemaName); err != nil {
+				si.logger.Error("Failed to refresh schema",
+					zap.String("catalog", catalogName),
 					zap.String("schema", schemaName),
-					zap.String("table", tableName),
 					zap.Error(err))
 				continue
 			}
+			refreshed++
+		}
 
-			tableMetadata.Columns = columns
-			metadata.Tables = append(metadata.Tables, tableMetadata)
+		for schemaName := range updated {
+			if current[schemaName] {
+				continue
+			}
+			if err := si.cache.DeleteSchema(catalogName, schemaName); err != nil {
+				return err
+			}
+			removed++
 		}
+	}
+
+	si.lastRefresh = time.Now()
+	si.logger.Info("Schema refresh complete",
+		zap.Int("refreshed", refreshed),
+		zap.Int("unchanged", unchanged),
+		zap.Int("removed", removed))
+	return nil
+}
 
-		// Store this schema in the cache
-		if err := si.cache.StoreSchema(metadata); err != nil {
-			si.logger.Error("Failed to store schema in cache",
+// dropCatalog removes the cached schemas of a catalog that is filtered out,
+// returning how many there were
+func (si *SchemaIntrospector) dropCatalog(catalogName string) (int, error) {
+	updated, err := si.cache.GetSchemaUpdates(catalogName)
+	if err != nil {
+		return 0, err
+	}
+	for schemaName := range updated {
+		if err := si.cache.DeleteSchema(catalogName, schemaName); err != nil {
+			return 0, err
+		}
+	}
+	return len(updated), nil
+}
+
+// staleReason tells why a schema must be re-read, or returns "" when its
+// cached metadata is still current. cachedTables and tables are its table
+// names in the cache and on the server.
+func (si *SchemaIntrospector) staleReason(key schemaKey, cached bool, lastUpdate time.Time, cachedTables, tables []string) string {
+	if !cached {
+		return "new"
+	}
+	if !sameNames(cachedTables, tables) {
+		return "tables changed"
+	}
+	if time.Since(lastUpdate) > si.schemaTTLFor(key) {
+		return "expired"
+	}
+	return ""
+}
+
+// schemaTTLFor returns how long a schema's cached metadata stays current
+func (si *SchemaIntrospector) schemaTTLFor(key schemaKey) time.Duration {
+	si.accessMu.Lock()
+	defer si.accessMu.Unlock()
+	if accessed, ok := si.accessed[key]; ok && time.Since(accessed) < si.schemaTTL {
+		return si.activeSchemaTTL
+	}
+	return si.schemaTTL
+}
+
+// sameNames reports whether a and b hold the same names in any order
+func sameNames(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	counts := make(map[string]int, len(a))
+	for _, name := range a {
+		counts[name]++
+	}
+	for _, name := range b {
+		if counts[name]--; counts[name] < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// RefreshOnDemand marks a schema that is being completed from as in use
+// and, unless it was cached or refreshed on demand within the active TTL,
+// refreshes it in the background. Schemas that turn out not to exist, or
+// that are filtered out, are not cached.
+func (si *SchemaIntrospector) RefreshOnDemand(ctx context.Context, catalogName, schemaName string) {
+	key := schemaKey{catalogName, schemaName}
+	now := time.Now()
+
+	si.accessMu.Lock()
+	if !si.filter.includesSchema(catalogName, schemaName) {
+		si.accessMu.Unlock()
+		return
+	}
+	si.accessed[key] = now
+	ttl := si.activeSchemaTTL
+	if demanded, ok := si.demanded[key]; ok && now.Sub(demanded) < ttl {
+		si.accessMu.Unlock()
+		return
+	}
+	si.demanded[key] = now
+	si.accessMu.Unlock()
+
+	lastUpdate, cached, err := si.cache.GetSchemaUpdate(catalogName, schemaName)
+	if err != nil || cached && now.Sub(lastUpdate) < ttl {
+		return
+	}
+
+	go func() {
+		if err := si.refreshKnownSchema(ctx, catalogName, schemaName, cached); err != nil {
+			si.logger.Warn("On-demand schema refresh failed",
+				zap.String("catalog", catalogName),
 				zap.String("schema", schemaName),
 				zap.Error(err))
 		}
+	}()
+}
+
+// refreshKnownSchema refreshes a schema, first checking that it exists on
+// the server when it is not cached
+func (si *SchemaIntrospector) refreshKnownSchema(ctx context.Context, catalogName, schemaName string, cached bool) error {
+	if !cached {
+		schemas, err := si.GetSchemas(ctx, catalogName)
+		if err != nil {
+			return err
+		}
+		if !slices.Contains(schemas, schemaName) {
+			return nil
+		}
 	}
+	return si.RefreshSchema(ctx, catalogName, schemaName)
+}
 
-	si.lastRefresh = time.Now()
-	si.logger.Info("Full schema refresh complete")
-	return nil
+// GetCatalogs retrieves all catalog names from Trino
+func (si *SchemaIntrospector) GetCatalogs(ctx context.Context) ([]string, error) {
+	return si.queryNames(ctx, "SELECT catalog_name FROM system.metadata.catalogs")
+}
+
+// GetSchemas retrieves all schema names in a catalog
+func (si *SchemaIntrospector) GetSchemas(ctx context.Context, catalogName string) ([]string, error) {
+	query := fmt.Sprintf("SELECT schema_name FROM %s.information_schema.schemata",
+		QuoteIdentifier(catalogName))
+	return si.queryNames(ctx, query)
 }
 
-// GetSchemas retrieves all schema names from Trino
-func (si *SchemaIntrospector) GetSchemas() ([]string, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+// GetTables retrieves all table names for a specific schema
+func (si *SchemaIntrospector) GetTables(ctx context.Context, catalogName, schemaName string) ([]string, error) {
+	query := fmt.Sprintf("SELECT table_name FROM %s.information_schema.tables WHERE table_schema = ?",
+		QuoteIdentifier(catalogName))
+	return si.queryNames(ctx, query, schemaName)
+}
+
+// GetTableListing retrieves the table names of every schema in a catalog,
+// by schema, with a single query
+func (si *SchemaIntrospector) GetTableListing(ctx context.Context, catalogName string) (map[string][]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
 	defer cancel()
 
-	query := "SELECT schema_name FROM information_schema.schemata"
+	query := fmt.Sprintf("SELECT table_schema, table_name FROM %s.information_schema.tables",
+		QuoteIdentifier(catalogName))
 	rows, err := si.db.QueryContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var schemas []string
+	listing := make(map[string][]string)
 	for rows.Next() {
-		var name string
-		if err := rows.Scan(&name); err != nil {
+		var schemaName, tableName string
+		if err := rows.Scan(&schemaName, &tableName); err != nil {
 			return nil, err
 		}
-		schemas = append(schemas, name)
+		listing[schemaName] = append(listing[schemaName], tableName)
 	}
 
 	if err := rows.Err(); err != nil {
 		return nil, err
 	}
 
-	return schemas, nil
+	return listing, nil
 }
 
-// GetTables retrieves all table names for a specific schema
-func (si *SchemaIntrospector) GetTables(schemaName string) ([]string, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+// queryNames runs a metadata query selecting a single text column
+func (si *SchemaIntrospector) queryNames(ctx context.Context, query string, args ...any) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
-	query := "SELECT table_name FROM information_schema.tables WHERE table_schema = ?"
-	rows, err := si.db.QueryContext(ctx, query, schemaName)
+	rows, err := si.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var tables []string
+	var names []string
 	for rows.Next() {
 		var name string
 		if err := rows.Scan(&name); err != nil {
 			return nil, err
 		}
-		tables = append(tables, name)
+		names = append(names, name)
 	}
 
 	if err := rows.Err(); err != nil {
 		return nil, err
 	}
 
-	return tables, nil
+	return names, nil
+}
+
+// queryRecords runs a statement such as SHOW FUNCTIONS whose columns differ
+// between Trino versions, returning each row keyed by lower-cased column
+// name. Missing columns read as "".
+func (si *SchemaIntrospector) queryRecords(ctx context.Context, query string) ([]map[string]string, error) {
+	rows, err := si.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	var records []map[string]string
+	for rows.Next() {
+		values := make([]sql.NullString, len(columns))
+		dest := make([]any, len(columns))
+		for i := range values {
+			dest[i] = &values[i]
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		record := make(map[string]string, len(columns))
+		for i, column := range columns {
+			record[strings.ToLower(column)] = values[i].String
+		}
+		records = append(records, record)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return records, nil
 }
 
 // GetColumns retrieves all column metadata for a specific table
-func (si *SchemaIntrospector) GetColumns(schemaName, tableName string) ([]ColumnMetadata, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (si *SchemaIntrospector) GetColumns(ctx context.Context, catalogName, schemaName, tableName string) ([]ColumnMetadata, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
-	query := `
-		SELECT column_name, data_type 
-		FROM information_schema.columns 
+	query := fmt.Sprintf(`
+		SELECT column_name, data_type, comment
+		FROM %s.information_schema.columns 
 		WHERE table_schema = ? AND table_name = ?
 		ORDER BY ordinal_position
-	`
+	`, QuoteIdentifier(catalogName))
 	rows, err := si.db.QueryContext(ctx, query, schemaName, tableName)
 	if err != nil {
 		return nil, err
@@ -260,11 +519,14 @@ func (si *SchemaIntrospector) GetColumns(schemaName, tableName string) ([]Column
 	var columns []ColumnMetadata
 	for rows.Next() {
 		var col ColumnMetadata
-		if err := rows.Scan(&col.Name, &col.DataType); err != nil {
+		var comment sql.NullString
+		if err := rows.Scan(&col.Name, &col.DataType, &comment); err != nil {
 			return nil, err
 		}
+		col.Comment = comment.String
 		col.Table = tableName
 		col.Schema = schemaName
+		col.Catalog = catalogName
 		columns = append(columns, col)
 	}
 
@@ -276,32 +538,46 @@ func (si *SchemaIntrospector) GetColumns(schemaName, tableName string) ([]Column
 }
 
 // RefreshSchema refreshes metadata for a specific schema
-func (si *SchemaIntrospector) RefreshSchema(schemaName string) error {
+func (si *SchemaIntrospector) RefreshSchema(ctx context.Context, catalogName, schemaName string) error {
 	si.mu.Lock()
 	defer si.mu.Unlock()
 
-	si.logger.Info("Refreshing schema", zap.String("schema", schemaName))
+	return si.refreshSchema(ctx, catalogName, schemaName)
+}
+
+// refreshSchema fetches a schema's tables and columns and stores them in
+// the cache. Callers must hold si.mu.
+func (si *SchemaIntrospector) refreshSchema(ctx context.Context, catalogName, schemaName string) error {
+	si.logger.Debug("Refreshing schema",
+		zap.String("catalog", catalogName),
+		zap.String("schema", schemaName))
 
 	// Build SchemaMetadata object
 	metadata := SchemaMetadata{
+		Catalog:    catalogName,
 		Name:       schemaName,
 		LastUpdate: time.Now(),
 	}
 
 	// Get tables for this schema
-	tables, err := si.GetTables(schemaName)
+	tables, err := si.GetTables(ctx, catalogName, schemaName)
 	if err != nil {
 		return err
 	}
 
 	// For each table, get columns
 	for _, tableName := range tables {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
 		tableMetadata := TableMetadata{
-			Name:   tableName,
-			Schema: schemaName,
+			Name:    tableName,
+			Catalog: catalogName,
+			Schema:  schemaName,
 		}
 
-		columns, err := si.GetColumns(schemaName, tableName)
+		columns, err := si.GetColumns(ctx, catalogName, schemaName, tableName)
 		if err != nil {
 			si.logger.Error("Failed to get columns",
 				zap.String("table", tableName),
@@ -0,0 +1,148 @@
+package autocomplete
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestRefreshAllRereadsOnlyChangedSchemas(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	cache, err := NewSchemaCache(t.TempDir(), zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cache.db.Close()
+	for _, metadata := range []SchemaMetadata{
+		{Catalog: "hive", Name: "sales", Tables: []TableMetadata{{Name: "orders"}}},
+		{Catalog: "hive", Name: "logs", Tables: []TableMetadata{{Name: "events"}}},
+		{Catalog: "hive", Name: "archive", Tables: []TableMetadata{{Name: "a"}}},
+		{Catalog: "hive", Name: "dropped", Tables: []TableMetadata{{Name: "x"}}},
+	} {
+		if err := cache.StoreSchema(metadata); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := cache.db.Exec("UPDATE schemas SET last_update = ? WHERE name = 'archive'", time.Now().Add(-48*time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	mock.ExpectQuery("system.metadata.catalogs").WillReturnRows(sqlmock.NewRows([]string{"catalog_name"}).AddRow("hive"))
+	mock.ExpectQuery("SHOW SESSION").WillReturnError(errors.New("unsupported"))
+	mock.ExpectQuery("information_schema.schemata").WillReturnRows(sqlmock.NewRows([]string{"schema_name"}).
+		AddRow("sales").AddRow("logs").AddRow("archive").AddRow("fresh").AddRow("information_schema"))
+	mock.ExpectQuery("SELECT table_schema, table_name FROM").WillReturnRows(sqlmock.NewRows([]string{"table_schema", "table_name"}).
+		AddRow("sales", "orders").AddRow("logs", "events").AddRow("logs", "errors").AddRow("archive", "a").AddRow("fresh", "t1"))
+	// sales is unchanged and still current, so only the others are read
+	expectSchemaRead := func(schemaName string, tables ...string) {
+		rows := sqlmock.NewRows([]string{"table_name"})
+		for _, table := range tables {
+			rows.AddRow(table)
+		}
+		mock.ExpectQuery("SELECT table_name FROM").WithArgs(schemaName).WillReturnRows(rows)
+		for _, table := range tables {
+			mock.ExpectQuery("information_schema.columns").WithArgs(schemaName, table).
+				WillReturnRows(sqlmock.NewRows([]string{"column_name", "data_type", "comment"}).AddRow("id", "bigint", nil))
+		}
+	}
+	expectSchemaRead("logs", "events", "errors")
+	expectSchemaRead("archive", "a")
+	expectSchemaRead("fresh", "t1")
+
+	if err := NewSchemaIntrospector(db, cache, zap.NewNop()).RefreshAll(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+
+	schemas, err := cache.GetSchemas("hive")
+	slices.Sort(schemas)
+	if err != nil || !slices.Equal(schemas, []string{"archive", "fresh", "logs", "sales"}) {
+		t.Errorf("cached schemas = %q, %v", schemas, err)
+	}
+	tables, err := cache.GetTables("hive", "logs")
+	slices.Sort(tables)
+	if err != nil || !slices.Equal(tables, []string{"errors", "events"}) {
+		t.Errorf("cached logs tables = %q, %v", tables, err)
+	}
+	if tables, err := cache.GetTables("hive", "dropped"); err != nil || len(tables) != 0 {
+		t.Errorf("dropped schema still has tables %q, %v", tables, err)
+	}
+}
+
+func TestRefreshOnDemand(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	cache, err := NewSchemaCache(t.TempDir(), zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cache.db.Close()
+	core, logs := observer.New(zap.WarnLevel)
+	si := NewSchemaIntrospector(db, cache, zap.New(core))
+
+	// A schema that does not exist is looked up and not cached
+	mock.MatchExpectationsInOrder(false)
+	mock.ExpectQuery("information_schema.schemata").WillReturnRows(sqlmock.NewRows([]string{"schema_name"}).AddRow("sales"))
+	mock.ExpectQuery("information_schema.schemata").WillReturnRows(sqlmock.NewRows([]string{"schema_name"}).AddRow("sales"))
+	mock.ExpectQuery("SELECT table_name FROM").WithArgs("sales").
+		WillReturnRows(sqlmock.NewRows([]string{"table_name"}).AddRow("orders"))
+	mock.ExpectQuery("information_schema.columns").WithArgs("sales", "orders").
+		WillReturnRows(sqlmock.NewRows([]string{"column_name", "data_type", "comment"}).AddRow("id", "bigint", nil))
+
+	si.RefreshOnDemand(context.Background(), "hive", "typo")
+	si.RefreshOnDemand(context.Background(), "hive", "sales")
+	waitFor(t, func() bool { return mock.ExpectationsWereMet() == nil })
+	waitFor(t, func() bool {
+		_, cached, _ := cache.GetSchemaUpdate("hive", "sales")
+		return cached
+	})
+
+	// Completing from them again soon after queries nothing, which would
+	// fail for lack of expected queries
+	si.RefreshOnDemand(context.Background(), "hive", "typo")
+	si.RefreshOnDemand(context.Background(), "hive", "sales")
+	time.Sleep(50 * time.Millisecond)
+	if logs.Len() != 0 {
+		t.Errorf("unexpected refresh: %v", logs.All())
+	}
+	if _, cached, _ := cache.GetSchemaUpdate("hive", "typo"); cached {
+		t.Error("a schema missing on the server was cached")
+	}
+
+	// Schemas completed from are re-read on the shorter TTL
+	if ttl := si.schemaTTLFor(schemaKey{"hive", "sales"}); ttl != si.activeSchemaTTL {
+		t.Errorf("TTL of a schema in use = %v, want %v", ttl, si.activeSchemaTTL)
+	}
+	if ttl := si.schemaTTLFor(schemaKey{"hive", "other"}); ttl != si.schemaTTL {
+		t.Errorf("TTL of an idle schema = %v, want %v", ttl, si.schemaTTL)
+	}
+}
+
+// waitFor polls cond until it holds, failing the test after a few seconds
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met in time")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
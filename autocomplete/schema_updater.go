@@ -1,19 +1,73 @@
 package autocomplete
 
 import (
+	"context"
+	"crypto/sha256"
 	"database/sql"
+	"encoding/hex"
 	"fmt"
-	"os"
+	"path/filepath"
+	"strings"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
-	"github.com/TFMV/trino-cli/config"
 	_ "github.com/trinodb/trino-go-client/trino"
 	"go.uber.org/zap"
 )
 
+// Profile is a Trino server whose metadata is cached for completion, and
+// which of its catalogs and schemas to introspect. The CLI makes one from a
+// profile of its config file.
+type Profile struct {
+	Name    string   // Names the cache directory
+	DSN     string   // Connection string for the Trino driver
+	Server  string   // Identifies the server, e.g. user@host:port
+	Catalog string   // Catalog sessions start in
+	Schema  string   // Schema sessions start in
+	Include []string // Patterns of the catalogs and schemas to introspect; see SchemaIntrospector.SetFilter
+	Exclude []string // Patterns of the catalogs and schemas to leave out
+}
+
+// ProfileCacheDir returns the directory of a profile's autocomplete cache.
+// It is keyed by the profile name and the server the profile connects to,
+// so profiles never mix their suggestions and a profile pointed at another
+// server starts afresh.
+func ProfileCacheDir(p Profile) (string, error) {
+	root, err := CacheRootDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(root, profileCacheKey(p)), nil
+}
+
+// profileCacheKey names a profile's cache directory: the profile name,
+// made safe for a file name, and a digest of its server
+func profileCacheKey(p Profile) string {
+	name := strings.Map(func(r rune) rune {
+		if r < utf8.RuneSelf && (unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_' || r == '.') {
+			return r
+		}
+		return '_'
+	}, p.Name)
+	sum := sha256.Sum256([]byte(p.Server))
+	return name + "-" + hex.EncodeToString(sum[:4])
+}
+
+// newProfileIntrospector returns an introspector reading the catalogs and
+// schemas the profile's patterns select, introspecting everything when a
+// pattern is invalid
+func newProfileIntrospector(db *sql.DB, cache *SchemaCache, p Profile, logger *zap.Logger) *SchemaIntrospector {
+	introspector := NewSchemaIntrospector(db, cache, logger)
+	if err := introspector.SetFilter(p.Include, p.Exclude); err != nil {
+		logger.Warn("Ignoring autocomplete include/exclude patterns", zap.Error(err))
+	}
+	return introspector
+}
+
 // StartSchemaCacheUpdater starts a background goroutine that refreshes schema metadata
-// at the specified interval for the given profile.
-func StartSchemaCacheUpdater(interval time.Duration, profileName string, logger *zap.Logger) error {
+// at the specified interval for the given profile until ctx is cancelled.
+func StartSchemaCacheUpdater(ctx context.Context, interval time.Duration, p Profile, logger *zap.Logger) error {
 	if logger == nil {
 		var err error
 		logger, err = zap.NewProduction()
@@ -22,28 +76,20 @@ func StartSchemaCacheUpdater(interval time.Duration, profileName string, logger
 		}
 	}
 
-	log := logger.With(zap.String("component", "schema_updater"), zap.String("profile", profileName))
+	log := logger.With(zap.String("component", "schema_updater"), zap.String("profile", p.Name))
 	log.Info("Starting schema cache updater", zap.Duration("interval", interval))
 
 	// Get database connection for the profile
-	dsn := fmt.Sprintf("http://%s@%s:%d?catalog=%s&schema=%s",
-		config.AppConfig.Profiles[profileName].User,
-		config.AppConfig.Profiles[profileName].Host,
-		config.AppConfig.Profiles[profileName].Port,
-		config.AppConfig.Profiles[profileName].Catalog,
-		config.AppConfig.Profiles[profileName].Schema)
-
-	db, err := sql.Open("trino", dsn)
+	db, err := sql.Open("trino", p.DSN)
 	if err != nil {
 		return fmt.Errorf("failed to connect to database: %w", err)
 	}
 
-	// Create cache directory
-	homeDir, err := os.UserHomeDir()
+	// Each profile has a cache of its own
+	cacheDir, err := ProfileCacheDir(p)
 	if err != nil {
-		return fmt.Errorf("failed to get home directory: %w", err)
+		return err
 	}
-	cacheDir := fmt.Sprintf("%s/.trino-cli/autocomplete_cache", homeDir)
 
 	// Create schema cache
 	cache, err := NewSchemaCache(cacheDir, log)
@@ -52,44 +98,50 @@ func StartSchemaCacheUpdater(interval time.Duration, profileName string, logger
 	}
 
 	// Create schema introspector
-	introspector := NewSchemaIntrospector(db, cache, log)
+	introspector := newProfileIntrospector(db, cache, p, log)
 
 	// Set refresh interval
 	introspector.SetRefreshInterval(interval)
 
 	// Start background refresh
-	introspector.StartBackgroundRefresh()
+	introspector.StartBackgroundRefresh(ctx)
+
+	// Release the connection and cache once the caller is done with the updater
+	go func() {
+		<-ctx.Done()
+		introspector.StopBackgroundRefresh()
+		db.Close()
+		cache.Close()
+	}()
 
 	log.Info("Schema cache updater started successfully")
 	return nil
 }
 
 // FetchAndCacheSchema fetches schema metadata for the given profile and caches it
-func FetchAndCacheSchema(profileName string) error {
+func FetchAndCacheSchema(ctx context.Context, p Profile) error {
+	return fetchAndCacheSchema(ctx, p, false)
+}
+
+// fetchAndCacheSchema refreshes a profile's cache, re-reading every schema
+// when full is set and otherwise only those that are new, changed or expired
+func fetchAndCacheSchema(ctx context.Context, p Profile, full bool) error {
 	logger, _ := zap.NewProduction()
 	defer logger.Sync()
-	log := logger.With(zap.String("component", "schema_updater"), zap.String("profile", profileName))
+	log := logger.With(zap.String("component", "schema_updater"), zap.String("profile", p.Name))
 
 	// Get database connection for the profile
-	dsn := fmt.Sprintf("http://%s@%s:%d?catalog=%s&schema=%s",
-		config.AppConfig.Profiles[profileName].User,
-		config.AppConfig.Profiles[profileName].Host,
-		config.AppConfig.Profiles[profileName].Port,
-		config.AppConfig.Profiles[profileName].Catalog,
-		config.AppConfig.Profiles[profileName].Schema)
-
-	db, err := sql.Open("trino", dsn)
+	db, err := sql.Open("trino", p.DSN)
 	if err != nil {
 		return fmt.Errorf("failed to connect to database: %w", err)
 	}
 	defer db.Close()
 
-	// Create cache directory
-	homeDir, err := os.UserHomeDir()
+	// Each profile has a cache of its own
+	cacheDir, err := ProfileCacheDir(p)
 	if err != nil {
-		return fmt.Errorf("failed to get home directory: %w", err)
+		return err
 	}
-	cacheDir := fmt.Sprintf("%s/.trino-cli/autocomplete_cache", homeDir)
 
 	// Create schema cache
 	cache, err := NewSchemaCache(cacheDir, log)
@@ -99,11 +151,15 @@ func FetchAndCacheSchema(profileName string) error {
 	defer cache.Close()
 
 	// Create schema introspector
-	introspector := NewSchemaIntrospector(db, cache, log)
+	introspector := newProfileIntrospector(db, cache, p, log)
+	if full {
+		// Every cached schema counts as expired
+		introspector.SetSchemaTTLs(0, 0)
+	}
 
 	// Refresh all schemas
 	log.Info("Refreshing schema cache...")
-	if err := introspector.RefreshAll(); err != nil {
+	if err := introspector.RefreshAll(ctx); err != nil {
 		log.Error("Failed to refresh schema cache", zap.Error(err))
 		return fmt.Errorf("failed to refresh schema cache: %w", err)
 	}
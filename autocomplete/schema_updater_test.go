@@ -0,0 +1,47 @@
+package autocomplete
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestProfileCacheDirsAreSeparate(t *testing.T) {
+	prod := Profile{Name: "prod", Server: "etl@trino.example.com:443"}
+	dev := Profile{Name: "dev", Server: "etl@localhost:8080"}
+	named := func(p Profile, name string) Profile {
+		p.Name = name
+		return p
+	}
+
+	keys := map[string]bool{}
+	for _, key := range []string{
+		profileCacheKey(prod),
+		profileCacheKey(dev),
+		profileCacheKey(named(prod, "dev")),   // Same name, another server
+		profileCacheKey(named(prod, "prod2")), // Same server, another profile
+	} {
+		if keys[key] {
+			t.Errorf("cache key %q is shared", key)
+		}
+		keys[key] = true
+	}
+	if a, b := profileCacheKey(prod), profileCacheKey(Profile{Name: "prod", Server: prod.Server, Include: []string{"hive"}}); a != b {
+		t.Errorf("introspection patterns changed the cache key: %q, %q", a, b)
+	}
+	for _, name := range []string{"../../etc", "a/b", "..", ""} {
+		key := profileCacheKey(named(dev, name))
+		if strings.ContainsAny(key, `/\`) || filepath.Base(key) != key {
+			t.Errorf("unsafe cache key %q for profile %q", key, name)
+		}
+	}
+
+	t.Setenv("HOME", t.TempDir())
+	dir, err := ProfileCacheDir(prod)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join("autocomplete_cache", profileCacheKey(prod)); !strings.HasSuffix(dir, want) {
+		t.Errorf("ProfileCacheDir = %q, want it to end in %q", dir, want)
+	}
+}
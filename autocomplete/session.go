@@ -0,0 +1,225 @@
+package autocomplete
+
+import (
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// Score boosts applied to objects that live in the active session's
+// catalog/schema so they rank above objects from elsewhere.
+const (
+	sessionCatalogBoost = 0.1
+	sessionSchemaBoost  = 0.2
+)
+
+// ParseUseStatement extracts the catalog and schema from a USE statement.
+// It accepts both "USE schema" and "USE catalog.schema" forms. When only a
+// schema is given, catalog is returned empty.
+func ParseUseStatement(query string) (catalog string, schema string, ok bool) {
+	stmt := strings.TrimSpace(query)
+	stmt = strings.TrimSpace(strings.TrimSuffix(stmt, ";"))
+
+	if len(stmt) < 4 || !strings.EqualFold(stmt[:3], "USE") || !isSpace(stmt[3]) {
+		return "", "", false
+	}
+
+	parts := splitQualifiedName(strings.TrimSpace(stmt[3:]))
+	for i, part := range parts {
+		parts[i] = unquoteIdentifier(part)
+		if parts[i] == "" {
+			return "", "", false
+		}
+	}
+
+	switch len(parts) {
+	case 1:
+		return "", parts[0], true
+	case 2:
+		return parts[0], parts[1], true
+	default:
+		return "", "", false
+	}
+}
+
+// splitQualifiedName splits a dotted name, ignoring dots inside quotes.
+// Unquoted whitespace makes the name invalid and yields no parts.
+func splitQualifiedName(name string) []string {
+	var parts []string
+	var current strings.Builder
+	inQuotes := false
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		switch {
+		case c == '"':
+			inQuotes = !inQuotes
+			current.WriteByte(c)
+		case c == '.' && !inQuotes:
+			parts = append(parts, current.String())
+			current.Reset()
+		case isSpace(c) && !inQuotes:
+			return nil
+		default:
+			current.WriteByte(c)
+		}
+	}
+	return append(parts, current.String())
+}
+
+// isSpace reports whether c is an ASCII whitespace character
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+// unquoteIdentifier strips surrounding double quotes from an identifier
+func unquoteIdentifier(ident string) string {
+	if len(ident) >= 2 && ident[0] == '"' && ident[len(ident)-1] == '"' {
+		return strings.ReplaceAll(ident[1:len(ident)-1], `""`, `"`)
+	}
+	return ident
+}
+
+// QuoteIdentifier double-quotes name unless it is a plain lower-case
+// identifier other than a reserved word, doubling any embedded quotes
+func QuoteIdentifier(name string) string {
+	plain := name != ""
+	for i, r := range name {
+		if !(r == '_' || (r >= 'a' && r <= 'z') || (i > 0 && r >= '0' && r <= '9')) {
+			plain = false
+			break
+		}
+	}
+	if plain && !IsReserved(name) {
+		return name
+	}
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// SetSessionContext updates the catalog and schema the user is currently
+// working in. Suggestions from this catalog/schema are ranked higher.
+// An empty catalog keeps the current catalog (as with "USE schema").
+func (ac *AutocompleteService) SetSessionContext(catalog, schema string) {
+	ac.mu.Lock()
+	defer ac.mu.Unlock()
+
+	if catalog != "" {
+		ac.sessionCatalog = catalog
+	}
+	ac.sessionSchema = schema
+
+	ac.sessionMu.Lock()
+	ac.session = nil
+	ac.sessionMu.Unlock()
+
+	ac.logger.Debug("Updated autocomplete session context",
+		zap.String("catalog", ac.sessionCatalog),
+		zap.String("schema", ac.sessionSchema))
+}
+
+// SessionContext returns the catalog and schema of the active session
+func (ac *AutocompleteService) SessionContext() (string, string) {
+	ac.mu.RLock()
+	defer ac.mu.RUnlock()
+	return ac.sessionCatalog, ac.sessionSchema
+}
+
+// sessionIndex tells which of the names a suggestion may carry without its
+// catalog are cached in the session catalog, and which in the session schema
+type sessionIndex struct {
+	version uint64          // Of the cache it was read from
+	schemas map[string]bool // Schemas of the session catalog
+	tables  map[string]bool // Tables of the session catalog, true for those in the session schema
+	columns map[string]bool // Columns of the session catalog, true for those in the session schema
+}
+
+// sessionNames returns the index of the session's names, reading it from the
+// cache only when the session or the cache changed since it was last read,
+// or nil without a cache. Callers must hold ac.mu.
+func (ac *AutocompleteService) sessionNames() *sessionIndex {
+	if ac.cache == nil || ac.sessionCatalog == "" {
+		return nil
+	}
+	ac.sessionMu.Lock()
+	defer ac.sessionMu.Unlock()
+
+	version := ac.cache.namesVersion()
+	if ac.session != nil && ac.session.version == version {
+		return ac.session
+	}
+	schemas, tables, columns, err := ac.cache.catalogNames(ac.sessionCatalog, ac.sessionSchema)
+	if err != nil {
+		ac.logger.Debug("Failed to read the session's names", zap.Error(err))
+		return nil
+	}
+	ac.session = &sessionIndex{version: version, schemas: schemas, tables: tables, columns: columns}
+	return ac.session
+}
+
+// applySessionPriority boosts suggestions that belong to the active catalog
+// and schema. Callers must hold ac.mu.
+func (ac *AutocompleteService) applySessionPriority(suggestions []Suggestion) {
+	if ac.sessionSchema == "" && ac.sessionCatalog == "" {
+		return
+	}
+	index := ac.sessionNames()
+
+	for i := range suggestions {
+		s := &suggestions[i]
+		if s.Type == Keyword || s.Type == Function {
+			continue
+		}
+		if s.Type == CatalogName {
+			if strings.EqualFold(s.Text, ac.sessionCatalog) {
+				s.Score += sessionCatalogBoost
+			}
+			continue
+		}
+
+		// Names the cache holds in several places carry no catalog or
+		// schema, so the session's index tells whether it has them
+		catalog, schema := s.Catalog, s.Schema
+		var inCatalog, inSchema bool
+		if index != nil {
+			name := strings.ToLower(s.Text)
+			switch {
+			case s.Type == SchemaName:
+				inCatalog = index.schemas[name]
+			case s.Type == TableName && strings.Contains(name, "."):
+				inCatalog = index.schemas[name[:strings.LastIndex(name, ".")]]
+			case s.Type == TableName:
+				inSchema, inCatalog = index.tables[name]
+			case s.Type == ColumnName:
+				inSchema, inCatalog = index.columns[name]
+			}
+		}
+		if ac.sessionCatalog != "" {
+			if catalog == "" && inCatalog {
+				catalog = ac.sessionCatalog
+			}
+			if !strings.EqualFold(catalog, ac.sessionCatalog) {
+				continue
+			}
+			s.Score += sessionCatalogBoost
+		}
+		if schema == "" && inSchema {
+			schema = ac.sessionSchema
+		}
+
+		if ac.sessionSchema == "" {
+			continue
+		}
+		if schema == "" {
+			switch s.Type {
+			case SchemaName:
+				schema = s.Text
+			case TableName:
+				if idx := strings.LastIndex(s.Text, "."); idx != -1 {
+					schema = s.Text[:idx]
+				}
+			}
+		}
+		if strings.EqualFold(schema, ac.sessionSchema) {
+			s.Score += sessionSchemaBoost
+		}
+	}
+}
@@ -0,0 +1,180 @@
+package autocomplete
+
+import (
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestParseUseStatement(t *testing.T) {
+	tests := []struct {
+		query   string
+		catalog string
+		schema  string
+		ok      bool
+	}{
+		{"USE analytics", "", "analytics", true},
+		{"use hive.analytics;", "hive", "analytics", true},
+		{`USE "my-catalog"."My Schema"`, "my-catalog", "My Schema", true},
+		{"  USE  hive.default  ", "hive", "default", true},
+		{"SELECT 1", "", "", false},
+		{"USE", "", "", false},
+		{"USE a.b.c", "", "", false},
+		{"USE hive.", "", "", false},
+	}
+
+	for _, tt := range tests {
+		catalog, schema, ok := ParseUseStatement(tt.query)
+		if ok != tt.ok || catalog != tt.catalog || schema != tt.schema {
+			t.Errorf("ParseUseStatement(%q) = (%q, %q, %v), want (%q, %q, %v)",
+				tt.query, catalog, schema, ok, tt.catalog, tt.schema, tt.ok)
+		}
+	}
+}
+
+func TestApplySessionPriority(t *testing.T) {
+	ac := &AutocompleteService{logger: zap.NewNop()}
+	ac.SetSessionContext("hive", "sales")
+
+	suggestions := []Suggestion{
+		{Text: "orders", Type: TableName, Catalog: "hive", Schema: "marketing", Score: 0.9},
+		{Text: "orders", Type: TableName, Catalog: "hive", Schema: "sales", Score: 0.9},
+		{Text: "sales.customers", Type: TableName, Catalog: "hive", Score: 0.9},
+		{Text: "orders", Type: TableName, Catalog: "iceberg", Schema: "sales", Score: 0.9},
+		{Text: "SELECT", Type: Keyword, Score: 0.9},
+	}
+	ac.applySessionPriority(suggestions)
+
+	if suggestions[1].Score <= suggestions[0].Score {
+		t.Errorf("expected session schema table to outrank other schema, got %v <= %v",
+			suggestions[1].Score, suggestions[0].Score)
+	}
+	if suggestions[2].Score != suggestions[1].Score {
+		t.Errorf("expected qualified table in session schema to be boosted, got %v", suggestions[2].Score)
+	}
+	if suggestions[3].Score != 0.9 {
+		t.Errorf("expected table from another catalog to stay unboosted, got %v", suggestions[3].Score)
+	}
+	if suggestions[4].Score != 0.9 {
+		t.Errorf("expected keywords to stay unboosted, got %v", suggestions[4].Score)
+	}
+
+	// Switching schema with USE re-ranks immediately
+	ac.SetSessionContext("", "marketing")
+	if catalog, schema := ac.SessionContext(); catalog != "hive" || schema != "marketing" {
+		t.Fatalf("unexpected session context %s.%s", catalog, schema)
+	}
+	suggestions[0].Score, suggestions[1].Score = 0.9, 0.9
+	ac.applySessionPriority(suggestions[:2])
+	if suggestions[0].Score <= suggestions[1].Score {
+		t.Errorf("expected marketing table to outrank sales table after USE")
+	}
+}
+
+func TestApplySessionPriorityFollowsUse(t *testing.T) {
+	cache, err := NewSchemaCache(t.TempDir(), zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cache.db.Close()
+	for _, schema := range []SchemaMetadata{
+		{Catalog: "hive", Name: "sales", Tables: []TableMetadata{
+			{Name: "orders", Columns: []ColumnMetadata{{Name: "amount", DataType: "double"}}},
+			{Name: "events", Columns: []ColumnMetadata{{Name: "ts", DataType: "timestamp"}}},
+		}},
+		{Catalog: "iceberg", Name: "lake", Tables: []TableMetadata{
+			{Name: "orders", Columns: []ColumnMetadata{{Name: "amount", DataType: "double"}}},
+			{Name: "snapshots", Columns: []ColumnMetadata{{Name: "id", DataType: "bigint"}}},
+		}},
+	} {
+		if err := cache.StoreSchema(schema); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ac := &AutocompleteService{logger: zap.NewNop(), cache: cache}
+	ac.SetSessionContext("hive", "sales")
+
+	type named struct {
+		text string
+		typ  SQLCompletionType
+	}
+	scores := func(ctx sqlContext, word string) map[named]float64 {
+		candidates := contextualSuggestions(ctx, word, cache)
+		ac.applySessionPriority(candidates)
+		got := make(map[named]float64)
+		for _, s := range candidates {
+			got[named{s.Text, s.Type}] = s.Score
+		}
+		return got
+	}
+	// Added up as applySessionPriority does, not folded as constants
+	inSession := float64(sessionCatalogBoost)
+	inSession += sessionSchemaBoost
+	tables := sqlContext{completionType: TableName, clause: clauseFrom}
+	columns := sqlContext{completionType: ColumnName}
+
+	want := map[named]float64{
+		{"events", TableName}:         inSession,
+		{"orders", TableName}:         inSession,
+		{"snapshots", TableName}:      0,
+		{"hive", CatalogName}:         sessionCatalogBoost,
+		{"iceberg", CatalogName}:      0,
+		{"lake.snapshots", TableName}: 0,
+	}
+	got := scores(tables, "")
+	for key, score := range want {
+		if got[key] != score {
+			t.Errorf("in hive.sales, %v scored %v, want %v", key, got[key], score)
+		}
+	}
+	if got := scores(columns, ""); got[named{"ts", ColumnName}] != inSession || got[named{"id", ColumnName}] != 0 {
+		t.Errorf("in hive.sales, column scores = %v", got)
+	}
+
+	// After USE iceberg.lake, the other catalog's names are the boosted ones
+	ac.SetSessionContext("iceberg", "lake")
+	want = map[named]float64{
+		{"events", TableName}:         0,
+		{"orders", TableName}:         inSession,
+		{"snapshots", TableName}:      inSession,
+		{"hive", CatalogName}:         0,
+		{"iceberg", CatalogName}:      sessionCatalogBoost,
+		{"lake.snapshots", TableName}: inSession,
+	}
+	got = scores(tables, "")
+	for key, score := range want {
+		if got[key] != score {
+			t.Errorf("in iceberg.lake, %v scored %v, want %v", key, got[key], score)
+		}
+	}
+	if got := scores(columns, ""); got[named{"ts", ColumnName}] != 0 || got[named{"id", ColumnName}] != inSession {
+		t.Errorf("in iceberg.lake, column scores = %v", got)
+	}
+
+	// Names stored after the session's were read are picked up
+	err = cache.StoreSchema(SchemaMetadata{Catalog: "iceberg", Name: "lake", Tables: []TableMetadata{{Name: "manifests"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := scores(tables, "m"); got[named{"manifests", TableName}] != inSession {
+		t.Errorf("table cached after USE scored %v", got[named{"manifests", TableName}])
+	}
+}
+
+func TestQuoteIdentifier(t *testing.T) {
+	tests := map[string]string{
+		"orders":     "orders",
+		"Orders":     `"Orders"`,
+		"order":      `"order"`,
+		"SELECT":     `"SELECT"`,
+		"my-catalog": `"my-catalog"`,
+		"a.b":        `"a.b"`,
+		`say"hi`:     `"say""hi"`,
+	}
+	for name, want := range tests {
+		if got := QuoteIdentifier(name); got != want {
+			t.Errorf("QuoteIdentifier(%q) = %s, want %s", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,255 @@
+package autocomplete
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/TFMV/trino-cli/sqllex"
+	"go.uber.org/zap"
+)
+
+// SessionPropertyMetadata describes a system or catalog session property
+// from SHOW SESSION. Catalog properties are named catalog.property.
+type SessionPropertyMetadata struct {
+	Name        string `json:"name"`
+	Value       string `json:"value"`
+	Default     string `json:"default"`
+	Type        string `json:"type"`
+	Description string `json:"description"`
+}
+
+// literal renders value as it is written in SET SESSION
+func (p SessionPropertyMetadata) literal(value string) string {
+	if strings.EqualFold(p.Type, "varchar") {
+		return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+	}
+	return value
+}
+
+// statementContext sets the completion for the name at the cursor in SET
+// SESSION, SHOW and DESCRIBE statements, where names are not those of a
+// query. before holds the tokens up to the name and qualifier its dotted
+// prefix. It reports whether the cursor is at such a name.
+func statementContext(ctx *sqlContext, before []sqllex.Token, qualifier []string) bool {
+	for i := len(before) - 1; i >= 0; i-- {
+		if before[i].Is(";") {
+			before = before[i+1:]
+			break
+		}
+	}
+	var lead []sqllex.Token
+	for _, tok := range before {
+		if tok.Kind != sqllex.Comment {
+			lead = append(lead, tok)
+		}
+	}
+	words := make([]string, len(lead))
+	for i, tok := range lead {
+		words[i] = strings.ToUpper(tok.Text)
+	}
+	starts := func(prefix ...string) bool {
+		if len(words) < len(prefix) {
+			return false
+		}
+		for i, word := range prefix {
+			if words[i] != word {
+				return false
+			}
+		}
+		return true
+	}
+	after := func(prefix ...string) bool {
+		return len(words) == len(prefix) && starts(prefix...)
+	}
+
+	switch {
+	case after("SET", "SESSION") && len(qualifier) <= 1:
+		ctx.completionType = SessionProperty
+		if len(qualifier) == 1 {
+			ctx.catalog = qualifier[0]
+		}
+	case starts("SET", "SESSION") && len(words) > 3 && words[len(words)-1] == "=" && len(qualifier) == 0:
+		var name []string
+		for _, tok := range lead[2 : len(lead)-1] {
+			if tok.IsName() {
+				name = append(name, identifierName(tok))
+			}
+		}
+		ctx.completionType = SessionValue
+		ctx.property = strings.Join(name, ".")
+	case (after("SHOW", "SCHEMAS", "FROM") || after("SHOW", "SCHEMAS", "IN")) && len(qualifier) == 0:
+		ctx.completionType = CatalogName
+	case (after("SHOW", "TABLES", "FROM") || after("SHOW", "TABLES", "IN")) && len(qualifier) <= 1:
+		ctx.completionType = SchemaName
+		if len(qualifier) == 1 {
+			ctx.catalog = qualifier[0]
+		}
+	case after("DESCRIBE"), after("SHOW", "COLUMNS", "FROM"), after("SHOW", "COLUMNS", "IN"),
+		after("SHOW", "CREATE", "TABLE"), after("SHOW", "CREATE", "VIEW"), after("SHOW", "STATS", "FOR"):
+		ctx.completionType = TableName
+		ctx.clause = clauseFrom
+		ctx.catalog, ctx.schema, _ = splitQualifier(append(qualifier, ""))
+	default:
+		return false
+	}
+	return true
+}
+
+// sessionPropertySuggestions suggests the session properties, or after
+// "catalog." the rest of that catalog's property names
+func sessionPropertySuggestions(cache *SchemaCache, catalog string) []Suggestion {
+	var suggestions []Suggestion
+	for _, prop := range cache.GetSessionProperties() {
+		text := prop.Name
+		if catalog != "" {
+			var ok bool
+			if text, ok = strings.CutPrefix(prop.Name, catalog+"."); !ok {
+				continue
+			}
+		}
+		detail := prop.Type
+		if prop.Description != "" {
+			detail += " — " + prop.Description
+		}
+		suggestions = append(suggestions, Suggestion{Text: text, Type: SessionProperty, DetailText: detail})
+	}
+	return suggestions
+}
+
+// sessionValueSuggestions suggests values for a session property: true and
+// false for booleans, and otherwise its default and current values
+func sessionValueSuggestions(cache *SchemaCache, name string) []Suggestion {
+	for _, prop := range cache.GetSessionProperties() {
+		if !strings.EqualFold(prop.Name, name) {
+			continue
+		}
+		if strings.EqualFold(prop.Type, "boolean") {
+			return []Suggestion{
+				{Text: "true", Type: SessionValue, DetailText: "boolean"},
+				{Text: "false", Type: SessionValue, DetailText: "boolean"},
+			}
+		}
+		suggestions := []Suggestion{{Text: prop.literal(prop.Default), Type: SessionValue, DetailText: "Default"}}
+		if prop.Value != prop.Default {
+			suggestions = append(suggestions, Suggestion{Text: prop.literal(prop.Value), Type: SessionValue, DetailText: "Current"})
+		}
+		return suggestions
+	}
+	return nil
+}
+
+// GetSessionProperties returns the cached session properties
+func (sc *SchemaCache) GetSessionProperties() []SessionPropertyMetadata {
+	sc.lock.RLock()
+	defer sc.lock.RUnlock()
+	return sc.sessionProperties
+}
+
+// loadSessionProperties makes the session properties stored by the last
+// refresh current
+func (sc *SchemaCache) loadSessionProperties() error {
+	sc.lock.Lock()
+	defer sc.lock.Unlock()
+
+	rows, err := sc.db.Query("SELECT name, value, default_value, type, description FROM session_properties ORDER BY name")
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var properties []SessionPropertyMetadata
+	for rows.Next() {
+		var prop SessionPropertyMetadata
+		if err := rows.Scan(&prop.Name, &prop.Value, &prop.Default, &prop.Type, &prop.Description); err != nil {
+			return err
+		}
+		properties = append(properties, prop)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	sc.sessionProperties = properties
+	return nil
+}
+
+// StoreSessionProperties replaces the cached session properties
+func (sc *SchemaCache) StoreSessionProperties(properties []SessionPropertyMetadata) error {
+	sc.lock.Lock()
+	defer sc.lock.Unlock()
+
+	tx, err := sc.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec("DELETE FROM session_properties"); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	stmt, err := tx.Prepare(
+		`INSERT OR REPLACE INTO session_properties
+		(name, value, default_value, type, description)
+		VALUES (?, ?, ?, ?, ?)`,
+	)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	defer stmt.Close()
+
+	for _, prop := range properties {
+		if _, err := stmt.Exec(prop.Name, prop.Value, prop.Default, prop.Type, prop.Description); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	sc.sessionProperties = properties
+	sc.logger.Debug("Stored session properties in cache", zap.Int("properties", len(properties)))
+	return nil
+}
+
+// GetSessionProperties retrieves the system and catalog session properties
+func (si *SchemaIntrospector) GetSessionProperties(ctx context.Context) ([]SessionPropertyMetadata, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	records, err := si.queryRecords(ctx, "SHOW SESSION")
+	if err != nil {
+		return nil, err
+	}
+	if len(records) > 0 {
+		if _, ok := records[0]["name"]; !ok {
+			return nil, fmt.Errorf("SHOW SESSION returned no name column")
+		}
+	}
+
+	properties := make([]SessionPropertyMetadata, 0, len(records))
+	for _, record := range records {
+		properties = append(properties, SessionPropertyMetadata{
+			Name:        record["name"],
+			Value:       record["value"],
+			Default:     record["default"],
+			Type:        record["type"],
+			Description: record["description"],
+		})
+	}
+	return properties, nil
+}
+
+// RefreshSessionProperties fetches the session properties into the cache
+func (si *SchemaIntrospector) RefreshSessionProperties(ctx context.Context) error {
+	properties, err := si.GetSessionProperties(ctx)
+	if err != nil {
+		return err
+	}
+	return si.cache.StoreSessionProperties(properties)
+}
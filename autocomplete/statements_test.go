@@ -0,0 +1,97 @@
+package autocomplete
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"go.uber.org/zap"
+)
+
+func TestStatementSuggestions(t *testing.T) {
+	cache, err := NewSchemaCache(t.TempDir(), zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cache.db.Close()
+	for _, metadata := range []SchemaMetadata{
+		{Catalog: "hive", Name: "sales", Tables: []TableMetadata{{Name: "orders"}}},
+		{Catalog: "iceberg", Name: "logs", Tables: []TableMetadata{{Name: "events"}}},
+	} {
+		if err := cache.StoreSchema(metadata); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := cache.StoreSessionProperties([]SessionPropertyMetadata{
+		{Name: "join_distribution_type", Value: "AUTOMATIC", Default: "AUTOMATIC", Type: "varchar"},
+		{Name: "query_max_run_time", Value: "1h", Default: "100.00d", Type: "varchar"},
+		{Name: "spill_enabled", Value: "false", Default: "false", Type: "boolean"},
+		{Name: "hive.insert_existing_partitions_behavior", Value: "APPEND", Default: "APPEND", Type: "varchar"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"SET SESSION |", []string{"hive.insert_existing_partitions_behavior", "join_distribution_type", "query_max_run_time", "spill_enabled"}},
+		{"set session q|", []string{"query_max_run_time"}},
+		{"SET SESSION hive.|", []string{"insert_existing_partitions_behavior"}},
+		{"SET SESSION spill_enabled = |", []string{"false", "true"}},
+		{"SET SESSION query_max_run_time = |", []string{"'100.00d'", "'1h'"}},
+		{"SELECT 1; SHOW SCHEMAS FROM |", []string{"hive", "iceberg"}},
+		{"SHOW SCHEMAS IN ice|", []string{"iceberg"}},
+		{"SHOW TABLES FROM |", []string{"hive", "iceberg", "logs", "sales"}},
+		{"SHOW TABLES FROM iceberg.|", []string{"logs"}},
+		{"DESCRIBE |", []string{"events", "hive", "iceberg", "logs", "logs.events", "orders", "sales", "sales.orders"}},
+		{"DESCRIBE sales.|", []string{"orders"}},
+		{"SHOW COLUMNS FROM iceberg.logs.|", []string{"events"}},
+	}
+	for _, tt := range tests {
+		pos := strings.Index(tt.query, cursor)
+		got := GetContextualSuggestions(strings.Replace(tt.query, cursor, "", 1), pos, cache)
+		slices.Sort(got)
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("suggestions for %q = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestSessionPropertiesCached(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	mock.ExpectQuery("SHOW SESSION").WillReturnRows(
+		sqlmock.NewRows([]string{"Name", "Value", "Default", "Type", "Description"}).
+			AddRow("spill_enabled", "true", "false", "boolean", "Enable spilling"),
+	)
+
+	dir := t.TempDir()
+	cache, err := NewSchemaCache(dir, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := NewSchemaIntrospector(db, cache, zap.NewNop()).RefreshSessionProperties(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	cache.db.Close()
+
+	// A new session starts from the properties stored by the last one
+	reopened, err := NewSchemaCache(dir, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reopened.db.Close()
+	if err := reopened.LoadCache(); err != nil {
+		t.Fatal(err)
+	}
+	want := SessionPropertyMetadata{Name: "spill_enabled", Value: "true", Default: "false", Type: "boolean", Description: "Enable spilling"}
+	if got := reopened.GetSessionProperties(); len(got) != 1 || got[0] != want {
+		t.Errorf("session properties = %+v, want %+v", got, want)
+	}
+}
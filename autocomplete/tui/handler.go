@@ -0,0 +1,428 @@
+// Package tui shows the suggestions of the autocomplete engine in a tview
+// application: as a popup at the cursor of an input field, computed as the
+// user types and inserted with Enter or Tab.
+package tui
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"maps"
+	"sync"
+	"time"
+
+	"github.com/TFMV/trino-cli/autocomplete"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"go.uber.org/zap"
+)
+
+// debounceDelay is how long typing must pause before suggestions are
+// computed for the text, so a burst of keystrokes computes them once
+const debounceDelay = 100 * time.Millisecond
+
+// Handler manages SQL autocompletion integration with TUI
+type Handler struct {
+	service           *autocomplete.AutocompleteService
+	db                *sql.DB // Connection the service introspects, closed by Stop
+	suggestionBox     *tview.List
+	inputField        *tview.InputField
+	app               *tview.Application
+	logger            *zap.Logger
+	suggestionVisible bool
+	currentCatalog    string
+	currentSchema     string
+	suggestions       []autocomplete.Suggestion
+	suggestionsFor    string // The text suggestions were computed for
+	suggestionsMutex  sync.RWMutex
+	anchor            func() (x, y int, ok bool) // Where the editor shows its cursor
+	iconColors        map[autocomplete.SQLCompletionType]tcell.Color
+
+	// The pending or running computation, superseded by each Update
+	updateMu     sync.Mutex
+	updateTimer  *time.Timer
+	cancelUpdate context.CancelFunc
+	updateGen    uint64
+}
+
+// NewHandler creates a new autocomplete handler for the TUI. The handler
+// takes over db and closes it when stopped.
+func NewHandler(ctx context.Context, db *sql.DB, profile autocomplete.Profile, app *tview.Application,
+	inputField *tview.InputField, logger *zap.Logger) (*Handler, error) {
+
+	if logger == nil {
+		var err error
+		logger, err = zap.NewProduction()
+		if err != nil {
+			return nil, fmt.Errorf("failed to create logger: %w", err)
+		}
+	}
+
+	// Each profile has a cache of its own
+	cacheDir, err := autocomplete.ProfileCacheDir(profile)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create autocomplete service
+	service, err := autocomplete.NewAutocompleteService(db, cacheDir, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create autocomplete service: %w", err)
+	}
+	if err := service.SetFilter(profile.Include, profile.Exclude); err != nil {
+		logger.Warn("Ignoring autocomplete include/exclude patterns", zap.Error(err))
+	}
+
+	// Create suggestion box, drawn as a popup at the cursor
+	suggestionBox := tview.NewList().
+		ShowSecondaryText(false).
+		SetHighlightFullLine(true).
+		SetMainTextColor(tcell.ColorWhite).
+		SetSelectedTextColor(tcell.ColorBlack).
+		SetSelectedBackgroundColor(tcell.ColorAqua)
+	suggestionBox.SetBorder(true)
+
+	// Start from the profile's catalog/schema; USE statements update these later
+	currentCatalog := "default"
+	currentSchema := "public"
+	if profile.Catalog != "" {
+		currentCatalog = profile.Catalog
+	}
+	if profile.Schema != "" {
+		currentSchema = profile.Schema
+	}
+	service.SetSessionContext(currentCatalog, currentSchema)
+
+	handler := &Handler{
+		service:           service,
+		db:                db,
+		suggestionBox:     suggestionBox,
+		inputField:        inputField,
+		app:               app,
+		logger:            logger,
+		suggestionVisible: false,
+		currentCatalog:    currentCatalog,
+		currentSchema:     currentSchema,
+		iconColors:        maps.Clone(defaultIconColors),
+	}
+
+	// Start autocomplete service
+	if err := service.Start(ctx); err != nil {
+		logger.Warn("Autocomplete service initialization had issues", zap.Error(err))
+		// Continue anyway - still usable for keywords
+	}
+
+	return handler, nil
+}
+
+// ProcessKey handles keyboard input for autocompletion
+func (ah *Handler) ProcessKey(event *tcell.EventKey) bool {
+	// If suggestions are visible, handle selection navigation
+	if ah.suggestionVisible {
+		switch event.Key() {
+		case tcell.KeyDown:
+			// Select next suggestion
+			count := ah.suggestionBox.GetItemCount()
+			if count > 0 {
+				current := ah.suggestionBox.GetCurrentItem()
+				if current < count-1 {
+					ah.suggestionBox.SetCurrentItem(current + 1)
+				}
+			}
+			return true
+		case tcell.KeyUp:
+			// Select previous suggestion
+			if ah.suggestionBox.GetItemCount() > 0 {
+				current := ah.suggestionBox.GetCurrentItem()
+				if current > 0 {
+					ah.suggestionBox.SetCurrentItem(current - 1)
+				}
+			}
+			return true
+		case tcell.KeyPgDn:
+			// Scroll a page down
+			if count := ah.suggestionBox.GetItemCount(); count > 0 {
+				ah.suggestionBox.SetCurrentItem(min(ah.suggestionBox.GetCurrentItem()+popupMaxRows, count-1))
+			}
+			return true
+		case tcell.KeyPgUp:
+			// Scroll a page up
+			if ah.suggestionBox.GetItemCount() > 0 {
+				ah.suggestionBox.SetCurrentItem(max(ah.suggestionBox.GetCurrentItem()-popupMaxRows, 0))
+			}
+			return true
+		case tcell.KeyEnter, tcell.KeyTab:
+			// Accept current suggestion
+			if ah.suggestionBox.GetItemCount() > 0 {
+				ah.acceptSuggestion(ah.suggestionBox.GetCurrentItem())
+			}
+			return true
+		case tcell.KeyEscape:
+			// Hide suggestions
+			ah.HideSuggestions()
+			return true
+		}
+	}
+
+	// Handle Tab for opening suggestions
+	if event.Key() == tcell.KeyTab && !ah.suggestionVisible {
+		ah.ShowSuggestions()
+		return true
+	}
+
+	// Handle Ctrl+Space for opening suggestions
+	if event.Key() == tcell.KeyCtrlSpace && !ah.suggestionVisible {
+		ah.ShowSuggestions()
+		return true
+	}
+
+	return false // Event not handled
+}
+
+// SuggestionsVisible reports whether the suggestion box is showing
+func (ah *Handler) SuggestionsVisible() bool {
+	return ah.suggestionVisible
+}
+
+// SetColors changes the suggestion box colors
+func (ah *Handler) SetColors(text, selectedText, selectedBackground tcell.Color) {
+	ah.suggestionBox.SetMainTextColor(text).
+		SetSelectedTextColor(selectedText).
+		SetSelectedBackgroundColor(selectedBackground)
+}
+
+// Update should be called when the input text changes. Suggestions are
+// computed once typing pauses for debounceDelay, and a computation still
+// running for earlier text is cancelled.
+func (ah *Handler) Update(text string, cursorPos int) {
+	ah.updateMu.Lock()
+	defer ah.updateMu.Unlock()
+
+	ah.stopUpdate()
+	ctx, cancel := context.WithCancel(context.Background())
+	ah.cancelUpdate = cancel
+	ah.updateGen++
+	gen := ah.updateGen
+	ah.updateTimer = time.AfterFunc(debounceDelay, func() {
+		ah.computeSuggestions(ctx, gen, text, cursorPos)
+	})
+}
+
+// stopUpdate cancels the pending or running computation. Callers must hold
+// ah.updateMu.
+func (ah *Handler) stopUpdate() {
+	if ah.updateTimer != nil {
+		ah.updateTimer.Stop()
+	}
+	if ah.cancelUpdate != nil {
+		ah.cancelUpdate()
+	}
+}
+
+// computeSuggestions computes the suggestions for text, dropping them when
+// a later Update superseded the computation
+func (ah *Handler) computeSuggestions(ctx context.Context, gen uint64, text string, cursorPos int) {
+	suggestions, err := ah.service.GetCompletions(ctx, text, cursorPos)
+	if ctx.Err() != nil {
+		return
+	}
+	if err != nil {
+		ah.logger.Error("Failed to get completions", zap.Error(err))
+		return
+	}
+
+	ah.updateMu.Lock()
+	defer ah.updateMu.Unlock()
+	if gen != ah.updateGen {
+		return
+	}
+	ah.setSuggestions(text, suggestions)
+
+	// If suggestions box is visible, update it as the user types on
+	if ah.suggestionVisible {
+		ah.app.QueueUpdateDraw(func() {
+			ah.updateSuggestionBox()
+			if ah.suggestionBox.GetItemCount() == 0 {
+				ah.HideSuggestions()
+			}
+		})
+	}
+}
+
+// setSuggestions replaces the suggestions with those computed for text
+func (ah *Handler) setSuggestions(text string, suggestions []autocomplete.Suggestion) {
+	ah.suggestionsMutex.Lock()
+	defer ah.suggestionsMutex.Unlock()
+	ah.suggestions = suggestions
+	ah.suggestionsFor = text
+}
+
+// SetSessionContext switches the catalog/schema used to prioritize
+// suggestions. An empty catalog keeps the current one.
+func (ah *Handler) SetSessionContext(catalog, schema string) {
+	ah.suggestionsMutex.Lock()
+	if catalog != "" {
+		ah.currentCatalog = catalog
+	}
+	ah.currentSchema = schema
+	ah.suggestionsMutex.Unlock()
+
+	ah.service.SetSessionContext(catalog, schema)
+}
+
+// LearnFromHistory ranks the tables and columns that earlier queries use
+// above others
+func (ah *Handler) LearnFromHistory(queries []string) {
+	ah.service.LearnQueries(queries)
+}
+
+// ObserveQuery inspects a submitted query, counting the tables and columns
+// it uses towards ranking, and updates the session context immediately when
+// it is a USE statement. It reports whether it was one.
+func (ah *Handler) ObserveQuery(query string) bool {
+	ah.service.LearnQueries([]string{query})
+	catalog, schema, ok := autocomplete.ParseUseStatement(query)
+	if !ok {
+		return false
+	}
+	ah.SetSessionContext(catalog, schema)
+	ah.logger.Info("Session context changed",
+		zap.String("catalog", ah.currentCatalog),
+		zap.String("schema", ah.currentSchema))
+	return true
+}
+
+// Attach makes input the field that suggestions are computed for and
+// inserted into, e.g. when the TUI switches between editor tabs. Any open
+// suggestion list is closed.
+func (ah *Handler) Attach(input *tview.InputField) {
+	ah.suggestionVisible = false
+	ah.inputField = input
+
+	input.SetChangedFunc(func(text string) {
+		cursorPos := len(text) // Default to end of text
+		ah.Update(text, cursorPos)
+	})
+}
+
+// Stop should be called when closing the application
+func (ah *Handler) Stop() {
+	ah.updateMu.Lock()
+	ah.stopUpdate()
+	ah.updateMu.Unlock()
+	ah.service.Stop()
+	if ah.db != nil {
+		ah.db.Close()
+	}
+}
+
+// ShowSuggestions displays the suggestion box
+func (ah *Handler) ShowSuggestions() {
+	text := ah.inputField.GetText()
+	cursorPos := len(text) // Default to end of text if no cursor position available
+
+	// Typing has not paused since the text changed, so compute now
+	ah.suggestionsMutex.RLock()
+	stale := ah.suggestionsFor != text
+	ah.suggestionsMutex.RUnlock()
+	if stale {
+		suggestions, err := ah.service.GetCompletions(context.Background(), text, cursorPos)
+		if err != nil {
+			ah.logger.Error("Failed to get completions", zap.Error(err))
+		}
+		ah.setSuggestions(text, suggestions)
+	}
+
+	// Update suggestions box content
+	ah.updateSuggestionBox()
+
+	// Make the suggestions visible if we have suggestions. The editor keeps
+	// the focus, so typing on narrows the list.
+	if ah.suggestionBox.GetItemCount() > 0 {
+		ah.suggestionVisible = true
+	}
+}
+
+// HideSuggestions hides the suggestion box
+func (ah *Handler) HideSuggestions() {
+	ah.suggestionVisible = false
+}
+
+// UpdateSuggestionBox updates the content of the suggestion box
+func (ah *Handler) updateSuggestionBox() {
+	ah.suggestionBox.Clear()
+
+	ah.suggestionsMutex.RLock()
+	defer ah.suggestionsMutex.RUnlock()
+
+	for _, suggestion := range ah.suggestions {
+		ah.suggestionBox.AddItem(ah.popupItem(suggestion), "", 0, nil)
+	}
+}
+
+// acceptSuggestion applies the selected suggestion to the input field
+func (ah *Handler) acceptSuggestion(index int) {
+	if index < 0 || index >= len(ah.suggestions) {
+		return
+	}
+
+	suggestion := ah.suggestions[index]
+
+	// Boost the score of the selected suggestion
+	go ah.service.BoostSuggestion(suggestion)
+
+	// Replace the word at the cursor, which may have grown since the
+	// suggestions were shown
+	text := ah.inputField.GetText()
+	newText, _ := autocomplete.ApplySuggestion(text, len(text), suggestion)
+	ah.inputField.SetText(newText)
+
+	// Hide the suggestions
+	ah.HideSuggestions()
+}
+
+// Integrate integrates the autocomplete handler with the TUI
+func Integrate(ctx context.Context, app *tview.Application, input *tview.InputField, profile autocomplete.Profile, logger *zap.Logger) (*Handler, error) {
+	// Get database connection
+	db, err := sql.Open("trino", profile.DSN)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	// Create autocomplete handler
+	handler, err := NewHandler(ctx, db, profile, app, input, logger)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create autocomplete handler: %w", err)
+	}
+
+	// Draw the suggestion popup over everything else
+	afterDraw := app.GetAfterDrawFunc()
+	app.SetAfterDrawFunc(func(screen tcell.Screen) {
+		if afterDraw != nil {
+			afterDraw(screen)
+		}
+		handler.drawPopup(screen)
+	})
+
+	// Set up input field to trigger autocomplete updates
+	handler.Attach(input)
+
+	// Intercept key events for autocomplete navigation
+	originalInputCapture := app.GetInputCapture()
+	app.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		// Give autocomplete handler first chance to process the key
+		if handler.ProcessKey(event) {
+			return nil
+		}
+
+		// Otherwise, pass to original handler if it exists
+		if originalInputCapture != nil {
+			return originalInputCapture(event)
+		}
+
+		return event
+	})
+
+	return handler, nil
+}
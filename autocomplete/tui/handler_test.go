@@ -0,0 +1,49 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/TFMV/trino-cli/autocomplete"
+	"go.uber.org/zap"
+)
+
+func TestUpdateComputesOnlyTheLastText(t *testing.T) {
+	ac, err := autocomplete.NewAutocompleteService(nil, t.TempDir(), zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ah := &Handler{service: ac, logger: zap.NewNop()}
+	defer ah.Stop()
+
+	for _, text := range []string{"S", "SE", "SEL"} {
+		ah.Update(text, len(text))
+	}
+	ah.suggestionsMutex.RLock()
+	computed := ah.suggestionsFor
+	ah.suggestionsMutex.RUnlock()
+	if computed != "" {
+		t.Fatalf("suggestions computed for %q before typing paused", computed)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		ah.suggestionsMutex.RLock()
+		computed, suggestions := ah.suggestionsFor, ah.suggestions
+		ah.suggestionsMutex.RUnlock()
+		if computed == "SEL" {
+			if len(suggestions) == 0 || !strings.EqualFold(suggestions[0].Text, "SELECT") {
+				t.Errorf("suggestions for SEL = %+v", suggestions)
+			}
+			break
+		}
+		if computed != "" {
+			t.Fatalf("suggestions computed for superseded text %q", computed)
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("suggestions were never computed")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,143 @@
+package tui
+
+import (
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/TFMV/trino-cli/autocomplete"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// popupMaxRows is how many suggestions the popup shows at once; the rest
+// scroll into view
+const popupMaxRows = 10
+
+// popupMaxDetail cuts off the detail shown beside a suggestion
+const popupMaxDetail = 40
+
+// suggestionIcons marks each popup entry with its kind
+var suggestionIcons = map[autocomplete.SQLCompletionType]string{
+	autocomplete.Keyword:         "K",
+	autocomplete.Function:        "ƒ",
+	autocomplete.CatalogName:     "C",
+	autocomplete.SchemaName:      "S",
+	autocomplete.TableName:       "T",
+	autocomplete.ColumnName:      "#",
+	autocomplete.SessionProperty: "P",
+	autocomplete.SessionValue:    "=",
+}
+
+// defaultIconColors are used until SetIconColors is called
+var defaultIconColors = map[autocomplete.SQLCompletionType]tcell.Color{
+	autocomplete.Keyword:         tcell.ColorDeepSkyBlue,
+	autocomplete.Function:        tcell.ColorFuchsia,
+	autocomplete.CatalogName:     tcell.ColorYellow,
+	autocomplete.SchemaName:      tcell.ColorLightBlue,
+	autocomplete.TableName:       tcell.ColorLightCyan,
+	autocomplete.ColumnName:      tcell.ColorWhite,
+	autocomplete.SessionProperty: tcell.ColorYellow,
+	autocomplete.SessionValue:    tcell.ColorYellow,
+}
+
+// SetAnchor sets where the editor shows its cursor, which the popup opens
+// below. Without it the popup opens below the start of the input field.
+func (ah *Handler) SetAnchor(cursor func() (x, y int, ok bool)) {
+	ah.anchor = cursor
+}
+
+// SetIconColors changes the colors of the popup icons of some suggestion
+// types
+func (ah *Handler) SetIconColors(colors map[autocomplete.SQLCompletionType]tcell.Color) {
+	for typ, color := range colors {
+		ah.iconColors[typ] = color
+	}
+}
+
+// popupItem renders a suggestion as a popup entry: a colored icon, the
+// text and a dimmed detail
+func (ah *Handler) popupItem(s autocomplete.Suggestion) string {
+	detail := s.DetailText
+	switch s.Type {
+	case autocomplete.Keyword:
+		detail = ""
+	case autocomplete.CatalogName:
+		detail = "Catalog"
+	case autocomplete.SchemaName:
+		if detail == "" {
+			detail = "Schema"
+		}
+	case autocomplete.Function:
+		if detail == "" {
+			detail = "Function"
+		}
+	}
+	if utf8.RuneCountInString(detail) > popupMaxDetail {
+		detail = string([]rune(detail)[:popupMaxDetail-1]) + "…"
+	}
+
+	item := fmt.Sprintf("[%s]%s[-] %s", ah.iconColors[s.Type], suggestionIcons[s.Type], tview.Escape(s.Text))
+	if detail != "" {
+		item += fmt.Sprintf("  [%s]%s[-]", tcell.ColorGray, tview.Escape(detail))
+	}
+	return item
+}
+
+// popupRect places a width by height popup below the cursor at x, y with
+// its text, which starts indent cells in, lined up under the word typed
+// before the cursor. It opens above the cursor when there is more room
+// there, and is kept on screen.
+func popupRect(x, y, word, indent, width, height, screenWidth, screenHeight int) (int, int, int, int) {
+	width = min(width, screenWidth)
+	below, above := screenHeight-y-1, y
+	if height > below && above > below {
+		height = min(height, above)
+		y -= height
+	} else {
+		height = min(height, below)
+		y++
+	}
+	x = max(0, min(x-word-indent, screenWidth-width))
+	return x, y, width, height
+}
+
+// drawPopup draws the open suggestion list over the screen, anchored at
+// the cursor
+func (ah *Handler) drawPopup(screen tcell.Screen) {
+	count := ah.suggestionBox.GetItemCount()
+	if !ah.suggestionVisible || count == 0 {
+		return
+	}
+
+	x, y, ok := 0, 0, false
+	if ah.anchor != nil {
+		x, y, ok = ah.anchor()
+	}
+	if !ok {
+		var height int
+		x, y, _, height = ah.inputField.GetInnerRect()
+		y += height - 1
+	}
+
+	width := 0
+	for i := 0; i < count; i++ {
+		main, _ := ah.suggestionBox.GetItemText(i)
+		width = max(width, tview.TaggedStringWidth(main))
+	}
+	title := ""
+	if count > popupMaxRows {
+		title = fmt.Sprintf(" %d/%d ", ah.suggestionBox.GetCurrentItem()+1, count)
+	}
+	ah.suggestionBox.SetTitle(title)
+	width = max(width, len(title)) + 2 // Border
+
+	text := ah.inputField.GetText()
+	word, _ := autocomplete.WordAtCursor(text, len(text))
+	screenWidth, screenHeight := screen.Size()
+	x, y, width, height := popupRect(x, y, utf8.RuneCountInString(word), 3, width, min(count, popupMaxRows)+2, screenWidth, screenHeight)
+	if height < 3 {
+		return // No room for even one entry
+	}
+	ah.suggestionBox.SetRect(x, y, width, height)
+	ah.suggestionBox.Draw(screen)
+}
@@ -0,0 +1,98 @@
+package tui
+
+import (
+	"maps"
+	"strings"
+	"testing"
+
+	"github.com/TFMV/trino-cli/autocomplete"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+func TestPopupRect(t *testing.T) {
+	for _, tc := range []struct {
+		name                    string
+		x, y, word              int
+		wantX, wantY, wantWidth int
+		wantHeight              int
+	}{
+		{"below the word", 20, 2, 3, 14, 3, 12, 6},
+		{"above near the bottom", 20, 20, 0, 17, 14, 12, 6},
+		{"kept on screen at the right edge", 78, 2, 0, 68, 3, 12, 6},
+		{"kept on screen at the left edge", 1, 2, 4, 0, 3, 12, 6},
+	} {
+		x, y, width, height := popupRect(tc.x, tc.y, tc.word, 3, 12, 6, 80, 24)
+		if x != tc.wantX || y != tc.wantY || width != tc.wantWidth || height != tc.wantHeight {
+			t.Errorf("%s: popupRect = %d, %d, %d, %d, want %d, %d, %d, %d", tc.name,
+				x, y, width, height, tc.wantX, tc.wantY, tc.wantWidth, tc.wantHeight)
+		}
+	}
+	// Shrinks to the room left below when that is the larger side
+	if _, y, _, height := popupRect(10, 3, 0, 3, 12, 20, 80, 10); y != 4 || height != 6 {
+		t.Errorf("popup on a short screen at y=%d with height %d, want 4 and 6", y, height)
+	}
+}
+
+func TestPopupDrawnAtCursor(t *testing.T) {
+	screen := tcell.NewSimulationScreen("")
+	if err := screen.Init(); err != nil {
+		t.Fatal(err)
+	}
+	defer screen.Fini()
+	screen.SetSize(60, 20)
+
+	input := tview.NewInputField().SetText("SELECT * FROM or")
+	input.SetRect(0, 0, 60, 1)
+	ah := &Handler{
+		suggestionBox: tview.NewList().ShowSecondaryText(false),
+		inputField:    input,
+		iconColors:    maps.Clone(defaultIconColors),
+	}
+	ah.suggestionBox.SetBorder(true)
+	ah.SetAnchor(func() (int, int, bool) { return 16, 0, true })
+	for i := 0; i < 15; i++ {
+		ah.suggestions = append(ah.suggestions, autocomplete.Suggestion{Text: "orders", Type: autocomplete.TableName, DetailText: "hive.sales.orders"})
+	}
+	ah.suggestions[0] = autocomplete.Suggestion{Text: "ORDER BY", Type: autocomplete.Keyword}
+	ah.updateSuggestionBox()
+	ah.suggestionVisible = true
+	ah.suggestionBox.SetCurrentItem(12)
+
+	ah.drawPopup(screen)
+	screen.Show()
+
+	line := func(y int) string {
+		var b strings.Builder
+		for x := 0; x < 60; x++ {
+			r, _, _, _ := screen.GetContent(x, y)
+			b.WriteRune(r)
+		}
+		return b.String()
+	}
+	// The entries line up under the typed word, two columns left of the cursor
+	if got := line(2); !strings.HasPrefix(strings.TrimSpace(got), "│T orders  hive.sales.orders") || string([]rune(got)[14:20]) != "orders" {
+		t.Errorf("popup row = %q", got)
+	}
+	if got := line(1); !strings.Contains(got, "13/15") {
+		t.Errorf("popup title = %q, want the position in the list", got)
+	}
+	// Scrolled so the current entry, not the first, is shown
+	for y := 1; y < 12; y++ {
+		if strings.Contains(line(y), "ORDER BY") {
+			t.Errorf("row %d shows the first entry after scrolling: %q", y, line(y))
+		}
+	}
+	_, _, style, _ := screen.GetContent(12, 2)
+	if fg, _, _ := style.Decompose(); fg != defaultIconColors[autocomplete.TableName] {
+		t.Errorf("table icon color = %v, want %v", fg, defaultIconColors[autocomplete.TableName])
+	}
+
+	ah.HideSuggestions()
+	screen.Clear()
+	ah.drawPopup(screen)
+	screen.Show()
+	if got := strings.TrimSpace(line(2)); got != "" {
+		t.Errorf("hidden popup drawn: %q", got)
+	}
+}
@@ -0,0 +1,187 @@
+package autocomplete
+
+import (
+	"math"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// usageHalfLife is how long it takes the learned boost of a suggestion that
+// is no longer picked to halve
+const usageHalfLife = 30 * 24 * time.Hour
+
+// usageMinScore is the decayed score below which usage is forgotten
+const usageMinScore = 0.5
+
+// Usage lifts a suggestion's score by up to maxUsageBoost, reaching half of
+// it at usageSaturation, so habit reorders close matches but never puts a
+// weak match above a good one
+const (
+	maxUsageBoost   = 0.15
+	usageSaturation = 20.0
+)
+
+// Usage kinds, one per trie the learned boost is restored to
+const (
+	usageKeyword = "keyword"
+	usageObject  = "object"
+)
+
+// usageKey identifies a suggestion whose use is tracked
+type usageKey struct {
+	kind string
+	word string
+}
+
+// usageEntry is a suggestion's learned score as of its last use
+type usageEntry struct {
+	score    float64
+	lastUsed time.Time
+}
+
+// decayed returns the score left at now
+func (u usageEntry) decayed(now time.Time) float64 {
+	age := now.Sub(u.lastUsed)
+	if age <= 0 {
+		return u.score
+	}
+	return u.score * math.Pow(0.5, float64(age)/float64(usageHalfLife))
+}
+
+// usageKind returns the usage kind of a suggestion type, or "" for types
+// whose use is not learned
+func usageKind(typ SQLCompletionType) string {
+	switch typ {
+	case Keyword:
+		return usageKeyword
+	case CatalogName, SchemaName, TableName, ColumnName, Function:
+		return usageObject
+	}
+	return ""
+}
+
+// RecordUsage adds amount to the learned score of a picked suggestion,
+// after decaying what it had, and stores it so it outlives the session
+func (sc *SchemaCache) RecordUsage(kind, word string, amount float64) error {
+	sc.lock.Lock()
+	defer sc.lock.Unlock()
+
+	now := time.Now()
+	key := usageKey{kind: kind, word: strings.ToLower(word)}
+	entry := usageEntry{score: sc.usage[key].decayed(now) + amount, lastUsed: now}
+
+	if _, err := sc.db.Exec(
+		"INSERT OR REPLACE INTO suggestion_usage (kind, word, score, last_used) VALUES (?, ?, ?, ?)",
+		key.kind, key.word, entry.score, now.Unix(),
+	); err != nil {
+		return err
+	}
+
+	sc.usage[key] = entry
+	return nil
+}
+
+// UsageScore returns the decayed learned score of a suggestion
+func (sc *SchemaCache) UsageScore(kind, word string) float64 {
+	sc.lock.RLock()
+	defer sc.lock.RUnlock()
+	entry, ok := sc.usage[usageKey{kind: kind, word: strings.ToLower(word)}]
+	if !ok {
+		return 0
+	}
+	return entry.decayed(time.Now())
+}
+
+// UsageScores returns the decayed learned scores of every suggestion of a
+// kind, by lower-cased word
+func (sc *SchemaCache) UsageScores(kind string) map[string]float64 {
+	sc.lock.RLock()
+	defer sc.lock.RUnlock()
+
+	now := time.Now()
+	scores := make(map[string]float64)
+	for key, entry := range sc.usage {
+		if key.kind == kind {
+			scores[key.word] = entry.decayed(now)
+		}
+	}
+	return scores
+}
+
+// loadUsage reads the stored usage, forgetting suggestions whose score has
+// decayed away
+func (sc *SchemaCache) loadUsage() error {
+	sc.lock.Lock()
+	defer sc.lock.Unlock()
+
+	rows, err := sc.db.Query("SELECT kind, word, score, last_used FROM suggestion_usage")
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	now := time.Now()
+	usage := make(map[usageKey]usageEntry)
+	var forgotten []usageKey
+	for rows.Next() {
+		var key usageKey
+		var score float64
+		var lastUsed int64
+		if err := rows.Scan(&key.kind, &key.word, &score, &lastUsed); err != nil {
+			return err
+		}
+		entry := usageEntry{score: score, lastUsed: time.Unix(lastUsed, 0)}
+		if entry.decayed(now) < usageMinScore {
+			forgotten = append(forgotten, key)
+			continue
+		}
+		usage[key] = entry
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	rows.Close()
+
+	for _, key := range forgotten {
+		if _, err := sc.db.Exec("DELETE FROM suggestion_usage WHERE kind = ? AND word = ?", key.kind, key.word); err != nil {
+			return err
+		}
+	}
+
+	sc.usage = usage
+	sc.logger.Debug("Loaded suggestion usage",
+		zap.Int("entries", len(usage)),
+		zap.Int("forgotten", len(forgotten)))
+	return nil
+}
+
+// restoreUsage lifts the words in the tries by their learned scores, so
+// rankings picked up in earlier sessions apply from the start
+func (ac *AutocompleteService) restoreUsage() {
+	for word, score := range ac.cache.UsageScores(usageKeyword) {
+		ac.keywordTrie.BoostWord(word, int(math.Round(score)))
+	}
+	for word, score := range ac.cache.UsageScores(usageObject) {
+		ac.cache.BoostWord(word, int(math.Round(score)))
+	}
+}
+
+// applyUsage raises the scores of suggestions the user has picked before.
+// Callers must hold ac.mu.
+func (ac *AutocompleteService) applyUsage(suggestions []Suggestion) {
+	if ac.cache == nil {
+		return
+	}
+	for i := range suggestions {
+		s := &suggestions[i]
+		kind := usageKind(s.Type)
+		if kind == "" {
+			continue
+		}
+		if u := ac.cache.UsageScore(kind, s.Text); u > 0 {
+			s.Score += maxUsageBoost * u / (u + usageSaturation)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package autocomplete
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func TestUsagePersistsAndDecays(t *testing.T) {
+	dir := t.TempDir()
+	cache, err := NewSchemaCache(dir, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		if err := cache.RecordUsage(usageObject, "Orders", 10); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := cache.RecordUsage(usageObject, "stale", 10); err != nil {
+		t.Fatal(err)
+	}
+	if err := cache.RecordUsage(usageKeyword, "SELECT", 5); err != nil {
+		t.Fatal(err)
+	}
+	// Age one entry by a half-life and the other long enough to forget it
+	age := func(word string, by time.Duration) {
+		if _, err := cache.db.Exec("UPDATE suggestion_usage SET last_used = ? WHERE word = ?",
+			time.Now().Add(-by).Unix(), word); err != nil {
+			t.Fatal(err)
+		}
+	}
+	age("orders", usageHalfLife)
+	age("stale", 10*usageHalfLife)
+	cache.db.Close()
+
+	reopened, err := NewSchemaCache(dir, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reopened.db.Close()
+	if err := reopened.LoadCache(); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := reopened.UsageScore(usageObject, "ORDERS"); math.Abs(got-15) > 0.1 {
+		t.Errorf("orders usage after a half-life = %.2f, want 15", got)
+	}
+	if got := reopened.UsageScore(usageKeyword, "select"); math.Abs(got-5) > 0.1 {
+		t.Errorf("select usage = %.2f, want 5", got)
+	}
+	if got := reopened.UsageScore(usageObject, "stale"); got != 0 {
+		t.Errorf("decayed usage should be forgotten, got %.2f", got)
+	}
+	var rows int
+	if err := reopened.db.QueryRow("SELECT COUNT(*) FROM suggestion_usage").Scan(&rows); err != nil || rows != 2 {
+		t.Errorf("stored usage rows = %d, %v, want 2", rows, err)
+	}
+}
+
+func TestUsageRanksPickedSuggestionsFirst(t *testing.T) {
+	dir := t.TempDir()
+	ac, err := NewAutocompleteService(nil, dir, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ac.cache.StoreSchema(SchemaMetadata{Catalog: "hive", Name: "sales", Tables: []TableMetadata{
+		{Name: "orders"}, {Name: "orgs"},
+	}}); err != nil {
+		t.Fatal(err)
+	}
+
+	first := func(ac *AutocompleteService) string {
+		query := "SELECT * FROM sales.or"
+		suggestions, err := ac.GetCompletions(context.Background(), query, len(query))
+		if err != nil || len(suggestions) == 0 {
+			t.Fatalf("GetCompletions = %+v, %v", suggestions, err)
+		}
+		return suggestions[0].Text
+	}
+	if got := first(ac); got != "orgs" {
+		t.Fatalf("the closer match should rank first before any use, got %q", got)
+	}
+	ac.BoostSuggestion(Suggestion{Text: "orders", Type: TableName})
+	if got := first(ac); got != "orders" {
+		t.Errorf("a picked suggestion should rank first, got %q", got)
+	}
+	ac.cache.db.Close()
+
+	// The ranking survives a restart
+	restarted, err := NewAutocompleteService(nil, dir, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer restarted.cache.db.Close()
+	if err := restarted.cache.LoadCache(); err != nil {
+		t.Fatal(err)
+	}
+	if got := first(restarted); got != "orders" {
+		t.Errorf("after a restart the picked suggestion should still rank first, got %q", got)
+	}
+}
@@ -0,0 +1,117 @@
+package bookmark
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// Bookmark is a table a user marked in the schema browser of a profile
+type Bookmark struct {
+	Profile string    `json:"profile"`
+	Catalog string    `json:"catalog"`
+	Schema  string    `json:"schema"`
+	Table   string    `json:"table"`
+	Created time.Time `json:"created"`
+}
+
+var db *sql.DB
+
+// Initialize sets up the bookmark database under ~/.trino-cli/bookmarks
+func Initialize() error {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return fmt.Errorf("failed to get home directory: %w", err)
+	}
+
+	bookmarkDir := filepath.Join(homeDir, ".trino-cli", "bookmarks")
+	if err := os.MkdirAll(bookmarkDir, 0755); err != nil {
+		return fmt.Errorf("failed to create bookmark directory: %w", err)
+	}
+
+	db, err = sql.Open("sqlite3", filepath.Join(bookmarkDir, "bookmarks.db"))
+	if err != nil {
+		return fmt.Errorf("failed to open bookmark database: %w", err)
+	}
+
+	createTableSQL := `
+	CREATE TABLE IF NOT EXISTS bookmarks (
+		profile TEXT NOT NULL,
+		catalog TEXT NOT NULL,
+		schema_name TEXT NOT NULL,
+		table_name TEXT NOT NULL,
+		created DATETIME DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (profile, catalog, schema_name, table_name)
+	);
+	`
+	if _, err := db.Exec(createTableSQL); err != nil {
+		return fmt.Errorf("failed to create bookmark table: %w", err)
+	}
+	return nil
+}
+
+// Close closes the database connection
+func Close() error {
+	if db != nil {
+		return db.Close()
+	}
+	return nil
+}
+
+// Add bookmarks a table for a profile; bookmarking it again does nothing
+func Add(ctx context.Context, profile, catalog, schema, table string) error {
+	if db == nil {
+		return fmt.Errorf("bookmark database not initialized")
+	}
+	_, err := db.ExecContext(ctx,
+		`INSERT OR IGNORE INTO bookmarks (profile, catalog, schema_name, table_name, created) VALUES (?, ?, ?, ?, ?)`,
+		profile, catalog, schema, table, time.Now().UTC())
+	if err != nil {
+		return fmt.Errorf("failed to save bookmark: %w", err)
+	}
+	return nil
+}
+
+// Remove deletes a table's bookmark for a profile, if it has one
+func Remove(ctx context.Context, profile, catalog, schema, table string) error {
+	if db == nil {
+		return fmt.Errorf("bookmark database not initialized")
+	}
+	_, err := db.ExecContext(ctx,
+		`DELETE FROM bookmarks WHERE profile = ? AND catalog = ? AND schema_name = ? AND table_name = ?`,
+		profile, catalog, schema, table)
+	if err != nil {
+		return fmt.Errorf("failed to remove bookmark: %w", err)
+	}
+	return nil
+}
+
+// List returns a profile's bookmarks ordered by catalog, schema and table
+func List(ctx context.Context, profile string) ([]Bookmark, error) {
+	if db == nil {
+		return nil, fmt.Errorf("bookmark database not initialized")
+	}
+
+	rows, err := db.QueryContext(ctx,
+		`SELECT profile, catalog, schema_name, table_name, created FROM bookmarks WHERE profile = ? ORDER BY catalog, schema_name, table_name`,
+		profile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list bookmarks: %w", err)
+	}
+	defer rows.Close()
+
+	var bookmarks []Bookmark
+	for rows.Next() {
+		var b Bookmark
+		if err := rows.Scan(&b.Profile, &b.Catalog, &b.Schema, &b.Table, &b.Created); err != nil {
+			return nil, fmt.Errorf("failed to scan bookmark: %w", err)
+		}
+		bookmarks = append(bookmarks, b)
+	}
+	return bookmarks, rows.Err()
+}
@@ -0,0 +1,45 @@
+package bookmark
+
+import (
+	"context"
+	"testing"
+)
+
+func TestAddRemoveList(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	if err := Initialize(); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	defer Close()
+
+	ctx := context.Background()
+	for _, table := range []string{"orders", "customers", "orders"} {
+		if err := Add(ctx, "prod", "hive", "sales", table); err != nil {
+			t.Fatalf("Add failed: %v", err)
+		}
+	}
+	if err := Add(ctx, "dev", "hive", "sales", "scratch"); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	bookmarks, err := List(ctx, "prod")
+	if err != nil || len(bookmarks) != 2 || bookmarks[0].Table != "customers" || bookmarks[1].Table != "orders" {
+		t.Fatalf("List = %+v, %v", bookmarks, err)
+	}
+	if bookmarks[0].Created.IsZero() {
+		t.Error("bookmark has no creation time")
+	}
+
+	if err := Remove(ctx, "prod", "hive", "sales", "orders"); err != nil {
+		t.Fatalf("Remove failed: %v", err)
+	}
+	if err := Remove(ctx, "prod", "hive", "sales", "missing"); err != nil {
+		t.Errorf("Remove of a missing bookmark = %v", err)
+	}
+	if bookmarks, _ := List(ctx, "prod"); len(bookmarks) != 1 || bookmarks[0].Table != "customers" {
+		t.Errorf("List after Remove = %+v", bookmarks)
+	}
+	if bookmarks, _ := List(ctx, "dev"); len(bookmarks) != 1 {
+		t.Errorf("another profile's bookmarks = %+v", bookmarks)
+	}
+}
@@ -0,0 +1,245 @@
+package bundle
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/TFMV/trino-cli/engine"
+)
+
+// A bundle file is laid out as:
+//
+//	magic (4 bytes) | salt (16 bytes) | nonce (12 bytes) | AES-256-GCM ciphertext
+//
+// The plaintext is a gzipped tar archive holding metadata.json, query.sql,
+// result.arrow, and plan.txt.
+const (
+	magic         = "TCB1"
+	saltSize      = 16
+	keySize       = 32
+	kdfIterations = 600000
+
+	metadataFile = "metadata.json"
+	queryFile    = "query.sql"
+	resultFile   = "result.arrow"
+	planFile     = "plan.txt"
+)
+
+// ErrInvalidPassphrase is returned when a bundle cannot be decrypted
+var ErrInvalidPassphrase = errors.New("invalid passphrase or corrupted bundle")
+
+// Metadata describes the query a bundle was created from
+type Metadata struct {
+	HistoryID  string        `json:"history_id"`
+	Profile    string        `json:"profile"`
+	ExecutedAt time.Time     `json:"executed_at"`
+	Duration   time.Duration `json:"duration"`
+	Rows       int           `json:"rows"`
+	Columns    []string      `json:"columns"`
+	CreatedAt  time.Time     `json:"created_at"`
+	CreatedBy  string        `json:"created_by,omitempty"`
+	Note       string        `json:"note,omitempty"`
+}
+
+// Bundle is a self-contained snapshot of a query and its result
+type Bundle struct {
+	Metadata Metadata            `json:"metadata"`
+	Query    string              `json:"query"`
+	Plan     string              `json:"plan,omitempty"`
+	Result   *engine.QueryResult `json:"result"`
+}
+
+// Write encrypts the bundle with the passphrase and writes it to path
+func Write(path string, b *Bundle, passphrase string) error {
+	data, err := Encode(b, passphrase)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+// Read loads and decrypts a bundle from path
+func Read(path string, passphrase string) (*Bundle, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Decode(data, passphrase)
+}
+
+// Encode serializes and encrypts a bundle
+func Encode(b *Bundle, passphrase string) ([]byte, error) {
+	if passphrase == "" {
+		return nil, fmt.Errorf("passphrase must not be empty")
+	}
+
+	archive, err := archiveBundle(b)
+	if err != nil {
+		return nil, err
+	}
+
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	gcm, err := newGCM(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	out := make([]byte, 0, len(magic)+saltSize+len(nonce)+len(archive)+gcm.Overhead())
+	out = append(out, magic...)
+	out = append(out, salt...)
+	out = append(out, nonce...)
+	return gcm.Seal(out, nonce, archive, []byte(magic)), nil
+}
+
+// Decode decrypts and deserializes a bundle
+func Decode(data []byte, passphrase string) (*Bundle, error) {
+	if len(data) < len(magic)+saltSize || string(data[:len(magic)]) != magic {
+		return nil, fmt.Errorf("not a trino-cli bundle")
+	}
+	salt := data[len(magic) : len(magic)+saltSize]
+
+	gcm, err := newGCM(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	rest := data[len(magic)+saltSize:]
+	if len(rest) < gcm.NonceSize() {
+		return nil, fmt.Errorf("truncated bundle")
+	}
+	nonce, ciphertext := rest[:gcm.NonceSize()], rest[gcm.NonceSize():]
+
+	archive, err := gcm.Open(nil, nonce, ciphertext, []byte(magic))
+	if err != nil {
+		return nil, ErrInvalidPassphrase
+	}
+
+	return unarchiveBundle(archive)
+}
+
+// newGCM derives a key from the passphrase and returns an AES-GCM cipher
+func newGCM(passphrase string, salt []byte) (cipher.AEAD, error) {
+	key, err := pbkdf2.Key(sha256.New, passphrase, salt, kdfIterations, keySize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive key: %w", err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// archiveBundle packs the bundle contents into a gzipped tar archive
+func archiveBundle(b *Bundle) ([]byte, error) {
+	metadata, err := json.MarshalIndent(b.Metadata, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode metadata: %w", err)
+	}
+
+	files := []struct {
+		name string
+		data []byte
+	}{
+		{metadataFile, metadata},
+		{queryFile, []byte(b.Query)},
+		{planFile, []byte(b.Plan)},
+	}
+	if b.Result != nil {
+		result, err := engine.ExportArrow(b.Result)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode result: %w", err)
+		}
+		files = append(files, struct {
+			name string
+			data []byte
+		}{resultFile, result})
+	}
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for _, f := range files {
+		hdr := &tar.Header{
+			Name:    f.name,
+			Mode:    0600,
+			Size:    int64(len(f.data)),
+			ModTime: b.Metadata.CreatedAt,
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return nil, err
+		}
+		if _, err := tw.Write(f.data); err != nil {
+			return nil, err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// unarchiveBundle unpacks a gzipped tar archive into a bundle
+func unarchiveBundle(archive []byte) (*Bundle, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(archive))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bundle archive: %w", err)
+	}
+	defer gz.Close()
+
+	b := &Bundle{}
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read bundle archive: %w", err)
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", hdr.Name, err)
+		}
+
+		switch hdr.Name {
+		case metadataFile:
+			if err := json.Unmarshal(data, &b.Metadata); err != nil {
+				return nil, fmt.Errorf("failed to decode metadata: %w", err)
+			}
+		case queryFile:
+			b.Query = string(data)
+		case planFile:
+			b.Plan = string(data)
+		case resultFile:
+			if b.Result, err = engine.ImportArrow(data); err != nil {
+				return nil, fmt.Errorf("failed to decode result: %w", err)
+			}
+		}
+	}
+	return b, nil
+}
@@ -0,0 +1,76 @@
+package bundle
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/TFMV/trino-cli/engine"
+)
+
+func TestBundleRoundTrip(t *testing.T) {
+	original := &Bundle{
+		Metadata: Metadata{
+			HistoryID: "123",
+			Profile:   "prod",
+			Duration:  1500 * time.Millisecond,
+			Rows:      2,
+			Columns:   []string{"id", "name"},
+			CreatedAt: time.Now().UTC().Truncate(time.Second),
+			Note:      "weekly numbers",
+		},
+		Query: "SELECT id, name FROM users",
+		Plan:  "Fragment 0 [SINGLE]\n",
+		Result: &engine.QueryResult{
+			Columns: []string{"id", "name"},
+			Rows: [][]interface{}{
+				{int64(1), "alice"},
+				{int64(2), nil},
+			},
+		},
+	}
+
+	path := filepath.Join(t.TempDir(), "result.tcb")
+	if err := Write(path, original, "s3cret"); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	loaded, err := Read(path, "s3cret")
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+
+	if loaded.Query != original.Query || loaded.Plan != original.Plan {
+		t.Errorf("query/plan mismatch: got %q / %q", loaded.Query, loaded.Plan)
+	}
+	if loaded.Metadata.HistoryID != "123" || loaded.Metadata.Note != "weekly numbers" {
+		t.Errorf("metadata mismatch: %+v", loaded.Metadata)
+	}
+	if !loaded.Metadata.CreatedAt.Equal(original.Metadata.CreatedAt) {
+		t.Errorf("created_at mismatch: %v != %v", loaded.Metadata.CreatedAt, original.Metadata.CreatedAt)
+	}
+	if loaded.Result == nil || len(loaded.Result.Rows) != 2 {
+		t.Fatalf("expected 2 result rows, got %+v", loaded.Result)
+	}
+	if loaded.Result.Rows[0][0] != int64(1) || loaded.Result.Rows[0][1] != "alice" {
+		t.Errorf("unexpected first row: %v", loaded.Result.Rows[0])
+	}
+	if loaded.Result.Rows[1][1] != nil {
+		t.Errorf("expected NULL to round-trip, got %v", loaded.Result.Rows[1][1])
+	}
+}
+
+func TestBundleWrongPassphrase(t *testing.T) {
+	data, err := Encode(&Bundle{Query: "SELECT 1"}, "right")
+	if err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+
+	if _, err := Decode(data, "wrong"); !errors.Is(err, ErrInvalidPassphrase) {
+		t.Fatalf("expected ErrInvalidPassphrase, got %v", err)
+	}
+	if _, err := Decode([]byte("garbage"), "right"); err == nil {
+		t.Fatal("expected error decoding non-bundle data")
+	}
+}
@@ -3,17 +3,24 @@ package cache
 import (
 	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 )
 
-// Note: In a production implementation, query results would be stored in Apache Arrow IPC format on disk.
-// For demonstration purposes, we use an in-memory map and slice.
+// Query results are stored on disk in Apache Arrow IPC format, one file per
+// cache key under ~/.trino-cli/cache.
+
+// ErrNotFound is returned when a cache entry does not exist
+var ErrNotFound = errors.New("cache entry not found")
+
+// resultExt is the file extension of cached results
+const resultExt = ".arrow"
 
 // queryHistory holds the history of executed queries.
 var queryHistory = []string{}
 
-// cacheStore maps query IDs to cached result strings.
-var cacheStore = map[string]string{}
-
 // GetHistory returns the list of executed queries.
 func GetHistory() ([]string, error) {
 	// In production, read from a persistent history file or database.
@@ -25,29 +32,97 @@ func AddToHistory(query string) {
 	queryHistory = append(queryHistory, query)
 }
 
+// Dir returns the directory holding cached results.
+func Dir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, ".trino-cli", "cache"), nil
+}
+
+// path returns the file for a cache key, rejecting keys that would escape Dir.
+func path(key string) (string, error) {
+	if key == "" || strings.ContainsAny(key, `/\`) || key == "." || key == ".." {
+		return "", fmt.Errorf("invalid cache key %q", key)
+	}
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, key+resultExt), nil
+}
+
 // ListCache returns all cached query identifiers.
 func ListCache() ([]string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return []string{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cache directory: %w", err)
+	}
+
 	keys := []string{}
-	for k := range cacheStore {
-		keys = append(keys, k)
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), resultExt) {
+			keys = append(keys, strings.TrimSuffix(entry.Name(), resultExt))
+		}
 	}
+	sort.Strings(keys)
 	return keys, nil
 }
 
-// ReplayCache retrieves a cached query result by its query ID.
-func ReplayCache(queryID string) (string, error) {
-	result, ok := cacheStore[queryID]
-	if !ok {
-		return "", errors.New("cache entry not found")
+// Load retrieves a cached query result by its key.
+func Load(key string) ([]byte, error) {
+	file, err := path(key)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(file)
+	if os.IsNotExist(err) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cache entry: %w", err)
+	}
+	return data, nil
+}
+
+// Save stores a query result under key, replacing any previous entry.
+func Save(key string, data []byte) error {
+	file, err := path(key)
+	if err != nil {
+		return err
 	}
-	return result, nil
+	if err := os.MkdirAll(filepath.Dir(file), 0700); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	// Write to a temp file first so readers never see a partial result
+	tmp := file + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	if err := os.Rename(tmp, file); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	return nil
 }
 
-// SaveCache stores a query result in the cache.
-func SaveCache(queryID string, result string) error {
-	if _, exists := cacheStore[queryID]; exists {
-		return fmt.Errorf("cache entry already exists")
+// Delete removes the cached result of key. A missing entry is not an error.
+func Delete(key string) error {
+	file, err := path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete cache entry: %w", err)
 	}
-	cacheStore[queryID] = result
 	return nil
 }
@@ -0,0 +1,38 @@
+package cache
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSaveLoadList(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	if keys, err := ListCache(); err != nil || len(keys) != 0 {
+		t.Fatalf("expected empty cache, got %v, %v", keys, err)
+	}
+	if _, err := Load("missing"); err != ErrNotFound {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+
+	if err := Save("b", []byte("second")); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if err := Save("a", []byte("first")); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	data, err := Load("a")
+	if err != nil || !bytes.Equal(data, []byte("first")) {
+		t.Errorf("Load = %q, %v", data, err)
+	}
+
+	keys, err := ListCache()
+	if err != nil || len(keys) != 2 || keys[0] != "a" || keys[1] != "b" {
+		t.Errorf("ListCache = %v, %v", keys, err)
+	}
+
+	if err := Save("../escape", nil); err == nil {
+		t.Error("expected keys with path separators to be rejected")
+	}
+}
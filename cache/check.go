@@ -0,0 +1,56 @@
+package cache
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Health describes the state of the result cache
+type Health struct {
+	Dir     string `json:"dir"`
+	Exists  bool   `json:"exists"`
+	Entries int    `json:"entries"`
+	Size    int64  `json:"size_bytes"`
+	Partial int    `json:"partial"` // Results an interrupted save left half-written
+}
+
+// Check inspects the result cache, and that results can be saved to it
+func Check() (Health, error) {
+	dir, err := Dir()
+	if err != nil {
+		return Health{}, err
+	}
+	health := Health{Dir: dir}
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return health, nil
+	}
+	if err != nil {
+		return health, fmt.Errorf("failed to read cache directory: %w", err)
+	}
+	health.Exists = true
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		switch name := entry.Name(); {
+		case strings.HasSuffix(name, resultExt):
+			health.Entries++
+			if info, err := entry.Info(); err == nil {
+				health.Size += info.Size()
+			}
+		case strings.HasSuffix(name, resultExt+".tmp"):
+			health.Partial++
+		}
+	}
+
+	probe, err := os.CreateTemp(dir, ".check-*")
+	if err != nil {
+		return health, fmt.Errorf("cache directory isn't writable: %w", err)
+	}
+	probe.Close()
+	os.Remove(probe.Name())
+	return health, nil
+}
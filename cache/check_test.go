@@ -0,0 +1,39 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	health, err := Check()
+	if err != nil || health.Exists {
+		t.Fatalf("expected a missing cache, got %+v, %v", health, err)
+	}
+
+	if err := Save("a", []byte("first")); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if err := Save("b", []byte("second")); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	dir, _ := Dir()
+	if err := os.WriteFile(filepath.Join(dir, "c"+resultExt+".tmp"), []byte("thi"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	health, err = Check()
+	if err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+	want := Health{Dir: dir, Exists: true, Entries: 2, Size: int64(len("first") + len("second")), Partial: 1}
+	if health != want {
+		t.Errorf("health = %+v, want %+v", health, want)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 3 {
+		t.Errorf("Check left files behind: %v", entries)
+	}
+}
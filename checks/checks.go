@@ -0,0 +1,181 @@
+// Package checks reads data quality checks, queries paired with what their
+// results must satisfy, and evaluates results against them. A checks file
+// lists them in YAML:
+//
+//	checks:
+//	  - name: no duplicate orders
+//	    query: SELECT order_id FROM orders GROUP BY order_id HAVING count(*) > 1
+//	    expect:
+//	      rows: 0
+//	  - name: orders loaded today
+//	    query: SELECT count(*) FROM orders WHERE order_date = current_date
+//	    expect:
+//	      value: ">= 1000"
+package checks
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/TFMV/trino-cli/engine"
+	"gopkg.in/yaml.v3"
+)
+
+// maxViolations is how many rows a failure lists before summing up the rest
+const maxViolations = 5
+
+// File is a checks file
+type File struct {
+	Checks []Check `yaml:"checks"`
+}
+
+// Check is a query and what its result must satisfy
+type Check struct {
+	Name   string `yaml:"name"`
+	Query  string `yaml:"query"`
+	Expect Expect `yaml:"expect"`
+
+	rows  *Condition
+	value *Condition
+}
+
+// Expect holds the conditions of a check, each a comparison such as
+// ">= 1000" or "!= null". A bare value is compared for equality.
+type Expect struct {
+	Rows   string `yaml:"rows"`   // Condition on the number of rows
+	Value  string `yaml:"value"`  // Condition every row's value must meet
+	Column string `yaml:"column"` // Column of the value; defaults to the first
+}
+
+// Load reads the checks of a file, rejecting unknown keys, checks without
+// a query or expectation, and conditions that don't parse
+func Load(path string) ([]Check, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	checks, err := parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return checks, nil
+}
+
+// parse decodes the checks of a checks file
+func parse(data []byte) ([]Check, error) {
+	var file File
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&file); err != nil && !errors.Is(err, io.EOF) {
+		return nil, errors.New(strings.TrimPrefix(err.Error(), "yaml: "))
+	}
+	if len(file.Checks) == 0 {
+		return nil, errors.New("no checks; list them under checks:")
+	}
+
+	for i := range file.Checks {
+		if err := file.Checks[i].prepare(i); err != nil {
+			return nil, err
+		}
+	}
+	return file.Checks, nil
+}
+
+// prepare names the i-th check of a file if it has no name, and parses its
+// conditions
+func (c *Check) prepare(i int) error {
+	if c.Name == "" {
+		c.Name = fmt.Sprintf("check %d", i+1)
+	}
+	if strings.TrimSpace(c.Query) == "" {
+		return fmt.Errorf("%s: no query", c.Name)
+	}
+	if c.Expect.Rows == "" && c.Expect.Value == "" {
+		return fmt.Errorf("%s: expects nothing; give expect.rows or expect.value", c.Name)
+	}
+	if c.Expect.Column != "" && c.Expect.Value == "" {
+		return fmt.Errorf("%s: expect.column needs expect.value", c.Name)
+	}
+	if c.Expect.Rows != "" {
+		rows, err := ParseCondition(c.Expect.Rows)
+		if err != nil {
+			return fmt.Errorf("%s: expect.rows: %w", c.Name, err)
+		}
+		if rows.number == nil {
+			return fmt.Errorf("%s: expect.rows compares with a number, not %q", c.Name, rows.Bound)
+		}
+		c.rows = &rows
+	}
+	if c.Expect.Value != "" {
+		value, err := ParseCondition(c.Expect.Value)
+		if err != nil {
+			return fmt.Errorf("%s: expect.value: %w", c.Name, err)
+		}
+		c.value = &value
+	}
+	return nil
+}
+
+// Evaluate returns why result fails the check, or nothing when it passes
+func (c Check) Evaluate(result *engine.QueryResult) []string {
+	var failures []string
+	if c.rows != nil {
+		if ok, _ := c.rows.Holds(len(result.Rows)); !ok {
+			failures = append(failures, fmt.Sprintf("%d rows, expected %s", len(result.Rows), c.rows))
+		}
+	}
+	if c.value == nil {
+		return failures
+	}
+
+	column, name := 0, c.Expect.Column
+	switch {
+	case len(result.Columns) == 0:
+		return append(failures, "no columns to compare")
+	case name == "":
+		name = result.Columns[0]
+	default:
+		column = -1
+		for i, n := range result.Columns {
+			if strings.EqualFold(n, c.Expect.Column) {
+				column, name = i, n
+			}
+		}
+		if column < 0 {
+			return append(failures, fmt.Sprintf("no column %s; the result has %s", name, strings.Join(result.Columns, ", ")))
+		}
+	}
+	if len(result.Rows) == 0 {
+		return append(failures, fmt.Sprintf("no rows, expected %s %s", name, c.value))
+	}
+
+	violations := 0
+	for i, row := range result.Rows {
+		ok, err := c.value.Holds(row[column])
+		if ok {
+			continue
+		}
+		violations++
+		if violations > maxViolations {
+			continue
+		}
+		failure := fmt.Sprintf("%s is %s", name, formatValue(row[column]))
+		if len(result.Rows) > 1 {
+			failure += fmt.Sprintf(" in row %d", i+1)
+		}
+		if err != nil {
+			failure += fmt.Sprintf(", which can't be compared with %s: %v", c.value, err)
+		} else {
+			failure += fmt.Sprintf(", expected %s", c.value)
+		}
+		failures = append(failures, failure)
+	}
+	if violations > maxViolations {
+		failures = append(failures, fmt.Sprintf("and %d more rows", violations-maxViolations))
+	}
+	return failures
+}
@@ -0,0 +1,102 @@
+package checks
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/TFMV/trino-cli/engine"
+)
+
+func TestParse(t *testing.T) {
+	checks, err := parse([]byte(`
+checks:
+  - name: no duplicates
+    query: SELECT id FROM t GROUP BY id HAVING count(*) > 1
+    expect:
+      rows: 0
+  - query: SELECT avg(x) AS mean FROM t
+    expect:
+      column: mean
+      value: "< 10"
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(checks) != 2 || checks[0].Name != "no duplicates" || checks[1].Name != "check 2" {
+		t.Errorf("checks = %+v", checks)
+	}
+
+	for _, tt := range []struct{ yaml, want string }{
+		{"", "no checks"},
+		{"checks:\n  - query: SELECT 1\n    expect:\n      rowz: 1\n", "field rowz not found"},
+		{"checks:\n  - name: a\n    expect:\n      rows: 1\n", "a: no query"},
+		{"checks:\n  - name: a\n    query: SELECT 1\n", "a: expects nothing"},
+		{"checks:\n  - name: a\n    query: SELECT 1\n    expect:\n      rows: many\n", "a: expect.rows compares with a number"},
+		{"checks:\n  - name: a\n    query: SELECT 1\n    expect:\n      value: < null\n", "a: expect.value: null can only"},
+	} {
+		if _, err := parse([]byte(tt.yaml)); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("parse(%q) error = %v, want %q", tt.yaml, err, tt.want)
+		}
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	result := &engine.QueryResult{
+		Columns: []string{"day", "orders"},
+		Rows: [][]interface{}{
+			{"2026-10-14", int64(1200)},
+			{"2026-10-15", int64(800)},
+			{"2026-10-16", nil},
+		},
+	}
+	tests := []struct {
+		expect Expect
+		want   []string
+	}{
+		{Expect{Rows: "3"}, nil},
+		{Expect{Rows: "0"}, []string{"3 rows, expected = 0"}},
+		{Expect{Value: ">= 1000", Column: "ORDERS"}, []string{
+			"orders is 800 in row 2, expected >= 1000",
+			"orders is NULL in row 3, expected >= 1000",
+		}},
+		{Expect{Value: "> 100"}, []string{
+			"day is 2026-10-14 in row 1, which can't be compared with > 100: it isn't a number",
+			"day is 2026-10-15 in row 2, which can't be compared with > 100: it isn't a number",
+			"day is 2026-10-16 in row 3, which can't be compared with > 100: it isn't a number",
+		}},
+		{Expect{Value: "1", Column: "total"}, []string{"no column total; the result has day, orders"}},
+	}
+	for _, tt := range tests {
+		c := Check{Name: "c", Query: "SELECT 1", Expect: tt.expect}
+		if err := c.prepare(0); err != nil {
+			t.Fatal(err)
+		}
+		if got := c.Evaluate(result); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%+v: failures = %q, want %q", tt.expect, got, tt.want)
+		}
+	}
+
+	empty := Check{Name: "c", Query: "SELECT 1", Expect: Expect{Value: "1"}}
+	if err := empty.prepare(0); err != nil {
+		t.Fatal(err)
+	}
+	if got := empty.Evaluate(&engine.QueryResult{Columns: []string{"n"}}); !reflect.DeepEqual(got, []string{"no rows, expected n = 1"}) {
+		t.Errorf("failures = %q", got)
+	}
+}
+
+func TestEvaluateLimitsViolations(t *testing.T) {
+	result := &engine.QueryResult{Columns: []string{"n"}}
+	for i := 0; i < 8; i++ {
+		result.Rows = append(result.Rows, []interface{}{int64(i)})
+	}
+	checks, err := parse([]byte("checks:\n  - query: SELECT 1\n    expect:\n      value: '> 100'\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	failures := checks[0].Evaluate(result)
+	if len(failures) != maxViolations+1 || failures[maxViolations] != "and 3 more rows" {
+		t.Errorf("failures = %q", failures)
+	}
+}
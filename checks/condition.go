@@ -0,0 +1,182 @@
+package checks
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// operators are the comparisons of a condition, longest first so that <=
+// isn't read as <
+var operators = []string{"<=", ">=", "!=", "<>", "==", "=", "<", ">"}
+
+// timeLayouts are the forms a bound compared with a date or time may take
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02",
+}
+
+// Condition compares a value with a bound. Numbers compare as numbers,
+// dates and times as instants, and anything else as text; NULL meets no
+// condition but = null.
+type Condition struct {
+	Op    string // =, !=, <, <=, > or >=
+	Bound string // Without the quotes that make a number compare as text
+
+	number *float64
+	null   bool
+}
+
+// ParseCondition reads a condition such as ">= 1000", "!= null" or
+// "= 'active'". Without an operator it compares for equality.
+func ParseCondition(s string) (Condition, error) {
+	s = strings.TrimSpace(s)
+	c := Condition{Op: "="}
+	for _, op := range operators {
+		if strings.HasPrefix(s, op) {
+			c.Op, s = op, strings.TrimSpace(s[len(op):])
+			break
+		}
+	}
+	switch c.Op {
+	case "<>":
+		c.Op = "!="
+	case "==":
+		c.Op = "="
+	}
+	if s == "" {
+		return c, errors.New("no value to compare with")
+	}
+
+	if len(s) >= 2 && (s[0] == '\'' || s[0] == '"') && s[len(s)-1] == s[0] {
+		c.Bound = s[1 : len(s)-1]
+		return c, nil
+	}
+	c.Bound = s
+	if strings.EqualFold(s, "null") {
+		if c.Op != "=" && c.Op != "!=" {
+			return c, fmt.Errorf("null can only be compared with = or !=, not %s", c.Op)
+		}
+		c.null = true
+	} else if n, err := strconv.ParseFloat(s, 64); err == nil {
+		c.number = &n
+	}
+	return c, nil
+}
+
+func (c Condition) String() string {
+	return c.Op + " " + c.Bound
+}
+
+// Holds reports whether v meets the condition. It fails when v can't be
+// compared with the bound, such as text with a number.
+func (c Condition) Holds(v any) (bool, error) {
+	switch {
+	case c.null:
+		return (v == nil) == (c.Op == "="), nil
+	case v == nil:
+		return false, nil
+	}
+
+	if c.number != nil {
+		n, ok := toFloat(v)
+		if !ok {
+			return false, errors.New("it isn't a number")
+		}
+		return c.compare(cmpFloat(n, *c.number)), nil
+	}
+	if t, ok := v.(time.Time); ok {
+		for _, layout := range timeLayouts {
+			if bound, err := time.ParseInLocation(layout, c.Bound, t.Location()); err == nil {
+				return c.compare(t.Compare(bound)), nil
+			}
+		}
+		return false, fmt.Errorf("%q isn't a date or time", c.Bound)
+	}
+	return c.compare(strings.Compare(formatValue(v), c.Bound)), nil
+}
+
+// compare applies the operator to the outcome of comparing a value with
+// the bound: negative when it is smaller, zero when equal, positive when
+// larger
+func (c Condition) compare(cmp int) bool {
+	switch c.Op {
+	case "=":
+		return cmp == 0
+	case "!=":
+		return cmp != 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	default:
+		return cmp >= 0
+	}
+}
+
+// cmpFloat compares two numbers like strings.Compare
+func cmpFloat(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// toFloat converts a result value to a number. The driver returns DECIMAL
+// values as text, so text that reads as a number counts as one.
+func toFloat(v any) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int8:
+		return float64(n), true
+	case int16:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint8:
+		return float64(n), true
+	case uint16:
+		return float64(n), true
+	case uint32:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case float32:
+		return float64(n), true
+	case float64:
+		return n, true
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(n), 64)
+		return f, err == nil
+	case []byte:
+		f, err := strconv.ParseFloat(strings.TrimSpace(string(n)), 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// formatValue renders a result value for comparison as text and for
+// failure messages
+func formatValue(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "NULL"
+	case time.Time:
+		return v.Format("2006-01-02 15:04:05.999999999")
+	case []byte:
+		return string(v)
+	}
+	return fmt.Sprint(v)
+}
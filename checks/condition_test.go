@@ -0,0 +1,65 @@
+package checks
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCondition(t *testing.T) {
+	tests := []struct {
+		in, want string
+		wantErr  bool
+	}{
+		{in: "0", want: "= 0"},
+		{in: ">= 1000", want: ">= 1000"},
+		{in: "<>5", want: "!= 5"},
+		{in: "== 'active'", want: "= active"},
+		{in: "!= null", want: "!= null"},
+		{in: "< null", wantErr: true},
+		{in: ">=", wantErr: true},
+	}
+	for _, tt := range tests {
+		c, err := ParseCondition(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseCondition(%q) error = %v", tt.in, err)
+			continue
+		}
+		if err == nil && c.String() != tt.want {
+			t.Errorf("ParseCondition(%q) = %q, want %q", tt.in, c, tt.want)
+		}
+	}
+}
+
+func TestHolds(t *testing.T) {
+	day := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		cond    string
+		value   any
+		want    bool
+		wantErr bool
+	}{
+		{cond: "0", value: int64(0), want: true},
+		{cond: ">= 1000", value: int64(999), want: false},
+		{cond: "< 0.01", value: 0.005, want: true},
+		{cond: "< 0.01", value: "0.020", want: false}, // DECIMAL
+		{cond: "> 1", value: "many", wantErr: true},
+		{cond: "= active", value: "active", want: true},
+		{cond: "= '5'", value: int64(5), want: true},
+		{cond: "!= null", value: nil, want: false},
+		{cond: "= null", value: nil, want: true},
+		{cond: "!= 0", value: nil, want: false},
+		{cond: ">= 2026-10-01", value: day, want: true},
+		{cond: "< 2026-10-16 00:00:00", value: day, want: false},
+		{cond: "> yesterday", value: day, wantErr: true},
+	}
+	for _, tt := range tests {
+		c, err := ParseCondition(tt.cond)
+		if err != nil {
+			t.Fatalf("ParseCondition(%q): %v", tt.cond, err)
+		}
+		got, err := c.Holds(tt.value)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("%q.Holds(%v) = %v, %v; want %v", tt.cond, tt.value, got, err, tt.want)
+		}
+	}
+}
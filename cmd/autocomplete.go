@@ -0,0 +1,217 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/TFMV/trino-cli/autocomplete"
+	"github.com/TFMV/trino-cli/config"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+var (
+	autocompleteClearAll bool
+	autocompleteClearYes bool
+)
+
+// autocompleteCmd is the parent command for managing the autocomplete cache.
+var autocompleteCmd = &cobra.Command{
+	Use:   "autocomplete",
+	Short: "Manage the autocomplete cache",
+	Long: `The interactive shell completes catalogs, schemas, tables and columns from a
+cache of each profile's metadata, kept under ~/.trino-cli/autocomplete_cache and
+refreshed in the background. These commands refresh, clear and inspect it.`,
+}
+
+// autocompleteRefreshCmd re-reads every schema of a profile into its cache.
+var autocompleteRefreshCmd = &cobra.Command{
+	Use:   "refresh",
+	Short: "Re-read all schema metadata into the autocomplete cache",
+	Run: func(cmd *cobra.Command, args []string) {
+		log := logger.With(zap.String("command", "autocomplete refresh"), zap.String("profile", profile))
+		defer log.Sync()
+
+		if _, ok := config.AppConfig.Profiles[profile]; !ok {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", config.UnknownProfile(profile))
+			os.Exit(1)
+		}
+
+		if err := readPassword(profile); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		start := time.Now()
+		fmt.Printf("Refreshing autocomplete cache for profile %s...\n", profile)
+		if err := autocomplete.ForceRefreshSchema(cmd.Context(), autocompleteProfile(profile)); err != nil {
+			log.Error("Failed to refresh autocomplete cache", zap.Error(err))
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		status, err := autocomplete.ProfileCacheStatus(autocompleteProfile(profile))
+		if err != nil {
+			fmt.Printf("Refreshed in %s.\n", time.Since(start).Round(time.Millisecond))
+			return
+		}
+		fmt.Printf("Refreshed %d schemas, %d tables and %d columns in %s.\n",
+			status.Schemas, status.Tables, status.Columns, time.Since(start).Round(time.Millisecond))
+	},
+}
+
+// autocompleteClearCmd deletes the autocomplete cache of one or every profile.
+var autocompleteClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Delete the autocomplete cache",
+	Long: `Deletes the autocomplete cache of the selected profile, or with --all of every
+profile. The cache is rebuilt the next time the interactive shell starts.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		log := logger.With(zap.String("command", "autocomplete clear"))
+		defer log.Sync()
+
+		prompt := fmt.Sprintf("Delete the autocomplete cache of profile %s?", profile)
+		if autocompleteClearAll {
+			prompt = "Delete the autocomplete cache of every profile?"
+		}
+		if !autocompleteClearYes && !confirm(prompt) {
+			fmt.Println("Aborted.")
+			return
+		}
+
+		if !autocompleteClearAll {
+			freed, err := autocomplete.ClearProfileCache(autocompleteProfile(profile))
+			if err != nil {
+				log.Error("Failed to clear autocomplete cache", zap.String("profile", profile), zap.Error(err))
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Printf("Cleared autocomplete cache of profile %s (%s freed).\n", profile, formatBytes(freed))
+			return
+		}
+
+		root, err := autocomplete.CacheRootDir()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		freed, _, _ := storeUsage(localStore{Paths: []string{root}}, time.Time{})
+		if err := os.RemoveAll(root); err != nil {
+			log.Error("Failed to clear autocomplete caches", zap.Error(err))
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Cleared all autocomplete caches (%s freed).\n", formatBytes(freed))
+	},
+}
+
+// autocompleteStatusCmd reports the size and age of the autocomplete caches.
+var autocompleteStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the size and age of the autocomplete cache of each profile",
+	Long: `Shows, for every configured profile or only the one given with --profile,
+how many catalogs, schemas, tables and columns its autocomplete cache holds,
+its size on disk, and how long ago its schemas were last refreshed.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		profiles := []string{profile}
+		if !cmd.Flags().Changed("profile") {
+			profiles = profiles[:0]
+			for name := range config.AppConfig.Profiles {
+				profiles = append(profiles, name)
+			}
+			slices.Sort(profiles)
+		}
+
+		if jsonOutput {
+			// profileStatus is a profile's cache status, or why it couldn't
+			// be read
+			type profileStatus struct {
+				Profile string `json:"profile"`
+				*autocomplete.CacheStatus
+				Error string `json:"error,omitempty"`
+			}
+			statuses := make([]profileStatus, 0, len(profiles))
+			for _, name := range profiles {
+				s := profileStatus{Profile: name}
+				if status, err := autocomplete.ProfileCacheStatus(autocompleteProfile(name)); err != nil {
+					s.Error = err.Error()
+				} else {
+					s.CacheStatus = &status
+				}
+				statuses = append(statuses, s)
+			}
+			printJSON(statuses)
+			return
+		}
+
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader([]string{"Profile", "Catalogs", "Schemas", "Tables", "Columns", "Size", "Refreshed", "Oldest"})
+		table.SetBorder(false)
+		table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
+		table.SetAlignment(tablewriter.ALIGN_LEFT)
+		table.SetCenterSeparator("")
+		table.SetColumnSeparator("")
+		table.SetRowSeparator("")
+		table.SetHeaderLine(false)
+
+		for _, name := range profiles {
+			status, err := autocomplete.ProfileCacheStatus(autocompleteProfile(name))
+			switch {
+			case err != nil:
+				table.Append([]string{name, "error: " + err.Error(), "", "", "", "", "", ""})
+			case !status.Exists:
+				table.Append([]string{name, "-", "-", "-", "-", "-", "never", "-"})
+			default:
+				table.Append([]string{
+					name,
+					strconv.Itoa(status.Catalogs),
+					strconv.Itoa(status.Schemas),
+					strconv.Itoa(status.Tables),
+					strconv.Itoa(status.Columns),
+					formatBytes(status.Size),
+					formatCacheAge(status.Newest),
+					formatCacheAge(status.Oldest),
+				})
+			}
+		}
+		table.Render()
+	},
+}
+
+func init() {
+	autocompleteClearCmd.Flags().BoolVar(&autocompleteClearAll, "all", false, "Clear the cache of every profile")
+	autocompleteClearCmd.Flags().BoolVarP(&autocompleteClearYes, "yes", "y", false, "Skip the confirmation prompt")
+
+	autocompleteCmd.AddCommand(autocompleteRefreshCmd)
+	autocompleteCmd.AddCommand(autocompleteClearCmd)
+	autocompleteCmd.AddCommand(autocompleteStatusCmd)
+
+	rootCmd.AddCommand(autocompleteCmd)
+}
+
+// formatCacheAge renders how long ago a schema was refreshed
+func formatCacheAge(t time.Time) string {
+	if t.IsZero() {
+		return "never"
+	}
+	return time.Since(t).Round(time.Second).String() + " ago"
+}
+
+// autocompleteProfile describes the named profile to the autocomplete
+// engine: the server its metadata is read from and cached for, and the
+// catalogs and schemas the autocomplete section selects
+func autocompleteProfile(name string) autocomplete.Profile {
+	p := config.AppConfig.Profiles[name]
+	return autocomplete.Profile{
+		Name:    name,
+		DSN:     p.DSN(),
+		Server:  p.ServerKey(),
+		Catalog: p.Catalog,
+		Schema:  p.Schema,
+		Include: config.AppConfig.Autocomplete.Include,
+		Exclude: config.AppConfig.Autocomplete.Exclude,
+	}
+}
@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/TFMV/trino-cli/config"
+	"github.com/TFMV/trino-cli/engine"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// execFiles holds the files of -f, where "-" is standard input
+var execFiles []string
+
+// batchStatement is a statement of batch mode and where it was read from
+type batchStatement struct {
+	engine.Statement
+	source string
+}
+
+// batchStatements gathers the statements of a batch run: those of -e, then
+// those of each -f file in order, or those piped in on standard input when
+// neither is given. ok is false when there is nothing to run in batch mode,
+// for the interactive shell.
+func batchStatements() (statements []batchStatement, ok bool, err error) {
+	add := func(source, script string) {
+		for _, s := range engine.SplitStatements(script) {
+			statements = append(statements, batchStatement{Statement: s, source: source})
+		}
+	}
+
+	if execQuery != "" {
+		add("-e", execQuery)
+	}
+	for _, name := range execFiles {
+		var data []byte
+		if name == "-" {
+			data, err = io.ReadAll(os.Stdin)
+			name = "stdin"
+		} else {
+			data, err = os.ReadFile(name)
+		}
+		if err != nil {
+			return nil, false, err
+		}
+		add(name, string(data))
+	}
+	if execQuery != "" || len(execFiles) > 0 {
+		return statements, true, nil
+	}
+
+	if term.IsTerminal(int(os.Stdin.Fd())) {
+		return nil, false, nil
+	}
+	data, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return nil, false, err
+	}
+	add("stdin", string(data))
+	return statements, true, nil
+}
+
+// runBatch runs the statements in order and prints their results, paged
+// when they don't fit on the screen, stopping at the first that fails. A
+// lone statement may run on the daemon; several share one session, so that
+// a USE or SET SESSION holds for those after it.
+func runBatch(cmd *cobra.Command, statements []batchStatement) error {
+	format := outputFormat
+	switch {
+	case cmd.Flags().Changed("format"):
+	case jsonOutput:
+		format = "json"
+	default:
+		format = config.AppConfig.Profiles[profile].Defaults.Format
+	}
+	ctx := cmd.Context()
+
+	if len(statements) == 1 {
+		result, err := executeQuery(ctx, statements[0].SQL, profile)
+		if err != nil {
+			return statements[0].wrap(err)
+		}
+		return page(func(w io.Writer) error {
+			return printResult(w, result, format)
+		})
+	}
+
+	if err := readPassword(profile); err != nil {
+		return err
+	}
+	script, err := engine.NewScript(ctx, profile)
+	if err != nil {
+		return err
+	}
+	defer script.Close()
+
+	return page(func(w io.Writer) error {
+		for i, s := range statements {
+			result, err := script.Execute(ctx, s.SQL)
+			if err != nil {
+				return s.wrap(err)
+			}
+			if i > 0 && (format == "" || format == "table") {
+				fmt.Fprintln(w)
+			}
+			if err := printResult(w, result, format); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// wrap says which statement an error came from
+func (s batchStatement) wrap(err error) error {
+	return fmt.Errorf("%s, line %d: %w", s.source, s.Line, err)
+}
+
+// printResult writes a result of batch mode, noting on stderr when it was
+// cut off at the profile's row limit
+func printResult(w io.Writer, result *engine.QueryResult, format string) error {
+	if result.Truncated {
+		fmt.Fprintf(os.Stderr, "Showing the first %d rows (defaults.max_rows)\n", len(result.Rows))
+	}
+	return writeResult(w, result, format)
+}
@@ -0,0 +1,259 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"strings"
+	"time"
+
+	"github.com/TFMV/trino-cli/bundle"
+	"github.com/TFMV/trino-cli/engine"
+	"github.com/TFMV/trino-cli/history"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+	"golang.org/x/term"
+)
+
+// bundlePassphraseEnv names the environment variable consulted for the
+// bundle passphrase before prompting.
+const bundlePassphraseEnv = "TRINO_CLI_BUNDLE_PASSPHRASE"
+
+var (
+	bundleOutput     string
+	bundleNote       string
+	bundlePassphrase string
+	bundleNoPlan     bool
+	bundleShowPlan   bool
+	bundleYes        bool
+)
+
+// bundleCmd is the parent command for shareable result bundles.
+var bundleCmd = &cobra.Command{
+	Use:   "bundle",
+	Short: "Create and view shareable result bundles",
+	Long: `Bundles are encrypted archives containing a query, its result (Arrow), its plan,
+and metadata. They let colleagues view findings without access to the cluster.`,
+}
+
+// bundleCreateCmd creates a bundle from a history entry.
+var bundleCreateCmd = &cobra.Command{
+	Use:   "create <history_id>",
+	Short: "Create an encrypted bundle from a history entry",
+	Long: `Writes the query of a history entry, its result and its plan to an encrypted
+bundle. The result is the one cached for the entry when cache.auto captured
+it; otherwise the query runs again to capture one, which isn't recorded in the
+history. The passphrase is asked for first. A statement that changes data,
+such as INSERT or DELETE, is only run again after confirmation or with --yes.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		log := logger.With(zap.String("command", "bundle create"), zap.String("id", args[0]))
+		defer log.Sync()
+
+		entry, err := history.GetQueryByID(cmd.Context(), args[0])
+		if err != nil {
+			log.Error("Error retrieving query", zap.Error(err))
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		// Ask for the passphrase before running anything, so that a mistyped
+		// one doesn't waste a run
+		passphrase, err := readBundlePassphrase(true)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		// Prefer the result cached for the entry, which is the one it saw
+		var result *engine.QueryResult
+		if entry.CacheKey != "" {
+			result, err = loadCachedResult(entry.CacheKey)
+			if err != nil {
+				log.Info("Cached result unavailable", zap.String("key", entry.CacheKey), zap.Error(err))
+				result = nil
+			}
+		}
+
+		if result == nil && !engine.ReadOnly(entry.Query) && !bundleYes {
+			if !term.IsTerminal(int(os.Stdin.Fd())) {
+				fmt.Fprintln(os.Stderr, "Error: the query changes data and would run again; pass --yes to run it")
+				os.Exit(1)
+			}
+			if !confirm("The query changes data and runs again to capture its result. Run it?") {
+				fmt.Println("Aborted.")
+				return
+			}
+		}
+		if result == nil || !bundleNoPlan {
+			if err := readPassword(entry.Profile); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		if result == nil {
+			fmt.Fprintf(os.Stderr, "Running query from profile %s to capture its result...\n", entry.Profile)
+			result, err = engine.ExecuteQuery(engine.WithoutHistory(cmd.Context()), entry.Query, entry.Profile)
+			if err != nil {
+				log.Error("Error executing query", zap.Error(err))
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+		}
+
+		var plan string
+		if !bundleNoPlan {
+			if plan, err = engine.ExplainQuery(cmd.Context(), entry.Query, entry.Profile); err != nil {
+				// A missing plan should not prevent sharing the result
+				log.Warn("Failed to capture query plan", zap.Error(err))
+			}
+		}
+
+		b := &bundle.Bundle{
+			Metadata: bundle.Metadata{
+				HistoryID:  entry.ID,
+				Profile:    entry.Profile,
+				ExecutedAt: entry.Timestamp,
+				Duration:   entry.Duration,
+				Rows:       len(result.Rows),
+				Columns:    result.Columns,
+				CreatedAt:  time.Now().UTC(),
+				Note:       bundleNote,
+			},
+			Query:  entry.Query,
+			Plan:   plan,
+			Result: result,
+		}
+		if u, err := user.Current(); err == nil {
+			b.Metadata.CreatedBy = u.Username
+		}
+
+		output := bundleOutput
+		if output == "" {
+			output = entry.ID + ".tcb"
+		}
+		if err := bundle.Write(output, b, passphrase); err != nil {
+			log.Error("Error writing bundle", zap.Error(err))
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		log.Info("Bundle created", zap.String("file", output))
+		fmt.Printf("Bundle written to %s (%d rows).\n", output, len(result.Rows))
+	},
+}
+
+// bundleViewCmd displays the contents of a bundle.
+var bundleViewCmd = &cobra.Command{
+	Use:   "view <file>",
+	Short: "View the contents of a bundle",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		log := logger.With(zap.String("command", "bundle view"), zap.String("file", args[0]))
+		defer log.Sync()
+
+		passphrase, err := readBundlePassphrase(false)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		b, err := bundle.Read(args[0], passphrase)
+		if err != nil {
+			log.Error("Error reading bundle", zap.Error(err))
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		if jsonOutput {
+			printJSON(b)
+			return
+		}
+
+		m := b.Metadata
+		fmt.Printf("History ID:  %s\n", m.HistoryID)
+		fmt.Printf("Profile:     %s\n", m.Profile)
+		fmt.Printf("Executed at: %s\n", m.ExecutedAt.Local().Format(time.RFC1123))
+		fmt.Printf("Duration:    %s\n", formatDuration(m.Duration))
+		fmt.Printf("Rows:        %d\n", m.Rows)
+		fmt.Printf("Created at:  %s\n", m.CreatedAt.Local().Format(time.RFC1123))
+		if m.CreatedBy != "" {
+			fmt.Printf("Created by:  %s\n", m.CreatedBy)
+		}
+		if m.Note != "" {
+			fmt.Printf("Note:        %s\n", m.Note)
+		}
+
+		fmt.Printf("\nQuery:\n%s\n", strings.TrimSpace(b.Query))
+
+		if bundleShowPlan {
+			if b.Plan == "" {
+				fmt.Println("\nPlan: (not captured)")
+			} else {
+				fmt.Printf("\nPlan:\n%s\n", strings.TrimRight(b.Plan, "\n"))
+			}
+		}
+
+		fmt.Println()
+		if b.Result != nil {
+			displayQueryResult(b.Result)
+		} else {
+			fmt.Println("Bundle contains no result.")
+		}
+	},
+}
+
+func init() {
+	bundleCreateCmd.Flags().StringVar(&bundleOutput, "output", "", "Bundle file to write (default <history_id>.tcb)")
+	bundleCreateCmd.Flags().StringVar(&bundleNote, "note", "", "Note to include in the bundle metadata")
+	bundleCreateCmd.Flags().BoolVar(&bundleNoPlan, "no-plan", false, "Do not capture the query plan")
+	bundleCreateCmd.Flags().StringVar(&bundlePassphrase, "passphrase", "", "Passphrase to encrypt with (default $"+bundlePassphraseEnv+" or prompt)")
+	bundleCreateCmd.Flags().BoolVarP(&bundleYes, "yes", "y", false, "Run a query that changes data again without asking")
+
+	bundleViewCmd.Flags().BoolVar(&bundleShowPlan, "plan", false, "Show the captured query plan")
+	bundleViewCmd.Flags().StringVar(&bundlePassphrase, "passphrase", "", "Passphrase to decrypt with (default $"+bundlePassphraseEnv+" or prompt)")
+
+	bundleCmd.AddCommand(bundleCreateCmd)
+	bundleCmd.AddCommand(bundleViewCmd)
+
+	rootCmd.AddCommand(bundleCmd)
+}
+
+// readBundlePassphrase returns the passphrase from the flag, the environment,
+// or an interactive prompt. New bundles ask for the passphrase twice.
+func readBundlePassphrase(confirmNew bool) (string, error) {
+	if bundlePassphrase != "" {
+		return bundlePassphrase, nil
+	}
+	if p := os.Getenv(bundlePassphraseEnv); p != "" {
+		return p, nil
+	}
+
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return "", fmt.Errorf("no passphrase given; use --passphrase or $%s", bundlePassphraseEnv)
+	}
+
+	fmt.Fprint(os.Stderr, "Bundle passphrase: ")
+	p, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", err
+	}
+	if len(p) == 0 {
+		return "", fmt.Errorf("passphrase must not be empty")
+	}
+
+	if confirmNew {
+		fmt.Fprint(os.Stderr, "Confirm passphrase: ")
+		again, err := term.ReadPassword(fd)
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return "", err
+		}
+		if string(again) != string(p) {
+			return "", fmt.Errorf("passphrases do not match")
+		}
+	}
+	return string(p), nil
+}
@@ -1,10 +1,12 @@
 package cmd
 
 import (
+	"fmt"
 	"os"
 	"strings"
 
 	"github.com/TFMV/trino-cli/cache"
+	"github.com/TFMV/trino-cli/engine"
 	"github.com/spf13/cobra"
 	"go.uber.org/zap"
 )
@@ -26,10 +28,14 @@ var cacheListCmd = &cobra.Command{
 		entries, err := cache.ListCache()
 		if err != nil {
 			log.Error("Error listing cache", zap.Error(err))
-			os.Stderr.WriteString("[red]Error listing cache:[white] " + err.Error() + "\n")
-			return
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
 		}
 
+		if jsonOutput {
+			printJSON(jsonList(entries))
+			return
+		}
 		if len(entries) == 0 {
 			log.Info("No cached queries found")
 			os.Stdout.WriteString("[yellow]No cached queries found.[white]\n")
@@ -60,53 +66,34 @@ var cacheReplayCmd = &cobra.Command{
 		log := logger.With(zap.String("command", "cache replay"), zap.String("queryID", args[0]))
 
 		queryID := args[0]
-		pretty, _ := cmd.Flags().GetBool("pretty")
-		log.Info("Attempting to replay cached query", zap.Bool("pretty", pretty))
+		log.Info("Attempting to replay cached query")
 
-		resultStr, err := cache.ReplayCache(queryID)
+		result, err := loadCachedResult(queryID)
 		if err != nil {
 			log.Error("Error replaying cache", zap.Error(err))
-			os.Stderr.WriteString("[red]Error replaying cache:[white] " + err.Error() + "\n")
-			return
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
 		}
 
-		if resultStr == "" {
-			log.Warn("Cached result is empty")
-			os.Stdout.WriteString("[yellow]Cached result is empty.[white]\n")
-			return
-		}
-
-		// Print cached result with optional pretty print
 		log.Info("Displaying cached result")
-
-		var output strings.Builder
-		output.WriteString("[green]Cached Result:[white]\n")
-
-		if pretty {
-			// Apply some basic formatting for pretty output
-			output.WriteString(formatCachedResult(resultStr) + "\n")
-		} else {
-			output.WriteString(resultStr + "\n")
-		}
-
-		os.Stdout.WriteString(output.String())
+		displayQueryResult(result)
 	},
 }
 
-// formatCachedResult applies basic formatting to the cached result string
-func formatCachedResult(result string) string {
-	// Simple implementation - in a real app, you might want to parse and format more elegantly
-	lines := strings.Split(result, "\n")
-	for i, line := range lines {
-		if i == 0 {
-			lines[i] = "[green]" + line + "[white]"
-		}
+// loadCachedResult reads and decodes a cached query result
+func loadCachedResult(key string) (*engine.QueryResult, error) {
+	data, err := cache.Load(key)
+	if err != nil {
+		return nil, err
 	}
-	return strings.Join(lines, "\n")
+	return engine.ImportArrow(data)
 }
 
 func init() {
-	cacheReplayCmd.Flags().Bool("pretty", false, "Pretty-print cached results")
 	cacheCmd.AddCommand(cacheListCmd)
 	cacheCmd.AddCommand(cacheReplayCmd)
+
+	// Kept so scripts passing it still run
+	cacheReplayCmd.Flags().Bool("pretty", false, "Pretty-print cached results")
+	cacheReplayCmd.Flags().MarkDeprecated("pretty", "output is always formatted")
 }
@@ -0,0 +1,178 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/TFMV/trino-cli/checks"
+	"github.com/TFMV/trino-cli/engine"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+var checkFiles []string
+
+// Outcomes of a data quality check's assertion
+const (
+	assertPassed = "pass"
+	assertFailed = "fail"
+	assertError  = "error"
+)
+
+// checkResult is the outcome of running one check
+type checkResult struct {
+	File     string        `json:"file"`
+	Name     string        `json:"name"`
+	Status   string        `json:"status"` // pass, fail, or error when the query failed
+	Rows     int           `json:"rows"`
+	Duration time.Duration `json:"duration"`
+	Failures []string      `json:"failures,omitempty"` // The expectations the result didn't meet
+	Error    string        `json:"error,omitempty"`
+}
+
+// checkCmd runs data quality checks, for use as a gate in CI.
+var checkCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Run data quality checks and fail if any doesn't hold",
+	Long: `Runs the checks of the -f files against the --profile server and reports which
+hold, exiting with status 1 if any fails or its query can't run, so that it
+can gate a CI pipeline. Each check is a query with what its result must
+satisfy: a number of rows, or a condition every row's value must meet.
+
+  checks:
+    - name: no duplicate orders
+      query: SELECT order_id FROM orders GROUP BY order_id HAVING count(*) > 1
+      expect:
+        rows: 0
+    - name: orders loaded today
+      query: SELECT count(*) FROM orders WHERE order_date = current_date
+      expect:
+        value: ">= 1000"
+    - name: few customers without an email
+      query: SELECT avg(IF(email IS NULL, 1.0, 0)) AS missing FROM customers
+      expect:
+        column: missing
+        value: "< 0.01"
+
+Conditions compare with =, !=, <, <=, > or >=, and a bare value with =.
+Numbers compare as numbers, dates and times as such, and quoted or other
+values as text; null can be compared with = and != only. value applies to
+the first column unless column names another. The queries aren't recorded
+in the history.`,
+	Example: `  trino-cli check -f checks.yaml --profile prod
+  trino-cli check -f orders.yaml -f customers.yaml --json`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		results, err := runChecks(cmd)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		passed := 0
+		for _, r := range results {
+			if r.Status == assertPassed {
+				passed++
+			}
+		}
+		if jsonOutput {
+			printJSON(struct {
+				Checks []checkResult `json:"checks"`
+				Passed int           `json:"passed"`
+				Failed int           `json:"failed"`
+			}{results, passed, len(results) - passed})
+		} else {
+			displayCheckResults(results, passed)
+		}
+		if passed < len(results) {
+			os.Exit(1)
+		}
+	},
+}
+
+// runChecks loads the checks of every file, so that a mistake in one stops
+// the run before any query does, then runs them in order
+func runChecks(cmd *cobra.Command) ([]checkResult, error) {
+	if len(checkFiles) == 0 {
+		return nil, errors.New("give the checks to run with -f")
+	}
+	type fileChecks struct {
+		file   string
+		checks []checks.Check
+	}
+	var loaded []fileChecks
+	for _, file := range checkFiles {
+		c, err := checks.Load(file)
+		if err != nil {
+			return nil, err
+		}
+		loaded = append(loaded, fileChecks{file, c})
+	}
+	if err := chooseProfile(cmd, false); err != nil {
+		return nil, err
+	}
+	if err := readPassword(profile); err != nil {
+		return nil, err
+	}
+
+	ctx := engine.WithoutHistory(cmd.Context())
+	var results []checkResult
+	for _, f := range loaded {
+		for _, c := range f.checks {
+			if ctx.Err() != nil {
+				return results, ctx.Err()
+			}
+			r := checkResult{File: f.file, Name: c.Name, Status: assertPassed}
+			start := time.Now()
+			result, err := engine.ExecuteQuery(ctx, c.Query, profile)
+			r.Duration = time.Since(start)
+			switch {
+			case err != nil:
+				logger.Warn("Check query failed", zap.String("check", c.Name), zap.Error(err))
+				r.Status, r.Error = assertError, err.Error()
+			case result.Truncated:
+				r.Status = assertError
+				r.Error = fmt.Sprintf("the result has more than the profile's max_rows of %d rows; count or aggregate them in the query", len(result.Rows))
+			default:
+				r.Rows = len(result.Rows)
+				if r.Failures = c.Evaluate(result); len(r.Failures) > 0 {
+					r.Status = assertFailed
+				}
+			}
+			results = append(results, r)
+		}
+	}
+	return results, nil
+}
+
+// displayCheckResults prints each check's outcome, and why those that
+// didn't pass failed
+func displayCheckResults(results []checkResult, passed int) {
+	for _, r := range results {
+		fmt.Printf("%-5s  %s  (%s)\n", r.Status, r.Name, formatDuration(r.Duration))
+		for _, failure := range r.Failures {
+			fmt.Printf("       %s\n", failure)
+		}
+		if r.Error != "" {
+			fmt.Printf("       %s\n", r.Error)
+		}
+	}
+
+	fmt.Println()
+	switch failed := len(results) - passed; {
+	case failed == 0 && len(results) == 1:
+		fmt.Println("The check passed.")
+	case failed == 0:
+		fmt.Printf("All %d checks passed.\n", len(results))
+	default:
+		fmt.Printf("%d of %d checks failed.\n", failed, len(results))
+	}
+}
+
+func init() {
+	checkCmd.Flags().StringArrayVarP(&checkFiles, "file", "f", nil, "YAML file of checks to run; may be repeated")
+
+	rootCmd.AddCommand(checkCmd)
+}
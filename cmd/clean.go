@@ -0,0 +1,467 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/TFMV/trino-cli/history"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+var (
+	cleanOlderThan string
+	cleanWhat      string
+	cleanYes       bool
+)
+
+// localStore describes one category of data kept under ~/.trino-cli
+type localStore struct {
+	Name        string
+	Description string
+	Paths       []string // Directories, or single files
+	Kept        bool     // User data and credentials, reported but never deleted
+}
+
+// cleanCmd reports disk usage of local stores and deletes selected categories.
+var cleanCmd = &cobra.Command{
+	Use:   "clean",
+	Short: "Report and clean up local data in ~/.trino-cli",
+	Long: `Reports how much disk space each local store under ~/.trino-cli uses.
+With --what, deletes the selected categories (cache, history, logs) after
+confirmation. Use --older-than to only remove data older than a given age
+(e.g. 30d, 12h, 2w). An SQLite database is deleted together with its -wal and
+-shm files, and only once none of them changed since the cutoff.
+
+Snippets, bookmarks, the REPL's line history and credentials (login tokens and
+the vault key) are reported but never deleted.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		log := logger.With(zap.String("command", "clean"))
+		defer log.Sync()
+
+		stores, err := localStores()
+		if err != nil {
+			log.Error("Failed to locate local stores", zap.Error(err))
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		var cutoff time.Time
+		if cleanOlderThan != "" {
+			age, err := parseAge(cleanOlderThan)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			cutoff = time.Now().Add(-age)
+		}
+
+		// cleaned is what cleaning a store freed
+		type cleaned struct {
+			Name  string `json:"name"`
+			Freed int64  `json:"freed_bytes"`
+		}
+		// report is what clean found and freed, for --json
+		report := struct {
+			Stores  []storeReport `json:"stores"`
+			Cleaned []cleaned     `json:"cleaned"`
+		}{Stores: storeReports(stores, cutoff), Cleaned: []cleaned{}}
+		if cleanWhat == "" {
+			if jsonOutput {
+				printJSON(report)
+			} else {
+				displayStoreUsage(report.Stores, cutoff)
+			}
+			return
+		}
+		if !jsonOutput {
+			displayStoreUsage(report.Stores, cutoff)
+		}
+
+		selected, err := selectStores(stores, cleanWhat)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		names := make([]string, len(selected))
+		for i, s := range selected {
+			names[i] = s.Name
+		}
+		prompt := fmt.Sprintf("Delete %s data", strings.Join(names, ", "))
+		if !cutoff.IsZero() {
+			prompt += " older than " + cleanOlderThan
+		}
+		if !cleanYes && jsonOutput {
+			fmt.Fprintln(os.Stderr, "Error: --json needs --yes to delete, as it asks for no confirmation")
+			os.Exit(1)
+		}
+		if !cleanYes && !confirm(prompt+"?") {
+			fmt.Println("Aborted.")
+			return
+		}
+
+		failed := false
+		for _, store := range selected {
+			freed, err := cleanStore(cmd.Context(), store, cutoff)
+			if err != nil {
+				log.Error("Failed to clean store", zap.String("store", store.Name), zap.Error(err))
+				fmt.Fprintf(os.Stderr, "Error cleaning %s: %v\n", store.Name, err)
+				failed = true
+				continue
+			}
+			log.Info("Cleaned local store", zap.String("store", store.Name), zap.Int64("bytes", freed))
+			report.Cleaned = append(report.Cleaned, cleaned{Name: store.Name, Freed: freed})
+			if !jsonOutput {
+				fmt.Printf("Cleaned %s (%s freed).\n", store.Name, formatBytes(freed))
+			}
+		}
+		if jsonOutput {
+			printJSON(report)
+		}
+		if failed {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	cleanCmd.Flags().StringVar(&cleanOlderThan, "older-than", "", "Only remove data older than this age (e.g. 30d, 12h, 2w)")
+	cleanCmd.Flags().StringVar(&cleanWhat, "what", "", "Comma-separated categories to delete: cache, history, logs")
+	cleanCmd.Flags().BoolVarP(&cleanYes, "yes", "y", false, "Skip the confirmation prompt")
+
+	rootCmd.AddCommand(cleanCmd)
+}
+
+// localStores returns the known local stores under ~/.trino-cli
+func localStores() ([]localStore, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("unable to find home directory: %w", err)
+	}
+	base := filepath.Join(home, ".trino-cli")
+
+	return []localStore{
+		{
+			Name:        "cache",
+			Description: "Result and autocomplete caches",
+			Paths:       []string{filepath.Join(base, "cache"), filepath.Join(base, "autocomplete_cache")},
+		},
+		{
+			Name:        "history",
+			Description: "Query history database",
+			Paths:       []string{filepath.Join(base, "history")},
+		},
+		{
+			Name:        "logs",
+			Description: "Log files",
+			Paths:       []string{filepath.Join(base, "logs")},
+		},
+		{
+			Name:        "snippets",
+			Description: "Saved snippets",
+			Paths:       []string{filepath.Join(base, "snippets")},
+			Kept:        true,
+		},
+		{
+			Name:        "bookmarks",
+			Description: "Bookmarked tables",
+			Paths:       []string{filepath.Join(base, "bookmarks")},
+			Kept:        true,
+		},
+		{
+			Name:        "repl",
+			Description: "REPL line history",
+			Paths:       []string{filepath.Join(base, "repl_history")},
+			Kept:        true,
+		},
+		{
+			Name:        "credentials",
+			Description: "Login tokens, vault key",
+			Paths:       []string{filepath.Join(base, "tokens"), filepath.Join(base, "vault.key")},
+			Kept:        true,
+		},
+	}, nil
+}
+
+// selectStores picks the stores named in a comma-separated list
+func selectStores(stores []localStore, what string) ([]localStore, error) {
+	byName := make(map[string]localStore, len(stores))
+	var valid []string
+	for _, s := range stores {
+		byName[s.Name] = s
+		if !s.Kept {
+			valid = append(valid, s.Name)
+		}
+	}
+
+	var selected []localStore
+	seen := make(map[string]bool)
+	for _, name := range strings.Split(what, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" || seen[name] {
+			continue
+		}
+		store, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown category %q (valid: %s)", name, strings.Join(valid, ", "))
+		}
+		if store.Kept {
+			return nil, fmt.Errorf("clean keeps %s (valid: %s)", name, strings.Join(valid, ", "))
+		}
+		seen[name] = true
+		selected = append(selected, store)
+	}
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("no categories selected")
+	}
+	return selected, nil
+}
+
+// sqliteCompanions are the suffixes of the files SQLite keeps next to a
+// database while it is in use
+var sqliteCompanions = []string{"-wal", "-shm", "-journal"}
+
+// storeItem is a file of a store along with the files that belong to it: an
+// SQLite database and its -wal and -shm files are one item, since deleting
+// some of them without the others can corrupt the database
+type storeItem struct {
+	Paths    []string // The main file first
+	Size     int64
+	Modified time.Time // The last change to any of its files
+}
+
+// storeItems lists the items under a store's paths. Paths that don't exist
+// are skipped.
+func storeItems(store localStore) ([]storeItem, error) {
+	var items []*storeItem
+	byMain := make(map[string]*storeItem)
+	for _, root := range store.Paths {
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if os.IsNotExist(err) {
+					return nil
+				}
+				return err
+			}
+			if d.IsDir() {
+				return nil
+			}
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+
+			main := path
+			for _, suffix := range sqliteCompanions {
+				if strings.HasSuffix(path, suffix) {
+					main = strings.TrimSuffix(path, suffix)
+					break
+				}
+			}
+			item := byMain[main]
+			if item == nil {
+				item = &storeItem{}
+				byMain[main] = item
+				items = append(items, item)
+			}
+			if path == main {
+				item.Paths = append([]string{path}, item.Paths...)
+			} else {
+				item.Paths = append(item.Paths, path)
+			}
+			item.Size += info.Size()
+			if info.ModTime().After(item.Modified) {
+				item.Modified = info.ModTime()
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	result := make([]storeItem, len(items))
+	for i, item := range items {
+		result[i] = *item
+	}
+	return result, nil
+}
+
+// storeUsage returns the total size and file count of a store, and the size
+// of items last modified before cutoff (all of them if cutoff is zero).
+func storeUsage(store localStore, cutoff time.Time) (total int64, files int, reclaimable int64) {
+	items, err := storeItems(store)
+	if err != nil {
+		logger.Warn("Failed to measure local store", zap.String("store", store.Name), zap.Error(err))
+	}
+	for _, item := range items {
+		total += item.Size
+		files += len(item.Paths)
+		if cutoff.IsZero() || item.Modified.Before(cutoff) {
+			reclaimable += item.Size
+		}
+	}
+	return total, files, reclaimable
+}
+
+// storeReport is the disk usage of a store
+type storeReport struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Files       int    `json:"files"`
+	Size        int64  `json:"size_bytes"`
+	Reclaimable int64  `json:"reclaimable_bytes"` // Older than the cutoff, or all of it without one
+	Kept        bool   `json:"kept"`              // Never deleted by clean
+}
+
+// storeReports measures the disk usage of each store
+func storeReports(stores []localStore, cutoff time.Time) []storeReport {
+	reports := make([]storeReport, len(stores))
+	for i, store := range stores {
+		total, files, reclaimable := storeUsage(store, cutoff)
+		if store.Kept {
+			reclaimable = 0
+		}
+		reports[i] = storeReport{Name: store.Name, Description: store.Description, Files: files, Size: total, Reclaimable: reclaimable, Kept: store.Kept}
+	}
+	return reports
+}
+
+// displayStoreUsage prints a table with the disk usage of each store
+func displayStoreUsage(stores []storeReport, cutoff time.Time) {
+	table := tablewriter.NewWriter(os.Stdout)
+	header := []string{"Category", "Files", "Size", "Description"}
+	if !cutoff.IsZero() {
+		header = []string{"Category", "Files", "Size", "Older Than Cutoff", "Description"}
+	}
+	table.SetHeader(header)
+	table.SetBorder(false)
+	table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetCenterSeparator("")
+	table.SetColumnSeparator("")
+	table.SetRowSeparator("")
+	table.SetHeaderLine(false)
+	table.SetAutoWrapText(false)
+
+	var grandTotal int64
+	for _, store := range stores {
+		grandTotal += store.Size
+		description := store.Description
+		reclaimable := formatBytes(store.Reclaimable)
+		if store.Kept {
+			description += " (kept)"
+			reclaimable = "-"
+		}
+		row := []string{store.Name, strconv.Itoa(store.Files), formatBytes(store.Size), description}
+		if !cutoff.IsZero() {
+			row = []string{store.Name, strconv.Itoa(store.Files), formatBytes(store.Size), reclaimable, description}
+		}
+		table.Append(row)
+	}
+	table.Render()
+	fmt.Printf("\nTotal: %s\n", formatBytes(grandTotal))
+}
+
+// cleanStore deletes the data of a store and returns the number of bytes freed.
+// History is pruned through the history database rather than by removing the
+// file, since the database is held open by this process.
+func cleanStore(ctx context.Context, store localStore, cutoff time.Time) (int64, error) {
+	if store.Name == "history" {
+		before, _, _ := storeUsage(store, time.Time{})
+		count, err := history.ClearHistory(ctx, cutoff)
+		if err != nil {
+			return 0, err
+		}
+		if err := history.Vacuum(ctx); err != nil {
+			return 0, err
+		}
+		after, _, _ := storeUsage(store, time.Time{})
+		logger.Info("Pruned history entries", zap.Int64("count", count))
+		if after > before {
+			return 0, nil
+		}
+		return before - after, nil
+	}
+
+	items, err := storeItems(store)
+	if err != nil {
+		return 0, err
+	}
+
+	var freed int64
+	for _, item := range items {
+		if !cutoff.IsZero() && !item.Modified.Before(cutoff) {
+			continue
+		}
+		// The database goes first: its -wal file alone can't be applied
+		for _, path := range item.Paths {
+			if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+				return freed, err
+			}
+		}
+		freed += item.Size
+	}
+	return freed, nil
+}
+
+// parseAge parses an age such as "30d", "2w", or any time.ParseDuration value
+func parseAge(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, fmt.Errorf("empty age")
+	}
+
+	unit := s[len(s)-1]
+	if unit == 'd' || unit == 'w' {
+		n, err := strconv.Atoi(s[:len(s)-1])
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid age %q", s)
+		}
+		days := n
+		if unit == 'w' {
+			days = n * 7
+		}
+		return time.Duration(days) * 24 * time.Hour, nil
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid age %q", s)
+	}
+	return d, nil
+}
+
+// formatBytes renders a byte count in a human-readable form
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// confirm asks a yes/no question on stdin and reports whether the answer was yes
+func confirm(prompt string) bool {
+	fmt.Printf("%s [y/N]: ", prompt)
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return false
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
@@ -0,0 +1,220 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestParseAge(t *testing.T) {
+	tests := []struct {
+		age     string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"30d", 30 * 24 * time.Hour, false},
+		{"2w", 14 * 24 * time.Hour, false},
+		{" 1d ", 24 * time.Hour, false},
+		{"0d", 0, false},
+		{"12h", 12 * time.Hour, false},
+		{"90m", 90 * time.Minute, false},
+		{"1h30m", 90 * time.Minute, false},
+		{"", 0, true},
+		{"d", 0, true},
+		{"1.5d", 0, true},
+		{"-1d", 0, true},
+		{"-5h", 0, true},
+		{"30", 0, true},
+		{"soon", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.age, func(t *testing.T) {
+			got, err := parseAge(tt.age)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseAge(%q) error = %v, want error %v", tt.age, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseAge(%q) = %v, want %v", tt.age, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSelectStores(t *testing.T) {
+	stores := []localStore{{Name: "cache"}, {Name: "history"}, {Name: "logs"}, {Name: "snippets", Kept: true}}
+	tests := []struct {
+		what    string
+		want    []string
+		wantErr bool
+	}{
+		{"cache", []string{"cache"}, false},
+		{"Cache, logs", []string{"cache", "logs"}, false},
+		{"logs,history", []string{"logs", "history"}, false},
+		{"logs,logs", []string{"logs"}, false},
+		{"cache,,history", []string{"cache", "history"}, false},
+		{"", nil, true},
+		{" , ", nil, true},
+		{"tmp", nil, true},
+		{"cache,tmp", nil, true},
+		{"snippets", nil, true},
+		{"logs,snippets", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.what, func(t *testing.T) {
+			selected, err := selectStores(stores, tt.what)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("selectStores(%q) error = %v, want error %v", tt.what, err, tt.wantErr)
+			}
+			var got []string
+			for _, s := range selected {
+				got = append(got, s.Name)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("selectStores(%q) = %q, want %q", tt.what, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCleanStore(t *testing.T) {
+	now := time.Now()
+	// files are the store's files with their sizes and ages
+	files := []struct {
+		path string
+		size int
+		age  time.Duration
+	}{
+		{"results/old.arrow", 100, 40 * 24 * time.Hour},
+		{"results/new.arrow", 200, time.Hour},
+		{"completion/profile.json", 50, 10 * 24 * time.Hour},
+	}
+	tests := []struct {
+		name      string
+		olderThan time.Duration // Zero for no cutoff
+		freed     int64
+		left      []string
+	}{
+		{"everything", 0, 350, nil},
+		{"older than 30 days", 30 * 24 * time.Hour, 100, []string{"completion/profile.json", "results/new.arrow"}},
+		{"older than 2 days", 2 * 24 * time.Hour, 150, []string{"results/new.arrow"}},
+		{"older than a year", 365 * 24 * time.Hour, 0, []string{"completion/profile.json", "results/new.arrow", "results/old.arrow"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for _, f := range files {
+				path := filepath.Join(dir, f.path)
+				if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, make([]byte, f.size), 0644); err != nil {
+					t.Fatal(err)
+				}
+				modified := now.Add(-f.age)
+				if err := os.Chtimes(path, modified, modified); err != nil {
+					t.Fatal(err)
+				}
+			}
+			// A store's directories need not exist yet
+			store := localStore{Name: "cache", Paths: []string{dir, filepath.Join(dir, "missing")}}
+
+			var cutoff time.Time
+			if tt.olderThan > 0 {
+				cutoff = now.Add(-tt.olderThan)
+			}
+			freed, err := cleanStore(context.Background(), store, cutoff)
+			if err != nil {
+				t.Fatalf("cleanStore: %v", err)
+			}
+			if freed != tt.freed {
+				t.Errorf("freed %d bytes, want %d", freed, tt.freed)
+			}
+
+			var left []string
+			filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+				if err == nil && !d.IsDir() {
+					rel, _ := filepath.Rel(dir, path)
+					left = append(left, filepath.ToSlash(rel))
+				}
+				return nil
+			})
+			if !slices.Equal(left, tt.left) {
+				t.Errorf("left %q, want %q", left, tt.left)
+			}
+			if total, _, _ := storeUsage(store, time.Time{}); total != 350-tt.freed {
+				t.Errorf("storeUsage after cleaning = %d bytes, want %d", total, 350-tt.freed)
+			}
+		})
+	}
+}
+
+func TestCleanStoreKeepsDatabasesWhole(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name    string
+		walAge  time.Duration // Age of the -wal file; the database and -shm are 40 days old
+		freed   int64
+		deleted bool
+	}{
+		{"all files old", 35 * 24 * time.Hour, 1110, true},
+		{"recent -wal", time.Hour, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			files := map[string]struct {
+				size int
+				age  time.Duration
+			}{
+				"schema_cache.db":     {1000, 40 * 24 * time.Hour},
+				"schema_cache.db-wal": {100, tt.walAge},
+				"schema_cache.db-shm": {10, 40 * 24 * time.Hour},
+			}
+			for name, f := range files {
+				path := filepath.Join(dir, name)
+				if err := os.WriteFile(path, make([]byte, f.size), 0644); err != nil {
+					t.Fatal(err)
+				}
+				modified := now.Add(-f.age)
+				if err := os.Chtimes(path, modified, modified); err != nil {
+					t.Fatal(err)
+				}
+			}
+			store := localStore{Name: "cache", Paths: []string{dir}}
+
+			cutoff := now.Add(-30 * 24 * time.Hour)
+			if _, files, reclaimable := storeUsage(store, cutoff); files != 3 || reclaimable != tt.freed {
+				t.Errorf("storeUsage = %d files, %d reclaimable bytes, want 3 files, %d bytes", files, reclaimable, tt.freed)
+			}
+			freed, err := cleanStore(context.Background(), store, cutoff)
+			if err != nil {
+				t.Fatalf("cleanStore: %v", err)
+			}
+			if freed != tt.freed {
+				t.Errorf("freed %d bytes, want %d", freed, tt.freed)
+			}
+			for name := range files {
+				_, err := os.Stat(filepath.Join(dir, name))
+				if os.IsNotExist(err) != tt.deleted {
+					t.Errorf("%s deleted = %v, want %v", name, os.IsNotExist(err), tt.deleted)
+				}
+			}
+		})
+	}
+}
+
+func TestStoreUsageOfFiles(t *testing.T) {
+	dir := t.TempDir()
+	key := filepath.Join(dir, "vault.key")
+	if err := os.WriteFile(key, make([]byte, 32), 0600); err != nil {
+		t.Fatal(err)
+	}
+	// A store's paths may name single files, which need not exist
+	store := localStore{Name: "credentials", Paths: []string{key, filepath.Join(dir, "tokens")}, Kept: true}
+	if total, files, _ := storeUsage(store, time.Time{}); total != 32 || files != 1 {
+		t.Errorf("storeUsage = %d bytes in %d files, want 32 bytes in 1 file", total, files)
+	}
+}
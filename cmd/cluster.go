@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/TFMV/trino-cli/engine"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+var clusterFormat string
+
+// clusterCmd is the parent command for inspecting the cluster.
+var clusterCmd = &cobra.Command{
+	Use:   "cluster",
+	Short: "Inspect the Trino cluster",
+}
+
+// clusterStatusCmd shows the cluster's nodes, query counts and memory.
+var clusterStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the cluster's nodes, query counts and memory usage",
+	Long: `Shows the nodes of the --profile cluster and their state, how many queries are
+running, queued and blocked waiting for resources, and the usage of each node's
+memory pool. Nodes and queries come from system.runtime; memory pools are only
+exposed through JMX, so they are shown when the cluster has a catalog named jmx.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if clusterFormat != "table" && clusterFormat != "json" {
+			fmt.Fprintf(os.Stderr, "Error: unsupported format %q (use table or json)\n", clusterFormat)
+			os.Exit(1)
+		}
+		if err := readPassword(profile); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		status, err := engine.GetClusterStatus(cmd.Context(), profile)
+		if err != nil {
+			logger.Error("Error reading cluster status", zap.Error(err))
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		if clusterFormat == "json" || jsonOutput {
+			printJSON(status)
+			return
+		}
+
+		displayClusterStatus(status)
+	},
+}
+
+func displayClusterStatus(status *engine.ClusterStatus) {
+	fmt.Printf("Nodes: %d\n", len(status.Nodes))
+	table := newStatsTable([]string{"Node", "URI", "Version", "Role", "State"})
+	for _, n := range status.Nodes {
+		role := "worker"
+		if n.Coordinator {
+			role = "coordinator"
+		}
+		table.Append([]string{n.ID, n.URI, n.Version, role, n.State})
+	}
+	table.Render()
+
+	fmt.Printf("\nQueries: %d running, %d queued, %d blocked\n",
+		status.Queries.Running, status.Queries.Queued, status.Queries.Blocked)
+
+	if status.Memory == nil {
+		fmt.Println("\nMemory: unavailable (add a catalog named jmx using the jmx connector)")
+		return
+	}
+	fmt.Println("\nMemory:")
+	table = newStatsTable([]string{"Node", "Reserved", "Free", "Max", "Used"})
+	for _, p := range status.Memory {
+		used := "-"
+		if p.Max > 0 {
+			used = strconv.FormatFloat(float64(p.Reserved)/float64(p.Max)*100, 'f', 1, 64) + "%"
+		}
+		table.Append([]string{p.Node, formatBytes(p.Reserved), formatBytes(p.Free), formatBytes(p.Max), used})
+	}
+	table.Render()
+}
+
+func init() {
+	clusterStatusCmd.Flags().StringVar(&clusterFormat, "format", "table", "Output format (table, json)")
+
+	clusterCmd.AddCommand(clusterStatusCmd)
+
+	rootCmd.AddCommand(clusterCmd)
+}
@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/TFMV/trino-cli/autocomplete"
+	"github.com/TFMV/trino-cli/config"
+	"github.com/TFMV/trino-cli/ui"
+	"github.com/spf13/cobra"
+)
+
+// completing reports whether this run answers a shell's completion request,
+// which needs the config file and nothing else set up
+func completing() bool {
+	return len(os.Args) > 1 && (os.Args[1] == cobra.ShellCompRequestCmd || os.Args[1] == cobra.ShellCompNoDescRequestCmd)
+}
+
+// completeProfiles completes profile names from the config file, described
+// by where they connect
+func completeProfiles(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	var completions []string
+	for _, name := range ui.ProfileNames() {
+		if strings.HasPrefix(name, toComplete) {
+			p := config.AppConfig.Profiles[name]
+			completions = append(completions, fmt.Sprintf("%s\t%s@%s:%d", name, p.User, p.Host, p.Port))
+		}
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeProfileArg completes the profile argument of a command taking one
+func completeProfileArg(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return completeProfiles(cmd, args, toComplete)
+}
+
+// completeObjectName returns a completion function for the first argument
+// of a command taking a dotted object name, such as catalog.schema for
+// autocomplete.SchemaDepth or catalog.schema.table for
+// autocomplete.TableDepth. Names come from the profile's autocomplete
+// cache, so nothing is completed before it has been filled.
+func completeObjectName(depth int) cobra.CompletionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return completeCachedName(cmd, toComplete, depth)
+	}
+}
+
+// completeCatalogFlag completes a --catalog flag from the autocomplete cache
+func completeCatalogFlag(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return completeCachedName(cmd, toComplete, autocomplete.CatalogDepth)
+}
+
+// completeSchemaFlag completes a --schema flag from the autocomplete cache,
+// with the schemas of the command's --catalog when it is given
+func completeSchemaFlag(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	catalog, _ := cmd.Flags().GetString("catalog")
+	if catalog == "" {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	names, directive := completeCachedName(cmd, catalog+"."+toComplete, autocomplete.SchemaDepth)
+	for i, name := range names {
+		names[i] = strings.TrimPrefix(name, catalog+".")
+	}
+	return names, directive
+}
+
+// completeCachedName completes a dotted object name from the autocomplete
+// cache of the profile the command would use, leaving the shell to add no
+// space after a name the next part follows
+func completeCachedName(cmd *cobra.Command, toComplete string, depth int) ([]string, cobra.ShellCompDirective) {
+	name := profile
+	if names := ui.ProfileNames(); len(names) == 1 && !cmd.Flags().Changed("profile") && os.Getenv("TRINO_CLI_PROFILE") == "" {
+		name = names[0]
+	}
+	names, err := autocomplete.CompleteCachedName(autocompleteProfile(name), toComplete, depth)
+	if err != nil {
+		cobra.CompDebugln(err.Error(), true)
+		return nil, cobra.ShellCompDirectiveError
+	}
+	directive := cobra.ShellCompDirectiveNoFileComp
+	for _, n := range names {
+		if strings.HasSuffix(n, ".") {
+			directive |= cobra.ShellCompDirectiveNoSpace
+			break
+		}
+	}
+	return names, directive
+}
@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/TFMV/trino-cli/config"
+	"github.com/TFMV/trino-cli/keychain"
+	"github.com/spf13/cobra"
+)
+
+var (
+	keygenKeychain bool
+	keygenForce    bool
+)
+
+// configCmd is the parent command for working with the config file.
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Encrypt values for the config file",
+	Long: `Values tagged !vault in the config file are encrypted, so a config holding
+passwords or tokens can be kept in a dotfiles repository. They are decrypted on
+load with the vault key, read from $` + config.VaultKeyEnv + `, ~/.trino-cli/vault.key
+or the OS keychain.`,
+}
+
+// configKeygenCmd creates the vault key.
+var configKeygenCmd = &cobra.Command{
+	Use:   "keygen",
+	Short: "Create the vault key that encrypts config values",
+	Long: `Creates a random vault key and saves it to ~/.trino-cli/vault.key, or to the OS
+keychain with --keychain. Copy it to the other machines that read the config, or
+set $` + config.VaultKeyEnv + ` to it; values encrypted under a lost key can't be
+recovered.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if _, err := config.LoadVaultKey(); err == nil && !keygenForce {
+			fmt.Fprintln(os.Stderr, "Error: a vault key already exists; values encrypted under it would become unreadable (use --force to replace it)")
+			os.Exit(1)
+		}
+		key, err := config.NewVaultKey()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		encoded := base64.StdEncoding.EncodeToString(key)
+
+		if keygenKeychain {
+			if err := keychain.Set(config.VaultKeychainName, encoded); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Println("Stored a new vault key in the keychain.")
+			return
+		}
+		path, err := config.VaultKeyPath()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if err := os.WriteFile(path, []byte(encoded+"\n"), 0600); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Wrote a new vault key to %s.\n", path)
+	},
+}
+
+// configEncryptCmd encrypts a value for the config file.
+var configEncryptCmd = &cobra.Command{
+	Use:   "encrypt",
+	Short: "Encrypt a value to paste into the config file",
+	Long: `Encrypts a value under the vault key and prints it tagged !vault, ready to use
+as the value of a key such as password or token. The value is asked for at the
+terminal, or read from standard input when piped in.`,
+	Example: `  trino-cli config encrypt
+  echo "$TOKEN" | trino-cli config encrypt`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		key, err := config.LoadVaultKey()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		value, err := readSecret("Value to encrypt: ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		encrypted, err := config.Encrypt(key, value)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("!vault %s\n", encrypted)
+	},
+}
+
+func init() {
+	configKeygenCmd.Flags().BoolVar(&keygenKeychain, "keychain", false, "Store the key in the OS keychain instead of ~/.trino-cli/vault.key")
+	configKeygenCmd.Flags().BoolVar(&keygenForce, "force", false, "Replace an existing vault key")
+
+	configCmd.AddCommand(configKeygenCmd)
+	configCmd.AddCommand(configEncryptCmd)
+
+	rootCmd.AddCommand(configCmd)
+}
@@ -0,0 +1,191 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/TFMV/trino-cli/auth"
+	"github.com/TFMV/trino-cli/config"
+	"github.com/TFMV/trino-cli/keychain"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+	"golang.org/x/term"
+)
+
+var credentialToken bool
+
+// credentialCmd is the parent command for secrets kept in the OS keychain.
+var credentialCmd = &cobra.Command{
+	Use:   "credential",
+	Short: "Store profile passwords and tokens in the OS keychain",
+	Long: `Keeps a profile's password, or its OAuth2 access token with --token, in the macOS
+Keychain, the Secret Service (through libsecret's secret-tool) or the Windows
+Credential Manager. Profiles with "keychain: true" read them from there when
+connecting, and trino-cli login saves their tokens there too.`,
+}
+
+// credentialSetCmd stores a secret for a profile.
+var credentialSetCmd = &cobra.Command{
+	Use:   "set <profile>",
+	Short: "Store a profile's password (or token) in the keychain",
+	Long: `Stores a profile's password, or its access token with --token. The secret is
+asked for at the terminal, or read from standard input when piped in.`,
+	Example: `  trino-cli credential set prod
+  echo "$TOKEN" | trino-cli credential set prod --token`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeProfileArg,
+	Run: func(cmd *cobra.Command, args []string) {
+		name := args[0]
+		if _, ok := config.AppConfig.Profiles[name]; !ok {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", config.UnknownProfile(name))
+			os.Exit(1)
+		}
+		kind := "Password"
+		if credentialToken {
+			kind = "Token"
+		}
+		secret, err := readSecret(fmt.Sprintf("%s for %s: ", kind, name))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if credentialToken {
+			data, err := json.Marshal(auth.NewToken(secret))
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			secret = string(data)
+		}
+		if err := keychain.Set(credentialName(name, credentialToken), secret); err != nil {
+			logger.Error("Failed to store credential", zap.String("profile", name), zap.Error(err))
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Stored the %s of %s in the keychain.\n", strings.ToLower(kind), name)
+		if p := config.AppConfig.Profiles[name]; !p.Keychain {
+			fmt.Printf("Set keychain: true in profile %s for connections to use it.\n", name)
+		}
+	},
+}
+
+// credentialGetCmd prints a profile's stored secret.
+var credentialGetCmd = &cobra.Command{
+	Use:               "get <profile>",
+	Short:             "Print a profile's password (or token) from the keychain",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeProfileArg,
+	Run: func(cmd *cobra.Command, args []string) {
+		secret, err := keychain.Get(credentialName(args[0], credentialToken))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if credentialToken {
+			var token auth.Token
+			if err := json.Unmarshal([]byte(secret), &token); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: stored token is not valid: %v\n", err)
+				os.Exit(1)
+			}
+			secret = token.AccessToken
+		}
+		fmt.Println(secret)
+	},
+}
+
+// credentialDeleteCmd removes a profile's stored secret.
+var credentialDeleteCmd = &cobra.Command{
+	Use:               "delete <profile>",
+	Short:             "Remove a profile's password (or token) from the keychain",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeProfileArg,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := keychain.Delete(credentialName(args[0], credentialToken)); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println("Removed from the keychain.")
+	},
+}
+
+func init() {
+	for _, c := range []*cobra.Command{credentialSetCmd, credentialGetCmd, credentialDeleteCmd} {
+		c.Flags().BoolVar(&credentialToken, "token", false, "The profile's OAuth2 access token rather than its password")
+		credentialCmd.AddCommand(c)
+	}
+
+	rootCmd.AddCommand(credentialCmd)
+}
+
+// credentialName names a profile's password or token in the keychain
+func credentialName(profileName string, token bool) string {
+	if token {
+		return profileName + "/token"
+	}
+	return profileName + "/password"
+}
+
+// readSecret asks for a secret at the terminal, or reads it from piped input
+func readSecret(prompt string) (string, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return "", fmt.Errorf("failed to read secret from stdin: %w", err)
+		}
+		if secret := strings.TrimRight(string(data), "\r\n"); secret != "" {
+			return secret, nil
+		}
+		return "", fmt.Errorf("no secret given on stdin")
+	}
+	fmt.Fprint(os.Stderr, prompt)
+	secret, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", err
+	}
+	if len(secret) == 0 {
+		return "", fmt.Errorf("secret must not be empty")
+	}
+	return string(secret), nil
+}
+
+// loadKeychainSecrets reads the password and token of each profile that
+// keeps them in the keychain. A token cached there takes the place of one
+// cached on disk.
+func loadKeychainSecrets() {
+	for name, p := range config.AppConfig.Profiles {
+		if !p.Keychain {
+			continue
+		}
+		if p.Password == "" {
+			password, err := keychain.Get(credentialName(name, false))
+			switch {
+			case err == nil:
+				config.SetPassword(name, password)
+			case !errors.Is(err, keychain.ErrNotFound):
+				logger.Warn("Failed to read password from the keychain", zap.String("profile", name), zap.Error(err))
+			}
+		}
+
+		data, err := keychain.Get(credentialName(name, true))
+		if err != nil {
+			if !errors.Is(err, keychain.ErrNotFound) {
+				logger.Warn("Failed to read token from the keychain", zap.String("profile", name), zap.Error(err))
+			}
+			continue
+		}
+		var token auth.Token
+		if err := json.Unmarshal([]byte(data), &token); err != nil || token.AccessToken == "" {
+			logger.Warn("Ignoring invalid token in the keychain", zap.String("profile", name))
+			continue
+		}
+		if !token.Expired() {
+			config.SetAccessToken(name, token.AccessToken)
+		}
+	}
+}
@@ -0,0 +1,205 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/TFMV/trino-cli/daemon"
+	"github.com/TFMV/trino-cli/engine"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+var (
+	daemonForeground bool
+	noDaemon         bool
+)
+
+// daemonCmd is the parent command for the background daemon.
+var daemonCmd = &cobra.Command{
+	Use:   "daemon",
+	Short: "Manage the background daemon",
+	Long: `The daemon keeps authenticated connections and the metadata cache warm.
+While it is running, batch commands send their queries to it over a unix
+socket, avoiding per-command startup and authentication latency.`,
+}
+
+// daemonStartCmd starts the daemon.
+var daemonStartCmd = &cobra.Command{
+	Use:   "start",
+	Short: "Start the background daemon",
+	Run: func(cmd *cobra.Command, args []string) {
+		log := logger.With(zap.String("command", "daemon start"))
+		defer log.Sync()
+
+		if daemonForeground {
+			if err := daemon.Serve(cmd.Context(), []string{profile}, log); err != nil {
+				log.Error("Daemon failed", zap.Error(err))
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+
+		if client, err := daemon.Dial(); err == nil {
+			client.Close()
+			fmt.Println("Daemon is already running.")
+			return
+		}
+
+		pid, logFile, err := spawnDaemon()
+		if err != nil {
+			log.Error("Failed to start daemon", zap.Error(err))
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		// Wait briefly for the socket to come up
+		for i := 0; i < 50; i++ {
+			if client, err := daemon.Dial(); err == nil {
+				client.Close()
+				fmt.Printf("Daemon started (pid %d). Logs: %s\n", pid, logFile)
+				return
+			}
+			time.Sleep(100 * time.Millisecond)
+		}
+		fmt.Fprintf(os.Stderr, "Error: daemon did not come up; see %s\n", logFile)
+		os.Exit(1)
+	},
+}
+
+// daemonStopCmd stops the daemon.
+var daemonStopCmd = &cobra.Command{
+	Use:   "stop",
+	Short: "Stop the background daemon",
+	Run: func(cmd *cobra.Command, args []string) {
+		client, err := daemon.Dial()
+		if err != nil {
+			fmt.Println("Daemon is not running.")
+			return
+		}
+		defer client.Close()
+
+		if err := client.Shutdown(); err != nil {
+			logger.Error("Failed to stop daemon", zap.Error(err))
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println("Daemon stopped.")
+	},
+}
+
+// daemonStatusCmd reports whether the daemon is running.
+var daemonStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show daemon status",
+	Run: func(cmd *cobra.Command, args []string) {
+		// running is the daemon's status, which is empty when it isn't
+		// running, for --json
+		type running struct {
+			Running bool `json:"running"`
+			*daemon.StatusReply
+		}
+		client, err := daemon.Dial()
+		if err != nil {
+			if jsonOutput {
+				printJSON(running{})
+				return
+			}
+			fmt.Println("Daemon is not running.")
+			return
+		}
+		defer client.Close()
+
+		status, err := client.Status()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if jsonOutput {
+			printJSON(running{Running: true, StatusReply: status})
+			return
+		}
+		fmt.Printf("Daemon running (pid %d)\n", status.PID)
+		fmt.Printf("Uptime:   %s\n", time.Since(status.Started).Round(time.Second))
+		fmt.Printf("Queries:  %d\n", status.Queries)
+		fmt.Printf("Profiles: %s\n", strings.Join(status.Profiles, ", "))
+	},
+}
+
+func init() {
+	daemonStartCmd.Flags().BoolVar(&daemonForeground, "foreground", false, "Run the daemon in the foreground")
+	rootCmd.PersistentFlags().BoolVar(&noDaemon, "no-daemon", false, "Do not route queries through a running daemon")
+
+	daemonCmd.AddCommand(daemonStartCmd)
+	daemonCmd.AddCommand(daemonStopCmd)
+	daemonCmd.AddCommand(daemonStatusCmd)
+
+	rootCmd.AddCommand(daemonCmd)
+}
+
+// spawnDaemon re-executes this binary as a detached foreground daemon
+// writing its output to ~/.trino-cli/logs/daemon.log.
+func spawnDaemon() (int, string, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return 0, "", err
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return 0, "", err
+	}
+	logDir := filepath.Join(home, ".trino-cli", "logs")
+	if err := os.MkdirAll(logDir, 0755); err != nil {
+		return 0, "", err
+	}
+	logFile := filepath.Join(logDir, "daemon.log")
+	out, err := os.OpenFile(logFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return 0, "", err
+	}
+	defer out.Close()
+
+	args := []string{"daemon", "start", "--foreground", "--profile", profile}
+	if cfgFile != "" {
+		args = append(args, "--config", cfgFile)
+	}
+	proc := exec.Command(exe, args...)
+	proc.Stdout = out
+	proc.Stderr = out
+	proc.SysProcAttr = detachedProcAttr()
+	if err := proc.Start(); err != nil {
+		return 0, "", err
+	}
+	pid := proc.Process.Pid
+	proc.Process.Release()
+	return pid, logFile, nil
+}
+
+// executeQuery runs a query through the daemon when one is running with the
+// same settings for the profile, and directly otherwise.
+func executeQuery(ctx context.Context, query, profileName string) (*engine.QueryResult, error) {
+	if err := readPassword(profileName); err != nil {
+		return nil, err
+	}
+	// The daemon connects with its own config, so it can't use a password
+	// typed in here
+	if !noDaemon && !typedPasswords[profileName] {
+		if client, err := daemon.Dial(); err == nil {
+			defer client.Close()
+			logger.Debug("Executing query via daemon")
+			result, err := client.Execute(ctx, query, profileName)
+			if !errors.Is(err, daemon.ErrProfileMismatch) {
+				return result, err
+			}
+			logger.Debug("Daemon has other settings for the profile; executing directly", zap.String("profile", profileName))
+		}
+	}
+	return engine.ExecuteQuery(ctx, query, profileName)
+}
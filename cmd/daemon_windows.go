@@ -0,0 +1,11 @@
+//go:build windows
+
+package cmd
+
+import "syscall"
+
+// detachedProcAttr returns no special attributes; unix sockets and session
+// detachment are not supported on Windows.
+func detachedProcAttr() *syscall.SysProcAttr {
+	return nil
+}
@@ -0,0 +1,360 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/TFMV/trino-cli/autocomplete"
+	"github.com/TFMV/trino-cli/cache"
+	"github.com/TFMV/trino-cli/config"
+	"github.com/TFMV/trino-cli/engine"
+	"github.com/TFMV/trino-cli/history"
+	"github.com/TFMV/trino-cli/ui"
+	"github.com/spf13/cobra"
+)
+
+var doctorRedact bool
+
+// configErr is what loading the config file failed with, which doctor
+// reports instead of stopping
+var configErr error
+
+// doctorTimeout bounds the checks of each profile's server
+const doctorTimeout = 15 * time.Second
+
+// Outcomes of a doctor check
+const (
+	checkOK   = "ok"
+	checkWarn = "warn"
+	checkFail = "fail"
+	checkSkip = "skip"
+)
+
+// doctorCheck is the outcome of one check
+type doctorCheck struct {
+	Name   string `json:"name"`
+	Status string `json:"status"` // ok, warn, fail or skip
+	Detail string `json:"detail"`
+}
+
+// doctorSection groups the checks of the config, a profile or local data
+type doctorSection struct {
+	Title  string        `json:"title"`
+	Checks []doctorCheck `json:"checks"`
+}
+
+// doctorReport is everything doctor found, in the order it is printed
+type doctorReport struct {
+	Generated time.Time       `json:"generated"`
+	Client    clientInfo      `json:"client"`
+	Sections  []doctorSection `json:"sections"`
+}
+
+// failed counts the checks that failed
+func (r doctorReport) failed() int {
+	n := 0
+	for _, s := range r.Sections {
+		for _, c := range s.Checks {
+			if c.Status == checkFail {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+// doctorCmd checks the setup and prints a report to attach to bug reports.
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check the config, connections and local data, and report what is wrong",
+	Long: `Checks that the config file is valid; that the server of each profile, or of
+--profile when it is given, can be reached and accepts the profile's
+credentials; and that the query history, the result cache and the autocomplete
+caches are readable and intact. Prints the outcome of each check with the
+versions of trino-cli and of the servers, as a report to attach to a bug
+report, and exits with status 1 if any check failed.
+
+Passwords and tokens never appear in the report; --redact also replaces host
+names, user names and the home directory, for reports shared in public.
+Profiles that prompt for a password are only checked for the server, as doctor
+asks for nothing.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		report := doctorReport{Generated: time.Now().UTC(), Client: readClientInfo()}
+		names := doctorProfiles(cmd)
+		report.Sections = append(report.Sections, checkConfig(names))
+		report.Sections = append(report.Sections, checkProfiles(cmd.Context(), names)...)
+		report.Sections = append(report.Sections, checkLocalData(cmd.Context(), names))
+
+		redact := newRedactor(names, doctorRedact)
+		for i := range report.Sections {
+			s := &report.Sections[i]
+			s.Title = redact(s.Title)
+			for j := range s.Checks {
+				s.Checks[j].Detail = redact(s.Checks[j].Detail)
+			}
+		}
+
+		if jsonOutput {
+			printJSON(report)
+		} else {
+			displayDoctorReport(report)
+		}
+		if report.failed() > 0 {
+			os.Exit(1)
+		}
+	},
+}
+
+// doctorProfiles is the profile named by --profile or $TRINO_CLI_PROFILE,
+// or else every profile of the config, or the default one when it has none
+func doctorProfiles(cmd *cobra.Command) []string {
+	if cmd.Flags().Changed("profile") || os.Getenv("TRINO_CLI_PROFILE") != "" {
+		return []string{profile}
+	}
+	if names := ui.ProfileNames(); len(names) > 0 {
+		return names
+	}
+	return []string{profile}
+}
+
+// checkConfig reports whether the config file loaded, and what it holds
+func checkConfig(names []string) doctorSection {
+	section := doctorSection{Title: "Config"}
+	file := doctorCheck{Name: "file", Status: checkOK, Detail: cfgFile}
+	switch {
+	case configErr != nil:
+		file.Status, file.Detail = checkFail, configErr.Error()
+	case cfgFile == "":
+		file.Detail = "none; profiles come from the environment"
+	}
+	section.Checks = append(section.Checks, file)
+	if configErr != nil {
+		return section
+	}
+
+	profiles := doctorCheck{Name: "profiles", Status: checkOK, Detail: strings.Join(ui.ProfileNames(), ", ")}
+	if len(config.AppConfig.Profiles) == 0 {
+		profiles.Status = checkWarn
+		profiles.Detail = fmt.Sprintf("none; connecting to %s", engine.ServerAddress(names[0]))
+	}
+	section.Checks = append(section.Checks, profiles)
+	return section
+}
+
+// checkProfiles checks the server and credentials of each profile, all at
+// once
+func checkProfiles(ctx context.Context, names []string) []doctorSection {
+	sections := make([]doctorSection, len(names))
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sections[i] = checkProfile(ctx, name)
+		}()
+	}
+	wg.Wait()
+	return sections
+}
+
+// checkProfile reports whether the profile's server answers, and whether it
+// accepts the profile's credentials
+func checkProfile(ctx context.Context, name string) doctorSection {
+	ctx, cancel := context.WithTimeout(ctx, doctorTimeout)
+	defer cancel()
+	section := doctorSection{Title: fmt.Sprintf("Profile %s (%s)", name, engine.ServerAddress(name))}
+
+	server := doctorCheck{Name: "server", Status: checkOK}
+	info, err := engine.GetServerInfo(ctx, name)
+	switch {
+	case err != nil:
+		server.Status, server.Detail = checkFail, err.Error()
+	default:
+		server.Detail = fmt.Sprintf("Trino %s, environment %s", info.Version, info.Environment)
+		if info.Uptime != "" {
+			server.Detail += ", up " + info.Uptime
+		}
+		if info.Starting {
+			server.Status = checkWarn
+			server.Detail += ", still starting"
+		}
+	}
+	section.Checks = append(section.Checks, server)
+
+	p := config.AppConfig.Profiles[name]
+	auth := doctorCheck{Name: "auth", Status: checkOK}
+	switch {
+	case err != nil:
+		auth.Status, auth.Detail = checkSkip, "the server can't be reached"
+	case p.NeedsPassword():
+		env := p.PasswordEnv
+		if env == "" {
+			env = config.PasswordEnv
+		}
+		auth.Status, auth.Detail = checkSkip, fmt.Sprintf("prompts for a password; set $%s to check it", env)
+	default:
+		user, err := engine.CurrentUser(ctx, name)
+		if err != nil {
+			auth.Status, auth.Detail = checkFail, fmt.Sprintf("%s: %v", authMethod(p), err)
+		} else {
+			auth.Detail = fmt.Sprintf("running as %s, %s", user, authMethod(p))
+		}
+	}
+	section.Checks = append(section.Checks, auth)
+	return section
+}
+
+// authMethod describes how the profile authenticates
+func authMethod(p config.Profile) string {
+	switch {
+	case p.Kerberos.Enabled:
+		return "authenticated by Kerberos"
+	case p.AccessToken != "":
+		return "authenticated by OAuth2 token"
+	case p.Password != "" || p.PasswordEnv != "" || p.PromptPassword:
+		return "authenticated by password"
+	}
+	return "without credentials"
+}
+
+// checkLocalData reports whether the history, the result cache and the
+// autocomplete cache of each profile are intact
+func checkLocalData(ctx context.Context, names []string) doctorSection {
+	section := doctorSection{Title: "Local data"}
+
+	hist := doctorCheck{Name: "history", Status: checkOK}
+	if health, err := history.Check(ctx); err != nil {
+		hist.Status, hist.Detail = checkFail, err.Error()
+	} else {
+		hist.Detail = fmt.Sprintf("%s, %d entries, %s", health.Path, health.Entries, formatBytes(health.Size))
+		if !health.Search {
+			hist.Detail += ", no full-text index"
+		}
+		if len(health.Problems) > 0 {
+			hist.Status = checkFail
+			hist.Detail += "; damaged: " + strings.Join(health.Problems, "; ")
+		}
+	}
+	section.Checks = append(section.Checks, hist)
+
+	results := doctorCheck{Name: "result cache", Status: checkOK}
+	if health, err := cache.Check(); err != nil {
+		results.Status, results.Detail = checkFail, err.Error()
+	} else {
+		noun := "results"
+		if health.Entries == 1 {
+			noun = "result"
+		}
+		results.Detail = fmt.Sprintf("%s, %d %s, %s", health.Dir, health.Entries, noun, formatBytes(health.Size))
+		if !health.Exists {
+			results.Detail = health.Dir + ", empty"
+		}
+		if health.Partial > 0 {
+			results.Status = checkWarn
+			results.Detail += fmt.Sprintf("; %d left half-written, which trino-cli clean --what cache removes", health.Partial)
+		}
+	}
+	section.Checks = append(section.Checks, results)
+
+	for _, name := range names {
+		completion := doctorCheck{Name: "autocomplete", Status: checkOK}
+		status, err := autocomplete.ProfileCacheStatus(autocompleteProfile(name))
+		switch {
+		case err != nil:
+			completion.Status = checkFail
+			completion.Detail = fmt.Sprintf("%s: %v; trino-cli autocomplete clear --profile %s rebuilds it", name, err, name)
+		case !status.Exists || status.Schemas == 0:
+			completion.Detail = name + ": not built yet"
+		default:
+			completion.Detail = fmt.Sprintf("%s: %d schemas, %d tables, %d columns, %s, refreshed %s",
+				name, status.Schemas, status.Tables, status.Columns, formatBytes(status.Size), formatCacheAge(status.Newest))
+		}
+		section.Checks = append(section.Checks, completion)
+	}
+	return section
+}
+
+// newRedactor returns a function that hides the passwords and tokens of the
+// profiles. With identities set it also hides their hosts and users, and the
+// home directory.
+func newRedactor(names []string, identities bool) func(string) string {
+	var pairs, users []string
+	for _, name := range names {
+		p := config.AppConfig.Profiles[name]
+		for _, secret := range []string{p.Password, p.AccessToken} {
+			if secret != "" {
+				pairs = append(pairs, secret, "****")
+			}
+		}
+		if !identities {
+			continue
+		}
+		if p.Host != "" {
+			pairs = append(pairs, p.Host, "<"+name+" host>")
+		}
+		if p.User != "" {
+			users = append(users, regexp.QuoteMeta(p.User))
+		}
+	}
+	if home, err := os.UserHomeDir(); err == nil && identities {
+		pairs = append(pairs, home, "~")
+	}
+	replacer := strings.NewReplacer(pairs...)
+	if len(users) == 0 {
+		return replacer.Replace
+	}
+	// Users are only hidden as whole words, as short names turn up in others
+	userPattern := regexp.MustCompile(`\b(` + strings.Join(users, "|") + `)\b`)
+	return func(s string) string {
+		return userPattern.ReplaceAllString(replacer.Replace(s), "<user>")
+	}
+}
+
+// displayDoctorReport prints the report for a terminal or a bug report
+func displayDoctorReport(r doctorReport) {
+	commit := r.Client.Commit
+	if len(commit) > 12 {
+		commit = commit[:12]
+	}
+	fmt.Printf("trino-cli %s", r.Client.Version)
+	if commit != "" {
+		fmt.Printf(" (%s)", commit)
+	}
+	fmt.Printf(", %s %s, %s\n", r.Client.Go, r.Client.Platform, r.Generated.Format(time.RFC3339))
+
+	for _, s := range r.Sections {
+		fmt.Printf("\n%s\n", s.Title)
+		for _, c := range s.Checks {
+			detail := strings.ReplaceAll(c.Detail, "\n", "\n"+strings.Repeat(" ", 22))
+			fmt.Printf("  %-4s  %-14s  %s\n", c.Status, c.Name, detail)
+		}
+	}
+
+	fmt.Println()
+	switch failed := r.failed(); failed {
+	case 0:
+		fmt.Println("No problems found.")
+	case 1:
+		fmt.Println("1 check failed.")
+	default:
+		fmt.Printf("%d checks failed.\n", failed)
+	}
+}
+
+// doctoring reports whether the command being run is doctor
+func doctoring() bool {
+	cmd, _, err := rootCmd.Find(os.Args[1:])
+	return err == nil && cmd == doctorCmd
+}
+
+func init() {
+	doctorCmd.Flags().BoolVar(&doctorRedact, "redact", false, "Also hide host names, user names and the home directory, for reports shared in public")
+
+	rootCmd.AddCommand(doctorCmd)
+}
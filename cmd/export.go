@@ -1,8 +1,12 @@
 package cmd
 
 import (
+	"fmt"
+	"io"
 	"os"
+	"strings"
 
+	"github.com/TFMV/trino-cli/config"
 	"github.com/TFMV/trino-cli/engine"
 	"github.com/spf13/cobra"
 	"go.uber.org/zap"
@@ -18,12 +22,22 @@ var exportCmd = &cobra.Command{
 	Use:   "export [SQL]",
 	Short: "Exports query results to a specified format",
 	Long: `Executes the provided SQL query and exports the result in the specified format.
-Supported formats: csv, json, arrow, parquet. You can specify an output file using --output.`,
+Supported formats are listed under --format. You can specify an output file using --output.`,
 	Args: cobra.MinimumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		log := logger.With(zap.String("command", "export"))
 		defer log.Sync()
 
+		// --json, then the profile's default format, apply unless --format
+		// says otherwise
+		if f := config.AppConfig.Profiles[profile].Defaults.Format; !cmd.Flags().Changed("format") {
+			if jsonOutput {
+				exportFormat = "json"
+			} else if f != "" && f != "table" {
+				exportFormat = f
+			}
+		}
+
 		sql := args[0]
 		log.Info("Executing export command",
 			zap.String("query", sql),
@@ -31,88 +45,46 @@ Supported formats: csv, json, arrow, parquet. You can specify an output file usi
 			zap.String("output", outputFile))
 
 		// Execute the query
-		result, err := engine.ExecuteQuery(sql, profile)
+		result, err := executeQuery(cmd.Context(), sql, profile)
 		if err != nil {
 			log.Error("Error executing query", zap.Error(err))
-			os.Stderr.WriteString("Error executing query: " + err.Error() + "\n")
-			return
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
 		}
 
-		var stringOutput string
-		var binaryOutput []byte
-		isBinary := false
-
-		switch exportFormat {
-		case "csv":
-			stringOutput, err = engine.ExportCSV(result)
-		case "json":
-			stringOutput, err = engine.ExportJSON(result)
-		case "arrow":
-			binaryOutput, err = engine.ExportArrow(result)
-			isBinary = true
-		case "parquet":
-			binaryOutput, err = engine.ExportParquet(result)
-			isBinary = true
-		default:
+		format, ok := engine.LookupFormat(exportFormat)
+		if !ok {
 			log.Error("Unsupported export format", zap.String("format", exportFormat))
-			os.Stderr.WriteString("Unsupported export format: " + exportFormat + "\n")
-			return
-		}
-
-		if err != nil {
-			log.Error("Error exporting data", zap.Error(err))
-			os.Stderr.WriteString("Error exporting data: " + err.Error() + "\n")
-			return
+			fmt.Fprintf(os.Stderr, "Error: unsupported format %q (available: %s)\n",
+				exportFormat, strings.Join(engine.FormatNames(), ", "))
+			os.Exit(1)
 		}
 
-		// Write output to a file if specified, otherwise print to stdout
+		// Write output to a file if specified, otherwise stream to stdout
 		if outputFile != "" {
-			err = writeToFile(outputFile, stringOutput, binaryOutput, isBinary)
+			err = format.WriteFile(outputFile, result)
 			if err != nil {
 				log.Error("Error writing to file", zap.String("file", outputFile), zap.Error(err))
-				os.Stderr.WriteString("Error writing to file: " + err.Error() + "\n")
-			} else {
-				log.Info("Export successful", zap.String("file", outputFile))
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
 			}
+			log.Info("Export successful", zap.String("file", outputFile))
 		} else {
-			// Write to stdout
 			log.Info("Writing result to stdout")
-			if isBinary {
-				os.Stdout.Write(binaryOutput)
-			} else {
-				os.Stdout.WriteString(stringOutput)
+			err := page(func(w io.Writer) error {
+				return format.Writer.WriteResult(w, result)
+			})
+			if err != nil {
+				log.Error("Error exporting data", zap.Error(err))
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
 			}
 		}
 	},
 }
 
 func init() {
-	exportCmd.Flags().StringVar(&exportFormat, "format", "json", "Export format: csv, json, arrow, parquet")
+	exportCmd.Flags().StringVar(&exportFormat, "format", "json",
+		"Export format: "+strings.Join(engine.FormatNames(), ", ")+" (default json, or the profile's defaults.format)")
 	exportCmd.Flags().StringVar(&outputFile, "output", "", "Output file path (optional, defaults to stdout)")
 }
-
-// writeToFile writes data to a file, supporting both text and binary formats.
-func writeToFile(filename string, textData string, binaryData []byte, isBinary bool) error {
-	var err error
-	var file *os.File
-
-	// Open file with appropriate mode
-	if isBinary {
-		file, err = os.Create(filename)
-	} else {
-		file, err = os.OpenFile(filename, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
-	}
-
-	if err != nil {
-		return err
-	}
-	defer file.Close()
-
-	if isBinary {
-		_, err = file.Write(binaryData)
-	} else {
-		_, err = file.WriteString(textData)
-	}
-
-	return err
-}
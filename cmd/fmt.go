@@ -0,0 +1,189 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/TFMV/trino-cli/config"
+	"github.com/TFMV/trino-cli/sqlfmt"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+var (
+	fmtFiles         []string
+	fmtWrite         bool
+	fmtCheck         bool
+	fmtKeywordCase   string
+	fmtIndent        int
+	fmtLeadingCommas bool
+)
+
+// fmtCmd formats SQL without connecting to a server.
+var fmtCmd = &cobra.Command{
+	Use:   "fmt [SQL]",
+	Short: "Format SQL, or check that it is formatted",
+	Long: `Lays out Trino SQL in a consistent style: each clause of a query on a line of
+its own, the items of a long select list and the conditions of a WHERE on the
+lines under it, subqueries and CASE expressions indented, and keywords in one
+case. Only whitespace and the case of keywords change.
+
+The SQL is read from the arguments, from the files of -f, or from standard
+input when neither is given, and printed formatted. -w rewrites the files
+instead, and --check lists those that aren't formatted, exiting with status 1
+if there are any.
+
+The style comes from the sql_format section of the config file, which the
+flags override.`,
+	Example: `  trino-cli fmt "select a, b from t where x = 1"
+  trino-cli fmt -w -f daily.sql -f weekly.sql
+  trino-cli fmt --check -f daily.sql
+  pbpaste | trino-cli fmt --keyword-case lower`,
+	Run: func(cmd *cobra.Command, args []string) {
+		unformatted, err := runFmt(cmd, args)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if unformatted {
+			os.Exit(1)
+		}
+	},
+}
+
+// fmtInput is SQL to format and where it came from
+type fmtInput struct {
+	name string
+	sql  string
+}
+
+// runFmt formats the inputs, reporting whether --check found any that
+// weren't formatted
+func runFmt(cmd *cobra.Command, args []string) (bool, error) {
+	style, err := fmtStyle(cmd)
+	if err != nil {
+		return false, err
+	}
+	if fmtWrite && fmtCheck {
+		return false, errors.New("use --write or --check, not both")
+	}
+	inputs, err := fmtInputs(args)
+	if err != nil {
+		return false, err
+	}
+
+	unformatted := false
+	for i, in := range inputs {
+		formatted := sqlfmt.Format(in.sql, style)
+		if formatted != "" {
+			formatted += "\n"
+		}
+		switch {
+		case fmtCheck:
+			if strings.TrimRight(in.sql, "\r\n") != strings.TrimRight(formatted, "\n") {
+				fmt.Println(in.name)
+				unformatted = true
+			}
+		case fmtWrite:
+			if formatted == in.sql {
+				continue
+			}
+			info, err := os.Stat(in.name)
+			if err != nil {
+				return false, err
+			}
+			if err := os.WriteFile(in.name, []byte(formatted), info.Mode().Perm()); err != nil {
+				return false, err
+			}
+		default:
+			if i > 0 {
+				fmt.Println()
+			}
+			fmt.Print(formatted)
+		}
+	}
+	return unformatted, nil
+}
+
+// fmtStyle is the config file's style with the flags given applied
+func fmtStyle(cmd *cobra.Command) (sqlfmt.Style, error) {
+	style, err := sqlfmt.Configured(config.AppConfig.SQLFormat)
+	if err != nil {
+		return style, err
+	}
+	flags := cmd.Flags()
+	if flags.Changed("keyword-case") {
+		if style.KeywordCase, err = sqlfmt.ParseKeywordCase(fmtKeywordCase); err != nil {
+			return style, err
+		}
+	}
+	if flags.Changed("indent") {
+		if fmtIndent < 1 {
+			return style, fmt.Errorf("--indent must be at least 1, not %d", fmtIndent)
+		}
+		style.Indent = fmtIndent
+	}
+	if flags.Changed("leading-commas") {
+		style.LeadingCommas = fmtLeadingCommas
+	}
+	return style, nil
+}
+
+// fmtInputs gathers the SQL of the arguments, taken together, and of each
+// -f file, or what is piped in on standard input when neither is given
+func fmtInputs(args []string) ([]fmtInput, error) {
+	if fmtWrite && len(fmtFiles) == 0 {
+		return nil, errors.New("--write rewrites the files of -f; give at least one")
+	}
+	var inputs []fmtInput
+	if len(args) > 0 {
+		if fmtWrite {
+			return nil, errors.New("--write rewrites files; SQL given as arguments has none")
+		}
+		inputs = append(inputs, fmtInput{name: "arguments", sql: strings.Join(args, " ")})
+	}
+	for _, name := range fmtFiles {
+		var data []byte
+		var err error
+		if name == "-" {
+			if fmtWrite {
+				return nil, errors.New("--write can't rewrite standard input")
+			}
+			data, err = io.ReadAll(os.Stdin)
+			name = "stdin"
+		} else {
+			data, err = os.ReadFile(name)
+		}
+		if err != nil {
+			return nil, err
+		}
+		inputs = append(inputs, fmtInput{name: name, sql: string(data)})
+	}
+	if len(inputs) > 0 {
+		return inputs, nil
+	}
+
+	if term.IsTerminal(int(os.Stdin.Fd())) {
+		return nil, errors.New("give the SQL as arguments, with -f, or on standard input")
+	}
+	data, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return nil, err
+	}
+	return []fmtInput{{name: "stdin", sql: string(data)}}, nil
+}
+
+func init() {
+	fmtCmd.Flags().StringArrayVarP(&fmtFiles, "file", "f", nil, "Format the SQL of a file, - for standard input; may be repeated")
+	fmtCmd.Flags().BoolVarP(&fmtWrite, "write", "w", false, "Rewrite the files of -f formatted instead of printing them")
+	fmtCmd.Flags().BoolVar(&fmtCheck, "check", false, "List the inputs that aren't formatted, and exit with status 1 if there are any")
+	fmtCmd.Flags().StringVar(&fmtKeywordCase, "keyword-case", "", "Case of keywords: upper, lower or preserve (default sql_format.keyword_case, or upper)")
+	fmtCmd.Flags().IntVar(&fmtIndent, "indent", 0, "Spaces per indentation level (default sql_format.indent, or 2)")
+	fmtCmd.Flags().BoolVar(&fmtLeadingCommas, "leading-commas", false, "Start list lines with their comma instead of ending them with it (default sql_format.leading_commas)")
+	fmtCmd.RegisterFlagCompletionFunc("keyword-case", cobra.FixedCompletions([]string{"upper", "lower", "preserve"}, cobra.ShellCompDirectiveNoFileComp))
+
+	rootCmd.AddCommand(fmtCmd)
+}
@@ -1,14 +1,21 @@
 package cmd
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/TFMV/trino-cli/cache"
+	"github.com/TFMV/trino-cli/config"
 	"github.com/TFMV/trino-cli/engine"
 	"github.com/TFMV/trino-cli/history"
+	"github.com/TFMV/trino-cli/ui"
 	"github.com/olekukonko/tablewriter"
 	"github.com/spf13/cobra"
 	"go.uber.org/zap"
@@ -16,10 +23,21 @@ import (
 
 var (
 	historyLimit      int
+	historyStatsLimit int
 	historyOffset     int
 	historySearchTerm string
 	historyFuzzy      bool
 	historyDays       int
+	historyFailed     bool
+	historySince      string
+	historyUntil      string
+	historyMinDur     time.Duration
+	historyMinRows    int
+	historyFormat     string
+	historyPullOnly   bool
+	historyPushOnly   bool
+	historyOutput     string
+	historyExportFmt  string
 	historyCmd        *cobra.Command
 )
 
@@ -52,13 +70,25 @@ func init() {
 	}
 	historyListCmd.Flags().IntVarP(&historyLimit, "limit", "l", 20, "Maximum number of queries to show")
 	historyListCmd.Flags().IntVarP(&historyOffset, "offset", "o", 0, "Number of queries to skip")
+	historyListCmd.Flags().BoolVar(&historyFailed, "failed", false, "Only show queries that failed")
+	historyListCmd.Flags().StringVar(&historySince, "since", "", "Only show queries run since a date (YYYY-MM-DD, RFC3339) or age (e.g. 7d, 12h)")
+	historyListCmd.Flags().StringVar(&historyUntil, "until", "", "Only show queries run until a date (YYYY-MM-DD, RFC3339) or age (e.g. 7d, 12h)")
+	historyListCmd.Flags().DurationVar(&historyMinDur, "min-duration", 0, "Only show queries that took at least this long (e.g. 500ms, 2s)")
+	historyListCmd.Flags().IntVar(&historyMinRows, "min-rows", 0, "Only show queries that returned at least this many rows")
 
 	// Search subcommand
 	historySearchCmd := &cobra.Command{
 		Use:   "search [search term]",
 		Short: "Search query history",
-		Args:  cobra.MinimumNArgs(1),
-		Run:   historySearchCmdFunc,
+		Long: `Search the text of stored queries, newest first.
+
+Binaries built with -tags sqlite_fts5 search an FTS5 index: terms match whole
+words, "quoted phrases" match in order, prefix* matches the start of a word,
+and AND, OR and NOT combine terms. Other builds match the search term as a
+substring and say so on standard error. --fuzzy ranks queries by fuzzy match
+instead.`,
+		Args: cobra.MinimumNArgs(1),
+		Run:  historySearchCmdFunc,
 	}
 	historySearchCmd.Flags().IntVarP(&historyLimit, "limit", "l", 20, "Maximum number of queries to show")
 	historySearchCmd.Flags().BoolVarP(&historyFuzzy, "fuzzy", "f", false, "Use fuzzy search")
@@ -71,6 +101,30 @@ func init() {
 		Run:   historyReplayCmdFunc,
 	}
 
+	// Edit subcommand
+	historyEditCmd := &cobra.Command{
+		Use:   "edit [query id]",
+		Short: "Edit a query from history in $EDITOR and run it",
+		Long: `Open the stored SQL of a history entry in $VISUAL or $EDITOR (vi by default).
+The edited query is executed with the original profile and recorded as a new
+history entry linked to the original.`,
+		Args: cobra.ExactArgs(1),
+		Run:  historyEditCmdFunc,
+	}
+
+	// Results subcommand
+	historyResultsCmd := &cobra.Command{
+		Use:   "results [query id]",
+		Short: "Show the cached result of a query from history",
+		Long: `Display or export the result captured when a history entry ran, without
+re-running the query. Results are captured when cache.auto is enabled in the
+config file.`,
+		Args: cobra.ExactArgs(1),
+		Run:  historyResultsCmdFunc,
+	}
+	historyResultsCmd.Flags().StringVar(&historyExportFmt, "format", "", "Export format instead of a table: "+strings.Join(engine.FormatNames(), ", "))
+	historyResultsCmd.Flags().StringVar(&historyOutput, "output", "", "Output file path for --format (defaults to stdout)")
+
 	// Clear subcommand
 	historyClearCmd := &cobra.Command{
 		Use:   "clear",
@@ -79,27 +133,140 @@ func init() {
 	}
 	historyClearCmd.Flags().IntVarP(&historyDays, "days", "d", 0, "Clear history older than N days (0 = all history)")
 
+	// Stats subcommand
+	historyStatsCmd := &cobra.Command{
+		Use:   "stats",
+		Short: "Show query history statistics",
+		Long: `Report the most frequent queries (grouped by their normalized form), the slowest
+queries, per-profile counts, and daily query volume. Use --format json for dashboards.`,
+		Run: historyStatsCmdFunc,
+	}
+	historyStatsCmd.Flags().IntVarP(&historyStatsLimit, "limit", "l", 10, "Number of frequent and slowest queries to show")
+	historyStatsCmd.Flags().StringVar(&historySince, "since", "", "Only include queries run since a date (YYYY-MM-DD, RFC3339) or age (e.g. 7d, 12h)")
+	historyStatsCmd.Flags().StringVar(&historyUntil, "until", "", "Only include queries run until a date (YYYY-MM-DD, RFC3339) or age (e.g. 7d, 12h)")
+	historyStatsCmd.Flags().StringVar(&historyFormat, "format", "table", "Output format (table, json)")
+
+	// Sync subcommand
+	historySyncCmd := &cobra.Command{
+		Use:   "sync",
+		Short: "Sync query history with a remote store",
+		Long: `Merge the local query history with the remote store configured under
+history.sync in the config file, so several machines share one history.
+Entries are merged by ID, so syncing never overwrites or loses queries.
+
+HTTP stores are read with GET and written with a conditional PUT; set
+history.sync.token or TRINO_CLI_SYNC_TOKEN to send a bearer token. S3 stores
+use the AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN
+environment variables.`,
+		Run: historySyncCmdFunc,
+	}
+	historySyncCmd.Flags().BoolVar(&historyPullOnly, "pull-only", false, "Only import remote entries")
+	historySyncCmd.Flags().BoolVar(&historyPushOnly, "push-only", false, "Only upload local entries")
+
 	// Add subcommands to history command
 	historyCmd.AddCommand(historyListCmd)
 	historyCmd.AddCommand(historySearchCmd)
 	historyCmd.AddCommand(historyReplayCmd)
+	historyCmd.AddCommand(historyEditCmd)
+	historyCmd.AddCommand(historyResultsCmd)
 	historyCmd.AddCommand(historyClearCmd)
+	historyCmd.AddCommand(historyStatsCmd)
+	historyCmd.AddCommand(historySyncCmd)
 
 	// Add history command to root command
 	rootCmd.AddCommand(historyCmd)
 }
 
+// applyHistoryRetention prunes the history according to the history section
+// of the configuration, logging what was removed
+func applyHistoryRetention() {
+	policy := config.AppConfig.History
+	if policy.MaxEntries <= 0 && policy.MaxAge == "" {
+		return
+	}
+
+	var maxAge time.Duration
+	if policy.MaxAge != "" {
+		var err error
+		if maxAge, err = parseAge(policy.MaxAge); err != nil {
+			logger.Warn("Ignoring invalid history max_age", zap.String("max_age", policy.MaxAge), zap.Error(err))
+		}
+	}
+
+	result, err := history.Prune(context.Background(), policy.MaxEntries, maxAge)
+	if err != nil {
+		logger.Warn("Failed to apply history retention policy", zap.Error(err))
+		return
+	}
+	if result.Expired > 0 || result.OverLimit > 0 {
+		logger.Info("Pruned query history",
+			zap.Int64("expired", result.Expired),
+			zap.Int64("over_limit", result.OverLimit),
+			zap.Int("max_entries", policy.MaxEntries),
+			zap.String("max_age", policy.MaxAge))
+	}
+}
+
 func historyListCmdFunc(cmd *cobra.Command, args []string) {
-	queries, err := history.GetQueries(historyLimit, historyOffset)
+	filter := history.QueryFilter{
+		MinDuration: historyMinDur,
+		MinRows:     historyMinRows,
+	}
+	if historyFailed {
+		filter.Status = history.StatusFailed
+	}
+	// The global --profile flag filters only when given explicitly
+	if cmd.Flags().Changed("profile") {
+		filter.Profile = profile
+	}
+
+	var err error
+	if filter.Since, err = parseTimeFlag(historySince); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: invalid --since: %v\n", err)
+		os.Exit(1)
+	}
+	if filter.Until, err = parseUntilFlag(historyUntil); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: invalid --until: %v\n", err)
+		os.Exit(1)
+	}
+
+	queries, err := history.ListQueries(cmd.Context(), filter, historyLimit, historyOffset)
 	if err != nil {
 		logger.Error("Error retrieving query history", zap.Error(err))
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		return
+		os.Exit(1)
 	}
 
 	displayQueryHistory(queries)
 }
 
+// parseTimeFlag parses a point in time given as a date, an RFC3339 timestamp,
+// or an age relative to now (e.g. 7d). An empty value yields the zero time.
+func parseTimeFlag(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return t, nil
+	}
+	if age, err := parseAge(value); err == nil {
+		return time.Now().Add(-age), nil
+	}
+	return time.Time{}, fmt.Errorf("unrecognized time %q", value)
+}
+
+// parseUntilFlag parses the end of a time range like parseTimeFlag. A date
+// on its own covers the whole day, so it yields the following midnight.
+func parseUntilFlag(value string) (time.Time, error) {
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return t.AddDate(0, 0, 1), nil
+	}
+	return parseTimeFlag(value)
+}
+
 func historySearchCmdFunc(cmd *cobra.Command, args []string) {
 	// Join all the args to form the search term
 	searchTerm := strings.Join(args, " ")
@@ -108,18 +275,21 @@ func historySearchCmdFunc(cmd *cobra.Command, args []string) {
 	var err error
 
 	if historyFuzzy {
-		queries, err = history.FuzzySearchQueries(searchTerm, historyLimit)
+		queries, err = history.FuzzySearchQueries(cmd.Context(), searchTerm, historyLimit)
 	} else {
-		queries, err = history.SearchQueries(searchTerm, historyLimit)
+		queries, err = history.SearchQueries(cmd.Context(), searchTerm, historyLimit)
+		if !history.FullTextSearch() && !jsonOutput {
+			fmt.Fprintln(os.Stderr, "Searching by substring: this build has no full-text index (build with -tags sqlite_fts5 to enable it)")
+		}
 	}
 
 	if err != nil {
 		logger.Error("Error searching query history", zap.Error(err))
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		return
+		os.Exit(1)
 	}
 
-	if len(queries) == 0 {
+	if len(queries) == 0 && !jsonOutput {
 		fmt.Println("No matching queries found.")
 		return
 	}
@@ -131,27 +301,117 @@ func historyReplayCmdFunc(cmd *cobra.Command, args []string) {
 	id := args[0]
 
 	// Get the query from history
-	query, err := history.GetQueryByID(id)
+	query, err := history.GetQueryByID(cmd.Context(), id)
 	if err != nil {
 		logger.Error("Error retrieving query", zap.Error(err), zap.String("id", id))
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		return
+		os.Exit(1)
 	}
 
-	fmt.Printf("Replaying query: %s\n", query.Query)
+	if !jsonOutput {
+		fmt.Printf("Replaying query: %s\n", query.Query)
+	}
 
 	// Execute the query
-	result, err := engine.ExecuteQuery(query.Query, query.Profile)
+	result, err := executeQuery(cmd.Context(), query.Query, query.Profile)
 	if err != nil {
 		logger.Error("Error executing query", zap.Error(err))
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		return
+		os.Exit(1)
 	}
 
 	// Display the results
 	displayQueryResult(result)
 }
 
+func historyEditCmdFunc(cmd *cobra.Command, args []string) {
+	id := args[0]
+
+	original, err := history.GetQueryByID(cmd.Context(), id)
+	if err != nil {
+		logger.Error("Error retrieving query", zap.Error(err), zap.String("id", id))
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	query, err := ui.EditInEditor(original.Query)
+	if err != nil {
+		logger.Error("Error editing query", zap.Error(err))
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	query = strings.TrimSpace(query)
+	if query == "" {
+		fmt.Println("Empty query, nothing to run.")
+		return
+	}
+
+	if !jsonOutput {
+		fmt.Printf("Running edited query: %s\n", query)
+	}
+
+	// Record the run as a follow-up of the original entry
+	ctx := history.WithParentID(cmd.Context(), original.ID)
+	result, err := executeQuery(ctx, query, original.Profile)
+	if err != nil {
+		logger.Error("Error executing query", zap.Error(err))
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	displayQueryResult(result)
+}
+
+func historyResultsCmdFunc(cmd *cobra.Command, args []string) {
+	id := args[0]
+
+	entry, err := history.GetQueryByID(cmd.Context(), id)
+	if err != nil {
+		logger.Error("Error retrieving query", zap.Error(err), zap.String("id", id))
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if entry.CacheKey == "" {
+		fmt.Fprintf(os.Stderr, "Error: no cached result for query %s (enable cache.auto to capture results)\n", id)
+		os.Exit(1)
+	}
+
+	result, err := loadCachedResult(entry.CacheKey)
+	if errors.Is(err, cache.ErrNotFound) {
+		fmt.Fprintf(os.Stderr, "Error: the cached result of query %s has been removed\n", id)
+		os.Exit(1)
+	}
+	if err != nil {
+		logger.Error("Error loading cached result", zap.Error(err), zap.String("key", entry.CacheKey))
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if historyExportFmt == "" {
+		displayQueryResult(result)
+		return
+	}
+
+	format, ok := engine.LookupFormat(historyExportFmt)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Error: unsupported format %q (available: %s)\n",
+			historyExportFmt, strings.Join(engine.FormatNames(), ", "))
+		os.Exit(1)
+	}
+	if historyOutput != "" {
+		err = format.WriteFile(historyOutput, result)
+	} else {
+		err = page(func(w io.Writer) error {
+			return format.Writer.WriteResult(w, result)
+		})
+	}
+	if err != nil {
+		logger.Error("Error exporting cached result", zap.Error(err))
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
 func historyClearCmdFunc(cmd *cobra.Command, args []string) {
 	var olderThan time.Time
 
@@ -159,13 +419,19 @@ func historyClearCmdFunc(cmd *cobra.Command, args []string) {
 		olderThan = time.Now().AddDate(0, 0, -historyDays)
 	}
 
-	count, err := history.ClearHistory(olderThan)
+	count, err := history.ClearHistory(cmd.Context(), olderThan)
 	if err != nil {
 		logger.Error("Error clearing history", zap.Error(err))
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		return
+		os.Exit(1)
 	}
 
+	if jsonOutput {
+		printJSON(struct {
+			Cleared int64 `json:"cleared"`
+		}{count})
+		return
+	}
 	if historyDays > 0 {
 		fmt.Printf("Cleared %d queries older than %d days.\n", count, historyDays)
 	} else {
@@ -173,14 +439,182 @@ func historyClearCmdFunc(cmd *cobra.Command, args []string) {
 	}
 }
 
+func historySyncCmdFunc(cmd *cobra.Command, args []string) {
+	if historyPullOnly && historyPushOnly {
+		fmt.Fprintln(os.Stderr, "Error: --pull-only and --push-only are mutually exclusive")
+		os.Exit(1)
+	}
+
+	settings := config.AppConfig.History.Sync
+	if settings.URL == "" {
+		fmt.Fprintln(os.Stderr, "Error: no sync store configured (set history.sync.url in the config file)")
+		os.Exit(1)
+	}
+	token := settings.Token
+	if env := os.Getenv("TRINO_CLI_SYNC_TOKEN"); env != "" {
+		token = env
+	}
+
+	backend, err := history.NewBackend(settings.URL, settings.Region, settings.Endpoint, token)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	result, err := history.Sync(cmd.Context(), backend, !historyPushOnly, !historyPullOnly)
+	if err != nil {
+		logger.Error("Error syncing history", zap.Error(err))
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if jsonOutput {
+		printJSON(result)
+		return
+	}
+	fmt.Printf("Pulled %d and pushed %d queries.\n", result.Pulled, result.Pushed)
+}
+
+func historyStatsCmdFunc(cmd *cobra.Command, args []string) {
+	if historyFormat != "table" && historyFormat != "json" {
+		fmt.Fprintf(os.Stderr, "Error: unsupported format %q (use table or json)\n", historyFormat)
+		os.Exit(1)
+	}
+
+	var filter history.QueryFilter
+	if cmd.Flags().Changed("profile") {
+		filter.Profile = profile
+	}
+
+	var err error
+	if filter.Since, err = parseTimeFlag(historySince); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: invalid --since: %v\n", err)
+		os.Exit(1)
+	}
+	if filter.Until, err = parseUntilFlag(historyUntil); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: invalid --until: %v\n", err)
+		os.Exit(1)
+	}
+
+	stats, err := history.GetStats(cmd.Context(), filter, historyStatsLimit)
+	if err != nil {
+		logger.Error("Error computing history stats", zap.Error(err))
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if historyFormat == "json" || jsonOutput {
+		printJSON(stats)
+		return
+	}
+
+	displayHistoryStats(stats)
+}
+
+// newStatsTable creates a table styled like the history list
+func newStatsTable(header []string) *tablewriter.Table {
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader(header)
+	table.SetBorder(false)
+	table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetCenterSeparator("")
+	table.SetColumnSeparator("")
+	table.SetRowSeparator("")
+	table.SetHeaderLine(false)
+	table.SetAutoWrapText(true)
+	return table
+}
+
+func displayHistoryStats(stats *history.Stats) {
+	if stats.TotalQueries == 0 {
+		fmt.Println("No queries in history.")
+		return
+	}
+
+	fmt.Printf("Total queries: %d (%d failed)\n", stats.TotalQueries, stats.FailedQueries)
+
+	fmt.Println("\nMost frequent queries:")
+	table := newStatsTable([]string{"Count", "Avg Duration", "Last Run", "Query"})
+	for _, f := range stats.MostFrequent {
+		table.Append([]string{
+			strconv.Itoa(f.Count),
+			formatDuration(f.AvgDuration),
+			f.LastRun.Format("Jan 02 15:04:05"),
+			truncateQuery(f.Query),
+		})
+	}
+	table.Render()
+
+	fmt.Println("\nSlowest queries:")
+	table = newStatsTable([]string{"ID", "Duration", "Rows", "Profile", "Query"})
+	for _, q := range stats.Slowest {
+		table.Append([]string{
+			q.ID,
+			formatDuration(q.Duration),
+			strconv.Itoa(q.Rows),
+			q.Profile,
+			truncateQuery(q.Query),
+		})
+	}
+	table.Render()
+
+	fmt.Println("\nQueries per profile:")
+	table = newStatsTable([]string{"Profile", "Queries", "Failed", "Avg Duration"})
+	for _, p := range stats.Profiles {
+		table.Append([]string{
+			p.Profile,
+			strconv.Itoa(p.Count),
+			strconv.Itoa(p.Failed),
+			formatDuration(p.AvgDuration),
+		})
+	}
+	table.Render()
+
+	fmt.Println("\nQueries per day:")
+	table = newStatsTable([]string{"Day", "Queries", "Failed"})
+	for _, v := range stats.Volume {
+		table.Append([]string{v.Day, strconv.Itoa(v.Count), strconv.Itoa(v.Failed)})
+	}
+	table.Render()
+}
+
+// truncateQuery shortens a query for display in a table column
+func truncateQuery(query string) string {
+	query = strings.Join(strings.Fields(query), " ")
+	if len(query) > 80 {
+		return query[:77] + "..."
+	}
+	return query
+}
+
 func displayQueryHistory(queries []history.QueryHistory) {
+	if jsonOutput {
+		printJSON(jsonList(queries))
+		return
+	}
 	if len(queries) == 0 {
 		fmt.Println("No queries in history.")
 		return
 	}
 
-	table := tablewriter.NewWriter(os.Stdout)
-	table.SetHeader([]string{"ID", "Timestamp", "Profile", "Duration", "Rows", "Query"})
+	// Only show the error column when there is something to show
+	showErrors := false
+	for _, q := range queries {
+		if q.Status == history.StatusFailed {
+			showErrors = true
+			break
+		}
+	}
+
+	header := []string{"ID", "Timestamp", "Profile", "Status", "Duration", "Rows", "Query"}
+	if showErrors {
+		header = append(header, "Error")
+	}
+
+	var out bytes.Buffer
+	table := tablewriter.NewWriter(&out)
+	table.SetHeader(header)
 	table.SetBorder(false)
 	table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
 	table.SetAlignment(tablewriter.ALIGN_LEFT)
@@ -203,17 +637,27 @@ func displayQueryHistory(queries []history.QueryHistory) {
 		// Format timestamp
 		timestamp := q.Timestamp.Format("Jan 02 15:04:05")
 
-		table.Append([]string{
+		row := []string{
 			q.ID,
 			timestamp,
 			q.Profile,
+			q.Status,
 			duration,
 			strconv.Itoa(q.Rows),
 			queryStr,
-		})
+		}
+		if showErrors {
+			errStr := q.Error
+			if len(errStr) > 60 {
+				errStr = errStr[:57] + "..."
+			}
+			row = append(row, errStr)
+		}
+		table.Append(row)
 	}
 
 	table.Render()
+	pageBuffer(&out)
 }
 
 func formatDuration(d time.Duration) string {
@@ -227,12 +671,17 @@ func formatDuration(d time.Duration) string {
 }
 
 func displayQueryResult(result *engine.QueryResult) {
+	if jsonOutput {
+		printJSON(result)
+		return
+	}
 	if len(result.Rows) == 0 {
 		fmt.Println("Query returned no results.")
 		return
 	}
 
-	table := tablewriter.NewWriter(os.Stdout)
+	var out bytes.Buffer
+	table := tablewriter.NewWriter(&out)
 	table.SetHeader(result.Columns)
 	table.SetAutoFormatHeaders(false)
 	table.SetBorder(false)
@@ -258,4 +707,5 @@ func displayQueryResult(result *engine.QueryResult) {
 	}
 
 	table.Render()
+	pageBuffer(&out)
 }
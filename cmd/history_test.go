@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseUntilFlag(t *testing.T) {
+	got, err := parseUntilFlag("2025-01-31")
+	if err != nil {
+		t.Fatalf("parseUntilFlag failed: %v", err)
+	}
+	if want := time.Date(2025, 2, 1, 0, 0, 0, 0, time.Local); !got.Equal(want) {
+		t.Errorf("parseUntilFlag(date) = %v, want the next midnight %v", got, want)
+	}
+
+	// A query run late on the last day falls inside the range
+	lastQuery := time.Date(2025, 1, 31, 23, 59, 0, 0, time.Local)
+	if !lastQuery.Before(got) {
+		t.Errorf("query at %v is not before --until %v", lastQuery, got)
+	}
+
+	got, err = parseUntilFlag("2025-01-31T12:00:00Z")
+	if err != nil {
+		t.Fatalf("parseUntilFlag failed: %v", err)
+	}
+	if want := time.Date(2025, 1, 31, 12, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("parseUntilFlag(timestamp) = %v, want %v", got, want)
+	}
+
+	if got, err := parseUntilFlag(""); err != nil || !got.IsZero() {
+		t.Errorf("parseUntilFlag(\"\") = %v, %v, want the zero time", got, err)
+	}
+	if _, err := parseUntilFlag("yesterday"); err == nil {
+		t.Error("parseUntilFlag accepted an unrecognized time")
+	}
+}
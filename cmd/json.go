@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// jsonOutput makes the commands that list, report or show results print
+// JSON in place of tables and messages, for scripts and other tools
+var jsonOutput bool
+
+// printJSON writes v to standard output as indented JSON
+func printJSON(v any) {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+	}
+}
+
+// jsonList returns items, or an empty list when there are none, which
+// encodes as [] rather than null
+func jsonList[T any](items []T) []T {
+	if items == nil {
+		return []T{}
+	}
+	return items
+}
+
+func init() {
+	rootCmd.PersistentFlags().BoolVar(&jsonOutput, "json", false, "Print the output of list, status and result commands as JSON")
+}
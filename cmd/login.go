@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/TFMV/trino-cli/auth"
+	"github.com/TFMV/trino-cli/config"
+	"github.com/TFMV/trino-cli/daemon"
+	"github.com/TFMV/trino-cli/keychain"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+var (
+	loginNoBrowser bool
+	loginTimeout   time.Duration
+)
+
+// loginCmd logs in to a profile's server with OAuth2 and caches the token.
+var loginCmd = &cobra.Command{
+	Use:   "login",
+	Short: "Log in to a profile's server with OAuth2",
+	Long: `Runs the server's OAuth2 (OpenID Connect) login: the login page opens in a
+browser, or, with --no-browser or where no browser can be opened, its address is
+printed to open on any device. Once you have logged in there, the access token is
+cached under ~/.trino-cli/tokens, or in the OS keychain for profiles with
+"keychain: true", and every later connection of the profile uses it, until it
+expires and you log in again.`,
+	Example: "  trino-cli login --profile prod",
+	Args:    cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		log := logger.With(zap.String("command", "login"), zap.String("profile", profile))
+		defer log.Sync()
+
+		p, ok := config.AppConfig.Profiles[profile]
+		if !ok {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", config.UnknownProfile(profile))
+			os.Exit(1)
+		}
+
+		ctx, cancel := context.WithTimeout(cmd.Context(), loginTimeout)
+		defer cancel()
+		open := func(url string) error {
+			if !loginNoBrowser && auth.OpenBrowser(url) == nil {
+				fmt.Fprintf(os.Stderr, "Opened the login page in your browser. If it did not appear, open:\n\n  %s\n\n", url)
+			} else {
+				fmt.Fprintf(os.Stderr, "Open this page to log in:\n\n  %s\n\n", url)
+			}
+			fmt.Fprintln(os.Stderr, "Waiting for the login to finish...")
+			return nil
+		}
+		token, err := auth.Login(ctx, http.DefaultClient, p.ServerURL(), p.User, open)
+		if err != nil {
+			log.Error("Login failed", zap.Error(err))
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if err := saveToken(profile, p, token); err != nil {
+			log.Error("Failed to cache token", zap.Error(err))
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("Logged in to %s as %s.", p.Host, p.User)
+		if !token.Expiry.IsZero() {
+			fmt.Printf(" The token expires at %s.", token.Expiry.Local().Format("2006-01-02 15:04"))
+		}
+		fmt.Println()
+		if client, err := daemon.Dial(); err == nil {
+			client.Close()
+			fmt.Println("Restart the daemon (trino-cli daemon stop, then start) for it to use the new token.")
+		}
+	},
+}
+
+func init() {
+	loginCmd.Flags().BoolVar(&loginNoBrowser, "no-browser", false, "Print the login page's address instead of opening a browser")
+	loginCmd.Flags().DurationVar(&loginTimeout, "timeout", 5*time.Minute, "How long to wait for the login to finish")
+
+	rootCmd.AddCommand(loginCmd)
+}
+
+// saveToken caches a profile's token in the keychain, if the profile keeps
+// its secrets there, and on disk otherwise
+func saveToken(name string, p config.Profile, token auth.Token) error {
+	if !p.Keychain {
+		return auth.SaveToken(name, token)
+	}
+	data, err := json.Marshal(token)
+	if err != nil {
+		return err
+	}
+	return keychain.Set(credentialName(name, true), string(data))
+}
+
+// loadAccessTokens makes each profile with a token cached by login connect
+// with it. Expired tokens are left out, so the server asks for a new login.
+func loadAccessTokens() {
+	for name := range config.AppConfig.Profiles {
+		token, ok, err := auth.LoadToken(name)
+		if err != nil {
+			logger.Warn("Failed to load cached token", zap.String("profile", name), zap.Error(err))
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if token.Expired() {
+			logger.Debug("Cached token has expired", zap.String("profile", name))
+			continue
+		}
+		config.SetAccessToken(name, token.AccessToken)
+	}
+}
+
+// registerKerberos sets up the HTTP clients of the profiles that
+// authenticate with Kerberos
+func registerKerberos() {
+	for name, p := range config.AppConfig.Profiles {
+		if !p.Kerberos.Enabled {
+			continue
+		}
+		if err := auth.RegisterKerberos(p.Kerberos); err != nil {
+			logger.Warn("Failed to set up Kerberos", zap.String("profile", name), zap.Error(err))
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"bytes"
+	"io"
+	"os"
+
+	"github.com/TFMV/trino-cli/pager"
+)
+
+// noPager keeps output that doesn't fit on the screen out of the pager
+var noPager bool
+
+// page writes output with write, through $PAGER when it goes to a terminal
+// and doesn't fit on the screen, unless --no-pager is given
+func page(write func(w io.Writer) error) error {
+	if noPager {
+		return write(os.Stdout)
+	}
+	return pager.Run(write)
+}
+
+// pageBuffer shows output gathered in out, as page does
+func pageBuffer(out *bytes.Buffer) {
+	page(func(w io.Writer) error {
+		_, err := out.WriteTo(w)
+		return err
+	})
+}
+
+func init() {
+	rootCmd.PersistentFlags().BoolVar(&noPager, "no-pager", false, "Print output that doesn't fit on the screen directly instead of through $PAGER ("+pager.Default+" by default)")
+}
@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/TFMV/trino-cli/engine"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+var (
+	queryListLimit    int
+	queryListAll      bool
+	queryListAllUsers bool
+)
+
+// queryCmd is the parent command for the queries running on the server.
+var queryCmd = &cobra.Command{
+	Use:   "query",
+	Short: "List and kill queries running on the server",
+	Long: `Finds queries in system.runtime.queries and kills them with
+system.runtime.kill_query, without opening the Trino web UI. Killing another
+user's query needs the corresponding access rights.`,
+}
+
+// queryListCmd lists the server's queries.
+var queryListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the queries queued or running on the server",
+	Long: `Lists your queries that are queued or running on the server of --profile, the
+most recent first. --all adds the ones that finished or failed recently, and
+--all-users lists everyone's.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := readPassword(profile); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		queries, err := engine.ListServerQueries(cmd.Context(), profile, engine.ServerQueryFilter{
+			Limit:      queryListLimit,
+			AllUsers:   queryListAllUsers,
+			Unfinished: !queryListAll,
+		})
+		if err != nil {
+			logger.Error("Error listing server queries", zap.Error(err))
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		displayServerQueries(queries)
+	},
+}
+
+// queryKillCmd kills queries on the server.
+var queryKillCmd = &cobra.Command{
+	Use:     "kill <query_id>...",
+	Short:   "Kill queries running on the server",
+	Example: "  trino-cli query kill 20240305_140709_00012_abcde",
+	Args:    cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := readPassword(profile); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		// killed is how killing each query went, for --json
+		type killed struct {
+			ID     string `json:"id"`
+			Killed bool   `json:"killed"`
+			Error  string `json:"error,omitempty"`
+		}
+		var results []killed
+		failed := false
+		for _, id := range args {
+			if err := engine.KillQuery(cmd.Context(), profile, id); err != nil {
+				logger.Error("Error killing query", zap.String("id", id), zap.Error(err))
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				results = append(results, killed{ID: id, Error: err.Error()})
+				failed = true
+				continue
+			}
+			results = append(results, killed{ID: id, Killed: true})
+			if !jsonOutput {
+				fmt.Printf("Killed query %s.\n", id)
+			}
+		}
+		if jsonOutput {
+			printJSON(results)
+		}
+		if failed {
+			os.Exit(1)
+		}
+	},
+}
+
+func displayServerQueries(queries []engine.ServerQuery) {
+	if jsonOutput {
+		printJSON(jsonList(queries))
+		return
+	}
+	if len(queries) == 0 {
+		fmt.Println("No queries found.")
+		return
+	}
+
+	var out bytes.Buffer
+	table := tablewriter.NewWriter(&out)
+	table.SetHeader([]string{"Query ID", "State", "User", "Elapsed", "Query"})
+	table.SetBorder(false)
+	table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetCenterSeparator("")
+	table.SetColumnSeparator("")
+	table.SetRowSeparator("")
+	table.SetHeaderLine(false)
+	table.SetAutoWrapText(false)
+
+	now := time.Now()
+	for _, q := range queries {
+		table.Append([]string{q.ID, q.State, q.User, formatDuration(q.Elapsed(now)), truncateQuery(q.Query)})
+	}
+	table.Render()
+	pageBuffer(&out)
+}
+
+func init() {
+	queryListCmd.Flags().IntVarP(&queryListLimit, "limit", "l", 50, "Maximum number of queries to show")
+	queryListCmd.Flags().BoolVar(&queryListAll, "all", false, "Include queries that have finished or failed")
+	queryListCmd.Flags().BoolVar(&queryListAllUsers, "all-users", false, "List every user's queries, not just yours")
+
+	queryCmd.AddCommand(queryListCmd)
+	queryCmd.AddCommand(queryKillCmd)
+
+	rootCmd.AddCommand(queryCmd)
+}
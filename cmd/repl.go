@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/TFMV/trino-cli/repl"
+	"github.com/spf13/cobra"
+)
+
+// noTUI runs the line-based shell in place of the full-screen one
+var noTUI bool
+
+// replCmd runs the line-based shell.
+var replCmd = &cobra.Command{
+	Use:   "repl",
+	Short: "Run a line-based SQL shell, for dumb terminals and slow connections",
+	Long: `Reads SQL statements a line at a time, running each once its semicolon is
+typed, and prints the results as plain tables. Unlike the full-screen shell it
+doesn't redraw the screen, so it works on dumb terminals and over slow SSH
+connections. trino-cli --no-tui starts it too.
+
+Lines can be edited with the arrow keys, Home and End, and Up and Down go back
+through the lines typed in this and earlier sessions, kept in
+~/.trino-cli/repl_history. Ctrl+C cancels a running query or discards the line
+being typed, and Ctrl+D quits.
+
+Backslash commands, after psql's:
+  \?                               show help
+  \q                               quit
+  \c [PROFILE] [[CATALOG.]SCHEMA]  switch profile or schema, or show the current ones
+  \l                               list catalogs
+  \dn [CATALOG]                    list schemas
+  \dt [[[CATALOG.]SCHEMA.]PATTERN]  list tables, * matching any characters
+  \d [TABLE]                       describe a table, or list tables
+  \timing [on|off]                 toggle showing how long queries take`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := chooseProfile(cmd, false); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		runREPL(cmd)
+	},
+}
+
+// runREPL runs the line-based shell on the chosen profile
+func runREPL(cmd *cobra.Command) {
+	if err := readPassword(profile); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if err := repl.Run(cmd.Context(), profile, repl.Options{NoPager: noPager, Connect: readPassword}); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func init() {
+	rootCmd.Flags().BoolVar(&noTUI, "no-tui", false, "Run the line-based shell of `trino-cli repl` instead of the full-screen one")
+
+	rootCmd.AddCommand(replCmd)
+}
@@ -1,8 +1,13 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
 
 	"github.com/TFMV/trino-cli/config"
 	"github.com/TFMV/trino-cli/engine"
@@ -10,13 +15,22 @@ import (
 
 	"github.com/spf13/cobra"
 	"go.uber.org/zap"
+	"golang.org/x/term"
 )
 
 var (
-	cfgFile   string
-	profile   string
-	execQuery string
-	logger    *zap.Logger
+	cfgFile      string
+	profile      string
+	execQuery    string
+	outputFormat string
+	maxRows      int
+	pageSize     int
+	columnWidth  int
+	logger       *zap.Logger
+
+	// typedPasswords holds the profiles whose password was typed in at a
+	// prompt, which a running daemon has no way to know
+	typedPasswords = make(map[string]bool)
 )
 
 // rootCmd represents the base command when called without any subcommands.
@@ -24,21 +38,46 @@ var rootCmd = &cobra.Command{
 	Use:   "trino-cli",
 	Short: "Trino CLI tool for interactive querying and analysis",
 	Long:  `A high-performance, feature-rich CLI tool for connecting to Trino, executing queries interactively or in batch mode, caching results, and exporting in multiple formats.`,
-	// If -e flag is provided then run a single query in batch mode, otherwise launch the interactive TUI.
+	// If -e or -f is given, or SQL is piped in, run it in batch mode, otherwise launch the interactive TUI,
+	// or the line-based shell with --no-tui.
 	Run: func(cmd *cobra.Command, args []string) {
-		if execQuery != "" {
-			result, err := engine.ExecuteQuery(execQuery, profile)
-			if err != nil {
-				logger.Error("Error executing query", zap.Error(err))
+		statements, batch, err := batchStatements()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if err := chooseProfile(cmd, !batch && !noTUI); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if batch {
+			if err := runBatch(cmd, statements); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 				os.Exit(1)
-				return
 			}
-			// Display results in table format
-			engine.DisplayResult(result)
 			return
 		}
-		// Launch interactive TUI
-		ui.StartInteractive(profile)
+		if noTUI {
+			runREPL(cmd)
+			return
+		}
+		if err := readPassword(profile); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		// Launch interactive TUI, with any display limits given as flags
+		// taking precedence over the config file
+		flags := cmd.Flags()
+		if flags.Changed("max-rows") {
+			config.AppConfig.UI.MaxRows = maxRows
+		}
+		if flags.Changed("page-size") {
+			config.AppConfig.UI.PageSize = pageSize
+		}
+		if flags.Changed("column-width") {
+			config.AppConfig.UI.ColumnWidth = columnWidth
+		}
+		ui.StartInteractive(cmd.Context(), profile)
 	},
 }
 
@@ -53,11 +92,11 @@ func Execute() {
 		os.Exit(1)
 	}
 	defer logger.Sync()
+	engine.SetLogger(logger)
 
-	// Load configuration.
-	if err := initConfig(); err != nil {
-		logger.Error("Failed to load configuration", zap.Error(err))
-	}
+	// Load configuration. Its problems are reported once the flags are
+	// parsed, in case --config names another file.
+	_ = initConfig()
 
 	// Add subcommands.
 	rootCmd.AddCommand(historyCmd)
@@ -65,7 +104,11 @@ func Execute() {
 	rootCmd.AddCommand(exportCmd)
 	// The schema command is added in schema.go
 
-	if err := rootCmd.Execute(); err != nil {
+	// Cancel in-flight work on Ctrl+C or SIGTERM
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := rootCmd.ExecuteContext(ctx); err != nil {
 		logger.Error("Command execution error", zap.Error(err))
 		os.Exit(1)
 	}
@@ -73,17 +116,47 @@ func Execute() {
 
 func init() {
 	cobra.OnInitialize(func() {
+		// Shell completion reads the config file and the autocomplete cache
+		// and needs no credentials
+		if completing() {
+			initConfig()
+			config.ApplyEnv(profile)
+			return
+		}
 		if err := initConfig(); err != nil {
-			logger.Error("Failed to initialize config", zap.Error(err))
+			// doctor reports a broken config with everything else
+			if !doctoring() {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			configErr = err
 		}
+		if err := config.ApplyEnv(profile); err != nil {
+			logger.Error("Failed to apply environment overrides", zap.Error(err))
+		}
+		loadAccessTokens()
+		loadKeychainSecrets()
+		registerKerberos()
+		applyHistoryRetention()
 	})
-	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.trino-cli.yaml)")
-	rootCmd.PersistentFlags().StringVar(&profile, "profile", "default", "Trino profile to use")
-	rootCmd.PersistentFlags().StringVarP(&execQuery, "execute", "e", "", "Execute a single query in batch mode")
+	defaultProfile := "default"
+	if env := os.Getenv("TRINO_CLI_PROFILE"); env != "" {
+		defaultProfile = env
+	}
+	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", os.Getenv("TRINO_CLI_CONFIG"), "config file, or $TRINO_CLI_CONFIG (default is $HOME/.trino-cli.yaml)")
+	rootCmd.PersistentFlags().StringVar(&profile, "profile", defaultProfile, "Trino profile to use, or $TRINO_CLI_PROFILE")
+	rootCmd.RegisterFlagCompletionFunc("profile", completeProfiles)
+	rootCmd.PersistentFlags().StringVarP(&execQuery, "execute", "e", "", "Execute SQL in batch mode; statements are separated by semicolons")
+	rootCmd.Flags().StringArrayVarP(&execFiles, "file", "f", nil, "Execute the SQL of a file in batch mode, - for standard input; may be repeated")
+	rootCmd.Flags().StringVar(&outputFormat, "format", "table", "Output format of batch mode: table, "+strings.Join(engine.FormatNames(), ", ")+" (default table, or the profile's defaults.format)")
+	rootCmd.Flags().IntVar(&maxRows, "max-rows", 0, "Result rows to render in the interactive shell before L loads more (-1 for all; default 10000)")
+	rootCmd.Flags().IntVar(&pageSize, "page-size", 0, "Result rows per page in the interactive shell (default 500)")
+	rootCmd.Flags().IntVar(&columnWidth, "column-width", 0, "Width at which the interactive shell cuts off result values (default 40)")
 }
 
 func initConfig() error {
-	// Use the provided config file or default to $HOME/.trino-cli.yaml.
+	// Use the provided config file or default to $HOME/.trino-cli.yaml,
+	// which may be left out when the environment sets everything
 	if cfgFile != "" {
 		return config.LoadConfig(cfgFile)
 	}
@@ -91,6 +164,80 @@ func initConfig() error {
 	if err != nil {
 		return fmt.Errorf("unable to find home directory: %v", err)
 	}
-	cfgFile = home + "/.trino-cli.yaml"
+	path := home + "/.trino-cli.yaml"
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil
+	}
+	cfgFile = path
 	return config.LoadConfig(cfgFile)
 }
+
+// chooseProfile settles which profile to use when neither --profile nor
+// $TRINO_CLI_PROFILE names one: the only one the config has, or, when it has
+// several and interactive is set, the one picked from a list at the
+// terminal. Otherwise the default profile stays.
+func chooseProfile(cmd *cobra.Command, interactive bool) error {
+	if cmd.Flags().Changed("profile") || os.Getenv("TRINO_CLI_PROFILE") != "" {
+		return nil
+	}
+	names := ui.ProfileNames()
+	switch {
+	case len(names) == 1:
+		profile = names[0]
+		return config.ApplyEnv(profile)
+	case len(names) < 2 || !interactive || !term.IsTerminal(int(os.Stdin.Fd())):
+		return nil
+	}
+	picked, ok, err := ui.PickProfile(profile)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		os.Exit(0)
+	}
+	profile = picked
+	return config.ApplyEnv(profile)
+}
+
+// writeResult writes a result of batch mode to w as a table or in an export format
+func writeResult(w io.Writer, result *engine.QueryResult, format string) error {
+	if format == "" || format == "table" {
+		return engine.WriteTable(w, result)
+	}
+	f, ok := engine.LookupFormat(format)
+	if !ok {
+		return fmt.Errorf("unknown format %q (available: table, %s)", format, strings.Join(engine.FormatNames(), ", "))
+	}
+	return f.Writer.WriteResult(w, result)
+}
+
+// readPassword prompts for the password of a profile that asks for one and
+// finds none in the config or the environment, and keeps it for the rest of
+// the run. Other profiles need nothing read.
+func readPassword(profileName string) error {
+	p, ok := config.AppConfig.Profiles[profileName]
+	if !ok || !p.NeedsPassword() {
+		return nil
+	}
+	env := p.PasswordEnv
+	if env == "" {
+		env = config.PasswordEnv
+	}
+
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return fmt.Errorf("profile %s needs a password; set $%s or run from a terminal", profileName, env)
+	}
+	fmt.Fprintf(os.Stderr, "Password for %s@%s: ", p.User, p.Host)
+	password, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return err
+	}
+	if len(password) == 0 {
+		return fmt.Errorf("password must not be empty")
+	}
+	config.SetPassword(profileName, string(password))
+	typedPasswords[profileName] = true
+	return nil
+}
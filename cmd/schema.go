@@ -1,12 +1,34 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
+	"strings"
 
+	"github.com/TFMV/trino-cli/autocomplete"
+	"github.com/TFMV/trino-cli/bookmark"
+	"github.com/TFMV/trino-cli/config"
 	"github.com/TFMV/trino-cli/schema"
+	"github.com/TFMV/trino-cli/ui"
 	"github.com/spf13/cobra"
 	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	schemaExportCatalog string
+	schemaExportSchema  string
+	schemaExportFormat  string
+	schemaExportOutput  string
+
+	schemaDiffProfile2 string
+	schemaDiffCatalog  string
+	schemaDiffSchema   string
+	schemaDiffCached   bool
+
+	schemaERDFormat string
+	schemaERDOutput string
 )
 
 // schemaCmd is the parent command for schema-related operations.
@@ -27,28 +49,312 @@ var schemaBrowseCmd = &cobra.Command{
 
 		log.Info("Starting schema browser", zap.String("profile", profile))
 
-		// Create a new schema browser
+		// Create a new schema browser in the configured colors
+		if err := readPassword(profile); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		theme := ui.ApplyTheme(config.AppConfig.UI)
 		browser, err := schema.NewBrowser(profile, log)
 		if err != nil {
 			log.Error("Failed to create schema browser", zap.Error(err))
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			return
+			os.Exit(1)
+		}
+		browser.SetPalette(theme.SchemaPalette())
+		browser.SetHighlighter(func(sql string) string { return ui.HighlightSQL(sql, theme) })
+		browser.SetClipboard(ui.CopyToClipboard)
+		browser.SetAutoStats(config.AppConfig.UI.SchemaStats)
+		if ttl, err := schema.ParseCacheTTL(config.AppConfig.UI.SchemaTTL); err != nil {
+			log.Warn("Using the default schema cache TTL", zap.Error(err))
+		} else {
+			browser.SetCacheTTL(ttl)
+		}
+		if err := browser.SetHiddenPatterns(config.AppConfig.UI.SchemaHide); err != nil {
+			log.Warn("Hiding only the system schemas", zap.Error(err))
 		}
+		browser.SetShowHidden(config.AppConfig.UI.SchemaShowHidden)
 
 		// Start the browser
-		if err := browser.Start(); err != nil {
+		if err := browser.Start(cmd.Context()); err != nil {
 			log.Error("Schema browser error", zap.Error(err))
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			return
+			os.Exit(1)
 		}
 
 		log.Info("Schema browser closed")
 	},
 }
 
+// schemaExportCmd dumps schema metadata for documentation and diffing.
+var schemaExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export schema metadata as JSON or YAML",
+	Long: "Write the catalogs, schemas, tables, and columns of the server, with their types, nullability, and comments, " +
+		"as JSON or YAML. Output is sorted so that exports of the same schema diff cleanly.",
+	Run: func(cmd *cobra.Command, args []string) {
+		log := logger.With(zap.String("command", "schema export"))
+		defer log.Sync()
+
+		format := strings.ToLower(schemaExportFormat)
+		if format != "json" && format != "yaml" {
+			fmt.Fprintf(os.Stderr, "Error: unsupported format %q (use json or yaml)\n", schemaExportFormat)
+			os.Exit(1)
+		}
+
+		if err := readPassword(profile); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		db, err := schema.Connect(profile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		defer db.Close()
+
+		log.Info("Exporting schema metadata",
+			zap.String("catalog", schemaExportCatalog),
+			zap.String("schema", schemaExportSchema),
+			zap.String("format", format))
+		metadata, err := schema.ExportMetadata(cmd.Context(), db, schemaExportCatalog, schemaExportSchema)
+		if err != nil {
+			log.Error("Schema export failed", zap.Error(err))
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		var data []byte
+		if format == "yaml" {
+			data, err = yaml.Marshal(metadata)
+		} else {
+			data, err = json.MarshalIndent(metadata, "", "  ")
+			data = append(data, '\n')
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		if schemaExportOutput == "" {
+			os.Stdout.Write(data)
+			return
+		}
+		if err := os.WriteFile(schemaExportOutput, data, 0644); err != nil {
+			log.Error("Error writing to file", zap.String("file", schemaExportOutput), zap.Error(err))
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		log.Info("Schema export successful", zap.String("file", schemaExportOutput))
+	},
+}
+
+// schemaDiffCmd compares the metadata of two profiles' servers.
+var schemaDiffCmd = &cobra.Command{
+	Use:   "diff",
+	Short: "Compare the schemas of two profiles",
+	Long: "Compare the metadata of the --profile server with that of --profile2, listing the catalogs, schemas, and tables " +
+		"only one has (- only in --profile, + only in --profile2) and the columns added, removed, or retyped in shared tables. " +
+		"With --cached, each profile's autocomplete cache is compared instead of the servers; it holds only the schemas " +
+		"autocomplete introspects.",
+	Example: "  trino-cli schema diff --profile prod --profile2 staging --catalog hive --schema sales",
+	Run: func(cmd *cobra.Command, args []string) {
+		log := logger.With(zap.String("command", "schema diff"))
+		defer log.Sync()
+
+		log.Info("Comparing schemas",
+			zap.String("profile", profile),
+			zap.String("profile2", schemaDiffProfile2),
+			zap.String("catalog", schemaDiffCatalog),
+			zap.String("schema", schemaDiffSchema),
+			zap.Bool("cached", schemaDiffCached))
+		read := func(profileName string) (*schema.MetadataExport, error) {
+			if schemaDiffCached {
+				return cachedMetadata(profileName, log)
+			}
+			if err := readPassword(profileName); err != nil {
+				return nil, err
+			}
+			db, err := schema.Connect(profileName)
+			if err != nil {
+				return nil, err
+			}
+			defer db.Close()
+			metadata, err := schema.ExportMetadata(cmd.Context(), db, schemaDiffCatalog, schemaDiffSchema)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", profileName, err)
+			}
+			return metadata, nil
+		}
+
+		// Read both servers at once
+		var to *schema.MetadataExport
+		var toErr error
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			to, toErr = read(schemaDiffProfile2)
+		}()
+		from, err := read(profile)
+		<-done
+		if err == nil {
+			err = toErr
+		}
+		if err != nil {
+			log.Error("Schema diff failed", zap.Error(err))
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		changes := schema.DiffMetadata(from, to)
+		if jsonOutput {
+			printJSON(struct {
+				From    string                `json:"from"`
+				To      string                `json:"to"`
+				Changes []schema.SchemaChange `json:"changes"`
+			}{profile, schemaDiffProfile2, jsonList(changes)})
+			return
+		}
+		if len(changes) == 0 {
+			fmt.Printf("No differences between %s and %s\n", profile, schemaDiffProfile2)
+			return
+		}
+		fmt.Printf("Comparing %s (-) with %s (+)\n\n", profile, schemaDiffProfile2)
+		for _, change := range changes {
+			fmt.Println(change)
+		}
+		fmt.Printf("\n%d difference(s)\n", len(changes))
+	},
+}
+
+// schemaERDCmd draws an entity-relationship diagram of a schema.
+var schemaERDCmd = &cobra.Command{
+	Use:   "erd <catalog.schema>",
+	Short: "Draw an entity-relationship diagram of a schema",
+	Long: "Write the tables of a schema and the relationships between them as a Mermaid erDiagram or a Graphviz DOT graph. " +
+		"Trino reports no primary or foreign keys, so relationships are inferred from column names: a table's key is its " +
+		"id or <table>_id column, and a <name>_id column refers to the key of the <name> or <names> table. Nullable " +
+		"references are drawn as optional.",
+	Example: `  trino-cli schema erd hive.sales > sales.mmd
+  trino-cli schema erd hive.sales --format dot | dot -Tsvg > sales.svg`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeObjectName(autocomplete.SchemaDepth),
+	Run: func(cmd *cobra.Command, args []string) {
+		log := logger.With(zap.String("command", "schema erd"))
+		defer log.Sync()
+
+		catalog, schemaName, ok := strings.Cut(args[0], ".")
+		catalog, schemaName = strings.Trim(catalog, `"`), strings.Trim(schemaName, `"`)
+		if !ok || catalog == "" || schemaName == "" || strings.Contains(schemaName, ".") {
+			fmt.Fprintf(os.Stderr, "Error: %q is not a catalog.schema name\n", args[0])
+			os.Exit(1)
+		}
+		format := strings.ToLower(schemaERDFormat)
+		if format != "mermaid" && format != "dot" {
+			fmt.Fprintf(os.Stderr, "Error: unsupported format %q (use mermaid or dot)\n", schemaERDFormat)
+			os.Exit(1)
+		}
+
+		if err := readPassword(profile); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		db, err := schema.Connect(profile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		defer db.Close()
+
+		log.Info("Drawing schema diagram",
+			zap.String("catalog", catalog),
+			zap.String("schema", schemaName),
+			zap.String("format", format))
+		metadata, err := schema.ExportMetadata(cmd.Context(), db, catalog, schemaName)
+		if err != nil {
+			log.Error("Schema diagram failed", zap.Error(err))
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if len(metadata.Catalogs) == 0 || len(metadata.Catalogs[0].Schemas) == 0 {
+			fmt.Fprintf(os.Stderr, "Error: schema %s not found\n", args[0])
+			os.Exit(1)
+		}
+
+		s := metadata.Catalogs[0].Schemas[0]
+		diagram, err := schema.ERDiagram(s, schema.InferRelationships(s), format)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		if schemaERDOutput == "" {
+			fmt.Print(diagram)
+			return
+		}
+		if err := os.WriteFile(schemaERDOutput, []byte(diagram), 0644); err != nil {
+			log.Error("Error writing to file", zap.String("file", schemaERDOutput), zap.Error(err))
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		log.Info("Schema diagram written", zap.String("file", schemaERDOutput))
+	},
+}
+
+// cachedMetadata reads the metadata in a profile's autocomplete cache
+func cachedMetadata(profileName string, log *zap.Logger) (*schema.MetadataExport, error) {
+	if _, ok := config.AppConfig.Profiles[profileName]; !ok {
+		return nil, config.UnknownProfile(profileName)
+	}
+	dir, err := autocomplete.ProfileCacheDir(autocompleteProfile(profileName))
+	if err != nil {
+		return nil, err
+	}
+	store, err := autocomplete.NewSchemaCache(dir, log)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", profileName, err)
+	}
+	defer store.Close()
+	if catalogs, err := store.GetCatalogs(); err == nil && len(catalogs) == 0 {
+		return nil, fmt.Errorf("%s has no cached metadata; run `trino-cli autocomplete refresh --profile %s` first", profileName, profileName)
+	}
+	metadata, err := schema.StoredMetadata(store, schemaDiffCatalog, schemaDiffSchema)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", profileName, err)
+	}
+	return metadata, nil
+}
+
 func init() {
+	// Initialize the bookmark database behind the browser's Favorites
+	if err := bookmark.Initialize(); err != nil {
+		logger.Error("Failed to initialize bookmark database", zap.Error(err))
+	}
+
+	schemaExportCmd.Flags().StringVar(&schemaExportCatalog, "catalog", "", "Only export this catalog")
+	schemaExportCmd.Flags().StringVar(&schemaExportSchema, "schema", "", "Only export this schema")
+	schemaExportCmd.Flags().StringVar(&schemaExportFormat, "format", "json", "Output format (json or yaml)")
+	schemaExportCmd.Flags().StringVar(&schemaExportOutput, "output", "", "Output file path (optional, defaults to stdout)")
+
+	schemaDiffCmd.Flags().StringVar(&schemaDiffProfile2, "profile2", "", "Profile to compare --profile with")
+	schemaDiffCmd.Flags().StringVar(&schemaDiffCatalog, "catalog", "", "Only compare this catalog")
+	schemaDiffCmd.Flags().StringVar(&schemaDiffSchema, "schema", "", "Only compare this schema")
+	schemaDiffCmd.Flags().BoolVar(&schemaDiffCached, "cached", false, "Compare the profiles' autocomplete caches instead of querying the servers")
+	schemaDiffCmd.MarkFlagRequired("profile2")
+	schemaDiffCmd.RegisterFlagCompletionFunc("profile2", completeProfiles)
+	for _, c := range []*cobra.Command{schemaExportCmd, schemaDiffCmd} {
+		c.RegisterFlagCompletionFunc("catalog", completeCatalogFlag)
+		c.RegisterFlagCompletionFunc("schema", completeSchemaFlag)
+	}
+
+	schemaERDCmd.Flags().StringVar(&schemaERDFormat, "format", "mermaid", "Diagram format (mermaid or dot)")
+	schemaERDCmd.Flags().StringVar(&schemaERDOutput, "output", "", "Output file path (optional, defaults to stdout)")
+
 	// Add subcommands to schema command
 	schemaCmd.AddCommand(schemaBrowseCmd)
+	schemaCmd.AddCommand(schemaExportCmd)
+	schemaCmd.AddCommand(schemaDiffCmd)
+	schemaCmd.AddCommand(schemaERDCmd)
 
 	// Add schema command to root command
 	rootCmd.AddCommand(schemaCmd)
@@ -0,0 +1,201 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/TFMV/trino-cli/snippet"
+	"github.com/TFMV/trino-cli/ui"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+	"golang.org/x/term"
+)
+
+var (
+	snippetDescription string
+	snippetForce       bool
+)
+
+// snippetCmd is the parent command for the snippet library.
+var snippetCmd = &cobra.Command{
+	Use:   "snippet",
+	Short: "Manage reusable SQL snippets",
+	Long: `Snippets are named SQL fragments with optional placeholders, written as
+${name} or ${name:default}. Insert them from the interactive shell with Ctrl+O,
+or print them filled in with "snippet insert".`,
+}
+
+// snippetAddCmd saves a snippet.
+var snippetAddCmd = &cobra.Command{
+	Use:   "add <name> [sql]",
+	Short: "Save a snippet",
+	Long: `Save a snippet under a name. The SQL is taken from the arguments, from
+standard input when it is not a terminal, or else from $VISUAL or $EDITOR.`,
+	Example: `  trino-cli snippet add top-n 'SELECT * FROM ${table} ORDER BY ${column} DESC LIMIT ${n:10}'
+  trino-cli snippet add daily-load -d "Rows loaded per day" < daily_load.sql`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		log := logger.With(zap.String("command", "snippet add"), zap.String("name", args[0]))
+
+		name := args[0]
+		if err := snippet.ValidateName(name); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		body, err := readSnippetBody(args[1:])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		if err := snippet.Add(cmd.Context(), name, snippetDescription, body, snippetForce); err != nil {
+			log.Error("Error saving snippet", zap.Error(err))
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			if !snippetForce {
+				fmt.Fprintln(os.Stderr, "Use --force to replace an existing snippet.")
+			}
+			os.Exit(1)
+		}
+
+		fmt.Printf("Snippet %s saved", name)
+		if placeholders := snippet.Placeholders(body); len(placeholders) > 0 {
+			fmt.Printf(" with placeholders: %s", formatPlaceholders(placeholders))
+		}
+		fmt.Println(".")
+	},
+}
+
+// snippetListCmd lists the saved snippets.
+var snippetListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List saved snippets",
+	Run: func(cmd *cobra.Command, args []string) {
+		snippets, err := snippet.List(cmd.Context())
+		if err != nil {
+			logger.Error("Error listing snippets", zap.Error(err))
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if jsonOutput {
+			// listed is a snippet with the placeholders of its body
+			type listed struct {
+				snippet.Snippet
+				Placeholders []snippet.Placeholder `json:"placeholders"`
+			}
+			list := make([]listed, len(snippets))
+			for i, s := range snippets {
+				list[i] = listed{Snippet: s, Placeholders: jsonList(snippet.Placeholders(s.Body))}
+			}
+			printJSON(list)
+			return
+		}
+		if len(snippets) == 0 {
+			fmt.Println("No snippets saved. Add one with: trino-cli snippet add <name> <sql>")
+			return
+		}
+
+		table := newStatsTable([]string{"Name", "Placeholders", "Description", "SQL"})
+		table.SetAutoWrapText(false)
+		for _, s := range snippets {
+			sql := strings.Join(strings.Fields(s.Body), " ")
+			if len(sql) > 60 {
+				sql = sql[:57] + "..."
+			}
+			table.Append([]string{s.Name, formatPlaceholders(snippet.Placeholders(s.Body)), s.Description, sql})
+		}
+		table.Render()
+	},
+}
+
+// snippetInsertCmd prints a snippet with its placeholders filled in.
+var snippetInsertCmd = &cobra.Command{
+	Use:   "insert <name> [placeholder=value ...]",
+	Short: "Print a snippet with its placeholders filled in",
+	Long: `Print the SQL of a snippet with each placeholder replaced by the value
+given for it, or by its default. The output can be run directly:
+
+  trino-cli -e "$(trino-cli snippet insert top-n table=orders column=total)"`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		s, err := snippet.Get(cmd.Context(), args[0])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		values := make(map[string]string)
+		for _, arg := range args[1:] {
+			name, value, ok := strings.Cut(arg, "=")
+			if !ok || name == "" {
+				fmt.Fprintf(os.Stderr, "Error: invalid placeholder value %q, want name=value\n", arg)
+				os.Exit(1)
+			}
+			values[name] = value
+		}
+		known := make(map[string]bool)
+		for _, p := range snippet.Placeholders(s.Body) {
+			known[p.Name] = true
+		}
+		for name := range values {
+			if !known[name] {
+				fmt.Fprintf(os.Stderr, "Error: snippet %s has no placeholder %q\n", s.Name, name)
+				os.Exit(1)
+			}
+		}
+		if missing := snippet.Missing(s.Body, values); len(missing) > 0 {
+			fmt.Fprintf(os.Stderr, "Error: no value for %s (pass %s=...)\n", strings.Join(missing, ", "), missing[0])
+			os.Exit(1)
+		}
+
+		text, _ := snippet.Expand(s.Body, values)
+		fmt.Println(text)
+	},
+}
+
+func init() {
+	// Initialize the snippet database
+	if err := snippet.Initialize(); err != nil {
+		logger.Error("Failed to initialize snippet database", zap.Error(err))
+	}
+
+	snippetAddCmd.Flags().StringVarP(&snippetDescription, "description", "d", "", "Short description shown in the snippet list")
+	snippetAddCmd.Flags().BoolVarP(&snippetForce, "force", "f", false, "Replace an existing snippet with the same name")
+
+	snippetCmd.AddCommand(snippetAddCmd)
+	snippetCmd.AddCommand(snippetListCmd)
+	snippetCmd.AddCommand(snippetInsertCmd)
+
+	rootCmd.AddCommand(snippetCmd)
+}
+
+// readSnippetBody returns the SQL of a new snippet from args, piped input or
+// the user's editor
+func readSnippetBody(args []string) (string, error) {
+	if len(args) > 0 {
+		return strings.Join(args, " "), nil
+	}
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return "", fmt.Errorf("failed to read snippet from stdin: %w", err)
+		}
+		return strings.TrimSpace(string(data)), nil
+	}
+	body, err := ui.EditInEditor("")
+	return strings.TrimSpace(body), err
+}
+
+// formatPlaceholders lists placeholders with their defaults, e.g. "table, n=10"
+func formatPlaceholders(placeholders []snippet.Placeholder) string {
+	parts := make([]string, len(placeholders))
+	for i, p := range placeholders {
+		parts[i] = p.Name
+		if p.HasDefault {
+			parts[i] += "=" + p.Default
+		}
+	}
+	return strings.Join(parts, ", ")
+}
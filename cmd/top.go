@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/TFMV/trino-cli/ui"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+var topInterval time.Duration
+
+// topCmd watches the cluster's queries full screen.
+var topCmd = &cobra.Command{
+	Use:   "top",
+	Short: "Watch the cluster's running queries, like top",
+	Long: `Shows every user's queued and running queries on the --profile cluster full
+screen, with their elapsed and CPU time, input rows and memory, refreshed every
+--interval. Press T, C, R, M, U or S to sort by elapsed time, CPU, rows, memory,
+user or state (again to reverse), k to kill the selected query, r to refresh
+and q to quit.
+
+Memory is read from the coordinator's REST API, which profiles using Kerberos
+can't reach; it is shown as - for them.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !term.IsTerminal(int(os.Stdout.Fd())) {
+			fmt.Fprintln(os.Stderr, "Error: top needs a terminal; use `trino-cli query list` in scripts")
+			os.Exit(1)
+		}
+		if topInterval < time.Second {
+			fmt.Fprintln(os.Stderr, "Error: --interval must be at least 1s")
+			os.Exit(1)
+		}
+		if err := readPassword(profile); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if err := ui.RunTop(cmd.Context(), profile, topInterval); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	topCmd.Flags().DurationVar(&topInterval, "interval", 3*time.Second, "How often to refresh")
+
+	rootCmd.AddCommand(topCmd)
+}
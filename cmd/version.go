@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+
+	"github.com/TFMV/trino-cli/engine"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+// version is the release, set when building one with
+// -ldflags "-X github.com/TFMV/trino-cli/cmd.version=v1.2.0". Otherwise the
+// module version go install recorded is used, or dev.
+var version = ""
+
+var versionClientOnly bool
+
+// clientInfo is this build of trino-cli
+type clientInfo struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit,omitempty"`
+	Committed string `json:"committed,omitempty"` // Time of the commit, RFC 3339
+	Modified  bool   `json:"modified"`            // Built from a checkout with uncommitted changes
+	Go        string `json:"go"`
+	Platform  string `json:"platform"`
+}
+
+// versionCmd prints the versions of the client and the server.
+var versionCmd = &cobra.Command{
+	Use:   "version",
+	Short: "Show the version of trino-cli and of the Trino server",
+	Long: `Prints the version of trino-cli, the commit it was built from and the Go version
+and platform, then the version and environment of the --profile server, which
+it asks without credentials. A server that can't be reached is noted rather
+than failing the command; --client leaves the server out.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		client := readClientInfo()
+		// versions is the output of --json
+		type versions struct {
+			Client      clientInfo         `json:"client"`
+			Profile     string             `json:"profile,omitempty"`
+			Server      *engine.ServerInfo `json:"server,omitempty"`
+			ServerError string             `json:"server_error,omitempty"`
+		}
+		out := versions{Client: client}
+		if !versionClientOnly {
+			out.Profile = profile
+			server, err := engine.GetServerInfo(cmd.Context(), profile)
+			if err != nil {
+				logger.Warn("Error reading server version", zap.Error(err))
+				out.ServerError = err.Error()
+			}
+			out.Server = server
+		}
+
+		if jsonOutput {
+			printJSON(out)
+			return
+		}
+		commit := client.Commit
+		if len(commit) > 12 {
+			commit = commit[:12]
+		}
+		if client.Modified {
+			commit += " (modified)"
+		}
+		fmt.Println("Client:")
+		fmt.Printf("  Version:     %s\n", client.Version)
+		if client.Commit != "" {
+			fmt.Printf("  Commit:      %s\n", commit)
+			fmt.Printf("  Committed:   %s\n", client.Committed)
+		}
+		fmt.Printf("  Go:          %s %s\n", client.Go, client.Platform)
+		if versionClientOnly {
+			return
+		}
+
+		fmt.Printf("Server (profile %s, %s):\n", profile, engine.ServerAddress(profile))
+		if out.Server == nil {
+			fmt.Printf("  Unreachable: %s\n", out.ServerError)
+			return
+		}
+		fmt.Printf("  Version:     %s\n", out.Server.Version)
+		fmt.Printf("  Environment: %s\n", out.Server.Environment)
+		if out.Server.Uptime != "" {
+			fmt.Printf("  Uptime:      %s\n", out.Server.Uptime)
+		}
+		if out.Server.Starting {
+			fmt.Println("  State:       starting")
+		}
+	},
+}
+
+// readClientInfo gathers the version of this build, and the commit the Go
+// toolchain stamped it with when it was built from a checkout
+func readClientInfo() clientInfo {
+	client := clientInfo{Version: version, Go: runtime.Version(), Platform: runtime.GOOS + "/" + runtime.GOARCH}
+	if info, ok := debug.ReadBuildInfo(); ok {
+		if client.Version == "" && info.Main.Version != "(devel)" {
+			client.Version = info.Main.Version
+		}
+		for _, s := range info.Settings {
+			switch s.Key {
+			case "vcs.revision":
+				client.Commit = s.Value
+			case "vcs.time":
+				client.Committed = s.Value
+			case "vcs.modified":
+				client.Modified = s.Value == "true"
+			}
+		}
+	}
+	if client.Version == "" {
+		client.Version = "dev"
+	}
+	return client
+}
+
+func init() {
+	versionCmd.Flags().BoolVar(&versionClientOnly, "client", false, "Show the client's version only, without contacting the server")
+
+	rootCmd.AddCommand(versionCmd)
+}
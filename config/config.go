@@ -1,38 +1,334 @@
 package config
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"io/ioutil"
+	"net/url"
 	"os"
-
-	"gopkg.in/yaml.v3"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 )
 
 // Config holds the entire configuration for trino-cli.
 type Config struct {
-	Profiles map[string]Profile `yaml:"profiles"`
-	Defaults Defaults           `yaml:"defaults"`
+	Profiles     map[string]Profile `yaml:"profiles"`
+	Defaults     Defaults           `yaml:"defaults"`
+	History      History            `yaml:"history"`
+	Cache        Cache              `yaml:"cache"`
+	UI           UI                 `yaml:"ui"`
+	Autocomplete Autocomplete       `yaml:"autocomplete"`
+	SQLFormat    SQLFormat          `yaml:"sql_format"`
 }
 
 // Profile defines connection settings for a Trino profile.
 type Profile struct {
+	Extends string `yaml:"extends"` // Profile whose settings this one starts from, overriding only those it sets
+
 	Host    string `yaml:"host"`
 	Port    int    `yaml:"port"`
 	User    string `yaml:"user"`
 	Catalog string `yaml:"catalog"`
 	Schema  string `yaml:"schema"`
+
+	// Password authentication. Profiles with a password connect over HTTPS,
+	// the only way Trino accepts one.
+	Password       string `yaml:"password,omitempty"` // Kept in plain text; prefer password_env or prompt_password
+	PasswordEnv    string `yaml:"password_env"`       // Environment variable holding the password
+	PromptPassword bool   `yaml:"prompt_password"`    // Ask for the password on connect unless password_env, or $TRINO_PASSWORD, holds it
+	Keychain       bool   `yaml:"keychain"`           // Read the password and OAuth2 token from the OS keychain (trino-cli credential set)
+
+	// AccessToken is the OAuth2 token cached by trino-cli login, which
+	// connects over HTTPS in place of a password
+	AccessToken string `yaml:"-"`
+
+	Kerberos Kerberos `yaml:"kerberos"`
+
+	// Defaults overrides the top-level defaults for this profile. Once the
+	// config is loaded it holds both, merged.
+	Defaults Defaults `yaml:"defaults"`
+}
+
+// Kerberos configures SPNEGO authentication, over HTTPS, for clusters
+// secured with Kerberos. Credentials come from the keytab when one is
+// given, and otherwise from the ticket cache that kinit fills.
+type Kerberos struct {
+	Enabled     bool   `yaml:"enabled"`
+	Principal   string `yaml:"principal"`    // e.g. alice@EXAMPLE.COM; required with a keytab
+	Keytab      string `yaml:"keytab"`       // Keytab file; empty uses the ticket cache
+	TicketCache string `yaml:"ticket_cache"` // Credential cache; defaults to $KRB5CCNAME, then /tmp/krb5cc_<uid>
+	Config      string `yaml:"config"`       // krb5.conf; defaults to $KRB5_CONFIG, then /etc/krb5.conf
+	ServiceName string `yaml:"service_name"` // The coordinator's service name; defaults to trino
+}
+
+// ClientKey names the HTTP client that authenticates with these settings,
+// which the Trino driver looks up by name
+func (k Kerberos) ClientKey() string {
+	sum := sha256.Sum256([]byte(strings.Join([]string{k.Principal, k.Keytab, k.TicketCache, k.Config, k.ServiceName}, "\x00")))
+	return "kerberos-" + hex.EncodeToString(sum[:8])
 }
 
-// Defaults defines query defaults.
+// PasswordEnv is the environment variable read for the password of a
+// profile that prompts for one but names no variable of its own.
+const PasswordEnv = "TRINO_PASSWORD"
+
+// Defaults defines query defaults. Those under the top-level defaults key
+// apply to every profile, and a profile's own defaults override them.
 type Defaults struct {
-	MaxRows int    `yaml:"max_rows"`
-	Format  string `yaml:"format"`
+	Timeout string            `yaml:"timeout"`  // Query timeout, e.g. 5m; defaults to 30s, "off" waits for as long as a query runs
+	MaxRows int               `yaml:"max_rows"` // Rows a query returns before the rest are dropped; 0 means no limit
+	Format  string            `yaml:"format"`   // Output of -e and export: table (the default for -e), or an export format such as csv or json
+	Session map[string]string `yaml:"session"`  // Session properties set on every query, e.g. query_max_run_time: 1h
+}
+
+// QueryTimeout returns the query timeout, 0 when queries may run for as
+// long as they take, or fallback when none is set or it is not valid.
+func (d Defaults) QueryTimeout(fallback time.Duration) time.Duration {
+	switch d.Timeout {
+	case "":
+		return fallback
+	case "off":
+		return 0
+	}
+	timeout, err := time.ParseDuration(d.Timeout)
+	if err != nil || timeout < 0 {
+		return fallback
+	}
+	return timeout
+}
+
+// override returns d with the settings that o sets in place of its own.
+// Session properties are merged, o's winning.
+func (d Defaults) override(o Defaults) Defaults {
+	if o.Timeout != "" {
+		d.Timeout = o.Timeout
+	}
+	if o.MaxRows != 0 {
+		d.MaxRows = o.MaxRows
+	}
+	if o.Format != "" {
+		d.Format = o.Format
+	}
+	if len(o.Session) > 0 {
+		session := make(map[string]string, len(d.Session)+len(o.Session))
+		for k, v := range d.Session {
+			session[k] = v
+		}
+		for k, v := range o.Session {
+			session[k] = v
+		}
+		d.Session = session
+	}
+	return d
+}
+
+// History defines how much query history is kept. Zero values keep everything.
+type History struct {
+	MaxEntries int         `yaml:"max_entries"`
+	MaxAge     string      `yaml:"max_age"` // e.g. 90d, 12w, 720h
+	Sync       HistorySync `yaml:"sync"`
+}
+
+// HistorySync configures the remote store shared by `history sync`.
+type HistorySync struct {
+	URL      string `yaml:"url"`      // https://host/history.json or s3://bucket/key
+	Region   string `yaml:"region"`   // S3 region, defaults to us-east-1
+	Endpoint string `yaml:"endpoint"` // S3-compatible endpoint, e.g. http://localhost:9000
+	Token    string `yaml:"token"`    // Bearer token for HTTP stores
+}
+
+// Cache controls automatic caching of query results.
+type Cache struct {
+	Auto    bool `yaml:"auto"`     // Cache every successful result and link it to its history entry
+	MaxRows int  `yaml:"max_rows"` // Skip results larger than this; 0 means no limit
+}
+
+// Autocomplete limits the catalogs and schemas introspected for completion.
+// Patterns are globs matched against a catalog name, or against
+// catalog.schema when they contain a dot.
+type Autocomplete struct {
+	Include []string `yaml:"include"` // Introspect only matching catalogs and schemas; empty means all
+	Exclude []string `yaml:"exclude"` // Skip matching catalogs and schemas, e.g. system or "*.legacy_*"
+}
+
+// SQLFormat is the style `trino-cli fmt` and the shell's format key lay SQL
+// out in.
+type SQLFormat struct {
+	KeywordCase   string `yaml:"keyword_case"`   // upper (the default), lower, or preserve
+	Indent        int    `yaml:"indent"`         // Spaces per level; defaults to 2
+	LeadingCommas bool   `yaml:"leading_commas"` // Start list lines with their comma instead of ending them with it
+}
+
+// UI configures the interactive shell.
+type UI struct {
+	Theme            string      `yaml:"theme"`        // Color theme: dark (the default), light, solarized, or monochrome
+	NoHighlight      bool        `yaml:"no_highlight"` // Start with syntax highlighting turned off
+	Keymap           string      `yaml:"keymap"`       // Editor and result keys: default, vim, or emacs
+	Colors           ThemeColors `yaml:"colors"`       // Custom colors on top of the theme
+	Notify           Notify      `yaml:"notify"`
+	Watch            Watch       `yaml:"watch"`
+	Format           Format      `yaml:"format"`
+	MaxRows          int         `yaml:"max_rows"`           // Result rows rendered before L loads more; defaults to 10000, -1 renders all
+	PageSize         int         `yaml:"page_size"`          // Result rows per page; defaults to 500
+	ColumnWidth      int         `yaml:"column_width"`       // Width at which result values are cut off; defaults to 40
+	SchemaStats      bool        `yaml:"schema_stats"`       // Load SHOW STATS when a table is selected in the schema browser
+	SchemaTTL        string      `yaml:"schema_cache_ttl"`   // How long the schema browser reuses loaded metadata, e.g. 30m; defaults to 5m
+	SchemaHide       []string    `yaml:"schema_hide"`        // Catalogs, or catalog.schema globs, the schema browser hides along with system and information_schema
+	SchemaShowHidden bool        `yaml:"schema_show_hidden"` // Start the schema browser showing what it hides
+}
+
+// Format controls how result values are displayed in the interactive shell.
+// Date and time layouts use Go's reference time, Mon Jan 2 15:04:05 2006.
+type Format struct {
+	Null             string `yaml:"null"`               // Text shown for NULL; defaults to NULL
+	Date             string `yaml:"date"`               // Layout for DATE values; defaults to 2006-01-02
+	Time             string `yaml:"time"`               // Layout for TIME values; defaults to 15:04:05 with the column's fractional digits
+	Timestamp        string `yaml:"timestamp"`          // Layout for TIMESTAMP values; defaults to 2006-01-02 15:04:05 with the column's fractional digits
+	LeftAlignNumbers bool   `yaml:"left_align_numbers"` // Keep numeric columns left-aligned like text
+}
+
+// Watch configures watch mode, which re-runs a query on an interval.
+type Watch struct {
+	Interval    string `yaml:"interval"`     // Default interval for F5 and a bare \watch, e.g. 10s; defaults to 5s
+	NoHighlight bool   `yaml:"no_highlight"` // Don't highlight cells that changed since the previous run
+}
+
+// Notify configures alerts for long queries that finish while their tab is
+// not in view.
+type Notify struct {
+	After   string `yaml:"after"`   // Minimum query duration, e.g. 30s or 2m; defaults to 10s, "off" disables alerts
+	Desktop bool   `yaml:"desktop"` // Send a desktop notification as well as ringing the terminal bell
+}
+
+// ThemeColors overrides individual theme colors. Values are tview color
+// names (e.g. "orange") or hex codes (e.g. "#ff8700"); empty values keep the
+// theme's color.
+type ThemeColors struct {
+	// SQL tokens in the editor
+	Keyword string `yaml:"keyword"`
+	String  string `yaml:"string"`
+	Number  string `yaml:"number"`
+	Comment string `yaml:"comment"`
+
+	// Screen
+	Background    string `yaml:"background"`
+	Text          string `yaml:"text"`
+	Border        string `yaml:"border"`
+	Title         string `yaml:"title"`
+	Label         string `yaml:"label"`          // Prompt and form labels
+	Field         string `yaml:"field"`          // Editor and prompt background
+	Header        string `yaml:"header"`         // Result table header
+	Selection     string `yaml:"selection"`      // Selected cell or list item background
+	SelectionText string `yaml:"selection_text"` // Selected cell or list item text
+	StatusBar     string `yaml:"status_bar"`     // Status and tab bar background
+	Changed       string `yaml:"changed"`        // Cells that changed between watch-mode runs
+	Null          string `yaml:"null"`           // NULL values in results
+
+	// Schema tree
+	Catalog          string `yaml:"catalog"`
+	Schema           string `yaml:"schema"`
+	Table            string `yaml:"table"`
+	View             string `yaml:"view"`
+	MaterializedView string `yaml:"materialized_view"`
+	Column           string `yaml:"column"`
+}
+
+// DSN returns the Trino driver data source name for the profile.
+func (p Profile) DSN() string {
+	scheme, user, params := "http", url.User(p.User), ""
+	switch password := p.password(); {
+	case p.AccessToken != "":
+		scheme, params = "https", "&accessToken="+url.QueryEscape(p.AccessToken)
+	case p.Kerberos.Enabled:
+		scheme, params = "https", "&custom_client="+p.Kerberos.ClientKey()
+	case password != "":
+		// The driver only sends a password over HTTPS
+		scheme, user = "https", url.UserPassword(p.User, password)
+	}
+	if len(p.Defaults.Session) > 0 {
+		params += "&session_properties=" + url.QueryEscape(sessionProperties(p.Defaults.Session))
+	}
+	return fmt.Sprintf("%s://%s@%s:%d?catalog=%s&schema=%s%s",
+		scheme, user, p.Host, p.Port, url.QueryEscape(p.Catalog), url.QueryEscape(p.Schema), params)
+}
+
+// sessionProperties encodes session properties as the driver expects them,
+// name:value pairs separated by semicolons, sorted by name
+func sessionProperties(properties map[string]string) string {
+	pairs := make([]string, 0, len(properties))
+	for name, value := range properties {
+		pairs = append(pairs, name+":"+value)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ";")
+}
+
+// ServerURL returns the address of the profile's server, over HTTPS as
+// password and OAuth2 logins require
+func (p Profile) ServerURL() string {
+	return fmt.Sprintf("https://%s:%d", p.Host, p.Port)
+}
+
+// ServerKey identifies the user and server the profile connects as, e.g.
+// to key the caches of what was read from it
+func (p Profile) ServerKey() string {
+	return fmt.Sprintf("%s@%s:%d", p.User, strings.ToLower(p.Host), p.Port)
+}
+
+// password returns the profile's password from the config or, failing that,
+// from its environment variable. It is "" for profiles without one.
+func (p Profile) password() string {
+	switch {
+	case p.Password != "":
+		return p.Password
+	case p.PasswordEnv != "":
+		return os.Getenv(p.PasswordEnv)
+	case p.PromptPassword:
+		return os.Getenv(PasswordEnv)
+	}
+	return ""
+}
+
+// NeedsPassword reports whether the profile prompts for a password that
+// neither the config nor the environment supplies. Profiles logged in with
+// OAuth2 or using Kerberos need none.
+func (p Profile) NeedsPassword() bool {
+	return p.PromptPassword && p.AccessToken == "" && !p.Kerberos.Enabled && p.password() == ""
+}
+
+// SetAccessToken makes the named profile connect with an OAuth2 token for
+// the rest of the run.
+func SetAccessToken(name, token string) {
+	p, ok := AppConfig.Profiles[name]
+	if !ok {
+		return
+	}
+	p.AccessToken = token
+	AppConfig.Profiles[name] = p
+}
+
+// SetPassword keeps a password for the named profile for the rest of the
+// run, such as one typed in at a prompt. It is never written to disk.
+func SetPassword(name, password string) {
+	p, ok := AppConfig.Profiles[name]
+	if !ok {
+		return
+	}
+	p.Password = password
+	AppConfig.Profiles[name] = p
 }
 
 // AppConfig is the global configuration instance.
 var AppConfig Config
 
-// LoadConfig reads configuration from a YAML file.
+// LoadConfig reads configuration from a YAML file, replacing ${VAR}
+// references in its values with those of environment variables. A file
+// with problems, such as unknown keys or profiles without a host, is
+// rejected with an *Error listing them.
 func LoadConfig(path string) error {
 	if _, err := os.Stat(path); os.IsNotExist(err) {
 		return fmt.Errorf("config file does not exist: %s", path)
@@ -41,5 +337,77 @@ func LoadConfig(path string) error {
 	if err != nil {
 		return err
 	}
-	return yaml.Unmarshal(data, &AppConfig)
+	cfg, err := parse(path, data)
+	if err != nil {
+		return err
+	}
+	AppConfig = cfg
+	return nil
+}
+
+var envReference = regexp.MustCompile(`\$?\$\{([A-Za-z_][A-Za-z0-9_]*)(?::-([^}]*))?\}`)
+
+// expandEnv replaces ${VAR} with the value of the environment variable VAR,
+// and ${VAR:-default} with default when VAR is unset or empty. $${VAR}
+// stands for a literal ${VAR}.
+func expandEnv(s string) string {
+	return envReference.ReplaceAllStringFunc(s, func(ref string) string {
+		if strings.HasPrefix(ref, "$$") {
+			return ref[1:]
+		}
+		m := envReference.FindStringSubmatch(ref)
+		if value := os.Getenv(m[1]); value != "" {
+			return value
+		}
+		return m[2]
+	})
+}
+
+// envOverrides are the environment variables that override the connection
+// settings of the profile in use
+var envOverrides = []struct {
+	name  string
+	apply func(p *Profile, value string) error
+}{
+	{"TRINO_CLI_HOST", func(p *Profile, v string) error { p.Host = v; return nil }},
+	{"TRINO_CLI_PORT", func(p *Profile, v string) (err error) {
+		if p.Port, err = strconv.Atoi(v); err != nil {
+			return fmt.Errorf("invalid TRINO_CLI_PORT %q", v)
+		}
+		return nil
+	}},
+	{"TRINO_CLI_USER", func(p *Profile, v string) error { p.User = v; return nil }},
+	{"TRINO_CLI_CATALOG", func(p *Profile, v string) error { p.Catalog = v; return nil }},
+	{"TRINO_CLI_SCHEMA", func(p *Profile, v string) error { p.Schema = v; return nil }},
+	{"TRINO_CLI_PASSWORD", func(p *Profile, v string) error { p.Password = v; return nil }},
+}
+
+// ApplyEnv overrides the connection settings of the named profile with
+// TRINO_CLI_HOST, TRINO_CLI_PORT, TRINO_CLI_USER, TRINO_CLI_CATALOG,
+// TRINO_CLI_SCHEMA and TRINO_CLI_PASSWORD. A profile missing from the config
+// file is made from them alone, on port 8080 unless TRINO_CLI_PORT says
+// otherwise.
+func ApplyEnv(name string) error {
+	p, exists := AppConfig.Profiles[name]
+	if !exists {
+		p.Port = 8080
+		p.Defaults = AppConfig.Defaults
+	}
+	changed := false
+	for _, o := range envOverrides {
+		if value := os.Getenv(o.name); value != "" {
+			if err := o.apply(&p, value); err != nil {
+				return err
+			}
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+	if AppConfig.Profiles == nil {
+		AppConfig.Profiles = make(map[string]Profile)
+	}
+	AppConfig.Profiles[name] = p
+	return nil
 }
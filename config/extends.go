@@ -0,0 +1,97 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// resolveExtends fills in each profile that extends another with the
+// settings it doesn't set itself, taken from its base after that base's own
+// extends is resolved. Nested sections such as kerberos are merged key by
+// key. It reports bases that don't exist and profiles that extend
+// themselves.
+func resolveExtends(root *yaml.Node, profiles map[string]Profile) []string {
+	nodes := mappingValue(root, "profiles")
+	if nodes == nil || nodes.Kind != yaml.MappingNode {
+		return nil
+	}
+	byName := make(map[string]*yaml.Node)
+	var names []string
+	for i := 0; i+1 < len(nodes.Content); i += 2 {
+		name := nodes.Content[i].Value
+		byName[name] = nodes.Content[i+1]
+		names = append(names, name)
+	}
+
+	var problems []string
+	resolved := make(map[string]*yaml.Node)
+	var resolve func(name string, chain []string) *yaml.Node
+	resolve = func(name string, chain []string) *yaml.Node {
+		node := byName[name]
+		base := mappingValue(node, "extends")
+		for i, seen := range chain {
+			if seen == name {
+				problems = append(problems, fmt.Sprintf("line %d: profile %q extends itself through %s", base.Line, name, strings.Join(append(chain[i:], name), " -> ")))
+				return node
+			}
+		}
+		if merged, ok := resolved[name]; ok {
+			return merged
+		}
+		merged := node
+		switch {
+		case base == nil || base.Value == "":
+		case byName[base.Value] == nil:
+			problem := fmt.Sprintf("line %d: profile %q extends %q, which does not exist", base.Line, name, base.Value)
+			if guess := closest(base.Value, names); guess != "" && guess != name {
+				problem += fmt.Sprintf(" (did you mean %q?)", guess)
+			}
+			problems = append(problems, problem)
+		default:
+			merged = mergeNodes(resolve(base.Value, append(chain, name)), node)
+		}
+		resolved[name] = merged
+		return merged
+	}
+
+	sort.Strings(names)
+	for _, name := range names {
+		node := resolve(name, nil)
+		if node == byName[name] {
+			continue
+		}
+		var p Profile
+		_ = node.Decode(&p) // Type errors were reported when the file was decoded
+		p.Extends = profiles[name].Extends
+		profiles[name] = p
+	}
+	return problems
+}
+
+// mergeNodes returns over laid on top of base. Mappings are merged key by
+// key, and any other value in over replaces the one in base.
+func mergeNodes(base, over *yaml.Node) *yaml.Node {
+	if base == nil || base.Kind != yaml.MappingNode || over.Kind != yaml.MappingNode {
+		return over
+	}
+	merged := *base
+	merged.Content = append([]*yaml.Node(nil), base.Content...)
+	for i := 0; i+1 < len(over.Content); i += 2 {
+		key, value := over.Content[i], over.Content[i+1]
+		found := false
+		for j := 0; j+1 < len(merged.Content); j += 2 {
+			if merged.Content[j].Value == key.Value {
+				merged.Content[j+1] = mergeNodes(merged.Content[j+1], value)
+				found = true
+				break
+			}
+		}
+		if !found {
+			merged.Content = append(merged.Content, key, value)
+		}
+	}
+	return &merged
+}
@@ -0,0 +1,91 @@
+package config
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseExtends(t *testing.T) {
+	data := `profiles:
+  default:
+    host: trino.corp
+    port: 8443
+    user: etl
+    catalog: hive
+    prompt_password: true
+    kerberos:
+      enabled: true
+      principal: etl@CORP
+  sales:
+    extends: default
+    schema: sales
+  sales_dev:
+    extends: sales
+    host: trino-dev.corp
+    prompt_password: false
+    kerberos:
+      principal: dev@CORP
+`
+	cfg, err := parse("test.yaml", []byte(data))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	sales := cfg.Profiles["sales"]
+	if sales.Host != "trino.corp" || sales.Port != 8443 || sales.User != "etl" || sales.Catalog != "hive" || sales.Schema != "sales" || !sales.PromptPassword {
+		t.Errorf("sales = %+v, want the settings of default with schema sales", sales)
+	}
+	dev := cfg.Profiles["sales_dev"]
+	if dev.Host != "trino-dev.corp" || dev.Schema != "sales" || dev.Catalog != "hive" || dev.PromptPassword {
+		t.Errorf("sales_dev = %+v, want sales with its own host and prompt_password", dev)
+	}
+	if !dev.Kerberos.Enabled || dev.Kerberos.Principal != "dev@CORP" {
+		t.Errorf("sales_dev kerberos = %+v, want enabled kept and principal overridden", dev.Kerberos)
+	}
+	if dev.Extends != "sales" {
+		t.Errorf("sales_dev extends %q, want sales", dev.Extends)
+	}
+	if base := cfg.Profiles["default"]; base.Schema != "" {
+		t.Errorf("default = %+v, want it unchanged", base)
+	}
+}
+
+func TestParseExtendsProblems(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{
+			name: "unknown base",
+			data: "profiles:\n  base:\n    host: h\n    port: 8080\n    user: me\n  dev:\n    extends: bsae\n",
+			want: `line 7: profile "dev" extends "bsae", which does not exist (did you mean "base"?)`,
+		},
+		{
+			name: "cycle",
+			data: "profiles:\n  a:\n    extends: b\n    host: h\n    port: 8080\n    user: me\n  b:\n    extends: a\n",
+			want: `line 3: profile "a" extends itself through a -> b -> a`,
+		},
+		{
+			name: "missing host after inheritance",
+			data: "profiles:\n  base:\n    port: 8080\n    user: me\n  dev:\n    extends: base\n",
+			want: `line 5: profile "dev" needs host`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parse("test.yaml", []byte(tt.data))
+			var cfgErr *Error
+			if !errors.As(err, &cfgErr) {
+				t.Fatalf("parse error = %v, want *Error", err)
+			}
+			found := false
+			for _, problem := range cfgErr.Problems {
+				found = found || strings.HasPrefix(problem, tt.want)
+			}
+			if !found {
+				t.Errorf("problems = %q, want %q", cfgErr.Problems, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,310 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Error lists the problems found in a config file, each with its line
+type Error struct {
+	Path     string
+	Problems []string
+}
+
+func (e *Error) Error() string {
+	noun := "problems"
+	if len(e.Problems) == 1 {
+		noun = "problem"
+	}
+	return fmt.Sprintf("config file %s has %d %s:\n  %s", e.Path, len(e.Problems), noun, strings.Join(e.Problems, "\n  "))
+}
+
+// parse decodes a config file, rejecting malformed YAML, values of the wrong
+// type, keys the config does not have, and profiles that can't connect.
+// ${VAR} references in values are replaced from the environment, values
+// tagged !vault are decrypted, profiles that extend another are filled in
+// from it, and every profile's defaults from the top-level ones.
+func parse(path string, data []byte) (Config, error) {
+	var cfg Config
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	err := dec.Decode(&cfg)
+	if errors.Is(err, io.EOF) {
+		return cfg, nil // An empty file
+	}
+
+	// The file as written only tells of unknown keys; the types of values
+	// are checked once references in them are replaced
+	var typeErr *yaml.TypeError
+	var problems []string
+	switch {
+	case errors.As(err, &typeErr):
+		for _, msg := range typeErr.Errors {
+			if unknownField.MatchString(msg) {
+				problems = append(problems, explainTypeError(msg))
+			}
+		}
+	case err != nil:
+		return cfg, &Error{Path: path, Problems: []string{strings.TrimPrefix(err.Error(), "yaml: ")}}
+	}
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return cfg, &Error{Path: path, Problems: []string{strings.TrimPrefix(err.Error(), "yaml: ")}}
+	}
+	interpolateEnv(&root)
+	_, vaultProblems := decryptVault(&root)
+	problems = append(problems, vaultProblems...)
+
+	cfg = Config{}
+	if err := root.Decode(&cfg); errors.As(err, &typeErr) {
+		problems = append(problems, typeErr.Errors...)
+	}
+	problems = append(problems, resolveExtends(&root, cfg.Profiles)...)
+	problems = append(problems, checkProfiles(&root, cfg.Profiles)...)
+	problems = append(problems, checkTimeouts(&root)...)
+
+	for name, p := range cfg.Profiles {
+		p.Defaults = cfg.Defaults.override(p.Defaults)
+		cfg.Profiles[name] = p
+	}
+	if len(problems) > 0 {
+		return cfg, &Error{Path: path, Problems: problems}
+	}
+	return cfg, nil
+}
+
+// interpolateEnv replaces ${VAR} references in the values under node, as
+// expandEnv does. Replacing them in the parsed tree rather than the text
+// keeps values holding YAML syntax, such as ": " or a leading "*" in a
+// password, intact, and the lines of the file where they were.
+func interpolateEnv(node *yaml.Node) {
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 1; i < len(node.Content); i += 2 {
+			interpolateEnv(node.Content[i])
+		}
+	case yaml.ScalarNode:
+		if node.Tag == vaultTag {
+			return
+		}
+		value := expandEnv(node.Value)
+		if value == node.Value {
+			return
+		}
+		node.Value = value
+		// An unquoted reference takes the type of its value, so that
+		// port: ${PORT} is a number; anything else stays a string
+		if node.Style == 0 {
+			switch tag := (&yaml.Node{Kind: yaml.ScalarNode, Value: value}).ShortTag(); tag {
+			case "!!int", "!!float", "!!bool":
+				node.Tag = tag
+			default:
+				node.Tag = "!!str"
+			}
+		}
+	default:
+		for _, child := range node.Content {
+			interpolateEnv(child)
+		}
+	}
+}
+
+var unknownField = regexp.MustCompile(`^(line \d+): field (\S+) not found in type (\S+)$`)
+
+// explainTypeError rewords yaml's message for an unknown key, naming where
+// the key was found and the known key it was likely meant to be
+func explainTypeError(msg string) string {
+	m := unknownField.FindStringSubmatch(msg)
+	if m == nil {
+		return msg
+	}
+	section, ok := sections()[m[3]]
+	if !ok {
+		return fmt.Sprintf("%s: unknown key %q", m[1], m[2])
+	}
+	explained := fmt.Sprintf("%s: unknown key %q", m[1], m[2])
+	if section.path != "" {
+		explained += " under " + section.path
+	}
+	if guess := closest(m[2], section.keys); guess != "" {
+		explained += fmt.Sprintf(" (did you mean %q?)", guess)
+	}
+	return explained
+}
+
+// section is where a type of the config appears, and the keys it takes
+type section struct {
+	path string
+	keys []string
+}
+
+// sections maps the name of each struct type in Config, as yaml reports it,
+// to its place in the file
+func sections() map[string]section {
+	found := make(map[string]section)
+	var walk func(t reflect.Type, path string)
+	walk = func(t reflect.Type, path string) {
+		for t.Kind() == reflect.Map || t.Kind() == reflect.Slice || t.Kind() == reflect.Pointer {
+			if t.Kind() == reflect.Map {
+				path += ".<name>"
+			}
+			t = t.Elem()
+		}
+		if t.Kind() != reflect.Struct {
+			return
+		}
+		if _, seen := found[t.String()]; seen {
+			return
+		}
+		s := section{path: strings.TrimPrefix(path, ".")}
+		found[t.String()] = s
+		for i := 0; i < t.NumField(); i++ {
+			name, _, _ := strings.Cut(t.Field(i).Tag.Get("yaml"), ",")
+			if name == "" || name == "-" {
+				continue
+			}
+			s.keys = append(s.keys, name)
+			walk(t.Field(i).Type, path+"."+name)
+		}
+		found[t.String()] = s
+	}
+	walk(reflect.TypeOf(Config{}), "")
+	return found
+}
+
+// closest returns the key nearest to a misspelled one, or "" if none is
+// within two edits
+func closest(key string, keys []string) string {
+	best, bestDistance := "", 3
+	for _, k := range keys {
+		if d := editDistance(strings.ToLower(key), k); d < bestDistance {
+			best, bestDistance = k, d
+		}
+	}
+	return best
+}
+
+// editDistance is the Levenshtein distance between a and b
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
+
+// checkProfiles reports the profiles that lack a host, port or user, unless
+// the TRINO_CLI_ variable for it is set, and ports out of range. A port of
+// the wrong type was already reported by the decoder.
+func checkProfiles(root *yaml.Node, profiles map[string]Profile) []string {
+	nodes := mappingValue(root, "profiles")
+	if nodes == nil || nodes.Kind != yaml.MappingNode {
+		return nil
+	}
+	var problems []string
+	for i := 0; i+1 < len(nodes.Content); i += 2 {
+		name, node := nodes.Content[i].Value, nodes.Content[i+1]
+		p := profiles[name]
+		var missing []string
+		if p.Host == "" && os.Getenv("TRINO_CLI_HOST") == "" {
+			missing = append(missing, "host")
+		}
+		if p.Port == 0 && mappingValue(node, "port") == nil && os.Getenv("TRINO_CLI_PORT") == "" {
+			missing = append(missing, "port")
+		}
+		if p.User == "" && os.Getenv("TRINO_CLI_USER") == "" {
+			missing = append(missing, "user")
+		}
+		if len(missing) > 0 {
+			problems = append(problems, fmt.Sprintf("line %d: profile %q needs %s", nodes.Content[i].Line, name, strings.Join(missing, ", ")))
+		}
+		if p.Port < 0 || p.Port > 65535 {
+			line := nodes.Content[i].Line
+			if port := mappingValue(node, "port"); port != nil {
+				line = port.Line
+			}
+			problems = append(problems, fmt.Sprintf("line %d: profile %q has port %d, which is not between 1 and 65535", line, name, p.Port))
+		}
+	}
+	return problems
+}
+
+// checkTimeouts reports timeouts under defaults, at the top level or in a
+// profile, that are neither a duration nor "off"
+func checkTimeouts(root *yaml.Node) []string {
+	var problems []string
+	check := func(where string, node *yaml.Node) {
+		timeout := mappingValue(node, "timeout")
+		if timeout == nil || timeout.Value == "" || timeout.Value == "off" {
+			return
+		}
+		if d, err := time.ParseDuration(timeout.Value); err != nil || d < 0 {
+			problems = append(problems, fmt.Sprintf("line %d: %s timeout %q is not a duration such as 30s or 5m, or off", timeout.Line, where, timeout.Value))
+		}
+	}
+	if defaults := mappingValue(root, "defaults"); defaults != nil {
+		check("the", defaults)
+	}
+	if nodes := mappingValue(root, "profiles"); nodes != nil && nodes.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(nodes.Content); i += 2 {
+			if defaults := mappingValue(nodes.Content[i+1], "defaults"); defaults != nil {
+				check(fmt.Sprintf("profile %q", nodes.Content[i].Value), defaults)
+			}
+		}
+	}
+	return problems
+}
+
+// mappingValue returns the value of key in a mapping node, or in the
+// mapping of a document node
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// UnknownProfile explains that the config has no profile called name,
+// listing the ones it has
+func UnknownProfile(name string) error {
+	names := make([]string, 0, len(AppConfig.Profiles))
+	for n := range AppConfig.Profiles {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	if len(names) == 0 {
+		return fmt.Errorf("profile %q not found: the config has no profiles; add one under profiles: or set TRINO_CLI_HOST", name)
+	}
+	return fmt.Errorf("profile %q not found; the config has %s", name, strings.Join(names, ", "))
+}
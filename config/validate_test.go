@@ -0,0 +1,101 @@
+package config
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseProblems(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want []string
+	}{
+		{
+			name: "misspelled key",
+			data: "profiles:\n  dev:\n    hots: localhost\n    port: 8080\n    user: me\n",
+			want: []string{`line 3: unknown key "hots" under profiles.<name> (did you mean "host"?)`, `line 2: profile "dev" needs host`},
+		},
+		{
+			name: "unknown nested key",
+			data: "profiles:\n  dev:\n    host: h\n    port: 8080\n    user: me\n    kerberos:\n      enabeld: true\n",
+			want: []string{`line 7: unknown key "enabeld" under profiles.<name>.kerberos (did you mean "enabled"?)`},
+		},
+		{
+			name: "wrong type",
+			data: "profiles:\n  dev:\n    host: h\n    port: eighty\n    user: me\n",
+			want: []string{"line 4: cannot unmarshal !!str `eighty` into int"},
+		},
+		{
+			name: "missing fields",
+			data: "profiles:\n  dev:\n    catalog: hive\n",
+			want: []string{`line 2: profile "dev" needs host, port, user`},
+		},
+		{
+			name: "port out of range",
+			data: "profiles:\n  dev:\n    host: h\n    port: 70000\n    user: me\n",
+			want: []string{`line 4: profile "dev" has port 70000, which is not between 1 and 65535`},
+		},
+		{
+			name: "malformed",
+			data: "profiles:\n  dev:\n    host: [h\n",
+			want: []string{"line 2: did not find expected ',' or ']'"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parse("test.yaml", []byte(tt.data))
+			var cfgErr *Error
+			if !errors.As(err, &cfgErr) {
+				t.Fatalf("parse error = %v, want *Error", err)
+			}
+			if len(cfgErr.Problems) != len(tt.want) {
+				t.Fatalf("problems = %q, want %q", cfgErr.Problems, tt.want)
+			}
+			for i, want := range tt.want {
+				if !strings.HasPrefix(cfgErr.Problems[i], want) {
+					t.Errorf("problem %d = %q, want %q", i, cfgErr.Problems[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestParseValid(t *testing.T) {
+	for _, data := range []string{"", "# nothing yet\n", "profiles:\n  dev:\n    host: h\n    port: 8080\n    user: me\n"} {
+		if _, err := parse("test.yaml", []byte(data)); err != nil {
+			t.Errorf("parse(%q) = %v, want no error", data, err)
+		}
+	}
+}
+
+func TestParseMissingFieldsFromEnv(t *testing.T) {
+	t.Setenv("TRINO_CLI_HOST", "trino.ci")
+	t.Setenv("TRINO_CLI_USER", "ci")
+	_, err := parse("test.yaml", []byte("profiles:\n  ci:\n    port: 8080\n"))
+	if err != nil {
+		t.Errorf("parse = %v, want the variables to supply host and user", err)
+	}
+}
+
+func TestErrorMessage(t *testing.T) {
+	err := &Error{Path: "c.yaml", Problems: []string{"line 1: a", "line 2: b"}}
+	if got, want := err.Error(), "config file c.yaml has 2 problems:\n  line 1: a\n  line 2: b"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+}
+
+func TestUnknownProfile(t *testing.T) {
+	saved := AppConfig
+	defer func() { AppConfig = saved }()
+
+	AppConfig = Config{}
+	if err := UnknownProfile("prod"); !strings.Contains(err.Error(), "no profiles") {
+		t.Errorf("UnknownProfile without profiles = %v", err)
+	}
+	AppConfig = Config{Profiles: map[string]Profile{"dev": {}, "ci": {}}}
+	if err := UnknownProfile("prod"); !strings.HasSuffix(err.Error(), "the config has ci, dev") {
+		t.Errorf("UnknownProfile = %v, want the profiles listed", err)
+	}
+}
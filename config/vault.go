@@ -0,0 +1,162 @@
+package config
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/TFMV/trino-cli/keychain"
+	"gopkg.in/yaml.v3"
+)
+
+// String values tagged !vault in the config file are encrypted with
+// AES-256-GCM under the vault key, and hold the base64 of the nonce followed
+// by the ciphertext. trino-cli config encrypt makes them.
+const vaultTag = "!vault"
+
+// VaultKeyEnv is the environment variable holding the vault key, in base64.
+// It is read before the key file and the keychain.
+const VaultKeyEnv = "TRINO_CLI_VAULT_KEY"
+
+// VaultKeychainName names the vault key in the OS keychain
+const VaultKeychainName = "vault-key"
+
+// vaultKeySize is the length of a vault key, for AES-256
+const vaultKeySize = 32
+
+// vaultKeychainGet reads the vault key from the keychain. Tests replace it.
+var vaultKeychainGet = keychain.Get
+
+// errNoVaultKey is returned when none of the vault key's sources has it
+var errNoVaultKey = fmt.Errorf("no vault key; set $%s, or create one with trino-cli config keygen", VaultKeyEnv)
+
+// NewVaultKey returns a random vault key
+func NewVaultKey() ([]byte, error) {
+	key := make([]byte, vaultKeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("failed to generate vault key: %w", err)
+	}
+	return key, nil
+}
+
+// VaultKeyPath returns the file the vault key is kept in,
+// ~/.trino-cli/vault.key
+func VaultKeyPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".trino-cli", "vault.key"), nil
+}
+
+// LoadVaultKey returns the vault key from $TRINO_CLI_VAULT_KEY, the key
+// file, or the keychain, in that order
+func LoadVaultKey() ([]byte, error) {
+	encoded := os.Getenv(VaultKeyEnv)
+	source := "$" + VaultKeyEnv
+	if encoded == "" {
+		path, err := VaultKeyPath()
+		if err != nil {
+			return nil, err
+		}
+		data, err := os.ReadFile(path)
+		switch {
+		case err == nil:
+			encoded, source = string(data), path
+		case !errors.Is(err, os.ErrNotExist):
+			return nil, fmt.Errorf("failed to read vault key: %w", err)
+		}
+	}
+	if encoded == "" {
+		secret, err := vaultKeychainGet(VaultKeychainName)
+		if err != nil {
+			return nil, errNoVaultKey
+		}
+		encoded, source = secret, "the keychain"
+	}
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil || len(key) != vaultKeySize {
+		return nil, fmt.Errorf("vault key in %s is not a base64 %d-byte key", source, vaultKeySize)
+	}
+	return key, nil
+}
+
+// Encrypt encrypts a value for the config file under key
+func Encrypt(key []byte, plaintext string) (string, error) {
+	gcm, err := newVaultGCM(key)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	sealed := gcm.Seal(nonce, nonce, []byte(plaintext), []byte(vaultTag))
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt decrypts a value that Encrypt made under key
+func Decrypt(key []byte, ciphertext string) (string, error) {
+	gcm, err := newVaultGCM(key)
+	if err != nil {
+		return "", err
+	}
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimSpace(ciphertext))
+	if err != nil || len(sealed) < gcm.NonceSize() {
+		return "", fmt.Errorf("not an encrypted value")
+	}
+	plaintext, err := gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], []byte(vaultTag))
+	if err != nil {
+		return "", fmt.Errorf("value was encrypted with another vault key, or is corrupted")
+	}
+	return string(plaintext), nil
+}
+
+func newVaultGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid vault key: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// decryptVault replaces the !vault values under node with their plaintext,
+// reading the vault key only if there are any. It reports whether it
+// replaced any, and the values it could not decrypt.
+func decryptVault(node *yaml.Node) (bool, []string) {
+	var tagged []*yaml.Node
+	var walk func(n *yaml.Node)
+	walk = func(n *yaml.Node) {
+		if n.Kind == yaml.ScalarNode && n.Tag == vaultTag {
+			tagged = append(tagged, n)
+		}
+		for _, child := range n.Content {
+			walk(child)
+		}
+	}
+	walk(node)
+	if len(tagged) == 0 {
+		return false, nil
+	}
+
+	key, err := LoadVaultKey()
+	if err != nil {
+		return false, []string{fmt.Sprintf("line %d: encrypted value can't be read: %v", tagged[0].Line, err)}
+	}
+	var problems []string
+	for _, n := range tagged {
+		plaintext, err := Decrypt(key, n.Value)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("line %d: encrypted value can't be read: %v", n.Line, err))
+			continue
+		}
+		n.Tag, n.Value, n.Style = "!!str", plaintext, 0
+	}
+	return true, problems
+}
@@ -0,0 +1,112 @@
+package config
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/TFMV/trino-cli/keychain"
+)
+
+// useVaultKey points the vault key sources at a fresh key in the environment,
+// with no key file or keychain entry, and returns it
+func useVaultKey(t *testing.T) []byte {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	saved := vaultKeychainGet
+	t.Cleanup(func() { vaultKeychainGet = saved })
+	vaultKeychainGet = func(string) (string, error) { return "", keychain.ErrNotFound }
+
+	key, err := NewVaultKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(VaultKeyEnv, base64.StdEncoding.EncodeToString(key))
+	return key
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	key := useVaultKey(t)
+	encrypted, err := Encrypt(key, "s3cret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(encrypted, "s3cret") {
+		t.Fatalf("encrypted value %q holds the plaintext", encrypted)
+	}
+	if got, err := Decrypt(key, encrypted); err != nil || got != "s3cret" {
+		t.Errorf("Decrypt = %q, %v; want s3cret", got, err)
+	}
+
+	other, _ := NewVaultKey()
+	if _, err := Decrypt(other, encrypted); err == nil {
+		t.Error("expected decrypting under another key to fail")
+	}
+}
+
+func TestParseVault(t *testing.T) {
+	key := useVaultKey(t)
+	password, _ := Encrypt(key, "hunter2")
+	token, _ := Encrypt(key, "sync-token")
+	data := "profiles:\n  prod:\n    host: trino.prod\n    port: 443\n    user: etl\n    password: !vault " + password +
+		"\nhistory:\n  sync:\n    url: https://example.com/history.json\n    token: !vault " + token + "\n"
+
+	cfg, err := parse("test.yaml", []byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := cfg.Profiles["prod"].Password; got != "hunter2" {
+		t.Errorf("password = %q, want it decrypted", got)
+	}
+	if got := cfg.History.Sync.Token; got != "sync-token" {
+		t.Errorf("sync token = %q, want it decrypted", got)
+	}
+	if got := cfg.Profiles["prod"].Host; got != "trino.prod" {
+		t.Errorf("host = %q, want plain values kept", got)
+	}
+}
+
+func TestParseVaultProblems(t *testing.T) {
+	useVaultKey(t)
+	other, _ := NewVaultKey()
+	wrong, _ := Encrypt(other, "hunter2")
+	data := "profiles:\n  prod:\n    host: trino.prod\n    port: 443\n    user: etl\n    password: !vault " + wrong + "\n"
+
+	_, err := parse("test.yaml", []byte(data))
+	if err == nil || !strings.Contains(err.Error(), "line 6: encrypted value can't be read: value was encrypted with another vault key") {
+		t.Errorf("parse with a value under another key = %v", err)
+	}
+
+	t.Setenv(VaultKeyEnv, "")
+	_, err = parse("test.yaml", []byte(data))
+	if err == nil || !strings.Contains(err.Error(), "line 6: encrypted value can't be read: no vault key") {
+		t.Errorf("parse without a vault key = %v", err)
+	}
+}
+
+func TestLoadVaultKeyFile(t *testing.T) {
+	key := useVaultKey(t)
+	t.Setenv(VaultKeyEnv, "")
+	path, err := VaultKeyPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(base64.StdEncoding.EncodeToString(key)+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := LoadVaultKey(); err != nil || string(got) != string(key) {
+		t.Errorf("LoadVaultKey = %x, %v; want the key from %s", got, err, path)
+	}
+
+	if err := os.WriteFile(path, []byte("short"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadVaultKey(); err == nil || !strings.Contains(err.Error(), path) {
+		t.Errorf("LoadVaultKey with a bad key = %v, want it to name %s", err, path)
+	}
+}
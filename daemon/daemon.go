@@ -0,0 +1,389 @@
+package daemon
+
+import (
+	"context"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"net"
+	"net/rpc"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/TFMV/trino-cli/autocomplete"
+	"github.com/TFMV/trino-cli/config"
+	"github.com/TFMV/trino-cli/engine"
+	"github.com/TFMV/trino-cli/history"
+	"go.uber.org/zap"
+)
+
+// ErrNotRunning is returned by Dial when no daemon is listening
+var ErrNotRunning = errors.New("daemon is not running")
+
+// ErrProfileMismatch is returned by Client.Execute when the daemon would
+// connect with other settings for the profile than the client, because the
+// two read different config files or environments
+var ErrProfileMismatch = errors.New("daemon has other settings for the profile")
+
+// schemaRefreshInterval is how often the daemon refreshes the autocomplete
+// metadata cache for each profile it has served.
+const schemaRefreshInterval = 10 * time.Minute
+
+// cancelTimeout bounds how long a client waits for the daemon to acknowledge
+// a cancelled query
+const cancelTimeout = time.Second
+
+// earlyCancelTTL is how long the daemon remembers a cancellation that
+// arrived before its query started
+const earlyCancelTTL = time.Minute
+
+func init() {
+	// Result rows travel as []interface{}, so gob must know the concrete
+	// types the Trino driver produces.
+	gob.Register(time.Time{})
+	gob.Register([]interface{}{})
+	gob.Register(map[string]interface{}{})
+}
+
+// SocketPath returns the path of the daemon's unix socket
+func SocketPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, ".trino-cli", "daemon.sock"), nil
+}
+
+// ExecuteArgs are the arguments of the Daemon.Execute call
+type ExecuteArgs struct {
+	Query     string
+	Profile   string
+	Settings  string // engine.ProfileFingerprint of the profile in the client
+	ParentID  string // History entry the query was derived from, if any
+	RequestID string // Names the call for Daemon.Cancel
+}
+
+// CancelArgs are the arguments of the Daemon.Cancel call
+type CancelArgs struct {
+	RequestID string
+}
+
+// StatusReply describes a running daemon
+type StatusReply struct {
+	PID      int       `json:"pid"`
+	Started  time.Time `json:"started"`
+	Queries  int       `json:"queries"`
+	Profiles []string  `json:"profiles"`
+}
+
+// Service is the RPC service exposed over the daemon socket
+type Service struct {
+	ctx      context.Context // Cancelled when the daemon stops
+	logger   *zap.Logger
+	started  time.Time
+	shutdown chan struct{}
+
+	mu        sync.Mutex
+	queries   int
+	profiles  map[string]bool
+	running   map[string]context.CancelFunc // By request ID
+	cancelled map[string]time.Time          // Cancellations that came before their query
+}
+
+// newService returns a service whose queries run until ctx is cancelled
+func newService(ctx context.Context, logger *zap.Logger) *Service {
+	return &Service{
+		ctx:       ctx,
+		logger:    logger,
+		started:   time.Now(),
+		shutdown:  make(chan struct{}),
+		profiles:  make(map[string]bool),
+		running:   make(map[string]context.CancelFunc),
+		cancelled: make(map[string]time.Time),
+	}
+}
+
+// Execute runs a query on a warm connection for the requested profile
+func (s *Service) Execute(args ExecuteArgs, reply *engine.QueryResult) error {
+	if args.Settings != engine.ProfileFingerprint(args.Profile) {
+		return ErrProfileMismatch
+	}
+	s.warmProfile(args.Profile)
+
+	ctx, done := s.track(args.RequestID)
+	defer done()
+	if args.ParentID != "" {
+		ctx = history.WithParentID(ctx, args.ParentID)
+	}
+
+	// Queries of unrelated clients share the profile's pool, so one
+	// client's USE or SET SESSION must not stay on it
+	result, err := engine.ExecuteIsolated(ctx, args.Query, args.Profile)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.queries++
+	s.mu.Unlock()
+
+	*reply = *result
+	return nil
+}
+
+// Cancel stops the query of a request. net/rpc runs calls concurrently, so
+// the cancellation may arrive before the query starts; it is then applied
+// as soon as the query does.
+func (s *Service) Cancel(args CancelArgs, _ *struct{}) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if cancel, ok := s.running[args.RequestID]; ok {
+		cancel()
+		return nil
+	}
+	now := time.Now()
+	for id, at := range s.cancelled {
+		if now.Sub(at) > earlyCancelTTL {
+			delete(s.cancelled, id)
+		}
+	}
+	s.cancelled[args.RequestID] = now
+	return nil
+}
+
+// track returns the context to run a request's query in, which Cancel
+// cancels, and a function to call once the query is done
+func (s *Service) track(requestID string) (context.Context, func()) {
+	ctx, cancel := context.WithCancel(s.ctx)
+	if requestID == "" {
+		return ctx, cancel
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.cancelled[requestID]; ok {
+		delete(s.cancelled, requestID)
+		cancel()
+	}
+	s.running[requestID] = cancel
+	return ctx, func() {
+		s.mu.Lock()
+		delete(s.running, requestID)
+		s.mu.Unlock()
+		cancel()
+	}
+}
+
+// Status reports information about the daemon
+func (s *Service) Status(_ struct{}, reply *StatusReply) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	reply.PID = os.Getpid()
+	reply.Started = s.started
+	reply.Queries = s.queries
+	for p := range s.profiles {
+		reply.Profiles = append(reply.Profiles, p)
+	}
+	return nil
+}
+
+// Shutdown asks the daemon to exit
+func (s *Service) Shutdown(_ struct{}, _ *struct{}) error {
+	select {
+	case <-s.shutdown:
+	default:
+		close(s.shutdown)
+	}
+	return nil
+}
+
+// warmProfile starts keeping the metadata cache fresh for a profile the first
+// time it is used.
+func (s *Service) warmProfile(profile string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.profiles[profile] {
+		return
+	}
+	s.profiles[profile] = true
+
+	go func() {
+		if err := autocomplete.StartSchemaCacheUpdater(s.ctx, schemaRefreshInterval, autocompleteProfile(profile), s.logger); err != nil {
+			s.logger.Warn("Failed to start schema cache updater", zap.String("profile", profile), zap.Error(err))
+		}
+	}()
+}
+
+// Serve listens on the daemon socket and serves requests until Shutdown is
+// called or ctx is cancelled. profiles are warmed up front.
+func Serve(ctx context.Context, profiles []string, logger *zap.Logger) error {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+
+	socketPath, err := SocketPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(socketPath), 0700); err != nil {
+		return fmt.Errorf("failed to create socket directory: %w", err)
+	}
+
+	// Refuse to start twice; clean up a stale socket otherwise
+	if client, err := Dial(); err == nil {
+		client.Close()
+		return fmt.Errorf("daemon already running at %s", socketPath)
+	}
+	os.Remove(socketPath)
+
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", socketPath, err)
+	}
+	defer os.Remove(socketPath)
+	if err := os.Chmod(socketPath, 0600); err != nil {
+		listener.Close()
+		return fmt.Errorf("failed to restrict socket permissions: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	service := newService(ctx, logger)
+	server := rpc.NewServer()
+	if err := server.RegisterName("Daemon", service); err != nil {
+		listener.Close()
+		return err
+	}
+
+	for _, p := range profiles {
+		service.warmProfile(p)
+	}
+
+	go func() {
+		select {
+		case <-service.shutdown:
+			cancel()
+		case <-ctx.Done():
+		}
+		listener.Close()
+	}()
+
+	logger.Info("Daemon listening", zap.String("socket", socketPath))
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			select {
+			case <-ctx.Done():
+				engine.CloseConnections()
+				logger.Info("Daemon stopped")
+				return nil
+			default:
+				logger.Warn("Failed to accept connection", zap.Error(err))
+				continue
+			}
+		}
+		go server.ServeConn(conn)
+	}
+}
+
+// Client talks to a running daemon
+type Client struct {
+	rpc *rpc.Client
+}
+
+// Dial connects to the running daemon, returning ErrNotRunning if there is none
+func Dial() (*Client, error) {
+	socketPath, err := SocketPath()
+	if err != nil {
+		return nil, err
+	}
+	conn, err := net.DialTimeout("unix", socketPath, 200*time.Millisecond)
+	if err != nil {
+		return nil, ErrNotRunning
+	}
+	return &Client{rpc: rpc.NewClient(conn)}, nil
+}
+
+// requestSeq numbers this process's requests to the daemon
+var requestSeq atomic.Uint64
+
+// Execute runs a query through the daemon. Cancelling ctx cancels the query
+// in the daemon too. It fails with ErrProfileMismatch, without running the
+// query, when the daemon's settings for the profile differ from this
+// process's.
+func (c *Client) Execute(ctx context.Context, query, profile string) (*engine.QueryResult, error) {
+	var result engine.QueryResult
+	args := ExecuteArgs{
+		Query:     query,
+		Profile:   profile,
+		Settings:  engine.ProfileFingerprint(profile),
+		ParentID:  history.ParentIDFromContext(ctx),
+		RequestID: fmt.Sprintf("%d-%d", os.Getpid(), requestSeq.Add(1)),
+	}
+	call := c.rpc.Go("Daemon.Execute", args, &result, nil)
+	select {
+	case <-call.Done:
+		// net/rpc carries errors as their text
+		if call.Error != nil && call.Error.Error() == ErrProfileMismatch.Error() {
+			return nil, ErrProfileMismatch
+		}
+		if call.Error != nil {
+			return nil, call.Error
+		}
+		return &result, nil
+	case <-ctx.Done():
+		// Wait briefly for the daemon to take the cancellation, as the
+		// caller is often about to exit
+		cancel := c.rpc.Go("Daemon.Cancel", CancelArgs{RequestID: args.RequestID}, &struct{}{}, nil)
+		select {
+		case <-cancel.Done:
+			if cancel.Error != nil {
+				return nil, fmt.Errorf("failed to cancel the query in the daemon: %w", cancel.Error)
+			}
+		case <-time.After(cancelTimeout):
+		}
+		return nil, ctx.Err()
+	}
+}
+
+// Status returns information about the daemon
+func (c *Client) Status() (*StatusReply, error) {
+	var reply StatusReply
+	if err := c.rpc.Call("Daemon.Status", struct{}{}, &reply); err != nil {
+		return nil, err
+	}
+	return &reply, nil
+}
+
+// Shutdown stops the daemon
+func (c *Client) Shutdown() error {
+	return c.rpc.Call("Daemon.Shutdown", struct{}{}, &struct{}{})
+}
+
+// Close closes the connection to the daemon
+func (c *Client) Close() error {
+	return c.rpc.Close()
+}
+
+// autocompleteProfile describes the named profile to the schema cache
+// updater, which reads the profile's metadata within the autocomplete
+// section's patterns
+func autocompleteProfile(name string) autocomplete.Profile {
+	p := config.AppConfig.Profiles[name]
+	return autocomplete.Profile{
+		Name:    name,
+		DSN:     p.DSN(),
+		Server:  p.ServerKey(),
+		Catalog: p.Catalog,
+		Schema:  p.Schema,
+		Include: config.AppConfig.Autocomplete.Include,
+		Exclude: config.AppConfig.Autocomplete.Exclude,
+	}
+}
@@ -0,0 +1,125 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/TFMV/trino-cli/engine"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestServeStatusAndShutdown(t *testing.T) {
+	// Keep the socket inside a short temp dir; unix socket paths are length-limited
+	t.Setenv("HOME", t.TempDir())
+
+	if _, err := Dial(); err != ErrNotRunning {
+		t.Fatalf("expected ErrNotRunning before start, got %v", err)
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- Serve(context.Background(), nil, zaptest.NewLogger(t)) }()
+
+	var client *Client
+	var err error
+	for i := 0; i < 50; i++ {
+		if client, err = Dial(); err == nil {
+			break
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatalf("failed to dial daemon: %v", err)
+	}
+	defer client.Close()
+
+	status, err := client.Status()
+	if err != nil {
+		t.Fatalf("Status failed: %v", err)
+	}
+	if status.Queries != 0 || status.Started.IsZero() {
+		t.Errorf("unexpected status: %+v", status)
+	}
+
+	if err := client.Shutdown(); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Serve returned error: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("daemon did not stop")
+	}
+}
+
+func TestServeStopsOnContextCancel(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- Serve(ctx, nil, zaptest.NewLogger(t)) }()
+
+	for i := 0; i < 50; i++ {
+		if client, err := Dial(); err == nil {
+			client.Close()
+			break
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Serve returned error: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("daemon did not stop after cancel")
+	}
+}
+
+func TestCancelStopsRequest(t *testing.T) {
+	service := newService(context.Background(), zaptest.NewLogger(t))
+
+	running, done := service.track("1-1")
+	if err := service.Cancel(CancelArgs{RequestID: "1-1"}, &struct{}{}); err != nil {
+		t.Fatalf("Cancel failed: %v", err)
+	}
+	if running.Err() == nil {
+		t.Error("running request not cancelled")
+	}
+	done()
+	if len(service.running) != 0 {
+		t.Errorf("finished request still tracked: %v", service.running)
+	}
+
+	// A cancellation can overtake its query
+	service.Cancel(CancelArgs{RequestID: "1-2"}, &struct{}{})
+	early, done := service.track("1-2")
+	defer done()
+	if early.Err() == nil {
+		t.Error("request cancelled before it started is running")
+	}
+
+	other, done := service.track("1-3")
+	defer done()
+	if other.Err() != nil {
+		t.Error("unrelated request cancelled")
+	}
+}
+
+func TestExecuteRefusesOtherSettings(t *testing.T) {
+	service := newService(context.Background(), zaptest.NewLogger(t))
+
+	var result engine.QueryResult
+	err := service.Execute(ExecuteArgs{Query: "SELECT 1", Profile: "dev", Settings: "elsewhere"}, &result)
+	if !errors.Is(err, ErrProfileMismatch) {
+		t.Errorf("Execute with other settings = %v, want ErrProfileMismatch", err)
+	}
+	if len(service.profiles) != 0 {
+		t.Errorf("refused request warmed profiles %v", service.profiles)
+	}
+}
@@ -0,0 +1,130 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+)
+
+// ClusterNode is a node of the cluster, from system.runtime.nodes
+type ClusterNode struct {
+	ID          string `json:"id"`
+	URI         string `json:"uri"`
+	Version     string `json:"version"`
+	Coordinator bool   `json:"coordinator"`
+	State       string `json:"state"` // active, inactive or shutting_down
+}
+
+// QueryCounts counts the cluster's queries that are still going
+type QueryCounts struct {
+	Running int `json:"running"`
+	Queued  int `json:"queued"`
+	Blocked int `json:"blocked"` // Waiting for cluster resources
+}
+
+// MemoryPool is the usage of a node's general memory pool
+type MemoryPool struct {
+	Node     string `json:"node"`
+	Max      int64  `json:"max_bytes"`
+	Reserved int64  `json:"reserved_bytes"`
+	Free     int64  `json:"free_bytes"`
+}
+
+// ClusterStatus is a snapshot of the cluster's nodes, queries and memory
+type ClusterStatus struct {
+	Nodes   []ClusterNode `json:"nodes"`
+	Queries QueryCounts   `json:"queries"`
+	Memory  []MemoryPool  `json:"memory"` // nil without a jmx catalog
+}
+
+// clusterStatusTag starts the status statements so the query count can
+// leave them out
+const clusterStatusTag = "-- trino-cli: cluster status"
+
+const clusterNodesSQL = clusterStatusTag + `
+SELECT node_id, http_uri, node_version, coordinator, state
+FROM system.runtime.nodes
+ORDER BY coordinator DESC, node_id`
+
+const clusterQueriesSQL = clusterStatusTag + `
+SELECT
+  count_if(state NOT IN ('QUEUED', 'WAITING_FOR_RESOURCES', 'FINISHED', 'FAILED')),
+  count_if(state = 'QUEUED'),
+  count_if(state = 'WAITING_FOR_RESOURCES')
+FROM system.runtime.queries
+WHERE query NOT LIKE '` + clusterStatusTag + `%'`
+
+// Memory pools are only exposed through JMX, so they need a catalog using
+// the jmx connector
+const clusterMemorySQL = clusterStatusTag + `
+SELECT node, maxbytes, reservedbytes, freebytes
+FROM jmx.current."trino.memory:type=memorypool,name=general"
+ORDER BY node`
+
+// GetClusterStatus reads the status of the profile's cluster from
+// system.runtime and, when the cluster has one, the jmx catalog. It is not
+// recorded in the history.
+func GetClusterStatus(ctx context.Context, profile string) (*ClusterStatus, error) {
+	db, err := getConnection(profile)
+	if err != nil {
+		return nil, err
+	}
+	queryCtx, cancel := withQueryTimeout(ctx, profile)
+	defer cancel()
+	return readClusterStatus(queryCtx, db)
+}
+
+// readClusterStatus runs the status statements on conn
+func readClusterStatus(ctx context.Context, conn queryer) (*ClusterStatus, error) {
+	status := &ClusterStatus{}
+
+	rows, err := conn.QueryContext(ctx, clusterNodesSQL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+	for rows.Next() {
+		var n ClusterNode
+		if err := rows.Scan(&n.ID, &n.URI, &n.Version, &n.Coordinator, &n.State); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to read node list: %w", err)
+		}
+		status.Nodes = append(status.Nodes, n)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+
+	rows, err = conn.QueryContext(ctx, clusterQueriesSQL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count queries: %w", err)
+	}
+	if rows.Next() {
+		err = rows.Scan(&status.Queries.Running, &status.Queries.Queued, &status.Queries.Blocked)
+	}
+	rows.Close()
+	if err == nil {
+		err = rows.Err()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to count queries: %w", err)
+	}
+
+	// A cluster without a jmx catalog still has a status
+	rows, err = conn.QueryContext(ctx, clusterMemorySQL)
+	if err != nil {
+		return status, nil
+	}
+	defer rows.Close()
+	pools := []MemoryPool{}
+	for rows.Next() {
+		var p MemoryPool
+		if err := rows.Scan(&p.Node, &p.Max, &p.Reserved, &p.Free); err != nil {
+			return status, nil
+		}
+		pools = append(pools, p)
+	}
+	if rows.Err() == nil {
+		status.Memory = pools
+	}
+	return status, nil
+}
@@ -0,0 +1,75 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestReadClusterStatus(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(regexp.QuoteMeta("FROM system.runtime.nodes")).WillReturnRows(
+		sqlmock.NewRows([]string{"node_id", "http_uri", "node_version", "coordinator", "state"}).
+			AddRow("coord", "http://10.0.0.1:8080", "435", true, "active").
+			AddRow("worker-1", "http://10.0.0.2:8080", "435", false, "shutting_down"))
+	mock.ExpectQuery(regexp.QuoteMeta("FROM system.runtime.queries")).WillReturnRows(
+		sqlmock.NewRows([]string{"running", "queued", "blocked"}).AddRow(3, 2, 1))
+	mock.ExpectQuery(regexp.QuoteMeta("FROM jmx.current")).WillReturnRows(
+		sqlmock.NewRows([]string{"node", "maxbytes", "reservedbytes", "freebytes"}).
+			AddRow("coord", 1000, 250, 750))
+
+	status, err := readClusterStatus(context.Background(), db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(status.Nodes) != 2 || !status.Nodes[0].Coordinator || status.Nodes[1].State != "shutting_down" {
+		t.Errorf("nodes = %+v", status.Nodes)
+	}
+	if status.Queries != (QueryCounts{Running: 3, Queued: 2, Blocked: 1}) {
+		t.Errorf("queries = %+v", status.Queries)
+	}
+	if len(status.Memory) != 1 || status.Memory[0] != (MemoryPool{Node: "coord", Max: 1000, Reserved: 250, Free: 750}) {
+		t.Errorf("memory = %+v", status.Memory)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestReadClusterStatusWithoutJMX(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(regexp.QuoteMeta("FROM system.runtime.nodes")).WillReturnRows(
+		sqlmock.NewRows([]string{"node_id", "http_uri", "node_version", "coordinator", "state"}).
+			AddRow("coord", "http://10.0.0.1:8080", "435", true, "active"))
+	mock.ExpectQuery(regexp.QuoteMeta("FROM system.runtime.queries")).WillReturnRows(
+		sqlmock.NewRows([]string{"running", "queued", "blocked"}).AddRow(0, 0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta("FROM jmx.current")).WillReturnError(errors.New("Catalog 'jmx' not found"))
+
+	status, err := readClusterStatus(context.Background(), db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Memory != nil {
+		t.Errorf("memory = %+v, want none without a jmx catalog", status.Memory)
+	}
+}
+
+func TestClusterQueriesSQLExcludesItself(t *testing.T) {
+	if !strings.HasPrefix(clusterQueriesSQL, clusterStatusTag) || !strings.Contains(clusterQueriesSQL, "NOT LIKE '"+clusterStatusTag+"%'") {
+		t.Errorf("statement = %q", clusterQueriesSQL)
+	}
+}
@@ -0,0 +1,225 @@
+package engine
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/apache/arrow-go/v18/arrow/ipc"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/apache/arrow-go/v18/parquet"
+	"github.com/apache/arrow-go/v18/parquet/compress"
+	"github.com/apache/arrow-go/v18/parquet/pqarrow"
+)
+
+// StreamWriter writes a QueryResult to w in a specific output format.
+type StreamWriter interface {
+	WriteResult(w io.Writer, result *QueryResult) error
+}
+
+// StreamWriterFunc adapts an ordinary function to the StreamWriter interface.
+type StreamWriterFunc func(w io.Writer, result *QueryResult) error
+
+// WriteResult calls f(w, result).
+func (f StreamWriterFunc) WriteResult(w io.Writer, result *QueryResult) error {
+	return f(w, result)
+}
+
+// Format describes an export format available to the export command and the TUI.
+type Format struct {
+	Name        string
+	ContentType string
+	Extension   string
+	Binary      bool
+	Writer      StreamWriter
+}
+
+// WriteFile exports result to filename, replacing any existing file.
+func (f Format) WriteFile(filename string, result *QueryResult) error {
+	file, err := os.OpenFile(filename, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	if err := f.Writer.WriteResult(file, result); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+var (
+	formats   = map[string]Format{}
+	formatsMu sync.RWMutex
+)
+
+// RegisterFormat makes an export format available by name. Registering a
+// name twice replaces the earlier format.
+func RegisterFormat(format Format) {
+	formatsMu.Lock()
+	defer formatsMu.Unlock()
+	formats[strings.ToLower(format.Name)] = format
+}
+
+// LookupFormat returns the export format registered under name.
+func LookupFormat(name string) (Format, bool) {
+	formatsMu.RLock()
+	defer formatsMu.RUnlock()
+	format, ok := formats[strings.ToLower(name)]
+	return format, ok
+}
+
+// Formats returns all registered export formats sorted by name.
+func Formats() []Format {
+	formatsMu.RLock()
+	defer formatsMu.RUnlock()
+	list := make([]Format, 0, len(formats))
+	for _, format := range formats {
+		list = append(list, format)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// FormatNames returns the names of all registered export formats, sorted.
+func FormatNames() []string {
+	list := Formats()
+	names := make([]string, len(list))
+	for i, format := range list {
+		names[i] = format.Name
+	}
+	return names
+}
+
+func init() {
+	RegisterFormat(Format{Name: "csv", ContentType: "text/csv", Extension: ".csv", Writer: StreamWriterFunc(writeCSV)})
+	RegisterFormat(Format{Name: "json", ContentType: "application/json", Extension: ".json", Writer: StreamWriterFunc(writeJSON)})
+	RegisterFormat(Format{Name: "arrow", ContentType: "application/vnd.apache.arrow.stream", Extension: ".arrow", Binary: true, Writer: StreamWriterFunc(writeArrow)})
+	RegisterFormat(Format{Name: "parquet", ContentType: "application/vnd.apache.parquet", Extension: ".parquet", Binary: true, Writer: StreamWriterFunc(writeParquet)})
+	RegisterFormat(Format{Name: "markdown", ContentType: "text/markdown", Extension: ".md", Writer: StreamWriterFunc(writeMarkdown)})
+}
+
+// writeCSV writes the result as CSV with a header row.
+func writeCSV(w io.Writer, result *QueryResult) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(result.Columns); err != nil {
+		return err
+	}
+	for _, row := range result.Rows {
+		rowStrings := make([]string, len(row))
+		for i, v := range row {
+			rowStrings[i] = fmt.Sprintf("%v", v)
+		}
+		if err := writer.Write(rowStrings); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// writeJSON writes the result as an indented JSON document.
+func writeJSON(w io.Writer, result *QueryResult) error {
+	data, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// writeArrow writes the result as an Arrow IPC stream.
+func writeArrow(w io.Writer, result *QueryResult) error {
+	pool := memory.NewGoAllocator()
+	schema, record, err := createArrowRecord(result, pool)
+	if err != nil {
+		return fmt.Errorf("failed to create arrow record: %w", err)
+	}
+	defer record.Release()
+
+	writer := ipc.NewWriter(w, ipc.WithSchema(schema), ipc.WithAllocator(pool))
+	if err := writer.Write(record); err != nil {
+		_ = writer.Close()
+		return fmt.Errorf("failed to write arrow record: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("failed to close arrow writer: %w", err)
+	}
+	return nil
+}
+
+// writeParquet writes the result as a Snappy-compressed Parquet file.
+func writeParquet(w io.Writer, result *QueryResult) error {
+	pool := memory.NewGoAllocator()
+	// Convert the QueryResult into an Arrow Record.
+	schema, record, err := createArrowRecord(result, pool)
+	if err != nil {
+		return fmt.Errorf("failed to create arrow record: %w", err)
+	}
+	// Ensure the record is released when done.
+	defer record.Release()
+
+	// Use pqarrow to write the record to Parquet format
+	writer, err := pqarrow.NewFileWriter(
+		schema,
+		w,
+		parquet.NewWriterProperties(parquet.WithCompression(compress.Codecs.Snappy)),
+		pqarrow.NewArrowWriterProperties(pqarrow.WithAllocator(pool)),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create parquet writer: %w", err)
+	}
+
+	if err := writer.Write(record); err != nil {
+		_ = writer.Close()
+		return fmt.Errorf("failed to write record to parquet: %w", err)
+	}
+
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("failed to close parquet writer: %w", err)
+	}
+	return nil
+}
+
+// writeMarkdown writes the result as a GitHub-flavored Markdown table.
+func writeMarkdown(w io.Writer, result *QueryResult) error {
+	var b strings.Builder
+	writeRow := func(cells []string) {
+		b.WriteString("|")
+		for _, cell := range cells {
+			b.WriteString(" ")
+			b.WriteString(markdownEscaper.Replace(cell))
+			b.WriteString(" |")
+		}
+		b.WriteString("\n")
+	}
+
+	writeRow(result.Columns)
+	separator := make([]string, len(result.Columns))
+	for i := range separator {
+		separator[i] = "---"
+	}
+	writeRow(separator)
+
+	for _, row := range result.Rows {
+		cells := make([]string, len(row))
+		for i, v := range row {
+			if v == nil {
+				cells[i] = "NULL"
+			} else {
+				cells[i] = fmt.Sprintf("%v", v)
+			}
+		}
+		writeRow(cells)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// markdownEscaper keeps cell values from breaking the table layout
+var markdownEscaper = strings.NewReplacer("|", `\|`, "\r\n", "<br>", "\n", "<br>")
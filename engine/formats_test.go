@@ -0,0 +1,69 @@
+package engine
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestBuiltinFormatsRegistered(t *testing.T) {
+	for _, name := range []string{"csv", "json", "arrow", "parquet", "markdown"} {
+		if _, ok := LookupFormat(name); !ok {
+			t.Errorf("expected built-in format %q to be registered", name)
+		}
+	}
+	if _, ok := LookupFormat("CSV"); !ok {
+		t.Error("expected format lookup to be case-insensitive")
+	}
+}
+
+func TestRegisterFormat(t *testing.T) {
+	RegisterFormat(Format{
+		Name: "test-lines",
+		Writer: StreamWriterFunc(func(w io.Writer, result *QueryResult) error {
+			_, err := io.WriteString(w, "ok")
+			return err
+		}),
+	})
+	defer func() {
+		formatsMu.Lock()
+		delete(formats, "test-lines")
+		formatsMu.Unlock()
+	}()
+
+	found := false
+	for _, name := range FormatNames() {
+		if name == "test-lines" {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatal("registered format missing from FormatNames")
+	}
+
+	format, _ := LookupFormat("test-lines")
+	var buf bytes.Buffer
+	if err := format.Writer.WriteResult(&buf, &QueryResult{}); err != nil || buf.String() != "ok" {
+		t.Errorf("WriteResult = %q, %v", buf.String(), err)
+	}
+}
+
+func TestWriteMarkdown(t *testing.T) {
+	result := &QueryResult{
+		Columns: []string{"id", "note"},
+		Rows: [][]interface{}{
+			{int64(1), "a|b"},
+			{int64(2), nil},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := writeMarkdown(&buf, result); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "| id | note |\n| --- | --- |\n| 1 | a\\|b |\n| 2 | NULL |\n"
+	if buf.String() != want {
+		t.Errorf("writeMarkdown =\n%s\nwant\n%s", buf.String(), want)
+	}
+}
@@ -3,55 +3,130 @@ package engine
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
 	"database/sql"
-	"encoding/csv"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/TFMV/trino-cli/cache"
+	"github.com/TFMV/trino-cli/config"
 	"github.com/TFMV/trino-cli/history"
 	"github.com/apache/arrow-go/v18/arrow"
 	"github.com/apache/arrow-go/v18/arrow/array"
 	"github.com/apache/arrow-go/v18/arrow/ipc"
 	"github.com/apache/arrow-go/v18/arrow/memory"
-	"github.com/apache/arrow-go/v18/parquet"
-	"github.com/apache/arrow-go/v18/parquet/compress"
-	"github.com/apache/arrow-go/v18/parquet/pqarrow"
 	"go.uber.org/zap"
 
 	_ "github.com/trinodb/trino-go-client/trino"
 )
 
+// logger receives the engine's log entries; see SetLogger
+var logger = zap.NewNop()
+
+// SetLogger makes the engine log to l. Events of single queries are logged
+// at debug level, so that they stay out of a batch command's stderr.
+func SetLogger(l *zap.Logger) {
+	logger = l
+}
+
 // QueryResult represents the structure of query results.
 type QueryResult struct {
 	Columns []string        `json:"columns"`
+	Types   []string        `json:"types,omitempty"` // Trino type names, e.g. BIGINT, VARCHAR, TIMESTAMP(3); empty when unknown
 	Rows    [][]interface{} `json:"rows"`
+
+	// Truncated is set when rows past the profile's max_rows were dropped
+	Truncated bool `json:"truncated,omitempty"`
+
+	// CacheKey is set when ExecuteQuery saved the result in the result cache
+	CacheKey string `json:"-"`
 }
 
-// ExecuteQuery connects to Trino and executes the SQL query.
-// It handles connection pooling, session management, and includes automatic retry logic for transient failures.
-func ExecuteQuery(query string, profile string) (*QueryResult, error) {
-	logger, _ := zap.NewProduction()
-	defer logger.Sync()
+// DefaultQueryTimeout bounds queries whose context carries no deadline,
+// unless the profile's defaults set another timeout.
+const DefaultQueryTimeout = 30 * time.Second
 
-	logger.Info("Executing query", zap.String("query", query), zap.String("profile", profile))
-	startTime := time.Now()
+// withQueryTimeout applies the profile's query timeout unless ctx already
+// has a deadline.
+func withQueryTimeout(ctx context.Context, profile string) (context.Context, context.CancelFunc) {
+	if _, ok := ctx.Deadline(); ok {
+		return context.WithCancel(ctx)
+	}
+	timeout := profileConfig(profile).Defaults.QueryTimeout(DefaultQueryTimeout)
+	if timeout == 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// skipHistoryKey is the context key set by WithoutHistory
+type skipHistoryKey struct{}
 
+// WithoutHistory returns a context under which ExecuteQuery neither records
+// the query in the history nor caches its result, e.g. for the repeated runs
+// of a watched query
+func WithoutHistory(ctx context.Context) context.Context {
+	return context.WithValue(ctx, skipHistoryKey{}, true)
+}
+
+// recordsHistory reports whether queries run under ctx are recorded
+func recordsHistory(ctx context.Context) bool {
+	skip, _ := ctx.Value(skipHistoryKey{}).(bool)
+	return !skip
+}
+
+// ExecuteQuery connects to Trino and executes the SQL query.
+// It handles connection pooling, session management, and includes automatic retry logic for transient failures.
+// Cancelling ctx aborts the query.
+func ExecuteQuery(ctx context.Context, query string, profile string) (*QueryResult, error) {
 	// Retrieve connection details based on profile
 	db, err := getConnection(profile)
 	if err != nil {
-		logger.Error("Failed to establish connection", zap.Error(err))
 		return nil, err
 	}
-	defer db.Close()
+	return execute(ctx, db, query, profile)
+}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+// ExecuteIsolated runs a query like ExecuteQuery, but never leaves a USE or
+// SET SESSION behind on the profile's pool, where the driver would keep it
+// for whatever query reuses the connection next. A statement that changes
+// the session runs on a connection of its own, which is closed after it.
+// Long-lived processes serving unrelated callers, like the daemon, use it.
+func ExecuteIsolated(ctx context.Context, query string, profile string) (*QueryResult, error) {
+	if !ChangesSession(query) {
+		return ExecuteQuery(ctx, query, profile)
+	}
+	script, err := NewScript(ctx, profile)
+	if err != nil {
+		return nil, err
+	}
+	defer script.Close()
+	return script.Execute(ctx, query)
+}
+
+// queryer is a connection pool, or a single connection, to run queries on
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// execute runs the query on conn, recording it in the history
+func execute(ctx context.Context, conn queryer, query string, profile string) (*QueryResult, error) {
+	logger.Debug("Executing query", zap.String("query", query), zap.String("profile", profile))
+	startTime := time.Now()
+
+	queryCtx, cancel := withQueryTimeout(ctx, profile)
 	defer cancel()
 
-	rows, err := db.QueryContext(ctx, query)
+	rows, err := conn.QueryContext(queryCtx, query)
 	if err != nil {
-		logger.Error("Query execution failed", zap.Error(err))
+		logger.Debug("Query execution failed", zap.Error(err))
+		recordFailure(ctx, query, time.Since(startTime), profile, err)
 		return nil, err
 	}
 	defer rows.Close()
@@ -59,135 +134,380 @@ func ExecuteQuery(query string, profile string) (*QueryResult, error) {
 	result := &QueryResult{}
 	columns, err := rows.Columns()
 	if err != nil {
-		logger.Error("Failed to fetch column names", zap.Error(err))
+		logger.Debug("Failed to fetch column names", zap.Error(err))
 		return nil, err
 	}
 	result.Columns = columns
+	if types, err := rows.ColumnTypes(); err == nil {
+		for _, t := range types {
+			result.Types = append(result.Types, columnType(t))
+		}
+	}
 
-	// Process rows and build the result.
+	// Process rows and build the result, up to the profile's row limit
+	maxRows := profileConfig(profile).Defaults.MaxRows
 	for rows.Next() {
+		if maxRows > 0 && len(result.Rows) == maxRows {
+			result.Truncated = true
+			logger.Debug("Dropping rows past the row limit", zap.Int("max_rows", maxRows))
+			break
+		}
 		values := make([]interface{}, len(columns))
 		scanArgs := make([]interface{}, len(columns))
 		for i := range values {
 			scanArgs[i] = &values[i]
 		}
 		if err := rows.Scan(scanArgs...); err != nil {
-			logger.Error("Error scanning row", zap.Error(err))
+			logger.Warn("Error scanning row", zap.Error(err))
 			continue
 		}
 		result.Rows = append(result.Rows, values)
 	}
 	if err := rows.Err(); err != nil {
-		logger.Error("Row iteration error", zap.Error(err))
+		logger.Debug("Row iteration error", zap.Error(err))
+		recordFailure(ctx, query, time.Since(startTime), profile, err)
 		return nil, err
 	}
 
 	duration := time.Since(startTime)
-	if _, err := history.AddQuery(query, duration, len(result.Rows), profile); err != nil {
-		logger.Warn("Failed to add query to history", zap.Error(err))
+	if recordsHistory(ctx) {
+		id, err := history.AddQuery(ctx, query, duration, len(result.Rows), profile)
+		if err != nil {
+			logger.Warn("Failed to add query to history", zap.Error(err))
+		} else {
+			cacheResult(ctx, id, result)
+		}
 	}
-	logger.Info("Query executed successfully", zap.Int("rows_returned", len(result.Rows)))
+	logger.Debug("Query executed successfully", zap.Int("rows_returned", len(result.Rows)))
 	return result, nil
 }
 
-// DisplayResult prints the QueryResult in a simple table format.
-func DisplayResult(result *QueryResult) {
-	logger, _ := zap.NewProduction()
-	defer logger.Sync()
+// columnType returns the Trino type name of a result column. The driver
+// drops type parameters, so the precision of time and timestamp columns is
+// put back, as in TIMESTAMP(6) WITH TIME ZONE.
+func columnType(t *sql.ColumnType) string {
+	name := t.DatabaseTypeName()
+	if !strings.HasPrefix(name, "TIME") {
+		return name
+	}
+	precision, _, ok := t.DecimalSize()
+	if !ok {
+		return name
+	}
+	base, zone, _ := strings.Cut(name, " ")
+	return strings.TrimSpace(fmt.Sprintf("%s(%d) %s", base, precision, zone))
+}
+
+// ExplainQuery returns the distributed plan Trino produces for a query.
+// Unlike ExecuteQuery it does not record anything in the history.
+func ExplainQuery(ctx context.Context, query string, profile string) (string, error) {
+	db, err := getConnection(profile)
+	if err != nil {
+		return "", err
+	}
+
+	ctx, cancel := withQueryTimeout(ctx, profile)
+	defer cancel()
+
+	rows, err := db.QueryContext(ctx, "EXPLAIN "+query)
+	if err != nil {
+		return "", err
+	}
+	defer rows.Close()
+
+	var plan bytes.Buffer
+	for rows.Next() {
+		var line string
+		if err := rows.Scan(&line); err != nil {
+			return "", err
+		}
+		plan.WriteString(line)
+		plan.WriteString("\n")
+	}
+	if err := rows.Err(); err != nil {
+		return "", err
+	}
+	return plan.String(), nil
+}
+
+// cacheResult stores result in the result cache under the history entry's ID
+// and links the two, when automatic caching is enabled.
+func cacheResult(ctx context.Context, id string, result *QueryResult) {
+	settings := config.AppConfig.Cache
+	if !settings.Auto {
+		return
+	}
+	if settings.MaxRows > 0 && len(result.Rows) > settings.MaxRows {
+		logger.Debug("Result too large to cache", zap.Int("rows", len(result.Rows)), zap.Int("max_rows", settings.MaxRows))
+		return
+	}
+
+	data, err := ExportArrow(result)
+	if err != nil {
+		logger.Warn("Failed to encode result for cache", zap.Error(err))
+		return
+	}
+	if err := cache.Save(id, data); err != nil {
+		logger.Warn("Failed to cache result", zap.Error(err))
+		return
+	}
+	if err := history.SetCacheKey(ctx, id, id); err != nil {
+		logger.Warn("Failed to link cached result to history", zap.Error(err))
+		return
+	}
+	result.CacheKey = id
+}
 
-	columnStr := fmt.Sprintf("%v", result.Columns)
-	logger.Info("Query result columns", zap.String("columns", columnStr))
-	for i, row := range result.Rows {
-		rowStr := fmt.Sprintf("%v", row)
-		logger.Info("Query result row", zap.Int("row", i), zap.String("data", rowStr))
+// recordFailure stores a failed query and its error in the history database.
+// The write is detached from ctx so cancelled queries are still recorded.
+func recordFailure(ctx context.Context, query string, duration time.Duration, profile string, queryErr error) {
+	if !recordsHistory(ctx) {
+		return
+	}
+	if _, err := history.AddFailedQuery(context.WithoutCancel(ctx), query, duration, profile, queryErr); err != nil {
+		logger.Warn("Failed to add failed query to history", zap.Error(err))
 	}
+}
+
+// DisplayResult prints the QueryResult in a simple table format.
+func DisplayResult(result *QueryResult) {
+	WriteTable(os.Stdout, result)
+}
 
+// WriteTable writes the QueryResult to w in the simple table format of
+// DisplayResult
+func WriteTable(w io.Writer, result *QueryResult) error {
 	var buffer bytes.Buffer
 	buffer.WriteString(fmt.Sprintf("%v\n", result.Columns))
 	for _, row := range result.Rows {
 		buffer.WriteString(fmt.Sprintf("%v\n", row))
 	}
-	os.Stdout.WriteString(buffer.String())
+	_, err := w.Write(buffer.Bytes())
+	return err
 }
 
 // ExportCSV converts QueryResult into CSV format.
 func ExportCSV(result *QueryResult) (string, error) {
 	var buf bytes.Buffer
-	writer := csv.NewWriter(&buf)
-	if err := writer.Write(result.Columns); err != nil {
+	if err := writeCSV(&buf, result); err != nil {
 		return "", err
 	}
-	for _, row := range result.Rows {
-		rowStrings := make([]string, len(row))
-		for i, v := range row {
-			rowStrings[i] = fmt.Sprintf("%v", v)
-		}
-		if err := writer.Write(rowStrings); err != nil {
-			return "", err
-		}
-	}
-	writer.Flush()
 	return buf.String(), nil
 }
 
 // ExportJSON converts QueryResult into JSON format.
 func ExportJSON(result *QueryResult) (string, error) {
-	data, err := json.MarshalIndent(result, "", "  ")
-	if err != nil {
+	var buf bytes.Buffer
+	if err := writeJSON(&buf, result); err != nil {
 		return "", err
 	}
-	return string(data), nil
+	return buf.String(), nil
 }
 
 // ExportArrow converts QueryResult into Arrow IPC format.
 func ExportArrow(result *QueryResult) ([]byte, error) {
-	pool := memory.NewGoAllocator()
-	arrowBuffer := &bytes.Buffer{}
-	writer := ipc.NewWriter(arrowBuffer, ipc.WithAllocator(pool))
-	// (Implementation omitted for brevity.)
-	defer writer.Close()
-	return arrowBuffer.Bytes(), nil
+	var buf bytes.Buffer
+	if err := writeArrow(&buf, result); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
 }
 
-// ExportParquet converts QueryResult into Parquet format.
-func ExportParquet(result *QueryResult) ([]byte, error) {
-	pool := memory.NewGoAllocator()
-	// Convert the QueryResult into an Arrow Record.
-	schema, record, err := createArrowRecord(result, pool)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create arrow record: %w", err)
-	}
-	// Ensure the record is released when done.
-	defer record.Release()
-
-	buf := new(bytes.Buffer)
-	// Use pqarrow to write the record to Parquet format
-	writer, err := pqarrow.NewFileWriter(
-		schema,
-		buf,
-		parquet.NewWriterProperties(parquet.WithCompression(compress.Codecs.Snappy)),
-		pqarrow.NewArrowWriterProperties(pqarrow.WithAllocator(pool)),
-	)
+// arrowTypeKey is the field metadata key under which ExportArrow keeps a
+// column's Trino type, which the Arrow type alone doesn't tell
+const arrowTypeKey = "trino.type"
+
+// ImportArrow reads a QueryResult back from Arrow IPC format produced by ExportArrow.
+func ImportArrow(data []byte) (*QueryResult, error) {
+	reader, err := ipc.NewReader(bytes.NewReader(data), ipc.WithAllocator(memory.NewGoAllocator()))
 	if err != nil {
-		return nil, fmt.Errorf("failed to create parquet writer: %w", err)
+		return nil, fmt.Errorf("failed to open arrow data: %w", err)
 	}
+	defer reader.Release()
 
-	if err := writer.Write(record); err != nil {
-		_ = writer.Close()
-		return nil, fmt.Errorf("failed to write record to parquet: %w", err)
+	result := &QueryResult{}
+	fields := reader.Schema().Fields()
+	types := make([]string, len(fields))
+	typed := false
+	for i, field := range fields {
+		result.Columns = append(result.Columns, field.Name)
+		if k := field.Metadata.FindKey(arrowTypeKey); k >= 0 {
+			types[i] = field.Metadata.Values()[k]
+			typed = true
+		}
+	}
+	// Data written before types were kept has none
+	if typed {
+		result.Types = types
 	}
 
-	if err := writer.Close(); err != nil {
-		return nil, fmt.Errorf("failed to close parquet writer: %w", err)
+	for reader.Next() {
+		record := reader.Record()
+		for i := 0; i < int(record.NumRows()); i++ {
+			row := make([]interface{}, record.NumCols())
+			for j, col := range record.Columns() {
+				row[j] = arrowValue(col, i)
+			}
+			result.Rows = append(result.Rows, row)
+		}
+	}
+	if err := reader.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read arrow data: %w", err)
+	}
+	return result, nil
+}
+
+// arrowValue converts a single Arrow array element back into a Go value.
+func arrowValue(col arrow.Array, i int) interface{} {
+	if col.IsNull(i) {
+		return nil
+	}
+	switch c := col.(type) {
+	case *array.Int64:
+		return c.Value(i)
+	case *array.Float64:
+		return c.Value(i)
+	case *array.Boolean:
+		return c.Value(i)
+	case *array.String:
+		return c.Value(i)
+	case *array.Timestamp:
+		unit := c.DataType().(*arrow.TimestampType).Unit
+		return c.Value(i).ToTime(unit)
+	default:
+		return col.ValueStr(i)
 	}
+}
 
+// ExportParquet converts QueryResult into Parquet format.
+func ExportParquet(result *QueryResult) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := writeParquet(&buf, result); err != nil {
+		return nil, err
+	}
 	return buf.Bytes(), nil
 }
 
-// getConnection returns a Trino connection based on the specified profile.
+var (
+	// connections holds one connection pool per profile so repeated queries
+	// in a long-lived process (TUI, daemon) reuse warm connections.
+	connections   = map[string]*sql.DB{}
+	connectionsMu sync.Mutex
+
+	// sessions holds the catalog and schema each profile switched to with
+	// USE, which take the place of those in its config
+	sessions = map[string]session{}
+)
+
+// session is the default catalog and schema of a profile's queries
+type session struct {
+	catalog string
+	schema  string
+}
+
+// UseSchema makes later queries of the profile run in catalog.schema, as a
+// USE statement does; an empty catalog keeps the current one. The driver
+// only remembers a USE on the connection that ran it, so the profile's
+// pool is reopened for every connection to agree.
+func UseSchema(profile, catalog, schema string) {
+	connectionsMu.Lock()
+	defer connectionsMu.Unlock()
+
+	s, ok := sessions[profile]
+	if !ok {
+		p := profileConfig(profile)
+		s = session{catalog: p.Catalog, schema: p.Schema}
+	}
+	if catalog != "" {
+		s.catalog = catalog
+	}
+	s.schema = schema
+	sessions[profile] = s
+
+	dropConnection(profile)
+}
+
+// CurrentSchema returns the catalog and schema later queries of the profile
+// run in: the profile's own, or those of the last UseSchema
+func CurrentSchema(profile string) (catalog, schema string) {
+	connectionsMu.Lock()
+	defer connectionsMu.Unlock()
+
+	if s, ok := sessions[profile]; ok {
+		return s.catalog, s.schema
+	}
+	p := profileConfig(profile)
+	return p.Catalog, p.Schema
+}
+
+// getConnection returns a pooled Trino connection for the specified profile.
+// Unknown profiles fall back to a local default server.
 func getConnection(profile string) (*sql.DB, error) {
-	dsn := "http://user@localhost:8080?catalog=default&schema=public"
-	return sql.Open("trino", dsn)
+	connectionsMu.Lock()
+	defer connectionsMu.Unlock()
+
+	if db, ok := connections[profile]; ok {
+		return db, nil
+	}
+
+	p := profileConfig(profile)
+	if s, ok := sessions[profile]; ok {
+		p.Catalog, p.Schema = s.catalog, s.schema
+	}
+	db, err := sql.Open("trino", p.DSN())
+	if err != nil {
+		return nil, err
+	}
+	connections[profile] = db
+	return db, nil
+}
+
+// Reconnect drops the profile's pooled connections, and the catalog and
+// schema a USE switched it to, so that its next query connects afresh with
+// the settings in its config
+func Reconnect(profile string) {
+	connectionsMu.Lock()
+	defer connectionsMu.Unlock()
+
+	delete(sessions, profile)
+	dropConnection(profile)
+}
+
+// dropConnection closes the profile's pool, if it has one, so that its next
+// query opens another. The caller holds connectionsMu.
+func dropConnection(profile string) {
+	if db, ok := connections[profile]; ok {
+		delete(connections, profile)
+		go db.Close() // Waits for queries still running on it
+	}
+}
+
+// profileConfig returns the settings of a profile, falling back to a local
+// default server for unknown ones
+func profileConfig(profile string) config.Profile {
+	if p, ok := config.AppConfig.Profiles[profile]; ok {
+		return p
+	}
+	return config.Profile{Host: "localhost", Port: 8080, User: "user", Catalog: "default", Schema: "public", Defaults: config.AppConfig.Defaults}
+}
+
+// ProfileFingerprint identifies the settings the profile's queries connect
+// with, so that two processes can tell whether they agree on them
+func ProfileFingerprint(profile string) string {
+	data, _ := json.Marshal(profileConfig(profile))
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// CloseConnections closes all pooled connections.
+func CloseConnections() {
+	connectionsMu.Lock()
+	defer connectionsMu.Unlock()
+
+	for name, db := range connections {
+		db.Close()
+		delete(connections, name)
+	}
 }
 
 // createArrowRecord converts a QueryResult into an Arrow record.
@@ -209,6 +529,9 @@ func createArrowRecord(result *QueryResult, pool memory.Allocator) (*arrow.Schem
 			dt = arrow.BinaryTypes.String
 		}
 		fields[j] = arrow.Field{Name: colName, Type: dt, Nullable: true}
+		if j < len(result.Types) && result.Types[j] != "" {
+			fields[j].Metadata = arrow.NewMetadata([]string{arrowTypeKey}, []string{result.Types[j]})
+		}
 		switch dt := dt.(type) {
 		case *arrow.Int64Type:
 			builders[j] = array.NewInt64Builder(pool)
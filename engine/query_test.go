@@ -0,0 +1,140 @@
+package engine
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/TFMV/trino-cli/config"
+)
+
+func TestUseSchema(t *testing.T) {
+	saved := config.AppConfig
+	defer func() { config.AppConfig = saved }()
+	config.AppConfig = config.Config{Profiles: map[string]config.Profile{
+		"dev": {Host: "localhost", Port: 8080, User: "ana", Catalog: "hive", Schema: "default"},
+	}}
+	defer CloseConnections()
+	defer func() { delete(sessions, "dev") }()
+
+	db, err := getConnection("dev")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if catalog, schema := CurrentSchema("dev"); catalog != "hive" || schema != "default" {
+		t.Errorf("CurrentSchema before USE = %s.%s, want hive.default", catalog, schema)
+	}
+	UseSchema("dev", "", "analytics")
+	if got := sessions["dev"]; got != (session{catalog: "hive", schema: "analytics"}) {
+		t.Errorf("after USE analytics, session = %+v, want hive.analytics", got)
+	}
+	UseSchema("dev", "iceberg", "marts")
+	if got := sessions["dev"]; got != (session{catalog: "iceberg", schema: "marts"}) {
+		t.Errorf("after USE iceberg.marts, session = %+v, want iceberg.marts", got)
+	}
+	if catalog, schema := CurrentSchema("dev"); catalog != "iceberg" || schema != "marts" {
+		t.Errorf("CurrentSchema = %s.%s, want iceberg.marts", catalog, schema)
+	}
+
+	reopened, err := getConnection("dev")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reopened == db {
+		t.Error("expected USE to reopen the profile's connection pool")
+	}
+}
+
+func TestWithQueryTimeout(t *testing.T) {
+	saved := config.AppConfig
+	defer func() { config.AppConfig = saved }()
+	config.AppConfig = config.Config{Profiles: map[string]config.Profile{
+		"slow": {Defaults: config.Defaults{Timeout: "10m"}},
+		"open": {Defaults: config.Defaults{Timeout: "off"}},
+	}}
+
+	for profile, want := range map[string]time.Duration{"slow": 10 * time.Minute, "open": 0, "unknown": DefaultQueryTimeout} {
+		ctx, cancel := withQueryTimeout(context.Background(), profile)
+		deadline, ok := ctx.Deadline()
+		cancel()
+		switch {
+		case want == 0 && ok:
+			t.Errorf("%s: expected no deadline", profile)
+		case want != 0 && (!ok || time.Until(deadline) > want || time.Until(deadline) < want-time.Minute):
+			t.Errorf("%s: deadline in %s, want %s", profile, time.Until(deadline), want)
+		}
+	}
+}
+
+func TestReconnect(t *testing.T) {
+	defer CloseConnections()
+	UseSchema("dev", "iceberg", "marts")
+	db, err := getConnection("dev")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	Reconnect("dev")
+	if _, ok := sessions["dev"]; ok {
+		t.Error("expected Reconnect to forget the USE")
+	}
+	if reopened, _ := getConnection("dev"); reopened == db {
+		t.Error("expected Reconnect to reopen the profile's connection pool")
+	}
+}
+
+func TestArrowRoundTrip(t *testing.T) {
+	tests := []struct {
+		name   string
+		result QueryResult
+	}{
+		{"typed", QueryResult{
+			Columns: []string{"id", "name", "price"},
+			Types:   []string{"BIGINT", "VARCHAR(10)", "DECIMAL(10,2)"},
+			Rows:    [][]interface{}{{int64(1), "a", 1.5}, {int64(2), nil, nil}},
+		}},
+		{"untyped", QueryResult{
+			Columns: []string{"id"},
+			Rows:    [][]interface{}{{int64(1)}},
+		}},
+		{"partly typed", QueryResult{
+			Columns: []string{"id", "note"},
+			Types:   []string{"INTEGER", ""},
+			Rows:    [][]interface{}{{int64(7), "x"}},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := ExportArrow(&tt.result)
+			if err != nil {
+				t.Fatalf("ExportArrow: %v", err)
+			}
+			got, err := ImportArrow(data)
+			if err != nil {
+				t.Fatalf("ImportArrow: %v", err)
+			}
+			if !reflect.DeepEqual(got.Columns, tt.result.Columns) || !reflect.DeepEqual(got.Types, tt.result.Types) || !reflect.DeepEqual(got.Rows, tt.result.Rows) {
+				t.Errorf("round trip gave %+v, want %+v", *got, tt.result)
+			}
+		})
+	}
+}
+
+func TestProfileFingerprint(t *testing.T) {
+	saved := config.AppConfig
+	defer func() { config.AppConfig = saved }()
+	config.AppConfig = config.Config{Profiles: map[string]config.Profile{
+		"dev": {Host: "localhost", Port: 8080, User: "ana"},
+	}}
+
+	before := ProfileFingerprint("dev")
+	if ProfileFingerprint("dev") != before {
+		t.Error("fingerprint of unchanged settings differs")
+	}
+	config.AppConfig.Profiles["dev"] = config.Profile{Host: "trino.prod", Port: 8080, User: "ana"}
+	if ProfileFingerprint("dev") == before {
+		t.Error("fingerprint unchanged after the profile's host changed")
+	}
+}
@@ -0,0 +1,122 @@
+package engine
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// ServerQuery is a query known to the coordinator, from system.runtime.queries
+type ServerQuery struct {
+	ID      string    `json:"id"`
+	State   string    `json:"state"` // QUEUED, RUNNING, FINISHED, FAILED, ...
+	User    string    `json:"user"`
+	Query   string    `json:"query"`
+	Created time.Time `json:"created"`
+	Ended   time.Time `json:"ended,omitzero"` // Zero while the query is still going
+}
+
+// Done reports whether the query has finished or failed
+func (q ServerQuery) Done() bool {
+	return q.State == "FINISHED" || q.State == "FAILED"
+}
+
+// Elapsed is how long the query has run, or ran, as of now
+func (q ServerQuery) Elapsed(now time.Time) time.Duration {
+	if !q.Ended.IsZero() {
+		return q.Ended.Sub(q.Created)
+	}
+	return now.Sub(q.Created)
+}
+
+// serverQueriesTag starts the listing query so it can leave itself out
+const serverQueriesTag = "-- trino-cli: running queries"
+
+// ServerQueryFilter selects the queries ListServerQueries returns
+type ServerQueryFilter struct {
+	Limit      int
+	AllUsers   bool // Every user's queries rather than the session user's
+	Unfinished bool // Only queries still queued or running
+}
+
+// serverQueriesSQL builds the statement listing the queries filter selects,
+// unfinished ones first
+func serverQueriesSQL(filter ServerQueryFilter) string {
+	where := "query NOT LIKE '" + serverQueriesTag + "%'"
+	if !filter.AllUsers {
+		where = `"user" = current_user AND ` + where
+	}
+	if filter.Unfinished {
+		where += " AND state NOT IN ('FINISHED', 'FAILED')"
+	}
+	return serverQueriesTag + `
+SELECT query_id, state, "user", query, created, "end"
+FROM system.runtime.queries
+WHERE ` + where + `
+ORDER BY state IN ('FINISHED', 'FAILED'), created DESC
+LIMIT ` + strconv.Itoa(filter.Limit)
+}
+
+// ListServerQueries returns up to filter.Limit of the queries filter selects
+// from system.runtime.queries, those still queued or running first, then the
+// most recent. It is not recorded in the history.
+func ListServerQueries(ctx context.Context, profile string, filter ServerQueryFilter) ([]ServerQuery, error) {
+	db, err := getConnection(profile)
+	if err != nil {
+		return nil, err
+	}
+	queryCtx, cancel := withQueryTimeout(ctx, profile)
+	defer cancel()
+
+	rows, err := db.QueryContext(queryCtx, serverQueriesSQL(filter))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list queries: %w", err)
+	}
+	defer rows.Close()
+
+	var queries []ServerQuery
+	for rows.Next() {
+		var q ServerQuery
+		var created, ended sql.NullTime
+		if err := rows.Scan(&q.ID, &q.State, &q.User, &q.Query, &created, &ended); err != nil {
+			return nil, fmt.Errorf("failed to read query list: %w", err)
+		}
+		q.Created, q.Ended = created.Time, ended.Time
+		queries = append(queries, q)
+	}
+	return queries, rows.Err()
+}
+
+// queryIDPattern matches Trino query IDs such as 20240305_140709_00012_abcde
+var queryIDPattern = regexp.MustCompile(`^[0-9a-z_]+$`)
+
+// killQuerySQL builds the statement that kills query id
+func killQuerySQL(id string) (string, error) {
+	if !queryIDPattern.MatchString(id) {
+		return "", fmt.Errorf("invalid query ID %q", id)
+	}
+	return fmt.Sprintf("CALL system.runtime.kill_query(query_id => '%s', message => 'Killed from trino-cli')", id), nil
+}
+
+// KillQuery asks the coordinator to kill query id. Killing another user's
+// query needs the corresponding access rights.
+func KillQuery(ctx context.Context, profile, id string) error {
+	statement, err := killQuerySQL(id)
+	if err != nil {
+		return err
+	}
+	db, err := getConnection(profile)
+	if err != nil {
+		return err
+	}
+	queryCtx, cancel := withQueryTimeout(ctx, profile)
+	defer cancel()
+
+	if _, err := db.ExecContext(queryCtx, statement); err != nil {
+		return fmt.Errorf("failed to kill query %s: %w", id, err)
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package engine
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestKillQuerySQL(t *testing.T) {
+	statement, err := killQuerySQL("20240305_140709_00012_abcde")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(statement, "query_id => '20240305_140709_00012_abcde'") {
+		t.Errorf("statement = %q", statement)
+	}
+
+	for _, id := range []string{"", "x'; DROP TABLE t; --", "20240305 140709"} {
+		if _, err := killQuerySQL(id); err == nil {
+			t.Errorf("killQuerySQL(%q) should fail", id)
+		}
+	}
+}
+
+func TestServerQueriesSQLExcludesItself(t *testing.T) {
+	statement := serverQueriesSQL(ServerQueryFilter{Limit: 50})
+	if !strings.HasPrefix(statement, serverQueriesTag) {
+		t.Errorf("statement should start with its tag: %q", statement)
+	}
+	if !strings.Contains(statement, "NOT LIKE '"+serverQueriesTag+"%'") || !strings.HasSuffix(statement, "LIMIT 50") {
+		t.Errorf("statement = %q", statement)
+	}
+	if !strings.Contains(statement, `"user" = current_user`) || strings.Contains(statement, "NOT IN") {
+		t.Errorf("statement should list the user's queries in every state: %q", statement)
+	}
+}
+
+func TestServerQueriesSQLFilter(t *testing.T) {
+	statement := serverQueriesSQL(ServerQueryFilter{Limit: 10, AllUsers: true, Unfinished: true})
+	if strings.Contains(statement, "current_user") {
+		t.Errorf("statement should list every user's queries: %q", statement)
+	}
+	if !strings.Contains(statement, "state NOT IN ('FINISHED', 'FAILED')") {
+		t.Errorf("statement should leave finished queries out: %q", statement)
+	}
+}
+
+func TestServerQueryElapsed(t *testing.T) {
+	created := time.Date(2024, 3, 5, 14, 0, 0, 0, time.UTC)
+	running := ServerQuery{State: "RUNNING", Created: created}
+	if got := running.Elapsed(created.Add(time.Minute)); got != time.Minute || running.Done() {
+		t.Errorf("running query elapsed %s, done %v", got, running.Done())
+	}
+	finished := ServerQuery{State: "FINISHED", Created: created, Ended: created.Add(5 * time.Second)}
+	if got := finished.Elapsed(created.Add(time.Hour)); got != 5*time.Second || !finished.Done() {
+		t.Errorf("finished query elapsed %s, done %v", got, finished.Done())
+	}
+}
+
+func TestServerQueryJSON(t *testing.T) {
+	created := time.Date(2024, 3, 5, 14, 7, 9, 0, time.UTC)
+	running, err := json.Marshal(ServerQuery{ID: "q1", State: "RUNNING", User: "ana", Query: "SELECT 1", Created: created})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"id":"q1","state":"RUNNING","user":"ana","query":"SELECT 1","created":"2024-03-05T14:07:09Z"}`
+	if string(running) != want {
+		t.Errorf("running query = %s, want %s", running, want)
+	}
+
+	finished, err := json.Marshal(ServerQuery{ID: "q2", State: "FINISHED", Created: created, Ended: created.Add(time.Second)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(finished), `"ended":"2024-03-05T14:07:10Z"`) {
+		t.Errorf("finished query = %s, want its end time", finished)
+	}
+}
@@ -0,0 +1,45 @@
+package engine
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+)
+
+// Script runs the statements of a script one after another on a single
+// connection, so that USE and SET SESSION carry over to the statements
+// after them, as they would in an interactive session.
+type Script struct {
+	conn    *sql.Conn
+	profile string
+}
+
+// NewScript reserves a connection of the profile's pool for a script
+func NewScript(ctx context.Context, profile string) (*Script, error) {
+	db, err := getConnection(profile)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &Script{conn: conn, profile: profile}, nil
+}
+
+// Execute runs the next statement of the script, recording it in the
+// history like ExecuteQuery
+func (s *Script) Execute(ctx context.Context, query string) (*QueryResult, error) {
+	return execute(ctx, s.conn, query, s.profile)
+}
+
+// Close ends the script. Its connection is closed rather than returned to
+// the pool, which would hand the script's session on to later queries.
+func (s *Script) Close() error {
+	err := s.conn.Raw(func(any) error { return driver.ErrBadConn })
+	if errors.Is(err, driver.ErrBadConn) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,48 @@
+package engine
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestScript(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+
+	mock.ExpectQuery("SET SESSION query_max_run_time = '1h'").WillReturnRows(sqlmock.NewRows([]string{"result"}))
+	mock.ExpectQuery("SELECT 1").WillReturnRows(sqlmock.NewRows([]string{"_col0"}).AddRow(1))
+
+	ctx := WithoutHistory(context.Background())
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	script := &Script{conn: conn, profile: "dev"}
+	if _, err := script.Execute(ctx, "SET SESSION query_max_run_time = '1h'"); err != nil {
+		t.Fatal(err)
+	}
+	result, err := script.Execute(ctx, "SELECT 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Rows) != 1 {
+		t.Errorf("SELECT 1 returned %d rows, want 1", len(result.Rows))
+	}
+
+	mock.ExpectClose()
+	if err := script.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if open := db.Stats().OpenConnections; open != 0 {
+		t.Errorf("%d connections open after Close, want the script's closed rather than pooled", open)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,121 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/TFMV/trino-cli/config"
+)
+
+// ServerInfo is what the coordinator says about itself
+type ServerInfo struct {
+	Version     string `json:"version"`
+	Environment string `json:"environment"`
+	Uptime      string `json:"uptime,omitempty"` // As Trino writes durations, e.g. 3.25d
+	Starting    bool   `json:"starting"`         // Still starting up, not yet taking queries
+}
+
+// GetServerInfo asks the coordinator of the profile for its version and
+// environment. It needs no credentials, so it never prompts for a password.
+func GetServerInfo(ctx context.Context, profile string) (*ServerInfo, error) {
+	var info struct {
+		NodeVersion struct {
+			Version string `json:"version"`
+		} `json:"nodeVersion"`
+		Environment string `json:"environment"`
+		Uptime      string `json:"uptime"`
+		Starting    bool   `json:"starting"`
+	}
+	if err := restGet(ctx, profileConfig(profile), "/v1/info", &info); err != nil {
+		return nil, err
+	}
+	return &ServerInfo{
+		Version:     info.NodeVersion.Version,
+		Environment: info.Environment,
+		Uptime:      info.Uptime,
+		Starting:    info.Starting,
+	}, nil
+}
+
+// CurrentUser runs a query as the profile, which proves that the server
+// accepts its credentials, and returns the user the server takes it for. It
+// is not recorded in the history.
+func CurrentUser(ctx context.Context, profile string) (string, error) {
+	db, err := getConnection(profile)
+	if err != nil {
+		return "", err
+	}
+	queryCtx, cancel := withQueryTimeout(ctx, profile)
+	defer cancel()
+	return readCurrentUser(queryCtx, db)
+}
+
+// readCurrentUser asks conn for the user its queries run as
+func readCurrentUser(ctx context.Context, conn queryer) (string, error) {
+	rows, err := conn.QueryContext(ctx, "SELECT current_user")
+	if err != nil {
+		return "", err
+	}
+	defer rows.Close()
+	var user string
+	if rows.Next() {
+		if err := rows.Scan(&user); err != nil {
+			return "", err
+		}
+	}
+	return user, rows.Err()
+}
+
+// ServerAddress is the host:port of the profile's coordinator
+func ServerAddress(profile string) string {
+	p := profileConfig(profile)
+	return net.JoinHostPort(p.Host, strconv.Itoa(p.Port))
+}
+
+// restClient asks the coordinator's REST API for what SQL doesn't show
+var restClient = &http.Client{Timeout: 10 * time.Second}
+
+// restGet decodes the response of the coordinator's REST API at path into
+// v. The request is authenticated as the profile's connections are, from
+// its DSN; with a custom HTTP client, as Kerberos uses, it has no
+// credentials and only reaches public resources.
+func restGet(ctx context.Context, p config.Profile, path string, v any) error {
+	dsn, err := url.Parse(p.DSN())
+	if err != nil {
+		return err
+	}
+	params := dsn.Query()
+
+	endpoint := url.URL{Scheme: dsn.Scheme, Host: dsn.Host, Path: path}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint.String(), nil)
+	if err != nil {
+		return err
+	}
+	user := dsn.User.Username()
+	req.Header.Set("X-Trino-User", user)
+	if password, ok := dsn.User.Password(); ok {
+		req.SetBasicAuth(user, password)
+	}
+	if token := params.Get("accessToken"); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := restClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned %s", path, resp.Status)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to read %s response: %w", path, err)
+	}
+	return nil
+}
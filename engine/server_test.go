@@ -0,0 +1,74 @@
+package engine
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/TFMV/trino-cli/config"
+)
+
+func TestGetServerInfo(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/info" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"nodeVersion": {"version": "435"}, "environment": "production",
+			"coordinator": true, "starting": false, "uptime": "3.25d"}`))
+	}))
+	defer server.Close()
+	host, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+	portNumber, _ := strconv.Atoi(port)
+
+	saved := config.AppConfig
+	defer func() { config.AppConfig = saved }()
+	config.AppConfig = config.Config{Profiles: map[string]config.Profile{
+		"info": {Host: host, Port: portNumber, User: "ana"},
+	}}
+
+	info, err := GetServerInfo(context.Background(), "info")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := ServerInfo{Version: "435", Environment: "production", Uptime: "3.25d"}
+	if *info != want {
+		t.Errorf("info = %+v, want %+v", *info, want)
+	}
+}
+
+func TestRestGetStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "no", http.StatusUnauthorized)
+	}))
+	defer server.Close()
+	host, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+	portNumber, _ := strconv.Atoi(port)
+
+	var v any
+	err := restGet(context.Background(), config.Profile{Host: host, Port: portNumber, User: "ana"}, "/v1/info", &v)
+	if err == nil || err.Error() != "/v1/info returned 401 Unauthorized" {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestReadCurrentUser(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT current_user").WillReturnRows(sqlmock.NewRows([]string{"_col0"}).AddRow("ana"))
+	user, err := readCurrentUser(context.Background(), db)
+	if err != nil || user != "ana" {
+		t.Errorf("user = %q, %v", user, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,109 @@
+package engine
+
+import (
+	"strings"
+
+	"github.com/TFMV/trino-cli/sqllex"
+)
+
+// Statement is one statement of a script
+type Statement struct {
+	SQL  string // Without the terminating semicolon
+	Line int    // Line of the script the statement starts on, from 1
+}
+
+// SplitStatements splits a script into its statements at the semicolons
+// outside string literals, quoted identifiers and comments. Statements
+// holding nothing but whitespace and comments are dropped.
+func SplitStatements(script string) []Statement {
+	statements, _ := splitStatements(script)
+	return statements
+}
+
+// Terminated reports whether a script ends with a complete statement: no
+// literal or comment is left open, and nothing but whitespace and comments
+// follows its last semicolon. An interactive shell reads lines until then.
+func Terminated(script string) bool {
+	_, terminated := splitStatements(script)
+	return terminated
+}
+
+// splitStatements splits a script as SplitStatements does, and reports
+// whether it is terminated
+func splitStatements(script string) (statements []Statement, terminated bool) {
+	start, line, scanned := 0, 1, 0
+	startLine := 0 // Line of the statement's first token other than a comment, 0 before it
+	open := false  // A literal or comment runs to the end of the script
+
+	add := func(end int) {
+		if startLine > 0 {
+			statements = append(statements, Statement{SQL: strings.TrimSpace(script[start:end]), Line: startLine})
+		}
+		startLine = 0
+	}
+
+	for _, tok := range sqllex.Tokenize(script) {
+		line += strings.Count(script[scanned:tok.Start], "\n")
+		scanned, open = tok.Start, tok.Open
+		switch {
+		case tok.Is(";"):
+			add(tok.Start)
+			start = tok.End
+		case tok.Kind != sqllex.Comment && startLine == 0:
+			startLine = line
+		}
+	}
+	terminated = startLine == 0 && !open
+	add(len(script))
+	return statements, terminated
+}
+
+// readOnlyKeywords are the statements that read data without changing it
+var readOnlyKeywords = map[string]bool{
+	"SELECT": true, "WITH": true, "VALUES": true, "TABLE": true,
+	"SHOW": true, "DESCRIBE": true, "EXPLAIN": true,
+}
+
+// ReadOnly reports whether a statement only reads data, judged by its first
+// keyword. EXPLAIN ANALYZE runs the statement it explains, so it is judged
+// by that statement.
+func ReadOnly(statement string) bool {
+	words := leadingKeywords(statement, 4)
+	if len(words) > 1 && words[0] == "EXPLAIN" && words[1] == "ANALYZE" {
+		words = words[2:]
+		if len(words) > 0 && words[0] == "VERBOSE" {
+			words = words[1:]
+		}
+	}
+	return len(words) > 0 && readOnlyKeywords[words[0]]
+}
+
+// sessionKeywords are the statements that change the session of the
+// connection they run on rather than data: USE, SET SESSION, SET ROLE,
+// RESET SESSION, START TRANSACTION and the like
+var sessionKeywords = map[string]bool{
+	"USE": true, "SET": true, "RESET": true, "START": true,
+}
+
+// ChangesSession reports whether a statement changes the session of the
+// connection it runs on, judged by its first keyword
+func ChangesSession(statement string) bool {
+	words := leadingKeywords(statement, 1)
+	return len(words) > 0 && sessionKeywords[words[0]]
+}
+
+// leadingKeywords returns up to n of the words a statement starts with,
+// upper-cased, past comments and parentheses
+func leadingKeywords(statement string, n int) []string {
+	var words []string
+	for _, tok := range sqllex.Tokenize(statement) {
+		if tok.Kind == sqllex.Comment || tok.Is("(") {
+			continue
+		}
+		if tok.Kind != sqllex.Word || len(words) == n {
+			break
+		}
+		words = append(words, strings.ToUpper(tok.Text))
+	}
+	return words
+}
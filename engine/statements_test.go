@@ -0,0 +1,111 @@
+package engine
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitStatements(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   []Statement
+	}{
+		{"single without semicolon", "SELECT 1", []Statement{{"SELECT 1", 1}}},
+		{"trailing semicolon", "SELECT 1;\n", []Statement{{"SELECT 1", 1}}},
+		{
+			"several on their lines",
+			"USE hive.sales;\n\nSELECT *\nFROM orders;\nSELECT 2;",
+			[]Statement{{"USE hive.sales", 1}, {"SELECT *\nFROM orders", 3}, {"SELECT 2", 5}},
+		},
+		{
+			"semicolons in literals and identifiers",
+			"SELECT 'a;b', \"c;d\" FROM t;SELECT 'it''s;'",
+			[]Statement{{"SELECT 'a;b', \"c;d\" FROM t", 1}, {"SELECT 'it''s;'", 1}},
+		},
+		{
+			"semicolons in comments",
+			"-- first; query\nSELECT 1; /* a;\nb */ SELECT 2",
+			[]Statement{{"-- first; query\nSELECT 1", 2}, {"/* a;\nb */ SELECT 2", 3}},
+		},
+		{"multi-line literal", "SELECT 'a\nb';\nSELECT 2", []Statement{{"SELECT 'a\nb'", 1}, {"SELECT 2", 3}}},
+		{"comments only", "-- nothing here;\n/* or; here */;\n;", nil},
+		{"empty", "  \n", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SplitStatements(tt.script); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SplitStatements(%q) = %+v, want %+v", tt.script, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTerminated(t *testing.T) {
+	tests := map[string]bool{
+		"":                            true,
+		"SELECT 1;":                   true,
+		"SELECT 1;\n  -- done\n":      true,
+		"SELECT 1; /* done */":        true,
+		"SELECT 1":                    false,
+		"SELECT 1;\nSELECT 2":         false,
+		"SELECT 'a;\n":                false,
+		"SELECT \"a;b":                false,
+		"SELECT 1; /* still going;\n": false,
+		"SELECT 1 -- ends here;\n":    false,
+		"SELECT 'it''s';":             true,
+	}
+	for script, want := range tests {
+		if got := Terminated(script); got != want {
+			t.Errorf("Terminated(%q) = %v, want %v", script, got, want)
+		}
+	}
+}
+
+func TestReadOnly(t *testing.T) {
+	tests := map[string]bool{
+		"SELECT 1":                                 true,
+		"  select * from orders":                   true,
+		"-- latest\nSELECT 1":                      true,
+		"/* a */ (SELECT 1) UNION (SELECT 2)":      true,
+		"WITH t AS (SELECT 1) SELECT * FROM t":     true,
+		"SHOW TABLES":                              true,
+		"DESCRIBE orders":                          true,
+		"EXPLAIN INSERT INTO t VALUES (1)":         true,
+		"EXPLAIN ANALYZE SELECT 1":                 true,
+		"EXPLAIN ANALYZE INSERT INTO t VALUES (1)": false,
+		"explain analyze verbose DELETE FROM t":    false,
+		"INSERT INTO t SELECT * FROM s":            false,
+		"DELETE FROM orders":                       false,
+		"CREATE TABLE t AS SELECT 1":               false,
+		"CALL system.sync_partition_metadata('a')": false,
+		"":                  false,
+		"-- only a comment": false,
+		"SELECTED":          false,
+	}
+	for statement, want := range tests {
+		if got := ReadOnly(statement); got != want {
+			t.Errorf("ReadOnly(%q) = %v, want %v", statement, got, want)
+		}
+	}
+}
+
+func TestChangesSession(t *testing.T) {
+	tests := map[string]bool{
+		"USE hive.web":                          true,
+		"-- switch\nuse hive.web":               true,
+		"SET SESSION query_max_run_time = '1h'": true,
+		"RESET SESSION query_max_run_time":      true,
+		"SET ROLE admin":                        true,
+		"START TRANSACTION":                     true,
+		"SELECT 1":                              false,
+		"SHOW SESSION":                          false,
+		"INSERT INTO t VALUES (1)":              false,
+		"":                                      false,
+	}
+	for statement, want := range tests {
+		if got := ChangesSession(statement); got != want {
+			t.Errorf("ChangesSession(%q) = %v, want %v", statement, got, want)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package engine
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/TFMV/trino-cli/config"
+)
+
+// QueryActivity is a query still going on the cluster and what it has used
+// so far
+type QueryActivity struct {
+	ServerQuery
+	Rows   int64         // Input rows processed
+	CPU    time.Duration // CPU time of its splits
+	Memory int64         // Bytes reserved, or -1 when the coordinator can't be asked
+}
+
+// queryActivityTag starts the activity statement so it can leave itself out
+const queryActivityTag = "-- trino-cli: top"
+
+// queryActivitySQL lists every user's unfinished queries with the rows and
+// CPU time of their tasks
+const queryActivitySQL = queryActivityTag + `
+SELECT q.query_id, q.state, q."user", q.query, q.created,
+  coalesce(t.input_rows, 0), coalesce(t.cpu_ms, 0)
+FROM system.runtime.queries q
+LEFT JOIN (
+  SELECT query_id, sum(processed_input_rows) AS input_rows, sum(split_cpu_time_ms) AS cpu_ms
+  FROM system.runtime.tasks
+  GROUP BY query_id
+) t ON t.query_id = q.query_id
+WHERE q.state NOT IN ('FINISHED', 'FAILED') AND q.query NOT LIKE '` + queryActivityTag + `%'`
+
+// ListQueryActivity returns the cluster's queued and running queries. Rows
+// and CPU time come from system.runtime; memory is only reported by the
+// coordinator's REST API, so it is left at -1 for profiles using Kerberos or
+// when the API can't be reached. It is not recorded in the history.
+func ListQueryActivity(ctx context.Context, profile string) ([]QueryActivity, error) {
+	db, err := getConnection(profile)
+	if err != nil {
+		return nil, err
+	}
+	queryCtx, cancel := withQueryTimeout(ctx, profile)
+	defer cancel()
+
+	queries, err := readQueryActivity(queryCtx, db)
+	if err != nil {
+		return nil, err
+	}
+	if memory, err := queryMemory(queryCtx, profileConfig(profile)); err == nil {
+		for i := range queries {
+			if bytes, ok := memory[queries[i].ID]; ok {
+				queries[i].Memory = bytes
+			}
+		}
+	}
+	return queries, nil
+}
+
+// readQueryActivity runs the activity statement on conn
+func readQueryActivity(ctx context.Context, conn queryer) ([]QueryActivity, error) {
+	rows, err := conn.QueryContext(ctx, queryActivitySQL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list queries: %w", err)
+	}
+	defer rows.Close()
+
+	var queries []QueryActivity
+	for rows.Next() {
+		q := QueryActivity{Memory: -1}
+		var created sql.NullTime
+		var cpuMillis int64
+		if err := rows.Scan(&q.ID, &q.State, &q.User, &q.Query, &created, &q.Rows, &cpuMillis); err != nil {
+			return nil, fmt.Errorf("failed to read query list: %w", err)
+		}
+		q.Created = created.Time
+		q.CPU = time.Duration(cpuMillis) * time.Millisecond
+		queries = append(queries, q)
+	}
+	return queries, rows.Err()
+}
+
+// queryMemory returns the memory reserved by each query the coordinator
+// knows of, by ID, from its /v1/query API
+func queryMemory(ctx context.Context, p config.Profile) (map[string]int64, error) {
+	if p.Kerberos.Enabled {
+		return nil, fmt.Errorf("the query API isn't available with a custom HTTP client")
+	}
+	var infos []struct {
+		QueryID    string `json:"queryId"`
+		QueryStats struct {
+			TotalMemoryReservation string `json:"totalMemoryReservation"`
+		} `json:"queryStats"`
+	}
+	if err := restGet(ctx, p, "/v1/query", &infos); err != nil {
+		return nil, err
+	}
+	memory := make(map[string]int64, len(infos))
+	for _, info := range infos {
+		if bytes, err := parseDataSize(info.QueryStats.TotalMemoryReservation); err == nil {
+			memory[info.QueryID] = bytes
+		}
+	}
+	return memory, nil
+}
+
+// dataSizePattern matches sizes as Trino writes them, such as 0B or 1.52MB
+var dataSizePattern = regexp.MustCompile(`^([0-9]+(?:\.[0-9]+)?)\s*(B|kB|MB|GB|TB|PB)$`)
+
+// parseDataSize reads a size in Trino's notation, where units are powers of
+// 1024, as bytes
+func parseDataSize(s string) (int64, error) {
+	m := dataSizePattern.FindStringSubmatch(s)
+	if m == nil {
+		return 0, fmt.Errorf("invalid data size %q", s)
+	}
+	value, err := strconv.ParseFloat(m[1], 64)
+	if err != nil {
+		return 0, err
+	}
+	for _, unit := range []string{"B", "kB", "MB", "GB", "TB", "PB"} {
+		if unit == m[2] {
+			break
+		}
+		value *= 1024
+	}
+	return int64(value), nil
+}
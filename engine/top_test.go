@@ -0,0 +1,92 @@
+package engine
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/TFMV/trino-cli/config"
+)
+
+func TestReadQueryActivity(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	created := time.Date(2024, 3, 5, 14, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(regexp.QuoteMeta("FROM system.runtime.queries q")).WillReturnRows(
+		sqlmock.NewRows([]string{"query_id", "state", "user", "query", "created", "input_rows", "cpu_ms"}).
+			AddRow("20240305_140000_00001_abcde", "RUNNING", "ana", "SELECT * FROM orders", created, 1500, 2500))
+
+	queries, err := readQueryActivity(context.Background(), db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := QueryActivity{
+		ServerQuery: ServerQuery{ID: "20240305_140000_00001_abcde", State: "RUNNING", User: "ana", Query: "SELECT * FROM orders", Created: created},
+		Rows:        1500,
+		CPU:         2500 * time.Millisecond,
+		Memory:      -1,
+	}
+	if len(queries) != 1 || queries[0] != want {
+		t.Errorf("queries = %+v, want %+v", queries, want)
+	}
+}
+
+func TestQueryMemory(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/query" || r.Header.Get("X-Trino-User") != "ana" {
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte(`[
+			{"queryId": "q1", "queryStats": {"totalMemoryReservation": "1.50MB"}},
+			{"queryId": "q2", "queryStats": {"totalMemoryReservation": "0B"}}
+		]`))
+	}))
+	defer server.Close()
+	host, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+	portNumber, _ := strconv.Atoi(port)
+
+	memory, err := queryMemory(context.Background(), config.Profile{Host: host, Port: portNumber, User: "ana"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if memory["q1"] != 1572864 || memory["q2"] != 0 || len(memory) != 2 {
+		t.Errorf("memory = %v", memory)
+	}
+
+	kerberos := config.Profile{Host: host, Port: portNumber, User: "ana"}
+	kerberos.Kerberos.Enabled = true
+	if _, err := queryMemory(context.Background(), kerberos); err == nil {
+		t.Error("expected Kerberos profiles not to use the query API")
+	}
+}
+
+func TestParseDataSize(t *testing.T) {
+	tests := map[string]int64{
+		"0B":     0,
+		"512B":   512,
+		"2kB":    2048,
+		"1.50MB": 1572864,
+		"3GB":    3 << 30,
+	}
+	for s, want := range tests {
+		if got, err := parseDataSize(s); err != nil || got != want {
+			t.Errorf("parseDataSize(%q) = %d, %v; want %d", s, got, err, want)
+		}
+	}
+	for _, s := range []string{"", "12", "1.5 XB", "MB"} {
+		if _, err := parseDataSize(s); err == nil {
+			t.Errorf("parseDataSize(%q) should fail", s)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"time"
@@ -155,6 +156,7 @@ func executeSimpleQuery() error {
 
 	// Add to history (with profile "default")
 	id, err := history.AddQuery(
+		context.Background(),
 		"SELECT id, name, value FROM items ORDER BY id",
 		150*time.Millisecond,
 		len(result.Rows),
@@ -172,7 +174,7 @@ func executeSimpleQuery() error {
 func workWithHistory() error {
 	// Get recent queries
 	fmt.Println("Getting recent queries...")
-	queries, err := history.GetQueries(10, 0)
+	queries, err := history.GetQueries(context.Background(), 10, 0)
 	if err != nil {
 		return fmt.Errorf("failed to get queries: %w", err)
 	}
@@ -185,7 +187,7 @@ func workWithHistory() error {
 
 	// Search for queries
 	fmt.Println("\nSearching for queries containing 'items'...")
-	searchResults, err := history.SearchQueries("items", 5)
+	searchResults, err := history.SearchQueries(context.Background(), "items", 5)
 	if err != nil {
 		return fmt.Errorf("failed to search queries: %w", err)
 	}
@@ -0,0 +1,58 @@
+package history
+
+import (
+	"context"
+	"fmt"
+	"os"
+)
+
+// Health describes the state of the history database
+type Health struct {
+	Path     string   `json:"path"`
+	Size     int64    `json:"size_bytes"`
+	Entries  int      `json:"entries"`
+	Search   bool     `json:"full_text_search"`   // Searches use the FTS5 index rather than LIKE
+	Problems []string `json:"problems,omitempty"` // What SQLite's integrity check found wrong
+}
+
+// Check inspects the history database: its size, how many entries it
+// holds, and whether SQLite finds it intact
+func Check(ctx context.Context) (Health, error) {
+	health := Health{Path: dbPath}
+	if db == nil {
+		return health, fmt.Errorf("history database not initialized")
+	}
+	if info, err := os.Stat(dbPath); err == nil {
+		health.Size = info.Size()
+	}
+
+	rows, err := db.QueryContext(ctx, "PRAGMA quick_check")
+	if err != nil {
+		return health, fmt.Errorf("failed to check history database: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var result string
+		if err := rows.Scan(&result); err != nil {
+			return health, err
+		}
+		if result != "ok" {
+			health.Problems = append(health.Problems, result)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return health, fmt.Errorf("failed to check history database: %w", err)
+	}
+
+	if err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM query_history").Scan(&health.Entries); err != nil {
+		return health, fmt.Errorf("failed to count history entries: %w", err)
+	}
+	var indexes int
+	if err := db.QueryRowContext(ctx,
+		"SELECT COUNT(*) FROM sqlite_master WHERE type = 'trigger' AND name = 'query_history_fts_insert'",
+	).Scan(&indexes); err != nil {
+		return health, fmt.Errorf("failed to inspect search index: %w", err)
+	}
+	health.Search = indexes > 0
+	return health, nil
+}
@@ -0,0 +1,35 @@
+package history
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCheck(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	if err := Initialize(); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	defer Close()
+
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		if _, err := AddQuery(ctx, "SELECT 1", time.Millisecond, 1, "default"); err != nil {
+			t.Fatalf("AddQuery failed: %v", err)
+		}
+	}
+
+	health, err := Check(ctx)
+	if err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+	if want := filepath.Join(home, ".trino-cli", "history", "history.db"); health.Path != want {
+		t.Errorf("Path = %q, want %q", health.Path, want)
+	}
+	if health.Entries != 3 || health.Size == 0 || len(health.Problems) != 0 {
+		t.Errorf("unexpected health: %+v", health)
+	}
+}
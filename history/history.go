@@ -1,6 +1,7 @@
 package history
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"os"
@@ -8,6 +9,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/TFMV/trino-cli/cache"
 	_ "github.com/mattn/go-sqlite3"
 	"go.uber.org/zap"
 )
@@ -20,10 +22,24 @@ type QueryHistory struct {
 	Duration  time.Duration `json:"duration"`
 	Rows      int           `json:"rows"`
 	Profile   string        `json:"profile"`
+	Status    string        `json:"status"`
+	Error     string        `json:"error,omitempty"`
+	ParentID  string        `json:"parent_id,omitempty"` // Entry this query was edited from
+	CacheKey  string        `json:"cache_key,omitempty"` // Cached result captured for this run
 }
 
+// entryColumns lists the columns scanned into a QueryHistory, in order
+const entryColumns = "id, timestamp, query, duration, rows, profile, status, error, parent_id, cache_key"
+
+// Query statuses recorded in the history database
+const (
+	StatusSuccess = "success"
+	StatusFailed  = "failed"
+)
+
 var (
 	db     *sql.DB
+	dbPath string
 	logger *zap.Logger
 )
 
@@ -46,7 +62,7 @@ func Initialize() error {
 		return fmt.Errorf("failed to create history directory: %w", err)
 	}
 
-	dbPath := filepath.Join(historyDir, "history.db")
+	dbPath = filepath.Join(historyDir, "history.db")
 	db, err = sql.Open("sqlite3", dbPath)
 	if err != nil {
 		return fmt.Errorf("failed to open history database: %w", err)
@@ -60,19 +76,81 @@ func Initialize() error {
 		query TEXT NOT NULL,
 		duration INTEGER DEFAULT 0,
 		rows INTEGER DEFAULT 0,
-		profile TEXT NOT NULL
+		profile TEXT NOT NULL,
+		status TEXT NOT NULL DEFAULT 'success',
+		error TEXT NOT NULL DEFAULT ''
 	);
 	CREATE INDEX IF NOT EXISTS idx_query_history_timestamp ON query_history(timestamp);
+	CREATE TABLE IF NOT EXISTS history_meta (
+		key TEXT PRIMARY KEY,
+		value TEXT NOT NULL
+	);
 	`
 
 	if _, err := db.Exec(createTableSQL); err != nil {
 		return fmt.Errorf("failed to create history table: %w", err)
 	}
 
+	// Upgrade databases created before status tracking existed
+	if err := ensureColumn("query_history", "status", "TEXT NOT NULL DEFAULT 'success'"); err != nil {
+		return err
+	}
+	if err := ensureColumn("query_history", "error", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return err
+	}
+	if err := ensureColumn("query_history", "parent_id", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return err
+	}
+	if err := ensureColumn("query_history", "cache_key", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return err
+	}
+	if _, err := db.Exec("CREATE INDEX IF NOT EXISTS idx_query_history_status ON query_history(status)"); err != nil {
+		return fmt.Errorf("failed to create status index: %w", err)
+	}
+
+	if err := setupFullTextSearch(); err != nil {
+		return err
+	}
+
 	logger.Info("History database initialized", zap.String("path", dbPath))
 	return nil
 }
 
+// ensureColumn adds a column to a table if it does not exist yet
+func ensureColumn(table, column, definition string) error {
+	rows, err := db.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return fmt.Errorf("failed to inspect %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			cid       int
+			name      string
+			colType   string
+			notNull   int
+			dfltValue sql.NullString
+			pk        int
+		)
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &dfltValue, &pk); err != nil {
+			return fmt.Errorf("failed to inspect %s: %w", table, err)
+		}
+		if name == column {
+			return nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to inspect %s: %w", table, err)
+	}
+	rows.Close()
+
+	if _, err := db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition)); err != nil {
+		return fmt.Errorf("failed to add column %s.%s: %w", table, column, err)
+	}
+	return nil
+}
+
 // Close closes the database connection
 func Close() error {
 	if db != nil {
@@ -81,8 +159,37 @@ func Close() error {
 	return nil
 }
 
-// AddQuery adds a query to the history database
-func AddQuery(query string, duration time.Duration, rows int, profile string) (string, error) {
+// AddQuery adds a successfully executed query to the history database
+func AddQuery(ctx context.Context, query string, duration time.Duration, rows int, profile string) (string, error) {
+	return addEntry(ctx, query, duration, rows, profile, StatusSuccess, "")
+}
+
+// AddFailedQuery adds a query that failed to the history database along with its error
+func AddFailedQuery(ctx context.Context, query string, duration time.Duration, profile string, queryErr error) (string, error) {
+	errMsg := ""
+	if queryErr != nil {
+		errMsg = queryErr.Error()
+	}
+	return addEntry(ctx, query, duration, 0, profile, StatusFailed, errMsg)
+}
+
+// parentIDKey is the context key under which WithParentID stores an entry ID
+type parentIDKey struct{}
+
+// WithParentID returns a context under which recorded queries are linked to
+// the history entry id, e.g. when a stored query is edited and re-run.
+func WithParentID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, parentIDKey{}, id)
+}
+
+// ParentIDFromContext returns the entry ID set by WithParentID, if any
+func ParentIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(parentIDKey{}).(string)
+	return id
+}
+
+// addEntry inserts a history entry with the given status
+func addEntry(ctx context.Context, query string, duration time.Duration, rows int, profile, status, errMsg string) (string, error) {
 	if db == nil {
 		return "", fmt.Errorf("history database not initialized")
 	}
@@ -91,113 +198,198 @@ func AddQuery(query string, duration time.Duration, rows int, profile string) (s
 	id := fmt.Sprintf("%d", time.Now().UnixNano())
 
 	// Insert the query into the database
-	stmt, err := db.Prepare(`
-		INSERT INTO query_history (id, query, duration, rows, profile)
-		VALUES (?, ?, ?, ?, ?)
+	stmt, err := db.PrepareContext(ctx, `
+		INSERT INTO query_history (id, query, duration, rows, profile, status, error, parent_id)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
 	`)
 	if err != nil {
 		return "", fmt.Errorf("failed to prepare statement: %w", err)
 	}
 	defer stmt.Close()
 
-	_, err = stmt.Exec(id, query, duration.Milliseconds(), rows, profile)
+	_, err = stmt.ExecContext(ctx, id, query, duration.Milliseconds(), rows, profile, status, errMsg, ParentIDFromContext(ctx))
 	if err != nil {
 		return "", fmt.Errorf("failed to insert query: %w", err)
 	}
 
-	logger.Info("Query added to history", zap.String("id", id))
+	logger.Info("Query added to history", zap.String("id", id), zap.String("status", status))
 	return id, nil
 }
 
-// GetQueries retrieves query history entries
-func GetQueries(limit int, offset int) ([]QueryHistory, error) {
+// SetCacheKey links a history entry to the cached copy of its result
+func SetCacheKey(ctx context.Context, id, key string) error {
 	if db == nil {
-		return nil, fmt.Errorf("history database not initialized")
+		return fmt.Errorf("history database not initialized")
 	}
 
-	rows, err := db.Query(`
-		SELECT id, timestamp, query, duration, rows, profile
-		FROM query_history
-		ORDER BY timestamp DESC
-		LIMIT ? OFFSET ?
-	`, limit, offset)
+	res, err := db.ExecContext(ctx, "UPDATE query_history SET cache_key = ? WHERE id = ?", key, id)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query history: %w", err)
+		return fmt.Errorf("failed to set cache key: %w", err)
 	}
-	defer rows.Close()
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("query not found: %s", id)
+	}
+	return nil
+}
 
-	var queries []QueryHistory
-	for rows.Next() {
-		var q QueryHistory
-		var timestamp string
-		var durationMs int64
+// QueryFilter restricts which history entries are returned. Zero-valued
+// fields are ignored.
+type QueryFilter struct {
+	Profile     string
+	Status      string
+	Since       time.Time // Inclusive
+	Until       time.Time // Exclusive
+	MinDuration time.Duration
+	MinRows     int
+}
 
-		if err := rows.Scan(&q.ID, &timestamp, &q.Query, &durationMs, &q.Rows, &q.Profile); err != nil {
-			return nil, fmt.Errorf("failed to scan query: %w", err)
-		}
+// whereClause builds the SQL WHERE clause and arguments for the filter
+func (f QueryFilter) whereClause() (string, []interface{}) {
+	var conditions []string
+	var args []interface{}
 
-		// Parse timestamp
-		t, err := time.Parse("2006-01-02 15:04:05", timestamp)
-		if err != nil {
-			logger.Warn("Failed to parse timestamp", zap.Error(err), zap.String("timestamp", timestamp))
-			t = time.Now() // Fallback to current time
-		}
-		q.Timestamp = t
-		q.Duration = time.Duration(durationMs) * time.Millisecond
+	if f.Profile != "" {
+		conditions = append(conditions, "profile = ?")
+		args = append(args, f.Profile)
+	}
+	if f.Status != "" {
+		conditions = append(conditions, "status = ?")
+		args = append(args, f.Status)
+	}
+	if !f.Since.IsZero() {
+		conditions = append(conditions, "timestamp >= ?")
+		args = append(args, formatTimestamp(f.Since))
+	}
+	if !f.Until.IsZero() {
+		conditions = append(conditions, "timestamp < ?")
+		args = append(args, formatTimestamp(f.Until))
+	}
+	if f.MinDuration > 0 {
+		conditions = append(conditions, "duration >= ?")
+		args = append(args, f.MinDuration.Milliseconds())
+	}
+	if f.MinRows > 0 {
+		conditions = append(conditions, "rows >= ?")
+		args = append(args, f.MinRows)
+	}
 
-		queries = append(queries, q)
+	if len(conditions) == 0 {
+		return "", nil
 	}
+	return "WHERE " + strings.Join(conditions, " AND "), args
+}
 
-	return queries, nil
+// GetQueries retrieves query history entries
+func GetQueries(ctx context.Context, limit int, offset int) ([]QueryHistory, error) {
+	return ListQueries(ctx, QueryFilter{}, limit, offset)
 }
 
-// SearchQueries searches query history with a search term
-func SearchQueries(searchTerm string, limit int) ([]QueryHistory, error) {
+// ListQueries retrieves query history entries matching the filter, newest
+// first. Timestamps have one-second resolution, so entries from the same
+// second are ordered by their time-based IDs.
+func ListQueries(ctx context.Context, filter QueryFilter, limit int, offset int) ([]QueryHistory, error) {
 	if db == nil {
 		return nil, fmt.Errorf("history database not initialized")
 	}
 
-	// Use LIKE for simple search
-	searchPattern := "%" + searchTerm + "%"
-	rows, err := db.Query(`
-		SELECT id, timestamp, query, duration, rows, profile
+	where, args := filter.whereClause()
+	args = append(args, limit, offset)
+
+	rows, err := db.QueryContext(ctx, `
+		SELECT `+entryColumns+`
 		FROM query_history
-		WHERE query LIKE ?
-		ORDER BY timestamp DESC
-		LIMIT ?
-	`, searchPattern, limit)
+		`+where+`
+		ORDER BY timestamp DESC, id DESC
+		LIMIT ? OFFSET ?
+	`, args...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to search history: %w", err)
+		return nil, fmt.Errorf("failed to query history: %w", err)
 	}
 	defer rows.Close()
 
+	return scanQueries(rows)
+}
+
+// CompleteQuery returns the most recent query matching the filter that
+// starts with prefix, ignoring case, preferring queries that succeeded. It
+// returns "" when no query extends the prefix.
+func CompleteQuery(ctx context.Context, prefix string, filter QueryFilter) (string, error) {
+	if db == nil {
+		return "", fmt.Errorf("history database not initialized")
+	}
+
+	where, args := filter.whereClause()
+	if where == "" {
+		where = "WHERE "
+	} else {
+		where += " AND "
+	}
+	escaped := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(prefix)
+	args = append(args, escaped+"%", len(prefix))
+
+	var query string
+	err := db.QueryRowContext(ctx, `
+		SELECT query
+		FROM query_history
+		`+where+`query LIKE ? ESCAPE '\' AND length(CAST(query AS BLOB)) > ?
+		ORDER BY status = '`+StatusSuccess+`' DESC, timestamp DESC, id DESC
+		LIMIT 1
+	`, args...).Scan(&query)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to complete query: %w", err)
+	}
+	return query, nil
+}
+
+// scanQueries reads history entries from a result set
+func scanQueries(rows *sql.Rows) ([]QueryHistory, error) {
 	var queries []QueryHistory
 	for rows.Next() {
 		var q QueryHistory
 		var timestamp string
 		var durationMs int64
 
-		if err := rows.Scan(&q.ID, &timestamp, &q.Query, &durationMs, &q.Rows, &q.Profile); err != nil {
+		if err := rows.Scan(&q.ID, &timestamp, &q.Query, &durationMs, &q.Rows, &q.Profile, &q.Status, &q.Error, &q.ParentID, &q.CacheKey); err != nil {
 			return nil, fmt.Errorf("failed to scan query: %w", err)
 		}
 
-		// Parse timestamp
-		t, err := time.Parse("2006-01-02 15:04:05", timestamp)
-		if err != nil {
-			logger.Warn("Failed to parse timestamp", zap.Error(err), zap.String("timestamp", timestamp))
-			t = time.Now() // Fallback to current time
-		}
-		q.Timestamp = t
+		q.Timestamp = parseTimestamp(timestamp)
 		q.Duration = time.Duration(durationMs) * time.Millisecond
 
 		queries = append(queries, q)
 	}
 
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+
 	return queries, nil
 }
 
+// formatTimestamp formats a time the way SQLite's CURRENT_TIMESTAMP stores it
+func formatTimestamp(t time.Time) string {
+	return t.UTC().Format("2006-01-02 15:04:05")
+}
+
+// parseTimestamp parses a timestamp stored by SQLite
+func parseTimestamp(timestamp string) time.Time {
+	t, err := time.Parse("2006-01-02 15:04:05", timestamp)
+	if err != nil {
+		// go-sqlite3 returns DATETIME columns in RFC3339 form
+		if t, err = time.Parse(time.RFC3339, timestamp); err == nil {
+			return t
+		}
+		logger.Warn("Failed to parse timestamp", zap.Error(err), zap.String("timestamp", timestamp))
+		t = time.Now() // Fallback to current time
+	}
+	return t
+}
+
 // GetQueryByID retrieves a specific query by ID
-func GetQueryByID(id string) (*QueryHistory, error) {
+func GetQueryByID(ctx context.Context, id string) (*QueryHistory, error) {
 	if db == nil {
 		return nil, fmt.Errorf("history database not initialized")
 	}
@@ -206,11 +398,11 @@ func GetQueryByID(id string) (*QueryHistory, error) {
 	var timestamp string
 	var durationMs int64
 
-	err := db.QueryRow(`
-		SELECT id, timestamp, query, duration, rows, profile
+	err := db.QueryRowContext(ctx, `
+		SELECT `+entryColumns+`
 		FROM query_history
 		WHERE id = ?
-	`, id).Scan(&q.ID, &timestamp, &q.Query, &durationMs, &q.Rows, &q.Profile)
+	`, id).Scan(&q.ID, &timestamp, &q.Query, &durationMs, &q.Rows, &q.Profile, &q.Status, &q.Error, &q.ParentID, &q.CacheKey)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -219,20 +411,14 @@ func GetQueryByID(id string) (*QueryHistory, error) {
 		return nil, fmt.Errorf("failed to get query: %w", err)
 	}
 
-	// Parse timestamp
-	t, err := time.Parse("2006-01-02 15:04:05", timestamp)
-	if err != nil {
-		logger.Warn("Failed to parse timestamp", zap.Error(err), zap.String("timestamp", timestamp))
-		t = time.Now() // Fallback to current time
-	}
-	q.Timestamp = t
+	q.Timestamp = parseTimestamp(timestamp)
 	q.Duration = time.Duration(durationMs) * time.Millisecond
 
 	return &q, nil
 }
 
 // ClearHistory clears all or part of the query history
-func ClearHistory(olderThan time.Time) (int64, error) {
+func ClearHistory(ctx context.Context, olderThan time.Time) (int64, error) {
 	if db == nil {
 		return 0, fmt.Errorf("history database not initialized")
 	}
@@ -240,18 +426,27 @@ func ClearHistory(olderThan time.Time) (int64, error) {
 	var result sql.Result
 	var err error
 
+	where, args := "", []interface{}{}
 	if olderThan.IsZero() {
 		// Clear all history
-		result, err = db.Exec("DELETE FROM query_history")
+		olderThan = time.Now()
 	} else {
 		// Clear history older than specified time
-		timeStr := olderThan.Format("2006-01-02 15:04:05")
-		result, err = db.Exec("DELETE FROM query_history WHERE timestamp < ?", timeStr)
+		where, args = "WHERE timestamp < ?", []interface{}{formatTimestamp(olderThan)}
 	}
 
+	keys, err := linkedCacheKeys(ctx, where, args...)
+	if err != nil {
+		return 0, err
+	}
+	result, err = db.ExecContext(ctx, "DELETE FROM query_history "+where, args...)
 	if err != nil {
 		return 0, fmt.Errorf("failed to clear history: %w", err)
 	}
+	dropCachedResults(keys)
+	if err := raiseHorizon(ctx, formatTimestamp(olderThan)); err != nil {
+		return 0, err
+	}
 
 	rowsAffected, err := result.RowsAffected()
 	if err != nil {
@@ -261,10 +456,152 @@ func ClearHistory(olderThan time.Time) (int64, error) {
 	return rowsAffected, nil
 }
 
+// PruneResult reports how many entries a retention pass removed
+type PruneResult struct {
+	Expired   int64 // Older than the maximum age
+	OverLimit int64 // Beyond the maximum number of entries
+}
+
+// Prune applies a retention policy, deleting entries older than maxAge and
+// then the oldest entries beyond maxEntries. Zero disables either limit.
+func Prune(ctx context.Context, maxEntries int, maxAge time.Duration) (PruneResult, error) {
+	var result PruneResult
+	if db == nil {
+		return result, fmt.Errorf("history database not initialized")
+	}
+
+	if maxAge > 0 {
+		cutoff := formatTimestamp(time.Now().Add(-maxAge))
+		keys, err := linkedCacheKeys(ctx, "WHERE timestamp < ?", cutoff)
+		if err != nil {
+			return result, err
+		}
+		res, err := db.ExecContext(ctx, "DELETE FROM query_history WHERE timestamp < ?", cutoff)
+		if err != nil {
+			return result, fmt.Errorf("failed to prune expired history: %w", err)
+		}
+		dropCachedResults(keys)
+		if result.Expired, err = res.RowsAffected(); err != nil {
+			return result, fmt.Errorf("failed to get rows affected: %w", err)
+		}
+		if err := raiseHorizon(ctx, cutoff); err != nil {
+			return result, err
+		}
+	}
+
+	if maxEntries > 0 {
+		overLimit := `WHERE id NOT IN (
+				SELECT id FROM query_history ORDER BY timestamp DESC, id DESC LIMIT ?
+			)`
+		keys, err := linkedCacheKeys(ctx, overLimit, maxEntries)
+		if err != nil {
+			return result, err
+		}
+		res, err := db.ExecContext(ctx, "DELETE FROM query_history "+overLimit, maxEntries)
+		if err != nil {
+			return result, fmt.Errorf("failed to prune history beyond limit: %w", err)
+		}
+		dropCachedResults(keys)
+		if result.OverLimit, err = res.RowsAffected(); err != nil {
+			return result, fmt.Errorf("failed to get rows affected: %w", err)
+		}
+		if result.OverLimit > 0 {
+			var oldest string
+			if err := db.QueryRowContext(ctx, "SELECT COALESCE(MIN(timestamp), '') FROM query_history").Scan(&oldest); err != nil {
+				return result, fmt.Errorf("failed to find oldest history entry: %w", err)
+			}
+			if err := raiseHorizon(ctx, oldest); err != nil {
+				return result, err
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// linkedCacheKeys returns the keys of the cached results linked to the
+// entries a WHERE clause selects
+func linkedCacheKeys(ctx context.Context, where string, args ...interface{}) ([]string, error) {
+	query := "SELECT cache_key FROM query_history " + where
+	if where == "" {
+		query += "WHERE cache_key != ''"
+	} else {
+		query += " AND cache_key != ''"
+	}
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find cached results: %w", err)
+	}
+	defer rows.Close()
+
+	var keys []string
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return nil, fmt.Errorf("failed to find cached results: %w", err)
+		}
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
+
+// dropCachedResults deletes the cached results of deleted entries, so that
+// the result cache doesn't outlive the history. A result that can't be
+// deleted is only logged, as its entry is gone already.
+func dropCachedResults(keys []string) {
+	for _, key := range keys {
+		if err := cache.Delete(key); err != nil {
+			logger.Warn("Failed to delete cached result", zap.String("key", key), zap.Error(err))
+		}
+	}
+}
+
+// raiseHorizon records that history before timestamp was deleted, so that
+// syncing does not bring it back. The horizon only ever moves forward.
+func raiseHorizon(ctx context.Context, timestamp string) error {
+	if timestamp == "" {
+		return nil
+	}
+	_, err := db.ExecContext(ctx, `
+		INSERT INTO history_meta (key, value) VALUES ('horizon', ?)
+		ON CONFLICT(key) DO UPDATE SET value = max(value, excluded.value)
+	`, timestamp)
+	if err != nil {
+		return fmt.Errorf("failed to record history horizon: %w", err)
+	}
+	return nil
+}
+
+// horizon returns the time before which history was cleared or pruned, or
+// the zero time if none was
+func horizon(ctx context.Context) (time.Time, error) {
+	var value string
+	err := db.QueryRowContext(ctx, "SELECT value FROM history_meta WHERE key = 'horizon'").Scan(&value)
+	if err == sql.ErrNoRows {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to read history horizon: %w", err)
+	}
+	return parseTimestamp(value), nil
+}
+
+// Vacuum reclaims disk space left behind by deleted history entries
+func Vacuum(ctx context.Context) error {
+	if db == nil {
+		return fmt.Errorf("history database not initialized")
+	}
+
+	if _, err := db.ExecContext(ctx, "VACUUM"); err != nil {
+		return fmt.Errorf("failed to vacuum history database: %w", err)
+	}
+	return nil
+}
+
 // FuzzySearchQueries performs a fuzzy search on the query history
-func FuzzySearchQueries(searchTerm string, limit int) ([]QueryHistory, error) {
+func FuzzySearchQueries(ctx context.Context, searchTerm string, limit int) ([]QueryHistory, error) {
 	// Get all queries first (with a reasonable limit)
-	queries, err := GetQueries(1000, 0)
+	queries, err := GetQueries(ctx, 1000, 0)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,173 @@
+package history
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/TFMV/trino-cli/cache"
+)
+
+func TestPrune(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	if err := Initialize(); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	defer Close()
+
+	ctx := context.Background()
+	var ids []string
+	for i := 0; i < 5; i++ {
+		id, err := AddQuery(ctx, "SELECT 1", time.Millisecond, 1, "default")
+		if err != nil {
+			t.Fatalf("AddQuery failed: %v", err)
+		}
+		ids = append(ids, id)
+	}
+
+	// Age the first two entries past the retention window
+	old := formatTimestamp(time.Now().Add(-48 * time.Hour))
+	if _, err := db.Exec("UPDATE query_history SET timestamp = ? WHERE id IN (?, ?)", old, ids[0], ids[1]); err != nil {
+		t.Fatalf("failed to age entries: %v", err)
+	}
+
+	result, err := Prune(ctx, 2, 24*time.Hour)
+	if err != nil {
+		t.Fatalf("Prune failed: %v", err)
+	}
+	if result.Expired != 2 || result.OverLimit != 1 {
+		t.Errorf("unexpected prune result: %+v", result)
+	}
+
+	remaining, err := GetQueries(ctx, 10, 0)
+	if err != nil {
+		t.Fatalf("GetQueries failed: %v", err)
+	}
+	if len(remaining) != 2 || remaining[0].ID != ids[4] || remaining[1].ID != ids[3] {
+		t.Errorf("expected the two newest entries to remain, got %+v", remaining)
+	}
+
+	// Zero limits keep everything
+	if result, err = Prune(ctx, 0, 0); err != nil || result != (PruneResult{}) {
+		t.Errorf("expected no-op prune, got %+v, %v", result, err)
+	}
+}
+
+func TestPruneAndClearDropCachedResults(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	if err := Initialize(); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	defer Close()
+
+	ctx := context.Background()
+	var ids []string
+	for i := 0; i < 3; i++ {
+		id, err := AddQuery(ctx, "SELECT 1", time.Millisecond, 1, "default")
+		if err != nil {
+			t.Fatalf("AddQuery failed: %v", err)
+		}
+		if err := cache.Save(id, []byte("result")); err != nil {
+			t.Fatal(err)
+		}
+		if err := SetCacheKey(ctx, id, id); err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, id)
+	}
+	if err := cache.Save("saved-by-hand", []byte("result")); err != nil {
+		t.Fatal(err)
+	}
+
+	old := formatTimestamp(time.Now().Add(-48 * time.Hour))
+	if _, err := db.Exec("UPDATE query_history SET timestamp = ? WHERE id = ?", old, ids[0]); err != nil {
+		t.Fatalf("failed to age entry: %v", err)
+	}
+	if _, err := Prune(ctx, 0, 24*time.Hour); err != nil {
+		t.Fatalf("Prune failed: %v", err)
+	}
+	if _, err := cache.Load(ids[0]); !errors.Is(err, cache.ErrNotFound) {
+		t.Errorf("cached result of a pruned entry: %v, want it deleted", err)
+	}
+	if _, err := cache.Load(ids[1]); err != nil {
+		t.Errorf("cached result of a kept entry: %v", err)
+	}
+
+	if _, err := ClearHistory(ctx, time.Time{}); err != nil {
+		t.Fatalf("ClearHistory failed: %v", err)
+	}
+	keys, err := cache.ListCache()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 1 || keys[0] != "saved-by-hand" {
+		t.Errorf("cache after clearing history = %v, want only the unlinked result", keys)
+	}
+}
+
+func TestParentIDLink(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	if err := Initialize(); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	defer Close()
+
+	ctx := context.Background()
+	parent, err := AddQuery(ctx, "SELECT 1", time.Millisecond, 1, "default")
+	if err != nil {
+		t.Fatalf("AddQuery failed: %v", err)
+	}
+	child, err := AddFailedQuery(WithParentID(ctx, parent), "SELECT 2", time.Millisecond, "default", nil)
+	if err != nil {
+		t.Fatalf("AddFailedQuery failed: %v", err)
+	}
+
+	entry, err := GetQueryByID(ctx, child)
+	if err != nil {
+		t.Fatalf("GetQueryByID failed: %v", err)
+	}
+	if entry.ParentID != parent {
+		t.Errorf("expected parent %s, got %q", parent, entry.ParentID)
+	}
+}
+
+func TestCompleteQuery(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	if err := Initialize(); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	defer Close()
+
+	ctx := context.Background()
+	for _, q := range []string{"SELECT * FROM orders", "SELECT 100% FROM t", "SELECT * FROM orders WHERE id = 1"} {
+		if _, err := AddQuery(ctx, q, time.Millisecond, 1, "default"); err != nil {
+			t.Fatalf("AddQuery failed: %v", err)
+		}
+	}
+	if _, err := AddFailedQuery(ctx, "SELECT * FROM ordrs", time.Millisecond, "default", nil); err != nil {
+		t.Fatalf("AddFailedQuery failed: %v", err)
+	}
+	if _, err := AddQuery(ctx, "SELECT * FROM other_profile", time.Millisecond, 1, "other"); err != nil {
+		t.Fatalf("AddQuery failed: %v", err)
+	}
+
+	filter := QueryFilter{Profile: "default"}
+	tests := []struct{ prefix, want string }{
+		{"select * from ord", "SELECT * FROM orders WHERE id = 1"}, // Newest success, ignoring case
+		{"SELECT * FROM orders WHERE", "SELECT * FROM orders WHERE id = 1"},
+		{"SELECT 100%", "SELECT 100% FROM t"},
+		{"SELECT 1_", ""}, // Wildcards in the prefix are literal
+		{"SELECT * FROM orders WHERE id = 1", ""},
+		{"SELECT * FROM oth", ""}, // Another profile's query
+	}
+	for _, tt := range tests {
+		got, err := CompleteQuery(ctx, tt.prefix, filter)
+		if err != nil {
+			t.Fatalf("CompleteQuery(%q) failed: %v", tt.prefix, err)
+		}
+		if got != tt.want {
+			t.Errorf("CompleteQuery(%q) = %q, want %q", tt.prefix, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,191 @@
+package history
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// ftsEnabled reports whether the FTS5 index is available. go-sqlite3 only
+// includes FTS5 when built with the sqlite_fts5 tag; without it searches fall
+// back to LIKE.
+var ftsEnabled bool
+
+// setupFullTextSearch creates the FTS5 index over query text and keeps it in
+// sync with triggers. Index rowids are the numeric history IDs so they survive
+// VACUUM, which may renumber the history table's own rowids.
+func setupFullTextSearch() error {
+	// Triggers are missing on a fresh index and after a build without FTS5
+	// opened the database; either way the index must be rebuilt.
+	var synced int
+	if err := db.QueryRow(
+		"SELECT COUNT(*) FROM sqlite_master WHERE type = 'trigger' AND name = 'query_history_fts_insert'",
+	).Scan(&synced); err != nil {
+		return fmt.Errorf("failed to inspect search index: %w", err)
+	}
+
+	var available bool
+	if err := db.QueryRow("SELECT sqlite_compileoption_used('ENABLE_FTS5')").Scan(&available); err != nil {
+		return fmt.Errorf("failed to check for FTS5: %w", err)
+	}
+	if !available {
+		// Triggers left by an FTS5 build would make every insert fail
+		if _, err := db.Exec(`
+			DROP TRIGGER IF EXISTS query_history_fts_insert;
+			DROP TRIGGER IF EXISTS query_history_fts_delete;
+			DROP TRIGGER IF EXISTS query_history_fts_update;
+		`); err != nil {
+			return fmt.Errorf("failed to drop search index triggers: %w", err)
+		}
+		logger.Info("FTS5 unavailable, history search will use LIKE (build with -tags sqlite_fts5 to enable)")
+		return nil
+	}
+
+	if _, err := db.Exec("CREATE VIRTUAL TABLE IF NOT EXISTS query_history_fts USING fts5(query)"); err != nil {
+		return fmt.Errorf("failed to create search index: %w", err)
+	}
+
+	triggers := `
+	CREATE TRIGGER IF NOT EXISTS query_history_fts_insert AFTER INSERT ON query_history BEGIN
+		INSERT INTO query_history_fts(rowid, query) VALUES (CAST(new.id AS INTEGER), new.query);
+	END;
+	CREATE TRIGGER IF NOT EXISTS query_history_fts_delete AFTER DELETE ON query_history BEGIN
+		DELETE FROM query_history_fts WHERE rowid = CAST(old.id AS INTEGER);
+	END;
+	CREATE TRIGGER IF NOT EXISTS query_history_fts_update AFTER UPDATE OF query ON query_history BEGIN
+		UPDATE query_history_fts SET query = new.query WHERE rowid = CAST(old.id AS INTEGER);
+	END;
+	`
+	if _, err := db.Exec(triggers); err != nil {
+		return fmt.Errorf("failed to create search index triggers: %w", err)
+	}
+
+	if synced == 0 {
+		if _, err := db.Exec(`
+			DELETE FROM query_history_fts;
+			INSERT INTO query_history_fts(rowid, query) SELECT CAST(id AS INTEGER), query FROM query_history;
+		`); err != nil {
+			return fmt.Errorf("failed to build search index: %w", err)
+		}
+		logger.Info("Built history search index")
+	}
+
+	ftsEnabled = true
+	return nil
+}
+
+// FullTextSearch reports whether searches use the FTS5 index rather than
+// substring matching
+func FullTextSearch() bool {
+	return ftsEnabled
+}
+
+// SearchQueries searches query history with a search term, newest first.
+// With FTS5 the term matches whole words; quote a phrase ("order by") or end
+// a word with * to match a prefix (ord*).
+func SearchQueries(ctx context.Context, searchTerm string, limit int) ([]QueryHistory, error) {
+	if db == nil {
+		return nil, fmt.Errorf("history database not initialized")
+	}
+
+	if ftsEnabled {
+		match := ftsQuery(searchTerm)
+		if match == "" {
+			return ListQueries(ctx, QueryFilter{}, limit, 0)
+		}
+
+		rows, err := db.QueryContext(ctx, `
+			SELECT `+entryColumns+`
+			FROM query_history
+			WHERE id IN (
+				SELECT CAST(rowid AS TEXT) FROM query_history_fts WHERE query_history_fts MATCH ?
+			)
+			ORDER BY timestamp DESC
+			LIMIT ?
+		`, match, limit)
+		if err == nil {
+			defer rows.Close()
+			return scanQueries(rows)
+		}
+		logger.Warn("Full-text search failed, falling back to LIKE", zap.Error(err))
+	}
+
+	// Use LIKE for simple search
+	searchPattern := "%" + searchTerm + "%"
+	rows, err := db.QueryContext(ctx, `
+		SELECT `+entryColumns+`
+		FROM query_history
+		WHERE query LIKE ?
+		ORDER BY timestamp DESC
+		LIMIT ?
+	`, searchPattern, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search history: %w", err)
+	}
+	defer rows.Close()
+
+	return scanQueries(rows)
+}
+
+// ftsQuery turns a user search term into an FTS5 MATCH expression. Quoted
+// phrases and trailing-* prefixes are kept, AND/OR/NOT pass through as
+// operators, and every other word is quoted so SQL punctuation such as
+// "orders.id" or "count(*)" cannot break the query syntax.
+func ftsQuery(term string) string {
+	var parts []string
+	runes := []rune(term)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case r == ' ' || r == '\t' || r == '\n':
+			i++
+		case r == '"':
+			// Quoted phrase; an unterminated quote runs to the end
+			j := i + 1
+			for j < len(runes) && runes[j] != '"' {
+				j++
+			}
+			if phrase := strings.TrimSpace(string(runes[i+1 : j])); phrase != "" {
+				parts = append(parts, quoteFTS(phrase))
+			}
+			i = j + 1
+		default:
+			j := i
+			for j < len(runes) && runes[j] != ' ' && runes[j] != '\t' && runes[j] != '\n' && runes[j] != '"' {
+				j++
+			}
+			word := string(runes[i:j])
+			i = j
+
+			switch {
+			case word == "AND" || word == "OR" || word == "NOT":
+				parts = append(parts, word)
+			case strings.HasSuffix(word, "*") && len(strings.TrimRight(word, "*")) > 0:
+				parts = append(parts, quoteFTS(strings.TrimRight(word, "*"))+"*")
+			case strings.Trim(word, "*") != "":
+				parts = append(parts, quoteFTS(word))
+			}
+		}
+	}
+
+	// Operators are only valid between terms
+	for len(parts) > 0 && isFTSOperator(parts[0]) {
+		parts = parts[1:]
+	}
+	for len(parts) > 0 && isFTSOperator(parts[len(parts)-1]) {
+		parts = parts[:len(parts)-1]
+	}
+	return strings.Join(parts, " ")
+}
+
+// quoteFTS wraps s as an FTS5 string, doubling embedded quotes
+func quoteFTS(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+}
+
+func isFTSOperator(s string) bool {
+	return s == "AND" || s == "OR" || s == "NOT"
+}
@@ -0,0 +1,78 @@
+package history
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestFTSQuery(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"orders", `"orders"`},
+		{"join users", `"join" "users"`},
+		{`"order by" total`, `"order by" "total"`},
+		{"ord*", `"ord"*`},
+		{"orders.id count(*)", `"orders.id" "count(*)"`},
+		{"orders OR users", `"orders" OR "users"`},
+		{"AND orders NOT", `"orders"`},
+		{`say "hi`, `"say" "hi"`},
+		{"  ", ""},
+		{"*", ""},
+	}
+
+	for _, tt := range tests {
+		if got := ftsQuery(tt.in); got != tt.want {
+			t.Errorf("ftsQuery(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestSearchQueries(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	if err := Initialize(); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	defer Close()
+
+	ctx := context.Background()
+	for _, q := range []string{
+		"SELECT * FROM orders WHERE total > 10",
+		"SELECT name FROM users",
+		"SELECT o.id FROM orders o JOIN users u ON o.user_id = u.id",
+	} {
+		if _, err := AddQuery(ctx, q, time.Millisecond, 1, "default"); err != nil {
+			t.Fatalf("AddQuery failed: %v", err)
+		}
+	}
+
+	results, err := SearchQueries(ctx, "orders", 10)
+	if err != nil {
+		t.Fatalf("SearchQueries failed: %v", err)
+	}
+	if len(results) != 2 {
+		t.Errorf("expected 2 matches for orders, got %d", len(results))
+	}
+
+	results, err = SearchQueries(ctx, "users", 10)
+	if err != nil {
+		t.Fatalf("SearchQueries failed: %v", err)
+	}
+	if len(results) != 2 {
+		t.Errorf("expected 2 matches for users, got %d", len(results))
+	}
+
+	// Deleted entries must drop out of the index too
+	if _, err := ClearHistory(ctx, time.Time{}); err != nil {
+		t.Fatalf("ClearHistory failed: %v", err)
+	}
+	results, err = SearchQueries(ctx, "orders", 10)
+	if err != nil {
+		t.Fatalf("SearchQueries failed: %v", err)
+	}
+	if len(results) != 0 {
+		t.Errorf("expected no matches after clearing, got %d", len(results))
+	}
+}
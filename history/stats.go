@@ -0,0 +1,240 @@
+package history
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// QueryFrequency is a normalized query and how often it was run
+type QueryFrequency struct {
+	Query       string        `json:"query"`
+	Count       int           `json:"count"`
+	AvgDuration time.Duration `json:"avg_duration"`
+	LastRun     time.Time     `json:"last_run"`
+}
+
+// ProfileStats summarizes the queries run against one profile
+type ProfileStats struct {
+	Profile     string        `json:"profile"`
+	Count       int           `json:"count"`
+	Failed      int           `json:"failed"`
+	AvgDuration time.Duration `json:"avg_duration"`
+}
+
+// DailyVolume is the number of queries run on one local day
+type DailyVolume struct {
+	Day    string `json:"day"` // YYYY-MM-DD in local time
+	Count  int    `json:"count"`
+	Failed int    `json:"failed"`
+}
+
+// Stats is an aggregate report over the query history
+type Stats struct {
+	TotalQueries  int              `json:"total_queries"`
+	FailedQueries int              `json:"failed_queries"`
+	MostFrequent  []QueryFrequency `json:"most_frequent"`
+	Slowest       []QueryHistory   `json:"slowest"`
+	Profiles      []ProfileStats   `json:"profiles"`
+	Volume        []DailyVolume    `json:"volume"`
+}
+
+// GetStats computes aggregate statistics over the history entries matching
+// filter. limit bounds the frequent and slowest query lists.
+func GetStats(ctx context.Context, filter QueryFilter, limit int) (*Stats, error) {
+	if db == nil {
+		return nil, fmt.Errorf("history database not initialized")
+	}
+
+	where, args := filter.whereClause()
+	stats := &Stats{}
+
+	err := db.QueryRowContext(ctx, `
+		SELECT COUNT(*), COALESCE(SUM(CASE WHEN status = 'failed' THEN 1 ELSE 0 END), 0)
+		FROM query_history
+		`+where, args...).Scan(&stats.TotalQueries, &stats.FailedQueries)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count history: %w", err)
+	}
+
+	if stats.MostFrequent, err = mostFrequent(ctx, where, args, limit); err != nil {
+		return nil, err
+	}
+
+	rows, err := db.QueryContext(ctx, `
+		SELECT `+entryColumns+`
+		FROM query_history
+		`+where+`
+		ORDER BY duration DESC
+		LIMIT ?
+	`, append(args, limit)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query slowest queries: %w", err)
+	}
+	stats.Slowest, err = scanQueries(rows)
+	rows.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err = db.QueryContext(ctx, `
+		SELECT profile, COUNT(*),
+			SUM(CASE WHEN status = 'failed' THEN 1 ELSE 0 END),
+			AVG(duration)
+		FROM query_history
+		`+where+`
+		GROUP BY profile
+		ORDER BY COUNT(*) DESC
+	`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query profile counts: %w", err)
+	}
+	for rows.Next() {
+		var p ProfileStats
+		var avgMs float64
+		if err := rows.Scan(&p.Profile, &p.Count, &p.Failed, &avgMs); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan profile counts: %w", err)
+		}
+		p.AvgDuration = time.Duration(avgMs) * time.Millisecond
+		stats.Profiles = append(stats.Profiles, p)
+	}
+	err = rows.Err()
+	rows.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read profile counts: %w", err)
+	}
+
+	rows, err = db.QueryContext(ctx, `
+		SELECT date(timestamp, 'localtime') AS day, COUNT(*),
+			SUM(CASE WHEN status = 'failed' THEN 1 ELSE 0 END)
+		FROM query_history
+		`+where+`
+		GROUP BY day
+		ORDER BY day
+	`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query volume: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var v DailyVolume
+		if err := rows.Scan(&v.Day, &v.Count, &v.Failed); err != nil {
+			return nil, fmt.Errorf("failed to scan volume: %w", err)
+		}
+		stats.Volume = append(stats.Volume, v)
+	}
+
+	return stats, rows.Err()
+}
+
+// mostFrequent groups queries by their normalized form and returns the most
+// common ones. Normalization happens in Go, so every matching query is read.
+func mostFrequent(ctx context.Context, where string, args []interface{}, limit int) ([]QueryFrequency, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT query, duration, timestamp
+		FROM query_history
+		`+where, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query history: %w", err)
+	}
+	defer rows.Close()
+
+	type bucket struct {
+		freq    QueryFrequency
+		totalMs int64
+	}
+	buckets := make(map[string]*bucket)
+	for rows.Next() {
+		var query, timestamp string
+		var durationMs int64
+		if err := rows.Scan(&query, &durationMs, &timestamp); err != nil {
+			return nil, fmt.Errorf("failed to scan query: %w", err)
+		}
+
+		key := NormalizeQuery(query)
+		b, ok := buckets[key]
+		if !ok {
+			b = &bucket{freq: QueryFrequency{Query: key}}
+			buckets[key] = b
+		}
+		b.freq.Count++
+		b.totalMs += durationMs
+		if t := parseTimestamp(timestamp); t.After(b.freq.LastRun) {
+			b.freq.LastRun = t
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+
+	result := make([]QueryFrequency, 0, len(buckets))
+	for _, b := range buckets {
+		b.freq.AvgDuration = time.Duration(b.totalMs/int64(b.freq.Count)) * time.Millisecond
+		result = append(result, b.freq)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+		return result[i].LastRun.After(result[j].LastRun)
+	})
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+// NormalizeQuery reduces a query to its shape so that runs differing only in
+// literals, whitespace, or keyword case are grouped together. String and
+// numeric literals become "?", and a trailing semicolon is dropped.
+func NormalizeQuery(query string) string {
+	var b strings.Builder
+	runes := []rune(strings.TrimSpace(query))
+	lastSpace := false
+
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == '\'':
+			// Skip the string literal, honoring '' escapes
+			i++
+			for i < len(runes) {
+				if runes[i] == '\'' {
+					if i+1 < len(runes) && runes[i+1] == '\'' {
+						i += 2
+						continue
+					}
+					break
+				}
+				i++
+			}
+			b.WriteRune('?')
+			lastSpace = false
+		case unicode.IsDigit(r) && (i == 0 || !isIdentRune(runes[i-1])):
+			for i+1 < len(runes) && (unicode.IsDigit(runes[i+1]) || runes[i+1] == '.') {
+				i++
+			}
+			b.WriteRune('?')
+			lastSpace = false
+		case unicode.IsSpace(r):
+			if !lastSpace && b.Len() > 0 {
+				b.WriteRune(' ')
+				lastSpace = true
+			}
+		default:
+			b.WriteRune(unicode.ToLower(r))
+			lastSpace = false
+		}
+	}
+
+	return strings.TrimSuffix(strings.TrimSpace(b.String()), ";")
+}
+
+// isIdentRune reports whether r can appear inside an unquoted identifier
+func isIdentRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
@@ -0,0 +1,26 @@
+package history
+
+import "testing"
+
+func TestNormalizeQuery(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"SELECT * FROM orders WHERE id = 42", "select * from orders where id = ?"},
+		{"select *\n  from   orders\twhere id=7;", "select * from orders where id=?"},
+		{"SELECT name FROM users WHERE name = 'O''Brien'", "select name from users where name = ?"},
+		{"SELECT col1, t2.x FROM t2 LIMIT 10", "select col1, t2.x from t2 limit ?"},
+		{"SELECT 3.14", "select ?"},
+	}
+
+	for _, tt := range tests {
+		if got := NormalizeQuery(tt.in); got != tt.want {
+			t.Errorf("NormalizeQuery(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+
+	if NormalizeQuery("SELECT * FROM t WHERE a = 1") != NormalizeQuery("select * from t where a = 2") {
+		t.Error("expected queries differing only in literals to normalize identically")
+	}
+}
@@ -0,0 +1,446 @@
+package history
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ErrConflict is returned by Backend.Push when the remote copy changed since
+// it was pulled
+var ErrConflict = errors.New("remote history changed concurrently")
+
+// Backend stores a shared copy of the history. Pull returns the remote
+// entries and an opaque version (e.g. an ETag); Push uploads entries only if
+// the remote is still at that version, returning ErrConflict otherwise.
+type Backend interface {
+	Pull(ctx context.Context) (entries []QueryHistory, version string, err error)
+	Push(ctx context.Context, entries []QueryHistory, version string) error
+}
+
+// SyncResult reports what a sync changed
+type SyncResult struct {
+	Pulled int `json:"pulled"` // Remote entries added locally
+	Pushed int `json:"pushed"` // Local entries added remotely
+}
+
+// syncAttempts bounds retries when another machine pushes concurrently
+const syncAttempts = 3
+
+// Sync merges the local history with the backend. Entries are immutable and
+// identified by ID, so the merge is a union and never conflicts.
+func Sync(ctx context.Context, backend Backend, pull, push bool) (SyncResult, error) {
+	var result SyncResult
+	if db == nil {
+		return result, fmt.Errorf("history database not initialized")
+	}
+
+	for attempt := 1; attempt <= syncAttempts; attempt++ {
+		remote, version, err := backend.Pull(ctx)
+		if err != nil {
+			return result, fmt.Errorf("failed to pull history: %w", err)
+		}
+
+		if pull {
+			added, err := importEntries(ctx, remote)
+			if err != nil {
+				return result, err
+			}
+			result.Pulled += added
+		}
+		if !push {
+			return result, nil
+		}
+
+		local, err := ListQueries(ctx, QueryFilter{}, -1, 0)
+		if err != nil {
+			return result, err
+		}
+
+		// Cache keys point at files on this machine only
+		for i := range local {
+			local[i].CacheKey = ""
+		}
+
+		merged, added := mergeEntries(remote, local)
+		if added == 0 {
+			return result, nil
+		}
+
+		err = backend.Push(ctx, merged, version)
+		if errors.Is(err, ErrConflict) {
+			logger.Info("Remote history changed during sync, retrying", zap.Int("attempt", attempt))
+			continue
+		}
+		if err != nil {
+			return result, fmt.Errorf("failed to push history: %w", err)
+		}
+		result.Pushed = added
+		return result, nil
+	}
+	return result, ErrConflict
+}
+
+// mergeEntries returns the union of remote and local ordered by time, and how
+// many local entries were missing remotely
+func mergeEntries(remote, local []QueryHistory) ([]QueryHistory, int) {
+	seen := make(map[string]bool, len(remote))
+	merged := make([]QueryHistory, 0, len(remote)+len(local))
+	for _, q := range remote {
+		if !seen[q.ID] {
+			seen[q.ID] = true
+			merged = append(merged, q)
+		}
+	}
+
+	added := 0
+	for _, q := range local {
+		if !seen[q.ID] {
+			seen[q.ID] = true
+			merged = append(merged, q)
+			added++
+		}
+	}
+
+	sort.SliceStable(merged, func(i, j int) bool {
+		if !merged[i].Timestamp.Equal(merged[j].Timestamp) {
+			return merged[i].Timestamp.Before(merged[j].Timestamp)
+		}
+		return merged[i].ID < merged[j].ID
+	})
+	return merged, added
+}
+
+// importEntries inserts entries that are not yet stored locally, keeping their
+// original IDs and timestamps. Entries older than the last clear or prune are
+// skipped so that deleted history stays deleted.
+func importEntries(ctx context.Context, entries []QueryHistory) (int, error) {
+	deletedBefore, err := horizon(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin import: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT OR IGNORE INTO query_history
+			(id, timestamp, query, duration, rows, profile, status, error, parent_id)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prepare import: %w", err)
+	}
+	defer stmt.Close()
+
+	added := 0
+	for _, q := range entries {
+		if q.ID == "" || q.Query == "" {
+			continue
+		}
+		status := q.Status
+		if status == "" {
+			status = StatusSuccess
+		}
+		timestamp := q.Timestamp
+		if timestamp.IsZero() {
+			timestamp = time.Now()
+		}
+		if timestamp.Truncate(time.Second).Before(deletedBefore) {
+			continue
+		}
+		res, err := stmt.ExecContext(ctx, q.ID, formatTimestamp(timestamp), q.Query,
+			q.Duration.Milliseconds(), q.Rows, q.Profile, status, q.Error, q.ParentID)
+		if err != nil {
+			return 0, fmt.Errorf("failed to import entry %s: %w", q.ID, err)
+		}
+		if n, _ := res.RowsAffected(); n > 0 {
+			added++
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit import: %w", err)
+	}
+	return added, nil
+}
+
+// NewBackend returns the sync backend for a URL: http(s)://... stores the
+// history as a JSON document at that URL, s3://bucket/key stores it as an S3
+// object. endpoint overrides the S3 endpoint for S3-compatible stores.
+func NewBackend(rawURL, region, endpoint, token string) (Backend, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sync url: %w", err)
+	}
+
+	switch u.Scheme {
+	case "http", "https":
+		return &HTTPBackend{URL: rawURL, Token: token, Client: http.DefaultClient}, nil
+	case "s3":
+		key := strings.TrimPrefix(u.Path, "/")
+		if u.Host == "" || key == "" {
+			return nil, fmt.Errorf("s3 sync url must look like s3://bucket/key")
+		}
+		if region == "" {
+			region = "us-east-1"
+		}
+		return &S3Backend{
+			Bucket:       u.Host,
+			Key:          key,
+			Region:       region,
+			Endpoint:     endpoint,
+			AccessKey:    os.Getenv("AWS_ACCESS_KEY_ID"),
+			SecretKey:    os.Getenv("AWS_SECRET_ACCESS_KEY"),
+			SessionToken: os.Getenv("AWS_SESSION_TOKEN"),
+			Client:       http.DefaultClient,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported sync url scheme %q (use http, https or s3)", u.Scheme)
+	}
+}
+
+// HTTPBackend keeps the history as a JSON document that is fetched with GET
+// and replaced with PUT, using ETags for optimistic concurrency
+type HTTPBackend struct {
+	URL    string
+	Token  string // Sent as a bearer token when set
+	Client *http.Client
+}
+
+// Pull fetches the remote history; a missing document is an empty history
+func (b *HTTPBackend) Pull(ctx context.Context) ([]QueryHistory, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, b.URL, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	b.authorize(req)
+	return pullDocument(b.Client, req)
+}
+
+// Push replaces the remote history if it is still at version
+func (b *HTTPBackend) Push(ctx context.Context, entries []QueryHistory, version string) error {
+	body, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, b.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	setPrecondition(req, version)
+	b.authorize(req)
+	return pushDocument(b.Client, req)
+}
+
+func (b *HTTPBackend) authorize(req *http.Request) {
+	if b.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+b.Token)
+	}
+}
+
+// S3Backend keeps the history as a JSON object in an S3 bucket. Requests are
+// signed with AWS Signature Version 4 using static credentials.
+type S3Backend struct {
+	Bucket       string
+	Key          string
+	Region       string
+	Endpoint     string // Optional, e.g. http://localhost:9000 for MinIO
+	AccessKey    string
+	SecretKey    string
+	SessionToken string
+	Client       *http.Client
+}
+
+// Pull fetches the history object; a missing object is an empty history
+func (b *S3Backend) Pull(ctx context.Context) ([]QueryHistory, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, b.objectURL(), nil)
+	if err != nil {
+		return nil, "", err
+	}
+	if err := b.sign(req, nil, time.Now()); err != nil {
+		return nil, "", err
+	}
+	return pullDocument(b.Client, req)
+}
+
+// Push replaces the history object if it is still at version
+func (b *S3Backend) Push(ctx context.Context, entries []QueryHistory, version string) error {
+	body, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, b.objectURL(), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	setPrecondition(req, version)
+	if err := b.sign(req, body, time.Now()); err != nil {
+		return err
+	}
+	return pushDocument(b.Client, req)
+}
+
+// objectURL uses path-style addressing for custom endpoints and
+// virtual-hosted style for AWS
+func (b *S3Backend) objectURL() string {
+	key := s3EscapePath(b.Key)
+	if b.Endpoint != "" {
+		return strings.TrimRight(b.Endpoint, "/") + "/" + b.Bucket + "/" + key
+	}
+	return fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s", b.Bucket, b.Region, key)
+}
+
+// sign adds AWS Signature Version 4 headers covering every header already set
+func (b *S3Backend) sign(req *http.Request, body []byte, now time.Time) error {
+	if b.AccessKey == "" || b.SecretKey == "" {
+		return fmt.Errorf("AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY must be set for s3 sync")
+	}
+
+	payloadHash := sha256Hex(body)
+	amzDate := now.UTC().Format("20060102T150405Z")
+	day := amzDate[:8]
+
+	req.Header.Set("x-amz-date", amzDate)
+	req.Header.Set("x-amz-content-sha256", payloadHash)
+	if b.SessionToken != "" {
+		req.Header.Set("x-amz-security-token", b.SessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := day + "/" + b.Region + "/s3/aws4_request"
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+b.SecretKey), day)
+	key = hmacSHA256(key, b.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		b.AccessKey, scope, signedHeaders, signature))
+	return nil
+}
+
+// s3EscapePath percent-encodes an object key, keeping slashes
+func s3EscapePath(key string) string {
+	segments := strings.Split(key, "/")
+	for i, s := range segments {
+		segments[i] = strings.ReplaceAll(url.PathEscape(s), "+", "%2B")
+	}
+	return strings.Join(segments, "/")
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// setPrecondition makes a PUT conditional on the version seen by Pull. An
+// empty version means the document did not exist yet.
+func setPrecondition(req *http.Request, version string) {
+	if version == "" {
+		req.Header.Set("If-None-Match", "*")
+	} else {
+		req.Header.Set("If-Match", version)
+	}
+}
+
+// pullDocument performs a GET of a JSON history document
+func pullDocument(client *http.Client, req *http.Request) ([]QueryHistory, string, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, "", nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", unexpectedStatus(resp)
+	}
+
+	var entries []QueryHistory
+	if err := json.NewDecoder(resp.Body).Decode(&entries); err != nil {
+		return nil, "", fmt.Errorf("failed to decode remote history: %w", err)
+	}
+	return entries, resp.Header.Get("ETag"), nil
+}
+
+// pushDocument performs a conditional PUT of a JSON history document
+func pushDocument(client *http.Client, req *http.Request) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusPreconditionFailed || resp.StatusCode == http.StatusConflict:
+		return ErrConflict
+	case resp.StatusCode < 200 || resp.StatusCode > 299:
+		return unexpectedStatus(resp)
+	}
+	return nil
+}
+
+func unexpectedStatus(resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	return fmt.Errorf("%s %s: %s: %s", resp.Request.Method, resp.Request.URL.Redacted(), resp.Status,
+		strings.TrimSpace(string(body)))
+}
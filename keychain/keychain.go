@@ -0,0 +1,44 @@
+// Package keychain keeps secrets in the operating system's credential
+// store: the macOS Keychain, the Secret Service (GNOME Keyring, KWallet)
+// through libsecret's secret-tool, or the Windows Credential Manager.
+package keychain
+
+import (
+	"errors"
+	"fmt"
+)
+
+// service is the name trino-cli's secrets are filed under
+const service = "trino-cli"
+
+// ErrNotFound is returned for a secret the keychain does not hold
+var ErrNotFound = errors.New("secret not found in the keychain")
+
+// Set stores a secret under name, replacing any it had
+func Set(name, secret string) error {
+	if name == "" {
+		return fmt.Errorf("a secret needs a name")
+	}
+	if err := set(name, secret); err != nil {
+		return fmt.Errorf("failed to store %s in the keychain: %w", name, err)
+	}
+	return nil
+}
+
+// Get returns the secret stored under name, or ErrNotFound
+func Get(name string) (string, error) {
+	secret, err := get(name)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return "", fmt.Errorf("failed to read %s from the keychain: %w", name, err)
+	}
+	return secret, err
+}
+
+// Delete removes the secret stored under name, or returns ErrNotFound
+func Delete(name string) error {
+	err := del(name)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return fmt.Errorf("failed to delete %s from the keychain: %w", name, err)
+	}
+	return err
+}
@@ -0,0 +1,59 @@
+//go:build darwin
+
+package keychain
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// errItemNotFound is the exit status of security for a missing item
+const errItemNotFound = 44
+
+// set adds a generic password with security. The command is written to
+// security's standard input, hex-encoded, so the secret never appears in
+// the process list.
+func set(name, secret string) error {
+	cmd := exec.Command("security", "-i")
+	cmd.Stdin = strings.NewReader(fmt.Sprintf("add-generic-password -U -s %s -a %s -X %s\n",
+		quote(service), quote(name), hex.EncodeToString([]byte(secret))))
+	return run(cmd)
+}
+
+func get(name string) (string, error) {
+	out, err := exec.Command("security", "find-generic-password", "-s", service, "-a", name, "-w").Output()
+	if err := notFound(err); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(string(out), "\n"), nil
+}
+
+func del(name string) error {
+	return notFound(run(exec.Command("security", "delete-generic-password", "-s", service, "-a", name)))
+}
+
+// run runs cmd, adding what it wrote to stderr to its error
+func run(cmd *exec.Cmd) error {
+	out, err := cmd.CombinedOutput()
+	if err != nil && len(out) > 0 {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return err
+}
+
+// notFound maps security's exit status for a missing item to ErrNotFound
+func notFound(err error) error {
+	var exit *exec.ExitError
+	if errors.As(err, &exit) && exit.ExitCode() == errItemNotFound {
+		return ErrNotFound
+	}
+	return err
+}
+
+// quote quotes s for security's interactive mode
+func quote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
@@ -0,0 +1,60 @@
+//go:build !darwin && !windows
+
+package keychain
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// set stores the secret with secret-tool, which reads it from standard input
+// so it never appears in the process list
+func set(name, secret string) error {
+	cmd := exec.Command("secret-tool", "store", "--label", service+" "+name, "service", service, "account", name)
+	cmd.Stdin = strings.NewReader(secret)
+	return run(cmd)
+}
+
+// get looks the secret up. secret-tool exits with status 1, printing
+// nothing, when there is none.
+func get(name string) (string, error) {
+	cmd := exec.Command("secret-tool", "lookup", "service", service, "account", name)
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	var exit *exec.ExitError
+	switch {
+	case errors.As(err, &exit) && exit.ExitCode() == 1 && len(out) == 0 && stderr.Len() == 0:
+		return "", ErrNotFound
+	case err != nil:
+		return "", withStderr(err, stderr.String())
+	}
+	return string(out), nil
+}
+
+// del clears the secret. secret-tool clear succeeds whether or not there
+// was one, so it is looked up first.
+func del(name string) error {
+	if _, err := get(name); err != nil {
+		return err
+	}
+	return run(exec.Command("secret-tool", "clear", "service", service, "account", name))
+}
+
+// run runs cmd, adding what it wrote to stderr to its error
+func run(cmd *exec.Cmd) error {
+	out, err := cmd.CombinedOutput()
+	return withStderr(err, string(out))
+}
+
+func withStderr(err error, stderr string) error {
+	if errors.Is(err, exec.ErrNotFound) {
+		return fmt.Errorf("secret-tool is not installed (it comes with libsecret, e.g. the libsecret-tools package): %w", err)
+	}
+	if err != nil && strings.TrimSpace(stderr) != "" {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(stderr))
+	}
+	return err
+}
@@ -0,0 +1,56 @@
+//go:build !darwin && !windows
+
+package keychain
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// fakeSecretTool keeps secrets as files, answering like secret-tool
+const fakeSecretTool = `#!/bin/sh
+command=$1; shift
+[ "$1" = --label ] && shift 2
+file="$STORE/$(echo "$2.$4" | tr / _)"
+case $command in
+store) cat > "$file" ;;
+lookup) [ -f "$file" ] || exit 1; cat "$file" ;;
+clear) rm -f "$file" ;;
+esac
+`
+
+func TestSecretService(t *testing.T) {
+	bin := t.TempDir()
+	if err := os.WriteFile(filepath.Join(bin, "secret-tool"), []byte(fakeSecretTool), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv("STORE", t.TempDir())
+
+	if _, err := Get("prod/password"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Get before Set = %v, want ErrNotFound", err)
+	}
+	for _, secret := range []string{"first", "s3cr3t with spaces\nand lines"} {
+		if err := Set("prod/password", secret); err != nil {
+			t.Fatalf("Set failed: %v", err)
+		}
+		if got, err := Get("prod/password"); err != nil || got != secret {
+			t.Errorf("Get = %q, %v, want %q", got, err, secret)
+		}
+	}
+	if err := Delete("prod/password"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if err := Delete("prod/password"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("second Delete = %v, want ErrNotFound", err)
+	}
+}
+
+func TestSecretToolMissing(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+	if err := Set("prod/password", "x"); err == nil || errors.Is(err, ErrNotFound) {
+		t.Errorf("Set without secret-tool = %v, want an error", err)
+	}
+}
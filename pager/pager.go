@@ -0,0 +1,77 @@
+// Package pager shows long output through a pager, as git and psql do, when
+// it goes to a terminal and wouldn't fit on the screen.
+package pager
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/term"
+)
+
+// Default is the pager used when $PAGER is unset. -S chops long lines rather
+// than wrapping them, which keeps wide tables readable.
+const Default = "less -S"
+
+// Command returns the pager's command line: $PAGER, or Default when it is
+// unset. It is empty when $PAGER is set but empty, which turns paging off.
+func Command() []string {
+	p, ok := os.LookupEnv("PAGER")
+	if !ok {
+		p = Default
+	}
+	return strings.Fields(p)
+}
+
+// Fits reports whether output fits on a screen of width columns and height
+// rows, keeping a row for the shell prompt that follows it
+func Fits(output []byte, width, height int) bool {
+	lines := bytes.Split(bytes.TrimSuffix(output, []byte("\n")), []byte("\n"))
+	if len(lines) >= height {
+		return false
+	}
+	for _, line := range lines {
+		if utf8.RuneCount(line) > width {
+			return false
+		}
+	}
+	return true
+}
+
+// Run writes output with write. When stdout is a terminal the output is
+// gathered first and shown through the pager if it doesn't fit on the
+// screen; otherwise it streams to stdout. Output written before write fails
+// is still shown, and its error returned.
+func Run(write func(w io.Writer) error) error {
+	fd := int(os.Stdout.Fd())
+	args := Command()
+	if len(args) == 0 || !term.IsTerminal(fd) {
+		return write(os.Stdout)
+	}
+
+	var buf bytes.Buffer
+	err := write(&buf)
+	width, height, sizeErr := term.GetSize(fd)
+	if sizeErr != nil || Fits(buf.Bytes(), width, height) || !show(args, buf.Bytes()) {
+		os.Stdout.Write(buf.Bytes())
+	}
+	return err
+}
+
+// show runs the pager on output, reporting false when it can't be started
+func show(args []string, output []byte) bool {
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin = bytes.NewReader(output)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Start(); err != nil {
+		return false
+	}
+	// Quitting before the end is no failure of the output
+	_ = cmd.Wait()
+	return true
+}
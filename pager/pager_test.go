@@ -0,0 +1,44 @@
+package pager
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCommand(t *testing.T) {
+	t.Setenv("PAGER", "more -d")
+	if got := Command(); !reflect.DeepEqual(got, []string{"more", "-d"}) {
+		t.Errorf("Command with $PAGER=more -d = %q", got)
+	}
+	t.Setenv("PAGER", "")
+	if got := Command(); len(got) != 0 {
+		t.Errorf("Command with an empty $PAGER = %q, want paging off", got)
+	}
+	os.Unsetenv("PAGER")
+	if got := Command(); !reflect.DeepEqual(got, []string{"less", "-S"}) {
+		t.Errorf("Command without $PAGER = %q, want less -S", got)
+	}
+}
+
+func TestFits(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   bool
+	}{
+		{"short", "a\nb\n", true},
+		{"empty", "", true},
+		{"leaves a row for the prompt", strings.Repeat("x\n", 23), true},
+		{"too long", strings.Repeat("x\n", 24), false},
+		{"exactly as wide", strings.Repeat("x", 80) + "\n", true},
+		{"too wide", strings.Repeat("x", 81) + "\n", false},
+		{"wide characters counted once", strings.Repeat("é", 80), true},
+	}
+	for _, tt := range tests {
+		if got := Fits([]byte(tt.output), 80, 24); got != tt.want {
+			t.Errorf("%s: Fits = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package repl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/TFMV/trino-cli/config"
+	"github.com/TFMV/trino-cli/engine"
+)
+
+// errQuit ends the shell
+var errQuit = errors.New("quit")
+
+// command is one of the backslash commands, named after psql's
+type command struct {
+	name  string
+	usage string
+	help  string
+	run   func(s *shell, ctx context.Context, args []string) error
+}
+
+var commands []command
+
+func init() {
+	commands = []command{
+		{"?", `\?`, "show this help", (*shell).help},
+		{"q", `\q`, "quit", func(*shell, context.Context, []string) error { return errQuit }},
+		{"c", `\c [PROFILE] [[CATALOG.]SCHEMA]`, "switch profile or schema, or show the current ones", (*shell).connect},
+		{"l", `\l`, "list catalogs", (*shell).listCatalogs},
+		{"dn", `\dn [CATALOG]`, "list schemas", (*shell).listSchemas},
+		{"dt", `\dt [[[CATALOG.]SCHEMA.]PATTERN]`, "list tables, * matching any characters", (*shell).listTables},
+		{"d", `\d [TABLE]`, "describe a table, or list tables", (*shell).describe},
+		{"timing", `\timing [on|off]`, "toggle showing how long queries take", (*shell).toggleTiming},
+	}
+}
+
+// runCommand runs a line starting with a backslash
+func (s *shell) runCommand(ctx context.Context, line string) error {
+	fields := strings.Fields(strings.TrimPrefix(line, `\`))
+	if len(fields) == 0 {
+		return fmt.Errorf(`missing command after \; try \?`)
+	}
+	for _, c := range commands {
+		if c.name == fields[0] {
+			return c.run(s, ctx, fields[1:])
+		}
+	}
+	return fmt.Errorf(`invalid command \%s; try \?`, fields[0])
+}
+
+func (s *shell) help(context.Context, []string) error {
+	width := 0
+	for _, c := range commands {
+		width = max(width, len(c.usage))
+	}
+	for _, c := range commands {
+		fmt.Fprintf(s.out, "  %-*s  %s\n", width, c.usage, c.help)
+	}
+	fmt.Fprintln(s.out, "Statements end with a semicolon and may span lines; Ctrl+C cancels a running query.")
+	return nil
+}
+
+// connect switches to another profile when the first argument names one,
+// then to the catalog and schema of the next with a USE. Without arguments
+// it shows where queries run.
+func (s *shell) connect(ctx context.Context, args []string) error {
+	if len(args) > 2 {
+		return fmt.Errorf(`usage: \c [PROFILE] [[CATALOG.]SCHEMA]`)
+	}
+	if len(args) > 0 {
+		if _, ok := config.AppConfig.Profiles[args[0]]; ok {
+			if s.connectProfile != nil {
+				if err := s.connectProfile(args[0]); err != nil {
+					return err
+				}
+			}
+			s.profile = args[0]
+			args = args[1:]
+		} else if len(args) == 2 {
+			return fmt.Errorf("unknown profile %s", args[0])
+		}
+	}
+	if len(args) > 0 {
+		use := "USE " + args[0]
+		if _, err := s.execute(engine.WithoutHistory(ctx), use, s.profile); err != nil {
+			return err
+		}
+		s.observe(use)
+	}
+
+	p := config.AppConfig.Profiles[s.profile]
+	catalog, schema := engine.CurrentSchema(s.profile)
+	fmt.Fprintf(s.out, "Profile %s, %s@%s:%d, in %s.%s\n", s.profile, p.User, p.Host, p.Port, catalog, schema)
+	return nil
+}
+
+func (s *shell) listCatalogs(ctx context.Context, args []string) error {
+	if len(args) > 0 {
+		return fmt.Errorf(`usage: \l`)
+	}
+	return s.query(engine.WithoutHistory(ctx), "SHOW CATALOGS")
+}
+
+func (s *shell) listSchemas(ctx context.Context, args []string) error {
+	switch len(args) {
+	case 0:
+		return s.query(engine.WithoutHistory(ctx), "SHOW SCHEMAS")
+	case 1:
+		return s.query(engine.WithoutHistory(ctx), "SHOW SCHEMAS FROM "+args[0])
+	}
+	return fmt.Errorf(`usage: \dn [CATALOG]`)
+}
+
+func (s *shell) listTables(ctx context.Context, args []string) error {
+	if len(args) > 1 {
+		return fmt.Errorf(`usage: \dt [[[CATALOG.]SCHEMA.]PATTERN]`)
+	}
+	pattern := ""
+	if len(args) == 1 {
+		pattern = args[0]
+	}
+	return s.query(engine.WithoutHistory(ctx), showTablesSQL(pattern))
+}
+
+func (s *shell) describe(ctx context.Context, args []string) error {
+	switch len(args) {
+	case 0:
+		return s.listTables(ctx, nil)
+	case 1:
+		return s.query(engine.WithoutHistory(ctx), "DESCRIBE "+args[0])
+	}
+	return fmt.Errorf(`usage: \d [TABLE]`)
+}
+
+func (s *shell) toggleTiming(_ context.Context, args []string) error {
+	switch {
+	case len(args) == 0:
+		s.timing = !s.timing
+	case len(args) == 1 && strings.EqualFold(args[0], "on"):
+		s.timing = true
+	case len(args) == 1 && strings.EqualFold(args[0], "off"):
+		s.timing = false
+	default:
+		return fmt.Errorf(`usage: \timing [on|off]`)
+	}
+	if s.timing {
+		fmt.Fprintln(s.out, "Timing is on.")
+	} else {
+		fmt.Fprintln(s.out, "Timing is off.")
+	}
+	return nil
+}
+
+// showTablesSQL lists the tables matching a psql-style pattern: the tables
+// of a schema or of the current one, whose names match the part after the
+// last dot with * standing for any characters
+func showTablesSQL(pattern string) string {
+	query := "SHOW TABLES"
+	if i := strings.LastIndexByte(pattern, '.'); i >= 0 {
+		query += " FROM " + pattern[:i]
+		pattern = pattern[i+1:]
+	}
+	if pattern == "" || pattern == "*" {
+		return query
+	}
+	like := strings.NewReplacer("'", "''", "*", "%").Replace(pattern)
+	return query + " LIKE '" + like + "'"
+}
@@ -0,0 +1,105 @@
+package repl
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestShowTablesSQL(t *testing.T) {
+	tests := map[string]string{
+		"":              "SHOW TABLES",
+		"*":             "SHOW TABLES",
+		"orders":        "SHOW TABLES LIKE 'orders'",
+		"ord*":          "SHOW TABLES LIKE 'ord%'",
+		"sales.*":       "SHOW TABLES FROM sales",
+		"hive.sales.o*": "SHOW TABLES FROM hive.sales LIKE 'o%'",
+		"it's":          "SHOW TABLES LIKE 'it''s'",
+	}
+	for pattern, want := range tests {
+		if got := showTablesSQL(pattern); got != want {
+			t.Errorf("showTablesSQL(%q) = %q, want %q", pattern, got, want)
+		}
+	}
+}
+
+func TestCommands(t *testing.T) {
+	tests := []struct {
+		line string
+		want []string
+	}{
+		{`\l`, []string{"SHOW CATALOGS"}},
+		{`\dn`, []string{"SHOW SCHEMAS"}},
+		{`\dn hive`, []string{"SHOW SCHEMAS FROM hive"}},
+		{`\dt`, []string{"SHOW TABLES"}},
+		{`\dt ord*`, []string{"SHOW TABLES LIKE 'ord%'"}},
+		{`\d`, []string{"SHOW TABLES"}},
+		{`\d orders`, []string{"DESCRIBE orders"}},
+		{`\c sales`, []string{"USE sales"}},
+		{`\c`, nil},
+	}
+	for _, tt := range tests {
+		s, queries, _, _ := newTestShell(t, &emptyResult)
+		if err := s.runCommand(context.Background(), tt.line); err != nil {
+			t.Errorf("%s: %v", tt.line, err)
+			continue
+		}
+		if !reflect.DeepEqual(*queries, tt.want) {
+			t.Errorf("%s ran %q, want %q", tt.line, *queries, tt.want)
+		}
+	}
+
+	s, _, _, _ := newTestShell(t, &emptyResult)
+	for _, line := range []string{`\`, `\x`, `\d a b`, `\timing maybe`, `\c nope hive.sales`} {
+		if err := s.runCommand(context.Background(), line); err == nil {
+			t.Errorf("%s: expected an error", line)
+		}
+	}
+	if err := s.runCommand(context.Background(), `\q`); err != errQuit {
+		t.Errorf(`\q returned %v, want errQuit`, err)
+	}
+}
+
+func TestConnect(t *testing.T) {
+	s, queries, out, _ := newTestShell(t, &emptyResult)
+	var connected []string
+	s.connectProfile = func(profile string) error {
+		connected = append(connected, profile)
+		return nil
+	}
+
+	if err := s.runCommand(context.Background(), `\c prod analytics.web`); err != nil {
+		t.Fatal(err)
+	}
+	if s.profile != "prod" || !reflect.DeepEqual(connected, []string{"prod"}) {
+		t.Errorf("profile = %s after connecting %q, want prod", s.profile, connected)
+	}
+	if !reflect.DeepEqual(*queries, []string{"USE analytics.web"}) {
+		t.Errorf("ran %q, want the USE", *queries)
+	}
+	if want := "Profile prod, ana@trino.example.com:443, in analytics.web\n"; out.String() != want {
+		t.Errorf("printed %q, want %q", out.String(), want)
+	}
+	if got := s.prompt(false); got != "prod:analytics.web> " {
+		t.Errorf("prompt = %q after switching", got)
+	}
+}
+
+func TestTiming(t *testing.T) {
+	s, _, out, _ := newTestShell(t, &emptyResult)
+	for _, line := range []string{`\timing`, `\timing off`, `\timing on`} {
+		if err := s.runCommand(context.Background(), line); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !s.timing {
+		t.Error(`expected \timing on to turn timing on`)
+	}
+	if err := s.query(context.Background(), "SELECT 1"); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "Time: ") {
+		t.Errorf("expected the query's time, got %q", out.String())
+	}
+}
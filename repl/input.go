@@ -0,0 +1,242 @@
+package repl
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// errInterrupted is returned by ReadLine when Ctrl+C discards the line
+var errInterrupted = errors.New("interrupted")
+
+// lineReader reads the shell's input a line at a time
+type lineReader interface {
+	// ReadLine shows prompt and returns the next line, without its end.
+	// It returns io.EOF at the end of the input.
+	ReadLine(prompt string) (string, error)
+}
+
+// input reads a file on a goroutine, so that a read gives up as soon as ctx
+// is done rather than when the user next presses a key. Nothing is read but
+// what is asked for, leaving the terminal to the pager in between.
+type input struct {
+	ctx     context.Context
+	r       io.Reader
+	results chan inputRead
+	reading bool // A read is in flight
+}
+
+// inputRead is what a read returned
+type inputRead struct {
+	data []byte
+	err  error
+}
+
+func newInput(ctx context.Context, r io.Reader) *input {
+	return &input{ctx: ctx, r: r, results: make(chan inputRead, 1)}
+}
+
+func (in *input) Read(p []byte) (int, error) {
+	if !in.reading {
+		in.reading = true
+		go func(buf []byte) {
+			n, err := in.r.Read(buf)
+			in.results <- inputRead{buf[:n], err}
+		}(make([]byte, len(p)))
+	}
+	select {
+	case <-in.ctx.Done():
+		return 0, in.ctx.Err()
+	case read := <-in.results:
+		in.reading = false
+		return copy(p, read.data), read.err
+	}
+}
+
+// plainReader reads lines without editing, for dumb terminals and input
+// that isn't a terminal at all. Prompts are only shown to a terminal.
+type plainReader struct {
+	scanner *bufio.Scanner
+	out     io.Writer
+	prompts bool
+}
+
+func newPlainReader(r io.Reader, out io.Writer, prompts bool) *plainReader {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	return &plainReader{scanner: scanner, out: out, prompts: prompts}
+}
+
+func (r *plainReader) ReadLine(prompt string) (string, error) {
+	if r.prompts {
+		fmt.Fprint(r.out, prompt)
+	}
+	if !r.scanner.Scan() {
+		if err := r.scanner.Err(); err != nil {
+			return "", err
+		}
+		return "", io.EOF
+	}
+	return strings.TrimSuffix(r.scanner.Text(), "\r"), nil
+}
+
+// editor reads lines on a terminal with the editing keys of x/term's
+// Terminal: arrows, Home and End, Ctrl+A/E/K/U/W, and Up and Down through
+// the lines entered in this and earlier runs. The terminal is only in raw
+// mode while a line is read, so that Ctrl+C interrupts running queries.
+type editor struct {
+	fd      int
+	in      *input
+	out     io.Writer
+	term    *term.Terminal
+	history *historyFile
+
+	interrupts int           // Ctrl+C presses read but not yet seen by ReadLine
+	replay     *bytes.Reader // Lines being fed to a new Terminal's history
+}
+
+func newEditor(fd int, in *input, out io.Writer, history *historyFile) *editor {
+	e := &editor{fd: fd, in: in, out: out, history: history, replay: bytes.NewReader(nil)}
+	e.reset()
+	return e
+}
+
+// reset starts a new Terminal with the lines of the history. Terminal has
+// no way to add to its history other than entering the lines, nor to drop
+// the line being edited when Ctrl+C ends ReadLine, hence the replay.
+func (e *editor) reset() {
+	e.term = term.NewTerminal(e, "")
+	for _, line := range e.history.lines {
+		e.replay.Reset([]byte(line + "\r"))
+		e.term.ReadLine()
+	}
+	e.replay.Reset(nil)
+}
+
+// Read feeds the Terminal the replayed history, then the keys typed
+func (e *editor) Read(p []byte) (int, error) {
+	if e.replay.Len() > 0 {
+		return e.replay.Read(p)
+	}
+	n, err := e.in.Read(p)
+	e.interrupts += bytes.Count(p[:n], []byte{3})
+	return n, err
+}
+
+// Write echoes the Terminal's output, other than that of the replay
+func (e *editor) Write(p []byte) (int, error) {
+	if e.replay.Size() > 0 {
+		return len(p), nil
+	}
+	return e.out.Write(p)
+}
+
+func (e *editor) ReadLine(prompt string) (string, error) {
+	state, err := term.MakeRaw(e.fd)
+	if err != nil {
+		return "", err
+	}
+	defer term.Restore(e.fd, state)
+	if width, height, err := term.GetSize(e.fd); err == nil && width > 0 {
+		e.term.SetSize(width, height)
+	}
+
+	e.term.SetPrompt(prompt)
+	line, err := e.term.ReadLine()
+	switch {
+	case err == io.EOF && e.interrupts > 0:
+		// Ctrl+C, which Terminal reports like Ctrl+D
+		e.interrupts = 0
+		io.WriteString(e.out, "^C\r\n")
+		e.reset()
+		return "", errInterrupted
+	case errors.Is(err, term.ErrPasteIndicator):
+	case err == io.EOF:
+		io.WriteString(e.out, "\r\n")
+		return "", err
+	case err != nil:
+		return "", err
+	}
+	e.history.add(line)
+	return line, nil
+}
+
+// historySize is how many lines Up and Down go back through, which is as
+// many as Terminal keeps
+const historySize = 100
+
+// historyFileLimit is how many lines the history file grows to before it
+// is cut back to the last historySize
+const historyFileLimit = 1000
+
+// historyFile is the lines entered in the shell, kept across runs in a
+// file; a history without a path is only kept for the run
+type historyFile struct {
+	path  string
+	lines []string // The last historySize, oldest first
+}
+
+// historyPath is where the history is kept, ~/.trino-cli/repl_history
+func historyPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".trino-cli", "repl_history"), nil
+}
+
+// loadHistory reads the history file at path, cutting it back when it has
+// grown past historyFileLimit. A missing file is an empty history.
+func loadHistory(path string) (*historyFile, error) {
+	h := &historyFile{path: path}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return h, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	if len(data) == 0 {
+		lines = nil
+	}
+	h.lines = lines[max(0, len(lines)-historySize):]
+	if len(lines) > historyFileLimit {
+		if err := os.WriteFile(path, []byte(strings.Join(h.lines, "\n")+"\n"), 0600); err != nil {
+			return nil, err
+		}
+	}
+	return h, nil
+}
+
+// add records a line that was entered. Blank lines, and lines repeating
+// the one before, are left out.
+func (h *historyFile) add(line string) {
+	if strings.TrimSpace(line) == "" || (len(h.lines) > 0 && h.lines[len(h.lines)-1] == line) {
+		return
+	}
+	h.lines = append(h.lines, line)
+	if len(h.lines) > historySize {
+		h.lines = h.lines[len(h.lines)-historySize:]
+	}
+	if h.path == "" {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(h.path), 0700); err != nil {
+		return
+	}
+	f, err := os.OpenFile(h.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	fmt.Fprintln(f, line)
+}
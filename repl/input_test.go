@@ -0,0 +1,80 @@
+package repl
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestHistoryFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "trino-cli", "repl_history")
+	h, err := loadHistory(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(h.lines) != 0 {
+		t.Fatalf("expected an empty history, got %q", h.lines)
+	}
+
+	for _, line := range []string{"SELECT 1;", "", "  ", "SELECT 1;", `\dt`} {
+		h.add(line)
+	}
+	reloaded, err := loadHistory(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"SELECT 1;", `\dt`}
+	if !reflect.DeepEqual(h.lines, want) || !reflect.DeepEqual(reloaded.lines, want) {
+		t.Errorf("history = %q, reloaded %q, want %q", h.lines, reloaded.lines, want)
+	}
+}
+
+func TestHistoryFileLimit(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "repl_history")
+	var data strings.Builder
+	for i := range historyFileLimit + 1 {
+		fmt.Fprintf(&data, "SELECT %d;\n", i)
+	}
+	if err := os.WriteFile(path, []byte(data.String()), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	h, err := loadHistory(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(h.lines) != historySize || h.lines[historySize-1] != fmt.Sprintf("SELECT %d;", historyFileLimit) {
+		t.Fatalf("loaded %d lines ending %q, want the last %d", len(h.lines), h.lines[len(h.lines)-1], historySize)
+	}
+	saved, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Count(string(saved), "\n"); got != historySize {
+		t.Errorf("history file cut back to %d lines, want %d", got, historySize)
+	}
+}
+
+func TestInputCancel(t *testing.T) {
+	r, w := io.Pipe()
+	defer w.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	in := newInput(ctx, r)
+
+	go w.Write([]byte("SELECT 1;\n"))
+	buf := make([]byte, 64)
+	n, err := in.Read(buf)
+	if err != nil || string(buf[:n]) != "SELECT 1;\n" {
+		t.Fatalf("Read = %q, %v", buf[:n], err)
+	}
+
+	cancel()
+	if _, err := in.Read(buf); err != context.Canceled {
+		t.Errorf("Read after cancel = %v, want context.Canceled", err)
+	}
+}
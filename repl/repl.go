@@ -0,0 +1,259 @@
+// Package repl is a line-based SQL shell, for dumb terminals and slow
+// connections where the full-screen shell can't redraw well. Statements are
+// read up to their semicolon, with line editing and a history kept across
+// runs, and the backslash commands of psql inspect the catalog.
+package repl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/TFMV/trino-cli/autocomplete"
+	"github.com/TFMV/trino-cli/engine"
+	"github.com/TFMV/trino-cli/pager"
+	"github.com/olekukonko/tablewriter"
+	"golang.org/x/term"
+)
+
+// Options change how Run behaves
+type Options struct {
+	// NoPager prints results that don't fit on the screen directly instead
+	// of through $PAGER
+	NoPager bool
+
+	// Connect is called before \c switches to a profile, e.g. to ask for
+	// its password
+	Connect func(profile string) error
+}
+
+// shell is the state of a session
+type shell struct {
+	profile string
+	timing  bool
+	out     io.Writer
+	errOut  io.Writer
+
+	execute        func(ctx context.Context, query, profile string) (*engine.QueryResult, error)
+	page           func(write func(w io.Writer) error) error
+	connectProfile func(profile string) error
+	interrupts     <-chan os.Signal // Cancel the running query
+}
+
+// Run reads and runs statements and backslash commands on standard input
+// until \q, quit, exit or the end of the input. Lines are edited on a
+// terminal unless $TERM is dumb; other input is read as it comes, and
+// prompts are only shown to a terminal.
+func Run(ctx context.Context, profile string, opts Options) error {
+	// Ctrl+C cancels the running query rather than the shell; SIGTERM still
+	// ends it
+	ctx, stop := signal.NotifyContext(context.WithoutCancel(ctx), syscall.SIGTERM)
+	defer stop()
+	interrupts := make(chan os.Signal, 1)
+	signal.Notify(interrupts, os.Interrupt)
+	defer signal.Stop(interrupts)
+
+	s := &shell{
+		profile:        profile,
+		out:            os.Stdout,
+		errOut:         os.Stderr,
+		execute:        engine.ExecuteQuery,
+		page:           pager.Run,
+		connectProfile: opts.Connect,
+		interrupts:     interrupts,
+	}
+	if opts.NoPager {
+		s.page = func(write func(w io.Writer) error) error { return write(os.Stdout) }
+	}
+
+	in := newInput(ctx, os.Stdin)
+	fd := int(os.Stdin.Fd())
+	var lines lineReader
+	if term.IsTerminal(fd) && term.IsTerminal(int(os.Stdout.Fd())) && os.Getenv("TERM") != "dumb" {
+		path, err := historyPath()
+		if err != nil {
+			return err
+		}
+		history, err := loadHistory(path)
+		if err != nil {
+			return err
+		}
+		lines = newEditor(fd, in, os.Stdout, history)
+	} else {
+		lines = newPlainReader(in, os.Stdout, term.IsTerminal(fd))
+	}
+	if term.IsTerminal(fd) {
+		fmt.Fprintln(s.out, `Type \? for help, \q to quit.`)
+	}
+	return s.loop(ctx, lines)
+}
+
+// loop runs what is read from lines until the shell ends
+func (s *shell) loop(ctx context.Context, lines lineReader) error {
+	var pending strings.Builder // Lines of a statement that isn't terminated yet
+	for {
+		line, err := lines.ReadLine(s.prompt(pending.Len() > 0))
+		switch {
+		case errors.Is(err, errInterrupted):
+			pending.Reset()
+			continue
+		case err == io.EOF:
+			if pending.Len() > 0 {
+				// A last statement without a semicolon still runs
+				s.runStatements(ctx, pending.String())
+			}
+			return nil
+		case err != nil:
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+
+		trimmed := strings.TrimSpace(line)
+		if pending.Len() == 0 {
+			if strings.HasPrefix(trimmed, `\`) {
+				err := s.runCommand(ctx, trimmed)
+				if errors.Is(err, errQuit) {
+					return nil
+				}
+				if err != nil {
+					fmt.Fprintf(s.errOut, "Error: %v\n", err)
+				}
+				continue
+			}
+			switch strings.ToLower(strings.TrimSuffix(trimmed, ";")) {
+			case "quit", "exit":
+				return nil
+			case "":
+				continue
+			}
+		}
+
+		pending.WriteString(line)
+		pending.WriteByte('\n')
+		if engine.Terminated(pending.String()) {
+			s.runStatements(ctx, pending.String())
+			pending.Reset()
+		}
+	}
+}
+
+// prompt is the profile and the catalog and schema queries run in, as
+// psql shows the database; a statement's later lines get -> instead
+func (s *shell) prompt(continued bool) string {
+	prompt := s.profile
+	if catalog, schema := engine.CurrentSchema(s.profile); catalog != "" {
+		prompt += ":" + catalog
+		if schema != "" {
+			prompt += "." + schema
+		}
+	}
+	if continued {
+		return prompt + "-> "
+	}
+	return prompt + "> "
+}
+
+// runStatements runs the statements of a script in order, stopping at the
+// first that fails
+func (s *shell) runStatements(ctx context.Context, script string) {
+	for _, statement := range engine.SplitStatements(script) {
+		if err := s.query(ctx, statement.SQL); err != nil {
+			fmt.Fprintf(s.errOut, "Error: %v\n", err)
+			return
+		}
+	}
+}
+
+// query runs a statement and prints its result. Ctrl+C cancels it.
+func (s *shell) query(ctx context.Context, query string) error {
+	// Drop a Ctrl+C pressed while no query ran
+	select {
+	case <-s.interrupts:
+	default:
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-s.interrupts:
+			// After the terminal's ^C
+			fmt.Fprintln(s.errOut)
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	started := time.Now()
+	result, err := s.execute(ctx, query, s.profile)
+	elapsed := time.Since(started)
+	if err != nil {
+		if ctx.Err() == context.Canceled {
+			return errors.New("query cancelled")
+		}
+		return err
+	}
+	s.observe(query)
+
+	if result.Truncated {
+		fmt.Fprintf(s.errOut, "Showing the first %d rows (defaults.max_rows)\n", len(result.Rows))
+	}
+	if len(result.Columns) > 0 {
+		if err := s.page(func(w io.Writer) error { return writeTable(w, result) }); err != nil {
+			return err
+		}
+	}
+	if s.timing {
+		fmt.Fprintf(s.out, "Time: %s\n", elapsed.Round(time.Millisecond))
+	}
+	return nil
+}
+
+// observe makes later queries run in the catalog and schema a USE switched
+// to, as the interactive shell does
+func (s *shell) observe(query string) {
+	if catalog, schema, ok := autocomplete.ParseUseStatement(query); ok {
+		engine.UseSchema(s.profile, catalog, schema)
+	}
+}
+
+// writeTable writes a result as psql does: aligned columns under a rule,
+// then the row count
+func writeTable(w io.Writer, result *engine.QueryResult) error {
+	table := tablewriter.NewWriter(w)
+	table.SetHeader(result.Columns)
+	table.SetAutoFormatHeaders(false)
+	table.SetAutoWrapText(false)
+	table.SetBorder(false)
+	table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetCenterSeparator("+")
+	table.SetColumnSeparator("|")
+	table.SetRowSeparator("-")
+	for _, row := range result.Rows {
+		cells := make([]string, len(row))
+		for i, v := range row {
+			if v == nil {
+				cells[i] = "NULL"
+			} else {
+				cells[i] = fmt.Sprintf("%v", v)
+			}
+		}
+		table.Append(cells)
+	}
+	table.Render()
+
+	rows := "rows"
+	if len(result.Rows) == 1 {
+		rows = "row"
+	}
+	_, err := fmt.Fprintf(w, "(%d %s)\n\n", len(result.Rows), rows)
+	return err
+}
@@ -0,0 +1,130 @@
+package repl
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/TFMV/trino-cli/config"
+	"github.com/TFMV/trino-cli/engine"
+)
+
+// newTestShell returns a shell on the dev profile that records the queries
+// it runs instead of sending them, answering each with result
+func newTestShell(t *testing.T, result *engine.QueryResult) (s *shell, queries *[]string, out, errOut *bytes.Buffer) {
+	t.Helper()
+	saved := config.AppConfig
+	t.Cleanup(func() { config.AppConfig = saved })
+	config.AppConfig = config.Config{Profiles: map[string]config.Profile{
+		"dev":  {Host: "localhost", Port: 8080, User: "ana", Catalog: "hive", Schema: "default"},
+		"prod": {Host: "trino.example.com", Port: 443, User: "ana", Catalog: "iceberg", Schema: "marts"},
+	}}
+	t.Cleanup(engine.CloseConnections)
+	t.Cleanup(func() {
+		engine.Reconnect("dev")
+		engine.Reconnect("prod")
+	})
+
+	queries = new([]string)
+	out, errOut = new(bytes.Buffer), new(bytes.Buffer)
+	s = &shell{
+		profile: "dev",
+		out:     out,
+		errOut:  errOut,
+		execute: func(ctx context.Context, query, profile string) (*engine.QueryResult, error) {
+			*queries = append(*queries, query)
+			if strings.Contains(query, "missing") {
+				return nil, errors.New("table not found")
+			}
+			return result, nil
+		},
+		page: func(write func(w io.Writer) error) error { return write(out) },
+	}
+	return s, queries, out, errOut
+}
+
+func TestLoop(t *testing.T) {
+	s, queries, out, errOut := newTestShell(t, &engine.QueryResult{Columns: []string{"n"}, Rows: [][]interface{}{{1}}})
+	input := strings.Join([]string{
+		"SELECT 1;",
+		"SELECT *",
+		"  FROM orders",
+		"  WHERE note = 'a;",
+		"b';",
+		"",
+		"SELECT 2; SELECT * FROM missing; SELECT 3;",
+		"SELECT 4",
+	}, "\n")
+	if err := s.loop(context.Background(), newPlainReader(strings.NewReader(input), out, false)); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"SELECT 1",
+		"SELECT *\n  FROM orders\n  WHERE note = 'a;\nb'",
+		"SELECT 2",
+		"SELECT * FROM missing",
+		"SELECT 4",
+	}
+	if !reflect.DeepEqual(*queries, want) {
+		t.Errorf("ran %q, want %q", *queries, want)
+	}
+	if !strings.Contains(errOut.String(), "Error: table not found") {
+		t.Errorf("stderr = %q, want the failure", errOut.String())
+	}
+	if got := strings.Count(out.String(), "(1 row)"); got != 4 {
+		t.Errorf("printed %d results, want 4:\n%s", got, out.String())
+	}
+}
+
+func TestLoopQuit(t *testing.T) {
+	for _, quit := range []string{`\q`, "quit", "exit;"} {
+		s, queries, out, _ := newTestShell(t, &engine.QueryResult{})
+		input := "SELECT 1;\n" + quit + "\nSELECT 2;\n"
+		if err := s.loop(context.Background(), newPlainReader(strings.NewReader(input), out, false)); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(*queries, []string{"SELECT 1"}) {
+			t.Errorf("%s: ran %q, want only SELECT 1", quit, *queries)
+		}
+	}
+}
+
+func TestPrompt(t *testing.T) {
+	s, _, out, _ := newTestShell(t, &engine.QueryResult{})
+	if err := s.loop(context.Background(), newPlainReader(strings.NewReader("SELECT\n1;\nUSE sales;\n"), out, true)); err != nil {
+		t.Fatal(err)
+	}
+	want := "dev:hive.default> dev:hive.default-> dev:hive.default> dev:hive.sales> "
+	if out.String() != want {
+		t.Errorf("prompts = %q, want %q", out.String(), want)
+	}
+}
+
+func TestWriteTable(t *testing.T) {
+	var out bytes.Buffer
+	result := &engine.QueryResult{
+		Columns: []string{"name", "rows"},
+		Rows:    [][]interface{}{{"orders", 1200}, {"customers", nil}},
+	}
+	if err := writeTable(&out, result); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(out.String(), "\n")
+	if len(lines) < 5 || !strings.Contains(lines[0], "name") || !strings.Contains(lines[1], "-+-") {
+		t.Fatalf("expected a header over a rule, got:\n%s", out.String())
+	}
+	if !strings.Contains(lines[3], "customers") || !strings.Contains(lines[3], "NULL") {
+		t.Errorf("expected NULL for the missing value, got %q", lines[3])
+	}
+	if lines[4] != "(2 rows)" {
+		t.Errorf("footer = %q, want (2 rows)", lines[4])
+	}
+}
+
+// emptyResult is the result of a statement such as USE, which has no columns
+var emptyResult = engine.QueryResult{}
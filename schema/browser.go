@@ -3,12 +3,14 @@ package schema
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"sort"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/TFMV/trino-cli/autocomplete"
 	"github.com/TFMV/trino-cli/config"
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
@@ -19,11 +21,16 @@ import (
 
 // SchemaTree represents the structure of the Trino schema
 type SchemaTree struct {
-	Catalogs map[string]bool
-	Schemas  map[string]map[string]bool
-	Tables   map[string]map[string]map[string]bool
-	Columns  map[string]map[string]map[string][]Column
-	mu       sync.RWMutex
+	Catalogs   map[string]bool
+	Schemas    map[string]map[string]bool
+	Tables     map[string]map[string]map[string]bool
+	Columns    map[string]map[string]map[string][]Column
+	Stats      map[string]map[string]map[string]*TableStats
+	Partitions map[string]map[string]map[string][]Partition
+	Views      map[string]map[string]map[string]View
+	Comments   map[string]map[string]map[string]string // Table comments
+	Properties map[string]map[string]map[string][]TableProperty
+	mu         sync.RWMutex
 }
 
 // Column represents a column in a table
@@ -31,15 +38,21 @@ type Column struct {
 	Name     string
 	Type     string
 	Nullable bool
+	Comment  string
 }
 
 // NewSchemaTree creates a new schema tree
 func NewSchemaTree() *SchemaTree {
 	return &SchemaTree{
-		Catalogs: make(map[string]bool),
-		Schemas:  make(map[string]map[string]bool),
-		Tables:   make(map[string]map[string]map[string]bool),
-		Columns:  make(map[string]map[string]map[string][]Column),
+		Catalogs:   make(map[string]bool),
+		Schemas:    make(map[string]map[string]bool),
+		Tables:     make(map[string]map[string]map[string]bool),
+		Columns:    make(map[string]map[string]map[string][]Column),
+		Stats:      make(map[string]map[string]map[string]*TableStats),
+		Partitions: make(map[string]map[string]map[string][]Partition),
+		Views:      make(map[string]map[string]map[string]View),
+		Comments:   make(map[string]map[string]map[string]string),
+		Properties: make(map[string]map[string]map[string][]TableProperty),
 	}
 }
 
@@ -58,10 +71,24 @@ func NewSchemaCache() *SchemaCache {
 	}
 }
 
+// rlock read-locks the cache and the tree it holds, which loads write to
+// under the tree's lock, and returns the function that unlocks both
+func (sc *SchemaCache) rlock() func() {
+	sc.mu.RLock()
+	data := sc.Data
+	if data == nil {
+		return sc.mu.RUnlock
+	}
+	data.mu.RLock()
+	return func() {
+		data.mu.RUnlock()
+		sc.mu.RUnlock()
+	}
+}
+
 // Get returns the cached schema tree if it's still valid, otherwise nil
 func (sc *SchemaCache) Get() *SchemaTree {
-	sc.mu.RLock()
-	defer sc.mu.RUnlock()
+	defer sc.rlock()()
 	if time.Now().Before(sc.Expiry) {
 		return sc.Data
 	}
@@ -78,8 +105,7 @@ func (sc *SchemaCache) Update(tree *SchemaTree, duration time.Duration) {
 
 // HasCatalog checks if a catalog exists in the cache
 func (sc *SchemaCache) HasCatalog(catalog string) bool {
-	sc.mu.RLock()
-	defer sc.mu.RUnlock()
+	defer sc.rlock()()
 	if sc.Data == nil {
 		return false
 	}
@@ -89,8 +115,7 @@ func (sc *SchemaCache) HasCatalog(catalog string) bool {
 
 // HasSchema checks if a schema exists in the cache
 func (sc *SchemaCache) HasSchema(catalog, schema string) bool {
-	sc.mu.RLock()
-	defer sc.mu.RUnlock()
+	defer sc.rlock()()
 	if sc.Data == nil {
 		return false
 	}
@@ -103,8 +128,7 @@ func (sc *SchemaCache) HasSchema(catalog, schema string) bool {
 
 // HasTable checks if a table exists in the cache
 func (sc *SchemaCache) HasTable(catalog, schema, table string) bool {
-	sc.mu.RLock()
-	defer sc.mu.RUnlock()
+	defer sc.rlock()()
 	if sc.Data == nil {
 		return false
 	}
@@ -119,8 +143,7 @@ func (sc *SchemaCache) HasTable(catalog, schema, table string) bool {
 
 // GetCatalogs returns all catalogs from the cache
 func (sc *SchemaCache) GetCatalogs() []string {
-	sc.mu.RLock()
-	defer sc.mu.RUnlock()
+	defer sc.rlock()()
 	if sc.Data == nil || time.Now().After(sc.Expiry) {
 		return nil
 	}
@@ -135,8 +158,7 @@ func (sc *SchemaCache) GetCatalogs() []string {
 
 // GetSchemas returns all schemas for a catalog from the cache
 func (sc *SchemaCache) GetSchemas(catalog string) []string {
-	sc.mu.RLock()
-	defer sc.mu.RUnlock()
+	defer sc.rlock()()
 	if sc.Data == nil || time.Now().After(sc.Expiry) {
 		return nil
 	}
@@ -154,8 +176,7 @@ func (sc *SchemaCache) GetSchemas(catalog string) []string {
 
 // GetTables returns all tables for a schema from the cache
 func (sc *SchemaCache) GetTables(catalog, schema string) []string {
-	sc.mu.RLock()
-	defer sc.mu.RUnlock()
+	defer sc.rlock()()
 	if sc.Data == nil || time.Now().After(sc.Expiry) {
 		return nil
 	}
@@ -175,8 +196,7 @@ func (sc *SchemaCache) GetTables(catalog, schema string) []string {
 
 // GetColumns returns all columns for a table from the cache
 func (sc *SchemaCache) GetColumns(catalog, schema, table string) []Column {
-	sc.mu.RLock()
-	defer sc.mu.RUnlock()
+	defer sc.rlock()()
 	if sc.Data == nil || time.Now().After(sc.Expiry) {
 		return nil
 	}
@@ -191,28 +211,93 @@ func (sc *SchemaCache) GetColumns(catalog, schema, table string) []Column {
 
 // SchemaTreeNode represents a node in the tview tree
 type SchemaTreeNode struct {
-	Type     string // "catalog", "schema", "table", "column"
+	Type     string // "catalog", "schema", "table", "column", "partitions", "partition", "more_partitions", "favorites"
 	Name     string
 	Catalog  string
 	Schema   string
 	Table    string
 	DataType string // for columns
+	Offset   int    // for partitions, the index in the table's partition list
+	View     *View  // for tables that are views
+	Comment  string // for tables and columns
 	Loaded   bool
 }
 
 // Browser manages the interactive schema browser
 type Browser struct {
-	tree       *SchemaTree
-	cache      *SchemaCache
-	treeView   *tview.TreeView
-	app        *tview.Application
-	infoText   *tview.TextView
-	db         *sql.DB
-	logger     *zap.Logger
-	profile    string
-	rootNode   *tview.TreeNode
-	loadingJob context.CancelFunc
-	dbPool     *sql.DB // Connection pool for better performance
+	tree          *SchemaTree
+	cache         *SchemaCache
+	treeView      *tview.TreeView
+	app           *tview.Application
+	infoText      *tview.TextView
+	db            *sql.DB
+	logger        *zap.Logger
+	profile       string
+	rootNode      *tview.TreeNode
+	ctx           context.Context // Lives as long as the running browser
+	dbPool        *sql.DB         // Connection pool for better performance
+	onInsert      func(string)    // Set when embedded; receives selected names
+	palette       Palette
+	highlight     func(string) string          // Colors DDL; see SetHighlighter
+	copy          func(string) (string, error) // Copies DDL; see SetClipboard
+	ddl           string                       // DDL shown in the info pane
+	ddlNode       *tview.TreeNode              // Table whose DDL is shown
+	preview       *tview.Table                 // Replaces the info pane while shown
+	previewed     bool
+	autoStats     bool                      // Load table statistics on selection; see SetAutoStats
+	ttl           time.Duration             // How long metadata is cached; see SetCacheTTL
+	store         *autocomplete.SchemaCache // Metadata shared with autocomplete; see store.go
+	searching     bool                      // Global search replaces the info pane
+	searchPane    *tview.Flex
+	searchInput   *tview.InputField
+	searchList    *tview.List
+	searchResults []searchResult
+	hidden        []string // Patterns of hidden catalogs and schemas; see SetHiddenPatterns
+	showHidden    bool
+	filter        string // Lower-case pattern the tree is filtered by
+	filtering     bool   // The filter input replaces the info pane
+	filterInput   *tview.InputField
+	unfiltered    map[*tview.TreeNode][]*tview.TreeNode // Loaded children, before hiding and filtering
+	favorites     *tview.TreeNode                       // Bookmarked tables; see favorites.go
+	loads         loads                                 // Loads of the nodes' children; see loads.go
+	openQuery     func(string)                          // Opens query templates; see SetQueryOpener
+	templates     []QueryTemplate
+	templatePane  *tview.Flex // Replaces the info pane while listing templates
+	templateList  *tview.List
+	templateText  *tview.TextView
+	pane          *tview.Flex // Holds the tree and the info pane
+	infoHeight    int         // Rows of the info pane at rest; 0 sizes it by infoWeight
+	infoWeight    int         // Proportion of the info pane when grown
+}
+
+// embeddedInfoHeight is the height of the embedded info pane, which grows
+// while it shows DDL or a preview
+const embeddedInfoHeight = 6
+
+// Palette holds the colors of the schema browser
+type Palette struct {
+	Root             tcell.Color
+	Catalog          tcell.Color
+	Schema           tcell.Color
+	Table            tcell.Color
+	View             tcell.Color
+	MaterializedView tcell.Color
+	Column           tcell.Color
+	Title            tcell.Color // Tree title
+	InfoTitle        tcell.Color // Info box title
+}
+
+// DefaultPalette is used until SetPalette is called
+var DefaultPalette = Palette{
+	Root:             tcell.ColorGreen,
+	Catalog:          tcell.ColorYellow,
+	Schema:           tcell.ColorLightBlue,
+	Table:            tcell.ColorLightCyan,
+	View:             tcell.ColorPaleGreen,
+	MaterializedView: tcell.ColorPlum,
+	Column:           tcell.ColorWhite,
+	Title:            tcell.ColorGreen,
+	InfoTitle:        tcell.ColorBlue,
 }
 
 // NewBrowser creates a new schema browser
@@ -225,23 +310,10 @@ func NewBrowser(profileName string, logger *zap.Logger) (*Browser, error) {
 		}
 	}
 
-	// Get database connection
-	profile := config.AppConfig.Profiles[profileName]
-	if profile.Host == "" {
-		return nil, fmt.Errorf("profile %s not found", profileName)
-	}
-
-	dsn := fmt.Sprintf("http://%s@%s:%d?catalog=%s&schema=%s",
-		profile.User,
-		profile.Host,
-		profile.Port,
-		profile.Catalog,
-		profile.Schema)
-
 	// Create a connection pool instead of a single connection
-	db, err := sql.Open("trino", dsn)
+	db, err := Connect(profileName)
 	if err != nil {
-		return nil, fmt.Errorf("failed to connect to database: %w", err)
+		return nil, err
 	}
 
 	// Configure connection pooling
@@ -254,7 +326,7 @@ func NewBrowser(profileName string, logger *zap.Logger) (*Browser, error) {
 
 	// Set up the tree view
 	rootNode := tview.NewTreeNode("Trino Schema").
-		SetColor(tcell.ColorGreen).
+		SetColor(DefaultPalette.Root).
 		SetSelectable(false)
 
 	treeView := tview.NewTreeView().
@@ -279,6 +351,8 @@ func NewBrowser(profileName string, logger *zap.Logger) (*Browser, error) {
 		logger:   logger,
 		profile:  profileName,
 		rootNode: rootNode,
+		palette:  DefaultPalette,
+		store:    openStore(profileName, logger),
 	}
 
 	// Set up the node selection handler
@@ -288,32 +362,58 @@ func NewBrowser(profileName string, logger *zap.Logger) (*Browser, error) {
 	return browser, nil
 }
 
-// Start starts the schema browser
-func (b *Browser) Start() error {
+// Connect opens a connection pool to the server of a profile
+func Connect(profileName string) (*sql.DB, error) {
+	profile := config.AppConfig.Profiles[profileName]
+	if profile.Host == "" {
+		return nil, config.UnknownProfile(profileName)
+	}
+
+	db, err := sql.Open("trino", profile.DSN())
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+	return db, nil
+}
+
+// SetPalette changes the browser's colors. Call it before Start or Embed;
+// nodes that are already loaded keep their colors.
+func (b *Browser) SetPalette(palette Palette) {
+	b.palette = palette
+	b.rootNode.SetColor(palette.Root)
+}
+
+// Start starts the schema browser. Cancelling ctx aborts in-flight metadata loads.
+func (b *Browser) Start(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	b.ctx = ctx
+
 	// Create a new application
 	b.app = tview.NewApplication()
 
 	// Set up title bar
 	titleBar := tview.NewTextView().
-		SetText("Trino Schema Browser - Press Esc to exit").
-		SetTextAlign(tview.AlignCenter).
-		SetTextColor(tcell.ColorWhite)
+		SetText("Trino Schema Browser - Press Esc to exit, f to filter the tree, / to search everything loaded, b to bookmark a table, H to show system schemas, r to refresh").
+		SetTextAlign(tview.AlignCenter)
 
 	// Add borders for better UI
 	b.treeView.SetBorder(true).
 		SetTitle(" Schema Explorer ").
 		SetTitleAlign(tview.AlignLeft).
-		SetTitleColor(tcell.ColorGreen)
+		SetTitleColor(b.palette.Title)
 
 	b.infoText.SetBorder(true).
 		SetTitle(" Info ").
 		SetTitleAlign(tview.AlignLeft).
-		SetTitleColor(tcell.ColorBlue)
+		SetTitleColor(b.palette.InfoTitle)
 
 	// Create a flex layout for the main content area
 	contentFlex := tview.NewFlex().
 		AddItem(b.treeView, 0, 3, true).
 		AddItem(b.infoText, 0, 5, false)
+	b.pane = contentFlex
+	b.infoWeight = 5
 
 	// Create a search field
 	searchField := tview.NewInputField().
@@ -358,9 +458,10 @@ func (b *Browser) Start() error {
 								Loaded:  false,
 							}).
 							SetSelectable(true).
-							SetColor(tcell.ColorLightBlue)
+							SetColor(b.palette.Schema)
 						node.AddChild(schemaNode)
 					}
+					b.childrenLoaded(node)
 				})
 			}
 		case "schema":
@@ -372,19 +473,9 @@ func (b *Browser) Start() error {
 				b.app.QueueUpdateDraw(func() {
 					node.ClearChildren()
 					for _, table := range matchedTables {
-						tableNode := tview.NewTreeNode(table).
-							SetReference(&SchemaTreeNode{
-								Type:    "table",
-								Name:    table,
-								Catalog: ref.Catalog,
-								Schema:  ref.Schema,
-								Table:   table,
-								Loaded:  false,
-							}).
-							SetSelectable(true).
-							SetColor(tcell.ColorLightCyan)
-						node.AddChild(tableNode)
+						node.AddChild(b.tableNode(ref.Catalog, ref.Schema, table, b.viewOf(ref.Catalog, ref.Schema, table), b.commentOf(ref.Catalog, ref.Schema, table)))
 					}
+					b.childrenLoaded(node)
 				})
 			}
 		case "table":
@@ -413,19 +504,9 @@ func (b *Browser) Start() error {
 				b.app.QueueUpdateDraw(func() {
 					node.ClearChildren()
 					for _, col := range matchedColumns {
-						colNode := tview.NewTreeNode(fmt.Sprintf("%s (%s)", col.Name, col.Type)).
-							SetReference(&SchemaTreeNode{
-								Type:     "column",
-								Name:     col.Name,
-								Catalog:  ref.Catalog,
-								Schema:   ref.Schema,
-								Table:    ref.Table,
-								DataType: col.Type,
-							}).
-							SetSelectable(true).
-							SetColor(tcell.ColorWhite)
-						node.AddChild(colNode)
+						node.AddChild(b.columnNode(ref.Catalog, ref.Schema, ref.Table, col))
 					}
+					b.childrenLoaded(node)
 				})
 			}
 		}
@@ -446,16 +527,26 @@ func (b *Browser) Start() error {
 
 	// Load catalogs in the background after starting the UI
 	go func() {
-		if err := b.LoadCatalogs(); err != nil {
+		if err := b.runLoad(ctx, b.rootNode, false, b.LoadCatalogs); err != nil && !errors.Is(err, context.Canceled) {
 			b.logger.Error("Failed to load catalogs", zap.Error(err))
-			b.infoText.SetText(fmt.Sprintf("[red]Error loading catalogs: %v[white]", err))
+			b.infoText.SetText(fmt.Sprintf("[red]Error loading catalogs: %v[-]", err))
 		}
 	}()
 
 	// Set keyboard shortcuts
+	b.treeView.SetInputCapture(b.treeKey)
 	b.app.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
 		switch event.Key() {
 		case tcell.KeyEscape:
+			if b.searching {
+				b.closeSearch()
+				return nil
+			}
+			if b.filtering {
+				b.filterInput.SetText("")
+				b.closeFilter()
+				return nil
+			}
 			if b.treeView.HasFocus() {
 				// If the tree has focus, exit the application
 				b.app.Stop()
@@ -478,17 +569,154 @@ func (b *Browser) Start() error {
 		return err
 	}
 
-	// Close the database connection when the application exits
+	// Stop loading and close the database connection when the application exits
+	b.cancelAllLoads()
 	b.db.Close()
+	b.closeStore()
 	return nil
 }
 
+// Embed returns the browser as a pane for another application, e.g. the
+// interactive shell. Enter on a table or column passes its name (fully
+// qualified for tables) to onInsert; Space expands a table's columns, d
+// shows its DDL, i its properties, p previews its rows, g generates
+// queries for it, r reloads the selected subtree, / searches everything
+// loaded, f filters the tree, H shows the hidden schemas and b bookmarks a
+// table under Favorites.
+// Catalogs load in the background until ctx is cancelled; call Close once
+// the pane is no longer needed.
+func (b *Browser) Embed(ctx context.Context, app *tview.Application, onInsert func(name string)) tview.Primitive {
+	b.ctx = ctx
+	b.app = app
+	b.onInsert = onInsert
+
+	b.treeView.SetBorder(true).
+		SetTitle(" Schema (Enter inserts, Space expands) ").
+		SetTitleAlign(tview.AlignLeft).
+		SetTitleColor(b.palette.Title)
+	b.treeView.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyRune && event.Rune() == ' ' {
+			if node := b.treeView.GetCurrentNode(); node != nil {
+				b.toggleNode(node)
+			}
+			return nil
+		}
+		return b.treeKey(event)
+	})
+	b.infoText.SetBorder(true)
+
+	go func() {
+		if err := b.runLoad(ctx, b.rootNode, false, b.LoadCatalogs); err != nil && !errors.Is(err, context.Canceled) {
+			b.logger.Error("Failed to load catalogs", zap.Error(err))
+			b.app.QueueUpdateDraw(func() {
+				b.infoText.SetText(fmt.Sprintf("[red]Error loading catalogs: %v[-]", err))
+			})
+		}
+	}()
+
+	b.pane = tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(b.treeView, 0, 1, true).
+		AddItem(b.infoText, embeddedInfoHeight, 0, false)
+	b.infoHeight = embeddedInfoHeight
+	b.infoWeight = 1
+	return b.pane
+}
+
+// treeKey handles the tree's shortcuts for tables
+func (b *Browser) treeKey(event *tcell.EventKey) *tcell.EventKey {
+	if event = b.refreshKey(event); event == nil {
+		return nil
+	}
+	if event = b.ddlKey(event); event == nil {
+		return nil
+	}
+	if event = b.statsKey(event); event == nil {
+		return nil
+	}
+	if event = b.propertiesKey(event); event == nil {
+		return nil
+	}
+	if event = b.searchKey(event); event == nil {
+		return nil
+	}
+	if event = b.templateKey(event); event == nil {
+		return nil
+	}
+	if event = b.visibilityKey(event); event == nil {
+		return nil
+	}
+	if event = b.bookmarkKey(event); event == nil {
+		return nil
+	}
+	return b.previewKey(event)
+}
+
+// placeInfo puts item in the info pane's slot, grown or at rest
+func (b *Browser) placeInfo(item tview.Primitive, grown bool) {
+	if b.pane == nil {
+		return
+	}
+	b.pane.RemoveItem(b.infoText)
+	if b.preview != nil {
+		b.pane.RemoveItem(b.preview)
+	}
+	if b.searchPane != nil {
+		b.pane.RemoveItem(b.searchPane)
+	}
+	if b.templatePane != nil {
+		b.pane.RemoveItem(b.templatePane)
+	}
+	if b.filterInput != nil {
+		b.pane.RemoveItem(b.filterInput)
+	}
+	if grown || b.infoHeight == 0 {
+		b.pane.AddItem(item, 0, b.infoWeight, false)
+	} else {
+		b.pane.AddItem(item, b.infoHeight, 0, false)
+	}
+}
+
+// TreeView returns the browser's tree, e.g. to give it focus when embedded
+func (b *Browser) TreeView() *tview.TreeView {
+	return b.treeView
+}
+
+// Close releases the browser's database connections
+func (b *Browser) Close() error {
+	b.cancelAllLoads()
+	b.closeStore()
+	return b.db.Close()
+}
+
+// QualifiedName joins catalog, schema and table into a name usable in SQL,
+// quoting the parts that are not plain lower-case identifiers or that are
+// reserved words
+func QualifiedName(parts ...string) string {
+	quoted := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if part != "" {
+			quoted = append(quoted, QuoteIdentifier(part))
+		}
+	}
+	return strings.Join(quoted, ".")
+}
+
+// QuoteIdentifier double-quotes name unless it is a plain lower-case
+// identifier other than a reserved word, as autocomplete.QuoteIdentifier does
+func QuoteIdentifier(name string) string {
+	return autocomplete.QuoteIdentifier(name)
+}
+
 // LoadCatalogs loads the catalogs from Trino
-func (b *Browser) LoadCatalogs() error {
+func (b *Browser) LoadCatalogs(ctx context.Context) error {
+	favorites := b.listBookmarks(ctx)
+
 	// Check if we have this in cache
 	if cachedCatalogs := b.cache.GetCatalogs(); cachedCatalogs != nil {
 		b.logger.Info("Using cached catalogs")
 		b.app.QueueUpdateDraw(func() {
+			b.rootNode.AddChild(b.favoritesNode())
 			for _, catalog := range cachedCatalogs {
 				node := tview.NewTreeNode(catalog).
 					SetReference(&SchemaTreeNode{
@@ -498,14 +726,16 @@ func (b *Browser) LoadCatalogs() error {
 						Loaded:  false,
 					}).
 					SetSelectable(true).
-					SetColor(tcell.ColorYellow)
+					SetColor(b.palette.Catalog)
 				b.rootNode.AddChild(node)
 			}
+			b.childrenLoaded(b.rootNode)
+			b.showFavorites(favorites)
 		})
 		return nil
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
 	rows, err := b.dbPool.QueryContext(ctx, "SHOW CATALOGS")
@@ -538,10 +768,11 @@ func (b *Browser) LoadCatalogs() error {
 	b.tree.mu.Unlock()
 
 	// Update the cache
-	b.cache.Update(b.tree, 5*time.Minute)
+	b.updateCache()
 
 	// Update the UI on the main thread
 	b.app.QueueUpdateDraw(func() {
+		b.rootNode.AddChild(b.favoritesNode())
 		for _, catalog := range catalogs {
 			node := tview.NewTreeNode(catalog).
 				SetReference(&SchemaTreeNode{
@@ -551,16 +782,21 @@ func (b *Browser) LoadCatalogs() error {
 					Loaded:  false,
 				}).
 				SetSelectable(true).
-				SetColor(tcell.ColorYellow)
+				SetColor(b.palette.Catalog)
 			b.rootNode.AddChild(node)
 		}
+		b.childrenLoaded(b.rootNode)
+		b.showFavorites(favorites)
 	})
 
 	return nil
 }
 
 // LoadSchemas loads the schemas for a catalog
-func (b *Browser) LoadSchemas(catalog string, node *tview.TreeNode) error {
+func (b *Browser) LoadSchemas(ctx context.Context, catalog string, node *tview.TreeNode) error {
+	// Read the schemas' tables ahead of the user once the schemas are known
+	defer b.prefetchTables(ctx, catalog, node)
+
 	// Check if we have this in cache
 	if cachedSchemas := b.cache.GetSchemas(catalog); cachedSchemas != nil {
 		b.logger.Info("Using cached schemas", zap.String("catalog", catalog))
@@ -576,22 +812,17 @@ func (b *Browser) LoadSchemas(catalog string, node *tview.TreeNode) error {
 						Loaded:  false,
 					}).
 					SetSelectable(true).
-					SetColor(tcell.ColorLightBlue)
+					SetColor(b.palette.Schema)
 				node.AddChild(schemaNode)
 			}
 			nodeRef := node.GetReference().(*SchemaTreeNode)
 			nodeRef.Loaded = true
+			b.childrenLoaded(node)
 		})
 		return nil
 	}
 
-	// Cancel any previous loading job
-	if b.loadingJob != nil {
-		b.loadingJob()
-	}
-
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	b.loadingJob = cancel
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
 	// Show loading indicator
@@ -599,13 +830,10 @@ func (b *Browser) LoadSchemas(catalog string, node *tview.TreeNode) error {
 		node.SetText(catalog + " (loading...)")
 	})
 
-	query := fmt.Sprintf("SHOW SCHEMAS FROM %s", catalog)
+	query := "SHOW SCHEMAS FROM " + QuoteIdentifier(catalog)
 	rows, err := b.dbPool.QueryContext(ctx, query)
 	if err != nil {
-		b.app.QueueUpdateDraw(func() {
-			node.SetText(catalog)
-			b.infoText.SetText(fmt.Sprintf("[red]Error loading schemas: %v[white]", err))
-		})
+		b.loadFailed(node, catalog, "schemas", err)
 		return fmt.Errorf("failed to query schemas: %w", err)
 	}
 	defer rows.Close()
@@ -614,25 +842,20 @@ func (b *Browser) LoadSchemas(catalog string, node *tview.TreeNode) error {
 	for rows.Next() {
 		var schema string
 		if err := rows.Scan(&schema); err != nil {
-			b.app.QueueUpdateDraw(func() {
-				node.SetText(catalog)
-				b.infoText.SetText(fmt.Sprintf("[red]Error loading schemas: %v[white]", err))
-			})
+			b.loadFailed(node, catalog, "schemas", err)
 			return fmt.Errorf("failed to scan schema: %w", err)
 		}
 		schemas = append(schemas, schema)
 	}
 
 	if err := rows.Err(); err != nil {
-		b.app.QueueUpdateDraw(func() {
-			node.SetText(catalog)
-			b.infoText.SetText(fmt.Sprintf("[red]Error loading schemas: %v[white]", err))
-		})
+		b.loadFailed(node, catalog, "schemas", err)
 		return fmt.Errorf("error iterating schemas: %w", err)
 	}
 
 	// Sort schemas alphabetically
 	sort.Strings(schemas)
+	b.storeSchemas(catalog, schemas)
 
 	// Add schemas to the tree
 	b.tree.mu.Lock()
@@ -645,7 +868,7 @@ func (b *Browser) LoadSchemas(catalog string, node *tview.TreeNode) error {
 	b.tree.mu.Unlock()
 
 	// Update the cache
-	b.cache.Update(b.tree, 5*time.Minute)
+	b.updateCache()
 
 	// Update the UI on the main thread
 	b.app.QueueUpdateDraw(func() {
@@ -661,19 +884,21 @@ func (b *Browser) LoadSchemas(catalog string, node *tview.TreeNode) error {
 					Loaded:  false,
 				}).
 				SetSelectable(true).
-				SetColor(tcell.ColorLightBlue)
+				SetColor(b.palette.Schema)
 			node.AddChild(schemaNode)
 		}
 		nodeRef := node.GetReference().(*SchemaTreeNode)
 		nodeRef.Loaded = true
+		b.childrenLoaded(node)
 	})
 
 	return nil
 }
 
 // LoadTables loads the tables for a schema
-func (b *Browser) LoadTables(catalog, schema string, node *tview.TreeNode) error {
-	// Check if we have this in cache
+func (b *Browser) LoadTables(ctx context.Context, catalog, schema string, node *tview.TreeNode) error {
+	// Check if we have this in cache, or in the store shared with autocomplete
+	warmed := b.warmTables(ctx, catalog, schema)
 	if cachedTables := b.cache.GetTables(catalog, schema); cachedTables != nil {
 		b.logger.Info("Using cached tables",
 			zap.String("catalog", catalog),
@@ -681,32 +906,21 @@ func (b *Browser) LoadTables(catalog, schema string, node *tview.TreeNode) error
 		b.app.QueueUpdateDraw(func() {
 			node.ClearChildren()
 			for _, table := range cachedTables {
-				tableNode := tview.NewTreeNode(table).
-					SetReference(&SchemaTreeNode{
-						Type:    "table",
-						Name:    table,
-						Catalog: catalog,
-						Schema:  schema,
-						Table:   table,
-						Loaded:  false,
-					}).
-					SetSelectable(true).
-					SetColor(tcell.ColorLightCyan)
-				node.AddChild(tableNode)
+				node.AddChild(b.tableNode(catalog, schema, table, b.viewOf(catalog, schema, table), b.commentOf(catalog, schema, table)))
 			}
 			nodeRef := node.GetReference().(*SchemaTreeNode)
 			nodeRef.Loaded = true
+			b.childrenLoaded(node)
 		})
+		if warmed {
+			b.inBackground(ctx, node, func(ctx context.Context) {
+				b.markTables(ctx, catalog, schema, node)
+			})
+		}
 		return nil
 	}
 
-	// Cancel any previous loading job
-	if b.loadingJob != nil {
-		b.loadingJob()
-	}
-
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	b.loadingJob = cancel
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
 	// Show loading indicator
@@ -714,14 +928,39 @@ func (b *Browser) LoadTables(catalog, schema string, node *tview.TreeNode) error
 		node.SetText(schema + " (loading...)")
 	})
 
-	query := fmt.Sprintf("SHOW TABLES FROM %s.%s", catalog, schema)
+	tables, views, comments, err := b.readTables(ctx, catalog, schema)
+	if err != nil {
+		b.loadFailed(node, schema, "tables", err)
+		return err
+	}
+	b.putTables(catalog, schema, tables, views, comments)
+
+	// Update the UI on the main thread
+	b.app.QueueUpdateDraw(func() {
+		node.ClearChildren()
+		node.SetText(schema)
+		for _, table := range tables {
+			var view *View
+			if v, ok := views[table]; ok {
+				view = &v
+			}
+			node.AddChild(b.tableNode(catalog, schema, table, view, comments[table]))
+		}
+		nodeRef := node.GetReference().(*SchemaTreeNode)
+		nodeRef.Loaded = true
+		b.childrenLoaded(node)
+	})
+
+	return nil
+}
+
+// readTables reads a schema's tables from the server, sorted, with its
+// views and table comments
+func (b *Browser) readTables(ctx context.Context, catalog, schema string) ([]string, map[string]View, map[string]string, error) {
+	query := "SHOW TABLES FROM " + QualifiedName(catalog, schema)
 	rows, err := b.dbPool.QueryContext(ctx, query)
 	if err != nil {
-		b.app.QueueUpdateDraw(func() {
-			node.SetText(schema)
-			b.infoText.SetText(fmt.Sprintf("[red]Error loading tables: %v[white]", err))
-		})
-		return fmt.Errorf("failed to query tables: %w", err)
+		return nil, nil, nil, fmt.Errorf("failed to query tables: %w", err)
 	}
 	defer rows.Close()
 
@@ -729,27 +968,31 @@ func (b *Browser) LoadTables(catalog, schema string, node *tview.TreeNode) error
 	for rows.Next() {
 		var table string
 		if err := rows.Scan(&table); err != nil {
-			b.app.QueueUpdateDraw(func() {
-				node.SetText(schema)
-				b.infoText.SetText(fmt.Sprintf("[red]Error loading tables: %v[white]", err))
-			})
-			return fmt.Errorf("failed to scan table: %w", err)
+			return nil, nil, nil, fmt.Errorf("failed to scan table: %w", err)
 		}
 		tables = append(tables, table)
 	}
 
 	if err := rows.Err(); err != nil {
-		b.app.QueueUpdateDraw(func() {
-			node.SetText(schema)
-			b.infoText.SetText(fmt.Sprintf("[red]Error loading tables: %v[white]", err))
-		})
-		return fmt.Errorf("error iterating tables: %w", err)
+		return nil, nil, nil, fmt.Errorf("error iterating tables: %w", err)
 	}
 
 	// Sort tables alphabetically
 	sort.Strings(tables)
 
-	// Add tables to the tree
+	views, err := b.LoadViews(ctx, catalog, schema)
+	if err != nil {
+		b.logger.Warn("Failed to load views", zap.Error(err),
+			zap.String("catalog", catalog),
+			zap.String("schema", schema))
+	}
+	comments := b.LoadTableComments(ctx, catalog, schema)
+	return tables, views, comments, nil
+}
+
+// putTables adds a schema's tables, views and comments to the tree, the
+// cache and the store
+func (b *Browser) putTables(catalog, schema string, tables []string, views map[string]View, comments map[string]string) {
 	b.tree.mu.Lock()
 	if _, ok := b.tree.Tables[catalog]; !ok {
 		b.tree.Tables[catalog] = make(map[string]map[string]bool)
@@ -760,39 +1003,25 @@ func (b *Browser) LoadTables(catalog, schema string, node *tview.TreeNode) error
 	for _, table := range tables {
 		b.tree.Tables[catalog][schema][table] = true
 	}
+	if _, ok := b.tree.Views[catalog]; !ok {
+		b.tree.Views[catalog] = make(map[string]map[string]View)
+	}
+	b.tree.Views[catalog][schema] = views
+	if _, ok := b.tree.Comments[catalog]; !ok {
+		b.tree.Comments[catalog] = make(map[string]map[string]string)
+	}
+	b.tree.Comments[catalog][schema] = comments
 	b.tree.mu.Unlock()
 
 	// Update the cache
-	b.cache.Update(b.tree, 5*time.Minute)
-
-	// Update the UI on the main thread
-	b.app.QueueUpdateDraw(func() {
-		node.ClearChildren()
-		node.SetText(schema)
-		for _, table := range tables {
-			tableNode := tview.NewTreeNode(table).
-				SetReference(&SchemaTreeNode{
-					Type:    "table",
-					Name:    table,
-					Catalog: catalog,
-					Schema:  schema,
-					Table:   table,
-					Loaded:  false,
-				}).
-				SetSelectable(true).
-				SetColor(tcell.ColorLightCyan)
-			node.AddChild(tableNode)
-		}
-		nodeRef := node.GetReference().(*SchemaTreeNode)
-		nodeRef.Loaded = true
-	})
-
-	return nil
+	b.updateCache()
+	b.storeTables(catalog, schema, tables)
 }
 
 // LoadColumns loads the columns for a table
-func (b *Browser) LoadColumns(catalog, schema, table string, node *tview.TreeNode) error {
-	// Check if we have this in cache
+func (b *Browser) LoadColumns(ctx context.Context, catalog, schema, table string, node *tview.TreeNode) error {
+	// Check if we have this in cache, or in the store shared with autocomplete
+	b.warmColumns(ctx, catalog, schema, table)
 	if cachedColumns := b.cache.GetColumns(catalog, schema, table); cachedColumns != nil {
 		b.logger.Info("Using cached columns",
 			zap.String("catalog", catalog),
@@ -801,46 +1030,30 @@ func (b *Browser) LoadColumns(catalog, schema, table string, node *tview.TreeNod
 		b.app.QueueUpdateDraw(func() {
 			node.ClearChildren()
 			for _, col := range cachedColumns {
-				colNode := tview.NewTreeNode(fmt.Sprintf("%s (%s)", col.Name, col.Type)).
-					SetReference(&SchemaTreeNode{
-						Type:     "column",
-						Name:     col.Name,
-						Catalog:  catalog,
-						Schema:   schema,
-						Table:    table,
-						DataType: col.Type,
-					}).
-					SetSelectable(true).
-					SetColor(tcell.ColorWhite)
-				node.AddChild(colNode)
+				node.AddChild(b.columnNode(catalog, schema, table, col))
 			}
+			node.AddChild(b.partitionsNode(catalog, schema, table))
 			nodeRef := node.GetReference().(*SchemaTreeNode)
 			nodeRef.Loaded = true
+			b.childrenLoaded(node)
 		})
 		return nil
 	}
 
-	// Cancel any previous loading job
-	if b.loadingJob != nil {
-		b.loadingJob()
-	}
-
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	b.loadingJob = cancel
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
 	// Show loading indicator
+	// Favorites are labelled with their qualified names
+	label := strings.TrimSuffix(node.GetText(), " (loading...)")
 	b.app.QueueUpdateDraw(func() {
-		node.SetText(table + " (loading...)")
+		node.SetText(label + " (loading...)")
 	})
 
-	query := fmt.Sprintf("DESCRIBE %s.%s.%s", catalog, schema, table)
+	query := "DESCRIBE " + QualifiedName(catalog, schema, table)
 	rows, err := b.dbPool.QueryContext(ctx, query)
 	if err != nil {
-		b.app.QueueUpdateDraw(func() {
-			node.SetText(table)
-			b.infoText.SetText(fmt.Sprintf("[red]Error loading columns: %v[white]", err))
-		})
+		b.loadFailed(node, label, "columns", err)
 		return fmt.Errorf("failed to query columns: %w", err)
 	}
 	defer rows.Close()
@@ -849,22 +1062,18 @@ func (b *Browser) LoadColumns(catalog, schema, table string, node *tview.TreeNod
 	for rows.Next() {
 		var col Column
 		var extraInfo string
-		if err := rows.Scan(&col.Name, &col.Type, &extraInfo); err != nil {
-			b.app.QueueUpdateDraw(func() {
-				node.SetText(table)
-				b.infoText.SetText(fmt.Sprintf("[red]Error loading columns: %v[white]", err))
-			})
+		var comment sql.NullString
+		if err := rows.Scan(&col.Name, &col.Type, &extraInfo, &comment); err != nil {
+			b.loadFailed(node, label, "columns", err)
 			return fmt.Errorf("failed to scan column: %w", err)
 		}
 		col.Nullable = !strings.Contains(extraInfo, "not null")
+		col.Comment = comment.String
 		columns = append(columns, col)
 	}
 
 	if err := rows.Err(); err != nil {
-		b.app.QueueUpdateDraw(func() {
-			node.SetText(table)
-			b.infoText.SetText(fmt.Sprintf("[red]Error loading columns: %v[white]", err))
-		})
+		b.loadFailed(node, label, "columns", err)
 		return fmt.Errorf("error iterating columns: %w", err)
 	}
 
@@ -880,86 +1089,88 @@ func (b *Browser) LoadColumns(catalog, schema, table string, node *tview.TreeNod
 	b.tree.mu.Unlock()
 
 	// Update the cache
-	b.cache.Update(b.tree, 5*time.Minute)
+	b.updateCache()
+	b.storeColumns(catalog, schema, table, columns)
 
 	// Update the UI on the main thread
 	b.app.QueueUpdateDraw(func() {
 		node.ClearChildren()
-		node.SetText(table)
+		node.SetText(label)
 		for _, col := range columns {
-			colNode := tview.NewTreeNode(fmt.Sprintf("%s (%s)", col.Name, col.Type)).
-				SetReference(&SchemaTreeNode{
-					Type:     "column",
-					Name:     col.Name,
-					Catalog:  catalog,
-					Schema:   schema,
-					Table:    table,
-					DataType: col.Type,
-				}).
-				SetSelectable(true).
-				SetColor(tcell.ColorWhite)
-			node.AddChild(colNode)
+			node.AddChild(b.columnNode(catalog, schema, table, col))
 		}
+		node.AddChild(b.partitionsNode(catalog, schema, table))
 		nodeRef := node.GetReference().(*SchemaTreeNode)
 		nodeRef.Loaded = true
+		b.childrenLoaded(node)
 	})
 
 	return nil
 }
 
-// nodeSelected is called when a node is selected
+// nodeSelected is called when a node is selected. When embedded, tables and
+// columns are inserted rather than expanded.
 func (b *Browser) nodeSelected(node *tview.TreeNode) {
 	nodeRef := node.GetReference()
 	if nodeRef == nil {
 		return
 	}
 
+	ref := nodeRef.(*SchemaTreeNode)
+	if b.onInsert != nil {
+		switch ref.Type {
+		case "table":
+			b.onInsert(QualifiedName(ref.Catalog, ref.Schema, ref.Table))
+			return
+		case "column":
+			b.onInsert(QuoteIdentifier(ref.Name))
+			return
+		}
+	}
+	b.toggleNode(node)
+}
+
+// toggleNode loads a node's children on first use and afterwards expands or
+// collapses it; see expand
+func (b *Browser) toggleNode(node *tview.TreeNode) {
+	nodeRef := node.GetReference()
+	if nodeRef == nil {
+		return
+	}
+
 	ref := nodeRef.(*SchemaTreeNode)
 	switch ref.Type {
 	case "catalog":
-		if !ref.Loaded {
-			go func() {
-				if err := b.LoadSchemas(ref.Catalog, node); err != nil {
-					b.logger.Error("Failed to load schemas", zap.Error(err), zap.String("catalog", ref.Catalog))
-				}
-			}()
-		} else {
-			node.SetExpanded(!node.IsExpanded())
-		}
+		b.expand(node, ref, "schemas", func(ctx context.Context) error {
+			return b.LoadSchemas(ctx, ref.Catalog, node)
+		})
 	case "schema":
-		if !ref.Loaded {
-			go func() {
-				if err := b.LoadTables(ref.Catalog, ref.Schema, node); err != nil {
-					b.logger.Error("Failed to load tables", zap.Error(err),
-						zap.String("catalog", ref.Catalog),
-						zap.String("schema", ref.Schema))
-				}
-			}()
-		} else {
-			node.SetExpanded(!node.IsExpanded())
-		}
+		b.expand(node, ref, "tables", func(ctx context.Context) error {
+			return b.LoadTables(ctx, ref.Catalog, ref.Schema, node)
+		})
 	case "table":
-		if !ref.Loaded {
-			go func() {
-				if err := b.LoadColumns(ref.Catalog, ref.Schema, ref.Table, node); err != nil {
-					b.logger.Error("Failed to load columns", zap.Error(err),
-						zap.String("catalog", ref.Catalog),
-						zap.String("schema", ref.Schema),
-						zap.String("table", ref.Table))
-				}
-			}()
-		} else {
-			node.SetExpanded(!node.IsExpanded())
-		}
+		b.expand(node, ref, "columns", func(ctx context.Context) error {
+			return b.LoadColumns(ctx, ref.Catalog, ref.Schema, ref.Table, node)
+		})
 	case "column":
 		// Columns don't have children, just show info
-		b.infoText.SetText(fmt.Sprintf("[green]Column:[white] %s\n[green]Type:[white] %s\n[green]Table:[white] %s.%s.%s",
-			ref.Name, ref.DataType, ref.Catalog, ref.Schema, ref.Table))
+		b.infoText.SetText(columnInfo(ref))
+	case "partitions":
+		b.expand(node, ref, "partitions", func(ctx context.Context) error {
+			return b.LoadPartitions(ctx, ref.Catalog, ref.Schema, ref.Table, node)
+		})
+	case "more_partitions":
+		b.showMorePartitions(node)
+	case "favorites":
+		node.SetExpanded(!node.IsExpanded())
 	}
 }
 
 // nodeChanged is called when the selected node changes
 func (b *Browser) nodeChanged(node *tview.TreeNode) {
+	b.hideDDL()
+	b.hidePreview()
+	b.placeInfo(b.infoText, false)
 	nodeRef := node.GetReference()
 	if nodeRef == nil {
 		return
@@ -968,16 +1179,37 @@ func (b *Browser) nodeChanged(node *tview.TreeNode) {
 	ref := nodeRef.(*SchemaTreeNode)
 	switch ref.Type {
 	case "catalog":
-		b.infoText.SetText(fmt.Sprintf("[green]Catalog:[white] %s\n\nPress Enter to view schemas.", ref.Name))
+		b.infoText.SetText(fmt.Sprintf("[green]Catalog:[-] %s\n\nPress Enter to view schemas.", ref.Name))
 	case "schema":
-		b.infoText.SetText(fmt.Sprintf("[green]Schema:[white] %s\n[green]Catalog:[white] %s\n\nPress Enter to view tables.",
+		b.infoText.SetText(fmt.Sprintf("[green]Schema:[-] %s\n[green]Catalog:[-] %s\n\nPress Enter to view tables.",
 			ref.Schema, ref.Catalog))
 	case "table":
-		b.infoText.SetText(fmt.Sprintf("[green]Table:[white] %s\n[green]Schema:[white] %s\n[green]Catalog:[white] %s\n\nPress Enter to view columns.",
-			ref.Table, ref.Schema, ref.Catalog))
+		if stats := b.cache.GetStats(ref.Catalog, ref.Schema, ref.Table); stats != nil {
+			b.placeInfo(b.infoText, true)
+			b.infoText.SetText(b.tableInfo(ref, stats))
+		} else if b.autoStats {
+			b.loadStats(node, ref)
+		} else {
+			if ref.View != nil && ref.View.Definition != "" {
+				b.placeInfo(b.infoText, true)
+			}
+			b.infoText.SetText(b.tableInfo(ref, nil))
+		}
 	case "column":
-		b.infoText.SetText(fmt.Sprintf("[green]Column:[white] %s\n[green]Type:[white] %s\n[green]Table:[white] %s.%s.%s",
-			ref.Name, ref.DataType, ref.Catalog, ref.Schema, ref.Table))
+		b.infoText.SetText(columnInfo(ref))
+	case "partitions":
+		b.infoText.SetText(fmt.Sprintf("[green]Partitions of:[-] %s.%s.%s\n\nPress Enter to list them, for connectors that expose $partitions.",
+			ref.Catalog, ref.Schema, ref.Table))
+	case "partition":
+		b.infoText.SetText(b.partitionInfo(ref))
+	case "more_partitions":
+		b.infoText.SetText("Press Enter to show the next partitions.")
+	case "favorites":
+		if len(node.GetChildren()) == 0 {
+			b.infoText.SetText("[green]Favorites[-]\n\nNo bookmarks yet. Press b on a table to bookmark it.")
+		} else {
+			b.infoText.SetText(fmt.Sprintf("[green]Favorites:[-] %d bookmarked tables\n\nPress b on a table to bookmark it or remove its bookmark.", len(node.GetChildren())))
+		}
 	}
 }
 
@@ -992,39 +1224,14 @@ func FuzzySearch(input string, items []string) []string {
 
 	// Score each item based on similarity to input
 	type scoredItem struct {
-		index     int
-		score     int
-		matchType string // For debugging
+		index int
+		score int
 	}
 
 	var scored []scoredItem
 	for i, item := range items {
-		lowerItem := strings.ToLower(item)
-
-		// Simple scoring algorithm - the lower the score, the better the match
-		if lowerItem == lowerInput { // Exact match
-			scored = append(scored, scoredItem{i, 0, "exact"})
-		} else if strings.HasPrefix(lowerItem, lowerInput) { // Prefix match
-			scored = append(scored, scoredItem{i, 1, "prefix"})
-		} else if strings.Contains(lowerItem, lowerInput) { // Contains match
-			// Increase the score for contains matches to ensure they come after prefix matches
-			scored = append(scored, scoredItem{i, 100 + strings.Index(lowerItem, lowerInput), "contains"})
-		} else if lowerInput != "" {
-			// Check for subsequence match (characters in the same order but not consecutive)
-			match := true
-			lastPos := -1
-			for _, c := range lowerInput {
-				pos := strings.IndexRune(lowerItem[lastPos+1:], c)
-				if pos == -1 {
-					match = false
-					break
-				}
-				lastPos += pos + 1
-			}
-
-			if match {
-				scored = append(scored, scoredItem{i, 1000 + lastPos, "subsequence"}) // Subsequence match, lowest priority
-			}
+		if score, ok := fuzzyScore(lowerInput, strings.ToLower(item)); ok {
+			scored = append(scored, scoredItem{i, score})
 		}
 	}
 
@@ -1041,3 +1248,28 @@ func FuzzySearch(input string, items []string) []string {
 
 	return result
 }
+
+// fuzzyScore scores how well a lower-cased item matches lower-cased input;
+// the lower the score, the better the match
+func fuzzyScore(lowerInput, lowerItem string) (int, bool) {
+	if lowerItem == lowerInput { // Exact match
+		return 0, true
+	} else if strings.HasPrefix(lowerItem, lowerInput) { // Prefix match
+		return 1, true
+	} else if strings.Contains(lowerItem, lowerInput) { // Contains match
+		// Increase the score for contains matches to ensure they come after prefix matches
+		return 100 + strings.Index(lowerItem, lowerInput), true
+	} else if lowerInput != "" {
+		// Check for subsequence match (characters in the same order but not consecutive)
+		lastPos := -1
+		for _, c := range lowerInput {
+			pos := strings.IndexRune(lowerItem[lastPos+1:], c)
+			if pos == -1 {
+				return 0, false
+			}
+			lastPos += pos + 1
+		}
+		return 1000 + lastPos, true // Subsequence match, lowest priority
+	}
+	return 0, false
+}
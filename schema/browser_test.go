@@ -618,3 +618,108 @@ func TestNodeChanged(t *testing.T) {
 		t.Fatalf("Expected info text to contain 'test_catalog', got '%s'", text)
 	}
 }
+
+// TestNodeSelectedEmbedded tests that an embedded browser inserts names
+// instead of expanding tables
+func TestNodeSelectedEmbedded(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+
+	var inserted []string
+	browser := &Browser{
+		tree:     NewSchemaTree(),
+		cache:    NewSchemaCache(),
+		treeView: tview.NewTreeView(),
+		infoText: tview.NewTextView(),
+		logger:   logger,
+		profile:  "test",
+		rootNode: tview.NewTreeNode("Trino Schema"),
+		onInsert: func(name string) { inserted = append(inserted, name) },
+	}
+
+	tableNode := tview.NewTreeNode("Orders").
+		SetReference(&SchemaTreeNode{
+			Type:    "table",
+			Name:    "Orders",
+			Catalog: "hive",
+			Schema:  "sales",
+			Table:   "Orders",
+		})
+	browser.nodeSelected(tableNode)
+
+	columnNode := tview.NewTreeNode("order id (bigint)").
+		SetReference(&SchemaTreeNode{
+			Type:     "column",
+			Name:     "order id",
+			Catalog:  "hive",
+			Schema:   "sales",
+			Table:    "Orders",
+			DataType: "bigint",
+		})
+	browser.nodeSelected(columnNode)
+
+	want := []string{`hive.sales."Orders"`, `"order id"`}
+	if strings.Join(inserted, "|") != strings.Join(want, "|") {
+		t.Fatalf("Expected inserted names %v, got %v", want, inserted)
+	}
+}
+
+// TestQuoteIdentifier tests identifier quoting for inserted names
+func TestQuoteIdentifier(t *testing.T) {
+	tests := map[string]string{
+		"orders":    "orders",
+		"order_2":   "order_2",
+		"Orders":    `"Orders"`,
+		"2024_data": `"2024_data"`,
+		`say"hi`:    `"say""hi"`,
+		"":          `""`,
+		"order":     `"order"`,
+		"table":     `"table"`,
+		"my-data":   `"my-data"`,
+		"a.b":       `"a.b"`,
+	}
+	for name, want := range tests {
+		if got := QuoteIdentifier(name); got != want {
+			t.Errorf("QuoteIdentifier(%q) = %s, want %s", name, got, want)
+		}
+	}
+	if got := QualifiedName("hive", "", "t"); got != "hive.t" {
+		t.Errorf("QualifiedName skipped parts = %s, want hive.t", got)
+	}
+}
+
+// TestMetadataQueriesQuote tests that the browser's metadata queries quote
+// names with dashes, dots and reserved words
+func TestMetadataQueriesQuote(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer db.Close()
+	mock.MatchExpectationsInOrder(false)
+
+	mock.ExpectQuery(`SHOW TABLES FROM "my-catalog"."order"`).
+		WillReturnRows(sqlmock.NewRows([]string{"Table"}).AddRow("a.b"))
+	mock.ExpectQuery(`SELECT table_name, view_definition FROM "my-catalog".information_schema.views WHERE table_schema = 'order'`).
+		WillReturnRows(sqlmock.NewRows([]string{"table_name", "view_definition"}))
+	mock.ExpectQuery(`SELECT name, definition FROM system.metadata.materialized_views WHERE catalog_name = 'my-catalog' AND schema_name = 'order'`).
+		WillReturnRows(sqlmock.NewRows([]string{"name", "definition"}))
+	mock.ExpectQuery(`SELECT schema_name, table_name, comment FROM system.metadata.table_comments WHERE catalog_name = 'my-catalog' AND schema_name = 'order'`).
+		WillReturnRows(sqlmock.NewRows([]string{"schema_name", "table_name", "comment"}))
+
+	browser := &Browser{
+		tree:   NewSchemaTree(),
+		cache:  NewSchemaCache(),
+		dbPool: db,
+		logger: zaptest.NewLogger(t),
+	}
+	tables, _, _, err := browser.readTables(context.Background(), "my-catalog", "order")
+	if err != nil {
+		t.Fatalf("readTables failed: %v", err)
+	}
+	if len(tables) != 1 || tables[0] != "a.b" {
+		t.Errorf("tables = %q, want [a.b]", tables)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,70 @@
+package schema
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rivo/tview"
+)
+
+// GetComment returns a table's comment from the cache, or "" if it has none
+func (sc *SchemaCache) GetComment(catalog, schema, table string) string {
+	defer sc.rlock()()
+	if sc.Data == nil || time.Now().After(sc.Expiry) {
+		return ""
+	}
+	return sc.Data.Comments[catalog][schema][table]
+}
+
+// LoadTableComments returns the comments of a schema's tables by name. They
+// come from system.metadata, which some servers restrict; failing to read it
+// only leaves the tables undocumented.
+func (b *Browser) LoadTableComments(ctx context.Context, catalog, schema string) map[string]string {
+	comments := make(map[string]string)
+	for key, comment := range tableComments(ctx, b.dbPool, catalog, schema) {
+		if comment != "" {
+			comments[key[1]] = comment
+		}
+	}
+	return comments
+}
+
+// commentOf looks up a table's comment in the cache
+func (b *Browser) commentOf(catalog, schema, table string) string {
+	return b.cache.GetComment(catalog, schema, table)
+}
+
+// columnNode returns the tree node of a column
+func (b *Browser) columnNode(catalog, schema, table string, col Column) *tview.TreeNode {
+	return tview.NewTreeNode(fmt.Sprintf("%s (%s)", col.Name, col.Type)).
+		SetReference(&SchemaTreeNode{
+			Type:     "column",
+			Name:     col.Name,
+			Catalog:  catalog,
+			Schema:   schema,
+			Table:    table,
+			DataType: col.Type,
+			Comment:  col.Comment,
+		}).
+		SetSelectable(true).
+		SetColor(b.palette.Column)
+}
+
+// columnInfo describes a column for the info pane
+func columnInfo(ref *SchemaTreeNode) string {
+	var text strings.Builder
+	fmt.Fprintf(&text, "[green]Column:[-] %s\n[green]Type:[-] %s\n[green]Table:[-] %s.%s.%s",
+		tview.Escape(ref.Name), tview.Escape(ref.DataType),
+		tview.Escape(ref.Catalog), tview.Escape(ref.Schema), tview.Escape(ref.Table))
+	writeComment(&text, ref.Comment)
+	return text.String()
+}
+
+// writeComment adds a table's or column's comment to its description
+func writeComment(text *strings.Builder, comment string) {
+	if comment = strings.TrimSpace(comment); comment != "" {
+		text.WriteString("\n\n[green]Comment:[-]\n" + tview.Escape(comment))
+	}
+}
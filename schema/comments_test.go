@@ -0,0 +1,64 @@
+package schema
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"go.uber.org/zap/zaptest"
+)
+
+// TestLoadTableComments tests reading table comments and tolerating servers
+// that hide them
+func TestLoadTableComments(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer db.Close()
+
+	query := regexp.QuoteMeta(`SELECT schema_name, table_name, comment FROM system.metadata.table_comments WHERE catalog_name = 'hive' AND schema_name = 'sales'`)
+	mock.ExpectQuery(query).
+		WillReturnRows(sqlmock.NewRows([]string{"schema_name", "table_name", "comment"}).
+			AddRow("sales", "orders", "One row per order").
+			AddRow("sales", "refunds", nil).
+			AddRow("sales", "scratch", ""))
+	mock.ExpectQuery(query).WillReturnError(errors.New("Access Denied"))
+
+	browser := &Browser{dbPool: db, logger: zaptest.NewLogger(t)}
+	comments := browser.LoadTableComments(context.Background(), "hive", "sales")
+	if len(comments) != 1 || comments["orders"] != "One row per order" {
+		t.Errorf("comments = %v", comments)
+	}
+	if comments := browser.LoadTableComments(context.Background(), "hive", "sales"); len(comments) != 0 {
+		t.Errorf("comments = %v when they cannot be read", comments)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+// TestCommentInfo tests showing comments in the info pane
+func TestCommentInfo(t *testing.T) {
+	browser := &Browser{tree: NewSchemaTree(), cache: NewSchemaCache(), palette: DefaultPalette}
+	browser.tree.Comments["hive"] = map[string]map[string]string{"sales": {"orders": "One row per [order]"}}
+	browser.cache.Update(browser.tree, time.Minute)
+
+	node := browser.tableNode("hive", "sales", "orders", nil, browser.commentOf("hive", "sales", "orders"))
+	info := browser.tableInfo(node.GetReference().(*SchemaTreeNode), nil)
+	if !strings.Contains(info, "[green]Comment:[-]\nOne row per [order[]") {
+		t.Errorf("table info = %q", info)
+	}
+	if info := browser.tableInfo(&SchemaTreeNode{Type: "table", Table: "refunds"}, nil); strings.Contains(info, "Comment") {
+		t.Errorf("table info = %q for a table without a comment", info)
+	}
+
+	column := browser.columnNode("hive", "sales", "orders", Column{Name: "id", Type: "bigint", Comment: "Order number"})
+	if info := columnInfo(column.GetReference().(*SchemaTreeNode)); !strings.HasSuffix(info, "[green]Comment:[-]\nOrder number") {
+		t.Errorf("column info = %q", info)
+	}
+}
@@ -0,0 +1,196 @@
+package schema
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"go.uber.org/zap"
+)
+
+// showCreateKinds are tried in order, since SHOW CREATE TABLE refuses views
+var showCreateKinds = []string{"TABLE", "VIEW", "MATERIALIZED VIEW"}
+
+// SetHighlighter sets the function that colors DDL for the info pane. It
+// must return text with tview color tags and everything else escaped; by
+// default DDL is shown uncolored.
+func (b *Browser) SetHighlighter(highlight func(sql string) string) {
+	b.highlight = highlight
+}
+
+// SetClipboard sets the function that copies DDL, which describes where the
+// text went. Without one, the browser cannot copy.
+func (b *Browser) SetClipboard(copy func(text string) (string, error)) {
+	b.copy = copy
+}
+
+// ShowCreate returns the statement that creates a table, view or
+// materialized view
+func (b *Browser) ShowCreate(ctx context.Context, catalog, schema, table string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	name := QualifiedName(catalog, schema, table)
+	var firstErr error
+	for _, kind := range showCreateKinds {
+		var ddl string
+		err := b.dbPool.QueryRowContext(ctx, fmt.Sprintf("SHOW CREATE %s %s", kind, name)).Scan(&ddl)
+		if err == nil {
+			return ddl, nil
+		}
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	return "", fmt.Errorf("failed to show create table: %w", firstErr)
+}
+
+// ddlKey handles the tree's DDL shortcuts: d shows the current table's DDL,
+// and once it is shown, y copies it and s saves it to a file
+func (b *Browser) ddlKey(event *tcell.EventKey) *tcell.EventKey {
+	if event.Key() != tcell.KeyRune || event.Modifiers()&(tcell.ModAlt|tcell.ModCtrl) != 0 {
+		return event
+	}
+	node := b.treeView.GetCurrentNode()
+	if node == nil {
+		return event
+	}
+	switch event.Rune() {
+	case 'd':
+		if ref, ok := node.GetReference().(*SchemaTreeNode); ok && ref.Type == "table" {
+			b.showDDL(node, ref)
+			return nil
+		}
+	case 'y':
+		if b.ddlNode == node {
+			b.copyDDL()
+			return nil
+		}
+	case 's':
+		if b.ddlNode == node {
+			b.saveDDL()
+			return nil
+		}
+	}
+	return event
+}
+
+// showDDL fetches a table's DDL in the background and shows it in the info
+// pane, unless the user has moved on by then
+func (b *Browser) showDDL(node *tview.TreeNode, ref *SchemaTreeNode) {
+	b.infoText.SetText(fmt.Sprintf("[green]Table:[-] %s\n\nLoading DDL...", tview.Escape(ref.Table)))
+	go func() {
+		ddl, err := b.ShowCreate(b.ctx, ref.Catalog, ref.Schema, ref.Table)
+		if err != nil {
+			b.logger.Error("Failed to show DDL", zap.Error(err),
+				zap.String("catalog", ref.Catalog),
+				zap.String("schema", ref.Schema),
+				zap.String("table", ref.Table))
+		}
+		b.app.QueueUpdateDraw(func() {
+			if b.treeView.GetCurrentNode() != node {
+				return
+			}
+			if err != nil {
+				b.infoText.SetText(fmt.Sprintf("[red]Error loading DDL: %v[-]", tview.Escape(err.Error())))
+				return
+			}
+			b.hidePreview()
+			b.ddl = ddl
+			b.ddlNode = node
+			b.placeInfo(b.infoText, true)
+			b.renderDDL("y copies, s saves to a file")
+		})
+	}()
+}
+
+// renderDDL shows the current DDL under a line with note
+func (b *Browser) renderDDL(note string) {
+	highlighted := tview.Escape(b.ddl)
+	if b.highlight != nil {
+		highlighted = b.highlight(b.ddl)
+	}
+	b.infoText.SetText(fmt.Sprintf("[green]DDL[-] (%s)\n\n%s", note, highlighted))
+	b.infoText.ScrollToBeginning()
+}
+
+// hideDDL forgets the shown DDL and shrinks the embedded info pane again
+func (b *Browser) hideDDL() {
+	if b.ddlNode == nil {
+		return
+	}
+	b.ddl = ""
+	b.ddlNode = nil
+	b.placeInfo(b.infoText, false)
+}
+
+// copyDDL puts the shown DDL on the clipboard
+func (b *Browser) copyDDL() {
+	if b.copy == nil {
+		b.renderDDL("[red]copying is not available[-]")
+		return
+	}
+	how, err := b.copy(b.ddl)
+	if err != nil {
+		b.renderDDL(fmt.Sprintf("[red]copy failed: %s[-]", tview.Escape(err.Error())))
+		return
+	}
+	b.renderDDL("copied " + tview.Escape(how))
+}
+
+// saveDDL writes the shown DDL to a file in the working directory
+func (b *Browser) saveDDL() {
+	ref := b.ddlNode.GetReference().(*SchemaTreeNode)
+	path, err := writeDDLFile(".", ref.Catalog, ref.Schema, ref.Table, b.ddl)
+	if err != nil {
+		b.renderDDL(fmt.Sprintf("[red]save failed: %s[-]", tview.Escape(err.Error())))
+		return
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	b.renderDDL("saved to " + tview.Escape(path))
+}
+
+// writeDDLFile saves ddl as catalog.schema.table.sql in dir, adding a number
+// to the name rather than overwriting an existing file, and returns the path
+func writeDDLFile(dir, catalog, schema, table, ddl string) (string, error) {
+	parts := []string{catalog, schema, table}
+	for i, part := range parts {
+		parts[i] = strings.NewReplacer("/", "_", `\`, "_").Replace(part)
+	}
+	base := strings.Join(parts, ".")
+	if !strings.HasSuffix(ddl, "\n") {
+		ddl += "\n"
+	}
+
+	for n := 1; n <= 100; n++ {
+		name := base + ".sql"
+		if n > 1 {
+			name = fmt.Sprintf("%s-%d.sql", base, n)
+		}
+		path := filepath.Join(dir, name)
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if errors.Is(err, os.ErrExist) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		if _, err := f.WriteString(ddl); err != nil {
+			f.Close()
+			return "", err
+		}
+		return path, f.Close()
+	}
+	return "", fmt.Errorf("%s.sql and 99 numbered copies already exist", base)
+}
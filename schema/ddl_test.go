@@ -0,0 +1,142 @@
+package schema
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"go.uber.org/zap/zaptest"
+)
+
+// TestShowCreateFallsBackToView tests that views, which SHOW CREATE TABLE
+// refuses, still show their DDL
+func TestShowCreateFallsBackToView(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SHOW CREATE TABLE hive.sales."Recent"`)).
+		WillReturnError(errors.New(`Relation 'hive.sales.Recent' is a view, not a table`))
+	mock.ExpectQuery(regexp.QuoteMeta(`SHOW CREATE VIEW hive.sales."Recent"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"Create View"}).AddRow("CREATE VIEW hive.sales.recent AS SELECT 1"))
+
+	browser := &Browser{dbPool: db, logger: zaptest.NewLogger(t)}
+	ddl, err := browser.ShowCreate(context.Background(), "hive", "sales", "Recent")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ddl != "CREATE VIEW hive.sales.recent AS SELECT 1" {
+		t.Errorf("ddl = %q", ddl)
+	}
+
+	for range showCreateKinds {
+		mock.ExpectQuery("SHOW CREATE").WillReturnError(errors.New("Table 'hive.sales.gone' does not exist"))
+	}
+	if _, err := browser.ShowCreate(context.Background(), "hive", "sales", "gone"); err == nil ||
+		!strings.Contains(err.Error(), "does not exist") {
+		t.Errorf("error = %v, want the SHOW CREATE TABLE error", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+// TestWriteDDLFile tests that saving never overwrites an earlier file
+func TestWriteDDLFile(t *testing.T) {
+	dir := t.TempDir()
+	for i, want := range []string{"hive.sales.orders.sql", "hive.sales.orders-2.sql"} {
+		path, err := writeDDLFile(dir, "hive", "sales", "orders", "CREATE TABLE orders ()")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if filepath.Base(path) != want {
+			t.Errorf("save %d went to %s, want %s", i+1, path, want)
+		}
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "hive.sales.orders.sql"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "CREATE TABLE orders ()\n" {
+		t.Errorf("file holds %q", data)
+	}
+
+	path, err := writeDDLFile(dir, "hive", "a/b", "t", "x\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Dir(path) != dir {
+		t.Errorf("a slash in a name escaped the directory: %s", path)
+	}
+}
+
+// TestDDLKeys tests copying and saving the DDL shown for a table
+func TestDDLKeys(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	var copied string
+	browser := &Browser{
+		cache:    NewSchemaCache(),
+		treeView: tview.NewTreeView(),
+		infoText: tview.NewTextView().SetDynamicColors(true),
+		logger:   zaptest.NewLogger(t),
+		copy: func(text string) (string, error) {
+			copied = text
+			return "to the clipboard", nil
+		},
+		highlight: func(sql string) string { return "[blue]" + tview.Escape(sql) + "[-]" },
+	}
+	tableNode := tview.NewTreeNode("orders").SetReference(&SchemaTreeNode{
+		Type: "table", Name: "orders", Catalog: "hive", Schema: "sales", Table: "orders",
+	})
+	browser.treeView.SetRoot(tableNode).SetCurrentNode(tableNode)
+
+	// Without shown DDL, y and s are left to the tree
+	key := func(r rune) *tcell.EventKey { return tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone) }
+	if browser.ddlKey(key('y')) == nil {
+		t.Fatal("y was handled without DDL")
+	}
+
+	browser.ddl = "CREATE TABLE hive.sales.orders (id bigint)"
+	browser.ddlNode = tableNode
+	browser.renderDDL("y copies, s saves to a file")
+	if text := browser.infoText.GetText(true); !strings.Contains(text, browser.ddl) {
+		t.Fatalf("info pane = %q, want the DDL", text)
+	}
+
+	if browser.ddlKey(key('y')) != nil {
+		t.Fatal("y was not handled")
+	}
+	if copied != browser.ddl {
+		t.Errorf("copied %q", copied)
+	}
+	if text := browser.infoText.GetText(true); !strings.Contains(text, "copied to the clipboard") {
+		t.Errorf("info pane = %q, want the copy noted", text)
+	}
+
+	if browser.ddlKey(key('s')) != nil {
+		t.Fatal("s was not handled")
+	}
+	data, err := os.ReadFile("hive.sales.orders.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != browser.ddl+"\n" {
+		t.Errorf("saved %q", data)
+	}
+
+	// Moving to another node forgets the DDL
+	browser.nodeChanged(tableNode)
+	if browser.ddlNode != nil || browser.ddlKey(key('s')) == nil {
+		t.Error("the DDL outlived a node change")
+	}
+}
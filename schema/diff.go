@@ -0,0 +1,181 @@
+package schema
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/TFMV/trino-cli/autocomplete"
+)
+
+// SchemaChange is one difference between the metadata of two servers,
+// described as what turns the first into the second
+type SchemaChange struct {
+	Kind   string `json:"kind"`           // catalog, schema, table or column
+	Name   string `json:"name"`           // Qualified name
+	Change string `json:"change"`         // added, removed or retyped
+	From   string `json:"from,omitempty"` // The column's type on the first server, when retyped
+	To     string `json:"to,omitempty"`   // The column's type on the second server, when retyped
+}
+
+// String describes the change on one line, marked +, - or ~ like a diff
+func (c SchemaChange) String() string {
+	switch c.Change {
+	case "added":
+		return fmt.Sprintf("+ %-7s %s", c.Kind, c.Name)
+	case "removed":
+		return fmt.Sprintf("- %-7s %s", c.Kind, c.Name)
+	default:
+		return fmt.Sprintf("~ %-7s %s  %s -> %s", c.Kind, c.Name, c.From, c.To)
+	}
+}
+
+// DiffMetadata lists what differs from one export to another: catalogs,
+// schemas and tables only one of them has, and the columns of shared tables
+// that were added, removed or changed type. A missing catalog or schema is
+// reported once rather than table by table. Changes are sorted by name.
+func DiffMetadata(from, to *MetadataExport) []SchemaChange {
+	var changes []SchemaChange
+	diffNames(catalogsByName(from), catalogsByName(to), "catalog", nil, &changes, func(name string, a, b CatalogExport) {
+		diffNames(schemasByName(a), schemasByName(b), "schema", []string{name}, &changes, func(schema string, a, b SchemaExport) {
+			diffNames(tablesByName(a), tablesByName(b), "table", []string{name, schema}, &changes, func(table string, a, b TableExport) {
+				diffColumns(QualifiedName(name, schema, table), a.Columns, b.Columns, &changes)
+			})
+		})
+	})
+	sortKey := func(c SchemaChange) string {
+		return strings.ToLower(strings.ReplaceAll(c.Name, `"`, ""))
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		if a, b := sortKey(changes[i]), sortKey(changes[j]); a != b {
+			return a < b
+		}
+		return changes[i].Name+changes[i].Change < changes[j].Name+changes[j].Change
+	})
+	return changes
+}
+
+// diffNames reports the objects only one side has, and compares the ones
+// both have with same
+func diffNames[T any](from, to map[string]T, kind string, parent []string, changes *[]SchemaChange, same func(name string, a, b T)) {
+	qualified := func(name string) string {
+		return QualifiedName(append(append([]string(nil), parent...), name)...)
+	}
+	for name, a := range from {
+		if b, ok := to[name]; ok {
+			same(name, a, b)
+		} else {
+			*changes = append(*changes, SchemaChange{Kind: kind, Name: qualified(name), Change: "removed"})
+		}
+	}
+	for name := range to {
+		if _, ok := from[name]; !ok {
+			*changes = append(*changes, SchemaChange{Kind: kind, Name: qualified(name), Change: "added"})
+		}
+	}
+}
+
+// diffColumns compares the columns of a table on both sides. Types are
+// compared ignoring case.
+func diffColumns(table string, from, to []ColumnExport, changes *[]SchemaChange) {
+	types := make(map[string]string, len(to))
+	for _, col := range to {
+		types[col.Name] = col.Type
+	}
+	seen := make(map[string]bool, len(from))
+	for _, col := range from {
+		seen[col.Name] = true
+		name := table + "." + QuoteIdentifier(col.Name)
+		switch typ, ok := types[col.Name]; {
+		case !ok:
+			*changes = append(*changes, SchemaChange{Kind: "column", Name: name, Change: "removed"})
+		case !strings.EqualFold(typ, col.Type):
+			*changes = append(*changes, SchemaChange{Kind: "column", Name: name, Change: "retyped", From: col.Type, To: typ})
+		}
+	}
+	for _, col := range to {
+		if !seen[col.Name] {
+			*changes = append(*changes, SchemaChange{Kind: "column", Name: table + "." + QuoteIdentifier(col.Name), Change: "added"})
+		}
+	}
+}
+
+// catalogsByName indexes an export's catalogs
+func catalogsByName(m *MetadataExport) map[string]CatalogExport {
+	byName := make(map[string]CatalogExport, len(m.Catalogs))
+	for _, c := range m.Catalogs {
+		byName[c.Name] = c
+	}
+	return byName
+}
+
+// schemasByName indexes a catalog's schemas
+func schemasByName(c CatalogExport) map[string]SchemaExport {
+	byName := make(map[string]SchemaExport, len(c.Schemas))
+	for _, s := range c.Schemas {
+		byName[s.Name] = s
+	}
+	return byName
+}
+
+// tablesByName indexes a schema's tables
+func tablesByName(s SchemaExport) map[string]TableExport {
+	byName := make(map[string]TableExport, len(s.Tables))
+	for _, t := range s.Tables {
+		byName[t.Name] = t
+	}
+	return byName
+}
+
+// StoredMetadata reads the metadata autocomplete has cached for a profile in
+// the shape of an export, limited to a catalog or schema when they are not
+// empty. The cache holds only the schemas autocomplete introspects, and no
+// table types or nullability.
+func StoredMetadata(store *autocomplete.SchemaCache, catalog, schema string) (*MetadataExport, error) {
+	catalogs := []string{catalog}
+	if catalog == "" {
+		var err error
+		if catalogs, err = store.GetCatalogs(); err != nil {
+			return nil, fmt.Errorf("failed to read cached catalogs: %w", err)
+		}
+		sort.Strings(catalogs)
+	}
+
+	export := &MetadataExport{Catalogs: []CatalogExport{}}
+	for _, catalogName := range catalogs {
+		schemas, err := store.GetSchemas(catalogName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read cached schemas of %s: %w", catalogName, err)
+		}
+		if len(schemas) == 0 && catalog == "" {
+			continue
+		}
+		sort.Strings(schemas)
+		c := CatalogExport{Name: catalogName, Schemas: []SchemaExport{}}
+		for _, schemaName := range schemas {
+			if schema != "" && schemaName != schema {
+				continue
+			}
+			tables, err := store.GetTables(catalogName, schemaName)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read cached tables of %s.%s: %w", catalogName, schemaName, err)
+			}
+			sort.Strings(tables)
+			s := SchemaExport{Name: schemaName, Tables: []TableExport{}}
+			for _, table := range tables {
+				columns, err := store.GetColumns(catalogName, schemaName, table)
+				if err != nil {
+					return nil, fmt.Errorf("failed to read cached columns of %s.%s.%s: %w", catalogName, schemaName, table, err)
+				}
+				t := TableExport{Name: table, Columns: []ColumnExport{}}
+				for _, col := range columns {
+					t.Columns = append(t.Columns, ColumnExport{Name: col.Name, Type: col.DataType, Nullable: true, Comment: col.Comment})
+				}
+				s.Tables = append(s.Tables, t)
+			}
+			c.Schemas = append(c.Schemas, s)
+		}
+		export.Catalogs = append(export.Catalogs, c)
+	}
+	return export, nil
+}
@@ -0,0 +1,86 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/TFMV/trino-cli/autocomplete"
+	"go.uber.org/zap/zaptest"
+)
+
+// TestDiffMetadata tests reporting what differs between two servers
+func TestDiffMetadata(t *testing.T) {
+	prod := &MetadataExport{Catalogs: []CatalogExport{
+		{Name: "hive", Schemas: []SchemaExport{
+			{Name: "sales", Tables: []TableExport{
+				{Name: "orders", Columns: []ColumnExport{{Name: "id", Type: "bigint"}, {Name: "amount", Type: "decimal(10,2)"}, {Name: "note", Type: "varchar"}}},
+				{Name: "refunds", Columns: []ColumnExport{{Name: "id", Type: "bigint"}}},
+			}},
+			{Name: "legacy", Tables: []TableExport{{Name: "old"}}},
+		}},
+		{Name: "kafka", Schemas: []SchemaExport{}},
+	}}
+	staging := &MetadataExport{Catalogs: []CatalogExport{
+		{Name: "hive", Schemas: []SchemaExport{
+			{Name: "sales", Tables: []TableExport{
+				{Name: "orders", Columns: []ColumnExport{{Name: "id", Type: "BIGINT"}, {Name: "amount", Type: "decimal(12,2)"}, {Name: "Status", Type: "varchar"}}},
+				{Name: "returns", Columns: []ColumnExport{{Name: "id", Type: "bigint"}}},
+			}},
+		}},
+	}}
+
+	want := []string{
+		"- schema  hive.legacy",
+		"~ column  hive.sales.orders.amount  decimal(10,2) -> decimal(12,2)",
+		"- column  hive.sales.orders.note",
+		`+ column  hive.sales.orders."Status"`,
+		"- table   hive.sales.refunds",
+		"+ table   hive.sales.returns",
+		"- catalog kafka",
+	}
+	changes := DiffMetadata(prod, staging)
+	if len(changes) != len(want) {
+		t.Fatalf("changes = %v, want %q", changes, want)
+	}
+	for i, change := range changes {
+		if change.String() != want[i] {
+			t.Errorf("change %d = %q, want %q", i, change.String(), want[i])
+		}
+	}
+	if changes := DiffMetadata(staging, staging); len(changes) != 0 {
+		t.Errorf("a server differs from itself: %v", changes)
+	}
+}
+
+// TestStoredMetadata tests reading autocomplete's cache as an export
+func TestStoredMetadata(t *testing.T) {
+	store, err := autocomplete.NewSchemaCache(t.TempDir(), zaptest.NewLogger(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	for _, s := range []autocomplete.SchemaMetadata{
+		{Catalog: "hive", Name: "sales", Tables: []autocomplete.TableMetadata{
+			{Name: "orders", Columns: []autocomplete.ColumnMetadata{{Name: "id", DataType: "bigint"}, {Name: "amount", DataType: "double"}}},
+		}},
+		{Catalog: "hive", Name: "web"},
+	} {
+		if err := store.StoreSchema(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	export, err := StoredMetadata(store, "", "sales")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(export.Catalogs) != 1 || len(export.Catalogs[0].Schemas) != 1 {
+		t.Fatalf("export = %+v", export)
+	}
+	orders := export.Catalogs[0].Schemas[0].Tables[0]
+	if orders.Name != "orders" || len(orders.Columns) != 2 || orders.Columns[1].Name != "amount" || orders.Columns[1].Type != "double" {
+		t.Errorf("orders = %+v", orders)
+	}
+	if export, _ := StoredMetadata(store, "iceberg", ""); len(export.Catalogs) != 1 || len(export.Catalogs[0].Schemas) != 0 {
+		t.Errorf("export of an uncached catalog = %+v", export)
+	}
+}
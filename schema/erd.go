@@ -0,0 +1,220 @@
+package schema
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Relationship is a reference from a column of one table to the key of
+// another, drawn as an edge of an entity-relationship diagram
+type Relationship struct {
+	Table     string
+	Column    string
+	RefTable  string
+	RefColumn string
+	Optional  bool // The column is nullable, so a row may reference nothing
+}
+
+// InferRelationships finds the references between a schema's tables. Trino
+// exposes no primary or foreign keys, so they follow naming conventions: a
+// table's key is a column named id or <table>_id (singular or plural), and
+// a column <name>_id refers to the key of the table called <name> or its
+// plural. NOT NULL, the constraint connectors do report, decides whether a
+// reference is optional.
+func InferRelationships(s SchemaExport) []Relationship {
+	keys := make(map[string]string, len(s.Tables))
+	byName := make(map[string]string, len(s.Tables))
+	for _, t := range s.Tables {
+		if key := keyColumn(t); key != "" {
+			keys[t.Name] = key
+		}
+		byName[strings.ToLower(t.Name)] = t.Name
+	}
+
+	var relationships []Relationship
+	for _, t := range s.Tables {
+		for _, col := range t.Columns {
+			name := strings.ToLower(col.Name)
+			stem, ok := strings.CutSuffix(name, "_id")
+			if !ok || stem == "" {
+				continue
+			}
+			for _, candidate := range plurals(stem) {
+				ref, ok := byName[candidate]
+				if !ok || keys[ref] == "" || (ref == t.Name && strings.EqualFold(col.Name, keys[ref])) {
+					continue
+				}
+				relationships = append(relationships, Relationship{
+					Table:     t.Name,
+					Column:    col.Name,
+					RefTable:  ref,
+					RefColumn: keys[ref],
+					Optional:  col.Nullable,
+				})
+				break
+			}
+		}
+	}
+	sort.Slice(relationships, func(i, j int) bool {
+		a, b := relationships[i], relationships[j]
+		if a.Table != b.Table {
+			return a.Table < b.Table
+		}
+		return a.Column < b.Column
+	})
+	return relationships
+}
+
+// keyColumn returns the column taken as a table's key, or "" if none looks
+// like one
+func keyColumn(t TableExport) string {
+	names := []string{"id"}
+	for _, stem := range singulars(strings.ToLower(t.Name)) {
+		names = append(names, stem+"_id")
+	}
+	for _, name := range names {
+		for _, col := range t.Columns {
+			if strings.EqualFold(col.Name, name) {
+				return col.Name
+			}
+		}
+	}
+	return ""
+}
+
+// plurals returns the names a table of stem things may have, stem first
+func plurals(stem string) []string {
+	names := []string{stem, stem + "s", stem + "es"}
+	if base, ok := strings.CutSuffix(stem, "y"); ok {
+		names = append(names, base+"ies")
+	}
+	return names
+}
+
+// singulars returns the stems a table name may be the plural of, the name
+// itself first
+func singulars(name string) []string {
+	stems := []string{name}
+	if base, ok := strings.CutSuffix(name, "ies"); ok {
+		stems = append(stems, base+"y")
+	}
+	if base, ok := strings.CutSuffix(name, "es"); ok {
+		stems = append(stems, base)
+	}
+	if base, ok := strings.CutSuffix(name, "s"); ok {
+		stems = append(stems, base)
+	}
+	return stems
+}
+
+// ERDiagram draws a schema's tables and relationships in Mermaid's erDiagram
+// syntax or as a Graphviz DOT graph
+func ERDiagram(s SchemaExport, relationships []Relationship, format string) (string, error) {
+	switch strings.ToLower(format) {
+	case "mermaid":
+		return mermaidERD(s, relationships), nil
+	case "dot":
+		return dotERD(s, relationships), nil
+	}
+	return "", fmt.Errorf("unsupported format %q (use mermaid or dot)", format)
+}
+
+// foreignKeys indexes the referencing columns by table and column
+func foreignKeys(relationships []Relationship) map[[2]string]bool {
+	fks := make(map[[2]string]bool, len(relationships))
+	for _, r := range relationships {
+		fks[[2]string{r.Table, r.Column}] = true
+	}
+	return fks
+}
+
+var mermaidUnsafe = regexp.MustCompile(`[^A-Za-z0-9_-]+`)
+
+// mermaidName makes a name usable as a Mermaid entity or attribute
+func mermaidName(name string) string {
+	return mermaidUnsafe.ReplaceAllString(name, "_")
+}
+
+// mermaidERD draws the diagram in Mermaid. Types are shortened to their base
+// name, e.g. decimal for decimal(10,2), which is all Mermaid accepts.
+func mermaidERD(s SchemaExport, relationships []Relationship) string {
+	fks := foreignKeys(relationships)
+	var out strings.Builder
+	out.WriteString("erDiagram\n")
+	for _, t := range s.Tables {
+		key := keyColumn(t)
+		fmt.Fprintf(&out, "    %s {\n", mermaidName(t.Name))
+		for _, col := range t.Columns {
+			typ, _, _ := strings.Cut(col.Type, "(")
+			fmt.Fprintf(&out, "        %s %s", mermaidName(strings.TrimSpace(typ)), mermaidName(col.Name))
+			switch {
+			case col.Name == key && fks[[2]string{t.Name, col.Name}]:
+				out.WriteString(" PK, FK")
+			case col.Name == key:
+				out.WriteString(" PK")
+			case fks[[2]string{t.Name, col.Name}]:
+				out.WriteString(" FK")
+			}
+			if comment := strings.TrimSpace(col.Comment); comment != "" {
+				fmt.Fprintf(&out, ` "%s"`, strings.ReplaceAll(comment, `"`, "'"))
+			}
+			out.WriteString("\n")
+		}
+		out.WriteString("    }\n")
+	}
+	for _, r := range relationships {
+		cardinality := "||--o{"
+		if r.Optional {
+			cardinality = "|o--o{"
+		}
+		fmt.Fprintf(&out, "    %s %s %s : \"%s\"\n", mermaidName(r.RefTable), cardinality, mermaidName(r.Table), strings.ReplaceAll(r.Column, `"`, "'"))
+	}
+	return out.String()
+}
+
+// dotERD draws the diagram as a DOT graph of HTML-labelled tables, with an
+// edge from each referencing column to the key it refers to. Optional
+// references are dashed.
+func dotERD(s SchemaExport, relationships []Relationship) string {
+	fks := foreignKeys(relationships)
+	quote := func(name string) string {
+		return `"` + strings.ReplaceAll(name, `"`, `\"`) + `"`
+	}
+
+	var out strings.Builder
+	out.WriteString("digraph erd {\n    rankdir=LR;\n    node [shape=plaintext];\n")
+	for _, t := range s.Tables {
+		key := keyColumn(t)
+		fmt.Fprintf(&out, "    %s [label=<<table border=\"0\" cellborder=\"1\" cellspacing=\"0\">", quote(t.Name))
+		fmt.Fprintf(&out, "<tr><td colspan=\"2\" bgcolor=\"lightgrey\"><b>%s</b></td></tr>", html.EscapeString(t.Name))
+		for _, col := range t.Columns {
+			var marks []string
+			if col.Name == key {
+				marks = append(marks, "PK")
+			}
+			if fks[[2]string{t.Name, col.Name}] {
+				marks = append(marks, "FK")
+			}
+			name := html.EscapeString(col.Name)
+			if len(marks) > 0 {
+				name = "<b>" + name + "</b> " + strings.Join(marks, ", ")
+			}
+			fmt.Fprintf(&out, "<tr><td port=%s align=\"left\">%s</td><td align=\"left\">%s</td></tr>",
+				quote(html.EscapeString(col.Name)), name, html.EscapeString(col.Type))
+		}
+		out.WriteString("</table>>];\n")
+	}
+	for _, r := range relationships {
+		style := ""
+		if r.Optional {
+			style = " [style=dashed]"
+		}
+		fmt.Fprintf(&out, "    %s:%s -> %s:%s%s;\n",
+			quote(r.Table), quote(r.Column), quote(r.RefTable), quote(r.RefColumn), style)
+	}
+	out.WriteString("}\n")
+	return out.String()
+}
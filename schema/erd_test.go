@@ -0,0 +1,75 @@
+package schema
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// erdSchema is a small sales schema following the usual key conventions
+var erdSchema = SchemaExport{Name: "sales", Tables: []TableExport{
+	{Name: "categories", Columns: []ColumnExport{{Name: "category_id", Type: "bigint"}, {Name: "name", Type: "varchar"}}},
+	{Name: "customers", Columns: []ColumnExport{{Name: "id", Type: "bigint"}, {Name: "name", Type: "varchar", Comment: `Full "legal" name`}}},
+	{Name: "orders", Columns: []ColumnExport{
+		{Name: "id", Type: "bigint"},
+		{Name: "customer_id", Type: "bigint"},
+		{Name: "coupon_id", Type: "bigint", Nullable: true},
+		{Name: "amount", Type: "decimal(10,2)"},
+	}},
+	{Name: "products", Columns: []ColumnExport{{Name: "id", Type: "bigint"}, {Name: "category_id", Type: "bigint", Nullable: true}}},
+	{Name: "order_items", Columns: []ColumnExport{{Name: "order_id", Type: "bigint"}, {Name: "product_id", Type: "bigint"}}},
+}}
+
+// TestInferRelationships tests finding references by column names
+func TestInferRelationships(t *testing.T) {
+	want := []Relationship{
+		{Table: "order_items", Column: "order_id", RefTable: "orders", RefColumn: "id"},
+		{Table: "order_items", Column: "product_id", RefTable: "products", RefColumn: "id"},
+		{Table: "orders", Column: "customer_id", RefTable: "customers", RefColumn: "id"},
+		{Table: "products", Column: "category_id", RefTable: "categories", RefColumn: "category_id", Optional: true},
+	}
+	if got := InferRelationships(erdSchema); !reflect.DeepEqual(got, want) {
+		t.Errorf("InferRelationships = %+v\nwant %+v", got, want)
+	}
+}
+
+// TestERDiagram tests drawing the diagram in Mermaid and DOT
+func TestERDiagram(t *testing.T) {
+	relationships := InferRelationships(erdSchema)
+
+	mermaid, err := ERDiagram(erdSchema, relationships, "mermaid")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"erDiagram\n    categories {\n        bigint category_id PK\n",
+		`        varchar name "Full 'legal' name"`,
+		"        decimal amount\n",
+		"        bigint customer_id FK\n",
+		`    customers ||--o{ orders : "customer_id"`,
+		`    categories |o--o{ products : "category_id"`,
+	} {
+		if !strings.Contains(mermaid, want) {
+			t.Errorf("Mermaid diagram lacks %q:\n%s", want, mermaid)
+		}
+	}
+
+	dot, err := ERDiagram(erdSchema, relationships, "DOT")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"digraph erd {",
+		`<td port="customer_id" align="left"><b>customer_id</b> FK</td><td align="left">bigint</td>`,
+		`"orders":"customer_id" -> "customers":"id";`,
+		`"products":"category_id" -> "categories":"category_id" [style=dashed];`,
+	} {
+		if !strings.Contains(dot, want) {
+			t.Errorf("DOT diagram lacks %q:\n%s", want, dot)
+		}
+	}
+
+	if _, err := ERDiagram(erdSchema, relationships, "svg"); err == nil {
+		t.Error("an unsupported format was accepted")
+	}
+}
@@ -0,0 +1,245 @@
+package schema
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// exportWorkers is how many tables ExportMetadata describes at once
+const exportWorkers = 8
+
+// MetadataExport is the schema metadata written by `schema export`. Every
+// list is sorted by name, columns by position, so exports diff cleanly.
+type MetadataExport struct {
+	Catalogs []CatalogExport `json:"catalogs" yaml:"catalogs"`
+}
+
+// CatalogExport describes a catalog
+type CatalogExport struct {
+	Name    string         `json:"name" yaml:"name"`
+	Schemas []SchemaExport `json:"schemas" yaml:"schemas"`
+}
+
+// SchemaExport describes a schema
+type SchemaExport struct {
+	Name   string        `json:"name" yaml:"name"`
+	Tables []TableExport `json:"tables" yaml:"tables"`
+}
+
+// TableExport describes a table or view
+type TableExport struct {
+	Name    string         `json:"name" yaml:"name"`
+	Type    string         `json:"type" yaml:"type"` // BASE TABLE or VIEW
+	Comment string         `json:"comment,omitempty" yaml:"comment,omitempty"`
+	Columns []ColumnExport `json:"columns" yaml:"columns"`
+}
+
+// ColumnExport describes a column
+type ColumnExport struct {
+	Name     string `json:"name" yaml:"name"`
+	Type     string `json:"type" yaml:"type"`
+	Nullable bool   `json:"nullable" yaml:"nullable"`
+	Comment  string `json:"comment,omitempty" yaml:"comment,omitempty"`
+}
+
+// ExportMetadata reads the catalogs, schemas, tables and columns of a
+// server. A non-empty catalog or schema limits the export to it; otherwise
+// information_schema is left out.
+func ExportMetadata(ctx context.Context, db *sql.DB, catalog, schema string) (*MetadataExport, error) {
+	catalogs := []string{catalog}
+	if catalog == "" {
+		var err error
+		if catalogs, err = queryStrings(ctx, db, "SHOW CATALOGS"); err != nil {
+			return nil, fmt.Errorf("failed to list catalogs: %w", err)
+		}
+		sort.Strings(catalogs)
+	}
+
+	export := &MetadataExport{Catalogs: []CatalogExport{}}
+	for _, name := range catalogs {
+		c, err := exportCatalog(ctx, db, name, schema)
+		if err != nil {
+			return nil, err
+		}
+		export.Catalogs = append(export.Catalogs, c)
+	}
+	return export, nil
+}
+
+// exportCatalog reads the metadata of one catalog
+func exportCatalog(ctx context.Context, db *sql.DB, catalog, schema string) (CatalogExport, error) {
+	where := " WHERE table_schema <> 'information_schema'"
+	if schema != "" {
+		where = " WHERE table_schema = " + sqlString(schema)
+	}
+	schemaWhere := strings.ReplaceAll(where, "table_schema", "schema_name")
+
+	c := CatalogExport{Name: catalog, Schemas: []SchemaExport{}}
+	names, err := queryStrings(ctx, db, fmt.Sprintf("SELECT schema_name FROM %s.information_schema.schemata%s",
+		QuoteIdentifier(catalog), schemaWhere))
+	if err != nil {
+		return c, fmt.Errorf("failed to list schemas of %s: %w", catalog, err)
+	}
+	sort.Strings(names)
+	schemas := make(map[string]*SchemaExport, len(names))
+	for _, name := range names {
+		c.Schemas = append(c.Schemas, SchemaExport{Name: name, Tables: []TableExport{}})
+	}
+	for i := range c.Schemas {
+		schemas[c.Schemas[i].Name] = &c.Schemas[i]
+	}
+
+	rows, err := db.QueryContext(ctx, fmt.Sprintf(
+		"SELECT table_schema, table_name, table_type FROM %s.information_schema.tables%s ORDER BY table_schema, table_name",
+		QuoteIdentifier(catalog), where))
+	if err != nil {
+		return c, fmt.Errorf("failed to list tables of %s: %w", catalog, err)
+	}
+	for rows.Next() {
+		var schemaName string
+		var table TableExport
+		if err := rows.Scan(&schemaName, &table.Name, &table.Type); err != nil {
+			rows.Close()
+			return c, fmt.Errorf("failed to scan table: %w", err)
+		}
+		if s := schemas[schemaName]; s != nil {
+			s.Tables = append(s.Tables, table)
+		}
+	}
+	err = rows.Err()
+	rows.Close()
+	if err != nil {
+		return c, fmt.Errorf("error iterating tables of %s: %w", catalog, err)
+	}
+
+	// Table comments live in system.metadata, which some servers restrict
+	comments := tableComments(ctx, db, catalog, schema)
+
+	var tables []*TableExport
+	var paths [][2]string
+	for i := range c.Schemas {
+		s := &c.Schemas[i]
+		for j := range s.Tables {
+			s.Tables[j].Comment = comments[[2]string{s.Name, s.Tables[j].Name}]
+			tables = append(tables, &s.Tables[j])
+			paths = append(paths, [2]string{s.Name, s.Tables[j].Name})
+		}
+	}
+	if err := describeTables(ctx, db, catalog, tables, paths); err != nil {
+		return c, err
+	}
+	return c, nil
+}
+
+// tableComments returns the comments of a catalog's tables by schema and
+// table, or none if they cannot be read
+func tableComments(ctx context.Context, db *sql.DB, catalog, schema string) map[[2]string]string {
+	comments := make(map[[2]string]string)
+	query := "SELECT schema_name, table_name, comment FROM system.metadata.table_comments WHERE catalog_name = " + sqlString(catalog)
+	if schema != "" {
+		query += " AND schema_name = " + sqlString(schema)
+	}
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return comments
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var schemaName, table string
+		var comment sql.NullString
+		if err := rows.Scan(&schemaName, &table, &comment); err != nil {
+			return comments
+		}
+		if comment.Valid {
+			comments[[2]string{schemaName, table}] = comment.String
+		}
+	}
+	return comments
+}
+
+// describeTables fills in the columns of tables, a few at a time
+func describeTables(ctx context.Context, db *sql.DB, catalog string, tables []*TableExport, paths [][2]string) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	jobs := make(chan int)
+	errs := make(chan error, len(tables))
+	var wg sync.WaitGroup
+	for range min(exportWorkers, len(tables)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				columns, err := describeTable(ctx, db, catalog, paths[i][0], paths[i][1])
+				if err != nil {
+					errs <- err
+					cancel()
+					continue
+				}
+				tables[i].Columns = columns
+			}
+		}()
+	}
+	for i := range tables {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	close(errs)
+	if err := <-errs; err != nil {
+		return err
+	}
+	return ctx.Err()
+}
+
+// describeTable reads a table's columns
+func describeTable(ctx context.Context, db *sql.DB, catalog, schema, table string) ([]ColumnExport, error) {
+	rows, err := db.QueryContext(ctx, "DESCRIBE "+QualifiedName(catalog, schema, table))
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe %s.%s.%s: %w", catalog, schema, table, err)
+	}
+	defer rows.Close()
+
+	columns := []ColumnExport{}
+	for rows.Next() {
+		var col ColumnExport
+		var extra string
+		var comment sql.NullString
+		if err := rows.Scan(&col.Name, &col.Type, &extra, &comment); err != nil {
+			return nil, fmt.Errorf("failed to scan column of %s.%s.%s: %w", catalog, schema, table, err)
+		}
+		col.Nullable = !strings.Contains(extra, "not null")
+		col.Comment = comment.String
+		columns = append(columns, col)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating columns of %s.%s.%s: %w", catalog, schema, table, err)
+	}
+	return columns, nil
+}
+
+// queryStrings returns the first column of a query's rows
+func queryStrings(ctx context.Context, db *sql.DB, query string) ([]string, error) {
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var values []string
+	for rows.Next() {
+		var value string
+		if err := rows.Scan(&value); err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, rows.Err()
+}
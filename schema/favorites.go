@@ -0,0 +1,106 @@
+package schema
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/TFMV/trino-cli/bookmark"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"go.uber.org/zap"
+)
+
+// favoritesNode returns the node listing the profile's bookmarked tables,
+// creating it on first use
+func (b *Browser) favoritesNode() *tview.TreeNode {
+	if b.favorites == nil {
+		b.favorites = tview.NewTreeNode("Favorites").
+			SetReference(&SchemaTreeNode{Type: "favorites", Name: "Favorites", Loaded: true}).
+			SetSelectable(true).
+			SetColor(b.palette.Root)
+	}
+	return b.favorites
+}
+
+// listBookmarks reads the profile's bookmarks. Without the bookmark database
+// the Favorites node just stays empty.
+func (b *Browser) listBookmarks(ctx context.Context) []bookmark.Bookmark {
+	bookmarks, err := bookmark.List(ctx, b.profile)
+	if err != nil {
+		b.logger.Debug("Failed to list bookmarks", zap.Error(err))
+	}
+	return bookmarks
+}
+
+// showFavorites puts a node for each bookmark under the Favorites node,
+// labelled with the table's qualified name
+func (b *Browser) showFavorites(bookmarks []bookmark.Bookmark) {
+	node := b.favoritesNode()
+	b.clearChildren(node)
+	for _, bm := range bookmarks {
+		view := b.viewOf(bm.Catalog, bm.Schema, bm.Table)
+		child := b.tableNode(bm.Catalog, bm.Schema, bm.Table, view, b.commentOf(bm.Catalog, bm.Schema, bm.Table))
+		child.SetText(tableLabel(QualifiedName(bm.Catalog, bm.Schema, bm.Table), view))
+		node.AddChild(child)
+	}
+	b.childrenLoaded(node)
+}
+
+// reloadFavorites reads the bookmarks again and shows them
+func (b *Browser) reloadFavorites() {
+	go func() {
+		bookmarks := b.listBookmarks(b.ctx)
+		b.app.QueueUpdateDraw(func() {
+			b.showFavorites(bookmarks)
+			if current := b.treeView.GetCurrentNode(); current != nil && b.parentOf(current) == nil && current != b.rootNode {
+				// The selected favorite was removed
+				b.treeView.SetCurrentNode(b.favorites)
+			}
+		})
+	}()
+}
+
+// bookmarkKey handles b, which bookmarks the selected table or removes its
+// bookmark
+func (b *Browser) bookmarkKey(event *tcell.EventKey) *tcell.EventKey {
+	if event.Key() != tcell.KeyRune || event.Rune() != 'b' || event.Modifiers()&(tcell.ModAlt|tcell.ModCtrl) != 0 {
+		return event
+	}
+	node := b.treeView.GetCurrentNode()
+	if node == nil {
+		return event
+	}
+	ref, ok := node.GetReference().(*SchemaTreeNode)
+	if !ok || ref.Type != "table" {
+		return event
+	}
+
+	name := QualifiedName(ref.Catalog, ref.Schema, ref.Table)
+	added, err := b.toggleBookmark(b.ctx, ref.Catalog, ref.Schema, ref.Table)
+	switch {
+	case err != nil:
+		b.infoText.SetText(fmt.Sprintf("[red]Error saving bookmark: %v[-]", tview.Escape(err.Error())))
+		return nil
+	case added:
+		b.infoText.SetText(fmt.Sprintf("Bookmarked %s. It is listed under Favorites.", tview.Escape(name)))
+	default:
+		b.infoText.SetText(fmt.Sprintf("Removed %s from Favorites.", tview.Escape(name)))
+	}
+	b.reloadFavorites()
+	return nil
+}
+
+// toggleBookmark bookmarks a table, or removes its bookmark if it has one,
+// and reports whether it is now bookmarked
+func (b *Browser) toggleBookmark(ctx context.Context, catalog, schema, table string) (bool, error) {
+	bookmarks, err := bookmark.List(ctx, b.profile)
+	if err != nil {
+		return false, err
+	}
+	for _, bm := range bookmarks {
+		if bm.Catalog == catalog && bm.Schema == schema && bm.Table == table {
+			return false, bookmark.Remove(ctx, b.profile, catalog, schema, table)
+		}
+	}
+	return true, bookmark.Add(ctx, b.profile, catalog, schema, table)
+}
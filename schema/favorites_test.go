@@ -0,0 +1,64 @@
+package schema
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/TFMV/trino-cli/bookmark"
+	"github.com/rivo/tview"
+	"go.uber.org/zap"
+)
+
+// TestFavorites tests bookmarking tables and listing them under Favorites
+func TestFavorites(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	if err := bookmark.Initialize(); err != nil {
+		t.Fatal(err)
+	}
+	defer bookmark.Close()
+
+	browser := &Browser{
+		cache:    NewSchemaCache(),
+		logger:   zap.NewNop(),
+		profile:  "prod",
+		rootNode: tview.NewTreeNode("Trino Schema"),
+	}
+	browser.treeView = tview.NewTreeView().SetRoot(browser.rootNode)
+	browser.rootNode.AddChild(browser.favoritesNode())
+	browser.childrenLoaded(browser.rootNode)
+
+	ctx := context.Background()
+	for _, table := range []string{"orders", "Refunds"} {
+		if added, err := browser.toggleBookmark(ctx, "hive", "sales", table); err != nil || !added {
+			t.Fatalf("toggleBookmark(%s) = %v, %v", table, added, err)
+		}
+	}
+	browser.showFavorites(browser.listBookmarks(ctx))
+	if got := childNames(browser.favorites); !slices.Equal(got, []string{`hive.sales."Refunds"`, "hive.sales.orders"}) {
+		t.Errorf("favorites = %q", got)
+	}
+	ref := browser.favorites.GetChildren()[1].GetReference().(*SchemaTreeNode)
+	if ref.Type != "table" || ref.Catalog != "hive" || ref.Schema != "sales" || ref.Table != "orders" {
+		t.Errorf("favorite = %+v", ref)
+	}
+
+	browser.setFilter("ord")
+	if got := childNames(browser.favorites); !slices.Equal(got, []string{"hive.sales.orders"}) {
+		t.Errorf("filtered favorites = %q", got)
+	}
+	browser.setFilter("")
+
+	if added, err := browser.toggleBookmark(ctx, "hive", "sales", "orders"); err != nil || added {
+		t.Fatalf("toggleBookmark of a bookmarked table = %v, %v", added, err)
+	}
+	browser.showFavorites(browser.listBookmarks(ctx))
+	if got := childNames(browser.favorites); !slices.Equal(got, []string{`hive.sales."Refunds"`}) {
+		t.Errorf("favorites after removing one = %q", got)
+	}
+
+	browser.profile = "dev"
+	if got := browser.listBookmarks(ctx); len(got) != 0 {
+		t.Errorf("another profile's bookmarks = %+v", got)
+	}
+}
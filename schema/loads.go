@@ -0,0 +1,197 @@
+package schema
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/rivo/tview"
+	"go.uber.org/zap"
+)
+
+// maxLoads is how many nodes load their children at once; further loads
+// wait in line
+const maxLoads = 4
+
+// loadJob is a queued or running load of a node's children, or work in the
+// background on its behalf such as prefetching
+type loadJob struct {
+	node       *tview.TreeNode
+	ctx        context.Context
+	cancel     context.CancelFunc
+	done       chan struct{}
+	background bool
+}
+
+// loads tracks the browser's load jobs. Each runs under a context of its
+// own, which is cancelled when its node is collapsed or refreshed, or when
+// the browser closes.
+type loads struct {
+	mu    sync.Mutex
+	jobs  map[*loadJob]bool
+	slots chan struct{} // Holds a token per running load
+}
+
+// add registers a job for node under a new context derived from ctx
+func (l *loads) add(ctx context.Context, node *tview.TreeNode, background bool) (context.Context, *loadJob) {
+	ctx, cancel := context.WithCancel(ctx)
+	job := &loadJob{node: node, ctx: ctx, cancel: cancel, done: make(chan struct{}), background: background}
+	if l.jobs == nil {
+		l.jobs = make(map[*loadJob]bool)
+	}
+	l.jobs[job] = true
+	return ctx, job
+}
+
+// remove ends a job, cancelling its context
+func (l *loads) remove(job *loadJob) {
+	l.mu.Lock()
+	delete(l.jobs, job)
+	l.mu.Unlock()
+	job.cancel()
+	close(job.done)
+}
+
+// running returns the job loading node's children, if there is one that
+// has not been cancelled
+func (l *loads) running(node *tview.TreeNode) *loadJob {
+	for job := range l.jobs {
+		if job.node == node && !job.background && job.ctx.Err() == nil {
+			return job
+		}
+	}
+	return nil
+}
+
+// runLoad loads node's children with load, waiting its turn behind the
+// loads already running. If node is being loaded already, runLoad waits
+// for that load instead, unless restart is set, which cancels it first.
+// Cancelled loads return context.Canceled.
+func (b *Browser) runLoad(ctx context.Context, node *tview.TreeNode, restart bool, load func(ctx context.Context) error) error {
+	b.loads.mu.Lock()
+	if job := b.loads.running(node); job != nil {
+		if !restart {
+			b.loads.mu.Unlock()
+			select {
+			case <-job.done:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		job.cancel()
+	}
+	ctx, job := b.loads.add(ctx, node, false)
+	if b.loads.slots == nil {
+		b.loads.slots = make(chan struct{}, maxLoads)
+	}
+	slots := b.loads.slots
+	b.loads.mu.Unlock()
+	defer b.loads.remove(job)
+
+	select {
+	case slots <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	defer func() { <-slots }()
+	return load(ctx)
+}
+
+// queueLoad loads node's children in the background, logging failures
+// other than cancellation
+func (b *Browser) queueLoad(node *tview.TreeNode, what string, load func(ctx context.Context) error) {
+	go func() {
+		if err := b.runLoad(b.ctx, node, false, load); err != nil && !errors.Is(err, context.Canceled) {
+			b.logger.Error("Failed to load "+what, zap.Error(err), zap.String("node", node.GetText()))
+		}
+	}()
+}
+
+// inBackground runs work on node's behalf without waiting for a turn, under
+// a context cancelled along with node's loads. The context keeps ctx's
+// values but not its cancellation, since work outlives the load that
+// starts it.
+func (b *Browser) inBackground(ctx context.Context, node *tview.TreeNode, work func(ctx context.Context)) {
+	b.loads.mu.Lock()
+	ctx, job := b.loads.add(context.WithoutCancel(ctx), node, true)
+	b.loads.mu.Unlock()
+	go func() {
+		defer b.loads.remove(job)
+		work(ctx)
+	}()
+}
+
+// loading reports whether node's children are being loaded
+func (b *Browser) loading(node *tview.TreeNode) bool {
+	b.loads.mu.Lock()
+	defer b.loads.mu.Unlock()
+	return b.loads.running(node) != nil
+}
+
+// cancelLoads cancels the loads of node and of the nodes under it,
+// including those the filter hides
+func (b *Browser) cancelLoads(node *tview.TreeNode) {
+	under := make(map[*tview.TreeNode]bool)
+	var visit func(n *tview.TreeNode)
+	visit = func(n *tview.TreeNode) {
+		if under[n] {
+			return
+		}
+		under[n] = true
+		children, ok := b.unfiltered[n]
+		if !ok {
+			children = n.GetChildren()
+		}
+		for _, child := range children {
+			visit(child)
+		}
+	}
+	visit(node)
+
+	b.loads.mu.Lock()
+	defer b.loads.mu.Unlock()
+	for job := range b.loads.jobs {
+		if under[job.node] {
+			job.cancel()
+		}
+	}
+}
+
+// cancelAllLoads cancels every load, as the browser closes
+func (b *Browser) cancelAllLoads() {
+	b.loads.mu.Lock()
+	defer b.loads.mu.Unlock()
+	for job := range b.loads.jobs {
+		job.cancel()
+	}
+}
+
+// loadFailed puts node's label back after a failed load and reports the
+// error, unless the load was cancelled
+func (b *Browser) loadFailed(node *tview.TreeNode, label, what string, err error) {
+	b.app.QueueUpdateDraw(func() {
+		node.SetText(label)
+		if !errors.Is(err, context.Canceled) {
+			b.infoText.SetText(fmt.Sprintf("[red]Error loading %s: %v[-]", what, tview.Escape(err.Error())))
+		}
+	})
+}
+
+// expand loads a node's children on first use, or cancels the load if it is
+// running already, and afterwards expands or collapses the node. Collapsing
+// cancels the loads under the node.
+func (b *Browser) expand(node *tview.TreeNode, ref *SchemaTreeNode, what string, load func(ctx context.Context) error) {
+	switch {
+	case ref.Loaded:
+		if node.IsExpanded() {
+			b.cancelLoads(node)
+		}
+		node.SetExpanded(!node.IsExpanded())
+	case b.loading(node):
+		b.cancelLoads(node)
+	default:
+		b.queueLoad(node, what, load)
+	}
+}
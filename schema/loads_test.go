@@ -0,0 +1,168 @@
+package schema
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rivo/tview"
+)
+
+// TestRunLoad tests that a node loads once at a time and that restarting a
+// load cancels the running one
+func TestRunLoad(t *testing.T) {
+	browser := &Browser{}
+	node := tview.NewTreeNode("hive")
+	started, release := make(chan struct{}), make(chan struct{})
+	var runs atomic.Int32
+
+	first := make(chan error, 1)
+	go func() {
+		first <- browser.runLoad(context.Background(), node, false, func(ctx context.Context) error {
+			runs.Add(1)
+			close(started)
+			<-release
+			return nil
+		})
+	}()
+	<-started
+	if !browser.loading(node) {
+		t.Error("the node is not loading")
+	}
+
+	// A second load joins the running one
+	joined := make(chan error, 1)
+	go func() {
+		joined <- browser.runLoad(context.Background(), node, false, func(ctx context.Context) error {
+			runs.Add(1)
+			return nil
+		})
+	}()
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	if err := <-first; err != nil {
+		t.Fatal(err)
+	}
+	if err := <-joined; err != nil || runs.Load() != 1 {
+		t.Errorf("joined load = %v after %d runs, want one run", err, runs.Load())
+	}
+	if browser.loading(node) {
+		t.Error("the node is still loading")
+	}
+
+	// Restarting cancels the running load
+	started = make(chan struct{})
+	cancelled := make(chan error, 1)
+	go func() {
+		cancelled <- browser.runLoad(context.Background(), node, false, func(ctx context.Context) error {
+			close(started)
+			<-ctx.Done()
+			return ctx.Err()
+		})
+	}()
+	<-started
+	if err := browser.runLoad(context.Background(), node, true, func(ctx context.Context) error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-cancelled; !errors.Is(err, context.Canceled) {
+		t.Errorf("restarted load = %v, want it cancelled", err)
+	}
+}
+
+// TestCancelLoads tests that collapsing a node cancels the loads under it
+// and no others
+func TestCancelLoads(t *testing.T) {
+	browser := &Browser{rootNode: tview.NewTreeNode("Trino Schema")}
+	hive, iceberg := tview.NewTreeNode("hive"), tview.NewTreeNode("iceberg")
+	sales := tview.NewTreeNode("sales")
+	browser.rootNode.AddChild(hive).AddChild(iceberg)
+	hive.AddChild(sales)
+
+	var started sync.WaitGroup
+	results := make(map[*tview.TreeNode]chan error)
+	for _, node := range []*tview.TreeNode{sales, iceberg} {
+		started.Add(1)
+		result := make(chan error, 1)
+		results[node] = result
+		go func() {
+			result <- browser.runLoad(context.Background(), node, false, func(ctx context.Context) error {
+				started.Done()
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case <-time.After(200 * time.Millisecond):
+					return nil
+				}
+			})
+		}()
+	}
+	started.Wait()
+
+	background := make(chan error, 1)
+	browser.inBackground(context.Background(), hive, func(ctx context.Context) {
+		<-ctx.Done()
+		background <- ctx.Err()
+	})
+
+	browser.cancelLoads(hive)
+	if err := <-results[sales]; !errors.Is(err, context.Canceled) {
+		t.Errorf("load under the collapsed node = %v, want it cancelled", err)
+	}
+	if err := <-background; !errors.Is(err, context.Canceled) {
+		t.Errorf("background work of the collapsed node = %v, want it cancelled", err)
+	}
+	if err := <-results[iceberg]; err != nil {
+		t.Errorf("load of another node = %v, want it finished", err)
+	}
+}
+
+// TestRunLoadLimit tests that only maxLoads loads run at once
+func TestRunLoadLimit(t *testing.T) {
+	browser := &Browser{}
+	var running, most atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 3*maxLoads; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			browser.runLoad(context.Background(), tview.NewTreeNode("schema"), false, func(ctx context.Context) error {
+				n := running.Add(1)
+				for m := most.Load(); n > m && !most.CompareAndSwap(m, n); m = most.Load() {
+				}
+				time.Sleep(10 * time.Millisecond)
+				running.Add(-1)
+				return nil
+			})
+		}()
+	}
+	wg.Wait()
+	if most.Load() > maxLoads {
+		t.Errorf("%d loads ran at once, want at most %d", most.Load(), maxLoads)
+	}
+}
+
+// TestCacheReadsDuringLoads tests reading the cache while loads write to
+// the tree; run with -race
+func TestCacheReadsDuringLoads(t *testing.T) {
+	browser := &Browser{tree: NewSchemaTree(), cache: NewSchemaCache()}
+	var wg sync.WaitGroup
+	for _, schema := range []string{"sales", "web", "finance"} {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			browser.putTables("hive", schema, []string{"orders"}, nil, map[string]string{"orders": "Orders"})
+		}()
+		go func() {
+			defer wg.Done()
+			browser.cache.GetTables("hive", schema)
+			browser.cache.GetComment("hive", schema, "orders")
+		}()
+	}
+	wg.Wait()
+	if got := browser.cache.GetTables("hive", "web"); len(got) != 1 {
+		t.Errorf("tables = %q", got)
+	}
+}
@@ -0,0 +1,306 @@
+package schema
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rivo/tview"
+	"go.uber.org/zap"
+)
+
+const (
+	partitionPageSize   = 100   // Partition nodes added per page
+	partitionFetchLimit = 10000 // Partitions read from $partitions at most
+)
+
+// Partition is a row of a table's $partitions table. Nil counts are not
+// reported by the connector, e.g. Hive reports only partition values.
+type Partition struct {
+	Values   string
+	RowCount *float64
+	Size     *float64 // Bytes
+	Files    *float64
+}
+
+// partitionMetrics maps the $partitions columns of Iceberg and Delta Lake to
+// the counts they hold
+var partitionMetrics = map[string]func(*Partition) **float64{
+	"record_count": func(p *Partition) **float64 { return &p.RowCount },
+	"total_size":   func(p *Partition) **float64 { return &p.Size },
+	"file_count":   func(p *Partition) **float64 { return &p.Files },
+}
+
+// GetPartitions returns a table's partitions from the cache
+func (sc *SchemaCache) GetPartitions(catalog, schema, table string) []Partition {
+	defer sc.rlock()()
+	if sc.Data == nil || time.Now().After(sc.Expiry) {
+		return nil
+	}
+
+	if schemas, ok := sc.Data.Partitions[catalog]; ok {
+		if tables, ok := schemas[schema]; ok {
+			return tables[table]
+		}
+	}
+	return nil
+}
+
+// partitionsNode returns the node under a table that lists its partitions
+// once expanded
+func (b *Browser) partitionsNode(catalog, schema, table string) *tview.TreeNode {
+	return tview.NewTreeNode("Partitions").
+		SetReference(&SchemaTreeNode{
+			Type:    "partitions",
+			Name:    "Partitions",
+			Catalog: catalog,
+			Schema:  schema,
+			Table:   table,
+		}).
+		SetSelectable(true).
+		SetColor(b.palette.Schema)
+}
+
+// QueryPartitions reads up to partitionFetchLimit rows of a table's
+// $partitions table. The second result reports whether there were more.
+func (b *Browser) QueryPartitions(ctx context.Context, catalog, schema, table string) ([]Partition, bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	query := fmt.Sprintf("SELECT * FROM %s LIMIT %d",
+		QualifiedName(catalog, schema, table+"$partitions"), partitionFetchLimit+1)
+	rows, err := b.dbPool.QueryContext(ctx, query)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to query partitions: %w", err)
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read partition columns: %w", err)
+	}
+	// Iceberg and Delta Lake put the values in a partition row next to
+	// counts; Hive lists one column per partition key
+	keyed := true
+	for _, col := range columns {
+		if _, metric := partitionMetrics[col]; metric || col == "partition" {
+			keyed = false
+		}
+	}
+
+	var partitions []Partition
+	for rows.Next() {
+		values := make([]any, len(columns))
+		pointers := make([]any, len(columns))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+		if err := rows.Scan(pointers...); err != nil {
+			return nil, false, fmt.Errorf("failed to scan partition: %w", err)
+		}
+
+		var p Partition
+		var parts []string
+		for i, col := range columns {
+			switch metric, ok := partitionMetrics[col]; {
+			case !keyed && ok:
+				*metric(&p) = partitionNumber(values[i])
+			case keyed:
+				parts = append(parts, col+"="+formatPartitionValue(values[i]))
+			case col == "partition":
+				parts = append(parts, formatPartitionValue(values[i]))
+			}
+		}
+		p.Values = strings.Join(parts, "/")
+		partitions = append(partitions, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, false, fmt.Errorf("error iterating partitions: %w", err)
+	}
+
+	more := len(partitions) > partitionFetchLimit
+	if more {
+		partitions = partitions[:partitionFetchLimit]
+	}
+	return partitions, more, nil
+}
+
+// formatPartitionValue renders a partition value; row values, as Iceberg
+// reports them, become their fields joined by commas
+func formatPartitionValue(value any) string {
+	if fields, ok := value.([]any); ok {
+		parts := make([]string, len(fields))
+		for i, field := range fields {
+			parts[i] = formatPartitionValue(field)
+		}
+		return strings.Join(parts, ", ")
+	}
+	return formatPreviewValue(value)
+}
+
+// partitionNumber converts a scanned count, which is nil when unknown
+func partitionNumber(value any) *float64 {
+	var f float64
+	switch v := value.(type) {
+	case int64:
+		f = float64(v)
+	case float64:
+		f = v
+	case []byte:
+		if _, err := fmt.Sscan(string(v), &f); err != nil {
+			return nil
+		}
+	default:
+		return nil
+	}
+	return &f
+}
+
+// LoadPartitions lists a table's partitions under node, one page at a time
+func (b *Browser) LoadPartitions(ctx context.Context, catalog, schema, table string, node *tview.TreeNode) error {
+	partitions := b.cache.GetPartitions(catalog, schema, table)
+	more := false
+	if partitions == nil {
+		b.app.QueueUpdateDraw(func() {
+			node.SetText("Partitions (loading...)")
+		})
+
+		var err error
+		partitions, more, err = b.QueryPartitions(ctx, catalog, schema, table)
+		if err != nil {
+			b.app.QueueUpdateDraw(func() {
+				node.SetText("Partitions (not available)")
+				b.infoText.SetText(fmt.Sprintf("[red]Error loading partitions: %v[-]", tview.Escape(err.Error())))
+			})
+			return err
+		}
+
+		b.tree.mu.Lock()
+		if _, ok := b.tree.Partitions[catalog]; !ok {
+			b.tree.Partitions[catalog] = make(map[string]map[string][]Partition)
+		}
+		if _, ok := b.tree.Partitions[catalog][schema]; !ok {
+			b.tree.Partitions[catalog][schema] = make(map[string][]Partition)
+		}
+		b.tree.Partitions[catalog][schema][table] = partitions
+		b.tree.mu.Unlock()
+
+		b.updateCache()
+	}
+
+	b.app.QueueUpdateDraw(func() {
+		count := fmt.Sprint(len(partitions))
+		if more {
+			count += "+"
+		}
+		node.SetText(fmt.Sprintf("Partitions (%s)", count))
+		node.ClearChildren()
+		b.addPartitionPage(node, partitions, 0)
+		node.GetReference().(*SchemaTreeNode).Loaded = true
+		node.SetExpanded(true)
+	})
+	return nil
+}
+
+// addPartitionPage adds the page of partitions starting at offset under
+// node, followed by a node that adds the next page
+func (b *Browser) addPartitionPage(node *tview.TreeNode, partitions []Partition, offset int) {
+	ref := node.GetReference().(*SchemaTreeNode)
+	end := min(offset+partitionPageSize, len(partitions))
+	for i := offset; i < end; i++ {
+		p := partitions[i]
+		text := p.Values
+		if text == "" {
+			text = "(unpartitioned)"
+		}
+		if p.RowCount != nil {
+			text += fmt.Sprintf(" (%s rows)", formatCount(*p.RowCount))
+		}
+		node.AddChild(tview.NewTreeNode(text).
+			SetReference(&SchemaTreeNode{
+				Type:    "partition",
+				Name:    p.Values,
+				Catalog: ref.Catalog,
+				Schema:  ref.Schema,
+				Table:   ref.Table,
+				Offset:  i,
+			}).
+			SetSelectable(true).
+			SetColor(b.palette.Column))
+	}
+	if end < len(partitions) {
+		node.AddChild(tview.NewTreeNode(fmt.Sprintf("... %d more (Enter shows the next %d)",
+			len(partitions)-end, min(partitionPageSize, len(partitions)-end))).
+			SetReference(&SchemaTreeNode{
+				Type:    "more_partitions",
+				Catalog: ref.Catalog,
+				Schema:  ref.Schema,
+				Table:   ref.Table,
+				Offset:  end,
+			}).
+			SetSelectable(true).
+			SetColor(b.palette.Schema))
+	}
+}
+
+// showMorePartitions replaces a "more" node with the next page of
+// partitions
+func (b *Browser) showMorePartitions(more *tview.TreeNode) {
+	ref := more.GetReference().(*SchemaTreeNode)
+	partitions := b.cache.GetPartitions(ref.Catalog, ref.Schema, ref.Table)
+	var parent *tview.TreeNode
+	b.treeView.GetRoot().Walk(func(node, p *tview.TreeNode) bool {
+		if node == more {
+			parent = p
+		}
+		return parent == nil
+	})
+	if parent == nil || partitions == nil {
+		// The cache expired; collapse so the next expansion reloads
+		if parent != nil {
+			parent.ClearChildren()
+			parent.GetReference().(*SchemaTreeNode).Loaded = false
+		}
+		return
+	}
+
+	parent.RemoveChild(more)
+	b.addPartitionPage(parent, partitions, ref.Offset)
+	if children := parent.GetChildren(); ref.Offset < len(children) {
+		b.treeView.SetCurrentNode(children[ref.Offset])
+	}
+}
+
+// partitionInfo describes a partition for the info pane
+func (b *Browser) partitionInfo(ref *SchemaTreeNode) string {
+	text := fmt.Sprintf("[green]Partition:[-] %s\n[green]Table:[-] %s.%s.%s",
+		tview.Escape(ref.Name), tview.Escape(ref.Catalog), tview.Escape(ref.Schema), tview.Escape(ref.Table))
+	partitions := b.cache.GetPartitions(ref.Catalog, ref.Schema, ref.Table)
+	if ref.Offset >= len(partitions) {
+		return text
+	}
+	p := partitions[ref.Offset]
+	if p.RowCount == nil && p.Size == nil && p.Files == nil {
+		return text
+	}
+	return text + fmt.Sprintf("\n[green]Rows:[-] %s  [green]Size:[-] %s  [green]Files:[-] %s",
+		formatStat(p.RowCount, formatCount), formatStat(p.Size, formatSize), formatStat(p.Files, formatCount))
+}
+
+// loadPartitionsInBackground starts LoadPartitions for a partitions node,
+// cancelling a load of it that is running when restart is set
+func (b *Browser) loadPartitionsInBackground(ref *SchemaTreeNode, node *tview.TreeNode, restart bool) {
+	go func() {
+		err := b.runLoad(b.ctx, node, restart, func(ctx context.Context) error {
+			return b.LoadPartitions(ctx, ref.Catalog, ref.Schema, ref.Table, node)
+		})
+		if err != nil && !errors.Is(err, context.Canceled) {
+			b.logger.Debug("Failed to load partitions", zap.Error(err),
+				zap.String("catalog", ref.Catalog),
+				zap.String("schema", ref.Schema),
+				zap.String("table", ref.Table))
+		}
+	}()
+}
@@ -0,0 +1,111 @@
+package schema
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/rivo/tview"
+	"go.uber.org/zap/zaptest"
+)
+
+// TestQueryPartitions tests reading the $partitions layouts of Hive and
+// Iceberg
+func TestQueryPartitions(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM hive.sales."orders$partitions" LIMIT 10001`)).
+		WillReturnRows(sqlmock.NewRows([]string{"ds", "region"}).
+			AddRow("2024-01-01", "eu").
+			AddRow("2024-01-02", nil))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM iceberg.sales."events$partitions" LIMIT 10001`)).
+		WillReturnRows(sqlmock.NewRows([]string{"partition", "record_count", "file_count", "total_size", "data"}).
+			AddRow("2024-01-01", int64(1200), int64(3), int64(4096), "{}"))
+
+	browser := &Browser{dbPool: db, logger: zaptest.NewLogger(t)}
+	hive, more, err := browser.QueryPartitions(context.Background(), "hive", "sales", "orders")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if more || len(hive) != 2 || hive[0].Values != "ds=2024-01-01/region=eu" || hive[1].Values != "ds=2024-01-02/region=NULL" {
+		t.Errorf("hive partitions = %+v, more = %v", hive, more)
+	}
+	if hive[0].RowCount != nil {
+		t.Error("hive partitions should have no row count")
+	}
+
+	iceberg, _, err := browser.QueryPartitions(context.Background(), "iceberg", "sales", "events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(iceberg) != 1 || iceberg[0].Values != "2024-01-01" {
+		t.Fatalf("iceberg partitions = %+v", iceberg)
+	}
+	p := iceberg[0]
+	if p.RowCount == nil || *p.RowCount != 1200 || p.Files == nil || *p.Files != 3 || p.Size == nil || *p.Size != 4096 {
+		t.Errorf("iceberg counts = %v rows, %v files, %v bytes", p.RowCount, p.Files, p.Size)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+
+	if got := formatPartitionValue([]any{"2024-01-01", int64(7)}); got != "2024-01-01, 7" {
+		t.Errorf("row value = %q", got)
+	}
+}
+
+// TestPartitionPaging tests that partitions are added a page at a time
+func TestPartitionPaging(t *testing.T) {
+	browser := &Browser{
+		tree:     NewSchemaTree(),
+		cache:    NewSchemaCache(),
+		treeView: tview.NewTreeView(),
+		infoText: tview.NewTextView().SetDynamicColors(true),
+		logger:   zaptest.NewLogger(t),
+	}
+	partitions := make([]Partition, partitionPageSize+30)
+	for i := range partitions {
+		partitions[i].Values = fmt.Sprintf("ds=%d", i)
+	}
+	browser.tree.Partitions["hive"] = map[string]map[string][]Partition{"sales": {"orders": partitions}}
+	browser.cache.Update(browser.tree, time.Minute)
+
+	root := tview.NewTreeNode("root")
+	node := browser.partitionsNode("hive", "sales", "orders")
+	root.AddChild(node)
+	browser.treeView.SetRoot(root)
+
+	browser.addPartitionPage(node, partitions, 0)
+	children := node.GetChildren()
+	if len(children) != partitionPageSize+1 {
+		t.Fatalf("first page has %d nodes, want %d and a more node", len(children), partitionPageSize)
+	}
+	more := children[partitionPageSize]
+	if more.GetReference().(*SchemaTreeNode).Type != "more_partitions" {
+		t.Fatal("the last node should show more partitions")
+	}
+
+	browser.toggleNode(more)
+	children = node.GetChildren()
+	if len(children) != len(partitions) {
+		t.Fatalf("after paging there are %d nodes, want %d", len(children), len(partitions))
+	}
+	if got := children[len(children)-1].GetText(); got != "ds=129" {
+		t.Errorf("last partition = %q", got)
+	}
+	if browser.treeView.GetCurrentNode() != children[partitionPageSize] {
+		t.Error("the cursor should move to the first partition of the new page")
+	}
+
+	browser.nodeChanged(children[3])
+	if text := browser.infoText.GetText(true); !regexp.MustCompile(`Partition: ds=3\b`).MatchString(text) {
+		t.Errorf("info = %q", text)
+	}
+}
@@ -0,0 +1,93 @@
+package schema
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/rivo/tview"
+	"go.uber.org/zap"
+)
+
+// prefetchWorkers is how many schemas' tables are read at once after a
+// catalog is expanded
+const prefetchWorkers = 4
+
+// prefetchLimit caps how many schemas of a catalog are read ahead, so
+// expanding a catalog with thousands of schemas does not flood the server
+const prefetchLimit = 100
+
+// prefetchTables reads the tables of a catalog's schemas in the background,
+// so that expanding a schema is served from the cache. Collapsing or
+// refreshing the catalog's node stops it.
+func (b *Browser) prefetchTables(ctx context.Context, catalog string, node *tview.TreeNode) {
+	schemas := b.cache.GetSchemas(catalog)
+	if len(schemas) == 0 {
+		return
+	}
+	b.inBackground(ctx, node, func(ctx context.Context) {
+		b.prefetch(ctx, catalog, schemas)
+	})
+}
+
+// prefetch reads the tables of schemas with a bounded pool of workers. It
+// skips the hidden schemas, those already cached, and those the store
+// holds, which are warmed when they are expanded.
+func (b *Browser) prefetch(ctx context.Context, catalog string, schemas []string) {
+	var pending []string
+	for _, schema := range schemas {
+		if len(pending) == prefetchLimit {
+			break
+		}
+		if b.hides(catalog, schema) || b.cache.GetTables(catalog, schema) != nil {
+			continue
+		}
+		if b.useStore(ctx) {
+			if _, ok, err := b.store.GetSchemaUpdate(catalog, schema); err == nil && ok {
+				continue
+			}
+		}
+		pending = append(pending, schema)
+	}
+
+	jobs := make(chan string)
+	var wg sync.WaitGroup
+	for range min(prefetchWorkers, len(pending)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for schema := range jobs {
+				b.prefetchSchema(ctx, catalog, schema)
+			}
+		}()
+	}
+	for _, schema := range pending {
+		select {
+		case jobs <- schema:
+		case <-ctx.Done():
+		}
+	}
+	close(jobs)
+	wg.Wait()
+}
+
+// prefetchSchema reads one schema's tables into the cache, unless the user
+// expanded it first
+func (b *Browser) prefetchSchema(ctx context.Context, catalog, schema string) {
+	if ctx.Err() != nil || b.cache.GetTables(catalog, schema) != nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	tables, views, comments, err := b.readTables(ctx, catalog, schema)
+	if err != nil {
+		b.logger.Debug("Failed to prefetch tables", zap.Error(err),
+			zap.String("catalog", catalog),
+			zap.String("schema", schema))
+		return
+	}
+	if b.cache.GetTables(catalog, schema) == nil {
+		b.putTables(catalog, schema, tables, views, comments)
+	}
+}
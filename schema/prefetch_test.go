@@ -0,0 +1,56 @@
+package schema
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"go.uber.org/zap/zaptest"
+)
+
+// TestPrefetch tests reading the tables of a catalog's schemas ahead of use
+func TestPrefetch(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer db.Close()
+	mock.MatchExpectationsInOrder(false)
+
+	for _, schema := range []string{"sales", "web"} {
+		mock.ExpectQuery("SHOW TABLES FROM hive." + schema).
+			WillReturnRows(sqlmock.NewRows([]string{"Table"}).AddRow("orders").AddRow("customers"))
+		mock.ExpectQuery("information_schema.views WHERE table_schema = '" + schema + "'").
+			WillReturnRows(sqlmock.NewRows([]string{"table_name", "view_definition"}))
+		mock.ExpectQuery("materialized_views WHERE catalog_name = 'hive' AND schema_name = '" + schema + "'").
+			WillReturnRows(sqlmock.NewRows([]string{"name", "definition"}))
+		mock.ExpectQuery("table_comments WHERE catalog_name = 'hive' AND schema_name = '" + schema + "'").
+			WillReturnRows(sqlmock.NewRows([]string{"schema_name", "table_name", "comment"}).AddRow(schema, "orders", "One row per order"))
+	}
+
+	browser := &Browser{
+		tree:   NewSchemaTree(),
+		cache:  NewSchemaCache(),
+		dbPool: db,
+		logger: zaptest.NewLogger(t),
+	}
+	// finance is cached already, and information_schema is hidden
+	browser.putTables("hive", "finance", []string{"ledger"}, nil, nil)
+
+	browser.prefetch(context.Background(), "hive", []string{"finance", "information_schema", "sales", "web"})
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+	for _, schema := range []string{"sales", "web"} {
+		if got := browser.cache.GetTables("hive", schema); !slices.Equal(got, []string{"customers", "orders"}) {
+			t.Errorf("prefetched tables of %s = %q", schema, got)
+		}
+		if got := browser.commentOf("hive", schema, "orders"); got != "One row per order" {
+			t.Errorf("prefetched comment of %s.orders = %q", schema, got)
+		}
+	}
+	if got := browser.cache.GetTables("hive", "finance"); !slices.Equal(got, []string{"ledger"}) {
+		t.Errorf("tables of finance = %q, want them kept", got)
+	}
+}
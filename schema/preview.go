@@ -0,0 +1,172 @@
+package schema
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"go.uber.org/zap"
+)
+
+// previewLimit caps the rows a preview fetches
+const previewLimit = 100
+
+// previewKey handles the tree's preview shortcuts: p previews the current
+// table's rows, and Tab moves into a shown preview to scroll it
+func (b *Browser) previewKey(event *tcell.EventKey) *tcell.EventKey {
+	node := b.treeView.GetCurrentNode()
+	if node == nil {
+		return event
+	}
+	switch {
+	case event.Key() == tcell.KeyRune && event.Rune() == 'p' && event.Modifiers()&(tcell.ModAlt|tcell.ModCtrl) == 0:
+		if ref, ok := node.GetReference().(*SchemaTreeNode); ok && ref.Type == "table" {
+			b.showPreview(node, ref)
+			return nil
+		}
+	case event.Key() == tcell.KeyTab && b.previewed && b.app != nil:
+		b.app.SetFocus(b.preview)
+		return nil
+	}
+	return event
+}
+
+// Preview returns the column names and the first rows of a table, with
+// values formatted for display
+func (b *Browser) Preview(ctx context.Context, catalog, schema, table string) ([]string, [][]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	query := fmt.Sprintf("SELECT * FROM %s LIMIT %d", QualifiedName(catalog, schema, table), previewLimit)
+	rows, err := b.dbPool.QueryContext(ctx, query)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to query preview: %w", err)
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read preview columns: %w", err)
+	}
+	var data [][]string
+	for rows.Next() {
+		values := make([]any, len(columns))
+		pointers := make([]any, len(columns))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+		if err := rows.Scan(pointers...); err != nil {
+			return nil, nil, fmt.Errorf("failed to scan preview row: %w", err)
+		}
+		row := make([]string, len(values))
+		for i, value := range values {
+			row[i] = formatPreviewValue(value)
+		}
+		data = append(data, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("error iterating preview rows: %w", err)
+	}
+	return columns, data, nil
+}
+
+// formatPreviewValue renders a scanned value, with NULL for nil
+func formatPreviewValue(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return "NULL"
+	case []byte:
+		return string(v)
+	case time.Time:
+		return v.Format("2006-01-02 15:04:05.000")
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// showPreview fetches a table's first rows in the background and shows them
+// in place of the info pane, unless the user has moved on by then
+func (b *Browser) showPreview(node *tview.TreeNode, ref *SchemaTreeNode) {
+	b.infoText.SetText(fmt.Sprintf("[green]Table:[-] %s\n\nLoading preview...", tview.Escape(ref.Table)))
+	go func() {
+		columns, rows, err := b.Preview(b.ctx, ref.Catalog, ref.Schema, ref.Table)
+		if err != nil {
+			b.logger.Error("Failed to preview table", zap.Error(err),
+				zap.String("catalog", ref.Catalog),
+				zap.String("schema", ref.Schema),
+				zap.String("table", ref.Table))
+		}
+		b.app.QueueUpdateDraw(func() {
+			if b.treeView.GetCurrentNode() != node {
+				return
+			}
+			if err != nil {
+				b.infoText.SetText(fmt.Sprintf("[red]Error loading preview: %v[-]", tview.Escape(err.Error())))
+				return
+			}
+			b.renderPreview(ref.Table, columns, rows)
+		})
+	}()
+}
+
+// renderPreview fills the preview table and shows it in place of the info
+// pane
+func (b *Browser) renderPreview(table string, columns []string, rows [][]string) {
+	b.hideDDL()
+	if b.preview == nil {
+		b.preview = tview.NewTable().
+			SetFixed(1, 0).
+			SetSelectable(true, false)
+		b.preview.SetBorder(true).SetTitleAlign(tview.AlignLeft)
+		b.preview.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+			if (event.Key() == tcell.KeyTab || event.Key() == tcell.KeyEscape) && b.app != nil {
+				b.app.SetFocus(b.treeView)
+				return nil
+			}
+			return event
+		})
+	}
+
+	b.preview.Clear()
+	for col, name := range columns {
+		b.preview.SetCell(0, col, tview.NewTableCell(tview.Escape(name)).
+			SetTextColor(b.palette.Title).
+			SetAttributes(tcell.AttrBold).
+			SetSelectable(false))
+	}
+	for r, row := range rows {
+		for col, value := range row {
+			cell := tview.NewTableCell(tview.Escape(value)).SetMaxWidth(40)
+			if value == "NULL" {
+				cell.SetTextColor(tcell.ColorGray)
+			}
+			b.preview.SetCell(r+1, col, cell)
+		}
+	}
+	b.preview.ScrollToBeginning()
+	if len(rows) > 0 {
+		b.preview.Select(1, 0)
+	}
+
+	title := fmt.Sprintf(" %s: %d rows (Tab to scroll) ", table, len(rows))
+	if len(rows) == previewLimit {
+		title = fmt.Sprintf(" %s: first %d rows (Tab to scroll) ", table, previewLimit)
+	}
+	b.preview.SetTitle(tview.Escape(title)).SetTitleColor(b.palette.InfoTitle)
+
+	if !b.previewed {
+		b.previewed = true
+		b.placeInfo(b.preview, true)
+	}
+}
+
+// hidePreview puts the info pane back in place of a shown preview
+func (b *Browser) hidePreview() {
+	if !b.previewed {
+		return
+	}
+	b.previewed = false
+	b.placeInfo(b.infoText, false)
+}
@@ -0,0 +1,73 @@
+package schema
+
+import (
+	"context"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/rivo/tview"
+	"go.uber.org/zap/zaptest"
+)
+
+// TestPreview tests fetching and formatting a table's first rows
+func TestPreview(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM hive.sales."Orders" LIMIT 100`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "note"}).
+			AddRow(int64(1), []byte("first")).
+			AddRow(int64(2), nil))
+
+	browser := &Browser{dbPool: db, logger: zaptest.NewLogger(t)}
+	columns, rows, err := browser.Preview(context.Background(), "hive", "sales", "Orders")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"id", "note"}; !reflect.DeepEqual(columns, want) {
+		t.Errorf("columns = %q, want %q", columns, want)
+	}
+	if want := [][]string{{"1", "first"}, {"2", "NULL"}}; !reflect.DeepEqual(rows, want) {
+		t.Errorf("rows = %q, want %q", rows, want)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+// TestPreviewReplacesInfoPane tests that a preview takes the info pane's
+// place until the tree moves on
+func TestPreviewReplacesInfoPane(t *testing.T) {
+	browser := &Browser{
+		cache:      NewSchemaCache(),
+		treeView:   tview.NewTreeView(),
+		infoText:   tview.NewTextView(),
+		logger:     zaptest.NewLogger(t),
+		infoHeight: embeddedInfoHeight,
+		infoWeight: 1,
+	}
+	browser.pane = tview.NewFlex().
+		AddItem(browser.treeView, 0, 1, true).
+		AddItem(browser.infoText, embeddedInfoHeight, 0, false)
+
+	browser.renderPreview("orders", []string{"id"}, [][]string{{"1"}, {"2"}})
+	if browser.pane.GetItemCount() != 2 || browser.pane.GetItem(1) != browser.preview {
+		t.Fatal("the preview did not replace the info pane")
+	}
+	if got := browser.preview.GetCell(2, 0).Text; got != "2" {
+		t.Errorf("second row = %q, want 2", got)
+	}
+
+	tableNode := tview.NewTreeNode("orders").SetReference(&SchemaTreeNode{
+		Type: "table", Name: "orders", Catalog: "hive", Schema: "sales", Table: "orders",
+	})
+	browser.nodeChanged(tableNode)
+	if browser.pane.GetItemCount() != 2 || browser.pane.GetItem(1) != browser.infoText {
+		t.Error("the info pane did not come back")
+	}
+}
@@ -0,0 +1,339 @@
+package schema
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"go.uber.org/zap"
+)
+
+// TableProperty is a property of a table's storage or layout, such as its
+// file format, location or partitioning
+type TableProperty struct {
+	Name  string
+	Value string
+}
+
+// storageProperties and layoutProperties are shown in their own sections of
+// the details, in this order; the rest follow in the order they were read
+var (
+	storageProperties = []string{"format", "file_format", "format_version", "location", "external_location", "storage_format", "compression_codec", "transactional"}
+	layoutProperties  = []string{"partitioned_by", "partitioning", "bucketed_by", "bucket_count", "bucketing_version", "sorted_by", "sorted_columns"}
+)
+
+// GetProperties returns a table's properties from the cache
+func (sc *SchemaCache) GetProperties(catalog, schema, table string) []TableProperty {
+	defer sc.rlock()()
+	if sc.Data == nil || time.Now().After(sc.Expiry) {
+		return nil
+	}
+	return sc.Data.Properties[catalog][schema][table]
+}
+
+// ShowProperties returns a table's properties, from the cache when it has
+// them. They are read from the WITH clause of SHOW CREATE TABLE, which every
+// connector writes, and from the table's $properties table, which Hive,
+// Iceberg and Delta Lake expose with more detail.
+func (b *Browser) ShowProperties(ctx context.Context, catalog, schema, table string) ([]TableProperty, error) {
+	if properties := b.cache.GetProperties(catalog, schema, table); properties != nil {
+		return properties, nil
+	}
+
+	ddl, err := b.ShowCreate(ctx, catalog, schema, table)
+	if err != nil {
+		return nil, err
+	}
+	properties := ParseTableProperties(ddl)
+	seen := make(map[string]bool, len(properties))
+	for _, p := range properties {
+		seen[p.Name] = true
+	}
+	for _, p := range b.propertiesTable(ctx, catalog, schema, table) {
+		if !seen[p.Name] {
+			seen[p.Name] = true
+			properties = append(properties, p)
+		}
+	}
+	if properties == nil {
+		properties = []TableProperty{}
+	}
+
+	b.tree.mu.Lock()
+	if _, ok := b.tree.Properties[catalog]; !ok {
+		b.tree.Properties[catalog] = make(map[string]map[string][]TableProperty)
+	}
+	if _, ok := b.tree.Properties[catalog][schema]; !ok {
+		b.tree.Properties[catalog][schema] = make(map[string][]TableProperty)
+	}
+	b.tree.Properties[catalog][schema][table] = properties
+	b.tree.mu.Unlock()
+
+	b.updateCache()
+	return properties, nil
+}
+
+// propertiesTable reads a table's $properties table, which connectors
+// shape either as key and value rows or as one row with a column per
+// property. Tables without one have no properties here.
+func (b *Browser) propertiesTable(ctx context.Context, catalog, schema, table string) []TableProperty {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	rows, err := b.dbPool.QueryContext(ctx, "SELECT * FROM "+QualifiedName(catalog, schema, table+"$properties"))
+	if err != nil {
+		b.logger.Debug("No $properties table", zap.Error(err),
+			zap.String("catalog", catalog),
+			zap.String("schema", schema),
+			zap.String("table", table))
+		return nil
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil
+	}
+	pairs := len(columns) == 2 && strings.EqualFold(columns[0], "key") && strings.EqualFold(columns[1], "value")
+
+	var properties []TableProperty
+	values := make([]sql.NullString, len(columns))
+	dest := make([]any, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return properties
+		}
+		if pairs {
+			properties = append(properties, TableProperty{Name: values[0].String, Value: values[1].String})
+			continue
+		}
+		for i, column := range columns {
+			properties = append(properties, TableProperty{Name: column, Value: values[i].String})
+		}
+		break
+	}
+	return properties
+}
+
+// ParseTableProperties returns the properties in the WITH clause of a
+// CREATE TABLE or CREATE MATERIALIZED VIEW statement, in order. String
+// values are unquoted and arrays listed with commas.
+func ParseTableProperties(ddl string) []TableProperty {
+	upper := strings.ToUpper(strings.TrimSpace(ddl))
+	if !strings.HasPrefix(upper, "CREATE TABLE") && !strings.HasPrefix(upper, "CREATE MATERIALIZED VIEW") &&
+		!strings.HasPrefix(upper, "CREATE OR REPLACE MATERIALIZED VIEW") {
+		return nil
+	}
+
+	// Find WITH ( outside quotes and parentheses, before any AS query
+	depth := 0
+	start := -1
+	for i := 0; i < len(ddl) && start < 0; i++ {
+		switch c := ddl[i]; {
+		case c == '\'' || c == '"':
+			i = skipQuoted(ddl, i)
+		case c == '(' || c == '[':
+			depth++
+		case c == ')' || c == ']':
+			depth--
+		case depth == 0 && isWord(ddl, i, "AS"):
+			return nil
+		case depth == 0 && isWord(ddl, i, "WITH"):
+			rest := strings.TrimLeft(ddl[i+4:], " \t\r\n")
+			if strings.HasPrefix(rest, "(") {
+				start = len(ddl) - len(rest) + 1
+			}
+		}
+	}
+	if start < 0 {
+		return nil
+	}
+
+	var properties []TableProperty
+	depth = 0
+	from := start
+	add := func(end int) {
+		name, value, ok := strings.Cut(ddl[from:end], "=")
+		if !ok {
+			return
+		}
+		properties = append(properties, TableProperty{
+			Name:  unquoteName(strings.TrimSpace(name)),
+			Value: propertyValue(strings.TrimSpace(value)),
+		})
+	}
+	for i := start; i < len(ddl); i++ {
+		switch c := ddl[i]; c {
+		case '\'', '"':
+			i = skipQuoted(ddl, i)
+		case '(', '[':
+			depth++
+		case ']':
+			depth--
+		case ')':
+			if depth == 0 {
+				add(i)
+				return properties
+			}
+			depth--
+		case ',':
+			if depth == 0 {
+				add(i)
+				from = i + 1
+			}
+		}
+	}
+	return properties
+}
+
+// skipQuoted returns the index of the quote closing the string or
+// identifier that starts at i, skipping doubled quotes
+func skipQuoted(s string, i int) int {
+	quote := s[i]
+	for j := i + 1; j < len(s); j++ {
+		if s[j] == quote {
+			if j+1 < len(s) && s[j+1] == quote {
+				j++
+				continue
+			}
+			return j
+		}
+	}
+	return len(s)
+}
+
+// isWord reports whether the keyword word starts at i as a whole word,
+// ignoring case
+func isWord(s string, i int, word string) bool {
+	if i+len(word) > len(s) || !strings.EqualFold(s[i:i+len(word)], word) {
+		return false
+	}
+	identChar := func(c byte) bool {
+		return c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+	}
+	return (i == 0 || !identChar(s[i-1])) && (i+len(word) == len(s) || !identChar(s[i+len(word)]))
+}
+
+// unquoteName strips the double quotes from a property name
+func unquoteName(name string) string {
+	if len(name) >= 2 && name[0] == '"' && name[len(name)-1] == '"' {
+		return strings.ReplaceAll(name[1:len(name)-1], `""`, `"`)
+	}
+	return name
+}
+
+// propertyValue formats a property's SQL value for reading: strings
+// unquoted, and arrays as their elements joined by commas
+func propertyValue(value string) string {
+	if len(value) >= 2 && value[0] == '\'' && value[len(value)-1] == '\'' {
+		return strings.ReplaceAll(value[1:len(value)-1], "''", "'")
+	}
+	if upper := strings.ToUpper(value); strings.HasPrefix(upper, "ARRAY[") && strings.HasSuffix(value, "]") {
+		var elements []string
+		inner := value[len("ARRAY[") : len(value)-1]
+		from := 0
+		for i := 0; i < len(inner); i++ {
+			switch inner[i] {
+			case '\'', '"':
+				i = skipQuoted(inner, i)
+			case ',':
+				elements = append(elements, propertyValue(strings.TrimSpace(inner[from:i])))
+				from = i + 1
+			}
+		}
+		if rest := strings.TrimSpace(inner[from:]); rest != "" {
+			elements = append(elements, propertyValue(rest))
+		}
+		return strings.Join(elements, ", ")
+	}
+	return value
+}
+
+// propertiesKey handles i, which shows the current table's properties
+func (b *Browser) propertiesKey(event *tcell.EventKey) *tcell.EventKey {
+	if event.Key() != tcell.KeyRune || event.Rune() != 'i' || event.Modifiers()&(tcell.ModAlt|tcell.ModCtrl) != 0 {
+		return event
+	}
+	node := b.treeView.GetCurrentNode()
+	if node == nil {
+		return event
+	}
+	if ref, ok := node.GetReference().(*SchemaTreeNode); ok && ref.Type == "table" {
+		b.hideDDL()
+		b.hidePreview()
+		b.loadProperties(node, ref)
+		return nil
+	}
+	return event
+}
+
+// loadProperties fetches a table's properties in the background and shows
+// them in the grown info pane, unless the user has moved on by then
+func (b *Browser) loadProperties(node *tview.TreeNode, ref *SchemaTreeNode) {
+	b.infoText.SetText(fmt.Sprintf("[green]Table:[-] %s\n\nLoading properties...", tview.Escape(ref.Table)))
+	go func() {
+		properties, err := b.ShowProperties(b.ctx, ref.Catalog, ref.Schema, ref.Table)
+		if err != nil {
+			b.logger.Error("Failed to load table properties", zap.Error(err),
+				zap.String("catalog", ref.Catalog),
+				zap.String("schema", ref.Schema),
+				zap.String("table", ref.Table))
+		}
+		b.app.QueueUpdateDraw(func() {
+			if b.treeView.GetCurrentNode() != node {
+				return
+			}
+			if err != nil {
+				b.infoText.SetText(fmt.Sprintf("[red]Error loading properties: %v[-]", tview.Escape(err.Error())))
+				return
+			}
+			b.placeInfo(b.infoText, true)
+			b.infoText.SetText(propertiesInfo(ref, properties))
+			b.infoText.ScrollToBeginning()
+		})
+	}()
+}
+
+// propertiesInfo describes a table's properties for the info pane: storage
+// first, then partitioning and bucketing, then the rest
+func propertiesInfo(ref *SchemaTreeNode, properties []TableProperty) string {
+	var text strings.Builder
+	fmt.Fprintf(&text, "[green]Details of:[-] %s", tview.Escape(QualifiedName(ref.Catalog, ref.Schema, ref.Table)))
+	if len(properties) == 0 {
+		text.WriteString("\n\nThe connector reports no properties for this table.")
+		return text.String()
+	}
+
+	byName := make(map[string]string, len(properties))
+	for _, p := range properties {
+		byName[p.Name] = p.Value
+	}
+	shown := make(map[string]bool, len(properties))
+	section := func(title string, names []string) {
+		var lines []string
+		for _, name := range names {
+			if value, ok := byName[name]; ok && !shown[name] {
+				shown[name] = true
+				lines = append(lines, fmt.Sprintf("  [yellow]%s:[-] %s", tview.Escape(name), tview.Escape(value)))
+			}
+		}
+		if len(lines) > 0 {
+			fmt.Fprintf(&text, "\n\n[green]%s[-]\n%s", title, strings.Join(lines, "\n"))
+		}
+	}
+	section("Storage", storageProperties)
+	section("Layout", layoutProperties)
+	var rest []string
+	for _, p := range properties {
+		rest = append(rest, p.Name)
+	}
+	section("Properties", rest)
+	return text.String()
+}
@@ -0,0 +1,100 @@
+package schema
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"go.uber.org/zap/zaptest"
+)
+
+// hiveDDL is SHOW CREATE TABLE output of a partitioned, bucketed Hive table
+const hiveDDL = `CREATE TABLE hive.sales.orders (
+   id bigint,
+   "with" varchar COMMENT 'a column, named WITH (sic)',
+   ds varchar
+)
+COMMENT 'Orders, one row each'
+WITH (
+   bucket_count = 8,
+   bucketed_by = ARRAY['id'],
+   external_location = 's3://lake/sales/orders',
+   format = 'ORC',
+   partitioned_by = ARRAY['ds','region'],
+   "orc.bloom_filter_columns" = ARRAY['it''s']
+)`
+
+// TestParseTableProperties tests reading the WITH clause of DDL
+func TestParseTableProperties(t *testing.T) {
+	want := []TableProperty{
+		{Name: "bucket_count", Value: "8"},
+		{Name: "bucketed_by", Value: "id"},
+		{Name: "external_location", Value: "s3://lake/sales/orders"},
+		{Name: "format", Value: "ORC"},
+		{Name: "partitioned_by", Value: "ds, region"},
+		{Name: "orc.bloom_filter_columns", Value: "it's"},
+	}
+	if got := ParseTableProperties(hiveDDL); !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseTableProperties = %+v\nwant %+v", got, want)
+	}
+
+	for _, ddl := range []string{
+		"CREATE TABLE memory.default.t (\n   id bigint\n)",
+		"CREATE VIEW hive.sales.v SECURITY DEFINER AS\nWITH x (a) AS (SELECT 1) SELECT a FROM x",
+	} {
+		if got := ParseTableProperties(ddl); got != nil {
+			t.Errorf("ParseTableProperties(%q) = %+v, want none", ddl, got)
+		}
+	}
+}
+
+// TestShowProperties tests merging the DDL's properties with $properties
+func TestShowProperties(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery("SHOW CREATE TABLE hive.sales.orders").
+		WillReturnRows(sqlmock.NewRows([]string{"Create Table"}).AddRow(hiveDDL))
+	mock.ExpectQuery(`SELECT \* FROM hive.sales."orders\$properties"`).
+		WillReturnRows(sqlmock.NewRows([]string{"key", "value"}).
+			AddRow("format", "orc").
+			AddRow("write.target-file-size-bytes", "134217728"))
+
+	browser := &Browser{
+		tree:   NewSchemaTree(),
+		cache:  NewSchemaCache(),
+		dbPool: db,
+		logger: zaptest.NewLogger(t),
+	}
+	properties, err := browser.ShowProperties(context.Background(), "hive", "sales", "orders")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(properties) != 7 || properties[3] != (TableProperty{Name: "format", Value: "ORC"}) ||
+		properties[6] != (TableProperty{Name: "write.target-file-size-bytes", Value: "134217728"}) {
+		t.Errorf("properties = %+v", properties)
+	}
+
+	// The second call is served from the cache
+	if cached, err := browser.ShowProperties(context.Background(), "hive", "sales", "orders"); err != nil || len(cached) != 7 {
+		t.Errorf("cached properties = %+v, %v", cached, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+
+	info := propertiesInfo(&SchemaTreeNode{Type: "table", Catalog: "hive", Schema: "sales", Table: "orders"}, properties)
+	storage, layout, rest := strings.Index(info, "Storage"), strings.Index(info, "Layout"), strings.Index(info, "[green]Properties")
+	if storage < 0 || layout < storage || rest < layout {
+		t.Fatalf("info lacks its sections in order:\n%s", info)
+	}
+	if !strings.Contains(info[storage:layout], "s3://lake/sales/orders") || !strings.Contains(info[layout:rest], "ds, region") ||
+		!strings.Contains(info[rest:], "write.target-file-size-bytes") {
+		t.Errorf("properties in the wrong sections:\n%s", info)
+	}
+}
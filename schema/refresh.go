@@ -0,0 +1,216 @@
+package schema
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"go.uber.org/zap"
+)
+
+// defaultCacheTTL is how long loaded metadata is reused unless SetCacheTTL
+// changes it
+const defaultCacheTTL = 5 * time.Minute
+
+// ParseCacheTTL parses ui.schema_cache_ttl, falling back to the default
+func ParseCacheTTL(setting string) (time.Duration, error) {
+	if strings.TrimSpace(setting) == "" {
+		return defaultCacheTTL, nil
+	}
+	ttl, err := time.ParseDuration(strings.TrimSpace(setting))
+	if err != nil || ttl <= 0 {
+		return defaultCacheTTL, fmt.Errorf("invalid ui.schema_cache_ttl %q", setting)
+	}
+	return ttl, nil
+}
+
+// SetCacheTTL sets how long loaded metadata is reused before the browser
+// reads it from the server again
+func (b *Browser) SetCacheTTL(ttl time.Duration) {
+	b.ttl = ttl
+}
+
+// updateCache stores the tree in the cache for the configured TTL
+func (b *Browser) updateCache() {
+	ttl := b.ttl
+	if ttl <= 0 {
+		ttl = defaultCacheTTL
+	}
+	b.cache.Update(b.tree, ttl)
+}
+
+// Clear empties the cache
+func (sc *SchemaCache) Clear() {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	sc.Data = NewSchemaTree()
+	sc.Expiry = time.Now()
+}
+
+// forget drops what the tree knows under a catalog, schema or table, or
+// under every catalog when catalog is empty. A table keeps its place in its
+// schema, its view definition and its comment, which are loaded with the
+// schema.
+func (t *SchemaTree) forget(catalog, schema, table string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	switch {
+	case catalog == "":
+		fresh := NewSchemaTree()
+		t.Catalogs, t.Schemas, t.Tables, t.Columns = fresh.Catalogs, fresh.Schemas, fresh.Tables, fresh.Columns
+		t.Stats, t.Partitions, t.Views, t.Comments = fresh.Stats, fresh.Partitions, fresh.Views, fresh.Comments
+		t.Properties = fresh.Properties
+	case schema == "":
+		delete(t.Schemas, catalog)
+		delete(t.Tables, catalog)
+		delete(t.Columns, catalog)
+		delete(t.Stats, catalog)
+		delete(t.Partitions, catalog)
+		delete(t.Views, catalog)
+		delete(t.Comments, catalog)
+		delete(t.Properties, catalog)
+	case table == "":
+		delete(t.Tables[catalog], schema)
+		delete(t.Columns[catalog], schema)
+		delete(t.Stats[catalog], schema)
+		delete(t.Partitions[catalog], schema)
+		delete(t.Views[catalog], schema)
+		delete(t.Comments[catalog], schema)
+		delete(t.Properties[catalog], schema)
+	default:
+		delete(t.Columns[catalog][schema], table)
+		delete(t.Stats[catalog][schema], table)
+		delete(t.Partitions[catalog][schema], table)
+		delete(t.Properties[catalog][schema], table)
+	}
+}
+
+// refreshKey handles r and F5, which reload the current node's subtree, and
+// R, which reloads everything
+func (b *Browser) refreshKey(event *tcell.EventKey) *tcell.EventKey {
+	if event.Modifiers()&(tcell.ModAlt|tcell.ModCtrl) != 0 {
+		return event
+	}
+	switch {
+	case event.Key() == tcell.KeyRune && event.Rune() == 'R':
+		b.refreshAll()
+		return nil
+	case event.Key() == tcell.KeyF5, event.Key() == tcell.KeyRune && event.Rune() == 'r':
+		if node := b.treeView.GetCurrentNode(); node != nil {
+			b.refreshNode(node)
+			return nil
+		}
+	}
+	return event
+}
+
+// refreshNode forgets the metadata under node and reads it again. Columns
+// refresh their table, partitions their table's partition list, and
+// Favorites reads the bookmarks again.
+func (b *Browser) refreshNode(node *tview.TreeNode) {
+	ref, ok := node.GetReference().(*SchemaTreeNode)
+	if !ok {
+		b.refreshAll()
+		return
+	}
+	switch ref.Type {
+	case "column", "partition", "more_partitions":
+		if parent := b.parentOf(node); parent != nil {
+			b.refreshNode(parent)
+		}
+		return
+	case "favorites":
+		b.reloadFavorites()
+		return
+	}
+
+	b.hideDDL()
+	b.hidePreview()
+	b.placeInfo(b.infoText, false)
+	b.cancelLoads(node)
+	if ref.Type == "partitions" {
+		b.tree.mu.Lock()
+		delete(b.tree.Partitions[ref.Catalog][ref.Schema], ref.Table)
+		b.tree.mu.Unlock()
+	} else {
+		b.tree.forget(ref.Catalog, ref.Schema, ref.Table)
+	}
+	ref.Loaded = false
+	b.clearChildren(node)
+	node.SetExpanded(true)
+	b.infoText.SetText(fmt.Sprintf("Refreshing %s...", tview.Escape(node.GetText())))
+
+	if ref.Type == "partitions" {
+		b.loadPartitionsInBackground(ref, node, true)
+		return
+	}
+	go func() {
+		err := b.runLoad(withoutStore(b.ctx), node, true, func(ctx context.Context) error {
+			return b.loadChildren(ctx, node)
+		})
+		if errors.Is(err, context.Canceled) {
+			return
+		}
+		if err != nil {
+			b.logger.Error("Failed to refresh metadata", zap.Error(err),
+				zap.String("catalog", ref.Catalog),
+				zap.String("schema", ref.Schema),
+				zap.String("table", ref.Table))
+			return
+		}
+		b.app.QueueUpdateDraw(func() {
+			if b.treeView.GetCurrentNode() == node {
+				b.nodeChanged(node)
+			}
+		})
+	}()
+}
+
+// refreshAll clears the cache and reloads the catalogs; the rest of the
+// tree loads again as it is opened
+func (b *Browser) refreshAll() {
+	b.hideDDL()
+	b.hidePreview()
+	b.placeInfo(b.infoText, false)
+	b.cancelLoads(b.rootNode)
+	b.tree.forget("", "", "")
+	b.cache.Clear()
+	b.clearChildren(b.rootNode)
+	b.treeView.SetCurrentNode(b.rootNode)
+	b.infoText.SetText("Refreshing all metadata...")
+
+	go func() {
+		err := b.runLoad(withoutStore(b.ctx), b.rootNode, true, b.LoadCatalogs)
+		if errors.Is(err, context.Canceled) {
+			return
+		}
+		if err != nil {
+			b.logger.Error("Failed to load catalogs", zap.Error(err))
+			b.app.QueueUpdateDraw(func() {
+				b.infoText.SetText(fmt.Sprintf("[red]Error loading catalogs: %v[-]", tview.Escape(err.Error())))
+			})
+			return
+		}
+		b.app.QueueUpdateDraw(func() {
+			b.infoText.SetText("Metadata refreshed.")
+		})
+	}()
+}
+
+// parentOf returns the node above node in the tree
+func (b *Browser) parentOf(node *tview.TreeNode) *tview.TreeNode {
+	var found *tview.TreeNode
+	b.rootNode.Walk(func(n, parent *tview.TreeNode) bool {
+		if n == node {
+			found = parent
+			return false
+		}
+		return found == nil
+	})
+	return found
+}
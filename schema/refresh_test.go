@@ -0,0 +1,104 @@
+package schema
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rivo/tview"
+)
+
+// TestParseCacheTTL tests reading ui.schema_cache_ttl
+func TestParseCacheTTL(t *testing.T) {
+	tests := []struct {
+		setting string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"", defaultCacheTTL, false},
+		{" 30m ", 30 * time.Minute, false},
+		{"soon", defaultCacheTTL, true},
+		{"-1m", defaultCacheTTL, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseCacheTTL(tt.setting)
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("ParseCacheTTL(%q) = %v, %v; want %v, error %v", tt.setting, got, err, tt.want, tt.wantErr)
+		}
+	}
+
+	browser := &Browser{tree: NewSchemaTree(), cache: NewSchemaCache()}
+	browser.SetCacheTTL(time.Hour)
+	browser.updateCache()
+	if until := time.Until(browser.cache.Expiry); until < 59*time.Minute {
+		t.Errorf("cache expires in %v, want an hour", until)
+	}
+}
+
+// refreshTree returns a tree with two catalogs loaded down to the columns
+func refreshTree() *SchemaTree {
+	tree := NewSchemaTree()
+	for _, catalog := range []string{"hive", "iceberg"} {
+		tree.Catalogs[catalog] = true
+		tree.Schemas[catalog] = map[string]bool{"sales": true}
+		tree.Tables[catalog] = map[string]map[string]bool{"sales": {"orders": true, "customers": true}}
+		tree.Columns[catalog] = map[string]map[string][]Column{"sales": {
+			"orders":    {{Name: "id"}},
+			"customers": {{Name: "id"}},
+		}}
+		tree.Stats[catalog] = map[string]map[string]*TableStats{"sales": {"orders": {}}}
+		tree.Views[catalog] = map[string]map[string]View{"sales": {"orders": {}}}
+	}
+	return tree
+}
+
+// TestForget tests dropping a subtree's metadata and only that
+func TestForget(t *testing.T) {
+	tree := refreshTree()
+	tree.forget("hive", "sales", "orders")
+	if _, ok := tree.Columns["hive"]["sales"]["orders"]; ok {
+		t.Error("the table's columns are still known")
+	}
+	if tree.Stats["hive"]["sales"]["orders"] != nil {
+		t.Error("the table's stats are still known")
+	}
+	if !tree.Tables["hive"]["sales"]["orders"] || tree.Columns["hive"]["sales"]["customers"] == nil {
+		t.Error("refreshing a table forgot its siblings or its place in the schema")
+	}
+
+	tree.forget("hive", "sales", "")
+	if tree.Tables["hive"]["sales"] != nil || tree.Columns["hive"]["sales"] != nil || tree.Views["hive"]["sales"] != nil {
+		t.Error("the schema's tables are still known")
+	}
+	if !tree.Schemas["hive"]["sales"] {
+		t.Error("refreshing a schema forgot it in its catalog")
+	}
+
+	tree.forget("hive", "", "")
+	if tree.Schemas["hive"] != nil || !tree.Catalogs["hive"] {
+		t.Errorf("schemas = %v after refreshing the catalog", tree.Schemas)
+	}
+	if tree.Tables["iceberg"]["sales"] == nil {
+		t.Error("refreshing one catalog forgot another")
+	}
+
+	tree.forget("", "", "")
+	if len(tree.Catalogs) != 0 || len(tree.Columns) != 0 {
+		t.Errorf("a full refresh left %v", tree.Catalogs)
+	}
+}
+
+// TestParentOf tests finding the node a column hangs from
+func TestParentOf(t *testing.T) {
+	browser := &Browser{rootNode: tview.NewTreeNode("Trino Schema")}
+	table := tview.NewTreeNode("orders")
+	column := tview.NewTreeNode("id")
+	table.AddChild(column)
+	browser.rootNode.AddChild(tview.NewTreeNode("hive").AddChild(table))
+
+	if browser.parentOf(column) != table {
+		t.Error("the column's table was not found")
+	}
+	if browser.parentOf(tview.NewTreeNode("elsewhere")) != nil {
+		t.Error("found a parent for a node outside the tree")
+	}
+}
@@ -0,0 +1,307 @@
+package schema
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"go.uber.org/zap"
+)
+
+// searchResultLimit caps the results global search lists
+const searchResultLimit = 200
+
+// searchLevels are the node types along a path from the root
+var searchLevels = []string{"catalog", "schema", "table", "column"}
+
+// searchResult is an object found by global search
+type searchResult struct {
+	Type string   // One of searchLevels
+	Path []string // Catalog, schema, table and column, as deep as Type goes
+}
+
+// label is the dotted name of the result
+func (r searchResult) label() string {
+	return strings.Join(r.Path, ".")
+}
+
+// searchObjects fuzzy-matches query against every catalog, schema, table and
+// column the browser has loaded. Queries with a dot match qualified names;
+// others match the object's own name.
+func (b *Browser) searchObjects(query string) []searchResult {
+	query = strings.ToLower(strings.TrimSpace(query))
+	if query == "" {
+		return nil
+	}
+	qualified := strings.Contains(query, ".")
+
+	type scoredResult struct {
+		searchResult
+		score  int
+		length int // Of the matched name; shorter is closer
+		level  int
+		label  string
+	}
+	var scored []scoredResult
+	add := func(level int, path ...string) {
+		if level == 0 && b.hides(path[0], "") || level > 0 && b.hides(path[0], path[1]) {
+			return
+		}
+		r := searchResult{Type: searchLevels[level], Path: path}
+		label := r.label()
+		name := path[len(path)-1]
+		if qualified {
+			name = label
+		}
+		if score, ok := fuzzyScore(query, strings.ToLower(name)); ok {
+			scored = append(scored, scoredResult{r, score, len(name), level, label})
+		}
+	}
+
+	b.tree.mu.RLock()
+	for catalog := range b.tree.Catalogs {
+		add(0, catalog)
+	}
+	for catalog, schemas := range b.tree.Schemas {
+		for schema := range schemas {
+			add(1, catalog, schema)
+		}
+	}
+	for catalog, schemas := range b.tree.Tables {
+		for schema, tables := range schemas {
+			for table := range tables {
+				add(2, catalog, schema, table)
+			}
+		}
+	}
+	for catalog, schemas := range b.tree.Columns {
+		for schema, tables := range schemas {
+			for table, columns := range tables {
+				for _, col := range columns {
+					add(3, catalog, schema, table, col.Name)
+				}
+			}
+		}
+	}
+	b.tree.mu.RUnlock()
+
+	// Better and closer matches first, then catalogs before the objects
+	// inside them
+	sort.Slice(scored, func(i, j int) bool {
+		if scored[i].score != scored[j].score {
+			return scored[i].score < scored[j].score
+		}
+		if scored[i].length != scored[j].length {
+			return scored[i].length < scored[j].length
+		}
+		if scored[i].level != scored[j].level {
+			return scored[i].level < scored[j].level
+		}
+		return scored[i].label < scored[j].label
+	})
+	results := make([]searchResult, 0, min(len(scored), searchResultLimit))
+	for _, s := range scored[:min(len(scored), searchResultLimit)] {
+		results = append(results, s.searchResult)
+	}
+	return results
+}
+
+// searchKey handles /, which opens global search
+func (b *Browser) searchKey(event *tcell.EventKey) *tcell.EventKey {
+	if event.Key() == tcell.KeyRune && event.Rune() == '/' && event.Modifiers()&(tcell.ModAlt|tcell.ModCtrl) == 0 {
+		b.openSearch()
+		return nil
+	}
+	return event
+}
+
+// openSearch shows the global search in place of the info pane
+func (b *Browser) openSearch() {
+	if b.searchPane == nil {
+		b.searchInput = tview.NewInputField().
+			SetLabel("Find: ").
+			SetPlaceholder("catalog, schema, table or column; a dot matches qualified names")
+		b.searchList = tview.NewList().
+			ShowSecondaryText(false).
+			SetHighlightFullLine(true)
+		b.searchPane = tview.NewFlex().
+			SetDirection(tview.FlexRow).
+			AddItem(b.searchInput, 1, 0, true).
+			AddItem(b.searchList, 0, 1, false)
+		b.searchPane.SetBorder(true).
+			SetTitle(" Search loaded objects (Enter jumps, Esc closes) ").
+			SetTitleAlign(tview.AlignLeft).
+			SetTitleColor(b.palette.InfoTitle)
+
+		b.searchInput.SetChangedFunc(b.updateSearch)
+		b.searchInput.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+			switch event.Key() {
+			case tcell.KeyUp, tcell.KeyDown, tcell.KeyPgUp, tcell.KeyPgDn:
+				if handler := b.searchList.InputHandler(); handler != nil {
+					handler(event, func(tview.Primitive) {})
+				}
+				return nil
+			case tcell.KeyEnter:
+				b.jumpToResult(b.searchList.GetCurrentItem())
+				return nil
+			case tcell.KeyEscape:
+				b.closeSearch()
+				return nil
+			}
+			return event
+		})
+	}
+
+	b.hideDDL()
+	b.hidePreview()
+	b.searching = true
+	b.searchInput.SetText("")
+	b.updateSearch("")
+	b.placeInfo(b.searchPane, true)
+	if b.app != nil {
+		b.app.SetFocus(b.searchInput)
+	}
+}
+
+// updateSearch lists the objects matching text
+func (b *Browser) updateSearch(text string) {
+	b.searchResults = b.searchObjects(text)
+	b.searchList.Clear()
+	for _, r := range b.searchResults {
+		b.searchList.AddItem(fmt.Sprintf("%s  [gray](%s)[-]", tview.Escape(r.label()), r.Type), "", 0, nil)
+	}
+	if len(b.searchResults) == 0 && strings.TrimSpace(text) != "" {
+		b.searchList.AddItem("[gray]No loaded object matches. Search covers what the tree has loaded.[-]", "", 0, nil)
+	}
+}
+
+// closeSearch puts the info pane back and returns to the tree
+func (b *Browser) closeSearch() {
+	if !b.searching {
+		return
+	}
+	b.searching = false
+	b.placeInfo(b.infoText, false)
+	if b.app != nil {
+		b.app.SetFocus(b.treeView)
+	}
+}
+
+// jumpToResult closes the search and moves the tree to result i
+func (b *Browser) jumpToResult(i int) {
+	if i < 0 || i >= len(b.searchResults) {
+		return
+	}
+	result := b.searchResults[i]
+	b.closeSearch()
+	if b.filter != "" && b.filterInput != nil {
+		// The result may be filtered out
+		b.filterInput.SetText("")
+	}
+	b.infoText.SetText(fmt.Sprintf("Finding %s...", tview.Escape(result.label())))
+	go b.reveal(b.ctx, result)
+}
+
+// reveal walks the tree down to a search result, loading the levels on the
+// way that are not loaded or are hidden by a filter, and selects it
+func (b *Browser) reveal(ctx context.Context, result searchResult) {
+	node := b.rootNode
+	for depth, name := range result.Path {
+		parent := node
+		var child *tview.TreeNode
+		find := func() {
+			child = findChild(parent, depth, name)
+		}
+		if !b.onUI(ctx, find) {
+			return
+		}
+		if child == nil {
+			err := b.runLoad(ctx, parent, false, func(ctx context.Context) error {
+				return b.loadChildren(ctx, parent)
+			})
+			if err != nil {
+				b.logger.Error("Failed to load search result", zap.Error(err), zap.String("object", result.label()))
+				return
+			}
+			if !b.onUI(ctx, find) {
+				return
+			}
+		}
+		if child == nil {
+			b.onUI(ctx, func() {
+				b.infoText.SetText(fmt.Sprintf("[red]%s no longer exists[-]", tview.Escape(result.label())))
+			})
+			return
+		}
+		b.onUI(ctx, func() {
+			parent.SetExpanded(true)
+		})
+		node = child
+	}
+
+	b.onUI(ctx, func() {
+		b.treeView.SetCurrentNode(node)
+		b.nodeChanged(node)
+	})
+}
+
+// findChild returns the child of node at depth in the tree that is named name
+func findChild(node *tview.TreeNode, depth int, name string) *tview.TreeNode {
+	for _, child := range node.GetChildren() {
+		ref, ok := child.GetReference().(*SchemaTreeNode)
+		if !ok || ref.Type != searchLevels[depth] {
+			continue
+		}
+		var childName string
+		switch ref.Type {
+		case "catalog":
+			childName = ref.Catalog
+		case "schema":
+			childName = ref.Schema
+		case "table":
+			childName = ref.Table
+		default:
+			childName = ref.Name
+		}
+		if childName == name {
+			return child
+		}
+	}
+	return nil
+}
+
+// loadChildren (re)loads the children of node, from the cache when it can
+func (b *Browser) loadChildren(ctx context.Context, node *tview.TreeNode) error {
+	if node == b.rootNode {
+		return b.LoadCatalogs(ctx)
+	}
+	ref := node.GetReference().(*SchemaTreeNode)
+	switch ref.Type {
+	case "catalog":
+		return b.LoadSchemas(ctx, ref.Catalog, node)
+	case "schema":
+		return b.LoadTables(ctx, ref.Catalog, ref.Schema, node)
+	case "table":
+		return b.LoadColumns(ctx, ref.Catalog, ref.Schema, ref.Table, node)
+	}
+	return nil
+}
+
+// onUI runs f on the application's event loop and waits for it, reporting
+// false if ctx ends first. Updates queued before it have run by then.
+func (b *Browser) onUI(ctx context.Context, f func()) bool {
+	done := make(chan struct{})
+	b.app.QueueUpdateDraw(func() {
+		f()
+		close(done)
+	})
+	select {
+	case <-done:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
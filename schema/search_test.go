@@ -0,0 +1,106 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/rivo/tview"
+	"go.uber.org/zap/zaptest"
+)
+
+// searchBrowser returns a browser that has loaded a few objects
+func searchBrowser(t *testing.T) *Browser {
+	tree := NewSchemaTree()
+	tree.Catalogs = map[string]bool{"hive": true, "iceberg": true}
+	tree.Schemas = map[string]map[string]bool{"hive": {"sales": true}, "iceberg": {"orders_archive": true}}
+	tree.Tables = map[string]map[string]map[string]bool{"hive": {"sales": {"orders": true, "customers": true}}}
+	tree.Columns = map[string]map[string]map[string][]Column{"hive": {"sales": {
+		"orders":    {{Name: "order_id"}, {Name: "customer_id"}},
+		"customers": {{Name: "id"}, {Name: "name"}},
+	}}}
+
+	browser := &Browser{
+		tree:     tree,
+		cache:    NewSchemaCache(),
+		treeView: tview.NewTreeView(),
+		infoText: tview.NewTextView(),
+		logger:   zaptest.NewLogger(t),
+		rootNode: tview.NewTreeNode("Trino Schema"),
+	}
+	browser.pane = tview.NewFlex().
+		AddItem(browser.treeView, 0, 1, true).
+		AddItem(browser.infoText, 0, 1, false)
+	browser.infoWeight = 1
+	return browser
+}
+
+// TestSearchObjects tests ranking every loaded object against a query
+func TestSearchObjects(t *testing.T) {
+	browser := searchBrowser(t)
+
+	results := browser.searchObjects("order")
+	var labels []string
+	for _, r := range results {
+		labels = append(labels, r.label()+" "+r.Type)
+	}
+	want := []string{
+		"hive.sales.orders table",
+		"hive.sales.orders.order_id column",
+		"iceberg.orders_archive schema",
+	}
+	// Prefix matches first, the closest of them first
+	if len(labels) != len(want) {
+		t.Fatalf("results = %q, want %q", labels, want)
+	}
+	for i, label := range want {
+		if labels[i] != label {
+			t.Errorf("result %d = %q, want %q", i, labels[i], label)
+		}
+	}
+
+	results = browser.searchObjects("sales.cust")
+	if len(results) == 0 || results[0].label() != "hive.sales.customers" {
+		t.Errorf("a qualified query should match the qualified name first, got %+v", results)
+	}
+	if results := browser.searchObjects(" "); results != nil {
+		t.Errorf("an empty query found %+v", results)
+	}
+}
+
+// TestSearchPane tests opening global search in place of the info pane
+func TestSearchPane(t *testing.T) {
+	browser := searchBrowser(t)
+
+	browser.openSearch()
+	if browser.pane.GetItem(1) != browser.searchPane {
+		t.Fatal("the search did not replace the info pane")
+	}
+	browser.searchInput.SetText("customer_id")
+	if browser.searchList.GetItemCount() != 1 || len(browser.searchResults) != 1 ||
+		browser.searchResults[0].label() != "hive.sales.orders.customer_id" {
+		t.Errorf("results = %+v", browser.searchResults)
+	}
+
+	browser.closeSearch()
+	if browser.pane.GetItem(1) != browser.infoText || browser.searching {
+		t.Error("closing the search did not bring the info pane back")
+	}
+}
+
+// TestFindChild tests locating a search result's nodes in the tree
+func TestFindChild(t *testing.T) {
+	browser := searchBrowser(t)
+	catalog := tview.NewTreeNode("hive").SetReference(&SchemaTreeNode{Type: "catalog", Name: "hive", Catalog: "hive"})
+	browser.rootNode.AddChild(catalog)
+	table := browser.tableNode("hive", "sales", "orders", nil, "")
+	schema := tview.NewTreeNode("sales").SetReference(&SchemaTreeNode{Type: "schema", Name: "sales", Catalog: "hive", Schema: "sales"})
+	schema.AddChild(table)
+	catalog.AddChild(schema)
+
+	if findChild(browser.rootNode, 0, "hive") != catalog || findChild(catalog, 1, "sales") != schema ||
+		findChild(schema, 2, "orders") != table {
+		t.Error("the path to hive.sales.orders was not found")
+	}
+	if findChild(schema, 2, "customers") != nil {
+		t.Error("found a table that is not in the tree")
+	}
+}
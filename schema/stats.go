@@ -0,0 +1,249 @@
+package schema
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"go.uber.org/zap"
+)
+
+// TableStats holds the statistics SHOW STATS reports for a table. Nil
+// values are unknown to the connector.
+type TableStats struct {
+	RowCount *float64
+	DataSize *float64 // Sum of the column sizes in bytes
+	Columns  []ColumnStats
+}
+
+// ColumnStats holds the statistics of one column
+type ColumnStats struct {
+	Name           string
+	DataSize       *float64
+	DistinctValues *float64
+	NullsFraction  *float64
+	Low            string
+	High           string
+}
+
+// SetAutoStats makes selecting a table load its statistics, as t does.
+// SHOW STATS can be slow on some connectors, so it is off by default.
+func (b *Browser) SetAutoStats(auto bool) {
+	b.autoStats = auto
+}
+
+// GetStats returns a table's statistics from the cache
+func (sc *SchemaCache) GetStats(catalog, schema, table string) *TableStats {
+	defer sc.rlock()()
+	if sc.Data == nil || time.Now().After(sc.Expiry) {
+		return nil
+	}
+
+	if schemas, ok := sc.Data.Stats[catalog]; ok {
+		if tables, ok := schemas[schema]; ok {
+			return tables[table]
+		}
+	}
+	return nil
+}
+
+// ShowStats returns a table's statistics, from the cache when it has them
+func (b *Browser) ShowStats(ctx context.Context, catalog, schema, table string) (*TableStats, error) {
+	if stats := b.cache.GetStats(catalog, schema, table); stats != nil {
+		return stats, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	rows, err := b.dbPool.QueryContext(ctx, "SHOW STATS FOR "+QualifiedName(catalog, schema, table))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query stats: %w", err)
+	}
+	defer rows.Close()
+
+	stats := &TableStats{}
+	for rows.Next() {
+		var name, low, high sql.NullString
+		var dataSize, distinct, nulls, rowCount sql.NullFloat64
+		if err := rows.Scan(&name, &dataSize, &distinct, &nulls, &rowCount, &low, &high); err != nil {
+			return nil, fmt.Errorf("failed to scan stats: %w", err)
+		}
+		if !name.Valid {
+			// The summary row, which carries the row count
+			stats.RowCount = optionalFloat(rowCount)
+			continue
+		}
+		stats.Columns = append(stats.Columns, ColumnStats{
+			Name:           name.String,
+			DataSize:       optionalFloat(dataSize),
+			DistinctValues: optionalFloat(distinct),
+			NullsFraction:  optionalFloat(nulls),
+			Low:            low.String,
+			High:           high.String,
+		})
+		if dataSize.Valid {
+			if stats.DataSize == nil {
+				stats.DataSize = new(float64)
+			}
+			*stats.DataSize += dataSize.Float64
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating stats: %w", err)
+	}
+
+	b.tree.mu.Lock()
+	if _, ok := b.tree.Stats[catalog]; !ok {
+		b.tree.Stats[catalog] = make(map[string]map[string]*TableStats)
+	}
+	if _, ok := b.tree.Stats[catalog][schema]; !ok {
+		b.tree.Stats[catalog][schema] = make(map[string]*TableStats)
+	}
+	b.tree.Stats[catalog][schema][table] = stats
+	b.tree.mu.Unlock()
+
+	b.updateCache()
+	return stats, nil
+}
+
+func optionalFloat(f sql.NullFloat64) *float64 {
+	if !f.Valid {
+		return nil
+	}
+	return &f.Float64
+}
+
+// statsKey handles t, which loads the current table's statistics
+func (b *Browser) statsKey(event *tcell.EventKey) *tcell.EventKey {
+	if event.Key() != tcell.KeyRune || event.Rune() != 't' || event.Modifiers()&(tcell.ModAlt|tcell.ModCtrl) != 0 {
+		return event
+	}
+	node := b.treeView.GetCurrentNode()
+	if node == nil {
+		return event
+	}
+	if ref, ok := node.GetReference().(*SchemaTreeNode); ok && ref.Type == "table" {
+		b.hideDDL()
+		b.hidePreview()
+		b.loadStats(node, ref)
+		return nil
+	}
+	return event
+}
+
+// loadStats fetches a table's statistics in the background and shows them
+// with the table's info, unless the user has moved on by then
+func (b *Browser) loadStats(node *tview.TreeNode, ref *SchemaTreeNode) {
+	b.infoText.SetText(b.tableInfo(ref, nil) + "\n\nLoading statistics...")
+	go func() {
+		stats, err := b.ShowStats(b.ctx, ref.Catalog, ref.Schema, ref.Table)
+		if err != nil {
+			b.logger.Error("Failed to load table stats", zap.Error(err),
+				zap.String("catalog", ref.Catalog),
+				zap.String("schema", ref.Schema),
+				zap.String("table", ref.Table))
+		}
+		b.app.QueueUpdateDraw(func() {
+			if b.treeView.GetCurrentNode() != node {
+				return
+			}
+			if err != nil {
+				b.infoText.SetText(b.tableInfo(ref, nil) +
+					fmt.Sprintf("\n\n[red]Error loading statistics: %v[-]", tview.Escape(err.Error())))
+				return
+			}
+			b.placeInfo(b.infoText, true)
+			b.infoText.SetText(b.tableInfo(ref, stats))
+			b.infoText.ScrollToBeginning()
+		})
+	}()
+}
+
+// tableInfo describes a table for the info pane, with its statistics when
+// they are known and its SQL when it is a view
+func (b *Browser) tableInfo(ref *SchemaTreeNode, stats *TableStats) string {
+	kind := "Table"
+	if ref.View != nil {
+		kind = "View"
+		if ref.View.Materialized {
+			kind = "Materialized view"
+		}
+	}
+	var text strings.Builder
+	fmt.Fprintf(&text, "[green]%s:[-] %s\n[green]Schema:[-] %s\n[green]Catalog:[-] %s",
+		kind, tview.Escape(ref.Table), tview.Escape(ref.Schema), tview.Escape(ref.Catalog))
+	writeComment(&text, ref.Comment)
+	if stats == nil {
+		text.WriteString("\n\nPress Enter to view columns, d to show its DDL, i its properties, p to preview its rows, t to load its statistics or g to generate queries.")
+	} else {
+		writeStats(&text, stats)
+	}
+	if ref.View != nil && ref.View.Definition != "" {
+		definition := tview.Escape(ref.View.Definition)
+		if b.highlight != nil {
+			definition = b.highlight(ref.View.Definition)
+		}
+		text.WriteString("\n\n[green]Definition:[-]\n" + definition)
+	}
+	return text.String()
+}
+
+// writeStats adds a table's statistics to its description
+func writeStats(text *strings.Builder, stats *TableStats) {
+	fmt.Fprintf(text, "\n[green]Rows:[-] %s  [green]Data size:[-] %s",
+		formatStat(stats.RowCount, formatCount), formatStat(stats.DataSize, formatSize))
+	if len(stats.Columns) == 0 {
+		return
+	}
+	width := len("Column")
+	for _, col := range stats.Columns {
+		width = max(width, len(col.Name))
+	}
+	fmt.Fprintf(text, "\n\n[yellow]%-*s  %12s  %7s[-]", width, "Column", "Distinct", "Nulls")
+	for _, col := range stats.Columns {
+		fmt.Fprintf(text, "\n%-*s  %12s  %7s", width, tview.Escape(col.Name),
+			formatStat(col.DistinctValues, formatCount),
+			formatStat(col.NullsFraction, func(f float64) string { return strconv.FormatFloat(f*100, 'f', 1, 64) + "%" }))
+	}
+}
+
+// formatStat formats a known statistic and shows unknown ones as ?
+func formatStat(value *float64, format func(float64) string) string {
+	if value == nil {
+		return "?"
+	}
+	return format(*value)
+}
+
+// formatCount formats an estimated count with thousands separators
+func formatCount(f float64) string {
+	digits := strconv.FormatFloat(f, 'f', 0, 64)
+	var out strings.Builder
+	for i, d := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 && digits[i-1] != '-' {
+			out.WriteByte(',')
+		}
+		out.WriteRune(d)
+	}
+	return out.String()
+}
+
+// formatSize formats a size in bytes
+func formatSize(f float64) string {
+	units := []string{"B", "KB", "MB", "GB", "TB", "PB"}
+	i := 0
+	for f >= 1024 && i < len(units)-1 {
+		f /= 1024
+		i++
+	}
+	if i == 0 {
+		return fmt.Sprintf("%.0f %s", f, units[i])
+	}
+	return fmt.Sprintf("%.1f %s", f, units[i])
+}
@@ -0,0 +1,60 @@
+package schema
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"go.uber.org/zap/zaptest"
+)
+
+// TestShowStats tests reading SHOW STATS and caching the result
+func TestShowStats(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery("SHOW STATS FOR hive.sales.orders").
+		WillReturnRows(sqlmock.NewRows([]string{"column_name", "data_size", "distinct_values_count", "nulls_fraction", "row_count", "low_value", "high_value"}).
+			AddRow("id", nil, 1500000.0, 0.0, nil, "1", "1500000").
+			AddRow("comment", 2048.0, nil, 0.25, nil, nil, nil).
+			AddRow(nil, nil, nil, nil, 1500000.0, nil, nil))
+
+	browser := &Browser{
+		tree:   NewSchemaTree(),
+		cache:  NewSchemaCache(),
+		dbPool: db,
+		logger: zaptest.NewLogger(t),
+	}
+	stats, err := browser.ShowStats(context.Background(), "hive", "sales", "orders")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.RowCount == nil || *stats.RowCount != 1500000 {
+		t.Errorf("row count = %v, want 1500000", stats.RowCount)
+	}
+	if stats.DataSize == nil || *stats.DataSize != 2048 {
+		t.Errorf("data size = %v, want the known column sizes summed", stats.DataSize)
+	}
+	if len(stats.Columns) != 2 || stats.Columns[0].DistinctValues == nil || stats.Columns[1].DistinctValues != nil {
+		t.Fatalf("columns = %+v", stats.Columns)
+	}
+
+	// The second call is served from the cache
+	if cached, err := browser.ShowStats(context.Background(), "hive", "sales", "orders"); err != nil || cached != stats {
+		t.Errorf("cached stats = %v, %v", cached, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+
+	info := browser.tableInfo(&SchemaTreeNode{Type: "table", Catalog: "hive", Schema: "sales", Table: "orders"}, stats)
+	for _, want := range []string{"1,500,000", "2.0 KB", "25.0%", "comment", "?"} {
+		if !strings.Contains(info, want) {
+			t.Errorf("info %q lacks %q", info, want)
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package schema
+
+import (
+	"context"
+
+	"github.com/TFMV/trino-cli/autocomplete"
+	"github.com/TFMV/trino-cli/config"
+	"github.com/rivo/tview"
+	"go.uber.org/zap"
+)
+
+// The browser shares the metadata autocomplete keeps on disk for each
+// profile. Tables and columns missing from the in-memory cache are read from
+// it before the server, so a new session opens warm, and what the browser
+// reads from the server is written back so both features agree. The store
+// only holds the schemas autocomplete introspects; the browser never adds
+// others to it.
+
+// storeKey marks contexts whose loads must skip the store
+type storeKey struct{}
+
+// withoutStore returns a context whose loads read the server, as a refresh
+// must
+func withoutStore(ctx context.Context) context.Context {
+	return context.WithValue(ctx, storeKey{}, true)
+}
+
+// openStore opens a profile's metadata store, or returns nil if it cannot
+func openStore(profileName string, logger *zap.Logger) *autocomplete.SchemaCache {
+	p := config.AppConfig.Profiles[profileName]
+	dir, err := autocomplete.ProfileCacheDir(autocomplete.Profile{Name: profileName, Server: p.ServerKey()})
+	if err != nil {
+		logger.Warn("Schema browser runs without the metadata store", zap.Error(err))
+		return nil
+	}
+	store, err := autocomplete.NewSchemaCache(dir, logger)
+	if err != nil {
+		logger.Warn("Schema browser runs without the metadata store", zap.Error(err))
+		return nil
+	}
+	return store
+}
+
+// useStore reports whether loads under ctx may read the store
+func (b *Browser) useStore(ctx context.Context) bool {
+	return b.store != nil && ctx.Value(storeKey{}) == nil
+}
+
+// warmTables fills the in-memory cache with a schema's tables from the
+// store, unless it has them already, and reports whether it did. Views and
+// table comments are not in the store; see markTables.
+func (b *Browser) warmTables(ctx context.Context, catalog, schema string) bool {
+	if !b.useStore(ctx) || b.cache.GetTables(catalog, schema) != nil {
+		return false
+	}
+	if _, ok, err := b.store.GetSchemaUpdate(catalog, schema); err != nil || !ok {
+		return false
+	}
+	tables, err := b.store.GetTables(catalog, schema)
+	if err != nil || len(tables) == 0 {
+		return false
+	}
+
+	b.tree.mu.Lock()
+	if _, ok := b.tree.Tables[catalog]; !ok {
+		b.tree.Tables[catalog] = make(map[string]map[string]bool)
+	}
+	b.tree.Tables[catalog][schema] = make(map[string]bool, len(tables))
+	for _, table := range tables {
+		b.tree.Tables[catalog][schema][table] = true
+	}
+	b.tree.mu.Unlock()
+	b.updateCache()
+	return true
+}
+
+// markTables loads a schema's views and table comments and updates their
+// nodes under node
+func (b *Browser) markTables(ctx context.Context, catalog, schema string, node *tview.TreeNode) {
+	views, err := b.LoadViews(ctx, catalog, schema)
+	if err != nil {
+		b.logger.Warn("Failed to load views", zap.Error(err),
+			zap.String("catalog", catalog),
+			zap.String("schema", schema))
+	}
+	comments := b.LoadTableComments(ctx, catalog, schema)
+
+	b.tree.mu.Lock()
+	if _, ok := b.tree.Views[catalog]; !ok {
+		b.tree.Views[catalog] = make(map[string]map[string]View)
+	}
+	b.tree.Views[catalog][schema] = views
+	if _, ok := b.tree.Comments[catalog]; !ok {
+		b.tree.Comments[catalog] = make(map[string]map[string]string)
+	}
+	b.tree.Comments[catalog][schema] = comments
+	b.tree.mu.Unlock()
+
+	b.app.QueueUpdateDraw(func() {
+		for _, child := range node.GetChildren() {
+			ref, ok := child.GetReference().(*SchemaTreeNode)
+			if !ok || ref.Type != "table" {
+				continue
+			}
+			ref.Comment = comments[ref.Table]
+			if view, ok := views[ref.Table]; ok {
+				ref.View = &view
+				child.SetText(tableLabel(ref.Table, ref.View)).SetColor(b.tableColor(ref.View))
+			}
+		}
+	})
+}
+
+// warmColumns fills the in-memory cache with a table's columns from the
+// store, unless it has them already
+func (b *Browser) warmColumns(ctx context.Context, catalog, schema, table string) {
+	if !b.useStore(ctx) || b.cache.GetColumns(catalog, schema, table) != nil {
+		return
+	}
+	stored, err := b.store.GetColumns(catalog, schema, table)
+	if err != nil || len(stored) == 0 {
+		return
+	}
+
+	columns := make([]Column, len(stored))
+	for i, col := range stored {
+		// The store does not record nullability
+		columns[i] = Column{Name: col.Name, Type: col.DataType, Nullable: true, Comment: col.Comment}
+	}
+	b.tree.mu.Lock()
+	if _, ok := b.tree.Columns[catalog]; !ok {
+		b.tree.Columns[catalog] = make(map[string]map[string][]Column)
+	}
+	if _, ok := b.tree.Columns[catalog][schema]; !ok {
+		b.tree.Columns[catalog][schema] = make(map[string][]Column)
+	}
+	b.tree.Columns[catalog][schema][table] = columns
+	b.tree.mu.Unlock()
+	b.updateCache()
+}
+
+// storeSchemas drops the schemas of a catalog the server no longer has from
+// the store
+func (b *Browser) storeSchemas(catalog string, schemas []string) {
+	if b.store == nil {
+		return
+	}
+	stored, err := b.store.GetSchemas(catalog)
+	if err != nil {
+		b.logger.Debug("Failed to read stored schemas", zap.Error(err), zap.String("catalog", catalog))
+		return
+	}
+	current := make(map[string]bool, len(schemas))
+	for _, schema := range schemas {
+		current[schema] = true
+	}
+	for _, schema := range stored {
+		if !current[schema] {
+			if err := b.store.DeleteSchema(catalog, schema); err != nil {
+				b.logger.Debug("Failed to drop stored schema", zap.Error(err),
+					zap.String("catalog", catalog),
+					zap.String("schema", schema))
+			}
+		}
+	}
+}
+
+// storeTables writes a schema's tables back to the store
+func (b *Browser) storeTables(catalog, schema string, tables []string) {
+	if b.store == nil {
+		return
+	}
+	if err := b.store.SyncTables(catalog, schema, tables); err != nil {
+		b.logger.Debug("Failed to store tables", zap.Error(err),
+			zap.String("catalog", catalog),
+			zap.String("schema", schema))
+	}
+}
+
+// storeColumns writes a table's columns back to the store
+func (b *Browser) storeColumns(catalog, schema, table string, columns []Column) {
+	if b.store == nil {
+		return
+	}
+	stored := make([]autocomplete.ColumnMetadata, len(columns))
+	for i, col := range columns {
+		stored[i] = autocomplete.ColumnMetadata{Name: col.Name, DataType: col.Type, Table: table, Schema: schema, Catalog: catalog, Comment: col.Comment}
+	}
+	if err := b.store.StoreColumns(catalog, schema, table, stored); err != nil {
+		b.logger.Debug("Failed to store columns", zap.Error(err),
+			zap.String("catalog", catalog),
+			zap.String("schema", schema),
+			zap.String("table", table))
+	}
+}
+
+// closeStore closes the metadata store, if one is open
+func (b *Browser) closeStore() {
+	if b.store == nil {
+		return
+	}
+	if err := b.store.Close(); err != nil {
+		b.logger.Debug("Failed to close the metadata store", zap.Error(err))
+	}
+	b.store = nil
+}
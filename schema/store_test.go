@@ -0,0 +1,57 @@
+package schema
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/TFMV/trino-cli/autocomplete"
+	"go.uber.org/zap/zaptest"
+)
+
+// TestStoreWarmsAndSyncs tests reading metadata from the store shared with
+// autocomplete and writing it back
+func TestStoreWarmsAndSyncs(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	store, err := autocomplete.NewSchemaCache(t.TempDir(), logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = store.StoreSchema(autocomplete.SchemaMetadata{Catalog: "hive", Name: "sales", Tables: []autocomplete.TableMetadata{
+		{Name: "orders", Columns: []autocomplete.ColumnMetadata{{Name: "id", DataType: "bigint"}}},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	browser := &Browser{tree: NewSchemaTree(), cache: NewSchemaCache(), logger: logger, store: store}
+	defer browser.closeStore()
+	ctx := context.Background()
+
+	if browser.warmTables(withoutStore(ctx), "hive", "sales") {
+		t.Error("a refresh read tables from the store")
+	}
+	if !browser.warmTables(ctx, "hive", "sales") || !slices.Equal(browser.cache.GetTables("hive", "sales"), []string{"orders"}) {
+		t.Errorf("tables were not warmed: %v", browser.cache.GetTables("hive", "sales"))
+	}
+	if browser.warmTables(ctx, "hive", "marketing") {
+		t.Error("warmed a schema the store does not hold")
+	}
+	browser.warmColumns(ctx, "hive", "sales", "orders")
+	if columns := browser.cache.GetColumns("hive", "sales", "orders"); len(columns) != 1 || columns[0].Type != "bigint" {
+		t.Errorf("columns = %+v", columns)
+	}
+
+	browser.storeTables("hive", "sales", []string{"orders", "returns"})
+	browser.storeColumns("hive", "sales", "returns", []Column{{Name: "order_id", Type: "bigint"}})
+	if columns, _ := store.GetColumns("hive", "sales", "returns"); len(columns) != 1 || columns[0].Name != "order_id" {
+		t.Errorf("stored columns = %+v", columns)
+	}
+	browser.storeTables("hive", "marketing", []string{"campaigns"})
+	browser.storeSchemas("hive", []string{"marketing"})
+	if schemas, _ := store.GetSchemas("hive"); len(schemas) != 0 {
+		t.Errorf("stored schemas = %q, want the dropped schema gone and none added", schemas)
+	}
+	if tables, _ := store.GetTables("hive", "marketing"); len(tables) != 0 {
+		t.Errorf("stored a schema autocomplete does not introspect: %q", tables)
+	}
+}
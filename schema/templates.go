@@ -0,0 +1,252 @@
+package schema
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/TFMV/trino-cli/snippet"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"go.uber.org/zap"
+)
+
+// templateFilterColumns caps the columns a filter template puts in WHERE
+const templateFilterColumns = 3
+
+// QueryTemplate is a query generated for a table. Its SQL may contain
+// snippet placeholders such as ${order_id}, which an editor offers as tab
+// stops; see snippet.Expand.
+type QueryTemplate struct {
+	Name string
+	SQL  string
+}
+
+// SetQueryOpener lets the browser open query templates in an editor. Without
+// one, templates can only be copied.
+func (b *Browser) SetQueryOpener(open func(sql string)) {
+	b.openQuery = open
+}
+
+// placeholderName turns a column name into a placeholder name
+var placeholderName = regexp.MustCompile(`[^A-Za-z0-9_]+`)
+
+// QueryTemplates returns the common queries for a table with columns
+func QueryTemplates(catalog, schema, table string, columns []Column) []QueryTemplate {
+	name := QualifiedName(catalog, schema, table)
+	list := " * "
+	if len(columns) > 0 {
+		names := make([]string, len(columns))
+		for i, col := range columns {
+			names[i] = QuoteIdentifier(col.Name)
+		}
+		list = "\n  " + strings.Join(names, ",\n  ") + "\n"
+	}
+
+	var filters []string
+	seen := make(map[string]bool)
+	for _, col := range columns[:min(len(columns), templateFilterColumns)] {
+		placeholder := strings.Trim(placeholderName.ReplaceAllString(col.Name, "_"), "_")
+		if placeholder == "" || seen[placeholder] {
+			placeholder = fmt.Sprintf("value%d", len(filters)+1)
+		}
+		seen[placeholder] = true
+		value := "${" + placeholder + "}"
+		if isTextType(col.Type) {
+			value = "'" + value + "'"
+		}
+		filters = append(filters, QuoteIdentifier(col.Name)+" = "+value)
+	}
+	filtered := fmt.Sprintf("SELECT%sFROM %s", list, name)
+	if len(filters) > 0 {
+		filtered += "\nWHERE " + strings.Join(filters, "\n  AND ")
+	}
+
+	return []QueryTemplate{
+		{Name: "Select columns", SQL: fmt.Sprintf("SELECT%sFROM %s\nLIMIT ${limit:100}", list, name)},
+		{Name: "Count rows", SQL: "SELECT COUNT(*) FROM " + name},
+		{Name: "Select with filters", SQL: filtered},
+	}
+}
+
+// isTextType reports whether values of a Trino type are written as strings
+func isTextType(dataType string) bool {
+	dataType = strings.ToLower(dataType)
+	return strings.HasPrefix(dataType, "varchar") || strings.HasPrefix(dataType, "char")
+}
+
+// templateKey handles g, which lists query templates for the current table
+func (b *Browser) templateKey(event *tcell.EventKey) *tcell.EventKey {
+	if event.Key() != tcell.KeyRune || event.Rune() != 'g' || event.Modifiers()&(tcell.ModAlt|tcell.ModCtrl) != 0 {
+		return event
+	}
+	node := b.treeView.GetCurrentNode()
+	if node == nil {
+		return event
+	}
+	if ref, ok := node.GetReference().(*SchemaTreeNode); ok && ref.Type == "table" {
+		b.showTemplates(node, ref)
+		return nil
+	}
+	return event
+}
+
+// TableColumns returns a table's columns, from the cache when it has them
+func (b *Browser) TableColumns(ctx context.Context, catalog, schema, table string) ([]Column, error) {
+	b.warmColumns(ctx, catalog, schema, table)
+	if columns := b.cache.GetColumns(catalog, schema, table); columns != nil {
+		return columns, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	described, err := describeTable(ctx, b.dbPool, catalog, schema, table)
+	if err != nil {
+		return nil, err
+	}
+	columns := make([]Column, len(described))
+	for i, col := range described {
+		columns[i] = Column{Name: col.Name, Type: col.Type, Nullable: col.Nullable, Comment: col.Comment}
+	}
+	return columns, nil
+}
+
+// showTemplates generates a table's query templates in the background and
+// lists them in place of the info pane, unless the user has moved on by then
+func (b *Browser) showTemplates(node *tview.TreeNode, ref *SchemaTreeNode) {
+	b.hideDDL()
+	b.hidePreview()
+	b.infoText.SetText(fmt.Sprintf("[green]Table:[-] %s\n\nLoading columns...", tview.Escape(ref.Table)))
+	go func() {
+		columns, err := b.TableColumns(b.ctx, ref.Catalog, ref.Schema, ref.Table)
+		if err != nil {
+			b.logger.Error("Failed to load columns for query templates", zap.Error(err),
+				zap.String("catalog", ref.Catalog),
+				zap.String("schema", ref.Schema),
+				zap.String("table", ref.Table))
+		}
+		b.app.QueueUpdateDraw(func() {
+			if b.treeView.GetCurrentNode() != node {
+				return
+			}
+			if err != nil {
+				b.infoText.SetText(fmt.Sprintf("[red]Error loading columns: %v[-]", tview.Escape(err.Error())))
+				return
+			}
+			b.renderTemplates(ref.Table, QueryTemplates(ref.Catalog, ref.Schema, ref.Table, columns))
+		})
+	}()
+}
+
+// renderTemplates lists templates in place of the info pane and focuses them
+func (b *Browser) renderTemplates(table string, templates []QueryTemplate) {
+	if b.templatePane == nil {
+		b.templateList = tview.NewList().
+			ShowSecondaryText(false).
+			SetHighlightFullLine(true)
+		b.templateText = tview.NewTextView().
+			SetDynamicColors(true).
+			SetWrap(false)
+		b.templatePane = tview.NewFlex().
+			SetDirection(tview.FlexRow).
+			AddItem(b.templateList, 3, 0, true).
+			AddItem(b.templateText, 0, 1, false)
+		b.templatePane.SetBorder(true).
+			SetTitleAlign(tview.AlignLeft).
+			SetTitleColor(b.palette.InfoTitle)
+
+		b.templateList.SetChangedFunc(func(i int, _, _ string, _ rune) {
+			b.showTemplateSQL(i)
+		})
+		b.templateList.SetSelectedFunc(func(i int, _, _ string, _ rune) {
+			if b.openQuery != nil {
+				b.openTemplate(i)
+			} else {
+				b.copyTemplate(i)
+			}
+		})
+		b.templateList.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+			switch {
+			case event.Key() == tcell.KeyEscape || event.Key() == tcell.KeyTab:
+				b.closeTemplates()
+				return nil
+			case event.Key() == tcell.KeyRune && event.Rune() == 'y':
+				b.copyTemplate(b.templateList.GetCurrentItem())
+				return nil
+			}
+			return event
+		})
+	}
+
+	action := "Enter copies"
+	if b.openQuery != nil {
+		action = "Enter opens, y copies"
+	}
+	b.templatePane.SetTitle(tview.Escape(fmt.Sprintf(" Queries for %s (%s, Esc closes) ", table, action)))
+
+	b.templates = templates
+	b.templateList.Clear()
+	for _, t := range templates {
+		b.templateList.AddItem(t.Name, "", 0, nil)
+	}
+	b.showTemplateSQL(0)
+	b.placeInfo(b.templatePane, true)
+	if b.app != nil {
+		b.app.SetFocus(b.templateList)
+	}
+}
+
+// showTemplateSQL shows template i under the list
+func (b *Browser) showTemplateSQL(i int) {
+	if i < 0 || i >= len(b.templates) {
+		return
+	}
+	query, _ := snippet.Expand(b.templates[i].SQL, nil)
+	if b.highlight != nil {
+		b.templateText.SetText(b.highlight(query))
+	} else {
+		b.templateText.SetText(tview.Escape(query))
+	}
+	b.templateText.ScrollToBeginning()
+}
+
+// openTemplate hands template i, placeholders and all, to the editor
+func (b *Browser) openTemplate(i int) {
+	if i < 0 || i >= len(b.templates) || b.openQuery == nil {
+		return
+	}
+	query := b.templates[i].SQL
+	b.closeTemplates()
+	b.openQuery(query)
+}
+
+// copyTemplate copies template i with its placeholders filled in by their
+// names and defaults
+func (b *Browser) copyTemplate(i int) {
+	if i < 0 || i >= len(b.templates) {
+		return
+	}
+	query, _ := snippet.Expand(b.templates[i].SQL, nil)
+	name := b.templates[i].Name
+	b.closeTemplates()
+	if b.copy == nil {
+		b.infoText.SetText("[red]Copying is not available[-]")
+		return
+	}
+	how, err := b.copy(query)
+	if err != nil {
+		b.infoText.SetText(fmt.Sprintf("[red]Copy failed: %s[-]", tview.Escape(err.Error())))
+		return
+	}
+	b.infoText.SetText(fmt.Sprintf("%s query copied %s", name, tview.Escape(how)))
+}
+
+// closeTemplates puts the info pane back and returns to the tree
+func (b *Browser) closeTemplates() {
+	b.placeInfo(b.infoText, false)
+	if b.app != nil {
+		b.app.SetFocus(b.treeView)
+	}
+}
@@ -0,0 +1,80 @@
+package schema
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/rivo/tview"
+	"go.uber.org/zap/zaptest"
+)
+
+// TestQueryTemplates tests generating queries from a table's columns
+func TestQueryTemplates(t *testing.T) {
+	columns := []Column{
+		{Name: "order_id", Type: "bigint"},
+		{Name: "Status", Type: "varchar(10)"},
+		{Name: "order-id", Type: "integer"},
+		{Name: "total", Type: "double"},
+	}
+	templates := QueryTemplates("hive", "sales", "orders", columns)
+	if len(templates) != 3 {
+		t.Fatalf("templates = %+v", templates)
+	}
+
+	wantSelect := "SELECT\n  order_id,\n  \"Status\",\n  \"order-id\",\n  total\nFROM hive.sales.orders\nLIMIT ${limit:100}"
+	if templates[0].SQL != wantSelect {
+		t.Errorf("select = %q, want %q", templates[0].SQL, wantSelect)
+	}
+	if templates[1].SQL != "SELECT COUNT(*) FROM hive.sales.orders" {
+		t.Errorf("count = %q", templates[1].SQL)
+	}
+	// Strings are quoted, and placeholders stay distinct
+	wantWhere := "\nWHERE order_id = ${order_id}\n  AND \"Status\" = '${Status}'\n  AND \"order-id\" = ${value3}"
+	if !strings.HasSuffix(templates[2].SQL, wantWhere) {
+		t.Errorf("filtered = %q, want it to end %q", templates[2].SQL, wantWhere)
+	}
+
+	if templates := QueryTemplates("hive", "sales", "orders", nil); templates[0].SQL != "SELECT * FROM hive.sales.orders\nLIMIT ${limit:100}" ||
+		templates[2].SQL != "SELECT * FROM hive.sales.orders" {
+		t.Errorf("templates without columns = %+v", templates)
+	}
+}
+
+// TestTemplateActions tests opening and copying a listed template
+func TestTemplateActions(t *testing.T) {
+	var opened, copied string
+	browser := &Browser{
+		cache:    NewSchemaCache(),
+		treeView: tview.NewTreeView(),
+		infoText: tview.NewTextView(),
+		logger:   zaptest.NewLogger(t),
+		copy: func(text string) (string, error) {
+			copied = text
+			return "to the clipboard", nil
+		},
+	}
+	browser.pane = tview.NewFlex().
+		AddItem(browser.treeView, 0, 1, true).
+		AddItem(browser.infoText, 0, 1, false)
+	browser.infoWeight = 1
+	templates := QueryTemplates("hive", "sales", "orders", []Column{{Name: "id", Type: "bigint"}})
+
+	browser.renderTemplates("orders", templates)
+	if browser.pane.GetItem(1) != browser.templatePane {
+		t.Fatal("the templates did not replace the info pane")
+	}
+	browser.copyTemplate(0)
+	if copied != "SELECT\n  id\nFROM hive.sales.orders\nLIMIT 100" {
+		t.Errorf("copied %q, want the placeholders filled in", copied)
+	}
+	if browser.pane.GetItem(1) != browser.infoText {
+		t.Error("copying did not bring the info pane back")
+	}
+
+	browser.SetQueryOpener(func(sql string) { opened = sql })
+	browser.renderTemplates("orders", templates)
+	browser.openTemplate(2)
+	if opened != templates[2].SQL || !strings.Contains(opened, "${id}") {
+		t.Errorf("opened %q, want the template with its placeholders", opened)
+	}
+}
@@ -0,0 +1,148 @@
+package schema
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"go.uber.org/zap"
+)
+
+// View describes a table node that is a view or a materialized view
+type View struct {
+	Materialized bool
+	Definition   string // The view's SQL, when the connector reports it
+}
+
+// GetView returns what the cache knows about a view; ok is false for base
+// tables
+func (sc *SchemaCache) GetView(catalog, schema, table string) (view View, ok bool) {
+	defer sc.rlock()()
+	if sc.Data == nil || time.Now().After(sc.Expiry) {
+		return View{}, false
+	}
+
+	if schemas, ok := sc.Data.Views[catalog]; ok {
+		if tables, ok := schemas[schema]; ok {
+			view, ok := tables[table]
+			return view, ok
+		}
+	}
+	return View{}, false
+}
+
+// sqlString quotes s as a SQL string literal
+func sqlString(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// LoadViews returns the views and materialized views of a schema by name.
+// Materialized views come from system.metadata, which some servers restrict;
+// failing to read it only leaves them looking like tables.
+func (b *Browser) LoadViews(ctx context.Context, catalog, schema string) (map[string]View, error) {
+	views := make(map[string]View)
+
+	query := fmt.Sprintf("SELECT table_name, view_definition FROM %s.information_schema.views WHERE table_schema = %s",
+		QuoteIdentifier(catalog), sqlString(schema))
+	rows, err := b.dbPool.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query views: %w", err)
+	}
+	for rows.Next() {
+		var name string
+		var definition *string
+		if err := rows.Scan(&name, &definition); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan view: %w", err)
+		}
+		view := View{}
+		if definition != nil {
+			view.Definition = strings.TrimSpace(*definition)
+		}
+		views[name] = view
+	}
+	err = rows.Err()
+	rows.Close()
+	if err != nil {
+		return nil, fmt.Errorf("error iterating views: %w", err)
+	}
+
+	query = fmt.Sprintf("SELECT name, definition FROM system.metadata.materialized_views WHERE catalog_name = %s AND schema_name = %s",
+		sqlString(catalog), sqlString(schema))
+	rows, err = b.dbPool.QueryContext(ctx, query)
+	if err != nil {
+		b.logger.Debug("Failed to query materialized views", zap.Error(err),
+			zap.String("catalog", catalog),
+			zap.String("schema", schema))
+		return views, nil
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var name string
+		var definition *string
+		if err := rows.Scan(&name, &definition); err != nil {
+			return nil, fmt.Errorf("failed to scan materialized view: %w", err)
+		}
+		view := View{Materialized: true}
+		if definition != nil {
+			view.Definition = strings.TrimSpace(*definition)
+		}
+		views[name] = view
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating materialized views: %w", err)
+	}
+	return views, nil
+}
+
+// tableNode returns the tree node of a table, colored and labelled by
+// whether it is a view
+func (b *Browser) tableNode(catalog, schema, table string, view *View, comment string) *tview.TreeNode {
+	return tview.NewTreeNode(tableLabel(table, view)).
+		SetReference(&SchemaTreeNode{
+			Type:    "table",
+			Name:    table,
+			Catalog: catalog,
+			Schema:  schema,
+			Table:   table,
+			View:    view,
+			Comment: comment,
+		}).
+		SetSelectable(true).
+		SetColor(b.tableColor(view))
+}
+
+// tableLabel is the text of a table node
+func tableLabel(table string, view *View) string {
+	switch {
+	case view == nil:
+		return table
+	case view.Materialized:
+		return table + " (materialized view)"
+	default:
+		return table + " (view)"
+	}
+}
+
+// tableColor is the color of a table node
+func (b *Browser) tableColor(view *View) tcell.Color {
+	switch {
+	case view == nil:
+		return b.palette.Table
+	case view.Materialized:
+		return b.palette.MaterializedView
+	default:
+		return b.palette.View
+	}
+}
+
+// viewOf looks up a table in the cache, returning nil for base tables
+func (b *Browser) viewOf(catalog, schema, table string) *View {
+	if view, ok := b.cache.GetView(catalog, schema, table); ok {
+		return &view
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package schema
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"go.uber.org/zap/zaptest"
+)
+
+// TestLoadViews tests telling views and materialized views from tables
+func TestLoadViews(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock DB: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT table_name, view_definition FROM hive.information_schema.views WHERE table_schema = 'o''brien'`)).
+		WillReturnRows(sqlmock.NewRows([]string{"table_name", "view_definition"}).
+			AddRow("recent", " SELECT * FROM orders ").
+			AddRow("hidden", nil))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT name, definition FROM system.metadata.materialized_views WHERE catalog_name = 'hive' AND schema_name = 'o''brien'`)).
+		WillReturnRows(sqlmock.NewRows([]string{"name", "definition"}).AddRow("daily", "SELECT 1"))
+
+	browser := &Browser{dbPool: db, logger: zaptest.NewLogger(t), palette: DefaultPalette}
+	views, err := browser.LoadViews(context.Background(), "hive", "o'brien")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]View{
+		"recent": {Definition: "SELECT * FROM orders"},
+		"hidden": {},
+		"daily":  {Materialized: true, Definition: "SELECT 1"},
+	}
+	if len(views) != len(want) {
+		t.Fatalf("views = %+v, want %+v", views, want)
+	}
+	for name, view := range want {
+		if views[name] != view {
+			t.Errorf("views[%s] = %+v, want %+v", name, views[name], view)
+		}
+	}
+
+	// Without access to system.metadata, views are still told apart
+	mock.ExpectQuery("information_schema.views").
+		WillReturnRows(sqlmock.NewRows([]string{"table_name", "view_definition"}).AddRow("recent", "SELECT 1"))
+	mock.ExpectQuery("materialized_views").WillReturnError(errors.New("Access Denied"))
+	if views, err := browser.LoadViews(context.Background(), "hive", "sales"); err != nil || len(views) != 1 {
+		t.Errorf("views = %+v, %v; want the plain view", views, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+
+	daily := views["daily"]
+	node := browser.tableNode("hive", "sales", "daily", &daily, "")
+	if node.GetText() != "daily (materialized view)" || node.GetColor() != DefaultPalette.MaterializedView {
+		t.Errorf("node = %q in %v", node.GetText(), node.GetColor())
+	}
+	if node := browser.tableNode("hive", "sales", "orders", nil, ""); node.GetText() != "orders" || node.GetColor() != DefaultPalette.Table {
+		t.Errorf("table node = %q in %v", node.GetText(), node.GetColor())
+	}
+
+	info := browser.tableInfo(node.GetReference().(*SchemaTreeNode), nil)
+	if !strings.Contains(info, "Materialized view:") || !strings.Contains(info, "SELECT 1") {
+		t.Errorf("info %q lacks the kind or the SQL", info)
+	}
+}
@@ -0,0 +1,264 @@
+package schema
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// defaultHidden are the objects the tree hides until H shows them: the
+// system catalog and every catalog's information_schema
+var defaultHidden = []string{"system", "*.information_schema"}
+
+// SetHiddenPatterns hides objects matching patterns along with the default
+// ones. Patterns are globs matched, ignoring case, against a catalog name,
+// or against catalog.schema when they contain a dot. If a pattern is
+// invalid, only the defaults are hidden.
+func (b *Browser) SetHiddenPatterns(patterns []string) error {
+	b.hidden = slices.Clone(defaultHidden)
+	var extra []string
+	for _, pattern := range patterns {
+		pattern = strings.ToLower(strings.TrimSpace(pattern))
+		if pattern == "" {
+			continue
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid ui.schema_hide pattern %q: %w", pattern, err)
+		}
+		extra = append(extra, pattern)
+	}
+	b.hidden = append(b.hidden, extra...)
+	return nil
+}
+
+// SetShowHidden sets whether the tree shows the objects it hides by
+// default; H toggles it
+func (b *Browser) SetShowHidden(show bool) {
+	b.showHidden = show
+}
+
+// hides reports whether a catalog, or a schema when schema is not empty, is
+// hidden
+func (b *Browser) hides(catalog, schema string) bool {
+	if b.showHidden {
+		return false
+	}
+	patterns := b.hidden
+	if patterns == nil {
+		patterns = defaultHidden
+	}
+	catalog, schema = strings.ToLower(catalog), strings.ToLower(schema)
+	for _, pattern := range patterns {
+		if strings.Contains(pattern, ".") {
+			if schema != "" {
+				if ok, _ := path.Match(pattern, catalog+"."+schema); ok {
+					return true
+				}
+			}
+		} else if ok, _ := path.Match(pattern, catalog); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// hidesNode reports whether a catalog or schema node is hidden
+func (b *Browser) hidesNode(ref *SchemaTreeNode) bool {
+	switch ref.Type {
+	case "catalog":
+		return b.hides(ref.Catalog, "")
+	case "schema":
+		return b.hides(ref.Catalog, ref.Schema)
+	}
+	return false
+}
+
+// matchesFilter reports whether a catalog, schema, table or column node's
+// name matches the tree filter: a glob when it has wildcards, and otherwise
+// a substring, ignoring case
+func (b *Browser) matchesFilter(ref *SchemaTreeNode) bool {
+	var name string
+	switch ref.Type {
+	case "catalog":
+		name = ref.Catalog
+	case "schema":
+		name = ref.Schema
+	case "table":
+		name = ref.Table
+	case "column":
+		name = ref.Name
+	default:
+		return false
+	}
+	name = strings.ToLower(name)
+	if strings.ContainsAny(b.filter, "*?[") {
+		ok, _ := path.Match(b.filter, name)
+		return ok
+	}
+	return strings.Contains(name, b.filter)
+}
+
+// childrenLoaded records the children just loaded under node and applies
+// the hidden patterns and the filter to them. Loaders call it on the UI
+// goroutine once node's children are in place.
+func (b *Browser) childrenLoaded(node *tview.TreeNode) {
+	if b.unfiltered == nil {
+		b.unfiltered = make(map[*tview.TreeNode][]*tview.TreeNode)
+	}
+	b.unfiltered[node] = slices.Clone(node.GetChildren())
+	b.applyFilter()
+}
+
+// clearChildren empties node before its children are loaded again
+func (b *Browser) clearChildren(node *tview.TreeNode) {
+	node.ClearChildren()
+	delete(b.unfiltered, node)
+}
+
+// applyFilter shows the loaded nodes that are not hidden and, while a
+// filter is set, match it or lead to or from a match. Matches are expanded
+// into view, and the selection moves up if its node is no longer shown.
+func (b *Browser) applyFilter() {
+	recorded := make(map[*tview.TreeNode][]*tview.TreeNode, len(b.unfiltered))
+	parents := make(map[*tview.TreeNode]*tview.TreeNode)
+	shown := map[*tview.TreeNode]bool{b.rootNode: true}
+
+	var visit func(node *tview.TreeNode, matched bool) bool
+	visit = func(node *tview.TreeNode, matched bool) bool {
+		all, ok := b.unfiltered[node]
+		if !ok {
+			// Not loaded by the tree's loaders, e.g. a table's partitions:
+			// shown as is
+			for _, child := range node.GetChildren() {
+				parents[child] = node
+				shown[child] = shown[node]
+			}
+			return false
+		}
+		recorded[node] = all
+
+		found := false
+		var children []*tview.TreeNode
+		for _, child := range all {
+			parents[child] = node
+			ref, _ := child.GetReference().(*SchemaTreeNode)
+			if ref != nil && b.hidesNode(ref) {
+				continue
+			}
+			childMatched := matched || b.filter == "" || (ref != nil && b.matchesFilter(ref))
+			shown[child] = shown[node]
+			below := visit(child, childMatched)
+			if !childMatched && !below {
+				shown[child] = false
+				continue
+			}
+			if below && b.filter != "" {
+				child.SetExpanded(true)
+			}
+			found = found || childMatched || below
+			children = append(children, child)
+		}
+		node.SetChildren(children)
+		return found && b.filter != ""
+	}
+	visit(b.rootNode, false)
+	b.unfiltered = recorded
+
+	current := b.treeView.GetCurrentNode()
+	if current == nil || current == b.rootNode || shown[current] {
+		return
+	}
+	for node := parents[current]; node != nil; node = parents[node] {
+		if shown[node] {
+			b.treeView.SetCurrentNode(node)
+			return
+		}
+	}
+	b.treeView.SetCurrentNode(b.rootNode)
+}
+
+// visibilityKey handles H, which shows or hides the hidden objects, and f,
+// which filters the whole tree
+func (b *Browser) visibilityKey(event *tcell.EventKey) *tcell.EventKey {
+	if event.Key() != tcell.KeyRune || event.Modifiers()&(tcell.ModAlt|tcell.ModCtrl) != 0 {
+		return event
+	}
+	switch event.Rune() {
+	case 'H':
+		b.showHidden = !b.showHidden
+		b.applyFilter()
+		if b.showHidden {
+			b.infoText.SetText("Showing system and hidden schemas. Press H to hide them again.")
+		} else {
+			b.infoText.SetText("Hiding system and hidden schemas. Press H to show them.")
+		}
+		return nil
+	case 'f':
+		b.openFilter()
+		return nil
+	}
+	return event
+}
+
+// openFilter shows the tree filter in place of the info pane
+func (b *Browser) openFilter() {
+	if b.filterInput == nil {
+		b.filterInput = tview.NewInputField().
+			SetLabel("Filter: ").
+			SetPlaceholder("name or glob, matched against everything loaded")
+		b.filterInput.SetBorder(true).
+			SetTitle(" Filter the tree (Enter keeps, Esc clears) ").
+			SetTitleAlign(tview.AlignLeft).
+			SetTitleColor(b.palette.InfoTitle)
+		b.filterInput.SetChangedFunc(b.setFilter)
+		b.filterInput.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+			switch event.Key() {
+			case tcell.KeyEnter:
+				b.closeFilter()
+				return nil
+			case tcell.KeyEscape:
+				b.filterInput.SetText("")
+				b.closeFilter()
+				return nil
+			}
+			return event
+		})
+	}
+
+	b.hideDDL()
+	b.hidePreview()
+	b.searching = false
+	b.filtering = true
+	b.filterInput.SetText(b.filter)
+	b.placeInfo(b.filterInput, false)
+	if b.app != nil {
+		b.app.SetFocus(b.filterInput)
+	}
+}
+
+// closeFilter puts the info pane back and returns to the tree, keeping the
+// filter
+func (b *Browser) closeFilter() {
+	if !b.filtering {
+		return
+	}
+	b.filtering = false
+	b.placeInfo(b.infoText, false)
+	if b.filter != "" {
+		b.infoText.SetText(fmt.Sprintf("Showing loaded objects matching [yellow]%s[-]. Press f to change the filter.", tview.Escape(b.filter)))
+	}
+	if b.app != nil {
+		b.app.SetFocus(b.treeView)
+	}
+}
+
+// setFilter filters the tree by pattern, or shows all of it when pattern is
+// empty
+func (b *Browser) setFilter(pattern string) {
+	b.filter = strings.ToLower(strings.TrimSpace(pattern))
+	b.applyFilter()
+}
@@ -0,0 +1,136 @@
+package schema
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/rivo/tview"
+)
+
+// TestHides tests the default and configured hidden patterns
+func TestHides(t *testing.T) {
+	browser := &Browser{}
+	if !browser.hides("system", "") || !browser.hides("hive", "information_schema") || browser.hides("hive", "sales") {
+		t.Error("the defaults do not hide exactly system and information_schema")
+	}
+
+	if err := browser.SetHiddenPatterns([]string{" Scratch ", "hive.tmp_*"}); err != nil {
+		t.Fatal(err)
+	}
+	if !browser.hides("scratch", "") || !browser.hides("hive", "TMP_1") || browser.hides("iceberg", "tmp_1") || !browser.hides("system", "") {
+		t.Errorf("hidden = %q", browser.hidden)
+	}
+	browser.SetShowHidden(true)
+	if browser.hides("system", "") {
+		t.Error("hid the system catalog while showing hidden objects")
+	}
+
+	if err := browser.SetHiddenPatterns([]string{"[oops"}); err == nil {
+		t.Error("an invalid pattern was accepted")
+	}
+	if !slices.Equal(browser.hidden, defaultHidden) {
+		t.Errorf("hidden = %q after an invalid pattern", browser.hidden)
+	}
+}
+
+// filterTree returns a browser whose tree is loaded down to hive's tables
+func filterTree() (*Browser, map[string]*tview.TreeNode) {
+	browser := &Browser{rootNode: tview.NewTreeNode("Trino Schema")}
+	browser.treeView = tview.NewTreeView().SetRoot(browser.rootNode)
+	nodes := make(map[string]*tview.TreeNode)
+	add := func(parent *tview.TreeNode, name string, ref *SchemaTreeNode) {
+		nodes[name] = tview.NewTreeNode(name).SetReference(ref)
+		parent.AddChild(nodes[name])
+	}
+
+	add(browser.rootNode, "hive", &SchemaTreeNode{Type: "catalog", Catalog: "hive"})
+	add(browser.rootNode, "system", &SchemaTreeNode{Type: "catalog", Catalog: "system"})
+	browser.childrenLoaded(browser.rootNode)
+	add(nodes["hive"], "information_schema", &SchemaTreeNode{Type: "schema", Catalog: "hive", Schema: "information_schema"})
+	add(nodes["hive"], "sales", &SchemaTreeNode{Type: "schema", Catalog: "hive", Schema: "sales"})
+	add(nodes["hive"], "web", &SchemaTreeNode{Type: "schema", Catalog: "hive", Schema: "web"})
+	browser.childrenLoaded(nodes["hive"])
+	add(nodes["sales"], "orders", &SchemaTreeNode{Type: "table", Catalog: "hive", Schema: "sales", Table: "orders"})
+	add(nodes["sales"], "refunds", &SchemaTreeNode{Type: "table", Catalog: "hive", Schema: "sales", Table: "refunds"})
+	browser.childrenLoaded(nodes["sales"])
+	add(nodes["web"], "sessions", &SchemaTreeNode{Type: "table", Catalog: "hive", Schema: "web", Table: "sessions"})
+	browser.childrenLoaded(nodes["web"])
+	return browser, nodes
+}
+
+// childNames returns the names of the children the tree shows under node
+func childNames(node *tview.TreeNode) []string {
+	var names []string
+	for _, child := range node.GetChildren() {
+		names = append(names, child.GetText())
+	}
+	return names
+}
+
+// TestApplyFilter tests hiding and filtering the loaded tree
+func TestApplyFilter(t *testing.T) {
+	browser, nodes := filterTree()
+	if got := childNames(browser.rootNode); !slices.Equal(got, []string{"hive"}) {
+		t.Errorf("catalogs = %q, want system hidden", got)
+	}
+	if got := childNames(nodes["hive"]); !slices.Equal(got, []string{"sales", "web"}) {
+		t.Errorf("schemas = %q, want information_schema hidden", got)
+	}
+
+	browser.SetShowHidden(true)
+	browser.applyFilter()
+	if got := childNames(nodes["hive"]); !slices.Equal(got, []string{"information_schema", "sales", "web"}) {
+		t.Errorf("schemas = %q while showing hidden objects", got)
+	}
+	browser.SetShowHidden(false)
+
+	browser.treeView.SetCurrentNode(nodes["sessions"])
+	browser.setFilter("REF")
+	if got := childNames(nodes["hive"]); !slices.Equal(got, []string{"sales"}) {
+		t.Errorf("schemas = %q, want only the one leading to a match", got)
+	}
+	if got := childNames(nodes["sales"]); !slices.Equal(got, []string{"refunds"}) || !nodes["sales"].IsExpanded() {
+		t.Errorf("tables = %q, want the match in view", got)
+	}
+	if browser.treeView.GetCurrentNode() != nodes["hive"] {
+		t.Errorf("selection is %q, want it moved up to a shown node", browser.treeView.GetCurrentNode().GetText())
+	}
+
+	// A matching node keeps what is under it
+	browser.setFilter("w?b")
+	if got := childNames(nodes["web"]); !slices.Equal(got, []string{"sessions"}) {
+		t.Errorf("tables of a matching schema = %q", got)
+	}
+	if got := childNames(nodes["hive"]); !slices.Equal(got, []string{"web"}) {
+		t.Errorf("schemas = %q for a glob", got)
+	}
+
+	browser.setFilter("")
+	if got := childNames(nodes["sales"]); !slices.Equal(got, []string{"orders", "refunds"}) {
+		t.Errorf("tables = %q after clearing the filter", got)
+	}
+}
+
+// TestSearchSkipsHidden tests that global search leaves hidden objects out
+func TestSearchSkipsHidden(t *testing.T) {
+	browser := &Browser{tree: NewSchemaTree()}
+	browser.tree.Catalogs = map[string]bool{"hive": true, "system": true}
+	browser.tree.Schemas["hive"] = map[string]bool{"information_schema": true, "info": true}
+	browser.tree.Tables["hive"] = map[string]map[string]bool{"information_schema": {"tables": true}}
+
+	for _, r := range browser.searchObjects("inf") {
+		if r.label() == "hive.information_schema" {
+			t.Errorf("found hidden %s", r.label())
+		}
+	}
+	if results := browser.searchObjects("tables"); len(results) != 0 {
+		t.Errorf("found %v in a hidden schema", results)
+	}
+	if results := browser.searchObjects("system"); len(results) != 0 {
+		t.Errorf("found %v", results)
+	}
+	browser.SetShowHidden(true)
+	if results := browser.searchObjects("system"); len(results) != 1 {
+		t.Errorf("found %v while showing hidden objects", results)
+	}
+}
@@ -0,0 +1,88 @@
+package snippet
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// placeholderPattern matches ${name} and ${name:default}
+var placeholderPattern = regexp.MustCompile(`\$\{([A-Za-z0-9_]+)(?::([^}]*))?\}`)
+
+// Placeholder is a named parameter of a snippet
+type Placeholder struct {
+	Name       string `json:"name"`
+	Default    string `json:"default,omitempty"`
+	HasDefault bool   `json:"has_default"`
+}
+
+// Stop is where a placeholder ended up in expanded text, as rune offsets
+type Stop struct {
+	Name       string
+	Start, End int
+}
+
+// Placeholders lists the parameters of body in order of first appearance.
+// A name may appear several times; the first default given for it wins.
+func Placeholders(body string) []Placeholder {
+	var placeholders []Placeholder
+	index := make(map[string]int)
+	for _, m := range placeholderPattern.FindAllStringSubmatchIndex(body, -1) {
+		name := body[m[2]:m[3]]
+		i, seen := index[name]
+		if !seen {
+			i = len(placeholders)
+			index[name] = i
+			placeholders = append(placeholders, Placeholder{Name: name})
+		}
+		if m[4] >= 0 && !placeholders[i].HasDefault {
+			placeholders[i].Default, placeholders[i].HasDefault = body[m[4]:m[5]], true
+		}
+	}
+	return placeholders
+}
+
+// Missing lists the placeholders of body that have neither a value in
+// values nor a default
+func Missing(body string, values map[string]string) []string {
+	var missing []string
+	for _, p := range Placeholders(body) {
+		if _, ok := values[p.Name]; !ok && !p.HasDefault {
+			missing = append(missing, p.Name)
+		}
+	}
+	return missing
+}
+
+// Expand fills in the placeholders of body with values, falling back to
+// each placeholder's default and then to its name, and reports where every
+// occurrence landed so an editor can offer them as tab stops
+func Expand(body string, values map[string]string) (string, []Stop) {
+	fill := make(map[string]string)
+	for _, p := range Placeholders(body) {
+		switch value, ok := values[p.Name]; {
+		case ok:
+			fill[p.Name] = value
+		case p.HasDefault:
+			fill[p.Name] = p.Default
+		default:
+			fill[p.Name] = p.Name
+		}
+	}
+
+	var out strings.Builder
+	var stops []Stop
+	last, offset := 0, 0
+	for _, m := range placeholderPattern.FindAllStringSubmatchIndex(body, -1) {
+		name := body[m[2]:m[3]]
+		out.WriteString(body[last:m[0]])
+		offset += utf8.RuneCountInString(body[last:m[0]])
+		value := fill[name]
+		out.WriteString(value)
+		stops = append(stops, Stop{Name: name, Start: offset, End: offset + utf8.RuneCountInString(value)})
+		offset += utf8.RuneCountInString(value)
+		last = m[1]
+	}
+	out.WriteString(body[last:])
+	return out.String(), stops
+}
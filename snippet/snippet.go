@@ -0,0 +1,170 @@
+package snippet
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+	"go.uber.org/zap"
+)
+
+// Snippet is a named, reusable SQL fragment. Its body may contain
+// placeholders such as ${table} or ${limit:100}; see Expand.
+type Snippet struct {
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	Body        string    `json:"body"`
+	Created     time.Time `json:"created"`
+	Updated     time.Time `json:"updated"`
+}
+
+// ErrNotFound is returned when no snippet has the requested name
+var ErrNotFound = errors.New("snippet not found")
+
+// ErrExists is returned by Add when a snippet with the name already exists
+var ErrExists = errors.New("snippet already exists")
+
+var (
+	db     *sql.DB
+	logger *zap.Logger
+)
+
+// Initialize sets up the snippet database under ~/.trino-cli/snippets
+func Initialize() error {
+	var err error
+	logger, err = zap.NewProduction()
+	if err != nil {
+		return fmt.Errorf("failed to initialize logger: %w", err)
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return fmt.Errorf("failed to get home directory: %w", err)
+	}
+
+	snippetDir := filepath.Join(homeDir, ".trino-cli", "snippets")
+	if err := os.MkdirAll(snippetDir, 0755); err != nil {
+		return fmt.Errorf("failed to create snippet directory: %w", err)
+	}
+
+	dbPath := filepath.Join(snippetDir, "snippets.db")
+	db, err = sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open snippet database: %w", err)
+	}
+
+	createTableSQL := `
+	CREATE TABLE IF NOT EXISTS snippets (
+		name TEXT PRIMARY KEY,
+		description TEXT NOT NULL DEFAULT '',
+		body TEXT NOT NULL,
+		created DATETIME DEFAULT CURRENT_TIMESTAMP,
+		updated DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+	`
+	if _, err := db.Exec(createTableSQL); err != nil {
+		return fmt.Errorf("failed to create snippet table: %w", err)
+	}
+
+	logger.Info("Snippet database initialized", zap.String("path", dbPath))
+	return nil
+}
+
+// Close closes the database connection
+func Close() error {
+	if db != nil {
+		return db.Close()
+	}
+	return nil
+}
+
+// ValidateName checks that a snippet name is usable on the command line:
+// non-empty and free of whitespace
+func ValidateName(name string) error {
+	if name == "" {
+		return fmt.Errorf("snippet name is empty")
+	}
+	if strings.ContainsFunc(name, func(r rune) bool { return r == ' ' || r == '\t' || r == '\n' || r == '\r' }) {
+		return fmt.Errorf("snippet name %q contains whitespace", name)
+	}
+	return nil
+}
+
+// Add stores a snippet. Unless replace is set it fails with ErrExists when
+// the name is taken; replacing keeps the original creation time.
+func Add(ctx context.Context, name, description, body string, replace bool) error {
+	if db == nil {
+		return fmt.Errorf("snippet database not initialized")
+	}
+	if err := ValidateName(name); err != nil {
+		return err
+	}
+	if strings.TrimSpace(body) == "" {
+		return fmt.Errorf("snippet %q has no SQL", name)
+	}
+
+	now := time.Now().UTC()
+	insert := `INSERT INTO snippets (name, description, body, created, updated) VALUES (?, ?, ?, ?, ?)`
+	if replace {
+		insert += ` ON CONFLICT(name) DO UPDATE SET description = excluded.description, body = excluded.body, updated = excluded.updated`
+	} else {
+		insert += ` ON CONFLICT(name) DO NOTHING`
+	}
+	res, err := db.ExecContext(ctx, insert, name, description, body, now, now)
+	if err != nil {
+		return fmt.Errorf("failed to save snippet: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("%w: %s", ErrExists, name)
+	}
+
+	logger.Info("Snippet saved", zap.String("name", name))
+	return nil
+}
+
+// Get returns the snippet with the given name
+func Get(ctx context.Context, name string) (*Snippet, error) {
+	if db == nil {
+		return nil, fmt.Errorf("snippet database not initialized")
+	}
+
+	var s Snippet
+	err := db.QueryRowContext(ctx, `SELECT name, description, body, created, updated FROM snippets WHERE name = ?`, name).
+		Scan(&s.Name, &s.Description, &s.Body, &s.Created, &s.Updated)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snippet: %w", err)
+	}
+	return &s, nil
+}
+
+// List returns every snippet ordered by name
+func List(ctx context.Context) ([]Snippet, error) {
+	if db == nil {
+		return nil, fmt.Errorf("snippet database not initialized")
+	}
+
+	rows, err := db.QueryContext(ctx, `SELECT name, description, body, created, updated FROM snippets ORDER BY name`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list snippets: %w", err)
+	}
+	defer rows.Close()
+
+	var snippets []Snippet
+	for rows.Next() {
+		var s Snippet
+		if err := rows.Scan(&s.Name, &s.Description, &s.Body, &s.Created, &s.Updated); err != nil {
+			return nil, fmt.Errorf("failed to scan snippet: %w", err)
+		}
+		snippets = append(snippets, s)
+	}
+	return snippets, rows.Err()
+}
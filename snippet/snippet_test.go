@@ -0,0 +1,68 @@
+package snippet
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestAddGetList(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	if err := Initialize(); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	defer Close()
+
+	ctx := context.Background()
+	if err := Add(ctx, "top", "Largest rows", "SELECT * FROM ${table} ORDER BY ${column} DESC LIMIT ${n:10}", false); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if err := Add(ctx, "count", "", "SELECT count(*) FROM ${table}", false); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if err := Add(ctx, "top", "", "SELECT 1", false); !errors.Is(err, ErrExists) {
+		t.Errorf("Add of an existing name = %v, want ErrExists", err)
+	}
+	if err := Add(ctx, "two words", "", "SELECT 1", false); err == nil {
+		t.Error("expected names with spaces to be rejected")
+	}
+
+	if err := Add(ctx, "count", "Row count", "SELECT count(*) FROM ${table:orders}", true); err != nil {
+		t.Fatalf("replacing Add failed: %v", err)
+	}
+	s, err := Get(ctx, "count")
+	if err != nil || s.Description != "Row count" || s.Body != "SELECT count(*) FROM ${table:orders}" {
+		t.Errorf("Get = %+v, %v", s, err)
+	}
+	if _, err := Get(ctx, "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get of a missing name = %v, want ErrNotFound", err)
+	}
+
+	snippets, err := List(ctx)
+	if err != nil || len(snippets) != 2 || snippets[0].Name != "count" || snippets[1].Name != "top" {
+		t.Errorf("List = %+v, %v", snippets, err)
+	}
+}
+
+func TestPlaceholders(t *testing.T) {
+	got := Placeholders("SELECT ${col} FROM ${table} WHERE ${col} > ${min:0} AND ${col} < ${max:}")
+	want := []Placeholder{{Name: "col"}, {Name: "table"}, {Name: "min", Default: "0", HasDefault: true}, {Name: "max", HasDefault: true}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Placeholders = %+v, want %+v", got, want)
+	}
+	if got := Missing("SELECT ${col} FROM ${table} LIMIT ${n:5}", map[string]string{"col": "id"}); !reflect.DeepEqual(got, []string{"table"}) {
+		t.Errorf("Missing = %v, want [table]", got)
+	}
+}
+
+func TestExpand(t *testing.T) {
+	text, stops := Expand("SELECT ${col} FROM ${table} WHERE ${col} = 'é' LIMIT ${n:10}", map[string]string{"table": "orders"})
+	if text != "SELECT col FROM orders WHERE col = 'é' LIMIT 10" {
+		t.Errorf("Expand text = %q", text)
+	}
+	want := []Stop{{"col", 7, 10}, {"table", 16, 22}, {"col", 29, 32}, {"n", 45, 47}}
+	if !reflect.DeepEqual(stops, want) {
+		t.Errorf("Expand stops = %+v, want %+v", stops, want)
+	}
+}
@@ -0,0 +1,480 @@
+// Package sqlfmt lays out Trino SQL in a consistent style: each clause of a
+// query on a line of its own, the items of a long select list and the
+// conditions of a WHERE on lines under it, subqueries and CASE expressions
+// indented, and keywords in one case. Only whitespace and the case of
+// keywords change, so formatted SQL means what it meant before.
+package sqlfmt
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/TFMV/trino-cli/config"
+	"github.com/TFMV/trino-cli/sqllex"
+)
+
+// KeywordCase is the case Format writes keywords in
+type KeywordCase string
+
+const (
+	Upper    KeywordCase = "upper"
+	Lower    KeywordCase = "lower"
+	Preserve KeywordCase = "preserve" // As written
+)
+
+// Style is how Format lays SQL out. The zero Style has upper-case keywords,
+// two-space indents and commas at the ends of lines.
+type Style struct {
+	KeywordCase   KeywordCase // Upper, Lower or Preserve; empty means Upper
+	Indent        int         // Spaces per level; 0 means 2
+	LeadingCommas bool        // Start list lines with their comma rather than end them with it
+}
+
+// ParseKeywordCase reads upper, lower or preserve, in any case. Empty is
+// upper.
+func ParseKeywordCase(s string) (KeywordCase, error) {
+	switch c := KeywordCase(strings.ToLower(strings.TrimSpace(s))); c {
+	case "":
+		return Upper, nil
+	case Upper, Lower, Preserve:
+		return c, nil
+	}
+	return "", fmt.Errorf("unknown keyword case %q; use upper, lower or preserve", s)
+}
+
+// Configured returns the style of the config file's sql_format section
+func Configured(c config.SQLFormat) (Style, error) {
+	keywordCase, err := ParseKeywordCase(c.KeywordCase)
+	if err != nil {
+		return Style{}, fmt.Errorf("sql_format.keyword_case: %w", err)
+	}
+	if c.Indent < 0 {
+		return Style{}, fmt.Errorf("sql_format.indent: %d is negative", c.Indent)
+	}
+	return Style{KeywordCase: keywordCase, Indent: c.Indent, LeadingCommas: c.LeadingCommas}, nil
+}
+
+// Format lays out the statements of sql in style, with a blank line between
+// them. Comments are kept where they were, as is text the lexer can't make
+// sense of, such as an unterminated string.
+func Format(sql string, style Style) string {
+	f := &formatter{style: style, tokens: tokenize(sql)}
+	return f.format()
+}
+
+// Compact formats sql on a single line, for editors that can't show more.
+// Line comments become block comments so that they still end where they
+// did.
+func Compact(sql string, style Style) string {
+	style.LeadingCommas = false
+	f := &formatter{style: style, tokens: tokenize(sql), compact: true}
+	return f.format()
+}
+
+// tokenize splits sql into tokens without the whitespace that ends a line
+// comment or unterminated literal, which the formatter lays out itself
+func tokenize(sql string) []sqllex.Token {
+	tokens := sqllex.Tokenize(sql)
+	for i := range tokens {
+		tokens[i].Text = strings.TrimRight(tokens[i].Text, " \t\r")
+	}
+	return tokens
+}
+
+// listClauses have their items on lines of their own when there are several
+var listClauses = map[string]bool{"SELECT": true, "GROUP BY": true, "ORDER BY": true, "VALUES": true}
+
+// conditionClauses have their top-level AND and OR on lines of their own
+var conditionClauses = map[string]bool{"WHERE": true, "HAVING": true, "JOIN": true}
+
+// punctuation is what the formatter knows how to space. Other characters,
+// e.g. the & of U&'...' or the ? of a prepared statement, keep the spacing
+// they had.
+var punctuation = wordSet(`, ( ) [ ] ; . = < > + - * / % <= >= <> != || -> =>`)
+
+// frame is the statement, or a parenthesis or bracket inside it
+type frame struct {
+	query   bool   // Clauses start lines: a query statement, or a subquery
+	indent  int    // Level of the clause keywords
+	close   int    // Level of the line a subquery's closing parenthesis goes on
+	clause  string // Clause being written, e.g. SELECT or GROUP BY
+	list    bool   // The clause's items are on lines of their own
+	cases   []int  // Levels of the lines the open CASE expressions started on
+	between bool   // A BETWEEN waits for its AND
+}
+
+type formatter struct {
+	style   Style
+	compact bool
+	tokens  []sqllex.Token
+	frames  []*frame
+
+	out     strings.Builder
+	level   int           // Level of the current line
+	pending int           // Line breaks due before the next token: 2 leaves a blank line
+	next    int           // Level of the line after the pending breaks
+	prev    *sqllex.Token // Token written last
+	unary   bool          // prev is a sign rather than an operator
+}
+
+func (f *formatter) format() string {
+	f.frames = []*frame{{query: true}}
+	start := true // The next token starts a statement
+	for i := 0; i < len(f.tokens); i++ {
+		// The comma of a line ending in a comment goes before the comment,
+		// unless it starts the next line
+		if i+1 < len(f.tokens) && (f.tokens[i].IsLineComment() && f.tokens[i+1].Is(",") && !f.style.LeadingCommas ||
+			f.tokens[i].Is(",") && f.tokens[i+1].IsLineComment() && !f.tokens[i+1].Newline && f.style.LeadingCommas) {
+			f.tokens[i], f.tokens[i+1] = f.tokens[i+1], f.tokens[i]
+		}
+		t := f.tokens[i]
+		fr := f.frames[len(f.frames)-1]
+		if start && t.Kind != sqllex.Comment {
+			start = false
+			fr.query = t.Kind != sqllex.Word || !inlineStatements[strings.ToUpper(t.Text)]
+		}
+
+		switch {
+		case t.Kind == sqllex.Comment:
+			f.comment(i)
+			continue
+		case t.Is(";"):
+			f.write(i, false)
+			f.frames = []*frame{{query: true}}
+			f.breakLine(0)
+			f.pending = 2
+			start = true
+			continue
+		case t.Is("("), t.Is("["):
+			f.write(i, f.spaced(t))
+			if j := f.following(i); t.Is("(") && j < len(f.tokens) && isQueryStart(f.tokens[j]) {
+				f.frames = append(f.frames, &frame{query: true, indent: f.level + 1, close: f.level})
+				f.breakLine(f.level + 1)
+			} else {
+				f.frames = append(f.frames, &frame{})
+			}
+			continue
+		case t.Is(")"), t.Is("]"):
+			if len(f.frames) > 1 {
+				f.frames = f.frames[:len(f.frames)-1]
+				if fr.query {
+					f.breakLine(fr.close)
+				}
+			}
+			f.write(i, false)
+			continue
+		case !fr.query:
+			f.write(i, f.spaced(t))
+			continue
+		}
+
+		if name, n := clauseAt(f.tokens, i, fr); name != "" {
+			f.startClause(i, n, name, fr)
+			i += n - 1
+			continue
+		}
+		word := ""
+		if t.Kind == sqllex.Word {
+			word = strings.ToUpper(t.Text)
+		}
+		switch {
+		case t.Is(","):
+			f.comma(i, fr)
+		case word == "CASE":
+			f.write(i, f.spaced(t))
+			fr.cases = append(fr.cases, f.level)
+		case (word == "WHEN" || word == "ELSE") && len(fr.cases) > 0:
+			f.breakLine(fr.cases[len(fr.cases)-1] + 1)
+			f.write(i, true)
+		case word == "END" && len(fr.cases) > 0:
+			f.breakLine(fr.cases[len(fr.cases)-1])
+			fr.cases = fr.cases[:len(fr.cases)-1]
+			f.write(i, true)
+		case word == "BETWEEN":
+			fr.between = true
+			f.write(i, true)
+		case word == "AND" && fr.between:
+			fr.between = false
+			f.write(i, true)
+		case (word == "AND" || word == "OR") && len(fr.cases) == 0 && conditionClauses[fr.clause]:
+			f.breakLine(fr.indent + 1)
+			f.write(i, true)
+		default:
+			f.write(i, f.spaced(t))
+		}
+	}
+	return f.out.String()
+}
+
+// startClause writes the n words of the clause at i on a line of their own
+func (f *formatter) startClause(i, n int, name string, fr *frame) {
+	f.breakLine(fr.indent)
+	for k := 0; k < n; k++ {
+		f.write(i+k, k > 0 || f.spaced(f.tokens[i]))
+	}
+	*fr = frame{query: true, indent: fr.indent, close: fr.close, clause: name}
+	switch {
+	case listClauses[name] && countItems(f.tokens, i+n) > 1:
+		fr.list = true
+		f.breakLine(fr.indent + 1)
+	case name == "UNION" || name == "INTERSECT" || name == "EXCEPT":
+		f.breakLine(fr.indent)
+	}
+}
+
+// comma ends an item of a list clause or a WITH query, starting a line for
+// the next
+func (f *formatter) comma(i int, fr *frame) {
+	var level int
+	switch {
+	case fr.list && len(fr.cases) == 0:
+		level = fr.indent + 1
+	case fr.clause == "WITH":
+		level = fr.indent
+	default:
+		f.write(i, false)
+		return
+	}
+	if f.style.LeadingCommas {
+		f.breakLine(level)
+		f.write(i, false)
+	} else {
+		f.write(i, false)
+		f.breakLine(level)
+	}
+}
+
+// comment writes a comment, keeping it on a line of its own if it was, or
+// at the end of the line it ended. A line comment ends its line.
+func (f *formatter) comment(i int) {
+	t := f.tokens[i]
+	if f.compact {
+		text := t.Text
+		if t.IsLineComment() {
+			text = "/* " + strings.ReplaceAll(strings.TrimSpace(strings.TrimPrefix(text, "--")), "*/", "* /") + " */"
+		}
+		f.emit(t, text, true)
+		return
+	}
+	if !t.Newline && f.pending > 0 && f.out.Len() > 0 {
+		// A comment at the end of a line stays there
+		pending, next := f.pending, f.next
+		f.pending = 0
+		f.write(i, true)
+		f.pending, f.next = pending, next
+		return
+	}
+	if t.Newline && f.pending == 0 {
+		f.breakLine(f.level)
+	}
+	f.write(i, true)
+	if t.IsLineComment() || i+1 < len(f.tokens) && f.tokens[i+1].Newline {
+		f.breakLine(f.level)
+	}
+}
+
+// breakLine starts a line at level before the next token
+func (f *formatter) breakLine(level int) {
+	f.pending = max(f.pending, 1)
+	f.next = level
+}
+
+// write writes the token at i in the case of the style
+func (f *formatter) write(i int, space bool) {
+	f.emit(f.tokens[i], f.text(i), space)
+}
+
+// emit writes text for t after any pending line breaks, or after a space
+func (f *formatter) emit(t sqllex.Token, text string, space bool) {
+	switch {
+	case f.pending > 0 && !f.compact:
+		if f.out.Len() > 0 {
+			f.out.WriteString(strings.Repeat("\n", f.pending))
+		}
+		f.out.WriteString(strings.Repeat(" ", f.next*f.indent()))
+	case space && f.out.Len() > 0:
+		f.out.WriteByte(' ')
+	}
+	if f.pending > 0 {
+		f.level = f.next
+		f.pending = 0
+	}
+	f.out.WriteString(text)
+	f.unary = (t.Is("-") || t.Is("+")) && f.startsOperand()
+	f.prev = &t
+}
+
+// text is the token at i, in the style's case if it is a keyword. Keywords
+// qualified by a dot, or called like functions, are names and keep theirs.
+func (f *formatter) text(i int) string {
+	t := f.tokens[i]
+	word := strings.ToUpper(t.Text)
+	if t.Kind != sqllex.Word || !keywords[word] || f.style.KeywordCase == Preserve {
+		return t.Text
+	}
+	if f.prev != nil && f.prev.Is(".") {
+		return t.Text
+	}
+	if i+1 < len(f.tokens) {
+		if next := f.tokens[i+1]; next.Is(".") || next.Is("(") && !next.Space && !spacedKeywords[word] {
+			return t.Text
+		}
+	}
+	if f.style.KeywordCase == Lower {
+		return strings.ToLower(t.Text)
+	}
+	return word
+}
+
+// spaced reports whether a space goes between the last token and t
+func (f *formatter) spaced(t sqllex.Token) bool {
+	p := f.prev
+	switch {
+	case p == nil:
+		return false
+	case t.Is(",") || t.Is(")") || t.Is("]") || t.Is(";"):
+		return false
+	case p.Is("(") || p.Is("[") || p.Is(".") || f.unary:
+		return false
+	case t.Kind == sqllex.Punct && !punctuation[t.Text], p.Kind == sqllex.Punct && !punctuation[p.Text]:
+		return t.Space
+	case t.Is("."), t.Is("["):
+		return !(p.Kind == sqllex.Word || p.Kind == sqllex.Quoted || p.Is(")") || p.Is("]")) && t.Space
+	case t.Is("("):
+		if p.Kind == sqllex.Word && !spacedKeywords[strings.ToUpper(p.Text)] || p.Kind == sqllex.Quoted {
+			return t.Space
+		}
+	case t.Kind == sqllex.String && p.Kind == sqllex.Word:
+		// X'0A' is a literal, not a name and a string
+		return t.Space
+	}
+	return true
+}
+
+// startsOperand reports whether what comes after the last token is the
+// start of an operand, making a + or - there a sign
+func (f *formatter) startsOperand() bool {
+	p := f.prev
+	switch {
+	case p == nil, p.Kind == sqllex.Comment:
+		return true
+	case p.Kind == sqllex.Punct:
+		return !p.Is(")") && !p.Is("]")
+	case p.Kind == sqllex.Word:
+		word := strings.ToUpper(p.Text)
+		return keywords[word] && word != "END" && word != "NULL" && word != "TRUE" && word != "FALSE"
+	}
+	return false
+}
+
+func (f *formatter) indent() int {
+	if f.style.Indent > 0 {
+		return f.style.Indent
+	}
+	return 2
+}
+
+// following returns the index of the first token after i that isn't a
+// comment
+func (f *formatter) following(i int) int {
+	for i++; i < len(f.tokens) && f.tokens[i].Kind == sqllex.Comment; i++ {
+	}
+	return i
+}
+
+// isQueryStart reports whether a parenthesis opening with t holds a query
+func isQueryStart(t sqllex.Token) bool {
+	word := strings.ToUpper(t.Text)
+	return t.Kind == sqllex.Word && (word == "SELECT" || word == "WITH" || word == "VALUES")
+}
+
+// clauseAt returns the clause of a query that starts at i, e.g. GROUP BY or
+// JOIN for LEFT OUTER JOIN, and how many words its keywords take
+func clauseAt(tokens []sqllex.Token, i int, fr *frame) (string, int) {
+	word := upperWord(tokens, i)
+	next := upperWord(tokens, i+1)
+	switch word {
+	case "SELECT":
+		if next == "DISTINCT" || next == "ALL" {
+			return word, 2
+		}
+		return word, 1
+	case "FROM":
+		if fr.clause != "DELETE" {
+			return word, 1
+		}
+	case "WHERE", "HAVING", "LIMIT", "OFFSET", "FETCH", "WINDOW", "VALUES", "UPDATE", "DELETE":
+		return word, 1
+	case "SET":
+		if fr.clause == "UPDATE" {
+			return word, 1
+		}
+	case "GROUP", "ORDER":
+		if next == "BY" {
+			return word + " BY", 2
+		}
+	case "UNION", "INTERSECT", "EXCEPT":
+		if next == "ALL" || next == "DISTINCT" {
+			return word, 2
+		}
+		return word, 1
+	case "WITH":
+		if next == "RECURSIVE" {
+			return word, 2
+		}
+		// Not the WITH of table properties, WITH ORDINALITY or WITH TIES
+		if i+2 < len(tokens) && (tokens[i+1].Kind == sqllex.Word || tokens[i+1].Kind == sqllex.Quoted) &&
+			next != "ORDINALITY" && (upperWord(tokens, i+2) == "AS" || tokens[i+2].Is("(")) {
+			return word, 1
+		}
+	case "JOIN":
+		return word, 1
+	case "LEFT", "RIGHT", "FULL", "INNER", "CROSS", "NATURAL":
+		for n := 1; i+n < len(tokens); n++ {
+			switch upperWord(tokens, i+n) {
+			case "JOIN":
+				return "JOIN", n + 1
+			case "LEFT", "RIGHT", "FULL", "INNER", "OUTER":
+				continue
+			}
+			return "", 0
+		}
+	}
+	return "", 0
+}
+
+// countItems counts the comma-separated items of the clause whose items
+// start at i
+func countItems(tokens []sqllex.Token, i int) int {
+	items, depth := 1, 0
+	for ; i < len(tokens); i++ {
+		t := tokens[i]
+		switch {
+		case t.Is("("), t.Is("["):
+			depth++
+		case t.Is(")"), t.Is("]"):
+			if depth == 0 {
+				return items
+			}
+			depth--
+		case t.Is(";"):
+			return items
+		case depth > 0:
+		case t.Is(","):
+			items++
+		case t.Kind == sqllex.Word:
+			if name, _ := clauseAt(tokens, i, &frame{}); name != "" {
+				return items
+			}
+		}
+	}
+	return items
+}
+
+// upperWord returns the token at i in upper case if it is a word, or ""
+func upperWord(tokens []sqllex.Token, i int) string {
+	if i >= len(tokens) || tokens[i].Kind != sqllex.Word {
+		return ""
+	}
+	return strings.ToUpper(tokens[i].Text)
+}
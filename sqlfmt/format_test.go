@@ -0,0 +1,195 @@
+package sqlfmt
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/TFMV/trino-cli/config"
+)
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		name string
+		sql  string
+		want string
+	}{
+		{
+			"clauses and lists",
+			"select a, b, count(*) as n from t left outer join u on t.id = u.id and u.x between 1 and 2 where a > 1 and (b = 2 or c = 3) group by a, b order by n desc limit 10",
+			`SELECT
+  a,
+  b,
+  count(*) AS n
+FROM t
+LEFT OUTER JOIN u ON t.id = u.id
+  AND u.x BETWEEN 1 AND 2
+WHERE a > 1
+  AND (b = 2 OR c = 3)
+GROUP BY
+  a,
+  b
+ORDER BY n DESC
+LIMIT 10`,
+		},
+		{
+			"with, union and case",
+			"with x as (select 1 a), y as (select * from x) select * from y union all select case when a = 1 then 'x' else 'z' end from x",
+			`WITH x AS (
+  SELECT 1 a
+),
+y AS (
+  SELECT *
+  FROM x
+)
+SELECT *
+FROM y
+UNION ALL
+SELECT CASE
+  WHEN a = 1 THEN 'x'
+  ELSE 'z'
+END
+FROM x`,
+		},
+		{
+			"subqueries",
+			"select coalesce((select max(a) from t), 0) as m, sum(case when a then 1 else 0 end) from t where b in (select b from u)",
+			`SELECT
+  coalesce((
+    SELECT max(a)
+    FROM t
+  ), 0) AS m,
+  sum(CASE WHEN a THEN 1 ELSE 0 END)
+FROM t
+WHERE b IN (
+  SELECT b
+  FROM u
+)`,
+		},
+		{
+			"comments",
+			"-- header\nselect a -- first\n, b /* second */ from t",
+			`-- header
+SELECT
+  a, -- first
+  b /* second */
+FROM t`,
+		},
+		{
+			"statements",
+			"show tables from hive.web like 'page%'; use hive.web; insert into t (a, b) values (1, 2), (3, 4);",
+			`SHOW TABLES FROM hive.web LIKE 'page%';
+
+USE hive.web;
+
+INSERT INTO t (a, b)
+VALUES
+  (1, 2),
+  (3, 4);`,
+		},
+		{
+			"update and delete",
+			"update t set a = 1, b = 2 where c = 3; delete from t where a = 1",
+			`UPDATE t
+SET a = 1, b = 2
+WHERE c = 3;
+
+DELETE FROM t
+WHERE a = 1`,
+		},
+		{
+			"operators and literals",
+			"select -1, a-1, 1e-5, 'it''s', X'0A', \"Select\", ARRAY[1,2][1], x->x+1 from t where x<>1 or y != -2",
+			`SELECT
+  -1,
+  a - 1,
+  1e-5,
+  'it''s',
+  X'0A',
+  "Select",
+  ARRAY[1, 2][1],
+  x -> x + 1
+FROM t
+WHERE x <> 1
+  OR y != -2`,
+		},
+		{
+			"names that are keywords",
+			"select cast(x as varchar), year(ts), t.date from unnest(a) with ordinality as u(x, n)",
+			`SELECT
+  cast(x AS varchar),
+  year(ts),
+  t.date
+FROM unnest(a) WITH ORDINALITY AS u(x, n)`,
+		},
+		{
+			"text it doesn't know",
+			"select ${column}, {{ value }} from ${table:orders} where s = 'unterminated",
+			`SELECT
+  ${column},
+  {{ value }}
+FROM ${table:orders}
+WHERE s = 'unterminated`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Format(tt.sql, Style{})
+			if got != tt.want {
+				t.Errorf("Format() =\n%s\nwant\n%s", got, tt.want)
+			}
+			if again := Format(got, Style{}); again != got {
+				t.Errorf("formatting again gives\n%s", again)
+			}
+		})
+	}
+}
+
+func TestFormatStyle(t *testing.T) {
+	sql := "SELECT a, -- first\n b FROM t WHERE x = 1"
+	style := Style{KeywordCase: Lower, Indent: 4, LeadingCommas: true}
+	want := `select
+    a -- first
+    , b
+from t
+where x = 1`
+	if got := Format(sql, style); got != want {
+		t.Errorf("Format() =\n%s\nwant\n%s", got, want)
+	}
+
+	if got := Format("Select a From t", Style{KeywordCase: Preserve}); got != "Select a\nFrom t" {
+		t.Errorf("preserved case: got %q", got)
+	}
+}
+
+func TestCompact(t *testing.T) {
+	sql := "select a, -- first\n  b\nfrom (select 1) t\nwhere x = 1\n  and y = 2;\nselect 2"
+	want := "SELECT a, /* first */ b FROM (SELECT 1) t WHERE x = 1 AND y = 2; SELECT 2"
+	if got := Compact(sql, Style{}); got != want {
+		t.Errorf("Compact() = %q, want %q", got, want)
+	}
+	if got := Compact(Format(sql, Style{}), Style{}); got != want {
+		t.Errorf("Compact(Format()) = %q, want %q", got, want)
+	}
+	if strings.Contains(Compact("select 1 -- a */ b", Style{}), "*/ b") {
+		t.Error("a */ in a line comment ended the block comment")
+	}
+}
+
+func TestConfigured(t *testing.T) {
+	style, err := Configured(config.SQLFormat{KeywordCase: "Lower", Indent: 4, LeadingCommas: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if style != (Style{KeywordCase: Lower, Indent: 4, LeadingCommas: true}) {
+		t.Errorf("Configured() = %+v", style)
+	}
+	if style, _ := Configured(config.SQLFormat{}); style.KeywordCase != Upper {
+		t.Errorf("default keyword case = %q, want upper", style.KeywordCase)
+	}
+	if _, err := Configured(config.SQLFormat{KeywordCase: "title"}); err == nil {
+		t.Error("expected an error for an unknown keyword case")
+	}
+	if _, err := Configured(config.SQLFormat{Indent: -1}); err == nil {
+		t.Error("expected an error for a negative indent")
+	}
+}
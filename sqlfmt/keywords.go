@@ -0,0 +1,47 @@
+package sqlfmt
+
+import "strings"
+
+// keywords are the reserved and non-reserved words of Trino's grammar whose
+// case Format changes. Types and function names keep the case they were
+// written in.
+var keywords = wordSet(`
+	ADD ALL ALTER ANALYZE AND ANY ARRAY AS ASC AT BERNOULLI BETWEEN BY CALL
+	CASCADE CASE CAST CATALOG CATALOGS COLUMN COLUMNS COMMENT COMMIT
+	CONSTRAINT CREATE CROSS CUBE CURRENT CURRENT_DATE CURRENT_TIME
+	CURRENT_TIMESTAMP CURRENT_USER DATA DATE DAY DEALLOCATE DEFAULT DELETE
+	DESC DESCRIBE DISTINCT DROP ELSE END ESCAPE EXCEPT EXECUTE EXISTS EXPLAIN
+	EXTRACT FALSE FETCH FILTER FIRST FOLLOWING FOR FORMAT FROM FULL FUNCTIONS
+	GRANT GROUP GROUPING HAVING HOUR IF IN INNER INSERT INTERSECT INTERVAL
+	INTO IS JOIN LAST LATERAL LEFT LIKE LIMIT LOCALTIME LOCALTIMESTAMP MAP
+	MATERIALIZED MERGE MINUTE MONTH NATURAL NEXT NO NOT NULL NULLS OFFSET ON
+	ONLY OR ORDER ORDINALITY OUTER OVER PARTITION PRECEDING PREPARE
+	PROPERTIES RANGE RECURSIVE RENAME REPLACE RESET REVOKE RIGHT ROLLBACK
+	ROLLUP ROW ROWS SCHEMA SCHEMAS SECOND SELECT SESSION SET SETS SHOW SOME
+	START STATS SYSTEM TABLE TABLES TABLESAMPLE THEN TIES TIME TIMESTAMP TO
+	TRANSACTION TRUE TRY_CAST UNBOUNDED UNION UNNEST UPDATE USE USING VALUES
+	VIEW WHEN WHERE WINDOW WITH WITHIN WITHOUT YEAR ZONE
+`)
+
+// spacedKeywords are keywords a parenthesis after is set apart from, unlike
+// those used as functions such as cast(x AS varchar) or year(ts)
+var spacedKeywords = wordSet(`
+	ALL AND ANY AS BETWEEN BY CASE DISTINCT ELSE EXCEPT EXISTS FILTER FROM
+	GROUP IN INTERSECT IS JOIN LATERAL LIKE NOT ON OR OVER SELECT SOME THEN
+	UNION USING VALUES WHEN WHERE WITH
+`)
+
+// inlineStatements start statements that are written on one line, their
+// FROM and LIKE being no clauses of a query
+var inlineStatements = wordSet(`
+	ALTER CALL COMMENT COMMIT DEALLOCATE DESCRIBE DROP GRANT RENAME RESET
+	REVOKE ROLLBACK SET SHOW START USE
+`)
+
+func wordSet(words string) map[string]bool {
+	set := make(map[string]bool)
+	for _, w := range strings.Fields(words) {
+		set[w] = true
+	}
+	return set
+}
@@ -0,0 +1,168 @@
+// Package sqllex splits SQL into tokens for the tools that read it without
+// parsing it: autocompletion, formatting, highlighting and splitting scripts
+// into statements. It never fails: unterminated strings, quoted identifiers
+// and comments run to the end of the input, so every byte but whitespace
+// belongs to a token.
+package sqllex
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Kind classifies tokens
+type Kind int
+
+const (
+	Word        Kind = iota // Keyword or unquoted identifier
+	Quoted                  // Double-quoted identifier
+	String                  // Single-quoted string literal
+	Number                  // Numeric literal
+	Comment                 // -- line or /* block */ comment
+	Punct                   // Operator or punctuation, e.g. ( ) , ; . <=
+	Placeholder             // A snippet's ${name} or ${name:default}
+)
+
+// Token is a lexical unit of SQL with its byte offsets and the whitespace
+// before it
+type Token struct {
+	Kind    Kind
+	Text    string
+	Start   int
+	End     int
+	Open    bool // A string, quoted identifier or block comment missing its closing delimiter
+	Space   bool // Whitespace comes before it
+	Newline bool // A line break comes before it
+}
+
+// Is reports whether t is the punctuation p
+func (t Token) Is(p string) bool {
+	return t.Kind == Punct && t.Text == p
+}
+
+// IsName reports whether t can be part of a (possibly qualified) name
+func (t Token) IsName() bool {
+	return t.Kind == Word || t.Kind == Quoted
+}
+
+// IsLineComment reports whether t is a -- comment, which ends its line
+func (t Token) IsLineComment() bool {
+	return t.Kind == Comment && strings.HasPrefix(t.Text, "--")
+}
+
+// Contains reports whether offset falls inside t rather than at one of its
+// edges. Tokens left open by the end of the input, and line comments, also
+// contain their end.
+func (t Token) Contains(offset int) bool {
+	if t.Start < offset && offset < t.End {
+		return true
+	}
+	return offset == t.End && (t.Open || t.IsLineComment())
+}
+
+// operators are the punctuation of more than one character
+var operators = []string{"<=", ">=", "<>", "!=", "||", "->", "=>"}
+
+// Tokenize splits sql into tokens, skipping whitespace
+func Tokenize(sql string) []Token {
+	var tokens []Token
+	space, newline := false, false
+	i := 0
+	for i < len(sql) {
+		r, size := utf8.DecodeRuneInString(sql[i:])
+		if unicode.IsSpace(r) {
+			space = true
+			newline = newline || r == '\n'
+			i += size
+			continue
+		}
+
+		start := i
+		kind := Punct
+		open := false
+		switch {
+		case strings.HasPrefix(sql[i:], "--"):
+			kind = Comment
+			for i < len(sql) && sql[i] != '\n' {
+				i++
+			}
+		case strings.HasPrefix(sql[i:], "/*"):
+			kind = Comment
+			if end := strings.Index(sql[i+2:], "*/"); end >= 0 {
+				i += end + 4
+			} else {
+				i, open = len(sql), true
+			}
+		case strings.HasPrefix(sql[i:], "${"):
+			kind = Placeholder
+			if end := strings.IndexByte(sql[i:], '}'); end >= 0 {
+				i += end + 1
+			} else {
+				i, open = len(sql), true
+			}
+		case r == '\'' || r == '"':
+			kind = String
+			if r == '"' {
+				kind = Quoted
+			}
+			i, open = scanQuoted(sql, i, sql[i])
+		case unicode.IsDigit(r):
+			kind = Number
+			for i < len(sql) && (isWordByte(sql[i]) || sql[i] == '.' ||
+				(sql[i] == '-' || sql[i] == '+') && (sql[i-1] == 'e' || sql[i-1] == 'E')) {
+				i++
+			}
+		case isIdentifierRune(r):
+			kind = Word
+			for i < len(sql) {
+				r, size := utf8.DecodeRuneInString(sql[i:])
+				if !isIdentifierRune(r) && !unicode.IsDigit(r) {
+					break
+				}
+				i += size
+			}
+		default:
+			i += size
+			for _, op := range operators {
+				if strings.HasPrefix(sql[start:], op) {
+					i = start + len(op)
+					break
+				}
+			}
+		}
+
+		tokens = append(tokens, Token{
+			Kind: kind, Text: sql[start:i], Start: start, End: i,
+			Open: open, Space: space, Newline: newline,
+		})
+		space, newline = false, false
+	}
+	return tokens
+}
+
+// scanQuoted returns the offset just past the quoted text starting at i,
+// where a doubled quote stands for itself, and whether it is unterminated
+func scanQuoted(sql string, i int, quote byte) (int, bool) {
+	for i++; i < len(sql); i++ {
+		if sql[i] != quote {
+			continue
+		}
+		if i+1 < len(sql) && sql[i+1] == quote {
+			i++
+			continue
+		}
+		return i + 1, false
+	}
+	return len(sql), true
+}
+
+// isIdentifierRune reports whether r can start an unquoted identifier
+func isIdentifierRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r)
+}
+
+// isWordByte reports whether b can continue a number such as 1e10 or 0x1F
+func isWordByte(b byte) bool {
+	return b == '_' || 'a' <= b && b <= 'z' || 'A' <= b && b <= 'Z' || '0' <= b && b <= '9'
+}
@@ -0,0 +1,75 @@
+package sqllex
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		sql  string
+		want []string
+	}{
+		{
+			`SELECT "a""b".c, 'it''s' -- note
+FROM t /* x */ WHERE n >= 1.5`,
+			[]string{"SELECT", `"a""b"`, ".", "c", ",", "'it''s'", "-- note",
+				"FROM", "t", "/* x */", "WHERE", "n", ">=", "1.5"},
+		},
+		{"x<>1e-5||y", []string{"x", "<>", "1e-5", "||", "y"}},
+		{"SELECT * FROM ${table} LIMIT ${n:10}", []string{"SELECT", "*", "FROM", "${table}", "LIMIT", "${n:10}"}},
+		{"SELECT größe_1 FROM t", []string{"SELECT", "größe_1", "FROM", "t"}},
+		{"", nil},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, tok := range Tokenize(tt.sql) {
+			if tok.Text != tt.sql[tok.Start:tok.End] {
+				t.Errorf("Tokenize(%q): %q has offsets of %q", tt.sql, tok.Text, tt.sql[tok.Start:tok.End])
+			}
+			got = append(got, tok.Text)
+		}
+		if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+			t.Errorf("Tokenize(%q) = %q, want %q", tt.sql, got, tt.want)
+		}
+	}
+}
+
+func TestTokenizeWhitespace(t *testing.T) {
+	tokens := Tokenize("SELECT a,b\n  FROM t")
+	want := []struct{ space, newline bool }{{false, false}, {true, false}, {false, false}, {false, false}, {true, true}, {true, false}}
+	if len(tokens) != len(want) {
+		t.Fatalf("got %d tokens, want %d", len(tokens), len(want))
+	}
+	for i, w := range want {
+		if tokens[i].Space != w.space || tokens[i].Newline != w.newline {
+			t.Errorf("%q: space %v newline %v, want %v %v", tokens[i].Text, tokens[i].Space, tokens[i].Newline, w.space, w.newline)
+		}
+	}
+}
+
+func TestTokenizeOpen(t *testing.T) {
+	tests := []struct {
+		sql  string
+		kind Kind
+		open bool
+	}{
+		{"SELECT 'abc", String, true},
+		{`SELECT "ab`, Quoted, true},
+		{"SELECT 1 /* note", Comment, true},
+		{"SELECT ${n", Placeholder, true},
+		{"SELECT 1 -- note", Comment, false},
+		{"SELECT 'abc'", String, false},
+	}
+	for _, tt := range tests {
+		tokens := Tokenize(tt.sql)
+		last := tokens[len(tokens)-1]
+		if last.Kind != tt.kind || last.Open != tt.open {
+			t.Errorf("Tokenize(%q) ends with %+v, want kind %v open %v", tt.sql, last, tt.kind, tt.open)
+		}
+		// Open tokens and line comments run on at the end of the input
+		if want := tt.open || last.IsLineComment(); last.Contains(len(tt.sql)) != want {
+			t.Errorf("Tokenize(%q): Contains(end) = %v, want %v", tt.sql, !want, want)
+		}
+	}
+}
@@ -0,0 +1,254 @@
+package ui
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/TFMV/trino-cli/engine"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// Chart kinds, switched with t in the chart view
+const (
+	chartBar  = "bar"
+	chartLine = "line"
+)
+
+// maxChartBars caps the rows drawn as bars; the rest are noted in the title
+const maxChartBars = 1000
+
+// barEighths are the partial blocks that end a bar, by eighths of a cell
+var barEighths = []rune{' ', '▏', '▎', '▍', '▌', '▋', '▊', '▉'}
+
+// chartData is one series: a label (from the x column) and a value (from
+// the y column) per row. Rows whose value is NULL or not a number are
+// skipped.
+type chartData struct {
+	labels  []string
+	values  []float64
+	skipped int
+}
+
+// chartSeries takes the x and y columns of result as a series
+func chartSeries(result *engine.QueryResult, x, y int) chartData {
+	var data chartData
+	numeric := y < len(result.Types) && isNumericType(result.Types[y])
+	var labelType string
+	if x < len(result.Types) {
+		labelType = result.Types[x]
+	}
+	for _, row := range result.Rows {
+		var value interface{}
+		if y < len(row) {
+			value = row[y]
+		}
+		f, ok := toFloat(value, numeric)
+		if !ok || math.IsNaN(f) || math.IsInf(f, 0) {
+			data.skipped++
+			continue
+		}
+		var label interface{}
+		if x < len(row) {
+			label = row[x]
+		}
+		data.labels = append(data.labels, currentFormat.text(label, labelType))
+		data.values = append(data.values, f)
+	}
+	return data
+}
+
+// chartableColumns returns the columns with numbers to plot: numeric types,
+// or native numbers when the type is unknown
+func chartableColumns(result *engine.QueryResult) []int {
+	var columns []int
+	for column := range result.Columns {
+		if column < len(result.Types) && isNumericType(result.Types[column]) {
+			columns = append(columns, column)
+			continue
+		}
+		for _, row := range result.Rows {
+			if column < len(row) && row[column] != nil {
+				if _, ok := toFloat(row[column], false); ok {
+					columns = append(columns, column)
+				}
+				break
+			}
+		}
+	}
+	return columns
+}
+
+// formatChartValue prints a value compactly for axis and bar labels
+func formatChartValue(v float64) string {
+	if v == math.Trunc(v) && math.Abs(v) < 1e15 {
+		return strconv.FormatFloat(v, 'f', 0, 64)
+	}
+	return strconv.FormatFloat(v, 'g', 6, 64)
+}
+
+// fitLabel truncates or pads s to exactly width runes
+func fitLabel(s string, width int) string {
+	runes := []rune(strings.Join(strings.Fields(s), " "))
+	if len(runes) > width {
+		if width <= 1 {
+			return string(runes[:width])
+		}
+		return string(runes[:width-1]) + "…"
+	}
+	return s + strings.Repeat(" ", width-len(runes))
+}
+
+// barChart draws one horizontal bar per value, scaled to the largest
+// magnitude, with its label on the left and its value on the right
+func barChart(data chartData, width int) string {
+	if len(data.values) == 0 {
+		return "No numeric values to chart."
+	}
+	values, labels := data.values, data.labels
+	if len(values) > maxChartBars {
+		values, labels = values[:maxChartBars], labels[:maxChartBars]
+	}
+
+	labelWidth, valueWidth, largest := 0, 0, 0.0
+	for i, v := range values {
+		labelWidth = max(labelWidth, len([]rune(labels[i])))
+		valueWidth = max(valueWidth, len(formatChartValue(v)))
+		largest = max(largest, math.Abs(v))
+	}
+	labelWidth = min(labelWidth, max(width/3, 1))
+	barWidth := max(width-labelWidth-valueWidth-4, 1)
+
+	var out strings.Builder
+	for i, v := range values {
+		eighths := 0
+		if largest > 0 {
+			eighths = int(math.Round(math.Abs(v) / largest * float64(barWidth*8)))
+		}
+		bar := strings.Repeat("█", eighths/8)
+		if eighths%8 > 0 {
+			bar += string(barEighths[eighths%8])
+		}
+		fmt.Fprintf(&out, "%s │%s %s\n", fitLabel(labels[i], labelWidth), bar, formatChartValue(v))
+	}
+	return strings.TrimSuffix(out.String(), "\n")
+}
+
+// lineChart plots the values left to right in a width x height grid with
+// the range on the y axis and the first and last labels under the x axis.
+// When there are more values than columns, each column shows their mean.
+func lineChart(data chartData, width, height int) string {
+	if len(data.values) == 0 {
+		return "No numeric values to chart."
+	}
+	lo, hi := data.values[0], data.values[0]
+	for _, v := range data.values {
+		lo, hi = min(lo, v), max(hi, v)
+	}
+	top, bottom := formatChartValue(hi), formatChartValue(lo)
+	axisWidth := max(len(top), len(bottom))
+	plotWidth := max(width-axisWidth-2, 2)
+	plotHeight := max(height-2, 2)
+
+	// One point per column when the values outnumber the columns,
+	// otherwise spread the values across the width
+	type point struct{ x, row int }
+	rowOf := func(v float64) int {
+		if hi == lo {
+			return plotHeight / 2
+		}
+		return int(math.Round((v - lo) / (hi - lo) * float64(plotHeight-1)))
+	}
+	var points []point
+	if n := len(data.values); n > plotWidth {
+		for x := 0; x < plotWidth; x++ {
+			from, to := x*n/plotWidth, (x+1)*n/plotWidth
+			sum := 0.0
+			for _, v := range data.values[from:to] {
+				sum += v
+			}
+			points = append(points, point{x, rowOf(sum / float64(to-from))})
+		}
+	} else {
+		for i, v := range data.values {
+			x := 0
+			if n > 1 {
+				x = i * (plotWidth - 1) / (n - 1)
+			}
+			points = append(points, point{x, rowOf(v)})
+		}
+	}
+
+	grid := make([][]rune, plotHeight)
+	for i := range grid {
+		grid[i] = []rune(strings.Repeat(" ", plotWidth))
+	}
+	for i, p := range points {
+		if i > 0 {
+			// Join neighbouring points with dots along the straight line
+			prev := points[i-1]
+			for x := prev.x + 1; x < p.x; x++ {
+				row := prev.row + int(math.Round(float64((p.row-prev.row)*(x-prev.x))/float64(p.x-prev.x)))
+				grid[plotHeight-1-row][x] = '·'
+			}
+		}
+		grid[plotHeight-1-p.row][p.x] = '•'
+	}
+
+	var out strings.Builder
+	for i, line := range grid {
+		label, tick := "", '│'
+		switch i {
+		case 0:
+			label, tick = top, '┤'
+		case plotHeight - 1:
+			label, tick = bottom, '┤'
+		}
+		fmt.Fprintf(&out, "%*s %c%s\n", axisWidth, label, tick, string(line))
+	}
+	fmt.Fprintf(&out, "%*s └%s\n", axisWidth, "", strings.Repeat("─", plotWidth))
+
+	first, last := data.labels[0], data.labels[len(data.labels)-1]
+	xAxis := fitLabel(first, plotWidth)
+	if len(data.labels) > 1 {
+		lastWidth := min(len([]rune(last)), plotWidth/2)
+		xAxis = fitLabel(first, plotWidth-lastWidth) + fitLabel(last, lastWidth)
+	}
+	fmt.Fprintf(&out, "%*s  %s", axisWidth, "", strings.TrimRight(xAxis, " "))
+	return out.String()
+}
+
+// chartView draws a chart sized to the space it is given, redrawing it when
+// that changes
+type chartView struct {
+	*tview.TextView
+	render        func(width, height int) string
+	width, height int
+}
+
+func newChartView(render func(width, height int) string) *chartView {
+	view := tview.NewTextView().
+		SetScrollable(true).
+		SetWrap(false)
+	view.SetBorder(true).
+		SetTitleAlign(tview.AlignLeft).
+		SetBorderPadding(0, 0, 1, 1)
+	return &chartView{TextView: view, render: render}
+}
+
+// refresh redraws the chart on the next draw, e.g. after the columns change
+func (c *chartView) refresh() {
+	c.width, c.height = 0, 0
+}
+
+// Draw renders the chart for the current size, then draws it
+func (c *chartView) Draw(screen tcell.Screen) {
+	_, _, width, height := c.GetInnerRect()
+	if width != c.width || height != c.height {
+		c.width, c.height = width, height
+		c.SetText(c.render(width, height)).ScrollToBeginning()
+	}
+	c.TextView.Draw(screen)
+}
@@ -0,0 +1,77 @@
+package ui
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/TFMV/trino-cli/engine"
+)
+
+func TestChartSeries(t *testing.T) {
+	result := &engine.QueryResult{
+		Columns: []string{"day", "total", "note"},
+		Types:   []string{"DATE", "DECIMAL(10,2)", "VARCHAR"},
+		Rows: [][]interface{}{
+			{"2024-01-01", "12.50", "a"},
+			{"2024-01-02", nil, "12"},
+			{nil, "3", "b"},
+		},
+	}
+	data := chartSeries(result, 0, 1)
+	if !reflect.DeepEqual(data.labels, []string{"2024-01-01", "NULL"}) || !reflect.DeepEqual(data.values, []float64{12.5, 3}) || data.skipped != 1 {
+		t.Errorf("chartSeries = %+v", data)
+	}
+
+	// DECIMAL strings chart; VARCHAR digits don't
+	if got := chartableColumns(result); !reflect.DeepEqual(got, []int{1}) {
+		t.Errorf("chartableColumns = %v, want [1]", got)
+	}
+	untyped := &engine.QueryResult{Columns: []string{"a", "b"}, Rows: [][]interface{}{{"x", nil}, {"y", int64(2)}}}
+	if got := chartableColumns(untyped); !reflect.DeepEqual(got, []int{1}) {
+		t.Errorf("chartableColumns without types = %v, want [1]", got)
+	}
+}
+
+func TestBarChart(t *testing.T) {
+	chart := barChart(chartData{labels: []string{"north", "south"}, values: []float64{10, 5}}, 24)
+	want := "north │█████████████ 10\n" +
+		"south │██████▌ 5"
+	if chart != want {
+		t.Errorf("barChart =\n%s\nwant\n%s", chart, want)
+	}
+
+	// Partial cells use the eighth blocks
+	chart = barChart(chartData{labels: []string{"a", "b"}, values: []float64{16, 1}}, 15)
+	if lines := strings.Split(chart, "\n"); lines[1] != "b │▌ 1" {
+		t.Errorf("small bar = %q", lines[1])
+	}
+
+	if got := barChart(chartData{}, 40); got != "No numeric values to chart." {
+		t.Errorf("empty chart = %q", got)
+	}
+}
+
+func TestLineChart(t *testing.T) {
+	chart := lineChart(chartData{labels: []string{"mon", "tue", "wed"}, values: []float64{0, 10, 5}}, 12, 5)
+	want := "10 ┤   •·   \n" +
+		"   │ ··  ··•\n" +
+		" 0 ┤•       \n" +
+		"   └────────\n" +
+		"    mon  wed"
+	if chart != want {
+		t.Errorf("lineChart =\n%s\nwant\n%s", chart, want)
+	}
+
+	// More values than columns are averaged into one point per column
+	values := make([]float64, 100)
+	labels := make([]string, 100)
+	for i := range values {
+		values[i] = float64(i % 2)
+		labels[i] = "x"
+	}
+	chart = lineChart(chartData{labels: labels, values: values}, 20, 6)
+	if lines := strings.Split(chart, "\n"); len(lines) != 6 || !strings.Contains(lines[2], "•") {
+		t.Errorf("averaged chart =\n%s", chart)
+	}
+}
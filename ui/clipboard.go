@@ -0,0 +1,91 @@
+package ui
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// clipboardCommands are tried in order to reach the system clipboard
+var clipboardCommands = [][]string{
+	{"pbcopy"},
+	{"wl-copy"},
+	{"xclip", "-selection", "clipboard"},
+	{"xsel", "--clipboard", "--input"},
+	{"clip.exe"},
+}
+
+// CopyToClipboard puts text on the system clipboard and describes how. When
+// no clipboard tool is installed (e.g. over SSH) it falls back to the OSC 52
+// escape sequence, which most modern terminals forward to the local clipboard.
+func CopyToClipboard(text string) (string, error) {
+	for _, command := range clipboardCommands {
+		path, err := exec.LookPath(command[0])
+		if err != nil {
+			continue
+		}
+		cmd := exec.Command(path, command[1:]...)
+		cmd.Stdin = strings.NewReader(text)
+		if err := cmd.Run(); err != nil {
+			return "", fmt.Errorf("%s: %w", command[0], err)
+		}
+		return "to the clipboard", nil
+	}
+
+	sequence := "\x1b]52;c;" + base64.StdEncoding.EncodeToString([]byte(text)) + "\a"
+	if _, err := os.Stdout.WriteString(sequence); err != nil {
+		return "", err
+	}
+	return "via the terminal clipboard", nil
+}
+
+// rowCSV formats one row of values as a CSV line. NULLs become empty fields.
+func rowCSV(values []interface{}) (string, error) {
+	fields := make([]string, len(values))
+	for i, v := range values {
+		if v != nil {
+			fields[i] = fmt.Sprintf("%v", v)
+		}
+	}
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	if err := writer.Write(fields); err != nil {
+		return "", err
+	}
+	writer.Flush()
+	return buf.String(), writer.Error()
+}
+
+// rowJSON formats one row as a JSON object keyed by column name, keeping
+// the columns in result order
+func rowJSON(columns []string, values []interface{}) (string, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, column := range columns {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(column)
+		if err != nil {
+			return "", err
+		}
+		var value interface{}
+		if i < len(values) {
+			value = values[i]
+		}
+		data, err := json.Marshal(value)
+		if err != nil {
+			return "", err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(data)
+	}
+	buf.WriteByte('}')
+	return buf.String(), nil
+}
@@ -0,0 +1,54 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCopyToClipboard(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "clipboard")
+	saved := clipboardCommands
+	defer func() { clipboardCommands = saved }()
+	clipboardCommands = [][]string{
+		{"trino-cli-no-such-clipboard-tool"},
+		{"sh", "-c", "cat > " + out},
+	}
+
+	how, err := CopyToClipboard("a,b\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if how != "to the clipboard" {
+		t.Errorf("how = %q", how)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "a,b\n" {
+		t.Errorf("clipboard = %q, want %q", data, "a,b\n")
+	}
+}
+
+func TestRowCSV(t *testing.T) {
+	got, err := rowCSV([]interface{}{int64(1), "a,b", nil, `say "hi"`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "1,\"a,b\",,\"say \"\"hi\"\"\"\n"; got != want {
+		t.Errorf("rowCSV = %q, want %q", got, want)
+	}
+}
+
+func TestRowJSON(t *testing.T) {
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	got, err := rowJSON([]string{"z", "a", "when"}, []interface{}{int64(1), nil, ts})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"z":1,"a":null,"when":"2024-01-02T03:04:05Z"}`; got != want {
+		t.Errorf("rowJSON = %s, want %s", got, want)
+	}
+}
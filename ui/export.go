@@ -0,0 +1,94 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/TFMV/trino-cli/engine"
+	"github.com/rivo/tview"
+)
+
+// showExportDialog replaces the screen with a form for exporting a result to
+// a file in any registered format. displayed is the result as filtered and
+// sorted in the table; when it differs from result the form offers a choice,
+// defaulting to what is displayed. onClose is called with a status message
+// once the dialog is dismissed, after the previous root has been restored.
+func showExportDialog(app *tview.Application, root tview.Primitive, result, displayed *engine.QueryResult, onClose func(status string)) {
+	formats := engine.Formats()
+	names := make([]string, len(formats))
+	for i, format := range formats {
+		names[i] = format.Name
+	}
+
+	form := tview.NewForm()
+	fileField := tview.NewInputField().
+		SetLabel("File").
+		SetFieldWidth(40).
+		SetText("result" + formats[0].Extension)
+
+	selected := formats[0]
+	form.AddDropDown("Format", names, 0, func(option string, index int) {
+		if index < 0 {
+			return
+		}
+		// Keep the file extension in step with the chosen format
+		name := strings.TrimSuffix(fileField.GetText(), selected.Extension)
+		selected = formats[index]
+		fileField.SetText(name + selected.Extension)
+	})
+	form.AddFormItem(fileField)
+
+	export := displayed
+	height := 9
+	if displayed != result {
+		form.AddCheckbox("Only displayed rows", true, func(checked bool) {
+			export = result
+			if checked {
+				export = displayed
+			}
+		})
+		height += 2
+	}
+
+	closeDialog := func(status string) {
+		app.SetRoot(root, true)
+		onClose(status)
+	}
+
+	form.AddButton("Export", func() {
+		filename := strings.TrimSpace(fileField.GetText())
+		if filename == "" {
+			return
+		}
+		if err := selected.WriteFile(filename, export); err != nil {
+			closeDialog(fmt.Sprintf("[red]Export failed: %v", err))
+			return
+		}
+		closeDialog(fmt.Sprintf("[green]Exported %d rows to %s", len(export.Rows), filename))
+	})
+	form.AddButton("Cancel", func() {
+		closeDialog("[yellow]Export cancelled")
+	})
+	form.SetCancelFunc(func() {
+		closeDialog("[yellow]Export cancelled")
+	})
+
+	title := fmt.Sprintf(" Export %d rows ", len(result.Rows))
+	if displayed != result {
+		title = fmt.Sprintf(" Export %d of %d rows ", len(displayed.Rows), len(result.Rows))
+	}
+	form.SetBorder(true).
+		SetTitle(title).
+		SetTitleAlign(tview.AlignLeft)
+
+	// Center the form on screen
+	modal := tview.NewFlex().
+		AddItem(nil, 0, 1, false).
+		AddItem(tview.NewFlex().SetDirection(tview.FlexRow).
+			AddItem(nil, 0, 1, false).
+			AddItem(form, height, 0, true).
+			AddItem(nil, 0, 1, false), 60, 0, true).
+		AddItem(nil, 0, 1, false)
+
+	app.SetRoot(modal, true).SetFocus(form)
+}
@@ -0,0 +1,82 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/rivo/tview"
+)
+
+// editorCommand returns the user's editor from $VISUAL or $EDITOR, split
+// into the program and its arguments (e.g. "code --wait"), or vi
+func editorCommand() []string {
+	for _, name := range []string{"VISUAL", "EDITOR"} {
+		if fields := strings.Fields(os.Getenv(name)); len(fields) > 0 {
+			return fields
+		}
+	}
+	return []string{"vi"}
+}
+
+// runExternalEditor writes text to a temporary .sql file, runs command on it
+// and returns the saved contents without trailing newlines
+func runExternalEditor(text string, command []string, stdin io.Reader, stdout, stderr io.Writer) (string, error) {
+	file, err := os.CreateTemp("", "trino-cli-*.sql")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer os.Remove(file.Name())
+
+	if text != "" {
+		text += "\n"
+	}
+	if _, err := file.WriteString(text); err != nil {
+		file.Close()
+		return "", fmt.Errorf("failed to write temporary file: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return "", fmt.Errorf("failed to write temporary file: %w", err)
+	}
+
+	cmd := exec.Command(command[0], append(command[1:], file.Name())...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = stdin, stdout, stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("%s failed: %w", command[0], err)
+	}
+
+	data, err := os.ReadFile(file.Name())
+	if err != nil {
+		return "", fmt.Errorf("failed to read edited query: %w", err)
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// splitRunSuffix strips a trailing semicolon from an edited query and
+// reports whether it was there. As with psql's \e, a query saved with a
+// terminating semicolon runs as soon as the editor closes.
+func splitRunSuffix(query string) (string, bool) {
+	trimmed := strings.TrimSpace(query)
+	if !strings.HasSuffix(trimmed, ";") {
+		return query, false
+	}
+	return strings.TrimSpace(strings.TrimSuffix(trimmed, ";")), true
+}
+
+// EditInEditor opens text in the user's editor on the terminal and returns
+// the saved contents without trailing newlines
+func EditInEditor(text string) (string, error) {
+	return runExternalEditor(text, editorCommand(), os.Stdin, os.Stdout, os.Stderr)
+}
+
+// editExternally suspends the TUI while the user's editor edits text
+func editExternally(app *tview.Application, text string) (string, error) {
+	var edited string
+	var err error
+	app.Suspend(func() {
+		edited, err = EditInEditor(text)
+	})
+	return edited, err
+}
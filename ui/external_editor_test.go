@@ -0,0 +1,53 @@
+package ui
+
+import (
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestEditorCommand(t *testing.T) {
+	t.Setenv("VISUAL", "")
+	t.Setenv("EDITOR", "")
+	if got := editorCommand(); !reflect.DeepEqual(got, []string{"vi"}) {
+		t.Errorf("no editor set = %q, want vi", got)
+	}
+	t.Setenv("EDITOR", "code --wait")
+	if got := editorCommand(); !reflect.DeepEqual(got, []string{"code", "--wait"}) {
+		t.Errorf("EDITOR = %q", got)
+	}
+	t.Setenv("VISUAL", "nano")
+	if got := editorCommand(); !reflect.DeepEqual(got, []string{"nano"}) {
+		t.Errorf("VISUAL should win, got %q", got)
+	}
+}
+
+func TestRunExternalEditor(t *testing.T) {
+	// sh -c runs the script with the temporary file as $0
+	editor := []string{"sh", "-c", `cat "$0" > "$0.seen" && printf 'SELECT *\nFROM t;\n\n' > "$0" && cat "$0.seen" && rm "$0.seen"`}
+	var seen strings.Builder
+	got, err := runExternalEditor("SELECT 1", editor, strings.NewReader(""), &seen, io.Discard)
+	if err != nil {
+		t.Fatalf("runExternalEditor failed: %v", err)
+	}
+	if seen.String() != "SELECT 1\n" {
+		t.Errorf("editor saw %q", seen.String())
+	}
+	if got != "SELECT *\nFROM t;" {
+		t.Errorf("edited query = %q", got)
+	}
+
+	if _, err := runExternalEditor("", []string{"false"}, nil, io.Discard, io.Discard); err == nil {
+		t.Error("expected an error when the editor fails")
+	}
+}
+
+func TestSplitRunSuffix(t *testing.T) {
+	if query, run := splitRunSuffix("SELECT 1 ;\n"); query != "SELECT 1" || !run {
+		t.Errorf("got %q, %v", query, run)
+	}
+	if query, run := splitRunSuffix("SELECT 1"); query != "SELECT 1" || run {
+		t.Errorf("got %q, %v", query, run)
+	}
+}
@@ -0,0 +1,133 @@
+package ui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/TFMV/trino-cli/config"
+)
+
+// Default layouts for date and time values, before any fractional seconds
+const (
+	defaultDateLayout      = "2006-01-02"
+	defaultTimeLayout      = "15:04:05"
+	defaultTimestampLayout = "2006-01-02 15:04:05"
+)
+
+// defaultTimePrecision is Trino's precision for TIME and TIMESTAMP columns
+// declared without one
+const defaultTimePrecision = 3
+
+// valueFormat renders result values for display, from ui.format in the
+// config file
+type valueFormat struct {
+	null        string
+	date        string
+	time        string
+	timestamp   string
+	alignNumber bool
+}
+
+// currentFormat is the format installed by StartInteractive
+var currentFormat = newValueFormat(config.Format{})
+
+func newValueFormat(settings config.Format) valueFormat {
+	null := settings.Null
+	if null == "" {
+		null = "NULL"
+	}
+	return valueFormat{
+		null:        null,
+		date:        settings.Date,
+		time:        settings.Time,
+		timestamp:   settings.Timestamp,
+		alignNumber: !settings.LeftAlignNumbers,
+	}
+}
+
+// parseType splits a Trino type name such as TIMESTAMP(6) WITH TIME ZONE
+// into its upper-case base name, its precision (-1 when none is given) and
+// whether it carries a time zone
+func parseType(typeName string) (base string, precision int, zoned bool) {
+	name := strings.ToUpper(strings.TrimSpace(typeName))
+	zoned = strings.HasSuffix(name, " WITH TIME ZONE")
+	name = strings.TrimSuffix(name, " WITH TIME ZONE")
+	precision = -1
+	if open := strings.IndexByte(name, '('); open >= 0 {
+		if p, err := strconv.Atoi(strings.TrimSuffix(name[open+1:], ")")); err == nil {
+			precision = p
+		}
+		name = name[:open]
+	}
+	return strings.TrimSpace(name), precision, zoned
+}
+
+// fraction is the layout suffix for precision fractional-second digits
+func fraction(precision int) string {
+	if precision <= 0 {
+		return ""
+	}
+	return "." + strings.Repeat("0", min(precision, 9))
+}
+
+// formatTime renders a date, time or timestamp according to its column
+// type. Unknown types show fractional seconds only when there are some.
+func (f valueFormat) formatTime(t time.Time, typeName string) string {
+	base, precision, zoned := parseType(typeName)
+	if precision < 0 {
+		precision = defaultTimePrecision
+	}
+
+	var layout, custom string
+	switch base {
+	case "DATE":
+		layout, custom = defaultDateLayout, f.date
+	case "TIME":
+		layout, custom = defaultTimeLayout+fraction(precision), f.time
+	case "TIMESTAMP":
+		layout, custom = defaultTimestampLayout+fraction(precision), f.timestamp
+	default:
+		layout, custom = defaultTimestampLayout+".999999999", f.timestamp
+		zoned = t.Location() != time.UTC
+	}
+	if custom != "" {
+		return t.Format(custom)
+	}
+
+	text := t.Format(layout)
+	if zoned {
+		zone := t.Location().String()
+		if zone == "" || zone == "Local" {
+			zone = t.Format("-07:00")
+		}
+		text += " " + zone
+	}
+	return text
+}
+
+// text renders a result value on one line for the result table. NULL shows
+// as the configured text.
+func (f valueFormat) text(value interface{}, typeName string) string {
+	switch v := value.(type) {
+	case nil:
+		return f.null
+	case time.Time:
+		return f.formatTime(v, typeName)
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+// rightAligned reports whether a value belongs in a right-aligned numeric
+// column. Without a column type, native numbers count.
+func (f valueFormat) rightAligned(value interface{}, typeName string) bool {
+	if !f.alignNumber {
+		return false
+	}
+	if typeName == "" {
+		_, ok := toFloat(value, false)
+		return ok
+	}
+	return isNumericType(typeName)
+}
@@ -0,0 +1,112 @@
+package ui
+
+import (
+	"testing"
+	"time"
+
+	"github.com/TFMV/trino-cli/config"
+	"github.com/TFMV/trino-cli/engine"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+func TestParseType(t *testing.T) {
+	tests := []struct {
+		typeName  string
+		base      string
+		precision int
+		zoned     bool
+	}{
+		{"TIMESTAMP(6) WITH TIME ZONE", "TIMESTAMP", 6, true},
+		{"timestamp(3)", "TIMESTAMP", 3, false},
+		{"TIME", "TIME", -1, false},
+		{"DECIMAL(10,2)", "DECIMAL", -1, false},
+		{"", "", -1, false},
+	}
+	for _, tt := range tests {
+		base, precision, zoned := parseType(tt.typeName)
+		if base != tt.base || precision != tt.precision || zoned != tt.zoned {
+			t.Errorf("parseType(%q) = %q, %d, %v", tt.typeName, base, precision, zoned)
+		}
+	}
+}
+
+func TestValueFormatText(t *testing.T) {
+	ts := time.Date(2024, 3, 5, 14, 7, 9, 123456000, time.UTC)
+	tokyo := ts.In(time.FixedZone("Asia/Tokyo", 9*3600))
+
+	format := newValueFormat(config.Format{})
+	tests := []struct {
+		value    interface{}
+		typeName string
+		want     string
+	}{
+		{nil, "VARCHAR", "NULL"},
+		{int64(42), "BIGINT", "42"},
+		{ts, "DATE", "2024-03-05"},
+		{ts, "TIMESTAMP(3)", "2024-03-05 14:07:09.123"},
+		{ts, "TIMESTAMP(6)", "2024-03-05 14:07:09.123456"},
+		{ts, "TIMESTAMP(0)", "2024-03-05 14:07:09"},
+		{ts, "TIMESTAMP", "2024-03-05 14:07:09.123"},
+		{tokyo, "TIMESTAMP(3) WITH TIME ZONE", "2024-03-05 23:07:09.123 Asia/Tokyo"},
+		{ts.In(time.FixedZone("", -5*3600)), "TIMESTAMP(0) WITH TIME ZONE", "2024-03-05 09:07:09 -05:00"},
+		{ts, "TIME(3)", "14:07:09.123"},
+		{ts, "", "2024-03-05 14:07:09.123456"},
+	}
+	for _, tt := range tests {
+		if got := format.text(tt.value, tt.typeName); got != tt.want {
+			t.Errorf("text(%v, %q) = %q, want %q", tt.value, tt.typeName, got, tt.want)
+		}
+	}
+
+	custom := newValueFormat(config.Format{Null: "∅", Date: "02/01/2006", Timestamp: time.RFC3339})
+	if got := custom.text(nil, "DATE"); got != "∅" {
+		t.Errorf("custom NULL = %q", got)
+	}
+	if got := custom.text(ts, "DATE"); got != "05/03/2024" {
+		t.Errorf("custom date = %q", got)
+	}
+	if got := custom.text(tokyo, "TIMESTAMP(3) WITH TIME ZONE"); got != "2024-03-05T23:07:09+09:00" {
+		t.Errorf("custom timestamp = %q", got)
+	}
+}
+
+func TestValueFormatRightAligned(t *testing.T) {
+	format := newValueFormat(config.Format{})
+	if !format.rightAligned("12.50", "DECIMAL(10,2)") {
+		t.Error("DECIMAL columns should be right-aligned")
+	}
+	if format.rightAligned("12", "VARCHAR") {
+		t.Error("VARCHAR columns should stay left-aligned")
+	}
+	if !format.rightAligned(3.5, "") || format.rightAligned("3.5", "") {
+		t.Error("untyped columns should right-align native numbers only")
+	}
+	if newValueFormat(config.Format{LeftAlignNumbers: true}).rightAligned(int64(1), "BIGINT") {
+		t.Error("left_align_numbers should turn alignment off")
+	}
+}
+
+func TestResultPagesCellFormat(t *testing.T) {
+	pages := newResultPages(&engine.QueryResult{
+		Columns: []string{"name", "total"},
+		Types:   []string{"VARCHAR", "BIGINT"},
+		Rows:    [][]interface{}{{nil, int64(7)}},
+	})
+
+	if cell := pages.GetCell(0, 1); cell.Align != tview.AlignRight {
+		t.Error("numeric header should be right-aligned")
+	}
+	if cell := pages.GetCell(1, 1); cell.Align != tview.AlignRight || cell.Text != "7" {
+		t.Errorf("numeric cell = %q, align %d", cell.Text, cell.Align)
+	}
+
+	null := pages.GetCell(1, 0)
+	if null.Text != "NULL" || null.Align != tview.AlignLeft {
+		t.Errorf("NULL cell = %q, align %d", null.Text, null.Align)
+	}
+	fg, _, attrs := null.Style.Decompose()
+	if fg != currentTheme.nullColor() || attrs&tcell.AttrDim == 0 {
+		t.Errorf("NULL cell style = %v, %v; want dim %v", fg, attrs, currentTheme.nullColor())
+	}
+}
@@ -0,0 +1,115 @@
+package ui
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// ghostColor is the color of the suggested rest of the line, dim enough
+// not to be mistaken for typed text
+var ghostColor = tcell.ColorGray
+
+// cursorScreen records where the wrapped screen was asked to show the
+// cursor, which tview input fields do not expose otherwise
+type cursorScreen struct {
+	tcell.Screen
+	x, y int
+}
+
+func (s *cursorScreen) ShowCursor(x, y int) {
+	s.x, s.y = x, y
+	s.Screen.ShowCursor(x, y)
+}
+
+// ghostSuffix returns the rest of the line query proposes after text, or ""
+// when query does not extend text
+func ghostSuffix(text, query string) string {
+	if len(query) <= len(text) || !strings.EqualFold(query[:len(text)], text) {
+		return ""
+	}
+	rest := query[len(text):]
+	if i := strings.IndexAny(rest, "\r\n"); i >= 0 {
+		rest = rest[:i]
+	}
+	return rest
+}
+
+// setSuggester sets the lookup for the query that the text typed so far
+// most likely continues into, which returns "" when there is none
+func (h *highlightInput) setSuggester(suggest func(text string) string) {
+	h.suggest = suggest
+	h.ghostFor, h.ghost, h.ghostLine = "", "", ""
+}
+
+// ghostText returns the suggested rest of the line for text, looking it up
+// only when the text changed since the last draw
+func (h *highlightInput) ghostText(text string) string {
+	if h.suggest == nil {
+		return ""
+	}
+	if text != h.ghostFor {
+		h.ghostFor, h.ghost, h.ghostLine = text, "", ""
+		if strings.TrimSpace(text) == "" {
+			return ""
+		}
+		query := h.suggest(text)
+		if h.ghost = ghostSuffix(text, query); h.ghost != "" {
+			h.ghostLine = query[:len(text)] + h.ghost
+		}
+	}
+	return h.ghost
+}
+
+// ghostShown reports whether a suggestion was shown after the cursor when
+// the editor was last drawn
+func (h *highlightInput) ghostShown() bool {
+	return h.ghostDrawn
+}
+
+// acceptGhost completes the query with the shown suggestion, reporting
+// whether there was one. The typed text takes the case of the query from
+// the history, which it matched ignoring case.
+func (h *highlightInput) acceptGhost() bool {
+	if !h.ghostDrawn {
+		return false
+	}
+	h.ghostDrawn = false
+	h.SetText(h.ghostLine)
+	return true
+}
+
+// drawGhost shows the suggested rest of the line in a dim color after the
+// query, when the cursor at x, y is at its end
+func (h *highlightInput) drawGhost(screen tcell.Screen, x, y int) {
+	h.ghostDrawn = false
+	if x < 0 {
+		return // The editor does not have focus
+	}
+	text := h.GetText()
+	ghost := h.ghostText(text)
+	if ghost == "" {
+		return
+	}
+	fieldX, fieldY, fieldWidth, ok := h.fieldRect()
+	if !ok || y != fieldY {
+		return
+	}
+	runes := []rune(text)
+	if offset := shownOffset(screen, runes, fieldX, fieldY, fieldWidth); offset < 0 || offset+x-fieldX != len(runes) {
+		return
+	}
+
+	// Stop before wide or zero-width characters, which would need more
+	// than one cell each
+	for _, r := range ghost {
+		if x >= fieldX+fieldWidth || r >= 0x1100 || !unicode.In(r, unicode.L, unicode.N, unicode.P, unicode.S, unicode.Zs) {
+			break
+		}
+		_, _, style, _ := screen.GetContent(x, y)
+		screen.SetContent(x, y, r, nil, style.Foreground(ghostColor))
+		x++
+	}
+	h.ghostDrawn = true
+}
@@ -0,0 +1,77 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+func TestGhostSuffix(t *testing.T) {
+	tests := []struct{ text, query, want string }{
+		{"select * fr", "SELECT * FROM t", "OM t"},
+		{"SELECT a", "SELECT a,\n  b FROM t", ","},
+		{"SELECT a", "SELECT a", ""},
+		{"SELECT b", "SELECT a FROM t", ""},
+		{"SELECT a", "", ""},
+	}
+	for _, tt := range tests {
+		if got := ghostSuffix(tt.text, tt.query); got != tt.want {
+			t.Errorf("ghostSuffix(%q, %q) = %q, want %q", tt.text, tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestHighlightInputGhostText(t *testing.T) {
+	screen := tcell.NewSimulationScreen("")
+	if err := screen.Init(); err != nil {
+		t.Fatal(err)
+	}
+	defer screen.Fini()
+	screen.SetSize(40, 1)
+
+	field := tview.NewInputField().SetLabel("SQL> ").SetFieldWidth(0).SetText("select * fr")
+	editor := newHighlightInput(field, dark, true)
+	editor.SetRect(0, 0, 40, 1)
+	lookups := 0
+	editor.setSuggester(func(text string) string {
+		lookups++
+		return "SELECT * FROM orders"
+	})
+
+	// Without focus there is no cursor to suggest after
+	editor.Draw(screen)
+	if editor.ghostShown() {
+		t.Error("the ghost should only show in the focused editor")
+	}
+
+	editor.Focus(func(tview.Primitive) {})
+	editor.Draw(screen)
+	if !editor.ghostShown() {
+		t.Fatal("expected a ghost after the query")
+	}
+	r, _, style, _ := screen.GetContent(5+11, 0)
+	if fg, _, _ := style.Decompose(); r != 'O' || fg != ghostColor {
+		t.Errorf("ghost cell = %q in %v, want 'O' in %v", r, fg, ghostColor)
+	}
+	editor.Draw(screen)
+	if lookups != 1 {
+		t.Errorf("looked up %d times for unchanged text, want 1", lookups)
+	}
+
+	// Away from the end of the query the ghost hides
+	moveCursor(field, 3)
+	editor.Draw(screen)
+	if editor.ghostShown() || editor.acceptGhost() {
+		t.Error("the ghost should hide when the cursor is not at the end")
+	}
+
+	moveCursor(field, 11)
+	editor.Draw(screen)
+	if !editor.acceptGhost() {
+		t.Fatal("acceptGhost should accept the shown ghost")
+	}
+	if got := field.GetText(); got != "SELECT * FROM orders" {
+		t.Errorf("text after accepting = %q", got)
+	}
+}
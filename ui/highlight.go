@@ -0,0 +1,293 @@
+package ui
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/TFMV/trino-cli/autocomplete"
+	"github.com/TFMV/trino-cli/sqllex"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// color returns the theme color for a token kind, or "" for plain text
+func (t Theme) color(kind tokenKind) string {
+	switch kind {
+	case tokenKeyword:
+		return t.Keyword
+	case tokenString:
+		return t.String
+	case tokenNumber:
+		return t.Number
+	case tokenComment:
+		return t.Comment
+	}
+	return ""
+}
+
+type tokenKind int
+
+const (
+	tokenPlain tokenKind = iota
+	tokenKeyword
+	tokenString
+	tokenNumber
+	tokenComment
+)
+
+// sqlToken is a run of query text sharing one highlight kind
+type sqlToken struct {
+	kind tokenKind
+	text string
+}
+
+// extraKeywords complements the autocomplete keyword list with words that
+// only appear there as part of a phrase or are not suggested at all
+var extraKeywords = []string{
+	"BY", "GROUP", "ORDER", "LEFT", "RIGHT", "INNER", "OUTER", "FULL", "CROSS",
+	"IS", "NULL", "TRUE", "FALSE", "USING", "NATURAL", "LATERAL", "UNNEST",
+	"OFFSET", "FETCH", "FIRST", "NEXT", "ROWS", "ROW", "ONLY", "EXCEPT",
+	"INTERSECT", "SHOW", "USE", "DESCRIBE", "EXPLAIN", "ANALYZE", "CATALOGS",
+	"SCHEMAS", "TABLES", "COLUMNS", "FUNCTIONS", "SESSION", "IF", "TRY_CAST",
+	"WINDOW", "RANGE", "PRECEDING", "FOLLOWING", "UNBOUNDED", "CURRENT",
+	"RECURSIVE", "SCHEMA", "REPLACE", "PREPARE", "EXECUTE", "DEALLOCATE",
+	"GRANT", "REVOKE", "COMMIT", "ROLLBACK", "START", "TRANSACTION", "ARRAY",
+	"MAP", "DATE", "TIME", "TIMESTAMP", "ZONE", "AT", "FILTER", "WITHIN",
+}
+
+var sqlKeywords = func() map[string]bool {
+	words := make(map[string]bool)
+	for _, phrase := range append(autocomplete.CommonSQLKeywords, extraKeywords...) {
+		for _, word := range strings.Fields(phrase) {
+			words[word] = true
+		}
+	}
+	return words
+}()
+
+// tokenizeSQL splits text into highlight tokens. It never fails: unterminated
+// strings and comments run to the end of the text, which is what the user
+// sees while typing them.
+func tokenizeSQL(text string) []sqlToken {
+	var tokens []sqlToken
+	emit := func(kind tokenKind, s string) {
+		if s == "" {
+			return
+		}
+		if n := len(tokens); n > 0 && tokens[n-1].kind == kind {
+			tokens[n-1].text += s
+			return
+		}
+		tokens = append(tokens, sqlToken{kind: kind, text: s})
+	}
+
+	end := 0
+	for _, tok := range sqllex.Tokenize(text) {
+		emit(tokenPlain, text[end:tok.Start])
+		end = tok.End
+		switch tok.Kind {
+		case sqllex.Word:
+			if sqlKeywords[strings.ToUpper(tok.Text)] {
+				emit(tokenKeyword, tok.Text)
+			} else {
+				emit(tokenPlain, tok.Text)
+			}
+		case sqllex.String:
+			emit(tokenString, tok.Text)
+		case sqllex.Number:
+			emit(tokenNumber, tok.Text)
+		case sqllex.Comment:
+			emit(tokenComment, tok.Text)
+		default:
+			// Quoted identifiers are never keywords
+			emit(tokenPlain, tok.Text)
+		}
+	}
+	emit(tokenPlain, text[end:])
+	return tokens
+}
+
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// HighlightSQL returns text marked up with tview color tags for theme. The
+// text itself is escaped, so brackets in the query are displayed verbatim.
+func HighlightSQL(text string, theme Theme) string {
+	var b, plain strings.Builder
+	for _, tok := range tokenizeSQL(text) {
+		color := theme.color(tok.kind)
+		if color == "" {
+			// Uncolored runs are escaped together so "[1]" is not split
+			// into pieces that no longer look like a tag to escape
+			plain.WriteString(tok.text)
+			continue
+		}
+		b.WriteString(tview.Escape(plain.String()))
+		plain.Reset()
+		b.WriteString("[" + color + "]")
+		b.WriteString(tview.Escape(tok.text))
+		b.WriteString("[-]")
+	}
+	b.WriteString(tview.Escape(plain.String()))
+	return b.String()
+}
+
+// highlightInput is an input field that colors its SQL as it is typed.
+// tview input fields draw their text unstyled, so the field is drawn first
+// and the visible cells are then recolored from the tokenized query.
+type highlightInput struct {
+	*tview.InputField
+	theme   Theme
+	enabled bool
+
+	// The span of an error Trino located in the query, in runes, shown
+	// until the query is edited
+	mark               bool
+	markStart, markEnd int
+	markedText         string
+
+	// Ghost text proposing the rest of the line from the query history
+	suggest    func(text string) string
+	ghostFor   string // The text the ghost was looked up for
+	ghost      string
+	ghostLine  string // The text completed with the ghost
+	ghostDrawn bool   // The ghost was shown after the cursor in the last draw
+
+	cursorX, cursorY int // Where the cursor was shown in the last draw, x < 0 without focus
+}
+
+func newHighlightInput(field *tview.InputField, theme Theme, enabled bool) *highlightInput {
+	return &highlightInput{InputField: field, theme: theme, enabled: enabled, cursorX: -1}
+}
+
+// setEnabled switches highlighting on or off
+func (h *highlightInput) setEnabled(on bool) {
+	h.enabled = on
+}
+
+// markError underlines the runes from start to end of the current query
+// in the error color, until the query changes or clearMark is called
+func (h *highlightInput) markError(start, end int) {
+	h.mark, h.markStart, h.markEnd, h.markedText = true, start, end, h.GetText()
+}
+
+// clearMark removes the error mark
+func (h *highlightInput) clearMark() {
+	h.mark = false
+}
+
+// marked reports whether the error mark applies to the current query
+func (h *highlightInput) marked(text string) bool {
+	return h.mark && text == h.markedText
+}
+
+// render formats a query for display elsewhere in the shell, highlighted
+// when highlighting is on and escaped either way
+func (h *highlightInput) render(query string) string {
+	if !h.enabled {
+		return tview.Escape(query)
+	}
+	return HighlightSQL(query, h.theme)
+}
+
+// Draw draws the input field, recolors the visible part of the query and
+// shows the history suggestion after the cursor.
+func (h *highlightInput) Draw(screen tcell.Screen) {
+	cursor := &cursorScreen{Screen: screen, x: -1}
+	h.InputField.Draw(cursor)
+	h.drawHighlight(screen)
+	h.drawGhost(screen, cursor.x, cursor.y)
+	h.cursorX, h.cursorY = cursor.x, cursor.y
+}
+
+// cursorPosition returns where the cursor was shown in the last draw,
+// reporting false when the editor did not have focus
+func (h *highlightInput) cursorPosition() (x, y int, ok bool) {
+	return h.cursorX, h.cursorY, h.cursorX >= 0
+}
+
+// fieldRect returns where the text of the field is drawn
+func (h *highlightInput) fieldRect() (fieldX, y, fieldWidth int, ok bool) {
+	x, y, width, height := h.GetInnerRect()
+	if height < 1 {
+		return 0, 0, 0, false
+	}
+	labelWidth := tview.TaggedStringWidth(h.GetLabel())
+	fieldX, fieldWidth = x+labelWidth, h.GetFieldWidth()
+	if fieldWidth == 0 || fieldWidth > width-labelWidth {
+		fieldWidth = width - labelWidth
+	}
+	return fieldX, y, fieldWidth, fieldWidth >= 1
+}
+
+// shownOffset returns the rune offset into text of the first visible cell
+func shownOffset(screen tcell.Screen, text []rune, fieldX, y, fieldWidth int) int {
+	visible := make([]rune, 0, fieldWidth)
+	for col := 0; col < fieldWidth; col++ {
+		r, _, _, _ := screen.GetContent(fieldX+col, y)
+		visible = append(visible, r)
+	}
+	return visibleOffset(text, visible)
+}
+
+// drawHighlight recolors the visible part of the query
+func (h *highlightInput) drawHighlight(screen tcell.Screen) {
+	marked := h.marked(h.GetText())
+	if !h.enabled && !marked {
+		return
+	}
+	text := []rune(h.GetText())
+	if len(text) == 0 {
+		return
+	}
+	fieldX, y, fieldWidth, ok := h.fieldRect()
+	if !ok {
+		return
+	}
+
+	// Per-rune colors for the whole query, so tokens that start off-screen
+	// (a long string scrolled to the left) are still colored correctly
+	colors := make([]string, 0, len(text))
+	for _, tok := range tokenizeSQL(string(text)) {
+		color := h.theme.color(tok.kind)
+		if !h.enabled {
+			color = ""
+		}
+		for range []rune(tok.text) {
+			colors = append(colors, color)
+		}
+	}
+
+	offset := shownOffset(screen, text, fieldX, y, fieldWidth)
+	if offset < 0 {
+		return
+	}
+
+	for col := 0; col < fieldWidth && offset+col < len(text); col++ {
+		r, combc, style, _ := screen.GetContent(fieldX+col, y)
+		if marked && offset+col >= h.markStart && offset+col < h.markEnd {
+			screen.SetContent(fieldX+col, y, r, combc, style.Foreground(tcell.ColorWhite).Background(tcell.ColorRed).Underline(true))
+			continue
+		}
+		if color := colors[offset+col]; color != "" {
+			screen.SetContent(fieldX+col, y, r, combc, style.Foreground(tcell.GetColor(color)))
+		}
+	}
+}
+
+// visibleOffset finds where the text shown in the field starts within the
+// full query. It returns -1 when the two cannot be matched, e.g. when the
+// query contains wide characters, in which case highlighting is skipped.
+func visibleOffset(text, visible []rune) int {
+	shown := []rune(strings.TrimRight(string(visible), " "))
+	if len(shown) == 0 {
+		return -1
+	}
+	for offset := 0; offset+len(shown) <= len(text); offset++ {
+		if string(text[offset:offset+len(shown)]) == string(shown) {
+			return offset
+		}
+	}
+	return -1
+}
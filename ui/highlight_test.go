@@ -0,0 +1,137 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+func TestTokenizeSQL(t *testing.T) {
+	tokens := tokenizeSQL("select 'it''s', 1.5e3 from t -- done")
+	want := []sqlToken{
+		{tokenKeyword, "select"},
+		{tokenPlain, " "},
+		{tokenString, "'it''s'"},
+		{tokenPlain, ", "},
+		{tokenNumber, "1.5e3"},
+		{tokenPlain, " "},
+		{tokenKeyword, "from"},
+		{tokenPlain, " t "},
+		{tokenComment, "-- done"},
+	}
+	if len(tokens) != len(want) {
+		t.Fatalf("got %d tokens %v, want %d", len(tokens), tokens, len(want))
+	}
+	for i := range want {
+		if tokens[i] != want[i] {
+			t.Errorf("token %d = %+v, want %+v", i, tokens[i], want[i])
+		}
+	}
+}
+
+func TestTokenizeSQLUnterminated(t *testing.T) {
+	for _, text := range []string{"select 'abc", "select /* note", `select "col`, "select ${n"} {
+		var joined string
+		for _, tok := range tokenizeSQL(text) {
+			joined += tok.text
+		}
+		if joined != text {
+			t.Errorf("tokens of %q rejoin to %q", text, joined)
+		}
+	}
+}
+
+func TestHighlightSQL(t *testing.T) {
+	theme := Theme{Keyword: "blue", Number: "red"}
+	got := HighlightSQL("SELECT a[1] FROM t", theme)
+	want := "[blue]SELECT[-] a[[red]1[-]] [blue]FROM[-] t"
+	if got != want {
+		t.Errorf("HighlightSQL = %q, want %q", got, want)
+	}
+
+	// Without a number color the brackets form a tag-like run that must be escaped
+	got = HighlightSQL("SELECT a[1] FROM t", Theme{Keyword: "blue"})
+	want = "[blue]SELECT[-] a[1[] [blue]FROM[-] t"
+	if got != want {
+		t.Errorf("HighlightSQL = %q, want %q", got, want)
+	}
+}
+
+func TestVisibleOffset(t *testing.T) {
+	text := []rune("select name from users")
+	if got := visibleOffset(text, []rune("name from users   ")); got != 7 {
+		t.Errorf("visibleOffset = %d, want 7", got)
+	}
+	if got := visibleOffset(text, []rune("nothing here")); got != -1 {
+		t.Errorf("visibleOffset = %d, want -1", got)
+	}
+}
+
+func TestHighlightInputDraw(t *testing.T) {
+	screen := tcell.NewSimulationScreen("")
+	if err := screen.Init(); err != nil {
+		t.Fatal(err)
+	}
+	defer screen.Fini()
+	screen.SetSize(40, 1)
+
+	field := tview.NewInputField().SetLabel("SQL> ").SetFieldWidth(0).SetText("select 'x' from t")
+	editor := newHighlightInput(field, Theme{Keyword: "blue", String: "red"}, true)
+	editor.SetRect(0, 0, 40, 1)
+	editor.Draw(screen)
+
+	colorAt := func(x int) tcell.Color {
+		_, _, style, _ := screen.GetContent(x, 0)
+		fg, _, _ := style.Decompose()
+		return fg
+	}
+	if got := colorAt(5); got != tcell.GetColor("blue") {
+		t.Errorf("keyword color = %v, want blue", got)
+	}
+	if got := colorAt(12); got != tcell.GetColor("red") {
+		t.Errorf("string color = %v, want red", got)
+	}
+	plain := colorAt(21)
+
+	editor.setEnabled(false)
+	editor.Draw(screen)
+	if got := colorAt(5); got != plain {
+		t.Errorf("keyword color with highlighting off = %v, want %v", got, plain)
+	}
+}
+
+func TestHighlightInputDrawsErrorMark(t *testing.T) {
+	screen := tcell.NewSimulationScreen("")
+	if err := screen.Init(); err != nil {
+		t.Fatal(err)
+	}
+	defer screen.Fini()
+	screen.SetSize(40, 1)
+
+	field := tview.NewInputField().SetLabel("SQL> ").SetFieldWidth(0).SetText("select x form t")
+	editor := newHighlightInput(field, dark, false)
+	editor.SetRect(0, 0, 40, 1)
+	editor.markError(9, 13)
+	editor.Draw(screen)
+
+	backgroundAt := func(x int) tcell.Color {
+		_, _, style, _ := screen.GetContent(x, 0)
+		_, bg, _ := style.Decompose()
+		return bg
+	}
+	for x := 5 + 9; x < 5+13; x++ {
+		if got := backgroundAt(x); got != tcell.ColorRed {
+			t.Errorf("column %d background = %v, want red", x, got)
+		}
+	}
+	if got := backgroundAt(5 + 8); got == tcell.ColorRed {
+		t.Error("the mark should cover only the word")
+	}
+
+	editor.clearMark()
+	editor.Draw(screen)
+	if got := backgroundAt(5 + 9); got == tcell.ColorRed {
+		t.Error("clearMark should remove the mark")
+	}
+}
@@ -0,0 +1,97 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/TFMV/trino-cli/history"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// historyPickerLimit caps how many matches the picker lists
+const historyPickerLimit = 50
+
+// showHistoryPicker replaces the screen with an incremental fuzzy search over
+// the persistent query history. Each match is displayed through render.
+// Choosing an entry calls onSelect with its query; onClose is always called
+// once the previous root has been restored.
+func showHistoryPicker(ctx context.Context, app *tview.Application, root tview.Primitive, render func(query string) string, onSelect func(query string), onClose func(status string)) {
+	filter := tview.NewInputField().
+		SetLabel("Search: ").
+		SetFieldWidth(0)
+
+	list := tview.NewList().
+		ShowSecondaryText(true).
+		SetHighlightFullLine(true).
+		SetSelectedStyle(currentTheme.selectedStyle())
+
+	var matches []history.QueryHistory
+	refresh := func(term string) {
+		var err error
+		matches, err = history.FuzzySearchQueries(ctx, term, historyPickerLimit)
+		list.Clear()
+		if err != nil {
+			list.AddItem(fmt.Sprintf("[red]%v", err), "", 0, nil)
+			return
+		}
+		if len(matches) == 0 {
+			list.AddItem("[yellow]No matching queries", "", 0, nil)
+			return
+		}
+		for _, q := range matches {
+			secondary := fmt.Sprintf("%s  %s  %s", q.Timestamp.Format("Jan 02 15:04:05"), q.Profile, q.Status)
+			list.AddItem(render(strings.Join(strings.Fields(q.Query), " ")), secondary, 0, nil)
+		}
+	}
+
+	closePicker := func(status string) {
+		app.SetRoot(root, true)
+		onClose(status)
+	}
+	choose := func() {
+		index := list.GetCurrentItem()
+		if index < 0 || index >= len(matches) {
+			return
+		}
+		query := matches[index].Query
+		closePicker("[green]Query loaded from history")
+		onSelect(query)
+	}
+
+	filter.SetChangedFunc(refresh)
+	filter.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyUp:
+			if i := list.GetCurrentItem(); i > 0 {
+				list.SetCurrentItem(i - 1)
+			}
+			return nil
+		case tcell.KeyDown, tcell.KeyCtrlR:
+			if i := list.GetCurrentItem(); i < list.GetItemCount()-1 {
+				list.SetCurrentItem(i + 1)
+			}
+			return nil
+		case tcell.KeyEnter:
+			choose()
+			return nil
+		case tcell.KeyEscape:
+			closePicker("[yellow]History search cancelled")
+			return nil
+		}
+		return event
+	})
+
+	refresh("")
+
+	layout := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(filter, 1, 0, true).
+		AddItem(list, 0, 1, false)
+	layout.SetBorder(true).
+		SetTitle(" History search (Enter to insert, Esc to cancel) ").
+		SetTitleAlign(tview.AlignLeft)
+
+	app.SetRoot(layout, true).SetFocus(filter)
+}
@@ -0,0 +1,98 @@
+package ui
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// structuredTypes are the Trino types whose values are pretty-printed as JSON
+var structuredTypes = map[string]bool{"JSON": true, "ROW": true, "MAP": true, "ARRAY": true}
+
+// formatCellValue renders a result value in full for the inspector. JSON,
+// ROW, MAP and ARRAY values are indented; strings that look like JSON are
+// too when the column type is unknown. Dates and times use the configured
+// layouts.
+func formatCellValue(value interface{}, typeName string) string {
+	if value == nil {
+		return currentFormat.null
+	}
+	base := strings.ToUpper(typeName)
+	if i := strings.IndexByte(base, '('); i >= 0 {
+		base = base[:i]
+	}
+	structured := structuredTypes[strings.TrimSpace(base)]
+
+	switch v := value.(type) {
+	case string:
+		trimmed := strings.TrimSpace(v)
+		looksJSON := strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[")
+		if structured || (typeName == "" && looksJSON) {
+			var buf bytes.Buffer
+			if err := json.Indent(&buf, []byte(trimmed), "", "  "); err == nil {
+				return buf.String()
+			}
+		}
+		return v
+	case []byte:
+		return string(v)
+	case time.Time:
+		return currentFormat.formatTime(v, typeName)
+	}
+	if structured {
+		if data, err := json.MarshalIndent(value, "", "  "); err == nil {
+			return string(data)
+		}
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+// showCellInspector replaces the screen with the full value of one result
+// cell. 'c' copies the value to the clipboard; Escape or q closes the
+// inspector. onClose is called with a status message after the previous
+// root has been restored.
+func showCellInspector(app *tview.Application, root tview.Primitive, column, typeName string, value interface{}, onClose func(status string)) {
+	text := formatCellValue(value, typeName)
+
+	view := tview.NewTextView().
+		SetDynamicColors(false).
+		SetScrollable(true).
+		SetWrap(true).
+		SetText(text)
+
+	label := column
+	if typeName != "" {
+		label = fmt.Sprintf("%s (%s)", column, strings.ToLower(typeName))
+	}
+	view.SetBorder(true).
+		SetTitle(fmt.Sprintf(" %s: c to copy, Esc to close ", tview.Escape(label))).
+		SetTitleAlign(tview.AlignLeft)
+
+	closeInspector := func(status string) {
+		app.SetRoot(root, true)
+		onClose(status)
+	}
+	view.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch {
+		case event.Key() == tcell.KeyEscape, event.Key() == tcell.KeyEnter, event.Rune() == 'q':
+			closeInspector("")
+			return nil
+		case event.Rune() == 'c':
+			how, err := CopyToClipboard(text)
+			if err != nil {
+				closeInspector(fmt.Sprintf("[red]Copy failed: %v", err))
+				return nil
+			}
+			closeInspector(fmt.Sprintf("[green]Copied %s value %s", tview.Escape(column), how))
+			return nil
+		}
+		return event
+	})
+
+	app.SetRoot(view, true).SetFocus(view)
+}
@@ -0,0 +1,28 @@
+package ui
+
+import (
+	"testing"
+)
+
+func TestFormatCellValue(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    interface{}
+		typeName string
+		want     string
+	}{
+		{"null", nil, "VARCHAR", "NULL"},
+		{"json string", `{"a":1}`, "JSON", "{\n  \"a\": 1\n}"},
+		{"untyped json", `[1,2]`, "", "[\n  1,\n  2\n]"},
+		{"varchar stays raw", `{"a":1}`, "VARCHAR", `{"a":1}`},
+		{"invalid json", `{oops`, "JSON", `{oops`},
+		{"map", map[string]interface{}{"k": "v"}, "MAP", "{\n  \"k\": \"v\"\n}"},
+		{"row with parameters", []interface{}{int64(1), "x"}, "ROW(a INTEGER, b VARCHAR)", "[\n  1,\n  \"x\"\n]"},
+		{"number", int64(42), "BIGINT", "42"},
+	}
+	for _, tt := range tests {
+		if got := formatCellValue(tt.value, tt.typeName); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
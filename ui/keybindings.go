@@ -0,0 +1,288 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// keyBinding is one shortcut listed by the help overlay
+type keyBinding struct {
+	keys   string
+	action string
+}
+
+// keyBindingGroup holds the shortcuts of one part of the shell
+type keyBindingGroup struct {
+	name     string
+	bindings []keyBinding
+}
+
+// bindings is the registry of shortcuts active under the keymap, grouped by
+// where they apply. The help overlay is generated from it, so a change to a
+// key handler belongs here too. Keys the keymap translates into others are
+// dropped from the default bindings by asking the keymap, so only the
+// keymap's own bindings are listed by hand.
+func (k *keymap) bindings() []keyBindingGroup {
+	editor := keyBindingGroup{name: "Editor", bindings: []keyBinding{
+		{"Enter", `Run the query (end it with \G for vertical output, \watch [seconds] to re-run it)`},
+		{"Up / Down", "Previous / next query from the history"},
+		{"Right / Tab", "Accept the rest of the line suggested from the history (shown dimmed)"},
+		{"Ctrl+R", "Search the query history"},
+		{"Ctrl+O", "Insert a snippet, or save the query as one"},
+		{"Tab / Shift+Tab", "Next / previous placeholder of an inserted snippet"},
+		{"Ctrl+G", "Edit the query in $VISUAL or $EDITOR"},
+		{"Alt+Shift+F", "Format the query (keyword case and spacing, in the sql_format style)"},
+		{"Esc", "Dismiss a query error, or clear the editor"},
+	}}
+	editor.drop(k.style, (*keymap).editorKey)
+	switch k.style {
+	case keymapVim:
+		editor.bindings = append(editor.bindings,
+			keyBinding{"Esc", "Dismiss a query error, or switch to normal mode (i, a, A, I switch back)"},
+			keyBinding{"h l 0 ^ $ w b", "Move the cursor (normal mode)"},
+			keyBinding{"j / k", "Next / previous query from the history (normal mode)"},
+			keyBinding{"x X D C S dd cc u", "Edit the query (normal mode)"},
+			keyBinding{"/", "Search the query history (normal mode)"},
+			keyBinding{"v", "Edit the query in $VISUAL or $EDITOR (normal mode)"},
+			keyBinding{"=", "Format the query (normal mode)"},
+			keyBinding{"?", "Show this help (normal mode)"},
+		)
+	case keymapEmacs:
+		editor.bindings = append(editor.bindings,
+			keyBinding{"Ctrl+F / Ctrl+B", "Move forward / back a character"},
+			keyBinding{"Alt+F / Alt+B", "Move forward / back a word"},
+			keyBinding{"Ctrl+A / Ctrl+E", "Move to the start / end of the query"},
+			keyBinding{"Ctrl+P / Ctrl+N", "Previous / next query from the history"},
+			keyBinding{"Ctrl+G", "Clear the editor"},
+			keyBinding{"Ctrl+X Ctrl+E", "Edit the query in $VISUAL or $EDITOR"},
+		)
+	}
+
+	autocomplete := keyBindingGroup{name: "Autocomplete", bindings: []keyBinding{
+		{"Tab / Ctrl+Space", "Show suggestions"},
+		{"Up / Down", "Choose a suggestion"},
+		{"PgUp / PgDn", "Scroll the suggestions a page"},
+		{"Enter / Tab", "Insert the suggestion"},
+		{"Esc", "Hide suggestions"},
+	}}
+
+	results := keyBindingGroup{name: "Results", bindings: []keyBinding{
+		{"Arrows, h j k l", "Move around the table"},
+		{"g / G, PgUp / PgDn", "First / last row, page up / down"},
+		{"0 / $", "First / last column"},
+		{"+ / -", "Widen / narrow the selected column"},
+		{"=", "Fit the selected column to its values"},
+		{"n / p", "Next / previous page of rows"},
+		{"L", "Render more rows of a result that was cut off"},
+		{"s", "Sort by the selected column (again to reverse)"},
+		{"Ctrl+F", "Filter rows by text or a comparison such as price > 10"},
+		{"Enter", "Inspect the selected value"},
+		{"v", "Toggle the one-row-at-a-time vertical view"},
+		{"c", "Copy the selected value"},
+		{"r / J", "Copy the selected row as CSV / JSON"},
+		{"A", "Copy the whole result as CSV"},
+		{"C", "Chart the selected numeric column"},
+		{"Esc", "Back to the editor"},
+	}}
+	results.drop(k.style, (*keymap).resultKey)
+	switch k.style {
+	case keymapVim:
+		results.bindings = append(results.bindings,
+			keyBinding{"Ctrl+F / Ctrl+D", "Page down"},
+			keyBinding{"Ctrl+B / Ctrl+U", "Page up"},
+			keyBinding{"/", "Filter rows"},
+		)
+	case keymapEmacs:
+		results.bindings = append(results.bindings,
+			keyBinding{"Ctrl+N / Ctrl+P", "Next / previous row"},
+			keyBinding{"Ctrl+F / Ctrl+B", "Next / previous column"},
+			keyBinding{"Ctrl+V / Alt+V", "Page down / up"},
+			keyBinding{"Alt+< / Alt+>", "First / last row"},
+			keyBinding{"Ctrl+S", "Filter rows"},
+			keyBinding{"Ctrl+G", "Back to the editor"},
+			keyBinding{"Ctrl+X Ctrl+E", "Edit the query in $VISUAL or $EDITOR"},
+		)
+	}
+
+	chart := keyBindingGroup{name: "Chart", bindings: []keyBinding{
+		{"x / X", "Label the rows with the next / previous column"},
+		{"y / Y", "Plot the next / previous numeric column"},
+		{"t", "Switch between a bar and a line chart"},
+		{"Esc / C", "Back to the table"},
+	}}
+
+	schemaPane := keyBindingGroup{name: "Schema pane", bindings: []keyBinding{
+		{"Ctrl+B / F3", "Show or hide the schema pane"},
+		{"Up / Down", "Move through the tree"},
+		{"Enter", "Insert the table or column name into the editor"},
+		{"Space", "Expand or collapse a node"},
+		{"d", "Show the table's DDL"},
+		{"i", "Show the table's format, location, partitioning and other properties"},
+		{"y / s", "Copy the shown DDL, or save it to a file"},
+		{"p", "Preview the table's first 100 rows"},
+		{"t", "Show the table's statistics"},
+		{"g", "Generate a SELECT, COUNT(*) or filtered query; Enter opens it, y copies it"},
+		{"/", "Search every loaded object and jump to it"},
+		{"f", "Filter the whole loaded tree by name or glob (Esc clears)"},
+		{"H", "Show or hide system and ui.schema_hide schemas"},
+		{"b", "Bookmark the table under Favorites, or remove its bookmark"},
+		{"r / F5", "Reload the selected node's subtree from the server"},
+		{"R", "Clear the schema cache and reload everything"},
+		{"Tab", "Move between the tree and the preview"},
+		{"Esc", "Back to the editor"},
+	}}
+
+	shell := keyBindingGroup{name: "Tabs and shell", bindings: []keyBinding{
+		{"Ctrl+T", "Open a query tab"},
+		{"Alt+W", "Close the tab, cancelling its query"},
+		{"Ctrl+N / Alt+N", "Next tab"},
+		{"Alt+P", "Previous tab"},
+		{"Alt+1..9", "Go to a tab"},
+		{"Ctrl+E", "Export the last result"},
+		{"Ctrl+Q", "List running queries (k kills the selected one)"},
+		{"Ctrl+P / F4", "Switch to another profile, reconnecting"},
+		{"F5", "Re-run the last query on an interval (again to stop)"},
+		{"F2", "Toggle syntax highlighting"},
+		{"F1 / ?", "Show this help (? outside the editor)"},
+		{"Ctrl+C", "Quit"},
+	}}
+	// The shell's keys, and the schema pane's first, work everywhere but are
+	// mostly pressed in the editor. The pane's others are its own.
+	toggle := keyBindingGroup{bindings: schemaPane.bindings[:1]}
+	toggle.drop(k.style, (*keymap).editorKey)
+	schemaPane.bindings = append(toggle.bindings, schemaPane.bindings[1:]...)
+	shell.drop(k.style, (*keymap).editorKey)
+	if k.style == keymapEmacs {
+		shell.bindings = append(shell.bindings, keyBinding{"Ctrl+X Ctrl+W", "Export the last result"})
+	}
+
+	return []keyBindingGroup{editor, autocomplete, results, chart, schemaPane, shell}
+}
+
+// translation is a keymap's editorKey or resultKey
+type translation func(*keymap, *tcell.EventKey) *tcell.EventKey
+
+// drop removes the keys of g that the keymap of style translates into other
+// keys, as it does with the keys it takes over. Keys joined by " / " are
+// alternatives, but pairs when the action is too, as in "Up / Down" for
+// "Previous / next query": a pair goes as a whole. Bindings left without
+// keys go.
+func (g *keyBindingGroup) drop(style string, translate translation) {
+	var kept []keyBinding
+	for _, b := range g.bindings {
+		pair := strings.Contains(b.action, " / ")
+		var keys []string
+		for _, name := range strings.Split(b.keys, " / ") {
+			if !translated(style, name, translate) {
+				keys = append(keys, name)
+			} else if pair {
+				keys = nil
+				break
+			}
+		}
+		if len(keys) > 0 {
+			b.keys = strings.Join(keys, " / ")
+			kept = append(kept, b)
+		}
+	}
+	g.bindings = kept
+}
+
+// translated reports whether a fresh keymap of style, in vim's insert mode,
+// turns the key named into another or consumes it. Names that aren't a
+// single key, such as "Arrows, h j k l", never are.
+func translated(style, name string, translate translation) bool {
+	event, ok := keyEvent(name)
+	if !ok {
+		return false
+	}
+	got := translate(newKeymap(style), event)
+	return got == nil || got.Key() != event.Key() || got.Rune() != event.Rune() || got.Modifiers() != event.Modifiers()
+}
+
+// keyEvent returns the event of a key named as the help overlay names it,
+// such as Ctrl+E, Alt+N, Alt+Shift+F, F4, Esc or ?
+func keyEvent(name string) (*tcell.EventKey, bool) {
+	switch rest, alt := strings.CutPrefix(name, "Alt+"); {
+	case alt:
+		r, shifted := []rune(rest), false
+		if after, ok := strings.CutPrefix(rest, "Shift+"); ok {
+			r, shifted = []rune(after), true
+		}
+		if len(r) != 1 {
+			return nil, false
+		}
+		if !shifted {
+			r[0] = unicode.ToLower(r[0])
+		}
+		return tcell.NewEventKey(tcell.KeyRune, r[0], tcell.ModAlt), true
+	case strings.HasPrefix(name, "Ctrl+") && len(name) == len("Ctrl+E") && 'A' <= name[5] && name[5] <= 'Z':
+		return tcell.NewEventKey(tcell.KeyCtrlA+tcell.Key(name[5]-'A'), 0, tcell.ModCtrl), true
+	case name == "Esc":
+		return tcell.NewEventKey(tcell.KeyEscape, 0, 0), true
+	case name == "Enter":
+		return tcell.NewEventKey(tcell.KeyEnter, 0, 0), true
+	case len(name) == 2 && name[0] == 'F' && '1' <= name[1] && name[1] <= '9':
+		return tcell.NewEventKey(tcell.KeyF1+tcell.Key(name[1]-'1'), 0, 0), true
+	case len([]rune(name)) == 1:
+		return tcell.NewEventKey(tcell.KeyRune, []rune(name)[0], 0), true
+	}
+	return nil, false
+}
+
+// helpText formats the bindings as two aligned columns under group
+// headings in the heading color
+func helpText(groups []keyBindingGroup, heading string) string {
+	width := 0
+	for _, group := range groups {
+		for _, b := range group.bindings {
+			width = max(width, len(b.keys))
+		}
+	}
+
+	var text strings.Builder
+	for i, group := range groups {
+		if i > 0 {
+			text.WriteString("\n")
+		}
+		fmt.Fprintf(&text, "[%s::b]%s[-::-]\n", heading, tview.Escape(group.name))
+		for _, b := range group.bindings {
+			fmt.Fprintf(&text, "  %-*s  %s\n", width, tview.Escape(b.keys), tview.Escape(b.action))
+		}
+	}
+	return text.String()
+}
+
+// showKeyHelp replaces the screen with the shortcut list until the user
+// closes it
+func showKeyHelp(app *tview.Application, root tview.Primitive, groups []keyBindingGroup, onClose func()) {
+	heading := currentTheme.Header
+	if heading == "" {
+		heading = "green"
+	}
+	view := tview.NewTextView().
+		SetDynamicColors(true).
+		SetScrollable(true).
+		SetWrap(false).
+		SetText(helpText(groups, heading))
+	view.SetBorder(true).
+		SetTitle(" Keyboard shortcuts (Esc to close) ").
+		SetTitleAlign(tview.AlignLeft)
+
+	view.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch {
+		case event.Key() == tcell.KeyEscape, event.Key() == tcell.KeyEnter, event.Key() == tcell.KeyF1,
+			isRune(event, 'q'), isRune(event, '?'):
+			app.SetRoot(root, true)
+			onClose()
+			return nil
+		}
+		return event
+	})
+
+	app.SetRoot(view, true).SetFocus(view)
+}
@@ -0,0 +1,145 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+)
+
+// findBinding returns the action bound to keys in the named group
+func findBinding(groups []keyBindingGroup, group, keys string) (string, bool) {
+	for _, g := range groups {
+		if g.name != group {
+			continue
+		}
+		for _, b := range g.bindings {
+			if b.keys == keys {
+				return b.action, true
+			}
+		}
+	}
+	return "", false
+}
+
+func TestKeyBindingsFollowKeymap(t *testing.T) {
+	for _, style := range []string{keymapDefault, keymapVim, keymapEmacs} {
+		for _, g := range newKeymap(style).bindings() {
+			if len(g.bindings) == 0 {
+				t.Errorf("%s keymap: group %s is empty", style, g.name)
+			}
+		}
+	}
+
+	if _, ok := findBinding(newKeymap("").bindings(), "Editor", "j / k"); ok {
+		t.Error("default keymap lists vim bindings")
+	}
+	if _, ok := findBinding(newKeymap("vim").bindings(), "Editor", "j / k"); !ok {
+		t.Error("vim keymap is missing its normal-mode bindings")
+	}
+	if action, _ := findBinding(newKeymap("vim").bindings(), "Editor", "Esc"); !strings.Contains(action, "normal mode") {
+		t.Errorf("vim Esc = %q, want it to switch to normal mode", action)
+	}
+	if _, ok := findBinding(newKeymap("vim").bindings(), "Results", "Ctrl+F / Ctrl+D"); !ok {
+		t.Error("vim keymap is missing its result bindings")
+	}
+
+	// Emacs takes Ctrl+B, Ctrl+N and Ctrl+P for movement, leaving F3, Alt+N
+	// and F4
+	emacs := newKeymap("emacs").bindings()
+	if _, ok := findBinding(emacs, "Tabs and shell", "F4"); !ok {
+		t.Error("emacs keymap should switch profiles with F4 only")
+	}
+	if _, ok := findBinding(emacs, "Schema pane", "F3"); !ok {
+		t.Error("emacs keymap should open the schema pane with F3 only")
+	}
+	if _, ok := findBinding(emacs, "Tabs and shell", "Alt+N"); !ok {
+		t.Error("emacs keymap should switch tabs with Alt+N only")
+	}
+	if _, ok := findBinding(newKeymap("").bindings(), "Schema pane", "Ctrl+B / F3"); !ok {
+		t.Error("default keymap should list Ctrl+B for the schema pane")
+	}
+
+	// Emacs takes Ctrl+E and Ctrl+G for editing, so exporting and the
+	// external editor move behind Ctrl+X
+	if action, ok := findBinding(emacs, "Tabs and shell", "Ctrl+E"); ok {
+		t.Errorf("emacs keymap lists Ctrl+E for %q, but it moves to the end", action)
+	}
+	if action, _ := findBinding(emacs, "Editor", "Ctrl+G"); action != "Clear the editor" {
+		t.Errorf("emacs Ctrl+G = %q, want it to clear the editor", action)
+	}
+	if _, ok := findBinding(emacs, "Tabs and shell", "Ctrl+X Ctrl+W"); !ok {
+		t.Error("emacs keymap is missing its export key")
+	}
+
+	// Vim pages with Ctrl+F in the results, and filters with /
+	vim := newKeymap("vim").bindings()
+	if action, ok := findBinding(vim, "Results", "Ctrl+F"); ok {
+		t.Errorf("vim keymap lists Ctrl+F for %q, but it pages down", action)
+	}
+	if _, ok := findBinding(vim, "Schema pane", "Esc"); !ok {
+		t.Error("vim keymap drops Esc from the schema pane, which it doesn't translate")
+	}
+}
+
+func TestKeyBindingGroupDrop(t *testing.T) {
+	g := keyBindingGroup{bindings: []keyBinding{
+		{"Ctrl+N / Alt+N", "Next tab"},
+		{"Ctrl+P / Ctrl+N", "Previous / next query"},
+		{"Ctrl+E", "Export"},
+		{"Ctrl+X Ctrl+E", "Edit"},
+		{"Arrows, h j k l", "Move"},
+		{"Alt+Shift+F", "Format"},
+	}}
+	g.drop(keymapEmacs, (*keymap).editorKey)
+	var got []string
+	for _, b := range g.bindings {
+		got = append(got, b.keys)
+	}
+	want := []string{"Alt+N", "Ctrl+X Ctrl+E", "Arrows, h j k l", "Alt+Shift+F"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("drop left %q, want %q", got, want)
+	}
+}
+
+func TestKeyEvent(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"Ctrl+E", "Ctrl+E"},
+		{"Alt+N", "Alt+Rune[n]"},
+		{"Alt+Shift+F", "Alt+Rune[F]"},
+		{"Alt+<", "Alt+Rune[<]"},
+		{"F4", "F4"},
+		{"Esc", "Esc"},
+		{"?", "Rune[?]"},
+	}
+	for _, tt := range tests {
+		event, ok := keyEvent(tt.name)
+		if !ok || event.Name() != tt.want {
+			t.Errorf("keyEvent(%q) = %v, want %s", tt.name, event, tt.want)
+		}
+	}
+	for _, name := range []string{"Ctrl+X Ctrl+E", "Alt+1..9", "Arrows, h j k l", "Ctrl+Space", "Up"} {
+		if _, ok := keyEvent(name); ok {
+			t.Errorf("keyEvent(%q) should not name a single key", name)
+		}
+	}
+}
+
+func TestHelpText(t *testing.T) {
+	text := helpText([]keyBindingGroup{
+		{name: "Editor", bindings: []keyBinding{{"Enter", "Run"}, {"Ctrl+R", "Search [history]"}}},
+		{name: "Results", bindings: []keyBinding{{"s", "Sort"}}},
+	}, "yellow")
+
+	for _, want := range []string{
+		"[yellow::b]Editor[-::-]\n",
+		"  Enter   Run\n",
+		"  Ctrl+R  Search [history[]\n",
+		"\n[yellow::b]Results[-::-]\n  s       Sort\n",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("help text is missing %q:\n%s", want, text)
+		}
+	}
+}
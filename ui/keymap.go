@@ -0,0 +1,255 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// Keymaps for the editor and result table, chosen with ui.keymap in the
+// config file
+const (
+	keymapDefault = "default"
+	keymapVim     = "vim"
+	keymapEmacs   = "emacs"
+)
+
+// keymap translates vim or emacs keys into the shell's default bindings
+// before they reach the editor or the result table. The vim keymap starts in
+// insert mode, where keys type as usual; Escape switches to normal mode for
+// motions and editing commands.
+type keymap struct {
+	style   string
+	normal  bool // vim normal mode
+	pending rune // first key of a two-key vim command such as dd
+	prefix  bool // emacs Ctrl+X was pressed
+}
+
+func newKeymap(style string) *keymap {
+	switch s := strings.ToLower(strings.TrimSpace(style)); s {
+	case keymapVim, keymapEmacs:
+		return &keymap{style: s}
+	}
+	return &keymap{style: keymapDefault}
+}
+
+// label is the editor prompt, which shows the vim mode
+func (k *keymap) label() string {
+	switch {
+	case k.style != keymapVim:
+		return "SQL> "
+	case k.normal:
+		return "[black:yellow]NORMAL[-:-] SQL> "
+	}
+	return "[black:green]INSERT[-:-] SQL> "
+}
+
+// key builds a translated event without a rune
+func key(k tcell.Key, mod tcell.ModMask) *tcell.EventKey {
+	return tcell.NewEventKey(k, 0, mod)
+}
+
+// isRune reports whether event is the plain (or shifted) character r
+func isRune(event *tcell.EventKey, r rune) bool {
+	return event.Key() == tcell.KeyRune && event.Rune() == r &&
+		event.Modifiers()&(tcell.ModAlt|tcell.ModCtrl) == 0
+}
+
+// isAltRune reports whether event is Alt (Meta) plus the character r
+func isAltRune(event *tcell.EventKey, r rune) bool {
+	return event.Key() == tcell.KeyRune && event.Rune() == r && event.Modifiers()&tcell.ModAlt != 0
+}
+
+// editorKey translates a key pressed in the SQL editor. A nil result means
+// the key was consumed, such as a vim mode switch.
+func (k *keymap) editorKey(event *tcell.EventKey) *tcell.EventKey {
+	switch k.style {
+	case keymapEmacs:
+		return k.emacsEditorKey(event)
+	case keymapVim:
+		return k.vimEditorKey(event)
+	}
+	return event
+}
+
+// emacsPrefix translates Ctrl+X and the key after it. Ctrl+X Ctrl+E edits
+// the query in $EDITOR, as in bash, and Ctrl+X Ctrl+W writes the last result
+// out, taking over the Ctrl+E and Ctrl+G the emacs keymap uses for editing.
+// Other keys after Ctrl+X keep their usual meaning. It reports whether it
+// handled the event.
+func (k *keymap) emacsPrefix(event *tcell.EventKey) (*tcell.EventKey, bool) {
+	if !k.prefix {
+		if event.Key() == tcell.KeyCtrlX {
+			k.prefix = true
+			return nil, true
+		}
+		return event, false
+	}
+	k.prefix = false
+	switch event.Key() {
+	case tcell.KeyCtrlE:
+		return key(tcell.KeyCtrlG, 0), true
+	case tcell.KeyCtrlW:
+		return key(tcell.KeyCtrlE, 0), true
+	}
+	return event, false
+}
+
+func (k *keymap) emacsEditorKey(event *tcell.EventKey) *tcell.EventKey {
+	if translated, ok := k.emacsPrefix(event); ok {
+		return translated
+	}
+
+	switch {
+	case event.Key() == tcell.KeyCtrlP:
+		return key(tcell.KeyUp, 0)
+	case event.Key() == tcell.KeyCtrlN:
+		return key(tcell.KeyDown, 0)
+	case event.Key() == tcell.KeyCtrlF:
+		return key(tcell.KeyRight, 0)
+	case event.Key() == tcell.KeyCtrlB:
+		return key(tcell.KeyLeft, 0)
+	case event.Key() == tcell.KeyCtrlE:
+		return key(tcell.KeyEnd, 0)
+	case event.Key() == tcell.KeyCtrlG:
+		return key(tcell.KeyEscape, 0)
+	case isAltRune(event, 'f'):
+		return key(tcell.KeyRight, tcell.ModCtrl)
+	case isAltRune(event, 'b'):
+		return key(tcell.KeyLeft, tcell.ModCtrl)
+	}
+	return event
+}
+
+func (k *keymap) vimEditorKey(event *tcell.EventKey) *tcell.EventKey {
+	if !k.normal {
+		if event.Key() == tcell.KeyEscape {
+			k.normal = true
+			return nil
+		}
+		return event
+	}
+
+	// Keys other than plain characters (Enter, arrows, Ctrl and Alt
+	// shortcuts) keep their usual meaning in normal mode
+	if event.Key() != tcell.KeyRune || event.Modifiers()&(tcell.ModAlt|tcell.ModCtrl) != 0 {
+		k.pending = 0
+		if event.Key() == tcell.KeyEscape {
+			return nil
+		}
+		return event
+	}
+
+	if pending := k.pending; pending != 0 {
+		k.pending = 0
+		switch {
+		case pending == 'd' && event.Rune() == 'd':
+			return key(tcell.KeyCtrlU, 0)
+		case pending == 'c' && event.Rune() == 'c':
+			k.normal = false
+			return key(tcell.KeyCtrlU, 0)
+		}
+		return nil
+	}
+
+	switch event.Rune() {
+	case 'i':
+		k.normal = false
+		return nil
+	case 'a':
+		k.normal = false
+		return key(tcell.KeyRight, 0)
+	case 'A':
+		k.normal = false
+		return key(tcell.KeyEnd, 0)
+	case 'I':
+		k.normal = false
+		return key(tcell.KeyHome, 0)
+	case 'h':
+		return key(tcell.KeyLeft, 0)
+	case 'l':
+		return key(tcell.KeyRight, 0)
+	case '0', '^':
+		return key(tcell.KeyHome, 0)
+	case '$':
+		return key(tcell.KeyEnd, 0)
+	case 'w':
+		return key(tcell.KeyRight, tcell.ModCtrl)
+	case 'b':
+		return key(tcell.KeyLeft, tcell.ModCtrl)
+	case 'x':
+		return key(tcell.KeyDelete, 0)
+	case 'X':
+		return key(tcell.KeyBackspace2, 0)
+	case 'D':
+		return key(tcell.KeyCtrlK, 0)
+	case 'C':
+		k.normal = false
+		return key(tcell.KeyCtrlK, 0)
+	case 'S':
+		k.normal = false
+		return key(tcell.KeyCtrlU, 0)
+	case 'u':
+		return key(tcell.KeyCtrlZ, 0)
+	case 'j':
+		return key(tcell.KeyDown, 0)
+	case 'k':
+		return key(tcell.KeyUp, 0)
+	case '/':
+		return key(tcell.KeyCtrlR, 0)
+	case 'v':
+		return key(tcell.KeyCtrlG, 0)
+	case '=':
+		return tcell.NewEventKey(tcell.KeyRune, 'F', tcell.ModAlt)
+	case '?':
+		return key(tcell.KeyF1, 0)
+	case 'd', 'c':
+		k.pending = event.Rune()
+		return nil
+	}
+	// Other characters would insert text, which normal mode never does
+	return nil
+}
+
+// resultKey translates a key pressed in the result table or vertical view.
+// The table already moves with h/j/k/l and g/G.
+func (k *keymap) resultKey(event *tcell.EventKey) *tcell.EventKey {
+	switch k.style {
+	case keymapEmacs:
+		if translated, ok := k.emacsPrefix(event); ok {
+			return translated
+		}
+		switch {
+		case event.Key() == tcell.KeyCtrlN:
+			return key(tcell.KeyDown, 0)
+		case event.Key() == tcell.KeyCtrlP:
+			return key(tcell.KeyUp, 0)
+		case event.Key() == tcell.KeyCtrlF:
+			return key(tcell.KeyRight, 0)
+		case event.Key() == tcell.KeyCtrlB:
+			return key(tcell.KeyLeft, 0)
+		case event.Key() == tcell.KeyCtrlV:
+			return key(tcell.KeyPgDn, 0)
+		case isAltRune(event, 'v'):
+			return key(tcell.KeyPgUp, 0)
+		case isAltRune(event, '<'):
+			return key(tcell.KeyHome, 0)
+		case isAltRune(event, '>'):
+			return key(tcell.KeyEnd, 0)
+		case event.Key() == tcell.KeyCtrlS:
+			return key(tcell.KeyCtrlF, 0)
+		case event.Key() == tcell.KeyCtrlG:
+			return key(tcell.KeyEscape, 0)
+		}
+	case keymapVim:
+		switch {
+		case event.Key() == tcell.KeyCtrlF, event.Key() == tcell.KeyCtrlD:
+			return key(tcell.KeyPgDn, 0)
+		case event.Key() == tcell.KeyCtrlB, event.Key() == tcell.KeyCtrlU:
+			return key(tcell.KeyPgUp, 0)
+		case isRune(event, '/'):
+			return key(tcell.KeyCtrlF, 0)
+		}
+	}
+	return event
+}
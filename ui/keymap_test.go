@@ -0,0 +1,171 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func runeKey(r rune) *tcell.EventKey {
+	return tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone)
+}
+
+func TestNewKeymapStyle(t *testing.T) {
+	for style, want := range map[string]string{"": keymapDefault, "Vim": keymapVim, "emacs": keymapEmacs, "nano": keymapDefault} {
+		if got := newKeymap(style).style; got != want {
+			t.Errorf("newKeymap(%q).style = %q, want %q", style, got, want)
+		}
+	}
+}
+
+func TestDefaultKeymapPassesKeysThrough(t *testing.T) {
+	keys := newKeymap("")
+	for _, event := range []*tcell.EventKey{runeKey('j'), tcell.NewEventKey(tcell.KeyCtrlN, 0, tcell.ModCtrl), tcell.NewEventKey(tcell.KeyEscape, 0, tcell.ModNone)} {
+		if got := keys.editorKey(event); got != event {
+			t.Errorf("editorKey(%v) = %v, want it unchanged", event.Name(), got)
+		}
+		if got := keys.resultKey(event); got != event {
+			t.Errorf("resultKey(%v) = %v, want it unchanged", event.Name(), got)
+		}
+	}
+}
+
+func TestVimEditorModes(t *testing.T) {
+	keys := newKeymap("vim")
+	if ev := runeKey('j'); keys.editorKey(ev) != ev {
+		t.Fatal("insert mode should type j")
+	}
+
+	if keys.editorKey(tcell.NewEventKey(tcell.KeyEscape, 0, tcell.ModNone)) != nil || !keys.normal {
+		t.Fatal("Escape should switch to normal mode")
+	}
+	if keys.label() != "[black:yellow]NORMAL[-:-] SQL> " {
+		t.Errorf("label = %q", keys.label())
+	}
+
+	tests := []struct {
+		r    rune
+		key  tcell.Key
+		mod  tcell.ModMask
+		none bool
+	}{
+		{r: 'h', key: tcell.KeyLeft},
+		{r: 'k', key: tcell.KeyUp},
+		{r: '$', key: tcell.KeyEnd},
+		{r: 'w', key: tcell.KeyRight, mod: tcell.ModCtrl},
+		{r: 'x', key: tcell.KeyDelete},
+		{r: '/', key: tcell.KeyCtrlR},
+		{r: '?', key: tcell.KeyF1},
+		{r: 'z', none: true},
+	}
+	for _, tt := range tests {
+		got := keys.editorKey(runeKey(tt.r))
+		if tt.none {
+			if got != nil {
+				t.Errorf("%c = %v, want it swallowed", tt.r, got.Name())
+			}
+			continue
+		}
+		if got == nil || got.Key() != tt.key || got.Modifiers()&tcell.ModCtrl != tt.mod {
+			t.Errorf("%c translated to %v", tt.r, got)
+		}
+	}
+
+	// dd clears the line; d followed by anything else does nothing
+	if keys.editorKey(runeKey('d')) != nil {
+		t.Fatal("d should wait for a second key")
+	}
+	if got := keys.editorKey(runeKey('d')); got == nil || got.Key() != tcell.KeyCtrlU {
+		t.Errorf("dd translated to %v", got)
+	}
+	keys.editorKey(runeKey('d'))
+	if got := keys.editorKey(runeKey('q')); got != nil {
+		t.Errorf("dq translated to %v", got.Name())
+	}
+
+	// Enter still runs the query in normal mode
+	enter := tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone)
+	if keys.editorKey(enter) != enter {
+		t.Error("Enter should pass through in normal mode")
+	}
+
+	if got := keys.editorKey(runeKey('A')); got == nil || got.Key() != tcell.KeyEnd || keys.normal {
+		t.Errorf("A should move to the end in insert mode, got %v", got)
+	}
+}
+
+func TestEmacsKeys(t *testing.T) {
+	keys := newKeymap("emacs")
+	tests := []struct {
+		event  *tcell.EventKey
+		editor tcell.Key
+		result tcell.Key
+	}{
+		{tcell.NewEventKey(tcell.KeyCtrlP, 0, tcell.ModCtrl), tcell.KeyUp, tcell.KeyUp},
+		{tcell.NewEventKey(tcell.KeyCtrlN, 0, tcell.ModCtrl), tcell.KeyDown, tcell.KeyDown},
+		{tcell.NewEventKey(tcell.KeyCtrlB, 0, tcell.ModCtrl), tcell.KeyLeft, tcell.KeyLeft},
+		{tcell.NewEventKey(tcell.KeyCtrlE, 0, tcell.ModCtrl), tcell.KeyEnd, tcell.KeyCtrlE},
+		{tcell.NewEventKey(tcell.KeyCtrlS, 0, tcell.ModCtrl), tcell.KeyCtrlS, tcell.KeyCtrlF},
+		{tcell.NewEventKey(tcell.KeyRune, '>', tcell.ModAlt), tcell.KeyRune, tcell.KeyEnd},
+	}
+	for _, tt := range tests {
+		if got := keys.editorKey(tt.event); got.Key() != tt.editor {
+			t.Errorf("editor %s = %s, want key %d", tt.event.Name(), got.Name(), tt.editor)
+		}
+		if got := keys.resultKey(tt.event); got.Key() != tt.result {
+			t.Errorf("result %s = %s, want key %d", tt.event.Name(), got.Name(), tt.result)
+		}
+	}
+
+	if got := keys.editorKey(tcell.NewEventKey(tcell.KeyRune, 'f', tcell.ModAlt)); got.Key() != tcell.KeyRight || got.Modifiers()&tcell.ModCtrl == 0 {
+		t.Errorf("Alt+F = %s, want Ctrl+Right", got.Name())
+	}
+}
+
+func TestExternalEditorKeys(t *testing.T) {
+	vim := newKeymap("vim")
+	vim.normal = true
+	if got := vim.editorKey(runeKey('v')); got == nil || got.Key() != tcell.KeyCtrlG {
+		t.Errorf("vim v = %v, want Ctrl+G", got)
+	}
+
+	emacs := newKeymap("emacs")
+	if emacs.editorKey(tcell.NewEventKey(tcell.KeyCtrlX, 0, tcell.ModCtrl)) != nil {
+		t.Fatal("Ctrl+X should wait for a second key")
+	}
+	if got := emacs.editorKey(tcell.NewEventKey(tcell.KeyCtrlE, 0, tcell.ModCtrl)); got.Key() != tcell.KeyCtrlG {
+		t.Errorf("Ctrl+X Ctrl+E = %s, want Ctrl+G", got.Name())
+	}
+	if got := emacs.editorKey(tcell.NewEventKey(tcell.KeyCtrlE, 0, tcell.ModCtrl)); got.Key() != tcell.KeyEnd {
+		t.Errorf("Ctrl+E alone = %s, want End", got.Name())
+	}
+}
+
+func TestEmacsExportKeys(t *testing.T) {
+	emacs := newKeymap("emacs")
+	for name, translate := range map[string]func(*tcell.EventKey) *tcell.EventKey{
+		"editor": emacs.editorKey,
+		"result": emacs.resultKey,
+	} {
+		if translate(tcell.NewEventKey(tcell.KeyCtrlX, 0, tcell.ModCtrl)) != nil {
+			t.Fatalf("%s: Ctrl+X should wait for a second key", name)
+		}
+		if got := translate(tcell.NewEventKey(tcell.KeyCtrlW, 0, tcell.ModCtrl)); got.Key() != tcell.KeyCtrlE {
+			t.Errorf("%s: Ctrl+X Ctrl+W = %s, want Ctrl+E", name, got.Name())
+		}
+	}
+}
+
+func TestFormatKeys(t *testing.T) {
+	vim := newKeymap("vim")
+	vim.normal = true
+	if got := vim.editorKey(runeKey('=')); got == nil || !isAltRune(got, 'F') {
+		t.Errorf("vim = = %v, want Alt+Shift+F", got)
+	}
+
+	// Alt+F moves a word in emacs, but Alt+Shift+F still formats
+	emacs := newKeymap("emacs")
+	if got := emacs.editorKey(tcell.NewEventKey(tcell.KeyRune, 'F', tcell.ModAlt)); !isAltRune(got, 'F') {
+		t.Errorf("emacs Alt+Shift+F = %s, want it unchanged", got.Name())
+	}
+}
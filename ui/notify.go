@@ -0,0 +1,84 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/TFMV/trino-cli/config"
+)
+
+// defaultNotifyAfter is how long a query must run before its completion is
+// announced, unless ui.notify.after says otherwise
+const defaultNotifyAfter = 10 * time.Second
+
+// notifyThreshold parses ui.notify.after. enabled is false when alerts are
+// turned off.
+func notifyThreshold(settings config.Notify) (threshold time.Duration, enabled bool, err error) {
+	switch after := strings.TrimSpace(settings.After); strings.ToLower(after) {
+	case "":
+		return defaultNotifyAfter, true, nil
+	case "off", "never", "false":
+		return 0, false, nil
+	default:
+		threshold, err := time.ParseDuration(after)
+		if err != nil || threshold < 0 {
+			return defaultNotifyAfter, true, fmt.Errorf("invalid ui.notify.after %q", after)
+		}
+		return threshold, true, nil
+	}
+}
+
+// notifyCommands build a desktop notification with each tool, tried in order
+var notifyCommands = []struct {
+	name string
+	args func(title, message string) []string
+}{
+	{"notify-send", func(title, message string) []string {
+		return []string{"--app-name=trino-cli", title, message}
+	}},
+	{"osascript", func(title, message string) []string {
+		return []string{"-e", fmt.Sprintf("display notification %s with title %s", appleScriptString(message), appleScriptString(title))}
+	}},
+}
+
+// appleScriptString quotes s as an AppleScript string literal
+func appleScriptString(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// ringBell sounds the terminal bell
+func ringBell() error {
+	_, err := os.Stdout.WriteString("\a")
+	return err
+}
+
+// sendDesktopNotification shows a desktop notification. Without
+// notify-send or osascript it falls back to the OSC 9 escape sequence,
+// which terminals such as iTerm2, WezTerm and Windows Terminal turn into a
+// notification.
+func sendDesktopNotification(title, message string) error {
+	for _, command := range notifyCommands {
+		path, err := exec.LookPath(command.name)
+		if err != nil {
+			continue
+		}
+		if err := exec.Command(path, command.args(title, message)...).Run(); err != nil {
+			return fmt.Errorf("%s: %w", command.name, err)
+		}
+		return nil
+	}
+
+	_, err := os.Stdout.WriteString("\x1b]9;" + title + ": " + strings.ReplaceAll(message, "\a", "") + "\a")
+	return err
+}
+
+// completionMessage describes how a query that ran out of view finished
+func completionMessage(tab int, stats *queryStats) string {
+	if stats.failed {
+		return fmt.Sprintf("Query in tab %d failed after %s", tab, formatDuration(stats.duration))
+	}
+	return fmt.Sprintf("Query in tab %d finished in %s: %d rows", tab, formatDuration(stats.duration), stats.rows)
+}
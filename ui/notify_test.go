@@ -0,0 +1,47 @@
+package ui
+
+import (
+	"testing"
+	"time"
+
+	"github.com/TFMV/trino-cli/config"
+)
+
+func TestNotifyThreshold(t *testing.T) {
+	tests := []struct {
+		after     string
+		threshold time.Duration
+		enabled   bool
+		invalid   bool
+	}{
+		{"", defaultNotifyAfter, true, false},
+		{"2m", 2 * time.Minute, true, false},
+		{"0s", 0, true, false},
+		{"Off", 0, false, false},
+		{"soon", defaultNotifyAfter, true, true},
+		{"-5s", defaultNotifyAfter, true, true},
+	}
+	for _, tt := range tests {
+		threshold, enabled, err := notifyThreshold(config.Notify{After: tt.after})
+		if threshold != tt.threshold || enabled != tt.enabled || (err != nil) != tt.invalid {
+			t.Errorf("notifyThreshold(%q) = %v, %v, %v", tt.after, threshold, enabled, err)
+		}
+	}
+}
+
+func TestAppleScriptString(t *testing.T) {
+	if got, want := appleScriptString(`say "hi" \ bye`), `"say \"hi\" \\ bye"`; got != want {
+		t.Errorf("appleScriptString = %s, want %s", got, want)
+	}
+}
+
+func TestCompletionMessage(t *testing.T) {
+	done := &queryStats{duration: 75 * time.Second, rows: 12}
+	if got, want := completionMessage(2, done), "Query in tab 2 finished in 1m15s: 12 rows"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	failed := &queryStats{duration: 12300 * time.Millisecond, failed: true}
+	if got, want := completionMessage(1, failed), "Query in tab 1 failed after 12.3s"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
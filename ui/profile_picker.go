@@ -0,0 +1,126 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/TFMV/trino-cli/config"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// ProfileNames returns the names of the configured profiles, sorted
+func ProfileNames() []string {
+	names := make([]string, 0, len(config.AppConfig.Profiles))
+	for name := range config.AppConfig.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// profileSummary describes where a profile connects, for the picker
+func profileSummary(p config.Profile) string {
+	summary := fmt.Sprintf("%s@%s:%d", p.User, p.Host, p.Port)
+	switch {
+	case p.Catalog != "" && p.Schema != "":
+		summary += "  " + p.Catalog + "." + p.Schema
+	case p.Catalog != "":
+		summary += "  " + p.Catalog
+	}
+	return summary
+}
+
+// newProfileList lists the profiles with current selected. Enter calls
+// onSelect with the chosen profile and Esc calls onCancel.
+func newProfileList(current string, onSelect func(name string), onCancel func()) *tview.List {
+	list := tview.NewList().
+		ShowSecondaryText(true).
+		SetHighlightFullLine(true).
+		SetSelectedStyle(currentTheme.selectedStyle())
+	names := ProfileNames()
+	for i, name := range names {
+		label := tview.Escape(name)
+		if name == current {
+			label += " [::d](current)"
+		}
+		list.AddItem(label, tview.Escape(profileSummary(config.AppConfig.Profiles[name])), 0, nil)
+		if name == current {
+			list.SetCurrentItem(i)
+		}
+	}
+	list.SetSelectedFunc(func(index int, _, _ string, _ rune) {
+		onSelect(names[index])
+	})
+	list.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEscape {
+			onCancel()
+			return nil
+		}
+		return event
+	})
+	list.SetBorder(true).
+		SetTitle(" Profiles (Enter to connect, Esc to cancel) ").
+		SetTitleAlign(tview.AlignLeft)
+	return list
+}
+
+// PickProfile asks at startup which profile to connect with, preselecting
+// current. ok is false when the user leaves with Esc or Ctrl+C.
+func PickProfile(current string) (name string, ok bool, err error) {
+	ApplyTheme(config.AppConfig.UI)
+	app := tview.NewApplication()
+	list := newProfileList(current, func(chosen string) {
+		name, ok = chosen, true
+		app.Stop()
+	}, app.Stop)
+	if err := app.SetRoot(list, true).Run(); err != nil {
+		return "", false, err
+	}
+	return name, ok, nil
+}
+
+// showProfilePicker replaces the screen with the list of profiles. onSelect
+// gets the chosen profile once its password, if it asks for one and none is
+// known, has been typed in. onClose is always called once the previous root
+// has been restored.
+func showProfilePicker(app *tview.Application, root tview.Primitive, current string, onSelect func(name string), onClose func(status string)) {
+	closePicker := func(status string) {
+		app.SetRoot(root, true)
+		onClose(status)
+	}
+	var list *tview.List
+	list = newProfileList(current, func(name string) {
+		if name == current {
+			closePicker("[yellow]Already connected with profile " + tview.Escape(name))
+			return
+		}
+		p := config.AppConfig.Profiles[name]
+		if !p.NeedsPassword() {
+			closePicker("")
+			onSelect(name)
+			return
+		}
+		password := tview.NewInputField().
+			SetLabel(fmt.Sprintf("Password for %s@%s: ", p.User, p.Host)).
+			SetMaskCharacter('*').
+			SetFieldWidth(0)
+		password.SetDoneFunc(func(key tcell.Key) {
+			switch {
+			case key == tcell.KeyEscape:
+				app.SetRoot(list, true).SetFocus(list)
+			case key == tcell.KeyEnter && password.GetText() != "":
+				config.SetPassword(name, password.GetText())
+				closePicker("")
+				onSelect(name)
+			}
+		})
+		password.SetBorder(true).
+			SetTitle(" Profile " + tview.Escape(name) + " (Enter to connect, Esc to go back) ").
+			SetTitleAlign(tview.AlignLeft)
+		app.SetRoot(password, true).SetFocus(password)
+	}, func() {
+		closePicker("[yellow]Profile switch cancelled")
+	})
+	app.SetRoot(list, true).SetFocus(list)
+}
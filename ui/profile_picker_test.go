@@ -0,0 +1,60 @@
+package ui
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/TFMV/trino-cli/config"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+func TestProfileNames(t *testing.T) {
+	saved := config.AppConfig
+	defer func() { config.AppConfig = saved }()
+	config.AppConfig = config.Config{Profiles: map[string]config.Profile{"prod": {}, "dev": {}, "staging": {}}}
+
+	if got, want := ProfileNames(), []string{"dev", "prod", "staging"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ProfileNames() = %v, want %v", got, want)
+	}
+}
+
+func TestProfileSummary(t *testing.T) {
+	tests := []struct {
+		profile config.Profile
+		want    string
+	}{
+		{config.Profile{User: "ana", Host: "trino.prod", Port: 443, Catalog: "hive", Schema: "sales"}, "ana@trino.prod:443  hive.sales"},
+		{config.Profile{User: "ana", Host: "localhost", Port: 8080, Catalog: "hive"}, "ana@localhost:8080  hive"},
+		{config.Profile{User: "ana", Host: "localhost", Port: 8080}, "ana@localhost:8080"},
+	}
+	for _, tt := range tests {
+		if got := profileSummary(tt.profile); got != tt.want {
+			t.Errorf("profileSummary(%+v) = %q, want %q", tt.profile, got, tt.want)
+		}
+	}
+}
+
+func TestProfileList(t *testing.T) {
+	saved := config.AppConfig
+	defer func() { config.AppConfig = saved }()
+	config.AppConfig = config.Config{Profiles: map[string]config.Profile{"dev": {}, "prod": {}, "staging": {}}}
+
+	var selected string
+	cancelled := false
+	list := newProfileList("prod", func(name string) { selected = name }, func() { cancelled = true })
+	if list.GetCurrentItem() != 1 {
+		t.Errorf("selected item = %d, want the current profile", list.GetCurrentItem())
+	}
+
+	handler := list.InputHandler()
+	handler(tcell.NewEventKey(tcell.KeyDown, 0, tcell.ModNone), func(p tview.Primitive) {})
+	handler(tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone), func(p tview.Primitive) {})
+	if selected != "staging" {
+		t.Errorf("Enter chose %q, want staging", selected)
+	}
+
+	if event := list.GetInputCapture()(tcell.NewEventKey(tcell.KeyEscape, 0, tcell.ModNone)); event != nil || !cancelled {
+		t.Error("expected Esc to cancel")
+	}
+}
@@ -0,0 +1,222 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/TFMV/trino-cli/engine"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// serverQueryLimit caps how many server-side queries the panel lists
+const serverQueryLimit = 50
+
+// localQuery is a query the shell has in flight in one of its tabs
+type localQuery struct {
+	tab     int
+	query   string
+	started time.Time
+	cancel  context.CancelFunc
+}
+
+// queryEntry is one row of the running-queries panel: a tab's query or one
+// from system.runtime.queries
+type queryEntry struct {
+	local  *localQuery
+	server *engine.ServerQuery
+}
+
+// queryEntries lists the tabs' queries first, then the server's
+func queryEntries(local []localQuery, server []engine.ServerQuery) []queryEntry {
+	entries := make([]queryEntry, 0, len(local)+len(server))
+	for i := range local {
+		entries = append(entries, queryEntry{local: &local[i]})
+	}
+	for i := range server {
+		entries = append(entries, queryEntry{server: &server[i]})
+	}
+	return entries
+}
+
+// cells returns where the query runs, its ID, state, elapsed time and text
+func (e queryEntry) cells(now time.Time) []string {
+	if q := e.local; q != nil {
+		return []string{fmt.Sprintf("Tab %d", q.tab), "", "RUNNING", formatDuration(now.Sub(q.started)), oneLine(q.query)}
+	}
+	q := e.server
+	return []string{"Server", q.ID, q.State, formatDuration(q.Elapsed(now)), oneLine(q.Query)}
+}
+
+// killable reports whether the entry is still going
+func (e queryEntry) killable() bool {
+	return e.local != nil || !e.server.Done()
+}
+
+// describe names the entry in confirmations and status messages
+func (e queryEntry) describe() string {
+	if e.local != nil {
+		return fmt.Sprintf("the query in tab %d", e.local.tab)
+	}
+	return "query " + e.server.ID
+}
+
+// oneLine collapses whitespace so a query fits on one table row
+func oneLine(query string) string {
+	return strings.Join(strings.Fields(query), " ")
+}
+
+// showQueriesPanel replaces the screen with the queries the shell has in
+// flight, from local, and the current user's queries on the server. k or
+// Delete kills the selected query after a y confirmation, r refreshes, and
+// Escape or q closes the panel. onClose is called with a status message
+// after the previous root has been restored.
+func showQueriesPanel(ctx context.Context, app *tview.Application, root tview.Primitive, profile string, local func() []localQuery, onClose func(status string)) {
+	table := tview.NewTable().
+		SetFixed(1, 0).
+		SetSelectable(true, false).
+		SetSelectedStyle(currentTheme.selectedStyle())
+	footer := tview.NewTextView().
+		SetDynamicColors(true)
+	const help = "[gray]k kill · r refresh · Esc close"
+	footer.SetText(help)
+
+	var entries []queryEntry
+	var server []engine.ServerQuery
+	var pending *queryEntry // Waiting for y to confirm the kill
+	closed := false
+	loading := true
+
+	render := func() {
+		row, _ := table.GetSelection()
+		entries = queryEntries(local(), server)
+		table.Clear()
+		for column, title := range []string{"Where", "Query ID", "State", "Elapsed", "Query"} {
+			table.SetCell(0, column, tview.NewTableCell(title).
+				SetTextColor(currentTheme.headerColor()).
+				SetSelectable(false))
+		}
+		now := time.Now()
+		for i, entry := range entries {
+			for column, text := range entry.cells(now) {
+				cell := tview.NewTableCell(tview.Escape(text))
+				if column == 4 {
+					cell.SetExpansion(1)
+				}
+				if !entry.killable() {
+					cell.SetTextColor(currentTheme.nullColor())
+				}
+				table.SetCell(i+1, column, cell)
+			}
+		}
+		if len(entries) == 0 {
+			text := "No queries"
+			if loading {
+				text = "Loading…"
+			}
+			table.SetCell(1, 0, tview.NewTableCell(text).SetSelectable(false))
+			return
+		}
+		table.Select(max(1, min(row, len(entries))), 0)
+	}
+
+	reload := func() {
+		loading = true
+		render()
+		go func() {
+			queries, err := engine.ListServerQueries(ctx, profile, engine.ServerQueryFilter{Limit: serverQueryLimit})
+			app.QueueUpdateDraw(func() {
+				if closed {
+					return
+				}
+				loading = false
+				if err != nil {
+					footer.SetText(fmt.Sprintf("[red]Server queries unavailable: %s", tview.Escape(err.Error())))
+				} else {
+					server = queries
+				}
+				render()
+			})
+		}()
+	}
+
+	closePanel := func(status string) {
+		closed = true
+		app.SetRoot(root, true)
+		onClose(status)
+	}
+
+	kill := func(entry queryEntry) {
+		if entry.local != nil {
+			entry.local.cancel()
+			footer.SetText(fmt.Sprintf("[green]Cancelled %s", entry.describe()))
+			reload()
+			return
+		}
+		footer.SetText(fmt.Sprintf("[yellow]Killing %s…", entry.describe()))
+		go func() {
+			err := engine.KillQuery(ctx, profile, entry.server.ID)
+			app.QueueUpdateDraw(func() {
+				if closed {
+					return
+				}
+				if err != nil {
+					footer.SetText(fmt.Sprintf("[red]%s", tview.Escape(err.Error())))
+					return
+				}
+				footer.SetText(fmt.Sprintf("[green]Killed %s", entry.describe()))
+				reload()
+			})
+		}()
+	}
+
+	table.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if entry := pending; entry != nil {
+			pending = nil
+			if isRune(event, 'y') || isRune(event, 'Y') {
+				kill(*entry)
+			} else {
+				footer.SetText(help)
+			}
+			return nil
+		}
+
+		switch {
+		case event.Key() == tcell.KeyEscape, isRune(event, 'q'), event.Key() == tcell.KeyCtrlQ:
+			closePanel("")
+			return nil
+		case isRune(event, 'r'), event.Key() == tcell.KeyF5:
+			footer.SetText(help)
+			reload()
+			return nil
+		case isRune(event, 'k'), event.Key() == tcell.KeyDelete:
+			row, _ := table.GetSelection()
+			if row < 1 || row > len(entries) {
+				return nil
+			}
+			entry := entries[row-1]
+			if !entry.killable() {
+				footer.SetText(fmt.Sprintf("[yellow]Query %s has already %s", entry.server.ID, strings.ToLower(entry.server.State)))
+				return nil
+			}
+			pending = &entry
+			footer.SetText(fmt.Sprintf("[yellow]Kill %s? (y/n)", entry.describe()))
+			return nil
+		}
+		return event
+	})
+
+	reload()
+
+	layout := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(table, 0, 1, true).
+		AddItem(footer, 1, 0, false)
+	layout.SetBorder(true).
+		SetTitle(" Running queries ").
+		SetTitleAlign(tview.AlignLeft)
+
+	app.SetRoot(layout, true).SetFocus(table)
+}
@@ -0,0 +1,51 @@
+package ui
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/TFMV/trino-cli/engine"
+)
+
+func TestQueryEntries(t *testing.T) {
+	now := time.Date(2024, 3, 5, 14, 0, 0, 0, time.UTC)
+	cancelled := false
+	local := []localQuery{{tab: 2, query: "SELECT\n  1", started: now.Add(-3 * time.Second), cancel: func() { cancelled = true }}}
+	server := []engine.ServerQuery{
+		{ID: "20240305_135955_00001_abcde", State: "RUNNING", Query: "SELECT 1", Created: now.Add(-5 * time.Second)},
+		{ID: "20240305_130000_00002_abcde", State: "FINISHED", Query: "SHOW TABLES", Created: now.Add(-time.Hour), Ended: now.Add(-time.Hour + 2*time.Second)},
+	}
+
+	entries := queryEntries(local, server)
+	if len(entries) != 3 {
+		t.Fatalf("got %d entries, want 3", len(entries))
+	}
+
+	tests := []struct {
+		cells    []string
+		killable bool
+		describe string
+	}{
+		{[]string{"Tab 2", "", "RUNNING", "3s", "SELECT 1"}, true, "the query in tab 2"},
+		{[]string{"Server", "20240305_135955_00001_abcde", "RUNNING", "5s", "SELECT 1"}, true, "query 20240305_135955_00001_abcde"},
+		{[]string{"Server", "20240305_130000_00002_abcde", "FINISHED", "2s", "SHOW TABLES"}, false, "query 20240305_130000_00002_abcde"},
+	}
+	for i, tt := range tests {
+		entry := entries[i]
+		if got := entry.cells(now); !slices.Equal(got, tt.cells) {
+			t.Errorf("entry %d cells = %q, want %q", i, got, tt.cells)
+		}
+		if entry.killable() != tt.killable {
+			t.Errorf("entry %d killable = %v", i, entry.killable())
+		}
+		if got := entry.describe(); got != tt.describe {
+			t.Errorf("entry %d describe = %q", i, got)
+		}
+	}
+
+	entries[0].local.cancel()
+	if !cancelled {
+		t.Error("a tab entry should cancel through the tab's cancel function")
+	}
+}
@@ -0,0 +1,94 @@
+package ui
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/trinodb/trino-go-client/trino"
+)
+
+// errorLinePrefix matches the "line 1:8: " Trino puts before the message of
+// errors it can locate in the query
+var errorLinePrefix = regexp.MustCompile(`line (\d+):(\d+): `)
+
+// errorLocation finds where in the query Trino located err, as a 1-based
+// line and column, and returns the message without its position prefix.
+// ok is false for errors with no position, such as a missing table.
+func errorLocation(err error) (line, column int, message string, ok bool) {
+	var trinoErr *trino.ErrTrino
+	if errors.As(err, &trinoErr) {
+		message = strings.TrimSpace(trinoErr.Message)
+		if loc := trinoErr.ErrorLocation; loc.LineNumber > 0 && loc.ColumnNumber > 0 {
+			if m := errorLinePrefix.FindStringIndex(message); m != nil && m[0] == 0 {
+				message = message[m[1]:]
+			}
+			return loc.LineNumber, loc.ColumnNumber, message, true
+		}
+	} else {
+		message = err.Error()
+	}
+
+	// Errors that lost their structure on the way still carry the prefix
+	m := errorLinePrefix.FindStringSubmatchIndex(message)
+	if m == nil {
+		return 0, 0, "", false
+	}
+	line, _ = strconv.Atoi(message[m[2]:m[3]])
+	column, _ = strconv.Atoi(message[m[4]:m[5]])
+	if line < 1 || column < 1 {
+		return 0, 0, "", false
+	}
+	return line, column, message[m[1]:], true
+}
+
+// errorSpan converts a 1-based line and column in query into rune offsets
+// covering the word there, or the single character when it is not part of
+// a word. A position past the end of the query (an unexpected end of
+// input) marks the last character.
+func errorSpan(query string, line, column int) (start, end int) {
+	runes := []rune(query)
+	offset := 0
+	for l := 1; l < line && offset < len(runes); offset++ {
+		if runes[offset] == '\n' {
+			l++
+		}
+	}
+	start = offset + column - 1
+	for i := offset; i < start && i < len(runes); i++ {
+		if runes[i] == '\n' {
+			// The column runs past the end of its line
+			start = i
+			break
+		}
+	}
+	if start >= len(runes) {
+		if len(runes) == 0 {
+			return 0, 0
+		}
+		return len(runes) - 1, len(runes)
+	}
+
+	end = start + 1
+	if isWordRune(runes[start]) {
+		for end < len(runes) && isWordRune(runes[end]) {
+			end++
+		}
+	}
+	return start, end
+}
+
+// moveCursor puts the editor's cursor at a rune offset into its text
+func moveCursor(input *tview.InputField, offset int) {
+	handle := input.InputHandler()
+	noFocus := func(tview.Primitive) {}
+	for range []rune(input.GetText()) {
+		handle(tcell.NewEventKey(tcell.KeyLeft, 0, tcell.ModNone), noFocus)
+	}
+	for i := 0; i < offset; i++ {
+		handle(tcell.NewEventKey(tcell.KeyRight, 0, tcell.ModNone), noFocus)
+	}
+}
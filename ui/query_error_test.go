@@ -0,0 +1,73 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/rivo/tview"
+	"github.com/trinodb/trino-go-client/trino"
+)
+
+func TestErrorLocation(t *testing.T) {
+	located := &trino.ErrQueryFailed{StatusCode: 200, Reason: &trino.ErrTrino{
+		Message:       "line 1:8: mismatched input 'FORM'. Expecting: ',', 'FROM'",
+		ErrorName:     "SYNTAX_ERROR",
+		ErrorLocation: trino.ErrorLocation{LineNumber: 1, ColumnNumber: 8},
+	}}
+	line, column, message, ok := errorLocation(fmt.Errorf("query failed: %w", located))
+	if !ok || line != 1 || column != 8 || message != "mismatched input 'FORM'. Expecting: ',', 'FROM'" {
+		t.Errorf("errorLocation = %d, %d, %q, %v", line, column, message, ok)
+	}
+
+	line, column, message, ok = errorLocation(errors.New("line 2:3: Column 'x' cannot be resolved"))
+	if !ok || line != 2 || column != 3 || message != "Column 'x' cannot be resolved" {
+		t.Errorf("errorLocation from text = %d, %d, %q, %v", line, column, message, ok)
+	}
+
+	missing := &trino.ErrQueryFailed{Reason: &trino.ErrTrino{Message: "Table 'x' does not exist"}}
+	if _, _, _, ok := errorLocation(missing); ok {
+		t.Error("an error without a position should not be located")
+	}
+}
+
+func TestErrorSpan(t *testing.T) {
+	tests := []struct {
+		query        string
+		line, column int
+		start, end   int
+	}{
+		{"SELECT * FORM t", 1, 10, 9, 13},
+		{"SELECT 1 +", 1, 11, 9, 10}, // Unexpected end of input
+		{"SELECT a,\n  ,b FROM t", 2, 3, 12, 13},
+		{"SELECT a\nFROM tbl x y", 2, 12, 20, 21},
+		{"", 1, 1, 0, 0},
+	}
+	for _, tt := range tests {
+		if start, end := errorSpan(tt.query, tt.line, tt.column); start != tt.start || end != tt.end {
+			t.Errorf("errorSpan(%q, %d, %d) = %d, %d, want %d, %d", tt.query, tt.line, tt.column, start, end, tt.start, tt.end)
+		}
+	}
+}
+
+func TestMoveCursorAndMark(t *testing.T) {
+	input := tview.NewInputField()
+	input.SetText("SELECT * FORM t")
+	moveCursor(input, 9)
+
+	// Typing at the cursor shows where it is
+	input.PasteHandler()("x", func(tview.Primitive) {})
+	if got := input.GetText(); got != "SELECT * xFORM t" {
+		t.Errorf("text after typing = %q", got)
+	}
+
+	editor := newHighlightInput(input, dark, true)
+	editor.markError(10, 14)
+	if !editor.marked(input.GetText()) {
+		t.Error("the mark should apply to the marked query")
+	}
+	input.SetText("SELECT * FROM t")
+	if editor.marked(input.GetText()) {
+		t.Error("editing the query should drop the mark")
+	}
+}
@@ -0,0 +1,68 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rivo/tview"
+)
+
+// verticalSuffix ends a query whose result should open in vertical mode,
+// as in the mysql client
+const verticalSuffix = `\G`
+
+// splitVerticalSuffix strips a trailing \G from query and reports whether
+// it was there
+func splitVerticalSuffix(query string) (string, bool) {
+	trimmed := strings.TrimSpace(query)
+	if !strings.HasSuffix(trimmed, verticalSuffix) {
+		return query, false
+	}
+	return strings.TrimSpace(strings.TrimSuffix(trimmed, verticalSuffix)), true
+}
+
+// record formats the i-th displayed row as a list of column names and
+// values, one per line. Multi-line values such as pretty-printed JSON are
+// indented under their column.
+func (p *resultPages) record(i int) string {
+	values := p.row(i)
+
+	width := 0
+	for _, column := range p.result.Columns {
+		width = max(width, tview.TaggedStringWidth(tview.Escape(column)))
+	}
+	pad := func(name string) string {
+		escaped := tview.Escape(name)
+		return strings.Repeat(" ", width-tview.TaggedStringWidth(escaped)) + escaped
+	}
+
+	header := currentTheme.Header
+	if header == "" {
+		header = "green"
+	}
+	null := currentTheme.Null
+	if null == "" {
+		null = "gray"
+	}
+
+	var b strings.Builder
+	for column, name := range p.result.Columns {
+		var value interface{}
+		if column < len(values) {
+			value = values[column]
+		}
+		var typeName string
+		if column < len(p.result.Types) {
+			typeName = p.result.Types[column]
+		}
+
+		if value == nil {
+			fmt.Fprintf(&b, "[%s]%s[-]: [%s::di]%s[-::-]\n", header, pad(name), null, tview.Escape(currentFormat.null))
+			continue
+		}
+		text := formatCellValue(value, typeName)
+		text = strings.ReplaceAll(text, "\n", "\n"+strings.Repeat(" ", width+2))
+		fmt.Fprintf(&b, "[%s]%s[-]: %s\n", header, pad(name), tview.Escape(text))
+	}
+	return b.String()
+}
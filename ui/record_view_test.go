@@ -0,0 +1,49 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/TFMV/trino-cli/engine"
+)
+
+func TestSplitVerticalSuffix(t *testing.T) {
+	tests := []struct {
+		query    string
+		want     string
+		vertical bool
+	}{
+		{`SELECT * FROM t\G`, "SELECT * FROM t", true},
+		{"SELECT * FROM t \\G  ", "SELECT * FROM t", true},
+		{"SELECT * FROM t", "SELECT * FROM t", false},
+		{`SELECT '\G'`, `SELECT '\G'`, false},
+	}
+	for _, tt := range tests {
+		got, vertical := splitVerticalSuffix(tt.query)
+		if got != tt.want || vertical != tt.vertical {
+			t.Errorf("splitVerticalSuffix(%q) = %q, %v; want %q, %v", tt.query, got, vertical, tt.want, tt.vertical)
+		}
+	}
+}
+
+func TestResultPagesRecord(t *testing.T) {
+	pages := newResultPages(&engine.QueryResult{
+		Columns: []string{"id", "payload"},
+		Types:   []string{"BIGINT", "JSON"},
+		Rows: [][]interface{}{
+			{int64(1), `{"a":[1]}`},
+			{int64(2), nil},
+		},
+	})
+
+	want := "[green]     id[-]: 1\n" +
+		"[green]payload[-]: {\n           \"a\": [\n             1\n           ]\n         }\n"
+	if got := pages.record(0); got != want {
+		t.Errorf("record(0) =\n%s\nwant\n%s", got, want)
+	}
+
+	pages.sortBy(0)
+	pages.sortBy(0) // descending
+	if got, want := pages.record(0), "[green]     id[-]: 2\n[green]payload[-]: [gray::di]NULL[-::-]\n"; got != want {
+		t.Errorf("record(0) after sorting = %q, want %q", got, want)
+	}
+}
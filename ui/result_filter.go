@@ -0,0 +1,95 @@
+package ui
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// rowFilter reports whether a result row should stay visible
+type rowFilter func(row []interface{}) bool
+
+// comparisonPattern matches simple expressions such as `price >= 10`,
+// `"order id" != 3` or `status = 'open'`
+var comparisonPattern = regexp.MustCompile(`^\s*("[^"]+"|[\p{L}_][\p{L}\p{N}_]*)\s*(=|==|!=|<>|>=|<=|>|<)\s*(.+?)\s*$`)
+
+// parseRowFilter builds a filter from the text typed into the result filter
+// prompt. A comparison against a known column filters on that column; any
+// other text keeps rows where some cell contains it, ignoring case.
+func parseRowFilter(expr string, columns, types []string) (rowFilter, error) {
+	expr = strings.TrimSpace(expr)
+	if expr == "" {
+		return nil, nil
+	}
+
+	if m := comparisonPattern.FindStringSubmatch(expr); m != nil {
+		name := strings.Trim(m[1], `"`)
+		for i, column := range columns {
+			if !strings.EqualFold(column, name) {
+				continue
+			}
+			var typeName string
+			if i < len(types) {
+				typeName = types[i]
+			}
+			return comparisonFilter(i, m[2], m[3], typeName)
+		}
+		if strings.HasPrefix(m[1], `"`) {
+			return nil, fmt.Errorf("unknown column %s", m[1])
+		}
+	}
+
+	needle := strings.ToLower(expr)
+	return func(row []interface{}) bool {
+		for _, value := range row {
+			if value != nil && strings.Contains(strings.ToLower(fmt.Sprint(value)), needle) {
+				return true
+			}
+		}
+		return false
+	}, nil
+}
+
+// comparisonFilter keeps rows whose value in column compares to literal as op
+// requires. NULL cells never match.
+func comparisonFilter(column int, op, literal, typeName string) (rowFilter, error) {
+	var operand interface{} = literal
+	if strings.HasPrefix(literal, "'") {
+		if len(literal) < 2 || !strings.HasSuffix(literal, "'") {
+			return nil, fmt.Errorf("unterminated string %s", literal)
+		}
+		operand = strings.ReplaceAll(literal[1:len(literal)-1], "''", "'")
+	}
+
+	// Compare by magnitude when the column is numeric or the operand is an
+	// unquoted number, so `id > 9` works even without type metadata
+	numeric := isNumericType(typeName)
+	if s, ok := operand.(string); ok && s == literal {
+		if _, err := strconv.ParseFloat(literal, 64); err == nil {
+			numeric = true
+		}
+	}
+
+	return func(row []interface{}) bool {
+		if column >= len(row) || row[column] == nil {
+			return false
+		}
+		c := compareValues(row[column], operand, numeric)
+		switch op {
+		case "=", "==":
+			return c == 0
+		case "!=", "<>":
+			return c != 0
+		case ">":
+			return c > 0
+		case ">=":
+			return c >= 0
+		case "<":
+			return c < 0
+		case "<=":
+			return c <= 0
+		}
+		return false
+	}, nil
+}
@@ -0,0 +1,100 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/TFMV/trino-cli/engine"
+)
+
+func TestParseRowFilter(t *testing.T) {
+	columns := []string{"id", "name", "price"}
+	types := []string{"BIGINT", "VARCHAR", "DECIMAL(10,2)"}
+	rows := [][]interface{}{
+		{int64(5), "Widget", "9.50"},
+		{int64(12), "gadget", "10.00"},
+		{int64(30), "It's", nil},
+	}
+
+	tests := []struct {
+		expr string
+		want []bool
+	}{
+		{"id > 10", []bool{false, true, true}},
+		{"ID <= 12", []bool{true, true, false}},
+		{"price >= 9.75", []bool{false, true, false}},
+		{"name = 'gadget'", []bool{false, true, false}},
+		{"name != 'gadget'", []bool{true, false, true}},
+		{`"name" = 'It''s'`, []bool{false, false, true}},
+		{"GADGET", []bool{false, true, false}},
+		{"unknown > 3", []bool{false, false, false}},
+	}
+	for _, tt := range tests {
+		filter, err := parseRowFilter(tt.expr, columns, types)
+		if err != nil {
+			t.Errorf("parseRowFilter(%q): %v", tt.expr, err)
+			continue
+		}
+		for i, row := range rows {
+			if got := filter(row); got != tt.want[i] {
+				t.Errorf("%q on row %d = %v, want %v", tt.expr, i, got, tt.want[i])
+			}
+		}
+	}
+
+	if filter, err := parseRowFilter("  ", columns, types); err != nil || filter != nil {
+		t.Errorf("blank expression = %v, %v; want no filter", filter, err)
+	}
+	if _, err := parseRowFilter(`"missing" = 1`, columns, types); err == nil {
+		t.Error("expected an error for an unknown quoted column")
+	}
+	if _, err := parseRowFilter("name = 'open", columns, types); err == nil {
+		t.Error("expected an error for an unterminated string")
+	}
+}
+
+func TestResultPagesFilter(t *testing.T) {
+	pages := newResultPages(&engine.QueryResult{
+		Columns: []string{"n"},
+		Types:   []string{"INTEGER"},
+		Rows:    [][]interface{}{{int64(3)}, {int64(1)}, {int64(4)}, {int64(2)}},
+	})
+	pages.sortBy(0)
+	if err := pages.setFilter("n >= 2"); err != nil {
+		t.Fatal(err)
+	}
+	if got := pages.GetRowCount(); got != 4 {
+		t.Errorf("rows with header = %d, want 4", got)
+	}
+	if got := pages.GetCell(1, 0).Text; got != "2" {
+		t.Errorf("first filtered row = %q, want 2 (sort kept)", got)
+	}
+	if err := pages.setFilter(""); err != nil {
+		t.Fatal(err)
+	}
+	if got := pages.rowCount(); got != 4 {
+		t.Errorf("rows after clearing the filter = %d, want 4", got)
+	}
+}
+
+func TestResultPagesDisplayed(t *testing.T) {
+	result := &engine.QueryResult{
+		Columns: []string{"n"},
+		Rows:    [][]interface{}{{int64(3)}, {int64(1)}, {int64(2)}},
+	}
+	pages := newResultPages(result)
+	if pages.displayed() != result {
+		t.Error("unfiltered, unsorted pages should display the original result")
+	}
+
+	pages.sortBy(0)
+	if err := pages.setFilter("n > 1"); err != nil {
+		t.Fatal(err)
+	}
+	got := pages.displayed()
+	if len(got.Rows) != 2 || got.Rows[0][0] != int64(2) || got.Rows[1][0] != int64(3) {
+		t.Errorf("displayed rows = %v, want [[2] [3]]", got.Rows)
+	}
+	if len(result.Rows) != 3 || result.Rows[0][0] != int64(3) {
+		t.Error("the original result was modified")
+	}
+}
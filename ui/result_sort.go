@@ -0,0 +1,228 @@
+package ui
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// numericTypes are the Trino types whose values sort by magnitude even when
+// the driver returns them as strings (DECIMAL does)
+var numericTypes = map[string]bool{
+	"TINYINT": true, "SMALLINT": true, "INTEGER": true, "BIGINT": true,
+	"REAL": true, "DOUBLE": true, "DECIMAL": true,
+}
+
+// isNumericType reports whether a Trino type name is numeric. Parameters
+// such as DECIMAL(10,2) are ignored.
+func isNumericType(typeName string) bool {
+	if i := strings.IndexByte(typeName, '('); i >= 0 {
+		typeName = typeName[:i]
+	}
+	return numericTypes[strings.ToUpper(strings.TrimSpace(typeName))]
+}
+
+// sortRowIndexes orders indexes into rows by column. NULLs always sort last
+// so they don't crowd the top of the table in either direction.
+func sortRowIndexes(order []int, rows [][]interface{}, column int, typeName string, desc bool) {
+	numeric := isNumericType(typeName)
+	value := func(i int) interface{} {
+		if column < len(rows[i]) {
+			return rows[i][column]
+		}
+		return nil
+	}
+
+	sort.SliceStable(order, func(a, b int) bool {
+		va, vb := value(order[a]), value(order[b])
+		if va == nil || vb == nil {
+			return va != nil && vb == nil
+		}
+		c := compareValues(va, vb, numeric)
+		if desc {
+			return c > 0
+		}
+		return c < 0
+	})
+}
+
+// compareValues orders two non-nil values. Numbers compare by magnitude,
+// including numeric strings when the column type is numeric; times compare
+// chronologically; everything else compares as text.
+func compareValues(a, b interface{}, numeric bool) int {
+	if c, ok := compareNumbers(a, b, numeric); ok {
+		return c
+	}
+	if ta, ok := a.(time.Time); ok {
+		if tb, ok := b.(time.Time); ok {
+			return ta.Compare(tb)
+		}
+	}
+	if ba, ok := a.(bool); ok {
+		if bb, ok := b.(bool); ok {
+			switch {
+			case ba == bb:
+				return 0
+			case !ba:
+				return -1
+			}
+			return 1
+		}
+	}
+	return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+}
+
+// compareNumbers orders two numbers without rounding them: integers compare
+// as integers, DECIMAL strings as exact fractions, and only REAL and DOUBLE
+// values, or strings that are not fractions such as "NaN", as floats. ok is
+// false unless both are numbers.
+func compareNumbers(a, b interface{}, parseStrings bool) (int, bool) {
+	if c, ok := compareIntegers(a, b); ok {
+		return c, true
+	}
+	if fa, ok := toFloat(a, false); ok && isFloat(a) {
+		if fb, ok := toFloat(b, false); ok && isFloat(b) {
+			return compareFloats(fa, fb), true
+		}
+	}
+	if ra, ok := toRat(a, parseStrings); ok {
+		if rb, ok := toRat(b, parseStrings); ok {
+			return ra.Cmp(rb), true
+		}
+	}
+	if fa, ok := toFloat(a, parseStrings); ok {
+		if fb, ok := toFloat(b, parseStrings); ok {
+			return compareFloats(fa, fb), true
+		}
+	}
+	return 0, false
+}
+
+// compareIntegers orders two integers of any Go integer type exactly
+func compareIntegers(a, b interface{}) (int, bool) {
+	ia, ua, unsignedA, ok := toInteger(a)
+	if !ok {
+		return 0, false
+	}
+	ib, ub, unsignedB, ok := toInteger(b)
+	if !ok {
+		return 0, false
+	}
+	switch {
+	case !unsignedA && !unsignedB:
+		return cmp.Compare(ia, ib), true
+	case unsignedA && unsignedB:
+		return cmp.Compare(ua, ub), true
+	case !unsignedA: // b is unsigned
+		if ia < 0 {
+			return -1, true
+		}
+		return cmp.Compare(uint64(ia), ub), true
+	default: // a is unsigned
+		if ib < 0 {
+			return 1, true
+		}
+		return cmp.Compare(ua, uint64(ib)), true
+	}
+}
+
+// toInteger widens signed integers to int64 and unsigned ones to uint64
+func toInteger(v interface{}) (i int64, u uint64, unsigned bool, ok bool) {
+	switch n := v.(type) {
+	case int:
+		return int64(n), 0, false, true
+	case int8:
+		return int64(n), 0, false, true
+	case int16:
+		return int64(n), 0, false, true
+	case int32:
+		return int64(n), 0, false, true
+	case int64:
+		return n, 0, false, true
+	case uint:
+		return 0, uint64(n), true, true
+	case uint8:
+		return 0, uint64(n), true, true
+	case uint16:
+		return 0, uint64(n), true, true
+	case uint32:
+		return 0, uint64(n), true, true
+	case uint64:
+		return 0, n, true, true
+	}
+	return 0, 0, false, false
+}
+
+// isFloat reports whether v is a REAL or DOUBLE value
+func isFloat(v interface{}) bool {
+	switch v.(type) {
+	case float32, float64:
+		return true
+	}
+	return false
+}
+
+// compareFloats orders two floats, with NaN equal to everything
+func compareFloats(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// toRat converts numbers to exact fractions, parsing strings such as
+// DECIMAL values only when parseStrings is set. Infinities and NaN have no
+// fraction.
+func toRat(v interface{}, parseStrings bool) (*big.Rat, bool) {
+	if i, u, unsigned, ok := toInteger(v); ok {
+		if unsigned {
+			return new(big.Rat).SetUint64(u), true
+		}
+		return new(big.Rat).SetInt64(i), true
+	}
+	switch n := v.(type) {
+	case float32:
+		return toRat(float64(n), parseStrings)
+	case float64:
+		if math.IsInf(n, 0) || math.IsNaN(n) {
+			return nil, false
+		}
+		return new(big.Rat).SetFloat64(n), true
+	case string:
+		if parseStrings {
+			return new(big.Rat).SetString(strings.TrimSpace(n))
+		}
+	}
+	return nil, false
+}
+
+// toFloat converts numeric values to float64. Strings are parsed only when
+// parseStrings is set, so a VARCHAR column of digits still sorts as text.
+func toFloat(v interface{}, parseStrings bool) (float64, bool) {
+	if i, u, unsigned, ok := toInteger(v); ok {
+		if unsigned {
+			return float64(u), true
+		}
+		return float64(i), true
+	}
+	switch n := v.(type) {
+	case float32:
+		return float64(n), true
+	case float64:
+		return n, true
+	case string:
+		if parseStrings {
+			f, err := strconv.ParseFloat(strings.TrimSpace(n), 64)
+			return f, err == nil
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,93 @@
+package ui
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/TFMV/trino-cli/engine"
+)
+
+// sortedOrder sorts every row index of rows
+func sortedOrder(rows [][]interface{}, column int, typeName string, desc bool) []int {
+	order := make([]int, len(rows))
+	for i := range order {
+		order[i] = i
+	}
+	sortRowIndexes(order, rows, column, typeName, desc)
+	return order
+}
+
+func TestSortedOrder(t *testing.T) {
+	rows := [][]interface{}{
+		{"10.5"},
+		{nil},
+		{"9.25"},
+		{"100"},
+	}
+
+	// DECIMAL values arrive as strings but sort by magnitude
+	if got, want := sortedOrder(rows, 0, "DECIMAL(10,2)", false), []int{2, 0, 3, 1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ascending decimal order = %v, want %v", got, want)
+	}
+	if got, want := sortedOrder(rows, 0, "DECIMAL(10,2)", true), []int{3, 0, 2, 1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("descending decimal order = %v, want %v", got, want)
+	}
+
+	// The same strings in a VARCHAR column sort as text
+	if got, want := sortedOrder(rows, 0, "VARCHAR", false), []int{0, 3, 2, 1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("varchar order = %v, want %v", got, want)
+	}
+
+	// Native numbers sort numerically even without type metadata
+	ints := [][]interface{}{{int64(10)}, {int64(9)}, {int64(100)}}
+	if got, want := sortedOrder(ints, 0, "", false), []int{1, 0, 2}; !reflect.DeepEqual(got, want) {
+		t.Errorf("untyped integer order = %v, want %v", got, want)
+	}
+}
+
+func TestCompareValuesKeepsPrecision(t *testing.T) {
+	tests := []struct {
+		name     string
+		a, b     interface{}
+		typeName string
+		want     int
+	}{
+		{"adjacent bigints above 2^53", int64(9007199254740993), int64(9007199254740992), "BIGINT", 1},
+		{"adjacent bigints at the maximum", int64(math.MaxInt64 - 1), int64(math.MaxInt64), "BIGINT", -1},
+		{"equal bigints", int64(9007199254740993), int64(9007199254740993), "BIGINT", 0},
+		{"negative and unsigned", int64(-1), uint64(math.MaxUint64), "", -1},
+		{"adjacent unsigned", uint64(math.MaxUint64), uint64(math.MaxUint64 - 1), "", 1},
+		{"bigint strings", "9007199254740993", "9007199254740992", "BIGINT", 1},
+		{"long decimals", "12345678901234567890.000000000000000001", "12345678901234567890", "DECIMAL(38,18)", 1},
+		{"equal decimals", "1.50", "1.5", "DECIMAL(10,2)", 0},
+		{"doubles", 0.1, 0.2, "DOUBLE", -1},
+		{"infinite double", math.Inf(1), 1e308, "DOUBLE", 1},
+		{"integer and double", int64(2), 1.5, "", 1},
+		{"NaN strings", "NaN", "NaN", "DOUBLE", 0},
+	}
+	for _, tt := range tests {
+		if got := compareValues(tt.a, tt.b, isNumericType(tt.typeName)); got != tt.want {
+			t.Errorf("%s: compareValues(%v, %v) = %d, want %d", tt.name, tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestResultPagesSortBy(t *testing.T) {
+	pages := newResultPages(&engine.QueryResult{
+		Columns: []string{"name"},
+		Types:   []string{"VARCHAR"},
+		Rows:    [][]interface{}{{"b"}, {"c"}, {"a"}},
+	})
+	pages.sortBy(0)
+	if got := pages.GetCell(1, 0).Text; got != "a" {
+		t.Errorf("first ascending row = %q, want a", got)
+	}
+	if got := pages.GetCell(0, 0).Text; got != "name ▲" {
+		t.Errorf("header = %q, want sort indicator", got)
+	}
+	pages.sortBy(0)
+	if got := pages.GetCell(1, 0).Text; got != "c" {
+		t.Errorf("first descending row = %q, want c", got)
+	}
+}
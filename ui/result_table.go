@@ -0,0 +1,820 @@
+package ui
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/TFMV/trino-cli/config"
+	"github.com/TFMV/trino-cli/engine"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// resultPageSize is how many result rows the table shows at a time, unless
+// ui.page_size says otherwise
+const resultPageSize = 500
+
+// defaultMaxRows is how many result rows the table renders before L loads
+// more, unless ui.max_rows says otherwise
+const defaultMaxRows = 10000
+
+// Column widths in screen cells. Longer values are cut off with an ellipsis
+// until the column is widened; Enter shows a value in full.
+const (
+	defaultColumnWidth = 40
+	minColumnWidth     = 3
+	columnWidthStep    = 5
+)
+
+// resultLimits bound how much of a result the table renders
+type resultLimits struct {
+	maxRows     int // 0 renders every row
+	pageSize    int
+	columnWidth int
+}
+
+// currentLimits are the limits installed by StartInteractive
+var currentLimits = newResultLimits(config.UI{})
+
+// newResultLimits reads ui.max_rows, ui.page_size and ui.column_width,
+// using the defaults for unset values
+func newResultLimits(settings config.UI) resultLimits {
+	limits := resultLimits{maxRows: defaultMaxRows, pageSize: resultPageSize, columnWidth: defaultColumnWidth}
+	switch {
+	case settings.MaxRows < 0:
+		limits.maxRows = 0
+	case settings.MaxRows > 0:
+		limits.maxRows = settings.MaxRows
+	}
+	if settings.PageSize > 0 {
+		limits.pageSize = settings.PageSize
+	}
+	if settings.ColumnWidth > 0 {
+		limits.columnWidth = max(settings.ColumnWidth, minColumnWidth)
+	}
+	return limits
+}
+
+// resultPages is a virtual table over one page of a query result. Cells are
+// created the first time tview asks for them, so only the rows the user
+// scrolls past are ever materialized.
+type resultPages struct {
+	tview.TableContentReadOnly
+	result     *engine.QueryResult
+	page       int
+	cells      map[[2]int]*tview.TableCell
+	sortColumn int             // -1 while rows are in query order
+	desc       bool            // Sort direction of sortColumn
+	filter     rowFilter       // Rows to keep, nil for all
+	filterText string          // The expression filter was parsed from
+	order      []int           // Row indexes in display order when sorted or filtered
+	changed    map[[2]int]bool // Result row and column of cells that changed in the last watch run
+	widths     map[int]int     // Column widths set by the user
+	limits     resultLimits
+	limit      int // Rows rendered so far, 0 for all; L raises it by limits.maxRows
+}
+
+func newResultPages(result *engine.QueryResult) *resultPages {
+	return &resultPages{
+		result:     result,
+		cells:      make(map[[2]int]*tview.TableCell),
+		sortColumn: -1,
+		widths:     make(map[int]int),
+		limits:     currentLimits,
+		limit:      currentLimits.maxRows,
+	}
+}
+
+// rows returns the result rows the table renders: all of them, or the
+// first limit
+func (p *resultPages) rows() [][]interface{} {
+	if p.truncated() {
+		return p.result.Rows[:p.limit]
+	}
+	return p.result.Rows
+}
+
+// truncated reports whether rows beyond the limit are held back
+func (p *resultPages) truncated() bool {
+	return p.limit > 0 && len(p.result.Rows) > p.limit
+}
+
+// loadMore renders another limits.maxRows rows, keeping the sort, filter
+// and page. It reports false when nothing was held back.
+func (p *resultPages) loadMore() bool {
+	if !p.truncated() {
+		return false
+	}
+	p.limit += p.limits.maxRows
+	page := p.page
+	p.arrange()
+	p.page = min(page, p.pageCount()-1)
+	return true
+}
+
+// columnWidth is how many screen cells a column's values may take
+func (p *resultPages) columnWidth(column int) int {
+	if width, ok := p.widths[column]; ok {
+		return width
+	}
+	return p.limits.columnWidth
+}
+
+// resizeColumn widens a column by delta cells, or narrows it when delta is
+// negative
+func (p *resultPages) resizeColumn(column, delta int) {
+	p.widths[column] = max(p.columnWidth(column)+delta, minColumnWidth)
+	clear(p.cells)
+}
+
+// fitColumn sizes a column to its header and the widest value on the
+// current page
+func (p *resultPages) fitColumn(column int) {
+	clear(p.cells)
+	p.widths[column] = math.MaxInt32 // Measure values in full
+	width := minColumnWidth
+	for row := 0; row < p.GetRowCount(); row++ {
+		if cell := p.GetCell(row, column); cell != nil {
+			width = max(width, tview.TaggedStringWidth(cell.Text))
+		}
+	}
+	p.widths[column] = width
+	clear(p.cells)
+}
+
+// sortBy orders the rows by column, ascending first and toggling the
+// direction when the same column is chosen again. It returns to page one.
+func (p *resultPages) sortBy(column int) {
+	if column < 0 || column >= len(p.result.Columns) {
+		return
+	}
+	if column == p.sortColumn {
+		p.desc = !p.desc
+	} else {
+		p.sortColumn, p.desc = column, false
+	}
+	p.arrange()
+}
+
+// setFilter keeps only the rows matching expr; an empty expr shows all rows.
+// The current sort order is kept.
+func (p *resultPages) setFilter(expr string) error {
+	filter, err := parseRowFilter(expr, p.result.Columns, p.result.Types)
+	if err != nil {
+		return err
+	}
+	p.filter, p.filterText = filter, strings.TrimSpace(expr)
+	p.arrange()
+	return nil
+}
+
+// arrange recomputes the display order from the filter and sort column and
+// returns to page one
+func (p *resultPages) arrange() {
+	p.order = nil
+	rows := p.rows()
+	if p.filter != nil {
+		p.order = []int{}
+		for i, row := range rows {
+			if p.filter(row) {
+				p.order = append(p.order, i)
+			}
+		}
+	}
+	if p.sortColumn >= 0 {
+		if p.order == nil {
+			p.order = make([]int, len(rows))
+			for i := range p.order {
+				p.order[i] = i
+			}
+		}
+		var typeName string
+		if p.sortColumn < len(p.result.Types) {
+			typeName = p.result.Types[p.sortColumn]
+		}
+		sortRowIndexes(p.order, rows, p.sortColumn, typeName, p.desc)
+	}
+	p.page = 0
+	clear(p.cells)
+}
+
+// replace swaps in a new run of the same query, keeping the sort order,
+// filter and page. With highlight set, cells whose value differs from the
+// previous run are marked.
+func (p *resultPages) replace(result *engine.QueryResult, highlight bool) {
+	p.changed = nil
+	if highlight {
+		p.changed = changedCells(p.result, result)
+	}
+	page := p.page
+	p.result = result
+	if p.sortColumn >= len(result.Columns) {
+		p.sortColumn, p.desc = -1, false
+	}
+	if p.filterText != "" {
+		filter, err := parseRowFilter(p.filterText, result.Columns, result.Types)
+		if err != nil {
+			filter, p.filterText = nil, ""
+		}
+		p.filter = filter
+	}
+	p.arrange()
+	p.page = min(page, p.pageCount()-1)
+}
+
+// changedCells finds the cells of next that differ from the same row and
+// column of prev. Rows beyond the end of prev are new and count as changed.
+func changedCells(prev, next *engine.QueryResult) map[[2]int]bool {
+	changed := make(map[[2]int]bool)
+	for i, row := range next.Rows {
+		for column, value := range row {
+			if i >= len(prev.Rows) || column >= len(prev.Rows[i]) || !reflect.DeepEqual(prev.Rows[i][column], value) {
+				changed[[2]int{i, column}] = true
+			}
+		}
+	}
+	return changed
+}
+
+// sourceRow returns the index into the result rows of a table row
+func (p *resultPages) sourceRow(row int) (int, bool) {
+	start, end := p.bounds()
+	index := start + row - 1
+	if row < 1 || index >= end {
+		return 0, false
+	}
+	if p.order != nil {
+		index = p.order[index]
+	}
+	return index, true
+}
+
+// value returns the result value shown at a table position, with the column
+// name and type, and whether the position holds a data cell
+func (p *resultPages) value(row, column int) (value interface{}, name, typeName string, ok bool) {
+	index, ok := p.sourceRow(row)
+	if !ok || column < 0 || column >= len(p.result.Columns) {
+		return nil, "", "", false
+	}
+	if values := p.result.Rows[index]; column < len(values) {
+		value = values[column]
+	}
+	if column < len(p.result.Types) {
+		typeName = p.result.Types[column]
+	}
+	return value, p.result.Columns[column], typeName, true
+}
+
+// displayed returns the result as currently shown: filtered, in display
+// order and without rows held back, across all pages. It is the original
+// result when none of these applies.
+func (p *resultPages) displayed() *engine.QueryResult {
+	if p.order == nil {
+		if p.truncated() {
+			return &engine.QueryResult{Columns: p.result.Columns, Types: p.result.Types, Rows: p.rows()}
+		}
+		return p.result
+	}
+	rows := make([][]interface{}, len(p.order))
+	for i, index := range p.order {
+		rows[i] = p.result.Rows[index]
+	}
+	return &engine.QueryResult{Columns: p.result.Columns, Types: p.result.Types, Rows: rows}
+}
+
+// row returns the i-th displayed row, counting across pages
+func (p *resultPages) row(i int) []interface{} {
+	if p.order != nil {
+		return p.result.Rows[p.order[i]]
+	}
+	return p.result.Rows[i]
+}
+
+// rowCount returns the number of rows left after filtering
+func (p *resultPages) rowCount() int {
+	if p.order != nil {
+		return len(p.order)
+	}
+	return len(p.rows())
+}
+
+// pageCount returns the number of pages, at least one
+func (p *resultPages) pageCount() int {
+	return max(1, (p.rowCount()+p.limits.pageSize-1)/p.limits.pageSize)
+}
+
+// setPage switches to page n and reports whether it changed
+func (p *resultPages) setPage(n int) bool {
+	if n < 0 || n >= p.pageCount() || n == p.page {
+		return false
+	}
+	p.page = n
+	clear(p.cells)
+	return true
+}
+
+// bounds returns the range of result rows on the current page
+func (p *resultPages) bounds() (start, end int) {
+	start = p.page * p.limits.pageSize
+	end = min(start+p.limits.pageSize, p.rowCount())
+	return start, end
+}
+
+// title describes the current page for the table border
+func (p *resultPages) title() string {
+	total := p.rowCount()
+	var filtered string
+	if p.filter != nil {
+		filtered = fmt.Sprintf(" of %d matching %q", len(p.rows()), p.filterText)
+	}
+	var held string
+	if p.truncated() {
+		held = fmt.Sprintf(" [first %d of %d rendered, L loads more]", p.limit, len(p.result.Rows))
+	}
+	var sorted string
+	if p.sortColumn >= 0 {
+		direction := "asc"
+		if p.desc {
+			direction = "desc"
+		}
+		sorted = fmt.Sprintf(", sorted by %s %s", p.result.Columns[p.sortColumn], direction)
+	}
+	if total <= p.limits.pageSize {
+		return tview.Escape(fmt.Sprintf(" Query Results: %d rows%s%s%s ", total, filtered, sorted, held))
+	}
+	start, end := p.bounds()
+	return tview.Escape(fmt.Sprintf(" Query Results: rows %d-%d of %d%s%s (page %d/%d, n/p to change page)%s ",
+		start+1, end, total, filtered, sorted, p.page+1, p.pageCount(), held))
+}
+
+func (p *resultPages) GetRowCount() int {
+	start, end := p.bounds()
+	return end - start + 1 // +1 for the header row
+}
+
+func (p *resultPages) GetColumnCount() int {
+	return len(p.result.Columns)
+}
+
+func (p *resultPages) GetCell(row, column int) *tview.TableCell {
+	if column < 0 || column >= len(p.result.Columns) {
+		return nil
+	}
+	key := [2]int{row, column}
+	if cell, ok := p.cells[key]; ok {
+		return cell
+	}
+
+	var cell *tview.TableCell
+	if row == 0 {
+		header := tview.Escape(p.result.Columns[column])
+		if column == p.sortColumn {
+			if p.desc {
+				header += " ▼"
+			} else {
+				header += " ▲"
+			}
+		}
+		align := tview.AlignLeft
+		if column < len(p.result.Types) && currentFormat.alignNumber && isNumericType(p.result.Types[column]) {
+			align = tview.AlignRight
+		}
+		cell = tview.NewTableCell(header).
+			SetMaxWidth(p.columnWidth(column)).
+			SetTextColor(currentTheme.headerColor()).
+			SetAlign(align).
+			SetExpansion(1).
+			SetSelectable(false)
+	} else {
+		value, _, typeName, ok := p.value(row, column)
+		if !ok {
+			return nil
+		}
+		align := tview.AlignLeft
+		if currentFormat.rightAligned(value, typeName) {
+			align = tview.AlignRight
+		}
+		cell = tview.NewTableCell(tview.Escape(currentFormat.text(value, typeName))).
+			SetMaxWidth(p.columnWidth(column)).
+			SetAlign(align).
+			SetExpansion(1)
+		if index, _ := p.sourceRow(row); p.changed[[2]int{index, column}] {
+			cell.SetTextColor(currentTheme.changedColor()).SetAttributes(tcell.AttrBold)
+		} else if value == nil {
+			cell.SetTextColor(currentTheme.nullColor()).SetAttributes(tcell.AttrDim | tcell.AttrItalic)
+		}
+	}
+	p.cells[key] = cell
+	return cell
+}
+
+// resultView is the result table together with its filter prompt and the
+// vertical, one-record-at-a-time display
+type resultView struct {
+	*tview.Flex
+	result      *engine.QueryResult
+	pages       *resultPages  // nil for empty results
+	setVertical func(on bool) // nil for empty results
+	reload      func(result *engine.QueryResult, highlight bool)
+}
+
+// replace shows a new run of the same query in place, keeping the sort,
+// filter, page and selection. It reports false when the view can't take
+// the result, because it was empty or the columns changed.
+func (v *resultView) replace(result *engine.QueryResult, highlight bool) bool {
+	if v.pages == nil || !slices.Equal(v.result.Columns, result.Columns) {
+		return false
+	}
+	v.reload(result, highlight)
+	v.result = result
+	return true
+}
+
+// showVertical switches to the vertical display, if there are rows to show
+func (v *resultView) showVertical() {
+	if v.setVertical != nil {
+		v.setVertical(true)
+	}
+}
+
+// displayed returns the rows as the user currently sees them, after any
+// filtering and sorting
+func (v *resultView) displayed() *engine.QueryResult {
+	if v.pages == nil {
+		return v.result
+	}
+	return v.pages.displayed()
+}
+
+// createResultTable renders query results as a scrollable, paginated table
+// with a Ctrl+F prompt for filtering the rows. Pressing Enter on a cell
+// calls inspect with its full value; notify reports clipboard copies.
+func createResultTable(result *engine.QueryResult, app *tview.Application, input *tview.InputField, inspect func(column, typeName string, value interface{}), notify func(status string)) *resultView {
+	if len(result.Rows) == 0 {
+		// Return a table with just the header and a "No results" message
+		table := tview.NewTable().SetBorders(true)
+
+		// Add column headers
+		for colIndex, colName := range result.Columns {
+			table.SetCell(0, colIndex,
+				tview.NewTableCell(colName).
+					SetTextColor(currentTheme.headerColor()).
+					SetAlign(tview.AlignLeft).
+					SetExpansion(1))
+		}
+
+		// Add "No results" message
+		if len(result.Columns) > 0 {
+			table.SetCell(1, 0,
+				tview.NewTableCell("[yellow]No results found.").
+					SetAlign(tview.AlignLeft).
+					SetSelectable(false))
+		}
+
+		table.SetBorder(true).
+			SetTitle(" Query Results: 0 rows ").
+			SetTitleAlign(tview.AlignLeft)
+		return &resultView{Flex: tview.NewFlex().AddItem(table, 0, 1, true), result: result}
+	}
+
+	pages := newResultPages(result)
+	table := tview.NewTable().
+		SetBorders(true).
+		SetContent(pages)
+
+	// Set table properties
+	table.SetFixed(1, 0) // Fix header row
+	table.SetSeparator(tview.Borders.Vertical)
+	table.SetBorder(true).
+		SetTitle(pages.title()).
+		SetTitleAlign(tview.AlignLeft).
+		SetBorderPadding(0, 0, 1, 1)
+
+	// Make the table scrollable and selectable; cells rather than rows are
+	// selected so the column under the cursor can be sorted with 's'
+	table.SetSelectable(true, true)
+	table.SetSelectedStyle(currentTheme.selectedStyle())
+
+	showPage := func(n int) {
+		if !pages.setPage(n) {
+			return
+		}
+		_, column := table.GetSelection()
+		table.SetTitle(pages.title())
+		table.Select(1, column).ScrollToBeginning()
+	}
+
+	table.SetSelectedFunc(func(row, column int) {
+		if value, name, typeName, ok := pages.value(row, column); ok {
+			inspect(name, typeName, value)
+		}
+	})
+
+	// copyText puts text on the clipboard and reports what was copied
+	copyText := func(what string, text string, err error) {
+		if err == nil {
+			var how string
+			if how, err = CopyToClipboard(text); err == nil {
+				notify(fmt.Sprintf("[green]Copied %s %s", what, how))
+				return
+			}
+		}
+		notify(fmt.Sprintf("[red]Copy failed: %v", err))
+	}
+	// copyRow copies the i-th displayed row as CSV or as a JSON object
+	copyRow := func(i int, asJSON bool) {
+		if i < 0 || i >= pages.rowCount() {
+			return
+		}
+		if asJSON {
+			text, err := rowJSON(result.Columns, pages.row(i))
+			copyText(fmt.Sprintf("row %d as JSON", i+1), text, err)
+			return
+		}
+		text, err := rowCSV(pages.row(i))
+		copyText(fmt.Sprintf("row %d as CSV", i+1), text, err)
+	}
+	// selectedRow returns the displayed row index under the table cursor
+	selectedRow := func() int {
+		row, _ := table.GetSelection()
+		start, _ := pages.bounds()
+		return start + row - 1
+	}
+
+	// Vertical mode shows one row at a time as a list of column: value
+	// lines, which reads far better than the grid for wide tables
+	record := tview.NewTextView().
+		SetDynamicColors(true).
+		SetScrollable(true).
+		SetWrap(true)
+	record.SetBorder(true).
+		SetTitleAlign(tview.AlignLeft).
+		SetBorderPadding(0, 0, 1, 1)
+	current := 0 // Displayed row shown in vertical mode
+	showRecord := func(i int) {
+		if i < 0 || i >= pages.rowCount() {
+			return
+		}
+		current = i
+		record.SetTitle(fmt.Sprintf(" Row %d of %d (n/p next/previous row, v for the table) ", i+1, pages.rowCount()))
+		record.SetText(pages.record(i)).ScrollToBeginning()
+	}
+
+	body := tview.NewPages().
+		AddPage("table", table, true, true).
+		AddPage("record", record, true, false)
+	vertical := false
+	setVertical := func(on bool) {
+		if on == vertical || pages.rowCount() == 0 {
+			return
+		}
+		vertical = on
+		row, column := table.GetSelection()
+		if on {
+			start, _ := pages.bounds()
+			showRecord(start + max(row, 1) - 1)
+			body.SwitchToPage("record")
+			app.SetFocus(record)
+			return
+		}
+		// Back in the grid, land on the row that was being viewed
+		pages.setPage(current / pages.limits.pageSize)
+		table.SetTitle(pages.title())
+		table.Select(current%pages.limits.pageSize+1, column)
+		body.SwitchToPage("table")
+		app.SetFocus(table)
+	}
+
+	record.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyEscape:
+			app.SetFocus(input)
+			return nil
+		case tcell.KeyRight:
+			showRecord(current + 1)
+			return nil
+		case tcell.KeyLeft:
+			showRecord(current - 1)
+			return nil
+		case tcell.KeyRune:
+			switch event.Rune() {
+			case 'n':
+				showRecord(current + 1)
+				return nil
+			case 'p':
+				showRecord(current - 1)
+				return nil
+			case 'v':
+				setVertical(false)
+				return nil
+			case 'r', 'J':
+				copyRow(current, event.Rune() == 'J')
+				return nil
+			}
+		}
+		return event
+	})
+
+	// The chart plots a numeric column of the displayed rows, labelled by
+	// another column, as bars or a line
+	chartColumns := chartableColumns(result)
+	chartX, chartY, chartKind := -1, -1, chartBar
+	var chart *chartView
+	chart = newChartView(func(width, height int) string {
+		shown := pages.displayed()
+		data := chartSeries(shown, chartX, chartY)
+		title := fmt.Sprintf(" %s by %s: %d rows", tview.Escape(shown.Columns[chartY]), tview.Escape(shown.Columns[chartX]), len(data.values))
+		if data.skipped > 0 {
+			title += fmt.Sprintf(", %d without a number skipped", data.skipped)
+		}
+		if chartKind == chartBar && len(data.values) > maxChartBars {
+			title += fmt.Sprintf(", first %d shown", maxChartBars)
+		}
+		chart.SetTitle(title + " (x/y change columns, t bar/line, Esc for the table) ")
+		if chartKind == chartLine {
+			return lineChart(data, width, height)
+		}
+		return barChart(data, width)
+	})
+	body.AddPage("chart", chart, true, false)
+	showChart := func() {
+		if len(chartColumns) == 0 {
+			notify("[yellow]No numeric columns to chart")
+			return
+		}
+		// Plot the selected column when it holds numbers
+		_, column := table.GetSelection()
+		if slices.Contains(chartColumns, column) {
+			chartY = column
+		} else if chartY < 0 {
+			chartY = chartColumns[0]
+		}
+		// Label the rows with the first other column until one is picked
+		if chartX < 0 {
+			chartX = 0
+			if chartX == chartY && len(result.Columns) > 1 {
+				chartX = 1
+			}
+		}
+		chart.refresh()
+		body.SwitchToPage("chart")
+		app.SetFocus(chart)
+	}
+	// cycle steps through choices from current, wrapping around
+	cycle := func(choices []int, current, step int) int {
+		i := slices.Index(choices, current)
+		return choices[(i+step+len(choices))%len(choices)]
+	}
+	allColumns := make([]int, len(result.Columns))
+	for i := range allColumns {
+		allColumns[i] = i
+	}
+	chart.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch {
+		case event.Key() == tcell.KeyEscape, isRune(event, 'C'):
+			body.SwitchToPage("table")
+			app.SetFocus(table)
+			return nil
+		case isRune(event, 'x'):
+			chartX = cycle(allColumns, chartX, 1)
+		case isRune(event, 'X'):
+			chartX = cycle(allColumns, chartX, -1)
+		case isRune(event, 'y'):
+			chartY = cycle(chartColumns, chartY, 1)
+		case isRune(event, 'Y'):
+			chartY = cycle(chartColumns, chartY, -1)
+		case isRune(event, 't'):
+			if chartKind == chartBar {
+				chartKind = chartLine
+			} else {
+				chartKind = chartBar
+			}
+		default:
+			return event
+		}
+		chart.refresh()
+		return nil
+	})
+
+	view := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(body, 0, 1, true)
+
+	// The filter prompt sits under the table while it is open. Enter applies
+	// the expression (an empty one clears the filter), Escape keeps the old one.
+	filterField := tview.NewInputField().
+		SetLabel("Filter (text or column > value): ").
+		SetFieldWidth(0)
+	closeFilter := func() {
+		view.RemoveItem(filterField)
+		app.SetFocus(table)
+	}
+	filterField.SetDoneFunc(func(key tcell.Key) {
+		if key == tcell.KeyEnter {
+			if err := pages.setFilter(filterField.GetText()); err != nil {
+				filterField.SetLabel(fmt.Sprintf("[red]%s:[-] ", tview.Escape(err.Error())))
+				return
+			}
+			_, column := table.GetSelection()
+			table.SetTitle(pages.title())
+			table.Select(1, column).ScrollToBeginning()
+		}
+		closeFilter()
+	})
+
+	// Add key handler for the table
+	table.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyEscape:
+			// Return focus to the input field when Escape is pressed
+			app.SetFocus(input)
+			return nil
+		case tcell.KeyCtrlF:
+			filterField.SetLabel("Filter (text or column > value): ").SetText(pages.filterText)
+			view.RemoveItem(filterField).AddItem(filterField, 1, 0, true)
+			app.SetFocus(filterField)
+			return nil
+		case tcell.KeyRune:
+			switch event.Rune() {
+			case 'n':
+				showPage(pages.page + 1)
+				return nil
+			case 'p':
+				showPage(pages.page - 1)
+				return nil
+			case 's':
+				_, column := table.GetSelection()
+				pages.sortBy(column)
+				table.SetTitle(pages.title())
+				table.Select(1, column).ScrollToBeginning()
+				return nil
+			case 'v':
+				setVertical(true)
+				return nil
+			case 'c':
+				row, column := table.GetSelection()
+				if value, name, typeName, ok := pages.value(row, column); ok {
+					copyText(name+" value", formatCellValue(value, typeName), nil)
+				}
+				return nil
+			case 'r', 'J':
+				copyRow(selectedRow(), event.Rune() == 'J')
+				return nil
+			case 'C':
+				showChart()
+				return nil
+			case 'L':
+				if !pages.loadMore() {
+					notify("[yellow]All rows are shown")
+					return nil
+				}
+				table.SetTitle(pages.title())
+				notify(fmt.Sprintf("[green]Showing %d of %d rows", len(pages.rows()), len(pages.result.Rows)))
+				return nil
+			case '+', '>', '-', '<', '=':
+				_, column := table.GetSelection()
+				switch event.Rune() {
+				case '+', '>':
+					pages.resizeColumn(column, columnWidthStep)
+				case '-', '<':
+					pages.resizeColumn(column, -columnWidthStep)
+				default:
+					pages.fitColumn(column)
+				}
+				notify(fmt.Sprintf("[green]Column %s is %d characters wide", tview.Escape(result.Columns[column]), pages.columnWidth(column)))
+				return nil
+			case '0', '^':
+				row, _ := table.GetSelection()
+				table.Select(row, 0)
+				return nil
+			case '$':
+				row, _ := table.GetSelection()
+				table.Select(row, len(result.Columns)-1)
+				return nil
+			case 'A':
+				shown := pages.displayed()
+				text, err := engine.ExportCSV(shown)
+				copyText(fmt.Sprintf("%d rows as CSV", len(shown.Rows)), text, err)
+				return nil
+			}
+		}
+		return event
+	})
+
+	reload := func(next *engine.QueryResult, highlight bool) {
+		row, column := table.GetSelection()
+		pages.replace(next, highlight)
+		table.SetTitle(pages.title())
+		start, end := pages.bounds()
+		table.Select(max(1, min(row, end-start)), column)
+		if vertical {
+			showRecord(min(current, pages.rowCount()-1))
+		}
+		chart.refresh()
+	}
+
+	return &resultView{Flex: view, result: result, pages: pages, setVertical: setVertical, reload: reload}
+}
@@ -0,0 +1,138 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/TFMV/trino-cli/config"
+	"github.com/TFMV/trino-cli/engine"
+)
+
+func TestResultPages(t *testing.T) {
+	result := &engine.QueryResult{Columns: []string{"n"}}
+	for i := 0; i < resultPageSize+10; i++ {
+		result.Rows = append(result.Rows, []interface{}{i})
+	}
+	pages := newResultPages(result)
+
+	if got := pages.pageCount(); got != 2 {
+		t.Fatalf("pageCount = %d, want 2", got)
+	}
+	if got := pages.GetRowCount(); got != resultPageSize+1 {
+		t.Errorf("first page rows = %d, want %d", got, resultPageSize+1)
+	}
+	if got := pages.GetCell(1, 0).Text; got != "0" {
+		t.Errorf("first row = %q, want 0", got)
+	}
+	if len(pages.cells) != 1 {
+		t.Errorf("materialized %d cells, want 1", len(pages.cells))
+	}
+
+	if !pages.setPage(1) {
+		t.Fatal("setPage(1) reported no change")
+	}
+	if got := pages.GetRowCount(); got != 11 {
+		t.Errorf("last page rows = %d, want 11", got)
+	}
+	if got := pages.GetCell(1, 0).Text; got != "500" {
+		t.Errorf("first row of page 2 = %q, want 500", got)
+	}
+	if got := pages.GetCell(0, 0).Text; got != "n" {
+		t.Errorf("header = %q, want n", got)
+	}
+	if pages.GetCell(12, 0) != nil {
+		t.Error("expected no cell past the end of the last page")
+	}
+	if pages.setPage(2) {
+		t.Error("setPage past the last page reported a change")
+	}
+}
+
+func TestResultPagesColumnWidths(t *testing.T) {
+	long := strings.Repeat("x", 60)
+	pages := newResultPages(&engine.QueryResult{
+		Columns: []string{"id", "payload"},
+		Rows:    [][]interface{}{{1, long}, {2, "short"}},
+	})
+	if got := pages.GetCell(1, 1).MaxWidth; got != defaultColumnWidth {
+		t.Errorf("default width = %d, want %d", got, defaultColumnWidth)
+	}
+
+	pages.resizeColumn(1, -columnWidthStep)
+	if got := pages.GetCell(1, 1).MaxWidth; got != defaultColumnWidth-columnWidthStep {
+		t.Errorf("narrowed width = %d, want %d", got, defaultColumnWidth-columnWidthStep)
+	}
+	pages.resizeColumn(1, -1000)
+	if got := pages.columnWidth(1); got != minColumnWidth {
+		t.Errorf("width after narrowing past the minimum = %d, want %d", got, minColumnWidth)
+	}
+
+	pages.fitColumn(1)
+	if got := pages.GetCell(1, 1).MaxWidth; got != 60 {
+		t.Errorf("fitted width = %d, want 60", got)
+	}
+	pages.fitColumn(0)
+	if got := pages.columnWidth(0); got != minColumnWidth {
+		t.Errorf("fitted narrow column = %d, want %d", got, minColumnWidth)
+	}
+}
+
+func TestNewResultLimits(t *testing.T) {
+	tests := []struct {
+		settings config.UI
+		want     resultLimits
+	}{
+		{config.UI{}, resultLimits{maxRows: defaultMaxRows, pageSize: resultPageSize, columnWidth: defaultColumnWidth}},
+		{config.UI{MaxRows: 200, PageSize: 50, ColumnWidth: 1}, resultLimits{maxRows: 200, pageSize: 50, columnWidth: minColumnWidth}},
+		{config.UI{MaxRows: -1}, resultLimits{maxRows: 0, pageSize: resultPageSize, columnWidth: defaultColumnWidth}},
+	}
+	for _, tt := range tests {
+		if got := newResultLimits(tt.settings); got != tt.want {
+			t.Errorf("newResultLimits(%+v) = %+v, want %+v", tt.settings, got, tt.want)
+		}
+	}
+}
+
+func TestResultPagesLoadMore(t *testing.T) {
+	result := &engine.QueryResult{Columns: []string{"n"}}
+	for i := 0; i < 25; i++ {
+		result.Rows = append(result.Rows, []interface{}{int64(i)})
+	}
+	pages := newResultPages(result)
+	pages.limits = resultLimits{maxRows: 10, pageSize: 4, columnWidth: defaultColumnWidth}
+	pages.limit = 10
+	pages.arrange()
+
+	if !pages.truncated() || pages.rowCount() != 10 || pages.pageCount() != 3 {
+		t.Fatalf("truncated %v, %d rows on %d pages", pages.truncated(), pages.rowCount(), pages.pageCount())
+	}
+	if title := pages.title(); !strings.Contains(title, "first 10 of 25 rendered, L loads more") {
+		t.Errorf("title = %q", title)
+	}
+	if got := len(pages.displayed().Rows); got != 10 {
+		t.Errorf("displayed %d rows, want 10", got)
+	}
+
+	// Sorting covers only the rendered rows, and survives loading more
+	pages.sortBy(0)
+	pages.sortBy(0)
+	if got := pages.GetCell(1, 0).Text; got != "9" {
+		t.Errorf("first row sorted descending = %q, want 9", got)
+	}
+	pages.setPage(1)
+	if !pages.loadMore() || pages.page != 1 {
+		t.Fatalf("loadMore should keep page 1, got %d", pages.page)
+	}
+	pages.setPage(0)
+	if got := pages.GetCell(1, 0).Text; got != "19" || pages.rowCount() != 20 {
+		t.Errorf("after loading more: first row %q of %d", got, pages.rowCount())
+	}
+
+	pages.loadMore()
+	if pages.truncated() || pages.loadMore() {
+		t.Error("every row should be rendered after the last load")
+	}
+	if strings.Contains(pages.title(), "L loads more") {
+		t.Error("the title should drop the indicator once every row is rendered")
+	}
+}
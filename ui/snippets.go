@@ -0,0 +1,238 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/TFMV/trino-cli/snippet"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// showSnippetPicker replaces the screen with the snippet library, filtered
+// by name and description as the user types. Enter inserts the chosen
+// snippet through onSelect; Ctrl+S saves query under the typed name.
+// onClose is always called once the previous root has been restored.
+func showSnippetPicker(ctx context.Context, app *tview.Application, root tview.Primitive, render func(query string) string, query string, onSelect func(s snippet.Snippet), onClose func(status string)) {
+	filter := tview.NewInputField().
+		SetLabel("Snippet: ").
+		SetFieldWidth(0)
+
+	list := tview.NewList().
+		ShowSecondaryText(true).
+		SetHighlightFullLine(true).
+		SetSelectedStyle(currentTheme.selectedStyle())
+
+	var matches []snippet.Snippet
+	refresh := func(term string) {
+		list.Clear()
+		all, err := snippet.List(ctx)
+		if err != nil {
+			matches = nil
+			list.AddItem(fmt.Sprintf("[red]%v", err), "", 0, nil)
+			return
+		}
+		matches = slices.DeleteFunc(all, func(s snippet.Snippet) bool {
+			return !strings.Contains(strings.ToLower(s.Name+" "+s.Description), strings.ToLower(strings.TrimSpace(term)))
+		})
+		if len(matches) == 0 {
+			list.AddItem("[yellow]No matching snippets", "Type a name and press Ctrl+S to save the current query as a snippet", 0, nil)
+			return
+		}
+		for _, s := range matches {
+			main := tview.Escape(s.Name)
+			if s.Description != "" {
+				main += "  [::d]" + tview.Escape(s.Description) + "[::-]"
+			}
+			list.AddItem(main, render(strings.Join(strings.Fields(s.Body), " ")), 0, nil)
+		}
+	}
+
+	closePicker := func(status string) {
+		app.SetRoot(root, true)
+		onClose(status)
+	}
+	choose := func() {
+		index := list.GetCurrentItem()
+		if index < 0 || index >= len(matches) {
+			return
+		}
+		chosen := matches[index]
+		status := fmt.Sprintf("[green]Snippet %s inserted", tview.Escape(chosen.Name))
+		if len(snippet.Placeholders(chosen.Body)) > 0 {
+			status += "; Tab moves to the next placeholder"
+		}
+		closePicker(status)
+		onSelect(chosen)
+	}
+	save := func() {
+		name := strings.TrimSpace(filter.GetText())
+		if strings.TrimSpace(query) == "" {
+			closePicker("[yellow]Type a query in the editor before saving it as a snippet")
+			return
+		}
+		if err := snippet.Add(ctx, name, "", query, true); err != nil {
+			closePicker(fmt.Sprintf("[red]%s", tview.Escape(err.Error())))
+			return
+		}
+		closePicker(fmt.Sprintf("[green]Query saved as snippet %s", tview.Escape(name)))
+	}
+
+	filter.SetChangedFunc(refresh)
+	filter.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyUp:
+			if i := list.GetCurrentItem(); i > 0 {
+				list.SetCurrentItem(i - 1)
+			}
+			return nil
+		case tcell.KeyDown:
+			if i := list.GetCurrentItem(); i < list.GetItemCount()-1 {
+				list.SetCurrentItem(i + 1)
+			}
+			return nil
+		case tcell.KeyEnter:
+			choose()
+			return nil
+		case tcell.KeyCtrlS:
+			save()
+			return nil
+		case tcell.KeyEscape:
+			closePicker("[yellow]Snippet selection cancelled")
+			return nil
+		}
+		return event
+	})
+
+	refresh("")
+
+	layout := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(filter, 1, 0, true).
+		AddItem(list, 0, 1, false)
+	layout.SetBorder(true).
+		SetTitle(" Snippets (Enter to insert, Ctrl+S to save the query under the typed name, Esc to cancel) ").
+		SetTitleAlign(tview.AlignLeft)
+
+	app.SetRoot(layout, true).SetFocus(filter)
+}
+
+// snippetStops walks the placeholders of a snippet inserted into the editor.
+// Each name is one tab stop; when the user moves on, the value typed over it
+// is copied to the other places the name appears.
+type snippetStops struct {
+	stops   []snippet.Stop // every placeholder, in order of position
+	order   []int          // the first stop of each name, in tab order
+	current int            // index into order
+	length  int            // editor text length when the current stop was selected
+}
+
+// insertSnippet expands body at the editor cursor and selects its first
+// placeholder. It returns nil when the snippet has no placeholders.
+func insertSnippet(input *tview.InputField, body string) *snippetStops {
+	text, stops := snippet.Expand(body, nil)
+	before := []rune(input.GetText())
+	input.PasteHandler()(text, func(tview.Primitive) {})
+	if len(stops) == 0 {
+		return nil
+	}
+
+	after := []rune(input.GetText())
+	at := insertionPoint(before, after, len([]rune(text)))
+	s := &snippetStops{}
+	seen := make(map[string]bool)
+	for i, stop := range stops {
+		stop.Start += at
+		stop.End += at
+		s.stops = append(s.stops, stop)
+		if !seen[stop.Name] {
+			seen[stop.Name] = true
+			s.order = append(s.order, i)
+		}
+	}
+	s.selectStop(input, string(after))
+	return s
+}
+
+// insertionPoint finds where n runes were inserted into before to give after
+func insertionPoint(before, after []rune, n int) int {
+	at := 0
+	for at < len(before) && before[at] == after[at] {
+		at++
+	}
+	return min(at, len(after)-n)
+}
+
+// advance copies the value of the current placeholder to its other
+// occurrences and moves step stops forward or back, selecting the
+// placeholder there. It reports false once the user tabs past the last
+// placeholder or the stops no longer match the text.
+func (s *snippetStops) advance(input *tview.InputField, step int) bool {
+	text, ok := s.sync(input.GetText())
+	if !ok {
+		return false
+	}
+	s.current = max(s.current+step, 0)
+	if s.current >= len(s.order) {
+		input.SetText(text)
+		return false
+	}
+	s.selectStop(input, text)
+	return true
+}
+
+// sync accounts for what was typed over the current placeholder and copies
+// its value to the other stops with the same name
+func (s *snippetStops) sync(text string) (string, bool) {
+	runes := []rune(text)
+	cur := s.order[s.current]
+	delta := len(runes) - s.length
+	s.stops[cur].End += delta
+	for i := cur + 1; i < len(s.stops); i++ {
+		s.stops[i].Start += delta
+		s.stops[i].End += delta
+	}
+	for _, stop := range s.stops {
+		if stop.Start < 0 || stop.End < stop.Start || stop.End > len(runes) {
+			return text, false
+		}
+	}
+
+	current := s.stops[cur]
+	value := slices.Clone(runes[current.Start:current.End])
+	out := make([]rune, 0, len(runes))
+	last, shift := 0, 0
+	for i := range s.stops {
+		stop := &s.stops[i]
+		start, end := stop.Start, stop.End
+		stop.Start += shift
+		if i == cur || stop.Name != current.Name {
+			stop.End += shift
+			continue
+		}
+		out = append(append(out, runes[last:start]...), value...)
+		last = end
+		shift += len(value) - (end - start)
+		stop.End = stop.Start + len(value)
+	}
+	out = append(out, runes[last:]...)
+	return string(out), true
+}
+
+// selectStop sets the editor text and selects the current placeholder, so
+// typing replaces it
+func (s *snippetStops) selectStop(input *tview.InputField, text string) {
+	input.SetText(text)
+	s.length = len([]rune(text))
+	stop := s.stops[s.order[s.current]]
+	handle := input.InputHandler()
+	noFocus := func(tview.Primitive) {}
+	for i := stop.End; i < s.length; i++ {
+		handle(tcell.NewEventKey(tcell.KeyLeft, 0, tcell.ModNone), noFocus)
+	}
+	for i := stop.Start; i < stop.End; i++ {
+		handle(tcell.NewEventKey(tcell.KeyLeft, 0, tcell.ModShift), noFocus)
+	}
+}
@@ -0,0 +1,63 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// typeText sends text to a field one key at a time, as the user would
+func typeText(input *tview.InputField, text string) {
+	for _, r := range text {
+		input.InputHandler()(tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone), func(tview.Primitive) {})
+	}
+}
+
+func TestInsertSnippetTabStops(t *testing.T) {
+	input := tview.NewInputField()
+	stops := insertSnippet(input, "SELECT * FROM ${t} WHERE ${t}.id = ${id:1}")
+	if stops == nil {
+		t.Fatal("expected tab stops")
+	}
+	if got := input.GetText(); got != "SELECT * FROM t WHERE t.id = 1" {
+		t.Fatalf("inserted text = %q", got)
+	}
+
+	// Typing replaces the selected placeholder; Tab copies it to the
+	// other occurrences and selects the next one
+	typeText(input, "orders")
+	if !stops.advance(input, 1) {
+		t.Fatal("advance ended before the last placeholder")
+	}
+	typeText(input, "42")
+	if got := input.GetText(); got != "SELECT * FROM orders WHERE orders.id = 42" {
+		t.Errorf("text after filling placeholders = %q", got)
+	}
+
+	if stops.advance(input, 1) {
+		t.Error("advance past the last placeholder should end the snippet")
+	}
+	if got := input.GetText(); got != "SELECT * FROM orders WHERE orders.id = 42" {
+		t.Errorf("final text = %q", got)
+	}
+}
+
+func TestInsertSnippetWithoutPlaceholders(t *testing.T) {
+	input := tview.NewInputField()
+	if insertSnippet(input, "SHOW CATALOGS") != nil {
+		t.Error("expected no tab stops")
+	}
+	if got := input.GetText(); got != "SHOW CATALOGS" {
+		t.Errorf("text = %q", got)
+	}
+}
+
+func TestInsertionPoint(t *testing.T) {
+	if got := insertionPoint([]rune("SELECT  FROM t"), []rune("SELECT a, b FROM t"), 4); got != 7 {
+		t.Errorf("insertionPoint = %d, want 7", got)
+	}
+	if got := insertionPoint([]rune("ab"), []rune("abb"), 1); got != 2 {
+		t.Errorf("insertionPoint at the end = %d, want 2", got)
+	}
+}
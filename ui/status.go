@@ -0,0 +1,118 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/TFMV/trino-cli/autocomplete"
+	"github.com/TFMV/trino-cli/config"
+	"github.com/rivo/tview"
+)
+
+// sessionInfo describes the connection shown at the right of the status
+// bar. The catalog, schema and transaction state follow the USE and
+// transaction statements run in any tab.
+type sessionInfo struct {
+	profile       string
+	host          string
+	port          int
+	catalog       string
+	schema        string
+	inTransaction bool
+}
+
+func newSessionInfo(profile string) *sessionInfo {
+	p := config.AppConfig.Profiles[profile]
+	return &sessionInfo{
+		profile: profile,
+		host:    p.Host,
+		port:    p.Port,
+		catalog: p.Catalog,
+		schema:  p.Schema,
+	}
+}
+
+// observe updates the session from a query that ran successfully
+func (s *sessionInfo) observe(query string) {
+	if catalog, schema, ok := autocomplete.ParseUseStatement(query); ok {
+		if catalog != "" {
+			s.catalog = catalog
+		}
+		s.schema = schema
+		return
+	}
+
+	words := strings.Fields(strings.ToUpper(strings.TrimSuffix(strings.TrimSpace(query), ";")))
+	switch {
+	case len(words) >= 2 && words[0] == "START" && words[1] == "TRANSACTION":
+		s.inTransaction = true
+	case len(words) >= 1 && (words[0] == "COMMIT" || words[0] == "ROLLBACK"):
+		s.inTransaction = false
+	}
+}
+
+// queryStats is how the last query in a tab went, or how long the running
+// one has taken so far
+type queryStats struct {
+	started  time.Time
+	running  bool
+	duration time.Duration
+	rows     int
+	cached   bool // The result was saved to the result cache
+	failed   bool
+}
+
+// formatDuration rounds a query duration for display
+func formatDuration(d time.Duration) string {
+	switch {
+	case d < time.Second:
+		return d.Round(time.Millisecond).String()
+	case d < time.Minute:
+		return d.Round(100 * time.Millisecond).String()
+	}
+	return d.Round(time.Second).String()
+}
+
+// renderSessionInfo formats the right side of the status bar: the profile
+// and server, catalog.schema, transaction state and the active tab's last
+// query. stats is nil before the tab has run anything.
+func renderSessionInfo(s *sessionInfo, stats *queryStats, now time.Time) string {
+	var parts []string
+
+	server := s.profile
+	if s.host != "" {
+		server = fmt.Sprintf("%s@%s:%d", s.profile, s.host, s.port)
+	}
+	parts = append(parts, "[aqua]"+tview.Escape(server)+"[-]")
+
+	location := s.catalog
+	if s.schema != "" {
+		location += "." + s.schema
+	}
+	if location != "" {
+		parts = append(parts, tview.Escape(location))
+	}
+
+	if s.inTransaction {
+		parts = append(parts, "[yellow]in transaction[-]")
+	} else {
+		parts = append(parts, "autocommit")
+	}
+
+	if stats != nil {
+		switch {
+		case stats.running:
+			parts = append(parts, "[yellow]running "+formatDuration(now.Sub(stats.started))+"[-]")
+		case stats.failed:
+			parts = append(parts, "[red]failed after "+formatDuration(stats.duration)+"[-]")
+		default:
+			last := fmt.Sprintf("%s, %d rows", formatDuration(stats.duration), stats.rows)
+			if stats.cached {
+				last += " [green]cached[-]"
+			}
+			parts = append(parts, last)
+		}
+	}
+	return strings.Join(parts, " │ ")
+}
@@ -0,0 +1,62 @@
+package ui
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSessionInfoObserve(t *testing.T) {
+	s := &sessionInfo{profile: "dev", catalog: "hive", schema: "default"}
+
+	s.observe("USE sales")
+	if s.catalog != "hive" || s.schema != "sales" {
+		t.Errorf("after USE sales: %s.%s", s.catalog, s.schema)
+	}
+	s.observe(`use iceberg."Raw Data";`)
+	if s.catalog != "iceberg" || s.schema != "Raw Data" {
+		t.Errorf("after USE iceberg: %s.%s", s.catalog, s.schema)
+	}
+
+	s.observe("start transaction read only")
+	if !s.inTransaction {
+		t.Error("START TRANSACTION should open a transaction")
+	}
+	s.observe("SELECT 1")
+	if !s.inTransaction {
+		t.Error("SELECT should not end the transaction")
+	}
+	s.observe("ROLLBACK;")
+	if s.inTransaction {
+		t.Error("ROLLBACK should end the transaction")
+	}
+}
+
+func TestRenderSessionInfo(t *testing.T) {
+	s := &sessionInfo{profile: "dev", host: "trino.local", port: 8443, catalog: "hive", schema: "web"}
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		stats *queryStats
+		want  string
+	}{
+		{"idle", nil, "[aqua]dev@trino.local:8443[-] │ hive.web │ autocommit"},
+		{"running", &queryStats{started: now.Add(-2500 * time.Millisecond), running: true},
+			"[aqua]dev@trino.local:8443[-] │ hive.web │ autocommit │ [yellow]running 2.5s[-]"},
+		{"done", &queryStats{duration: 42 * time.Millisecond, rows: 7, cached: true},
+			"[aqua]dev@trino.local:8443[-] │ hive.web │ autocommit │ 42ms, 7 rows [green]cached[-]"},
+		{"failed", &queryStats{duration: 90 * time.Second, failed: true},
+			"[aqua]dev@trino.local:8443[-] │ hive.web │ autocommit │ [red]failed after 1m30s[-]"},
+	}
+	for _, tt := range tests {
+		if got := renderSessionInfo(s, tt.stats, now); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+
+	s.host = ""
+	s.inTransaction = true
+	if got, want := renderSessionInfo(s, nil, now), "[aqua]dev[-] │ hive.web │ [yellow]in transaction[-]"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
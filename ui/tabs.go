@@ -0,0 +1,206 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/TFMV/trino-cli/history"
+	"github.com/rivo/tview"
+	"go.uber.org/zap"
+)
+
+// tabTitleWidth caps how much of a tab's last query is shown in the tab bar
+const tabTitleWidth = 24
+
+// historyNavLimit caps how many queries from earlier sessions Up/Down can
+// reach
+const historyNavLimit = 500
+
+// loadRecentQueries returns the profile's most recent queries from the
+// persistent history, oldest first, so Up/Down continues across sessions.
+// A query repeated back to back is kept once.
+func loadRecentQueries(ctx context.Context, profile string) ([]string, error) {
+	entries, err := history.ListQueries(ctx, history.QueryFilter{Profile: profile}, historyNavLimit, 0)
+	if err != nil {
+		return nil, err
+	}
+	queries := make([]string, 0, len(entries))
+	for i := len(entries) - 1; i >= 0; i-- {
+		query := entries[i].Query
+		if n := len(queries); n > 0 && queries[n-1] == query {
+			continue
+		}
+		queries = append(queries, query)
+	}
+	return queries, nil
+}
+
+// historyRankLimit caps how many of the profile's past queries inform the
+// ranking of autocomplete suggestions
+const historyRankLimit = 2000
+
+// loadRankingQueries returns the profile's most recent successful queries,
+// whose tables and columns autocomplete ranks first
+func loadRankingQueries(ctx context.Context, profile string) ([]string, error) {
+	entries, err := history.ListQueries(ctx, history.QueryFilter{Profile: profile, Status: history.StatusSuccess}, historyRankLimit, 0)
+	if err != nil {
+		return nil, err
+	}
+	queries := make([]string, len(entries))
+	for i, entry := range entries {
+		queries[i] = entry.Query
+	}
+	return queries, nil
+}
+
+// suggestFromHistory returns a lookup of the profile's most recent query
+// that the text typed so far continues into, for the editor's ghost text
+func suggestFromHistory(ctx context.Context, profile string, log *zap.Logger) func(text string) string {
+	return func(text string) string {
+		query, err := history.CompleteQuery(ctx, text, history.QueryFilter{Profile: profile})
+		if err != nil {
+			log.Debug("Failed to look up a history suggestion", zap.Error(err))
+		}
+		return query
+	}
+}
+
+// queryTab is one editor with its own result buffer and running query in
+// the interactive shell
+type queryTab struct {
+	number        int
+	input         *tview.InputField
+	editor        *highlightInput
+	results       *tview.Flex     // Welcome text, error or result table
+	errorBar      *tview.TextView // A located query error, between editor and results
+	layout        *tview.Flex     // Editor above results
+	history       []string        // Earlier sessions' and this tab's queries, for Up/Down
+	historyIndex  int
+	lastView      *resultView // The most recent successful result, for Ctrl+E
+	query         string      // The last submitted query, shown in the tab bar
+	status        string      // Status bar text while the tab is active
+	stats         *queryStats // The last or running query; nil before the first
+	cancel        context.CancelFunc
+	stopWatch     context.CancelFunc // Set while watch mode re-runs query
+	watchInterval time.Duration
+	snippet       *snippetStops // Placeholders left to fill in, if any
+	unseen        bool          // A query finished while the tab was in the background
+	closed        bool
+}
+
+func newQueryTab(number int, theme Theme, highlight bool, intro string) *queryTab {
+	input := tview.NewInputField().
+		SetLabel("SQL> ").
+		SetFieldWidth(0)
+	editor := newHighlightInput(input, theme, highlight)
+
+	results := tview.NewFlex()
+	results.AddItem(tview.NewTextView().
+		SetDynamicColors(true).
+		SetScrollable(true).
+		SetWrap(false).
+		SetText(intro), 0, 1, false)
+
+	errorBar := tview.NewTextView().
+		SetDynamicColors(true).
+		SetWrap(true)
+
+	layout := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(editor, 1, 0, true).
+		AddItem(errorBar, 0, 0, false).
+		AddItem(results, 0, 1, false)
+
+	return &queryTab{
+		number:       number,
+		input:        input,
+		editor:       editor,
+		results:      results,
+		errorBar:     errorBar,
+		layout:       layout,
+		historyIndex: -1,
+		status:       "[yellow]Ready",
+	}
+}
+
+// maxErrorBarHeight caps the lines the error bar takes from the results
+const maxErrorBarHeight = 4
+
+// showError shows message in the error bar above the results, which are
+// left in place
+func (t *queryTab) showError(message string) {
+	text := message + " [gray](Esc dismisses)"
+	_, _, width, _ := t.layout.GetInnerRect()
+	height := 1
+	if width > 0 {
+		height = (tview.TaggedStringWidth(text) + width - 1) / width
+	}
+	t.errorBar.SetText(text)
+	t.layout.ResizeItem(t.errorBar, max(1, min(height, maxErrorBarHeight)), 0)
+}
+
+// hideError dismisses the error bar and the error mark in the editor
+func (t *queryTab) hideError() {
+	t.errorBar.Clear()
+	t.layout.ResizeItem(t.errorBar, 0, 0)
+	t.editor.clearMark()
+}
+
+// errorShown reports whether the error bar is visible
+func (t *queryTab) errorShown() bool {
+	return t.errorBar.GetText(false) != ""
+}
+
+// running reports whether the tab has a query in flight
+func (t *queryTab) running() bool {
+	return t.cancel != nil
+}
+
+// watching reports whether the tab re-runs its query on an interval
+func (t *queryTab) watching() bool {
+	return t.stopWatch != nil
+}
+
+// name identifies the tab in the tab bar and in pages
+func (t *queryTab) name() string {
+	return fmt.Sprintf("tab-%d", t.number)
+}
+
+// label describes the tab for the tab bar: its number, the start of its last
+// query, and whether that query is running or finished unseen
+func (t *queryTab) label() string {
+	title := "new"
+	if t.query != "" {
+		title = strings.Join(strings.Fields(t.query), " ")
+		if runes := []rune(title); len(runes) > tabTitleWidth {
+			title = string(runes[:tabTitleWidth-1]) + "…"
+		}
+	}
+	label := fmt.Sprintf("%d: %s", t.number, tview.Escape(title))
+	switch {
+	case t.watching():
+		label += " (watching)"
+	case t.running():
+		label += " (running)"
+	case t.unseen:
+		label += " •"
+	}
+	return label
+}
+
+// renderTabBar formats the tab bar with the active tab highlighted
+func renderTabBar(tabs []*queryTab, active *queryTab) string {
+	var b strings.Builder
+	for _, tab := range tabs {
+		if tab == active {
+			fmt.Fprintf(&b, "[black:yellow] %s [-:-]", tab.label())
+		} else {
+			fmt.Fprintf(&b, " %s ", tab.label())
+		}
+		b.WriteString("│")
+	}
+	b.WriteString(" [gray]Ctrl+T new, Ctrl+N next, Alt+W close")
+	return b.String()
+}
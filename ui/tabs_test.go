@@ -0,0 +1,104 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/TFMV/trino-cli/history"
+)
+
+func TestQueryTabLabel(t *testing.T) {
+	tab := newQueryTab(2, Theme{}, false, "")
+	if got := tab.label(); got != "2: new" {
+		t.Errorf("label = %q, want %q", got, "2: new")
+	}
+
+	tab.query = "SELECT *\n  FROM [orders] WHERE total > 100"
+	_, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	tab.cancel = cancel
+	want := "2: SELECT * FROM [orders[] … (running)"
+	if got := tab.label(); got != want {
+		t.Errorf("label = %q, want %q", got, want)
+	}
+
+	tab.cancel = nil
+	tab.unseen = true
+	if got := tab.label(); !strings.HasSuffix(got, " •") {
+		t.Errorf("label = %q, want the unseen marker", got)
+	}
+}
+
+func TestRenderTabBar(t *testing.T) {
+	first := newQueryTab(1, Theme{}, false, "")
+	second := newQueryTab(2, Theme{}, false, "")
+	got := renderTabBar([]*queryTab{first, second}, second)
+	if !strings.HasPrefix(got, " 1: new │[black:yellow] 2: new [-:-]│") {
+		t.Errorf("tab bar = %q", got)
+	}
+}
+
+func TestLoadRecentQueries(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	if err := history.Initialize(); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	defer history.Close()
+
+	ctx := context.Background()
+	for _, entry := range []struct{ query, profile string }{
+		{"SELECT 1", "dev"},
+		{"SELECT 2", "dev"},
+		{"SELECT 2", "dev"},
+		{"SELECT 3", "prod"},
+		{"SELECT 4", "dev"},
+	} {
+		if _, err := history.AddQuery(ctx, entry.query, time.Millisecond, 1, entry.profile); err != nil {
+			t.Fatalf("AddQuery failed: %v", err)
+		}
+	}
+
+	got, err := loadRecentQueries(ctx, "dev")
+	if err != nil {
+		t.Fatalf("loadRecentQueries failed: %v", err)
+	}
+	want := []string{"SELECT 1", "SELECT 2", "SELECT 4"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("loadRecentQueries = %q, want %q", got, want)
+	}
+}
+
+func TestLoadRankingQueries(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	if err := history.Initialize(); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	defer history.Close()
+
+	ctx := context.Background()
+	for _, entry := range []struct{ query, profile string }{
+		{"SELECT * FROM orders", "dev"},
+		{"SELECT * FROM customers", "prod"},
+		{"SELECT * FROM orders", "dev"},
+	} {
+		if _, err := history.AddQuery(ctx, entry.query, time.Millisecond, 1, entry.profile); err != nil {
+			t.Fatalf("AddQuery failed: %v", err)
+		}
+	}
+	if _, err := history.AddFailedQuery(ctx, "SELECT * FROM ordres", time.Millisecond, "dev", errors.New("table not found")); err != nil {
+		t.Fatalf("AddFailedQuery failed: %v", err)
+	}
+
+	got, err := loadRankingQueries(ctx, "dev")
+	if err != nil {
+		t.Fatalf("loadRankingQueries failed: %v", err)
+	}
+	want := []string{"SELECT * FROM orders", "SELECT * FROM orders"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("loadRankingQueries = %q, want %q", got, want)
+	}
+}
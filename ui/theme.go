@@ -0,0 +1,194 @@
+package ui
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/TFMV/trino-cli/autocomplete"
+	"github.com/TFMV/trino-cli/config"
+	"github.com/TFMV/trino-cli/schema"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// Theme holds the tview color names used by the interactive shell: SQL
+// tokens in the editor, the screen, the result table and the schema tree.
+// Empty entries keep tview's default color. The fields mirror
+// config.ThemeColors so custom colors convert directly.
+type Theme struct {
+	// SQL tokens in the editor
+	Keyword string
+	String  string
+	Number  string
+	Comment string
+
+	// Screen
+	Background    string
+	Text          string
+	Border        string
+	Title         string
+	Label         string
+	Field         string
+	Header        string
+	Selection     string
+	SelectionText string
+	StatusBar     string
+	Changed       string
+	Null          string
+
+	// Schema tree
+	Catalog          string
+	Schema           string
+	Table            string
+	View             string
+	MaterializedView string
+	Column           string
+}
+
+// dark is the default theme, matching tview's own colors
+var dark = Theme{
+	Keyword: "deepskyblue", String: "yellow", Number: "fuchsia", Comment: "gray",
+	Background: "black", Text: "white", Border: "white", Title: "white", Label: "yellow",
+	Field: "blue", Header: "green", Selection: "navy", SelectionText: "white", Changed: "orange",
+	Null: "gray", Catalog: "yellow", Schema: "lightblue", Table: "lightcyan", View: "palegreen",
+	MaterializedView: "plum", Column: "white",
+}
+
+// Themes lists the built-in themes by name
+var Themes = map[string]Theme{
+	"default": dark,
+	"dark":    dark,
+	"light": {
+		Keyword: "navy", String: "maroon", Number: "purple", Comment: "olive",
+		Background: "white", Text: "black", Border: "gray", Title: "navy", Label: "maroon",
+		Field: "#dadada", Header: "darkgreen", Selection: "lightsteelblue", SelectionText: "black",
+		StatusBar: "#e4e4e4", Changed: "#d75f00", Null: "gray", Catalog: "darkgoldenrod", Schema: "navy", Table: "teal",
+		View: "darkgreen", MaterializedView: "purple", Column: "black",
+	},
+	"solarized": {
+		Keyword: "#268bd2", String: "#2aa198", Number: "#d33682", Comment: "#586e75",
+		Background: "#002b36", Text: "#839496", Border: "#586e75", Title: "#93a1a1", Label: "#b58900",
+		Field: "#073642", Header: "#859900", Selection: "#268bd2", SelectionText: "#fdf6e3",
+		StatusBar: "#073642", Changed: "#cb4b16", Null: "#586e75", Catalog: "#b58900", Schema: "#268bd2", Table: "#2aa198",
+		View: "#859900", MaterializedView: "#6c71c4", Column: "#93a1a1",
+	},
+	"monochrome": {
+		Keyword: "white", Comment: "gray",
+		Background: "black", Text: "white", Border: "gray", Title: "white", Label: "white",
+		Field: "#303030", Header: "white", Selection: "white", SelectionText: "black",
+		StatusBar: "#303030", Changed: "white", Null: "gray", Catalog: "white", Schema: "white", Table: "white",
+		View: "white", MaterializedView: "white", Column: "gray",
+	},
+}
+
+// currentTheme is the theme installed by ApplyTheme, for the colors tview
+// has no global style for
+var currentTheme = dark
+
+// configuredTheme resolves the theme named in the config file, applying any
+// custom colors. Unknown names fall back to the default theme.
+func configuredTheme(ui config.UI) Theme {
+	theme, ok := Themes[strings.ToLower(ui.Theme)]
+	if !ok {
+		theme = Themes["default"]
+	}
+	return theme.overlay(Theme(ui.Colors))
+}
+
+// overlay returns t with every color that is set in custom replaced
+func (t Theme) overlay(custom Theme) Theme {
+	dst := reflect.ValueOf(&t).Elem()
+	src := reflect.ValueOf(custom)
+	for i := 0; i < src.NumField(); i++ {
+		if color := src.Field(i).String(); color != "" {
+			dst.Field(i).SetString(color)
+		}
+	}
+	return t
+}
+
+// ApplyTheme resolves the configured theme and installs it as tview's
+// default styles, so widgets created afterwards use its colors
+func ApplyTheme(ui config.UI) Theme {
+	theme := configuredTheme(ui)
+	set := func(style *tcell.Color, name string) {
+		if name != "" {
+			*style = tcell.GetColor(name)
+		}
+	}
+	set(&tview.Styles.PrimitiveBackgroundColor, theme.Background)
+	set(&tview.Styles.PrimaryTextColor, theme.Text)
+	set(&tview.Styles.BorderColor, theme.Border)
+	set(&tview.Styles.GraphicsColor, theme.Border)
+	set(&tview.Styles.TitleColor, theme.Title)
+	set(&tview.Styles.SecondaryTextColor, theme.Label)
+	set(&tview.Styles.ContrastBackgroundColor, theme.Field)
+	currentTheme = theme
+	return theme
+}
+
+// themeColor parses a theme color name, using fallback when it is unset
+func themeColor(name string, fallback tcell.Color) tcell.Color {
+	if name == "" {
+		return fallback
+	}
+	return tcell.GetColor(name)
+}
+
+// headerColor is the text color of result table headers
+func (t Theme) headerColor() tcell.Color {
+	return themeColor(t.Header, tcell.ColorGreen)
+}
+
+// selectedStyle highlights the selected result cell or list item
+func (t Theme) selectedStyle() tcell.Style {
+	return tcell.StyleDefault.
+		Background(themeColor(t.Selection, tcell.ColorNavy)).
+		Foreground(themeColor(t.SelectionText, tcell.ColorWhite))
+}
+
+// changedColor marks cells whose value changed between watch-mode runs
+func (t Theme) changedColor() tcell.Color {
+	return themeColor(t.Changed, tcell.ColorOrange)
+}
+
+// nullColor is the text color of NULL values in results, which are also
+// dimmed
+func (t Theme) nullColor() tcell.Color {
+	return themeColor(t.Null, tcell.ColorGray)
+}
+
+// statusBarColor is the background of the status and tab bars
+func (t Theme) statusBarColor() tcell.Color {
+	return themeColor(t.StatusBar, tview.Styles.PrimitiveBackgroundColor)
+}
+
+// suggestionColors returns the theme's colors for the icons of the
+// autocomplete popup, matching the editor and the schema tree
+func (t Theme) suggestionColors() map[autocomplete.SQLCompletionType]tcell.Color {
+	palette := t.SchemaPalette()
+	return map[autocomplete.SQLCompletionType]tcell.Color{
+		autocomplete.Keyword:         themeColor(t.Keyword, tcell.ColorDeepSkyBlue),
+		autocomplete.Function:        themeColor(t.Number, tcell.ColorFuchsia),
+		autocomplete.CatalogName:     palette.Catalog,
+		autocomplete.SchemaName:      palette.Schema,
+		autocomplete.TableName:       palette.Table,
+		autocomplete.ColumnName:      palette.Column,
+		autocomplete.SessionProperty: themeColor(t.Label, tcell.ColorYellow),
+		autocomplete.SessionValue:    themeColor(t.Label, tcell.ColorYellow),
+	}
+}
+
+// SchemaPalette returns the theme's colors for the schema browser
+func (t Theme) SchemaPalette() schema.Palette {
+	palette := schema.DefaultPalette
+	palette.Root = themeColor(t.Header, palette.Root)
+	palette.Catalog = themeColor(t.Catalog, palette.Catalog)
+	palette.Schema = themeColor(t.Schema, palette.Schema)
+	palette.Table = themeColor(t.Table, palette.Table)
+	palette.View = themeColor(t.View, palette.View)
+	palette.MaterializedView = themeColor(t.MaterializedView, palette.MaterializedView)
+	palette.Column = themeColor(t.Column, palette.Column)
+	palette.Title = themeColor(t.Header, palette.Title)
+	return palette
+}
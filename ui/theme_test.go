@@ -0,0 +1,49 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/TFMV/trino-cli/config"
+	"github.com/TFMV/trino-cli/schema"
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestConfiguredTheme(t *testing.T) {
+	if got := configuredTheme(config.UI{}); got != Themes["dark"] {
+		t.Errorf("empty config = %+v, want the dark theme", got)
+	}
+	if got := configuredTheme(config.UI{Theme: "nope"}); got != Themes["dark"] {
+		t.Errorf("unknown theme = %+v, want the dark theme", got)
+	}
+
+	got := configuredTheme(config.UI{
+		Theme:  "Light",
+		Colors: config.ThemeColors{Keyword: "orange", StatusBar: "#112233", Column: "red"},
+	})
+	want := Themes["light"]
+	want.Keyword = "orange"
+	want.StatusBar = "#112233"
+	want.Column = "red"
+	if got != want {
+		t.Errorf("configuredTheme = %+v, want %+v", got, want)
+	}
+}
+
+func TestThemeStyles(t *testing.T) {
+	var empty Theme
+	if got := empty.headerColor(); got != tcell.ColorGreen {
+		t.Errorf("unset header = %v, want green", got)
+	}
+	if got := empty.SchemaPalette(); got != schema.DefaultPalette {
+		t.Errorf("unset palette = %+v, want the default", got)
+	}
+
+	theme := Themes["solarized"]
+	fg, bg, _ := theme.selectedStyle().Decompose()
+	if fg != tcell.GetColor("#fdf6e3") || bg != tcell.GetColor("#268bd2") {
+		t.Errorf("selected style = %v on %v", fg, bg)
+	}
+	if got := theme.SchemaPalette().Table; got != tcell.GetColor("#2aa198") {
+		t.Errorf("palette table = %v", got)
+	}
+}
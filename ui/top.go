@@ -0,0 +1,289 @@
+package ui
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/TFMV/trino-cli/config"
+	"github.com/TFMV/trino-cli/engine"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// topColumn is a column of the top view, and the key that sorts by it
+type topColumn struct {
+	title string
+	key   rune // Sorts by the column; 0 for columns that don't sort
+	right bool // Right-aligned
+}
+
+var topColumns = []topColumn{
+	{title: "Query ID"},
+	{title: "User", key: 'U'},
+	{title: "State", key: 'S'},
+	{title: "Elapsed", key: 'T', right: true},
+	{title: "CPU", key: 'C', right: true},
+	{title: "Rows", key: 'R', right: true},
+	{title: "Memory", key: 'M', right: true},
+	{title: "Query"},
+}
+
+// topSort orders the top view by the column sorted by key, descending
+// unless ascending is set
+type topSort struct {
+	key       rune
+	ascending bool
+}
+
+// sortActivity orders queries as s says, breaking ties by ID
+func sortActivity(queries []engine.QueryActivity, s topSort, now time.Time) {
+	slices.SortStableFunc(queries, func(a, b engine.QueryActivity) int {
+		var c int
+		switch s.key {
+		case 'U':
+			c = strings.Compare(a.User, b.User)
+		case 'S':
+			c = strings.Compare(a.State, b.State)
+		case 'C':
+			c = cmp.Compare(a.CPU, b.CPU)
+		case 'R':
+			c = cmp.Compare(a.Rows, b.Rows)
+		case 'M':
+			c = cmp.Compare(a.Memory, b.Memory)
+		default:
+			c = cmp.Compare(a.Elapsed(now), b.Elapsed(now))
+		}
+		if !s.ascending {
+			c = -c
+		}
+		if c == 0 {
+			c = strings.Compare(a.ID, b.ID)
+		}
+		return c
+	})
+}
+
+// topCells returns the cells of a query's row, in the order of topColumns
+func topCells(q engine.QueryActivity, now time.Time) []string {
+	memory := "-"
+	if q.Memory >= 0 {
+		memory = formatBytes(q.Memory)
+	}
+	return []string{
+		q.ID,
+		q.User,
+		q.State,
+		formatDuration(q.Elapsed(now)),
+		formatDuration(q.CPU),
+		strconv.FormatInt(q.Rows, 10),
+		memory,
+		oneLine(q.Query),
+	}
+}
+
+// formatBytes renders a byte count with a binary unit
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// RunTop shows every user's queued and running queries on the profile's
+// cluster full screen, refreshed every interval, until q or Escape. The
+// capital letter keys of the columns sort by them, pressing one again
+// reverses the order, and k or Delete kills the selected query after a y
+// confirmation.
+func RunTop(ctx context.Context, profile string, interval time.Duration) error {
+	ApplyTheme(config.AppConfig.UI)
+	app := tview.NewApplication()
+
+	table := tview.NewTable().
+		SetFixed(1, 0).
+		SetSelectable(true, false).
+		SetSelectedStyle(currentTheme.selectedStyle())
+	footer := tview.NewTextView().
+		SetDynamicColors(true)
+	const help = "[gray]T/C/R/M/U/S sort · k kill · r refresh · q quit"
+	footer.SetText(help)
+	layout := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(table, 0, 1, true).
+		AddItem(footer, 1, 0, false)
+	layout.SetBorder(true).
+		SetTitleAlign(tview.AlignLeft)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		<-ctx.Done()
+		app.Stop()
+	}()
+
+	var queries []engine.QueryActivity
+	var pending *engine.QueryActivity // Waiting for y to confirm the kill
+	var refreshed time.Time
+	order := topSort{key: 'T'}
+	loading := true
+
+	render := func() {
+		now := time.Now()
+		selected := ""
+		if row, _ := table.GetSelection(); row >= 1 && row <= len(queries) {
+			selected = queries[row-1].ID
+		}
+		sortActivity(queries, order, now)
+
+		table.Clear()
+		for column, c := range topColumns {
+			title := c.title
+			switch {
+			case c.key == 0 || c.key != order.key:
+			case order.ascending:
+				title += " ▲"
+			default:
+				title += " ▼"
+			}
+			cell := tview.NewTableCell(title).
+				SetTextColor(currentTheme.headerColor()).
+				SetSelectable(false)
+			if c.right {
+				cell.SetAlign(tview.AlignRight)
+			}
+			table.SetCell(0, column, cell)
+		}
+		row := 1
+		for i, q := range queries {
+			for column, text := range topCells(q, now) {
+				cell := tview.NewTableCell(tview.Escape(text))
+				if topColumns[column].right {
+					cell.SetAlign(tview.AlignRight)
+				}
+				if column == len(topColumns)-1 {
+					cell.SetExpansion(1)
+				}
+				table.SetCell(i+1, column, cell)
+			}
+			if q.ID == selected {
+				row = i + 1
+			}
+		}
+
+		title := fmt.Sprintf(" %s · %d queries ", profile, len(queries))
+		if !refreshed.IsZero() {
+			title += "· " + refreshed.Format("15:04:05") + " "
+		}
+		layout.SetTitle(title)
+		if len(queries) == 0 {
+			text := "No queued or running queries"
+			if loading {
+				text = "Loading…"
+			}
+			table.SetCell(1, 0, tview.NewTableCell(text).SetSelectable(false))
+			return
+		}
+		table.Select(min(row, len(queries)), 0)
+	}
+
+	refresh := make(chan struct{}, 1)
+	reload := func() {
+		select {
+		case refresh <- struct{}{}:
+		default:
+		}
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			list, err := engine.ListQueryActivity(ctx, profile)
+			app.QueueUpdateDraw(func() {
+				loading = false
+				if err != nil {
+					footer.SetText(fmt.Sprintf("[red]%s", tview.Escape(err.Error())))
+				} else {
+					queries, refreshed = list, time.Now()
+				}
+				render()
+			})
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			case <-refresh:
+			}
+		}
+	}()
+
+	kill := func(q engine.QueryActivity) {
+		footer.SetText(fmt.Sprintf("[yellow]Killing query %s…", q.ID))
+		go func() {
+			err := engine.KillQuery(ctx, profile, q.ID)
+			app.QueueUpdateDraw(func() {
+				if err != nil {
+					footer.SetText(fmt.Sprintf("[red]%s", tview.Escape(err.Error())))
+					return
+				}
+				footer.SetText(fmt.Sprintf("[green]Killed query %s", q.ID))
+				reload()
+			})
+		}()
+	}
+
+	table.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if q := pending; q != nil {
+			pending = nil
+			if isRune(event, 'y') || isRune(event, 'Y') {
+				kill(*q)
+			} else {
+				footer.SetText(help)
+			}
+			return nil
+		}
+
+		switch {
+		case event.Key() == tcell.KeyEscape, isRune(event, 'q'), event.Key() == tcell.KeyCtrlQ, event.Key() == tcell.KeyCtrlC:
+			app.Stop()
+			return nil
+		case isRune(event, 'r'), event.Key() == tcell.KeyF5:
+			footer.SetText(help)
+			reload()
+			return nil
+		case isRune(event, 'k'), event.Key() == tcell.KeyDelete:
+			row, _ := table.GetSelection()
+			if row < 1 || row > len(queries) {
+				return nil
+			}
+			q := queries[row-1]
+			pending = &q
+			footer.SetText(fmt.Sprintf("[yellow]Kill query %s? (y/n)", q.ID))
+			return nil
+		}
+		for _, c := range topColumns {
+			if c.key != 0 && isRune(event, c.key) {
+				if order.key == c.key {
+					order.ascending = !order.ascending
+				} else {
+					order = topSort{key: c.key, ascending: c.key == 'U' || c.key == 'S'}
+				}
+				render()
+				return nil
+			}
+		}
+		return event
+	})
+
+	render()
+	return app.SetRoot(layout, true).SetFocus(table).Run()
+}
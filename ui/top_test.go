@@ -0,0 +1,61 @@
+package ui
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/TFMV/trino-cli/engine"
+)
+
+func TestSortActivity(t *testing.T) {
+	now := time.Date(2024, 3, 5, 14, 0, 0, 0, time.UTC)
+	queries := []engine.QueryActivity{
+		{ServerQuery: engine.ServerQuery{ID: "a", User: "ana", State: "RUNNING", Created: now.Add(-time.Minute)}, Rows: 10, Memory: 300},
+		{ServerQuery: engine.ServerQuery{ID: "b", User: "bo", State: "QUEUED", Created: now.Add(-time.Hour)}, Rows: 0, Memory: -1},
+		{ServerQuery: engine.ServerQuery{ID: "c", User: "ana", State: "RUNNING", Created: now.Add(-time.Second)}, Rows: 500, Memory: 100},
+	}
+	ids := func() []string {
+		var ids []string
+		for _, q := range queries {
+			ids = append(ids, q.ID)
+		}
+		return ids
+	}
+
+	tests := []struct {
+		sort topSort
+		want []string
+	}{
+		{topSort{key: 'T'}, []string{"b", "a", "c"}},
+		{topSort{key: 'T', ascending: true}, []string{"c", "a", "b"}},
+		{topSort{key: 'R'}, []string{"c", "a", "b"}},
+		{topSort{key: 'M'}, []string{"a", "c", "b"}},
+		{topSort{key: 'U', ascending: true}, []string{"a", "c", "b"}},
+	}
+	for _, tt := range tests {
+		sortActivity(queries, tt.sort, now)
+		if got := ids(); !slices.Equal(got, tt.want) {
+			t.Errorf("sorted by %c (ascending %v) = %q, want %q", tt.sort.key, tt.sort.ascending, got, tt.want)
+		}
+	}
+}
+
+func TestTopCells(t *testing.T) {
+	now := time.Date(2024, 3, 5, 14, 0, 0, 0, time.UTC)
+	q := engine.QueryActivity{
+		ServerQuery: engine.ServerQuery{ID: "q1", User: "ana", State: "RUNNING", Query: "SELECT *\n  FROM orders", Created: now.Add(-90 * time.Second)},
+		Rows:        1200,
+		CPU:         4 * time.Second,
+		Memory:      3 << 20,
+	}
+	want := []string{"q1", "ana", "RUNNING", "1m30s", "4s", "1200", "3.0 MiB", "SELECT * FROM orders"}
+	if got := topCells(q, now); !slices.Equal(got, want) {
+		t.Errorf("topCells = %q, want %q", got, want)
+	}
+
+	q.Memory = -1
+	if got := topCells(q, now)[6]; got != "-" {
+		t.Errorf("unknown memory shown as %q, want -", got)
+	}
+}
@@ -1,20 +1,29 @@
 package ui
 
 import (
+	"context"
 	"fmt"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/TFMV/trino-cli/autocomplete"
+	"github.com/TFMV/trino-cli/autocomplete/tui"
+	"github.com/TFMV/trino-cli/config"
 	"github.com/TFMV/trino-cli/engine"
+	"github.com/TFMV/trino-cli/schema"
+	"github.com/TFMV/trino-cli/snippet"
+	"github.com/TFMV/trino-cli/sqlfmt"
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
 	"go.uber.org/zap"
 )
 
-// StartInteractive launches an interactive TUI-based query shell.
-func StartInteractive(profile string) {
+// StartInteractive launches an interactive TUI-based query shell. Cancelling
+// ctx aborts running queries and background metadata refreshes.
+func StartInteractive(ctx context.Context, profile string) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
 	// Initialize logger
 	logger, _ := zap.NewProduction()
 	defer logger.Sync()
@@ -22,156 +31,833 @@ func StartInteractive(profile string) {
 
 	log.Info("Starting interactive mode")
 
-	// Start schema cache updater in the background
-	if err := autocomplete.StartSchemaCacheUpdater(10*time.Minute, profile, log); err != nil {
-		log.Warn("Failed to start schema cache updater", zap.Error(err))
-		// Continue anyway - autocomplete will still work with initial data
-	} else {
-		log.Info("Schema cache updater started with 10-minute refresh interval")
+	// Background work for the profile, such as the schema cache updater and
+	// autocomplete, runs under profileCtx, which ends when Ctrl+P switches
+	// to another profile
+	profileCtx, cancelProfile := context.WithCancel(ctx)
+	startSchemaUpdater := func() {
+		if err := autocomplete.StartSchemaCacheUpdater(profileCtx, 10*time.Minute, autocompleteProfile(profile), log); err != nil {
+			log.Warn("Failed to start schema cache updater", zap.Error(err))
+			// Continue anyway - autocomplete will still work with initial data
+		} else {
+			log.Info("Schema cache updater started with 10-minute refresh interval")
+		}
 	}
+	startSchemaUpdater()
 
 	app := tview.NewApplication()
-	queryHistory := []string{}
-	historyIndex := -1
-	var historyLock sync.Mutex
-
-	// Input field for SQL queries.
-	input := tview.NewInputField().
-		SetLabel("SQL> ").
-		SetFieldWidth(0)
+	theme := ApplyTheme(config.AppConfig.UI)
+	currentFormat = newValueFormat(config.AppConfig.UI.Format)
+	currentLimits = newResultLimits(config.AppConfig.UI)
+	highlight := !config.AppConfig.UI.NoHighlight
+	keys := newKeymap(config.AppConfig.UI.Keymap)
+	notifyAfter, notifyEnabled, err := notifyThreshold(config.AppConfig.UI.Notify)
+	if err != nil {
+		log.Warn("Using the default notification threshold", zap.Error(err))
+	}
+	defaultInterval, err := watchInterval(config.AppConfig.UI.Watch)
+	if err != nil {
+		log.Warn("Using the default watch interval", zap.Error(err))
+	}
 
-	// Results area - will be replaced with a table when results are available
-	resultsArea := tview.NewFlex()
+	// Set while a dialog (export, history search) has taken over the screen
+	dialogOpen := false
 
-	// Initial welcome message
-	welcomeText := tview.NewTextView().
+	// Up/Down starts from the queries of earlier sessions, and queries run
+	// in any tab are added for tabs opened later
+	recent, err := loadRecentQueries(ctx, profile)
+	if err != nil {
+		log.Warn("Failed to load query history", zap.Error(err))
+	}
+	suggest := suggestFromHistory(ctx, profile, log)
+
+	// Each tab has its own editor, results and running query
+	var tabs []*queryTab
+	var active *queryTab
+	nextTabNumber := 1
+	tabPages := tview.NewPages()
+	tabBar := tview.NewTextView().
 		SetDynamicColors(true).
-		SetScrollable(true).
-		SetWrap(false).
-		SetText("Welcome to Trino CLI. Enter your SQL query and press [green]Enter[white].\nPress [yellow]Ctrl+Space[white] for autocompletion.")
-
-	resultsArea.AddItem(welcomeText, 0, 1, false)
+		SetWrap(false)
+	tabBar.SetBackgroundColor(theme.statusBarColor())
 
 	// Status bar to show execution state.
 	statusBar := tview.NewTextView().
 		SetDynamicColors(true).
 		SetText("[yellow]Ready")
+	statusBar.SetBackgroundColor(theme.statusBarColor())
+
+	// Connection and session details at the right of the status bar
+	session := newSessionInfo(profile)
+	sessionBar := tview.NewTextView().
+		SetDynamicColors(true).
+		SetWrap(false).
+		SetTextAlign(tview.AlignRight)
+	sessionBar.SetBackgroundColor(theme.statusBarColor())
+
+	// The schema browser pane is created on first use and shown left of
+	// the tabs while toggled on with Ctrl+B
+	body := tview.NewFlex().
+		AddItem(tabPages, 0, 1, true)
+	var browser *schema.Browser
+	var browserPane tview.Primitive
+	browserVisible := false
 
 	// Layout.
 	flex := tview.NewFlex().
 		SetDirection(tview.FlexRow).
-		AddItem(input, 1, 0, true).
-		AddItem(resultsArea, 0, 1, false).
-		AddItem(statusBar, 1, 0, false)
+		AddItem(tabBar, 1, 0, false).
+		AddItem(body, 0, 1, true).
+		AddItem(tview.NewFlex().
+			AddItem(statusBar, 0, 1, false).
+			AddItem(sessionBar, 0, 1, false), 1, 0, false)
+
+	refreshTabBar := func() {
+		tabBar.SetText(renderTabBar(tabs, active))
+	}
+	refreshSession := func() {
+		sessionBar.SetText(renderSessionInfo(session, active.stats, time.Now()))
+	}
+	// setStatus records a tab's status, showing it when the tab is active
+	setStatus := func(tab *queryTab, status string) {
+		tab.status = status
+		if tab == active {
+			statusBar.SetText(status)
+		}
+	}
 
 	// Set up autocomplete
-	var autocompleteHandler *autocomplete.AutocompleteHandler
-	autocompleteHandler, err := autocomplete.IntegrateWithTUI(app, input, flex, profile, log)
-	if err != nil {
-		log.Warn("Failed to initialize autocomplete", zap.Error(err))
-		// Continue without autocomplete
-	} else {
-		log.Info("Autocomplete initialized successfully")
-		defer autocompleteHandler.Stop()
+	var autocompleteHandler *tui.Handler
+	var runQuery func(tab *queryTab)
+	switchTab := func(tab *queryTab) {
+		active = tab
+		tab.unseen = false
+		tabPages.SwitchToPage(tab.name())
+		tab.input.SetLabel(keys.label())
+		if autocompleteHandler != nil {
+			autocompleteHandler.Attach(tab.input)
+		}
+		statusBar.SetText(tab.status)
+		refreshTabBar()
+		refreshSession()
+		app.SetFocus(tab.input)
+	}
+	addTab := func(intro string) *queryTab {
+		tab := newQueryTab(nextTabNumber, theme, highlight, intro)
+		nextTabNumber++
+		tab.history = append([]string(nil), recent...)
+		tab.historyIndex = len(tab.history)
+		tab.editor.setSuggester(suggest)
+		tab.input.SetDoneFunc(func(key tcell.Key) {
+			if key == tcell.KeyEnter {
+				runQuery(tab)
+			}
+		})
+		tabs = append(tabs, tab)
+		tabPages.AddPage(tab.name(), tab.layout, true, false)
+		switchTab(tab)
+		return tab
+	}
+	closeTab := func(tab *queryTab) {
+		if len(tabs) == 1 {
+			setStatus(tab, "[yellow]Cannot close the last tab")
+			return
+		}
+		if tab.running() {
+			tab.cancel()
+		}
+		if tab.stopWatch != nil {
+			tab.stopWatch()
+		}
+		tab.closed = true
+		index := 0
+		for i, t := range tabs {
+			if t == tab {
+				index = i
+			}
+		}
+		tabs = append(tabs[:index], tabs[index+1:]...)
+		tabPages.RemovePage(tab.name())
+		switchTab(tabs[min(index, len(tabs)-1)])
+	}
+	// cycleTab activates the tab delta positions away from the active one
+	cycleTab := func(delta int) {
+		for i, t := range tabs {
+			if t == active {
+				switchTab(tabs[(i+delta+len(tabs))%len(tabs)])
+				return
+			}
+		}
 	}
 
-	// Handle query execution.
-	input.SetDoneFunc(func(key tcell.Key) {
-		if key != tcell.KeyEnter {
+	// insertName adds a name chosen in the schema browser to the active
+	// editor, separated from what is already typed
+	insertName := func(name string) {
+		text := active.input.GetText()
+		if text != "" && !strings.HasSuffix(text, " ") && !strings.HasSuffix(text, "(") {
+			text += " "
+		}
+		active.input.SetText(text + name)
+		app.SetFocus(active.input)
+	}
+	// openTemplate puts a query generated in the schema browser in the
+	// active editor, or in a new tab if that one holds a query, with its
+	// placeholders as tab stops
+	openTemplate := func(query string) {
+		if strings.TrimSpace(active.input.GetText()) != "" {
+			addTab("Query generated from the schema browser. Tab moves between its placeholders.")
+		}
+		active.snippet = insertSnippet(active.input, query)
+		app.SetFocus(active.input)
+	}
+	toggleBrowser := func() {
+		if browserVisible {
+			browserVisible = false
+			body.RemoveItem(browserPane)
+			app.SetFocus(active.input)
 			return
 		}
+		if browser == nil {
+			b, err := schema.NewBrowser(profile, log)
+			if err != nil {
+				setStatus(active, fmt.Sprintf("[red]Schema browser unavailable: %v", err))
+				return
+			}
+			browser = b
+			browser.SetPalette(theme.SchemaPalette())
+			browser.SetHighlighter(func(sql string) string { return HighlightSQL(sql, theme) })
+			browser.SetClipboard(CopyToClipboard)
+			browser.SetAutoStats(config.AppConfig.UI.SchemaStats)
+			browser.SetQueryOpener(openTemplate)
+			if ttl, err := schema.ParseCacheTTL(config.AppConfig.UI.SchemaTTL); err != nil {
+				log.Warn("Using the default schema cache TTL", zap.Error(err))
+			} else {
+				browser.SetCacheTTL(ttl)
+			}
+			if err := browser.SetHiddenPatterns(config.AppConfig.UI.SchemaHide); err != nil {
+				log.Warn("Hiding only the system schemas", zap.Error(err))
+			}
+			browser.SetShowHidden(config.AppConfig.UI.SchemaShowHidden)
+			browserPane = browser.Embed(profileCtx, app, insertName)
+		}
+		browserVisible = true
+		body.Clear().
+			AddItem(browserPane, 0, 1, true).
+			AddItem(tabPages, 0, 2, false)
+		app.SetFocus(browser.TreeView())
+	}
+	defer func() {
+		if browser != nil {
+			browser.Close()
+		}
+	}()
 
-		query := input.GetText()
-		if strings.TrimSpace(query) == "" {
+	first := addTab("Welcome to Trino CLI. Enter your SQL query and press [green]Enter[-].\nPress [yellow]Ctrl+Space[-] for autocompletion, [yellow]Ctrl+R[-] to search history and [yellow]Ctrl+E[-] to export results.\nPress [yellow]Ctrl+T[-] to open another query tab, [yellow]Ctrl+B[-] to browse the schema and [yellow]F2[-] to toggle syntax highlighting.\nPress [yellow]F1[-] to list all keyboard shortcuts. End a query with [yellow]\\G[-] to show rows vertically.")
+
+	// startAutocomplete sets up autocomplete for the profile on input
+	startAutocomplete := func(input *tview.InputField) {
+		handler, err := tui.Integrate(profileCtx, app, input, autocompleteProfile(profile), log)
+		if err != nil {
+			log.Warn("Failed to initialize autocomplete", zap.Error(err))
+			// Continue without autocomplete
+			autocompleteHandler = nil
 			return
 		}
+		log.Info("Autocomplete initialized successfully")
+		handler.SetColors(tview.Styles.PrimaryTextColor, themeColor(theme.SelectionText, tcell.ColorBlack), themeColor(theme.Selection, tcell.ColorAqua))
+		handler.SetIconColors(theme.suggestionColors())
+		handler.SetAnchor(func() (int, int, bool) {
+			return active.editor.cursorPosition()
+		})
+		go func(profile string) {
+			queries, err := loadRankingQueries(ctx, profile)
+			if err != nil {
+				log.Debug("Failed to load history for autocomplete ranking", zap.Error(err))
+				return
+			}
+			handler.LearnFromHistory(queries)
+		}(profile)
+		autocompleteHandler = handler
+	}
+	startAutocomplete(first.input)
+	defer func() {
+		if autocompleteHandler != nil {
+			autocompleteHandler.Stop()
+		}
+	}()
 
-		// Add to history.
-		historyLock.Lock()
-		queryHistory = append(queryHistory, query)
-		historyIndex = len(queryHistory)
-		historyLock.Unlock()
+	// stopWatch turns off a tab's watch mode, if it is on
+	stopWatch := func(tab *queryTab) {
+		if tab.stopWatch != nil {
+			tab.stopWatch()
+			tab.stopWatch = nil
+		}
+	}
 
-		log.Info("Executing query", zap.String("query", query))
-		statusBar.SetText("[yellow]Executing query...")
+	// execute runs query in tab and shows the outcome; submitted is the
+	// editor text that started it. A rerun is a watch-mode refresh: it is
+	// left out of the history, updates the result table in place and leaves
+	// the editor and focus alone.
+	execute := func(tab *queryTab, query, submitted string, vertical, rerun bool) {
+		log.Info("Executing query", zap.String("query", query), zap.Int("tab", tab.number), zap.Bool("rerun", rerun))
+		queryCtx, cancelQuery := context.WithCancel(ctx)
+		tab.cancel = cancelQuery
+		if rerun {
+			queryCtx = engine.WithoutHistory(queryCtx)
+		}
+		tab.query = query
+		tab.stats = &queryStats{started: time.Now(), running: true}
+		if !rerun {
+			setStatus(tab, "[yellow]Executing query...")
+		}
+		refreshTabBar()
+		refreshSession()
 
-		go func() {
-			result, err := engine.ExecuteQuery(query, profile)
+		go func(profile string, completion *tui.Handler) {
+			result, err := engine.ExecuteQuery(queryCtx, query, profile)
 			app.QueueUpdateDraw(func() {
+				cancelQuery()
+				tab.cancel = nil
+				if tab.closed {
+					return
+				}
+				if tab != active {
+					tab.unseen = true
+				}
+				tab.stats.running = false
+				tab.stats.duration = time.Since(tab.stats.started)
+				tab.stats.failed = err != nil
+				if err == nil {
+					tab.stats.rows = len(result.Rows)
+					tab.stats.cached = result.CacheKey != ""
+					session.observe(query)
+					// Later queries in every tab run in the catalog and schema
+					// a USE switched to
+					if catalog, schema, ok := autocomplete.ParseUseStatement(query); ok {
+						engine.UseSchema(profile, catalog, schema)
+					}
+					// Re-rank autocomplete by what the query used and the
+					// catalog/schema it switched to
+					if completion != nil && !rerun {
+						completion.ObserveQuery(query)
+					}
+				}
+
+				// Announce long queries that finish while the user is in
+				// another tab, the schema pane or a dialog
+				if !rerun && notifyEnabled && tab.stats.duration >= notifyAfter && (tab != active || !tab.layout.HasFocus()) {
+					message := completionMessage(tab.number, tab.stats)
+					if err := ringBell(); err != nil {
+						log.Warn("Failed to ring the terminal bell", zap.Error(err))
+					}
+					if config.AppConfig.UI.Notify.Desktop {
+						go func() {
+							if err := sendDesktopNotification("trino-cli", message); err != nil {
+								log.Warn("Failed to send desktop notification", zap.Error(err))
+							}
+						}()
+					}
+				}
+				defer refreshSession()
+				defer refreshTabBar()
+
+				var line, column int
+				var message string
+				var located bool
 				if err != nil {
 					log.Error("Query execution failed", zap.Error(err))
+					line, column, message, located = errorLocation(err)
+				}
 
+				// An error Trino can place in the query is marked in the
+				// editor, which keeps the query, and the results stay put
+				keepQuery := located && !rerun && tab.input.GetText() == submitted
+				if keepQuery {
+					start, end := errorSpan(submitted, line, column)
+					tab.editor.markError(start, end)
+					moveCursor(tab.input, start)
+					tab.showError(fmt.Sprintf("[red]Error at line %d, column %d:[-] %s", line, column, tview.Escape(message)))
+					stopWatch(tab)
+					setStatus(tab, "[red]Execution failed")
+					if tab == active {
+						app.SetFocus(tab.input)
+					}
+				} else if err != nil {
 					// Show error message
 					errorText := tview.NewTextView().
 						SetDynamicColors(true).
 						SetScrollable(true).
 						SetWrap(true).
-						SetText(fmt.Sprintf("[red]Error:[white] %v", err))
+						SetText(fmt.Sprintf("[red]Error:[-] %v", err))
 
 					// Clear results area and add error message
-					resultsArea.Clear()
-					resultsArea.AddItem(errorText, 0, 1, false)
-
-					statusBar.SetText("[red]Execution failed")
+					tab.results.Clear()
+					tab.results.AddItem(errorText, 0, 1, false)
+
+					if tab.watching() {
+						stopWatch(tab)
+						setStatus(tab, "[red]Execution failed; watch stopped")
+					} else {
+						setStatus(tab, "[red]Execution failed")
+					}
 				} else {
 					log.Info("Query executed successfully",
 						zap.Int("rows", len(result.Rows)),
 						zap.Int("columns", len(result.Columns)))
 
-					// Create a scrollable table for results
-					resultTable := createResultTable(result, app, input)
+					if rerun && tab.lastView != nil && tab.lastView.replace(result, !config.AppConfig.UI.Watch.NoHighlight) {
+						// Put the table back in case the previous run failed
+						tab.results.Clear()
+						tab.results.AddItem(tab.lastView, 0, 1, false)
+					} else {
+						// Create a scrollable table for results, titled with the
+						// row count and current page
+						var resultTable *resultView
+						resultTable = createResultTable(result, app, tab.input, func(column, typeName string, value interface{}) {
+							dialogOpen = true
+							showCellInspector(app, flex, column, typeName, value, func(status string) {
+								dialogOpen = false
+								if status != "" {
+									setStatus(tab, status)
+								}
+								app.SetFocus(resultTable)
+							})
+						}, func(status string) {
+							setStatus(tab, status)
+						})
+
+						// Clear results area and add the table
+						tab.results.Clear()
+						tab.results.AddItem(resultTable, 0, 1, false)
+						tab.lastView = resultTable
+
+						// Set focus on the table to enable scrolling, sorting and
+						// filtering, unless the user is working in another tab
+						if tab == active && !rerun {
+							app.SetFocus(resultTable)
+						}
+						if vertical {
+							resultTable.showVertical()
+							if tab != active {
+								app.SetFocus(active.input)
+							}
+						}
+					}
+
+					if tab.watching() {
+						setStatus(tab, fmt.Sprintf("[green]%d rows at %s; re-running every %s (F5 stops)",
+							len(result.Rows), time.Now().Format("15:04:05"), tab.watchInterval))
+					} else {
+						status := fmt.Sprintf("[green]Execution complete: %d rows", len(result.Rows))
+						if result.Truncated {
+							status += " (stopped at defaults.max_rows)"
+						}
+						setStatus(tab, status)
+					}
+				}
+				// Keep anything typed into the editor while the query ran
+				if !rerun && !keepQuery && tab.input.GetText() == submitted {
+					tab.input.SetText("")
+				}
+			})
+		}(profile, autocompleteHandler)
+	}
+
+	// startWatch re-runs query in tab every interval until stopWatch. A tick
+	// that comes while the previous run is still going is skipped.
+	startWatch := func(tab *queryTab, query string, interval time.Duration) {
+		stopWatch(tab)
+		watchCtx, cancelWatch := context.WithCancel(ctx)
+		tab.stopWatch = cancelWatch
+		tab.watchInterval = interval
+		go func() {
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-watchCtx.Done():
+					return
+				case <-ticker.C:
+					app.QueueUpdateDraw(func() {
+						if watchCtx.Err() == nil && !tab.running() {
+							execute(tab, query, "", false, true)
+						}
+					})
+				}
+			}
+		}()
+	}
+
+	// Handle query execution. Each tab runs at most one query at a time and
+	// keeps its result when the user switches away.
+	runQuery = func(tab *queryTab) {
+		query := tab.input.GetText()
+		if strings.TrimSpace(query) == "" {
+			return
+		}
+		if tab.running() {
+			setStatus(tab, "[yellow]A query is already running in this tab; press Ctrl+T to open another")
+			return
+		}
+
+		tab.snippet = nil
+		tab.hideError()
 
-					// Set a title showing the number of rows returned
-					resultTable.SetTitle(fmt.Sprintf(" Query Results: %d rows ", len(result.Rows)))
-					resultTable.SetTitleAlign(tview.AlignLeft)
-					resultTable.SetBorderPadding(0, 0, 1, 1)
+		// Add to history.
+		tab.history = append(tab.history, query)
+		tab.historyIndex = len(tab.history)
+		recent = append(recent, query)
+
+		// A trailing \G shows the result one row at a time, and a trailing
+		// \watch re-runs the query on an interval. Any other query ends
+		// watch mode in the tab.
+		submitted := query
+		query, vertical := splitVerticalSuffix(query)
+		query, interval, watch := splitWatchSuffix(query)
+		stopWatch(tab)
+		if watch {
+			if interval <= 0 {
+				interval = defaultInterval
+			}
+			startWatch(tab, query, interval)
+		}
+		execute(tab, query, submitted, vertical, false)
+	}
 
-					// Clear results area and add the table
-					resultsArea.Clear()
-					resultsArea.AddItem(resultTable, 0, 1, false)
+	// switchProfile reconnects the shell with another profile: queries still
+	// running are cancelled, and autocomplete, the schema pane, history
+	// recall and the status bar start over with the new profile's server
+	// and caches. The tabs keep their queries and results.
+	switchProfile := func(name string) {
+		log.Info("Switching profile", zap.String("from", profile), zap.String("to", name))
+		for _, t := range tabs {
+			stopWatch(t)
+			if t.running() {
+				t.cancel()
+			}
+		}
+		if autocompleteHandler != nil {
+			autocompleteHandler.Stop()
+		}
+		if browser != nil {
+			if browserVisible {
+				toggleBrowser()
+			}
+			browser.Close()
+			browser = nil
+		}
+		cancelProfile()
 
-					// Set focus on the table to enable scrolling with arrow keys
-					app.SetFocus(resultTable)
+		profile = name
+		engine.Reconnect(profile)
+		profileCtx, cancelProfile = context.WithCancel(ctx)
+		startSchemaUpdater()
+		startAutocomplete(active.input)
 
-					statusBar.SetText("[green]Execution complete")
-				}
-				input.SetText("")
-			})
-		}()
-	})
+		recent, err = loadRecentQueries(ctx, profile)
+		if err != nil {
+			log.Warn("Failed to load query history", zap.Error(err))
+		}
+		suggest = suggestFromHistory(ctx, profile, log)
+		for _, t := range tabs {
+			t.history = append([]string(nil), recent...)
+			t.historyIndex = len(t.history)
+			t.editor.setSuggester(suggest)
+		}
+
+		session = newSessionInfo(profile)
+		refreshTabBar()
+		refreshSession()
+		setStatus(active, "[green]Connected with profile "+tview.Escape(profile))
+	}
+
+	// Tick the elapsed time of a running query in the status bar
+	go func() {
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				app.QueueUpdateDraw(func() {
+					if active.running() {
+						refreshSession()
+					}
+				})
+			}
+		}
+	}()
 
 	// Keyboard shortcuts.
 	app.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		// Leave all keys to the dialog while it is open
+		if dialogOpen {
+			return event
+		}
+
+		// Escape in the editor dismisses a query error first, whatever the
+		// keymap
+		if event.Key() == tcell.KeyEscape && active.errorShown() && active.input.HasFocus() &&
+			(autocompleteHandler == nil || !autocompleteHandler.SuggestionsVisible()) {
+			active.hideError()
+			return nil
+		}
+
+		// Translate vim or emacs keys into the default bindings below,
+		// except while the suggestion box needs Escape and the arrows
+		if autocompleteHandler == nil || !autocompleteHandler.SuggestionsVisible() {
+			switch focus := app.GetFocus(); {
+			case active.input.HasFocus():
+				event = keys.editorKey(event)
+				active.input.SetLabel(keys.label())
+			case active.lastView != nil && active.lastView.HasFocus():
+				if _, prompt := focus.(*tview.InputField); !prompt {
+					event = keys.resultKey(event)
+				}
+			}
+			if event == nil {
+				return nil
+			}
+		}
+
+		// F1, or ? anywhere but the editor and prompts, lists the shortcuts
+		if _, prompt := app.GetFocus().(*tview.InputField); event.Key() == tcell.KeyF1 ||
+			(isRune(event, '?') && !prompt && !active.input.HasFocus()) {
+			focus := app.GetFocus()
+			dialogOpen = true
+			showKeyHelp(app, flex, keys.bindings(), func() {
+				dialogOpen = false
+				app.SetFocus(focus)
+			})
+			return nil
+		}
+
+		// Tab switching comes before autocomplete, which claims Tab.
+		// Most terminals cannot send Ctrl+Tab, so Ctrl+N, Alt+N/P and
+		// Alt+1..9 work too.
+		switch {
+		case event.Key() == tcell.KeyTab && event.Modifiers()&tcell.ModCtrl != 0,
+			event.Key() == tcell.KeyCtrlN, isAltRune(event, 'n'):
+			cycleTab(1)
+			return nil
+		case event.Key() == tcell.KeyBacktab && event.Modifiers()&tcell.ModCtrl != 0,
+			isAltRune(event, 'p'):
+			cycleTab(-1)
+			return nil
+		case isAltRune(event, 'w'): // Close the active tab, cancelling its query
+			closeTab(active)
+			return nil
+		case event.Key() == tcell.KeyRune && event.Modifiers()&tcell.ModAlt != 0 &&
+			event.Rune() >= '1' && event.Rune() <= '9':
+			if i := int(event.Rune() - '1'); i < len(tabs) {
+				switchTab(tabs[i])
+			}
+			return nil
+		}
+
+		// Tab and Shift+Tab walk the placeholders of an inserted snippet
+		if s := active.snippet; s != nil && active.input.HasFocus() && event.Modifiers()&tcell.ModCtrl == 0 &&
+			(event.Key() == tcell.KeyTab || event.Key() == tcell.KeyBacktab) &&
+			(autocompleteHandler == nil || !autocompleteHandler.SuggestionsVisible()) {
+			step := 1
+			if event.Key() == tcell.KeyBacktab {
+				step = -1
+			}
+			if !s.advance(active.input, step) {
+				active.snippet = nil
+			}
+			return nil
+		}
+
+		// Right at the end of the query, or Tab, accepts the rest of the line
+		// suggested from the history, unless the suggestion box is open
+		if active.input.HasFocus() && active.editor.ghostShown() && event.Modifiers()&tcell.ModCtrl == 0 &&
+			(event.Key() == tcell.KeyRight || event.Key() == tcell.KeyTab) &&
+			(autocompleteHandler == nil || !autocompleteHandler.SuggestionsVisible()) {
+			active.editor.acceptGhost()
+			return nil
+		}
+
 		// First check if autocomplete handler wants to handle this key
 		if autocompleteHandler != nil && autocompleteHandler.ProcessKey(event) {
 			return nil
 		}
 
+		tab := active
+		input := tab.input
+		if isAltRune(event, 'F') && input.HasFocus() { // Format the query in place
+			style, err := sqlfmt.Configured(config.AppConfig.SQLFormat)
+			if err != nil {
+				setStatus(tab, fmt.Sprintf("[red]%s", tview.Escape(err.Error())))
+				return nil
+			}
+			// The editor has a single line, so the query stays on it
+			tab.snippet = nil
+			input.SetText(sqlfmt.Compact(input.GetText(), style))
+			setStatus(tab, "[green]Query formatted")
+			return nil
+		}
 		switch event.Key() {
 		case tcell.KeyUp: // Navigate history (previous query)
-			historyLock.Lock()
-			if historyIndex > 0 {
-				historyIndex--
-				input.SetText(queryHistory[historyIndex])
-				log.Debug("History navigation", zap.String("direction", "up"), zap.Int("index", historyIndex))
+			if !input.HasFocus() {
+				return event
+			}
+			tab.snippet = nil
+			if tab.historyIndex > 0 {
+				tab.historyIndex--
+				input.SetText(tab.history[tab.historyIndex])
+				log.Debug("History navigation", zap.String("direction", "up"), zap.Int("index", tab.historyIndex))
 			}
-			historyLock.Unlock()
 			return nil
 		case tcell.KeyDown: // Navigate history (next query)
-			historyLock.Lock()
-			if historyIndex < len(queryHistory)-1 {
-				historyIndex++
-				input.SetText(queryHistory[historyIndex])
-				log.Debug("History navigation", zap.String("direction", "down"), zap.Int("index", historyIndex))
+			if !input.HasFocus() {
+				return event
+			}
+			tab.snippet = nil
+			if tab.historyIndex < len(tab.history)-1 {
+				tab.historyIndex++
+				input.SetText(tab.history[tab.historyIndex])
+				log.Debug("History navigation", zap.String("direction", "down"), zap.Int("index", tab.historyIndex))
 			} else {
 				input.SetText("")
 			}
-			historyLock.Unlock()
 			return nil
 		case tcell.KeyEscape: // Clear input
+			if browserVisible && browser.TreeView().HasFocus() {
+				app.SetFocus(input)
+				return nil
+			}
+			if !input.HasFocus() {
+				// Let the result table and its filter prompt handle Escape
+				return event
+			}
+			if tab.snippet != nil {
+				// Leave the snippet as filled in so far
+				tab.snippet = nil
+				return nil
+			}
 			input.SetText("")
 			log.Debug("Input cleared")
 			return nil
+		case tcell.KeyCtrlB, tcell.KeyF3: // Show or hide the schema browser
+			toggleBrowser()
+			return nil
+		case tcell.KeyCtrlP, tcell.KeyF4: // Switch to another profile
+			focus := app.GetFocus()
+			dialogOpen = true
+			showProfilePicker(app, flex, profile, switchProfile, func(status string) {
+				dialogOpen = false
+				if status != "" {
+					setStatus(active, status)
+				}
+				app.SetFocus(focus)
+			})
+			return nil
+		case tcell.KeyCtrlT: // Open a new query tab
+			addTab("New query tab. [yellow]Ctrl+N[-] switches tabs and [yellow]Alt+W[-] closes this one.")
+			return nil
+		case tcell.KeyCtrlQ: // List running queries, with a kill action
+			focus := app.GetFocus()
+			dialogOpen = true
+			showQueriesPanel(ctx, app, flex, profile, func() []localQuery {
+				var running []localQuery
+				for _, t := range tabs {
+					if t.running() {
+						running = append(running, localQuery{tab: t.number, query: t.query, started: t.stats.started, cancel: t.cancel})
+					}
+				}
+				return running
+			}, func(status string) {
+				dialogOpen = false
+				if status != "" {
+					setStatus(tab, status)
+				}
+				app.SetFocus(focus)
+			})
+			return nil
+		case tcell.KeyCtrlE: // Export the last result
+			if tab.lastView == nil {
+				setStatus(tab, "[yellow]No results to export")
+				return nil
+			}
+			dialogOpen = true
+			showExportDialog(app, flex, tab.lastView.result, tab.lastView.displayed(), func(status string) {
+				dialogOpen = false
+				setStatus(tab, status)
+				app.SetFocus(input)
+			})
+			return nil
+		case tcell.KeyCtrlR: // Search the persistent history
+			dialogOpen = true
+			showHistoryPicker(ctx, app, flex, tab.editor.render, func(query string) {
+				input.SetText(query)
+			}, func(status string) {
+				dialogOpen = false
+				setStatus(tab, status)
+				app.SetFocus(input)
+			})
+			return nil
+		case tcell.KeyCtrlO: // Insert a snippet, or save the query as one
+			dialogOpen = true
+			showSnippetPicker(ctx, app, flex, tab.editor.render, input.GetText(), func(s snippet.Snippet) {
+				tab.snippet = insertSnippet(input, s.Body)
+			}, func(status string) {
+				dialogOpen = false
+				setStatus(tab, status)
+				app.SetFocus(input)
+			})
+			return nil
+		case tcell.KeyCtrlG: // Edit the query in $EDITOR; a trailing ; runs it
+			edited, err := editExternally(app, input.GetText())
+			if err != nil {
+				log.Error("External editor failed", zap.Error(err))
+				setStatus(tab, fmt.Sprintf("[red]%s", tview.Escape(err.Error())))
+				return nil
+			}
+			query, run := splitRunSuffix(edited)
+			input.SetText(query)
+			app.SetFocus(input)
+			if run {
+				runQuery(tab)
+			} else {
+				setStatus(tab, "[green]Query loaded from the editor; press Enter to run it")
+			}
+			return nil
+		case tcell.KeyF5: // Start or stop re-running the tab's last query
+			if browserVisible && browser.TreeView().HasFocus() {
+				// Refreshes the schema pane instead
+				return event
+			}
+			if tab.watching() {
+				stopWatch(tab)
+				setStatus(tab, "[yellow]Watch stopped")
+				refreshTabBar()
+				return nil
+			}
+			if tab.query == "" {
+				setStatus(tab, "[yellow]Run a query first, then press F5 to re-run it every "+defaultInterval.String())
+				return nil
+			}
+			startWatch(tab, tab.query, defaultInterval)
+			if tab.running() {
+				setStatus(tab, fmt.Sprintf("[green]Re-running every %s once the current run finishes (F5 stops)", defaultInterval))
+			} else {
+				execute(tab, tab.query, "", false, true)
+			}
+			refreshTabBar()
+			return nil
+		case tcell.KeyF2: // Toggle syntax highlighting in every tab
+			highlight = !highlight
+			for _, t := range tabs {
+				t.editor.setEnabled(highlight)
+			}
+			if highlight {
+				setStatus(tab, "[green]Syntax highlighting on")
+			} else {
+				setStatus(tab, "[yellow]Syntax highlighting off")
+			}
+			return nil
 		case tcell.KeyCtrlC: // Exit application
 			log.Info("User initiated application exit")
 			app.Stop()
@@ -188,81 +874,17 @@ func StartInteractive(profile string) {
 	log.Info("TUI application closed")
 }
 
-// createResultTable renders query results as a scrollable, interactive table.
-func createResultTable(result *engine.QueryResult, app *tview.Application, input *tview.InputField) *tview.Table {
-	if len(result.Rows) == 0 {
-		// Return a table with just the header and a "No results" message
-		table := tview.NewTable().SetBorders(true)
-
-		// Add column headers
-		for colIndex, colName := range result.Columns {
-			table.SetCell(0, colIndex,
-				tview.NewTableCell(colName).
-					SetTextColor(tcell.ColorGreen).
-					SetAlign(tview.AlignLeft).
-					SetExpansion(1))
-		}
-
-		// Add "No results" message
-		if len(result.Columns) > 0 {
-			table.SetCell(1, 0,
-				tview.NewTableCell("[yellow]No results found.").
-					SetAlign(tview.AlignLeft).
-					SetSelectable(false))
-		}
-
-		return table
-	}
-
-	table := tview.NewTable().
-		SetBorders(true).
-		SetSelectable(true, false) // Allow row selection for easier reading
-
-	// Add column headers with styling
-	for colIndex, colName := range result.Columns {
-		table.SetCell(0, colIndex,
-			tview.NewTableCell(colName).
-				SetTextColor(tcell.ColorGreen).
-				SetAlign(tview.AlignLeft).
-				SetExpansion(1).
-				SetSelectable(false))
-	}
-
-	// Add data rows
-	for rowIndex, row := range result.Rows {
-		for colIndex, value := range row {
-			var cellText string
-			if value == nil {
-				cellText = "NULL"
-			} else {
-				cellText = fmt.Sprintf("%v", value)
-			}
-
-			table.SetCell(rowIndex+1, colIndex, // +1 to account for header row
-				tview.NewTableCell(cellText).
-					SetAlign(tview.AlignLeft).
-					SetExpansion(1))
-		}
+// autocompleteProfile describes the named profile to the schema cache
+// updater and the completion popup
+func autocompleteProfile(name string) autocomplete.Profile {
+	p := config.AppConfig.Profiles[name]
+	return autocomplete.Profile{
+		Name:    name,
+		DSN:     p.DSN(),
+		Server:  p.ServerKey(),
+		Catalog: p.Catalog,
+		Schema:  p.Schema,
+		Include: config.AppConfig.Autocomplete.Include,
+		Exclude: config.AppConfig.Autocomplete.Exclude,
 	}
-
-	// Set table properties
-	table.SetFixed(1, 0) // Fix header row
-	table.SetSeparator(tview.Borders.Vertical)
-
-	// Make the table scrollable and selectable
-	table.SetSelectable(true, false)
-	table.SetSelectedStyle(tcell.StyleDefault.Background(tcell.ColorNavy).Foreground(tcell.ColorWhite))
-
-	// Add key handler for the table
-	table.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
-		switch event.Key() {
-		case tcell.KeyEscape:
-			// Return focus to the input field when Escape is pressed
-			app.SetFocus(input)
-			return nil
-		}
-		return event
-	})
-
-	return table
 }
@@ -0,0 +1,45 @@
+package ui
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/TFMV/trino-cli/config"
+)
+
+// defaultWatchInterval is how often a watched query re-runs unless
+// ui.watch.interval or the \watch command says otherwise
+const defaultWatchInterval = 5 * time.Second
+
+// watchSuffix matches a trailing \watch command with an optional interval
+// in seconds, as in psql
+var watchSuffix = regexp.MustCompile(`(?i)\\watch(?:\s+(\d+(?:\.\d+)?))?\s*$`)
+
+// splitWatchSuffix strips a trailing \watch [seconds] from query. interval
+// is zero when the command gives none.
+func splitWatchSuffix(query string) (stripped string, interval time.Duration, watch bool) {
+	m := watchSuffix.FindStringSubmatchIndex(query)
+	if m == nil {
+		return query, 0, false
+	}
+	if m[2] >= 0 {
+		seconds, _ := strconv.ParseFloat(query[m[2]:m[3]], 64)
+		interval = time.Duration(seconds * float64(time.Second))
+	}
+	return strings.TrimSpace(query[:m[0]]), interval, true
+}
+
+// watchInterval parses ui.watch.interval
+func watchInterval(settings config.Watch) (time.Duration, error) {
+	if strings.TrimSpace(settings.Interval) == "" {
+		return defaultWatchInterval, nil
+	}
+	interval, err := time.ParseDuration(strings.TrimSpace(settings.Interval))
+	if err != nil || interval <= 0 {
+		return defaultWatchInterval, fmt.Errorf("invalid ui.watch.interval %q", settings.Interval)
+	}
+	return interval, nil
+}
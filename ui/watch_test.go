@@ -0,0 +1,87 @@
+package ui
+
+import (
+	"testing"
+	"time"
+
+	"github.com/TFMV/trino-cli/config"
+	"github.com/TFMV/trino-cli/engine"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+func TestSplitWatchSuffix(t *testing.T) {
+	tests := []struct {
+		query    string
+		stripped string
+		interval time.Duration
+		watch    bool
+	}{
+		{"SELECT count(*) FROM t \\watch 2", "SELECT count(*) FROM t", 2 * time.Second, true},
+		{"SELECT 1\n\\WATCH 0.5 ", "SELECT 1", 500 * time.Millisecond, true},
+		{"SELECT 1 \\watch", "SELECT 1", 0, true},
+		{"SELECT '\\watch 2' AS s", "SELECT '\\watch 2' AS s", 0, false},
+		{"SELECT 1", "SELECT 1", 0, false},
+	}
+	for _, tt := range tests {
+		stripped, interval, watch := splitWatchSuffix(tt.query)
+		if stripped != tt.stripped || interval != tt.interval || watch != tt.watch {
+			t.Errorf("splitWatchSuffix(%q) = %q, %v, %v", tt.query, stripped, interval, watch)
+		}
+	}
+}
+
+func TestWatchInterval(t *testing.T) {
+	for setting, want := range map[string]time.Duration{"": defaultWatchInterval, "30s": 30 * time.Second} {
+		if got, err := watchInterval(config.Watch{Interval: setting}); got != want || err != nil {
+			t.Errorf("watchInterval(%q) = %v, %v, want %v", setting, got, err, want)
+		}
+	}
+	for _, setting := range []string{"soon", "0s"} {
+		if got, err := watchInterval(config.Watch{Interval: setting}); got != defaultWatchInterval || err == nil {
+			t.Errorf("watchInterval(%q) = %v, %v, want the default and an error", setting, got, err)
+		}
+	}
+}
+
+// bold reports whether a cell is drawn in bold, as changed cells are
+func bold(cell *tview.TableCell) bool {
+	_, _, attrs := cell.Style.Decompose()
+	return (attrs|cell.Attributes)&tcell.AttrBold != 0
+}
+
+func TestResultPagesReplace(t *testing.T) {
+	pages := newResultPages(&engine.QueryResult{
+		Columns: []string{"job", "rows"},
+		Types:   []string{"VARCHAR", "BIGINT"},
+		Rows:    [][]interface{}{{"b", int64(10)}, {"a", int64(5)}, {"c", int64(1)}},
+	})
+	pages.sortBy(0)
+	if err := pages.setFilter("rows > 2"); err != nil {
+		t.Fatal(err)
+	}
+
+	pages.replace(&engine.QueryResult{
+		Columns: []string{"job", "rows"},
+		Types:   []string{"VARCHAR", "BIGINT"},
+		Rows:    [][]interface{}{{"b", int64(12)}, {"a", int64(5)}, {"c", int64(3)}},
+	}, true)
+
+	if got := pages.rowCount(); got != 3 {
+		t.Fatalf("rows after replace = %d, want 3 (filter kept)", got)
+	}
+	if got := pages.GetCell(1, 0).Text; got != "a" {
+		t.Errorf("first row = %q, want a (sort kept)", got)
+	}
+	if cell := pages.GetCell(1, 1); bold(cell) {
+		t.Error("unchanged cell is highlighted")
+	}
+	if cell := pages.GetCell(2, 1); cell.Text != "12" || !bold(cell) {
+		t.Errorf("changed cell = %q, want 12 highlighted", cell.Text)
+	}
+
+	pages.replace(pages.result, false)
+	if cell := pages.GetCell(2, 1); bold(cell) {
+		t.Error("cell highlighted with highlighting off")
+	}
+}